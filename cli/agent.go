@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	agentSvc "go.keploy.io/server/v2/pkg/service/agent"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("agent", Agent)
+}
+
+// Agent retrieves the command to serve the gRPC control plane to record and test the app from a remote orchestrator
+func Agent(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "agent",
+		Short:   "serve the gRPC control plane to record and test the app from a remote orchestrator, and from keploy record and test run with --daemon",
+		Example: `sudo keploy agent -c "/path/to/user/app"`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.Validate(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var agent agentSvc.Service
+			var ok bool
+			if agent, ok = svc.(agentSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy agent service interface")
+				return errors.New("service doesn't satisfy agent service interface")
+			}
+			err = agent.Serve(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to serve the agent")
+			}
+			return err
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add agent cmd flags")
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+// runOnAgent runs keploy record or keploy test as an unprivileged client of keploy agent.
+func runOnAgent(ctx context.Context, logger *zap.Logger, cmdName string, cfg *config.Config) {
+	err := agentSvc.RunClient(ctx, logger, cmdName, *cfg)
+	if err != nil {
+		utils.LogError(logger, err, "failed to "+cmdName+" through keploy agent")
+	}
+}
@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	analyzeSvc "go.keploy.io/server/v2/pkg/service/analyze"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("analyze", Analyze)
+}
+
+// Analyze retrieves the command to report the consumption of the mocks across the last test runs and prune the never used mocks
+func Analyze(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "analyze",
+		Short:   "report the consumption of the mocks across the last test runs and prune the never used mocks",
+		Example: `keploy analyze --runs 10 --plan prune.yaml`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			planFile, err := cmd.Flags().GetString("prune")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get prune flag")
+				return err
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var analyze analyzeSvc.Service
+			var ok bool
+			if analyze, ok = svc.(analyzeSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy analyze service interface")
+				return errors.New("service doesn't satisfy analyze service interface")
+			}
+			if planFile != "" {
+				err = analyze.Prune(ctx, planFile)
+				if err != nil {
+					utils.LogError(logger, err, "failed to prune the mocks")
+				}
+				return err
+			}
+			err = analyze.Analyze(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to analyze the mocks")
+			}
+			return err
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add analyze cmd flags")
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
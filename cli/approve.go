@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	approveSvc "go.keploy.io/server/v2/pkg/service/approve"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("approve", Approve)
+}
+
+// Approve retrieves the command to accept the responses of the failed testcases of a test run as their expected responses
+func Approve(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "approve <testset>",
+		Short: "accept the responses of the failed testcases in the latest test run as their expected responses, recording the approval in the changelog of the test set",
+		Example: `keploy approve test-set-0 -m "the order id is a uuid now"
+keploy approve test-set-0 --testRun test-run-4 --testcases test-2,test-5`,
+		Args: cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			testRunID, err := cmd.Flags().GetString("testRun")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testRun flag")
+				return nil
+			}
+			testCases, err := cmd.Flags().GetStringSlice("testcases")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testcases flag")
+				return nil
+			}
+			message, err := cmd.Flags().GetString("message")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get message flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var approve approveSvc.Service
+			var ok bool
+			if approve, ok = svc.(approveSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy approve service interface")
+				return nil
+			}
+			err = approve.Approve(ctx, args[0], testRunID, testCases, message)
+			if err != nil {
+				utils.LogError(logger, err, "failed to approve the responses")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add approve cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	bundleSvc "go.keploy.io/server/v2/pkg/service/bundle"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("bundle", Bundle)
+}
+
+// Bundle retrieves the command to pack the test sets into a .keploy bundle and unpack them from it
+func Bundle(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "bundle",
+		Short: "pack the test sets, mocks and reports into a single compressed .keploy bundle and unpack them from it",
+		Example: `keploy bundle pack --file ./keploy.keploy
+keploy bundle unpack --file ./keploy.keploy -t test-set-0`,
+	}
+
+	var packCmd = &cobra.Command{
+		Use:     "pack",
+		Short:   "pack the test sets, mocks and reports of the keploy directory into a .keploy bundle",
+		Example: `keploy bundle pack --file ./keploy.keploy`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			bundle, ok := getBundleService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			err := bundle.Pack(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to pack the test sets")
+			}
+			return nil
+		},
+	}
+
+	var unpackCmd = &cobra.Command{
+		Use:     "unpack",
+		Short:   "unpack the test sets, mocks and reports of a .keploy bundle into the keploy directory",
+		Example: `keploy bundle unpack --file ./keploy.keploy`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			bundle, ok := getBundleService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			err := bundle.Unpack(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to unpack the test sets")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(packCmd, unpackCmd)
+	for _, c := range []*cobra.Command{cmd, packCmd, unpackCmd} {
+		if err := cmdConfigurator.AddFlags(c); err != nil {
+			utils.LogError(logger, err, "failed to add bundle cmd flags")
+			return nil
+		}
+	}
+	return cmd
+}
+
+// packBundle packs the keploy directory back into the bundle after record and test with the bundle storage driver.
+func packBundle(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory) {
+	// the command is usually stopped by cancelling the context, the test sets should still be packed
+	ctx = context.WithoutCancel(ctx)
+	bundle, ok := getBundleService(ctx, logger, serviceFactory)
+	if !ok {
+		return
+	}
+	err := bundle.Pack(ctx)
+	if err != nil {
+		utils.LogError(logger, err, "failed to pack the test sets into the bundle")
+	}
+}
+
+func getBundleService(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory) (bundleSvc.Service, bool) {
+	svc, err := serviceFactory.GetService(ctx, "bundle")
+	if err != nil {
+		utils.LogError(logger, err, "failed to get service")
+		return nil, false
+	}
+	bundle, ok := svc.(bundleSvc.Service)
+	if !ok {
+		utils.LogError(logger, nil, "service doesn't satisfy bundle service interface")
+		return nil, false
+	}
+	return bundle, true
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("completion", Completion)
+}
+
+// Completion retrieves the command to generate the shell completion scripts of keploy
+func Completion(_ context.Context, _ *zap.Logger, _ *config.Config, _ ServiceFactory, _ CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "completion bash|zsh|fish",
+		Short: "generate the completion script of keploy for the shell",
+		Example: `source <(keploy completion bash)
+keploy completion zsh > "${fpath[1]}/_keploy"
+keploy completion fish > ~/.config/fish/completions/keploy.fish`,
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(os.Stdout, true)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout, true)
+			}
+			return fmt.Errorf("unsupported shell %s, expected bash, zsh or fish", args[0])
+		},
+	}
+	return cmd
+}
@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	contractSvc "go.keploy.io/server/v2/pkg/service/contract"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("contract", Contract)
+}
+
+// Contract retrieves the command to export and verify the contracts between the services
+func Contract(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "contract",
+		Short: "export the mocks of a consumer as contracts and verify them at the provider",
+		Example: `keploy contract export --service order-service -t test-set-0 --contracts ./contracts
+keploy contract verify --provider payment-8080 --baseUrl http://localhost:8080 --contracts ./contracts`,
+	}
+
+	var exportCmd = &cobra.Command{
+		Use:     "export",
+		Short:   "export the recorded http mocks of the consumer service as contracts of its providers",
+		Example: `keploy contract export --service order-service -t test-set-0`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			contract, ok := getContractService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			err := contract.Export(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to export the contracts")
+			}
+			return nil
+		},
+	}
+
+	var verifyCmd = &cobra.Command{
+		Use:     "verify",
+		Short:   "verify the contracts of the consumers against the provider service",
+		Example: `keploy contract verify --provider payment-8080 --baseUrl http://localhost:8080`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			contract, ok := getContractService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			err := contract.Verify(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to verify the contracts")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(exportCmd, verifyCmd)
+	for _, c := range []*cobra.Command{cmd, exportCmd, verifyCmd} {
+		if err := cmdConfigurator.AddFlags(c); err != nil {
+			utils.LogError(logger, err, "failed to add contract cmd flags")
+			return nil
+		}
+	}
+	return cmd
+}
+
+func getContractService(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory) (contractSvc.Service, bool) {
+	svc, err := serviceFactory.GetService(ctx, "contract")
+	if err != nil {
+		utils.LogError(logger, err, "failed to get service")
+		return nil, false
+	}
+	contract, ok := svc.(contractSvc.Service)
+	if !ok {
+		utils.LogError(logger, nil, "service doesn't satisfy contract service interface")
+		return nil, false
+	}
+	return contract, true
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	doctorSvc "go.keploy.io/server/v2/pkg/service/doctor"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("doctor", Doctor)
+}
+
+// Doctor retrieves the command to check the machine before recording, it exits with a non-zero code if a check failed
+func Doctor(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "doctor",
+		Short:   "check that keploy can record and test the app on this machine",
+		Example: `sudo -E keploy doctor --proxyPort 16789`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var doctor doctorSvc.Service
+			var ok bool
+			if doctor, ok = svc.(doctorSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy doctor service interface")
+				return errors.New("service doesn't satisfy doctor service interface")
+			}
+
+			failed := 0
+			for _, check := range doctor.Diagnose(ctx) {
+				switch {
+				case check.Err == nil:
+					fmt.Printf("  PASS  %-14s %s\n", check.Name, check.Detail)
+					continue
+				case check.Warn:
+					fmt.Printf("  WARN  %-14s %v\n", check.Name, check.Err)
+				default:
+					failed++
+					fmt.Printf("  FAIL  %-14s %v\n", check.Name, check.Err)
+				}
+				if check.Hint != "" {
+					fmt.Printf("        %-14s fix: %s\n", "", check.Hint)
+				}
+			}
+			fmt.Println()
+			if failed > 0 {
+				errMsg := fmt.Sprintf("%d checks failed, fix them before running keploy record or keploy test", failed)
+				utils.LogError(logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+			logger.Info("keploy can record and test the app on this machine")
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add doctor cmd flags")
+		return nil
+	}
+	// the failed checks are already printed, the usage is not what went wrong
+	cmd.SilenceUsage = true
+	return cmd
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	explainSvc "go.keploy.io/server/v2/pkg/service/explain"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("explain", Explain)
+}
+
+// Explain retrieves the command to explain why the testcases of a test run failed
+func Explain(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "explain <testset> [testcase...]",
+		Short: "explain the failed testcases of the latest test run: the fields which differed, the mocks which were and weren't consumed, the unmocked outgoing calls and the likely causes",
+		Example: `keploy explain test-set-0
+keploy explain test-set-0 test-3 --testRun test-run-4`,
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			testRunID, err := cmd.Flags().GetString("testRun")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testRun flag")
+				return nil
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var explain explainSvc.Service
+			var ok bool
+			if explain, ok = svc.(explainSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy explain service interface")
+				return nil
+			}
+			err = explain.Explain(ctx, args[0], testRunID, args[1:], os.Stdout)
+			if err != nil {
+				utils.LogError(logger, err, "failed to explain the testcases")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add explain cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("export", Export)
+}
+
+// Export retrieves the command to export the recorded testcases into other formats
+func Export(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "export",
+		Short:   "export the recorded testcases into other formats",
+		Example: `keploy export --format postman -o postman_collection.json`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get format flag")
+				return nil
+			}
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get output flag")
+				return nil
+			}
+			testSets, err := cmd.Flags().GetStringSlice("testsets")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testsets flag")
+				return nil
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return nil
+			}
+			err = tools.Export(ctx, format, testSets, output)
+			if err != nil {
+				utils.LogError(logger, err, "failed to export the testcases")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add export cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("generate", Generate)
+}
+
+// Generate retrieves the command to generate testcases from an OpenAPI spec
+func Generate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "generate",
+		Short:   "generate keploy testcases from an OpenAPI spec",
+		Example: `keploy generate --openapi /path/to/openapi.yaml --baseUrl "http://localhost:8080"`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			specPath, err := cmd.Flags().GetString("openapi")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get openapi flag")
+				return nil
+			}
+			baseURL, err := cmd.Flags().GetString("baseUrl")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get baseUrl flag")
+				return nil
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return nil
+			}
+			err = tools.GenerateFromOpenAPI(ctx, specPath, baseURL)
+			if err != nil {
+				utils.LogError(logger, err, "failed to generate testcases from the openapi spec")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add generate cmd flags")
+		return nil
+	}
+	return cmd
+}
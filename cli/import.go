@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("import", Import)
+}
+
+// Import retrieves the command to import testcases from other api tools
+func Import(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "import",
+		Short: "import testcases from other api tools",
+		Example: `keploy import --postman /path/to/collection.json --env /path/to/environment.json
+keploy import --curl "curl -X POST http://localhost:8080/users -d '{\"name\":\"john\"}'"
+keploy import --http /path/to/requests.http -t test-set-0`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			collectionPath, err := cmd.Flags().GetString("postman")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get postman flag")
+				return nil
+			}
+			envPath, err := cmd.Flags().GetString("env")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get env flag")
+				return nil
+			}
+			curlCmds, err := cmd.Flags().GetString("curl")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get curl flag")
+				return nil
+			}
+			httpFilePath, err := cmd.Flags().GetString("http")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get http flag")
+				return nil
+			}
+			testSetID, err := cmd.Flags().GetString("testset")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testset flag")
+				return nil
+			}
+			sources := 0
+			for _, source := range []string{collectionPath, curlCmds, httpFilePath} {
+				if source != "" {
+					sources++
+				}
+			}
+			if sources != 1 {
+				return errors.New("exactly one of --postman, --curl or --http flags is required")
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return nil
+			}
+			switch {
+			case collectionPath != "":
+				err = tools.ImportPostman(ctx, collectionPath, envPath)
+				if err != nil {
+					utils.LogError(logger, err, "failed to import the postman collection")
+				}
+			case curlCmds != "":
+				err = tools.ImportCurl(ctx, curlCmds, testSetID)
+				if err != nil {
+					utils.LogError(logger, err, "failed to import the curl commands")
+				}
+			case httpFilePath != "":
+				err = tools.ImportHTTPFile(ctx, httpFilePath, testSetID)
+				if err != nil {
+					utils.LogError(logger, err, "failed to import the http file")
+				}
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add import cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	ingressSvc "go.keploy.io/server/v2/pkg/service/ingress"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("replay-ingress", ReplayIngress)
+}
+
+// ReplayIngress retrieves the command to send the recorded requests to an environment as a client
+func ReplayIngress(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "replay-ingress",
+		Short:   "send the recorded requests to an environment, e.g. a deployed staging, and compare its responses with the recorded ones",
+		Example: `keploy replay-ingress --testset test-set-0 --url https://staging.example.com`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var ingress ingressSvc.Service
+			var ok bool
+			if ingress, ok = svc.(ingressSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy ingress service interface")
+				return nil
+			}
+			err = ingress.Start(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to replay the requests")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add replay-ingress cmd flags")
+		return nil
+	}
+	return cmd
+}
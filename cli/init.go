@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("init", Init)
+}
+
+// Init retrieves the command to write a starter keploy.yml for the app with an interactive wizard
+func Init(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "init",
+		Short:   "write a starter keploy.yml for the app, detecting how it is run, its language and its port",
+		Example: `keploy init --path ./my-app`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return errors.New("service doesn't satisfy tools service interface")
+			}
+			err = tools.Init(ctx, cfg.Path)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write the starter config")
+			}
+			return err
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add init flags")
+		return nil
+	}
+	return cmd
+}
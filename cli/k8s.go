@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	k8sSvc "go.keploy.io/server/v2/pkg/service/k8s"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("k8s", K8s)
+}
+
+// K8s retrieves the command to record the app of a kubernetes pod as its sidecar and to test it with a Job
+func K8s(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "k8s",
+		Short: "record the app of a kubernetes pod with keploy as its sidecar and test it with a Job",
+		Example: `keploy k8s manifest sidecar --pvc keploy-data
+keploy k8s record --path /data --bucket my-keploy-tests
+keploy k8s test --path /data --bucket my-keploy-tests`,
+	}
+
+	var recordCmd = &cobra.Command{
+		Use:     "record",
+		Short:   "record the app sharing the process namespace of the pod, until the pod terminates",
+		Example: `keploy k8s record --path /data`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.Validate(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			k8s, ok := getK8sService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			err := k8s.Record(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to record the app of the pod")
+			}
+			return err
+		},
+	}
+
+	var testCmd = &cobra.Command{
+		Use:     "test",
+		Short:   "test the app sharing the process namespace of the pod, failing if any test set fails",
+		Example: `keploy k8s test --path /data --delay 10`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.Validate(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			k8s, ok := getK8sService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			err := k8s.Test(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to test the app of the pod")
+			}
+			return err
+		},
+	}
+
+	var manifestCmd = &cobra.Command{
+		Use:   "manifest [sidecar|job]",
+		Short: "write the manifest of the keploy sidecar to add to the pod of the app, or of the Job testing the app",
+		Example: `keploy k8s manifest sidecar --pvc keploy-data
+keploy k8s manifest job --appImage my-app:latest --bucket my-keploy-tests -o keploy-test.yaml`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{"sidecar", "job"},
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				utils.LogError(logger, err, "failed to read the output path")
+				return nil
+			}
+			k8s, ok := getK8sService(ctx, logger, serviceFactory)
+			if !ok {
+				return nil
+			}
+			w := os.Stdout
+			if output != "" {
+				w, err = os.Create(output)
+				if err != nil {
+					utils.LogError(logger, err, "failed to create the manifest file")
+					return nil
+				}
+				defer w.Close()
+			}
+			err = k8s.Manifest(ctx, args[0], w)
+			if err != nil {
+				utils.LogError(logger, err, "failed to write the manifest")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(recordCmd, testCmd, manifestCmd)
+	for _, c := range []*cobra.Command{cmd, recordCmd, testCmd, manifestCmd} {
+		if err := cmdConfigurator.AddFlags(c); err != nil {
+			utils.LogError(logger, err, "failed to add k8s cmd flags")
+			return nil
+		}
+		c.SilenceUsage = true
+	}
+	return cmd
+}
+
+func getK8sService(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory) (k8sSvc.Service, bool) {
+	svc, err := serviceFactory.GetService(ctx, "k8s")
+	if err != nil {
+		utils.LogError(logger, err, "failed to get service")
+		return nil, false
+	}
+	k8s, ok := svc.(k8sSvc.Service)
+	if !ok {
+		utils.LogError(logger, nil, "service doesn't satisfy k8s service interface")
+		return nil, false
+	}
+	return k8s, true
+}
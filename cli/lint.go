@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	lintSvc "go.keploy.io/server/v2/pkg/service/lint"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("lint", Lint)
+}
+
+// Lint retrieves the command to validate the recorded testcases and mocks, it exits with a non-zero code on the errors
+func Lint(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "lint",
+		Short:   "validate the recorded testcases and mocks",
+		Example: `keploy lint -t test-set-0 --strict`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			testSets, err := cmd.Flags().GetStringSlice("testsets")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testsets flag")
+				return err
+			}
+			strict, err := cmd.Flags().GetBool("strict")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get strict flag")
+				return err
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var lint lintSvc.Service
+			var ok bool
+			if lint, ok = svc.(lintSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy lint service interface")
+				return errors.New("service doesn't satisfy lint service interface")
+			}
+			issues, err := lint.Lint(ctx, testSets)
+			if err != nil {
+				utils.LogError(logger, err, "failed to lint the test sets")
+				return err
+			}
+
+			errorCount, warningCount := 0, 0
+			for _, issue := range issues {
+				fields := []zap.Field{zap.String("test-set", issue.TestSet), zap.String("file", issue.File)}
+				if issue.Name != "" {
+					fields = append(fields, zap.String("name", issue.Name))
+				}
+				if issue.Severity == lintSvc.SeverityError {
+					errorCount++
+					utils.LogError(logger, nil, issue.Message, fields...)
+					continue
+				}
+				warningCount++
+				logger.Warn(issue.Message, fields...)
+			}
+			if errorCount > 0 || (strict && warningCount > 0) {
+				errMsg := fmt.Sprintf("found %d errors and %d warnings in the recorded testcases and mocks", errorCount, warningCount)
+				utils.LogError(logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+			logger.Info("the recorded testcases and mocks are valid", zap.Int("warnings", warningCount))
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add lint cmd flags")
+		return nil
+	}
+	// the issues are already logged, the usage is not what went wrong
+	cmd.SilenceUsage = true
+	return cmd
+}
@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	loadSvc "go.keploy.io/server/v2/pkg/service/load"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("load", Load)
+}
+
+// Load retrieves the command to replay the recorded testcases as load against a running application
+func Load(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "load",
+		Short:   "replay the recorded testcases as load against a running application",
+		Example: `keploy load --testset test-set-0 --rps 100 --duration 2m --baseUrl http://staging.example.com`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var load loadSvc.Service
+			var ok bool
+			if load, ok = svc.(loadSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy load service interface")
+				return nil
+			}
+			err = load.Start(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to run the load")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add load cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	migrateSvc "go.keploy.io/server/v2/pkg/service/migrate"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("migrate", Migrate)
+}
+
+// Migrate retrieves the command to upgrade the recorded testcases and mocks to the current schema version
+func Migrate(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "migrate",
+		Short:   "upgrade the recorded testcases and mocks to the schema version of this version of keploy",
+		Example: `keploy migrate -t test-set-0 --dryRun`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			testSets, err := cmd.Flags().GetStringSlice("testsets")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get testsets flag")
+				return nil
+			}
+			dryRun, err := cmd.Flags().GetBool("dryRun")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get dryRun flag")
+				return nil
+			}
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var migrate migrateSvc.Service
+			var ok bool
+			if migrate, ok = svc.(migrateSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy migrate service interface")
+				return nil
+			}
+			err = migrate.Migrate(ctx, testSets, dryRun)
+			if err != nil {
+				utils.LogError(logger, err, "failed to migrate the test sets")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add migrate cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -6,8 +6,10 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.keploy.io/server/v2/config"
+	mockServerSvc "go.keploy.io/server/v2/pkg/service/mockserver"
 	recordSvc "go.keploy.io/server/v2/pkg/service/record"
 	replaySvc "go.keploy.io/server/v2/pkg/service/replay"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
@@ -75,5 +77,85 @@ func Mock(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFact
 		utils.LogError(logger, err, "failed to add flags")
 		return nil
 	}
+	// the flags of mock serve are added once it is a subcommand, as they are looked up by its full name
+	serveCmd := MockServe(ctx, logger, serviceFactory, cmdConfigurator)
+	cmd.AddCommand(serveCmd)
+	if err := cmdConfigurator.AddFlags(serveCmd); err != nil {
+		utils.LogError(logger, err, "failed to add mock serve cmd flags")
+		return nil
+	}
+	editCmd := MockEdit(ctx, logger, serviceFactory, cmdConfigurator)
+	cmd.AddCommand(editCmd)
+	if err := cmdConfigurator.AddFlags(editCmd); err != nil {
+		utils.LogError(logger, err, "failed to add mock edit cmd flags")
+		return nil
+	}
+	return cmd
+}
+
+// MockServe retrieves the command to serve the recorded mocks of a test set as a stub server, without an app
+func MockServe(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "serve",
+		Short: "serve the recorded mocks of a test set on a port, as a stub server of the recorded dependencies",
+		Example: `keploy mock serve -t test-set-0 --port 8080
+keploy mock serve -p ./services/payments`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, "mock serve")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var mockServer mockServerSvc.Service
+			var ok bool
+			if mockServer, ok = svc.(mockServerSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy mock server service interface")
+				return errors.New("service doesn't satisfy mock server service interface")
+			}
+			err = mockServer.Serve(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to serve the mocks")
+			}
+			return nil
+		},
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
+
+// MockEdit retrieves the command to edit a recorded mock in the editor, the edited mock is validated before it is saved
+func MockEdit(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "edit <testset> <mockname>",
+		Short: "edit a recorded mock in $EDITOR, it is validated and its derived fields are recomputed before it is saved",
+		Example: `keploy mock edit test-set-0 mock-3
+EDITOR=nano keploy mock edit test-set-0 mock-3 -p ./services/payments`,
+		Args: cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			svc, err := serviceFactory.GetService(ctx, "mock edit")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return errors.New("service doesn't satisfy tools service interface")
+			}
+			err = tools.EditMock(ctx, args[0], args[1])
+			if err != nil {
+				utils.LogError(logger, err, "failed to edit the mock")
+			}
+			return nil
+		},
+	}
+	cmd.SilenceUsage = true
 	return cmd
 }
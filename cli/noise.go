@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("noise", Noise)
+}
+
+// Noise retrieves the command to edit the global noise of the config file
+func Noise(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "noise",
+		Short: "edit the noise of the test sets in the keploy configuration file",
+		Example: `keploy noise add test-set-0 body.data.updatedAt
+keploy noise add --global header.Date`,
+	}
+
+	var addCmd = &cobra.Command{
+		Use:   "add [testset] <field>",
+		Short: "add a field, header.<name> or body.<json path>, to the noise of a test set or to the global noise",
+		Example: `keploy noise add test-set-0 body.data.items[*].id
+keploy noise add test-set-0 header.X-Request-Id --regex "^[0-9a-f-]+$"
+keploy noise add --global header.Date`,
+		Args: cobra.RangeArgs(1, 2),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configPath, err := cmd.Flags().GetString("configPath")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get configPath flag")
+				return nil
+			}
+			regexes, err := cmd.Flags().GetStringSlice("regex")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get regex flag")
+				return nil
+			}
+			global, err := cmd.Flags().GetBool("global")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get global flag")
+				return nil
+			}
+			var testSetID, field string
+			switch {
+			case global && len(args) == 1:
+				field = args[0]
+			case !global && len(args) == 2:
+				testSetID, field = args[0], args[1]
+			default:
+				err := errors.New("expected the test set and the field, or only the field with --global")
+				utils.LogError(logger, err, "invalid arguments of noise add")
+				return err
+			}
+
+			svc, err := serviceFactory.GetService(ctx, "noise")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return nil
+			}
+			err = tools.AddNoise(ctx, filepath.Join(configPath, "keploy.yml"), testSetID, field, regexes)
+			if err != nil {
+				utils.LogError(logger, err, "failed to add the noise")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(addCmd)
+	for _, c := range []*cobra.Command{cmd, addCmd} {
+		if err := cmdConfigurator.AddFlags(c); err != nil {
+			utils.LogError(logger, err, "failed to add noise cmd flags")
+			return nil
+		}
+	}
+	return cmd
+}
@@ -7,18 +7,21 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/fatih/color"
-	"github.com/moby/moby/pkg/parsers/kernel"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core/hooks"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
 	"go.keploy.io/server/v2/utils"
 	"go.keploy.io/server/v2/utils/log"
 	"go.uber.org/zap"
+	"golang.org/x/term"
 )
 
 func LogExample(example string) string {
@@ -74,6 +77,16 @@ Docker
 	Test:
 	keploy test -c "docker run -p 8080:8080 --name <containerName> --network <networkName> <applicationImage>" --delay 1 --buildDelay 1m
 
+Daemon
+	Agent:
+	sudo keploy agent
+
+	Record:
+	keploy record -c "/path/to/user/app/binary" --daemon
+
+	Test:
+	keploy test -c "/path/to/user/app/binary" --delay 2 --daemon
+
 `
 
 var ExampleOneClickInstall = `
@@ -131,14 +144,20 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 `
 
 var RootExamples = `
+  Init:
+	keploy init
+
   Record:
-	keploy record -c "docker run -p 8080:8080 --name <containerName> --network keploy-network <applicationImage>" --containerName "<containerName>" --delay 1 --buildDelay 1m
+	keploy record -c "docker run -p 8080:8080 --name <containerName> <applicationImage>" --containerName "<containerName>" --delay 1 --buildDelay 1m
 
   Test:
-	keploy test --c "docker run -p 8080:8080 --name <containerName> --network keploy-network <applicationImage>" --delay 1 --buildDelay 1m
+	keploy test --c "docker run -p 8080:8080 --name <containerName> <applicationImage>" --delay 1 --buildDelay 1m
 
   Config:
 	keploy config --generate -p "/path/to/localdir"
+
+  Completion:
+	source <(keploy completion bash)
 `
 
 var VersionTemplate = `{{with .Version}}{{printf "Keploy %s" .}}{{end}}{{"\n"}}`
@@ -155,6 +174,19 @@ func NewCmdConfigurator(logger *zap.Logger, config *config.Config) *CmdConfigura
 	}
 }
 
+// inK8s reports whether the command is a subcommand of keploy k8s, run in a pod without the command of the app.
+func inK8s(cmd *cobra.Command) bool {
+	return cmd.HasParent() && cmd.Parent().Name() == "k8s"
+}
+
+// cmdName returns the name of the command, the subcommands are prefixed with the name of their parent e.g. "contract verify"
+func cmdName(cmd *cobra.Command) string {
+	if cmd.HasParent() && cmd.Parent().Name() != "keploy" {
+		return cmd.Parent().Name() + " " + cmd.Name()
+	}
+	return cmd.Name()
+}
+
 func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 	//sets the displayment of flag-related errors
 	cmd.SilenceErrors = true
@@ -166,12 +198,136 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 
 	//add flags
 	var err error
-	switch cmd.Name() {
+	switch cmdName(cmd) {
 	case "update":
-		return nil
+		cmd.Flags().String("channel", c.cfg.SelfUpdate.Channel, "Release channel to update from: stable, beta or nightly")
+		cmd.Flags().String("offline", c.cfg.SelfUpdate.Offline, "Downloaded release archive, or the directory of the archive and its checksums, to update from in air-gapped environments")
+		cmd.Flags().String("publicKey", c.cfg.SelfUpdate.PublicKey, "Base64 ed25519 key to verify the signature of the release checksums with, defaults to the key of the keploy releases")
+		cmd.Flags().Bool("skipSignature", c.cfg.SelfUpdate.SkipSignature, "Skip verifying the signature of the release checksums, the binary is still verified against the checksums")
+	case "init":
+		cmd.Flags().StringP("path", "p", ".", "Path to the directory of the app the starter config is written to")
 	case "config":
 		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated config is stored")
 		cmd.Flags().Bool("generate", false, "Generate a new keploy configuration file")
+	case "generate":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("openapi", "", "Path to the OpenAPI spec (yaml/json) to generate the testcases from")
+		cmd.Flags().String("baseUrl", "", "Base URL of the application, defaults to the first server of the spec")
+		err := cmd.MarkFlagRequired("openapi")
+		if err != nil {
+			errMsg := "failed to mark openapi as required flag"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+	case "migrate":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testsets", "t", []string{}, "Testsets to migrate e.g. --testsets \"test-set-1, test-set-2\", all the testsets by default")
+		cmd.Flags().Bool("dryRun", false, "Only report the testcases and mocks of an older schema version without changing them")
+	case "analyze":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testsets", "t", c.cfg.Analyze.TestSets, "Testsets to analyze e.g. --testsets \"test-set-1, test-set-2\", all the testsets by default")
+		cmd.Flags().Uint64("runs", c.cfg.Analyze.Runs, "Number of the last test runs to analyze the consumption of the mocks across, 0 for all the test runs")
+		cmd.Flags().String("plan", c.cfg.Analyze.Plan, "Path of the file to write the prune plan of the never used mocks to")
+		cmd.Flags().String("prune", "", "Path of a reviewed prune plan, the mocks listed in it are deleted instead of analyzing the test runs")
+	case "ui":
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+		cmd.Flags().String("profile", c.cfg.Profile, "Profile of the config file whose values are overlaid onto the base config e.g. --profile ci")
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().Uint32("port", c.cfg.UI.Port, "Port of the dashboard, served on the localhost")
+		cmd.Flags().StringP("command", "c", c.cfg.Command, "Command to start the user application, used to rerun the testcases")
+	case "lint":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testsets", "t", []string{}, "Testsets to lint e.g. --testsets \"test-set-1, test-set-2\", all the testsets by default")
+		cmd.Flags().Bool("strict", false, "Exit with a non-zero code on the warnings too")
+	case "doctor":
+		cmd.Flags().Uint32("proxyPort", c.cfg.ProxyPort, "Port used by the Keploy proxy server to intercept the outgoing dependency calls")
+		cmd.Flags().Uint32("dnsPort", c.cfg.DNSPort, "Port used by the Keploy DNS server to intercept the DNS queries")
+		cmd.Flags().String("btfPath", c.cfg.BTFPath, "Path to the BTF of the kernel for the eBPF hooks, on the kernels without /sys/kernel/btf/vmlinux e.g. from btfhub-archive")
+	case "import":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("postman", "", "Path to the postman collection to import the testcases from")
+		cmd.Flags().String("env", "", "Path to the postman environment used to resolve the variables of the collection")
+		cmd.Flags().String("curl", "", "Curl command or path to a file of curl commands to import the testcases from")
+		cmd.Flags().String("http", "", "Path to the .http file to import the testcases from")
+		cmd.Flags().StringP("testset", "t", "", "Testset to add the imported curl/http requests to, a new testset is created by default")
+	case "load":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testset", "t", c.cfg.Load.TestSets, "Testsets to replay as load e.g. --testset \"test-set-1, test-set-2\"")
+		cmd.Flags().Uint64("rps", c.cfg.Load.RPS, "Number of requests sent per second, up to 10000")
+		cmd.Flags().Duration("duration", c.cfg.Load.Duration, "Duration of the load run")
+		cmd.Flags().String("baseUrl", c.cfg.Load.BaseURL, "Base URL of the environment to send the requests to, defaults to the recorded host")
+		cmd.Flags().Uint64("apiTimeout", c.cfg.Load.APITimeout, "Timeout in seconds for each request")
+	case "replay-ingress":
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored, for the noise of the test sets")
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testset", "t", c.cfg.Ingress.TestSets, "Testsets to replay e.g. --testset \"test-set-1, test-set-2\", all the testsets by default")
+		cmd.Flags().String("url", c.cfg.Ingress.URL, "Base URL of the environment to send the recorded requests to e.g. https://staging.example.com")
+		cmd.Flags().Uint64("apiTimeout", c.cfg.Ingress.APITimeout, "Timeout in seconds for each request")
+	case "push", "pull":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("bucket", c.cfg.Remote.Bucket, "Name of the S3-compatible bucket to sync the test sets with")
+		cmd.Flags().String("prefix", c.cfg.Remote.Prefix, "Key prefix of the test sets in the bucket")
+		cmd.Flags().String("endpoint", c.cfg.Remote.Endpoint, "Endpoint of the S3-compatible store e.g. http://localhost:9000, defaults to AWS S3")
+		cmd.Flags().String("region", c.cfg.Remote.Region, "Region of the bucket, defaults to AWS_REGION or us-east-1")
+		cmd.Flags().String("version", c.cfg.Remote.Version, "Version of the test sets to push (defaults to a timestamp) or pull (defaults to the latest)")
+		cmd.Flags().StringSliceP("testsets", "t", c.cfg.Remote.TestSets, "Testsets to sync e.g. --testsets \"test-set-1, test-set-2\", all the testsets and reports by default")
+	case "contract", "bundle", "k8s", "noise", "report":
+		return nil
+	case "noise add":
+		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+		cmd.Flags().StringSlice("regex", nil, "Regexes of the values of the field which are noise, any value is noise by default")
+		cmd.Flags().Bool("global", false, "Add the field to the global noise of all the test sets instead of the noise of a test set")
+	case "mock edit":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+	case "tag":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSlice("add", nil, "Tags to add to the testcases e.g. --add smoke,critical")
+		cmd.Flags().StringSlice("remove", nil, "Tags to remove from the testcases")
+	case "approve":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("testRun", "", "Test run whose responses are approved, the latest test run of the test set by default")
+		cmd.Flags().StringSlice("testcases", nil, "Failed testcases to approve e.g. --testcases \"test-1, test-2\", all the failed testcases by default")
+		cmd.Flags().StringP("message", "m", "", "Reason of the approval, recorded in the changelog of the test set")
+	case "explain":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("testRun", "", "Test run whose results are explained, the latest test run of the test set by default")
+	case "report diff":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testsets", "t", c.cfg.Report.TestSets, "Testsets to compare e.g. --testsets \"test-set-1, test-set-2\", all the testsets by default")
+		cmd.Flags().Float64("latencyThreshold", c.cfg.Report.LatencyThreshold, "Percentage by which the latency of a testcase has to grow to be reported as regressed")
+		cmd.Flags().StringP("output", "o", "", "Path of the markdown file the diff is written to, the diff is printed by default")
+	case "report comment":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testsets", "t", c.cfg.Report.TestSets, "Testsets to summarize e.g. --testsets \"test-set-1, test-set-2\", all the testsets by default")
+		cmd.Flags().String("format", "markdown", "Format of the summary, markdown")
+		cmd.Flags().String("base", "", "Test run the new failures and the coverage are compared with, the test run before by default")
+		cmd.Flags().Int("flakyRuns", c.cfg.Report.FlakyRuns, "Number of the latest test runs in which a testcase both passed and failed is reported flaky")
+		cmd.Flags().StringP("output", "o", "", "Path of the file the summary is written to, the summary is printed by default")
+	case "k8s manifest":
+		cmd.Flags().String("image", c.cfg.K8s.Image, "Image of keploy in the manifest")
+		cmd.Flags().String("appImage", c.cfg.K8s.AppImage, "Image of the app tested by the Job")
+		cmd.Flags().StringP("command", "c", c.cfg.Command, "Command to start the app in the Job, defaults to the command of its image")
+		cmd.Flags().String("pvc", c.cfg.K8s.PVC, "Name of the persistent volume claim storing the test sets, an emptyDir is used by default")
+		cmd.Flags().String("bucket", c.cfg.Remote.Bucket, "Name of the S3-compatible bucket to sync the test sets with, the credentials are read from the keploy-s3 secret")
+		cmd.Flags().StringP("output", "o", "", "Path of the manifest file, the manifest is printed by default")
+	case "bundle pack", "bundle unpack":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringP("file", "f", c.cfg.Bundle.File, "Path of the .keploy bundle")
+		cmd.Flags().StringSliceP("testsets", "t", c.cfg.Bundle.TestSets, "Testsets to pack or unpack e.g. --testsets \"test-set-1, test-set-2\", all the testsets and reports by default")
+	case "contract export":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().StringSliceP("testsets", "t", c.cfg.Contract.TestSets, "Testsets to export the mocks of e.g. --testsets \"test-set-1, test-set-2\"")
+		cmd.Flags().String("contracts", c.cfg.Contract.Path, "Path to the directory where the contracts are stored")
+		cmd.Flags().String("service", c.cfg.Contract.Service, "Name of the consumer service exporting the contracts")
+	case "contract verify":
+		cmd.Flags().String("contracts", c.cfg.Contract.Path, "Path to the directory where the contracts are stored")
+		cmd.Flags().String("provider", c.cfg.Contract.Provider, "Name of the provider service to verify the contracts of, all the providers are verified by default")
+		cmd.Flags().String("baseUrl", c.cfg.Contract.BaseURL, "Base URL of the provider service, defaults to the recorded host")
+	case "export":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().String("format", "openapi", "Format to export the testcases to (openapi/postman)")
+		cmd.Flags().StringP("output", "o", "", "Path of the exported file, the file extension (.yaml/.json) decides the encoding")
+		cmd.Flags().StringSliceP("testsets", "t", []string{}, "Testsets to export e.g. --testsets \"test-set-1, test-set-2\"")
 	case "mock":
 		cmd.Flags().StringP("path", "p", c.cfg.Path, "Path to local directory where generated testcases/mocks are stored")
 		cmd.Flags().Bool("record", false, "Record all outgoing network traffic")
@@ -184,11 +340,34 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 			utils.LogError(c.logger, err, errMsg)
 			return errors.New(errMsg)
 		}
-	case "record", "test":
+	case "record pause", "record resume":
+		cmd.Flags().Uint32("annotatePort", c.cfg.Record.AnnotatePort, "Localhost port of the api of the recording session, as given to keploy record")
+	case "mock serve":
+		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
+		cmd.Flags().Uint32("port", c.cfg.MockServer.Port, "Port the mocks are served on")
+		cmd.Flags().StringP("testSet", "t", c.cfg.MockServer.TestSet, "Test set whose mocks are served, the mocks of all the test sets by default")
+	case "record", "test", "agent", "k8s record", "k8s test":
 		cmd.Flags().String("configPath", ".", "Path to the local directory where keploy configuration file is stored")
+		cmd.Flags().String("profile", c.cfg.Profile, "Profile of the config file whose values are overlaid onto the base config e.g. --profile ci")
 		cmd.Flags().StringP("rerecord", "r", c.cfg.ReRecord, "Rerecord the testcases/mocks for the given testset(s)")
 		cmd.Flags().StringP("path", "p", ".", "Path to local directory where generated testcases/mocks are stored")
-		cmd.Flags().Uint32("port", c.cfg.Port, "GraphQL server port used for executing testcases in unit test library integration")
+		if cmd.Name() == "agent" {
+			cmd.Flags().Uint32("port", c.cfg.Agent.Port, "Port of the gRPC control plane of the agent, it only listens on 127.0.0.1 unless the agent token is set")
+			cmd.Flags().String("socket", c.cfg.Agent.Socket, "Path of the unix socket of the agent for keploy record and test run with --daemon")
+		} else {
+			if inK8s(cmd) {
+				cmd.Flags().String("bucket", c.cfg.Remote.Bucket, "Name of the S3-compatible bucket to sync the test sets with, they are kept in the keploy directory by default")
+			} else {
+				cmd.Flags().Bool("daemon", c.cfg.Daemon, "Run through keploy agent started with sudo, so that sudo isn't needed to run this command")
+			}
+			cmd.Flags().Uint32("port", c.cfg.Port, "GraphQL server port used for executing testcases in unit test library integration")
+			err = cmd.Flags().MarkHidden("port")
+			if err != nil {
+				errMsg := "failed to mark port as hidden flag"
+				utils.LogError(c.logger, err, errMsg)
+				return errors.New(errMsg)
+			}
+		}
 		cmd.Flags().Uint32("proxyPort", c.cfg.ProxyPort, "Port used by the Keploy proxy server to intercept the outgoing dependency calls")
 		cmd.Flags().Uint32("dnsPort", c.cfg.DNSPort, "Port used by the Keploy DNS server to intercept the DNS queries")
 		cmd.Flags().StringP("command", "c", c.cfg.Command, "Command to start the user application")
@@ -198,31 +377,72 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 		cmd.Flags().StringP("networkName", "n", c.cfg.NetworkName, "Name of the application's docker network")
 		cmd.Flags().UintSlice("passThroughPorts", config.GetByPassPorts(c.cfg), "Ports to bypass the proxy server and ignore the traffic")
 		cmd.Flags().Bool("generateGithubActions", c.cfg.GenerateGithubActions, "Generate Github Actions workflow file")
-		err = cmd.Flags().MarkHidden("port")
-		if err != nil {
-			errMsg := "failed to mark port as hidden flag"
-			utils.LogError(c.logger, err, errMsg)
-			return errors.New(errMsg)
-		}
+		cmd.Flags().String("redirect", c.cfg.Redirect, "How the outgoing calls of the app are redirected to the proxy (auto/ebpf/iptables/proxy), iptables needs only CAP_NET_ADMIN and CAP_NET_RAW but supports only keploy test, proxy passes HTTP_PROXY to the app on macOS and Windows")
+		cmd.Flags().String("btfPath", c.cfg.BTFPath, "Path to the BTF of the kernel for the eBPF hooks, on the kernels without /sys/kernel/btf/vmlinux e.g. from btfhub-archive")
+		cmd.Flags().Int64("randomSeed", c.cfg.RandomSeed, "Seed the getrandom and /dev/urandom of the app to make the generated ids reproducible (native apps only, 0 disables it)")
+		cmd.Flags().String("protoDir", c.cfg.ProtoDir, "Directory of the .proto files or descriptor sets of the grpc services called by the app, to write their mocks as readable JSON")
 		if cmd.Name() == "test" {
 			cmd.Flags().StringSliceP("testsets", "t", utils.Keys(c.cfg.Test.SelectedTests), "Testsets to run e.g. --testsets \"test-set-1, test-set-2\"")
 			cmd.Flags().Uint64P("delay", "d", 5, "User provided time to run its application")
+			cmd.Flags().String("readinessURL", c.cfg.Test.ReadinessURL, "Health endpoint of the application polled until it responds with 2xx, the testcases are run as soon as it is ready instead of after the delay")
+			cmd.Flags().Uint32("readinessPort", c.cfg.Test.ReadinessPort, "Port of the application polled until it accepts connections, the testcases are run as soon as it is ready instead of after the delay")
+			cmd.Flags().Duration("readinessTimeout", c.cfg.Test.ReadinessTimeout, "How long the application is polled for its readiness before the test set is failed")
 			cmd.Flags().Uint64("apiTimeout", c.cfg.Test.APITimeout, "User provided timeout for calling its application")
 			cmd.Flags().String("mongoPassword", c.cfg.Test.MongoPassword, "Authentication password for mocking MongoDB conn")
 			cmd.Flags().String("coverageReportPath", c.cfg.Test.CoverageReportPath, "Write a go coverage profile to the file in the given directory.")
 			cmd.Flags().StringP("language", "l", c.cfg.Test.Language, "application programming language")
 			cmd.Flags().Bool("ignoreOrdering", c.cfg.Test.IgnoreOrdering, "Ignore ordering of array in response")
+			cmd.Flags().Bool("hashAssets", c.cfg.Test.HashAssets, "Compare the inline scripts and styles of the html responses by their sha256 only")
+			cmd.Flags().StringToString("arrayMatching", c.cfg.Test.ArrayMatching, "How the elements of the arrays of the response bodies are matched by the array field (ordered/unordered/subset/length/key:<field>) e.g. --arrayMatching data.items=key:id,tags=unordered")
 			cmd.Flags().Bool("coverage", c.cfg.Test.Coverage, "Enable coverage reporting for the testcases. for golang please set language flag to golang, ref https://keploy.io/docs/server/sdk-installation/go/")
 			cmd.Flags().Bool("removeUnusedMocks", c.cfg.Test.RemoveUnusedMocks, "Clear the unused mocks for the passed test-sets")
 			cmd.Flags().Bool("goCoverage", c.cfg.Test.GoCoverage, "Enable go coverage reporting for the testcases")
+			cmd.Flags().String("changed", c.cfg.Test.Changed, "Run only the test sets and testcases whose coverage, stored by the runs with goCoverage, covers the files changed or untracked since the given git revision (HEAD if none)")
+			cmd.Flags().Lookup("changed").NoOptDefVal = "HEAD"
+			cmd.Flags().StringSlice("tags", c.cfg.Test.Tags, "Run only the testcases with any of the tags e.g. --tags smoke,critical")
 			cmd.Flags().Bool("fallBackOnMiss", c.cfg.Test.FallBackOnMiss, "Enable connecting to actual service if mock not found during test mode")
+			cmd.Flags().Bool("strictMocking", c.cfg.Test.StrictMocking, "Fail the testcase on an outgoing call which doesn't match any mock and isn't bypassed, instead of passing it through to the actual service")
+			cmd.Flags().Bool("update", c.cfg.Test.Update, "Update the expected response of the failed testcases with the actual response")
+			cmd.Flags().Float64("simulateLatency", c.cfg.Test.SimulateLatency, "Delay the http, grpc and mongo mock responses by their recorded latency times the given multiplier e.g. 1.5 (0 disables it)")
+			cmd.Flags().Int("streamChunkSize", c.cfg.Test.StreamChunkSize, "Stream the http mock bodies to the app in parts of this many bytes, sending the recorded chunked bodies chunked (0 writes them at once)")
+			cmd.Flags().String("conditional", c.cfg.Test.Conditional, "Serve the recorded http mock responses to the conditional requests (recorded), or a 304 if they are still valid for the If-None-Match or If-Modified-Since of the app (revalidate)")
+			cmd.Flags().Bool("oauthTokens", c.cfg.Test.OAuthTokens, "Match the oauth2 token requests of the app to the http mocks by their grant type, reissue the recorded jwts of their responses so they aren't expired, signed with keys of keploy added to the recorded jwks of the issuers, and match the requests with a bearer jwt by its claims. The jwts signed with a secret (HS*) are kept as recorded")
+			cmd.Flags().Bool("strictMockWindow", c.cfg.Test.StrictMockWindow, "Bind each testcase to the mocks recorded within its request and response window, so the adjacent testcases can't consume each other's mocks")
+			cmd.Flags().Duration("mockWindowSlack", c.cfg.Test.MockWindowSlack, "Widen the window of the testcases by this slack on both sides with --strictMockWindow")
+			cmd.Flags().String("annotations", c.cfg.Test.Annotations, "Annotate the failed testcases inline in the pull requests, with github workflow commands (github) or a gitlab code quality report (gitlab), auto detects the CI")
+			cmd.Flags().Bool("freezeTime", c.cfg.Test.FreezeTime, "Freeze the time of the app at the recorded time of each testcase using libfaketime (native apps only)")
+			cmd.Flags().String("freezeTimeLib", c.cfg.Test.FreezeTimeLib, "Path of libfaketime used to freeze the time of the app")
+			cmd.Flags().Bool("captureAppLogs", c.cfg.Test.CaptureAppLogs, "Attach the stdout and stderr of the app during each testcase to its test result in the report")
+			cmd.Flags().Bool("tui", c.cfg.Test.TUI, "Show the live progress of the testcases, their outgoing calls with the mocks they matched and the pass and fail tally in the terminal, writing the logs only to keploy-logs.txt")
 		} else {
 			cmd.Flags().Uint64("recordTimer", 0, "User provided time to record its application")
+			cmd.Flags().Uint64("maxMockFileSize", c.cfg.Record.MaxMockFileSize, "Size in MB past which the mocks file of a test set is rotated into numbered shards (0 disables it)")
+			cmd.Flags().String("mockFormat", c.cfg.Record.MockFormat, "Format to record the mocks in (yaml/protobuf), protobuf mocks are faster to load for large mock files")
+			cmd.Flags().Uint32("ingressPort", c.cfg.Record.IngressPort, "Port keploy receives the calls to the app on with --redirect proxy, forwarding them to the app to record them")
+			cmd.Flags().Uint32("appPort", c.cfg.Record.AppPort, "Port of the app the calls are forwarded to with --redirect proxy")
+			cmd.Flags().Uint32("annotatePort", c.cfg.Record.AnnotatePort, "Localhost port of the api naming and describing the next captured testcase, POST /keploy/annotate {name, description, tags}, and pausing and resuming the recording, POST /keploy/pause and /keploy/resume (0 disables it)")
+			cmd.Flags().StringSlice("sourceIPs", c.cfg.Record.SourceIPs, "Record only the requests from the client ips or cidrs e.g. --sourceIPs 10.1.2.3,10.8.0.0/16")
+			cmd.Flags().Bool("captureUserAgent", c.cfg.Record.CaptureUserAgent, "Add the user agent of the client to the captured testcases")
+			cmd.Flags().Float64("sampleRate", c.cfg.Record.SampleRate, "Fraction of the requests recorded e.g. 0.1 (0 records all of them)")
+			cmd.Flags().Int("maxPerEndpoint", c.cfg.Record.MaxPerEndpoint, "Maximum testcases recorded per method and path, with the ids in the path collapsed (0 disables it)")
+			cmd.Flags().Int("reservoir", c.cfg.Record.Reservoir, "Record a uniform sample of this many testcases of each test set, inserted when the session ends or the test set is rotated (0 disables it)")
+			cmd.Flags().Bool("continuous", c.cfg.Record.Continuous, "Record until stopped into a new test set every --rotation, always redacting the sensitive headers")
+			cmd.Flags().Duration("rotation", c.cfg.Record.Rotation, "Period of the test sets of the continuous recording e.g. 1h or 24h")
+			cmd.Flags().Uint64("maxDiskSize", c.cfg.Record.MaxDiskSize, "Size in MB of the keploy directory past which the continuous recording deletes the oldest test sets it recorded (0 disables it)")
+			cmd.Flags().Bool("tlsUprobes", c.cfg.Record.TLSUprobes, "Record the TLS calls of the native app from their plaintext captured with uprobes on SSL_read and SSL_write, instead of decrypting them in the proxy")
+			cmd.Flags().StringSlice("tlsLibraries", c.cfg.Record.TLSLibraries, "Libraries or binaries of the app linking OpenSSL or BoringSSL to attach the TLS uprobes to, besides the libssl of the distribution and of the node or python running the app")
+			cmd.Flags().String("javaAgent", c.cfg.Record.JavaAgent, "Path of the keploy JSSE agent jar passed to the JVM of the app, to record its TLS calls without trusting the CA of keploy; keploy-jsse-agent.jar is shipped next to the keploy binary")
+			cmd.Flags().Bool("tui", c.cfg.Record.TUI, "Show the captured testcases and mocks in the terminal, with d discarding the last testcase and q stopping the recording, writing the logs only to keploy-logs.txt")
+			if !inK8s(cmd) {
+				cmd.Flags().StringArray("app", nil, "Native app to record together with the others instead of -c, as name=\"command\" e.g. --app svc-a=\"./svc-a\" --app svc-b=\"node b.js\", its testcases are recorded into <path>/<name>/keploy")
+			}
 		}
 	case "keploy":
 		cmd.PersistentFlags().Bool("debug", c.cfg.Debug, "Run in debug mode")
 		cmd.PersistentFlags().Bool("disableTele", c.cfg.DisableTele, "Run in telemetry mode")
 		cmd.PersistentFlags().Bool("disableANSI", c.cfg.DisableANSI, "Disable ANSI color in logs")
+		cmd.PersistentFlags().StringSlice("telemetryEvents", c.cfg.TelemetryEvents, "Categories of the telemetry events sent: ping, runs, records and protocols (the kinds of the recorded mocks), all of them if empty")
+		cmd.PersistentFlags().String("telemetryLog", c.cfg.TelemetryLog, "JSONL file the telemetry events are written to instead of being sent over the network")
 		err = cmd.PersistentFlags().MarkHidden("disableTele")
 		if err != nil {
 			errMsg := "failed to mark telemetry as hidden flag"
@@ -243,15 +463,234 @@ func (c *CmdConfigurator) AddFlags(cmd *cobra.Command) error {
 }
 
 func (c *CmdConfigurator) Validate(ctx context.Context, cmd *cobra.Command) error {
+	err := c.ValidateFlags(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	// the iptables redirect, the proxy mode and the clients of keploy agent don't load the eBPF hooks
+	if c.cfg.Redirect == "iptables" || c.cfg.Redirect == "proxy" || c.cfg.Daemon {
+		return nil
+	}
+
 	//check if the version of the kernel is above 5.15 for eBPF support
-	isValid := kernel.CheckKernelVersion(5, 15, 0)
-	if !isValid {
-		errMsg := "Kernel version is below 5.15. Keploy requires kernel version 5.15 or above"
+	kernel := hooks.CheckKernel()
+	if kernel.Err != nil {
+		utils.LogError(c.logger, kernel.Err, "the kernel doesn't support the eBPF hooks", zap.String("found", kernel.Detail), zap.String("fix", kernel.Hint))
+		return kernel.Err
+	}
+	return nil
+}
+
+// inferCommand infers the command of the app from the files of the working directory when it isn't passed with -c:
+// docker compose with the container of the app if it has a compose file, or else the command running the app of its
+// language natively. The go apps are tested with their coverage. It reports whether the command was inferred, the
+// command of the apps with only a Dockerfile is suggested as their image has to be built first.
+func (c *CmdConfigurator) inferCommand(cmd *cobra.Command) bool {
+	// the files of the app aren't in the container of keploy
+	if c.cfg.InDocker {
+		return false
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return false
+	}
+	app := utils.DetectApp(dir)
+	switch {
+	case app.Compose != "":
+		c.cfg.Command = "docker compose -f " + app.Compose + " up"
+		if c.cfg.ContainerName == "" {
+			c.cfg.ContainerName = app.Container
+		}
+	case app.Native != "":
+		c.cfg.Command = app.Native
+		if cmd.Name() == "test" && app.Language == "golang" {
+			c.cfg.Command = "go run -cover ."
+			c.cfg.Test.GoCoverage = true
+		}
+	case app.Dockerfile:
+		name := strings.ToLower(filepath.Base(dir))
+		c.logger.Info(fmt.Sprintf(`the app has a Dockerfile, build its image with "docker build -t %[1]s ." and run keploy %[2]s -c "docker run -p %[3]d:%[3]d --name %[1]s %[1]s" --containerName %[1]s`, name, cmd.Name(), app.Port))
+		return false
+	default:
+		return false
+	}
+	if cmd.Name() == "test" && c.cfg.Test.Language == "" {
+		c.cfg.Test.Language = app.Language
+	}
+	c.logger.Info("inferred the command of the app from the working directory, pass it with -c otherwise", zap.String("command", c.cfg.Command), zap.String("language", app.Language))
+	return true
+}
+
+// parseApps reads the apps recorded together from --app name="command", which replace the apps of the config file,
+// and checks them.
+func (c *CmdConfigurator) parseApps(cmd *cobra.Command) error {
+	flags, err := cmd.Flags().GetStringArray("app")
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to read the apps to record")
+		return err
+	}
+	if len(flags) > 0 {
+		c.cfg.Record.Apps = nil
+	}
+	for _, f := range flags {
+		name, command, ok := strings.Cut(f, "=")
+		if !ok {
+			errMsg := fmt.Sprintf("invalid app %q, expected name=\"command\"", f)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		c.cfg.Record.Apps = append(c.cfg.Record.Apps, config.App{Name: strings.TrimSpace(name), Command: strings.TrimSpace(command)})
+	}
+	if len(c.cfg.Record.Apps) == 0 {
+		return nil
+	}
+
+	var errMsg string
+	switch {
+	case c.cfg.Command != "":
+		errMsg = "set either the command of the app with -c or the apps to record together with --app"
+	case c.cfg.Daemon:
+		errMsg = "the apps recorded together can't be run through keploy agent"
+	case c.cfg.ReRecord != "":
+		errMsg = "the testcases of the apps recorded together can't be re-recorded"
+	case c.cfg.Storage.Driver != "" && c.cfg.Storage.Driver != "yaml":
+		errMsg = fmt.Sprintf("the apps recorded together are only stored in yaml, not %s", c.cfg.Storage.Driver)
+	}
+	seen := map[string]bool{}
+	for _, a := range c.cfg.Record.Apps {
+		if errMsg != "" {
+			break
+		}
+		switch {
+		case a.Name == "" || a.Name == "." || a.Name == ".." || strings.ContainsAny(a.Name, `/\`):
+			errMsg = fmt.Sprintf("invalid name %q of the app, it names the directory its testcases are recorded into", a.Name)
+		case seen[a.Name]:
+			errMsg = fmt.Sprintf("the app %s is set twice", a.Name)
+		case a.Command == "":
+			errMsg = fmt.Sprintf("missing the command of the app %s", a.Name)
+		case utils.FindDockerCmd(a.Command) != utils.Native:
+			errMsg = fmt.Sprintf("the app %s isn't native, only the native apps are recorded together, run the docker apps together with docker compose", a.Name)
+		}
+		seen[a.Name] = true
+	}
+	if errMsg != "" {
+		utils.LogError(c.logger, nil, errMsg)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
+// validateUDP checks the UDP rules, the datagrams are intercepted with iptables for the native apps on linux.
+func (c *CmdConfigurator) validateUDP() error {
+	if len(c.cfg.UDP) == 0 {
+		return nil
+	}
+	var errMsg string
+	switch {
+	case c.cfg.Redirect == "proxy":
+		errMsg = "the UDP datagrams of the app can't be intercepted in the proxy mode"
+	case c.cfg.Command != "" && utils.CmdType(c.cfg.CommandType) != utils.Native:
+		errMsg = "only the UDP datagrams of the native apps are intercepted"
+	}
+	seen := map[uint32]bool{}
+	for _, rule := range c.cfg.UDP {
+		if errMsg != "" {
+			break
+		}
+		switch {
+		case rule.Port == 0 || rule.Port > 65535:
+			errMsg = fmt.Sprintf("invalid UDP port %d", rule.Port)
+		case rule.Port == 53:
+			errMsg = "the DNS queries are already answered by keploy, remove the UDP rule of the port 53"
+		case seen[rule.Port]:
+			errMsg = fmt.Sprintf("the UDP port %d is set twice", rule.Port)
+		case rule.Mode != config.UDPPassThrough && rule.Mode != config.UDPMock && rule.Mode != config.UDPDrop:
+			errMsg = fmt.Sprintf("invalid mode %q of the UDP port %d, expected passThrough, mock or drop", rule.Mode, rule.Port)
+		}
+		seen[rule.Port] = true
+	}
+	if errMsg != "" {
+		utils.LogError(c.logger, nil, errMsg)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
+// resolveRedirect resolves the auto redirect to the eBPF hooks if keploy has their capabilities, and to iptables
+// otherwise, and checks that keploy has the capabilities of the redirect. Outside linux only the proxy mode works.
+func (c *CmdConfigurator) resolveRedirect(cmd *cobra.Command) error {
+	if runtime.GOOS != "linux" && c.cfg.Redirect != "proxy" {
+		if c.cfg.Redirect != "auto" {
+			errMsg := fmt.Sprintf("the %s redirect is only supported on linux, use --redirect proxy on %s", c.cfg.Redirect, runtime.GOOS)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		c.logger.Warn(fmt.Sprintf("the eBPF hooks aren't supported on %s, falling back to the proxy mode", runtime.GOOS))
+		c.cfg.Redirect = "proxy"
+	}
+	if c.cfg.Redirect == "proxy" {
+		if cmd.Name() == "record" && (c.cfg.Record.IngressPort == 0 || c.cfg.Record.AppPort == 0) {
+			errMsg := "recording in the proxy mode needs the port keploy receives the calls to the app on and the port of the app, set them with --ingressPort and --appPort"
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		return nil
+	}
+
+	ebpfMissing, err := utils.MissingCapabilities(utils.EBPFCapabilities...)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to read the capabilities of keploy")
+		return err
+	}
+	iptablesMissing, err := utils.MissingCapabilities(utils.IptablesCapabilities...)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to read the capabilities of keploy")
+		return err
+	}
+
+	switch c.cfg.Redirect {
+	case "auto":
+		c.cfg.Redirect = "ebpf"
+		if len(ebpfMissing) > 0 && len(iptablesMissing) == 0 {
+			c.logger.Warn("keploy doesn't have the capabilities of the eBPF hooks, falling back to the iptables redirect", zap.Strings("missing", ebpfMissing))
+			c.cfg.Redirect = "iptables"
+		}
+	case "ebpf", "iptables":
+	default:
+		errMsg := fmt.Sprintf("invalid redirect: %s, expected auto, ebpf, iptables or proxy", c.cfg.Redirect)
+		utils.LogError(c.logger, nil, errMsg)
+		return errors.New(errMsg)
+	}
+
+	missing := ebpfMissing
+	if c.cfg.Redirect == "iptables" {
+		missing = iptablesMissing
+		if cmd.Name() == "record" {
+			errMsg := "recording the testcases needs the eBPF hooks, the iptables redirect only supports keploy test"
+			utils.LogError(c.logger, nil, errMsg, zap.Strings("missing capabilities", ebpfMissing))
+			return errors.New(errMsg)
+		}
+	}
+	if len(missing) > 0 {
+		errMsg := fmt.Sprintf("keploy is missing the capabilities %s of the %s redirect, run it with sudo or grant them e.g. with setcap", strings.Join(missing, ", "), c.cfg.Redirect)
 		utils.LogError(c.logger, nil, errMsg)
 		return errors.New(errMsg)
 	}
+	return nil
+}
 
-	return c.ValidateFlags(ctx, cmd)
+// applyProfile overlays the values of the profile in the profiles section of the config file onto the base config.
+// The flags still take precedence over them.
+func applyProfile(name string) error {
+	profile, ok := viper.GetStringMap("profiles")[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("profile %q not found in the profiles of the config file", name)
+	}
+	values, ok := profile.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("profile %q of the config file is not a map of the config values", name)
+	}
+	return viper.MergeConfigMap(values)
 }
 
 func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command) error {
@@ -268,13 +707,35 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 	viper.SetEnvPrefix("KEPLOY")
 
 	//used to bind flags specific to the command for eg: testsets, delay, recordTimer etc. (nested flags)
-	err = utils.BindFlagsToViper(c.logger, cmd, "")
+	// the flags of the subcommands are nested under the parent command for eg: contract.provider
+	viperKeyPrefix := ""
+	if cmd.HasParent() && cmd.Parent().Name() != "keploy" {
+		viperKeyPrefix = cmd.Parent().Name()
+	}
+	// push and pull share the config of the remote bucket
+	if cmd.Name() == "push" || cmd.Name() == "pull" {
+		viperKeyPrefix = "remote"
+	}
+	if cmdName(cmd) == "mock serve" {
+		viperKeyPrefix = "mockServer"
+	}
+	if cmd.Name() == "replay-ingress" {
+		viperKeyPrefix = "ingress"
+	}
+	if cmd.Name() == "update" {
+		viperKeyPrefix = "selfUpdate"
+	}
+	// keploy k8s record and test share the config of keploy record and test
+	if cmdName(cmd) == "k8s record" || cmdName(cmd) == "k8s test" {
+		viperKeyPrefix = cmd.Name()
+	}
+	err = utils.BindFlagsToViper(c.logger, cmd, viperKeyPrefix)
 	if err != nil {
 		errMsg := "failed to bind cmd specific flags to viper"
 		utils.LogError(c.logger, err, errMsg)
 		return errors.New(errMsg)
 	}
-	if cmd.Name() == "test" || cmd.Name() == "record" {
+	if cmd.Name() == "test" || cmd.Name() == "record" || cmd.Name() == "ui" || cmd.Name() == "agent" || cmd.Name() == "replay-ingress" {
 		configPath, err := cmd.Flags().GetString("configPath")
 		if err != nil {
 			utils.LogError(c.logger, nil, "failed to read the config path")
@@ -292,12 +753,22 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 			}
 			c.logger.Info("config file not found; proceeding with flags only")
 		}
+		if profile := viper.GetString("profile"); profile != "" {
+			if err := applyProfile(profile); err != nil {
+				utils.LogError(c.logger, err, "failed to apply the config profile", zap.String("profile", profile))
+				return err
+			}
+			c.logger.Info("using the config profile", zap.String("profile", profile))
+		}
 	}
 	if err := viper.Unmarshal(c.cfg); err != nil {
 		errMsg := "failed to unmarshal the config"
 		utils.LogError(c.logger, err, errMsg)
 		return errors.New(errMsg)
 	}
+	if unset := config.ExpandEnv(c.cfg); len(unset) > 0 {
+		c.logger.Warn("the environment variables used in the config are not set, they are replaced with empty values", zap.Strings("variables", unset))
+	}
 	if c.cfg.Debug {
 		logger, err := log.ChangeLogLevel(zap.DebugLevel)
 		*c.logger = *logger
@@ -331,10 +802,110 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		c.logger.Info("Color encoding is disabled")
 	}
 
+	if inK8s(cmd) {
+		bucket, err := cmd.Flags().GetString("bucket")
+		if err != nil {
+			errMsg := "failed to read the bucket"
+			utils.LogError(c.logger, err, errMsg)
+			return errors.New(errMsg)
+		}
+		if bucket != "" {
+			c.cfg.Remote.Bucket = bucket
+		}
+	}
+
+	for _, category := range c.cfg.TelemetryEvents {
+		switch category {
+		case config.TelemetryPing, config.TelemetryRuns, config.TelemetryRecords, config.TelemetryProtocols:
+		default:
+			errMsg := fmt.Sprintf("invalid telemetry event category: %s, expected ping, runs, records or protocols", category)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+	}
+	if c.cfg.TelemetryLog != "" {
+		c.cfg.TelemetryLog, err = utils.GetAbsPath(c.cfg.TelemetryLog)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path of the telemetry log")
+			return errors.New("failed to get the absolute path")
+		}
+	}
+
+	if c.cfg.ProtoDir != "" {
+		c.cfg.ProtoDir, err = utils.GetAbsPath(c.cfg.ProtoDir)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path of the proto directory")
+			return errors.New("failed to get the absolute path")
+		}
+	}
+
 	c.logger.Debug("config has been initialised", zap.Any("for cmd", cmd.Name()), zap.Any("config", c.cfg))
 
-	switch cmd.Name() {
-	case "record", "test":
+	switch cmdName(cmd) {
+	case "contract export", "contract verify":
+		if cmd.Name() == "export" {
+			absPath, err := utils.GetAbsPath(c.cfg.Path)
+			if err != nil {
+				utils.LogError(c.logger, err, "error while getting absolute path")
+				return errors.New("failed to get the absolute path")
+			}
+			c.cfg.Path = absPath + "/keploy"
+		}
+		absPath, err := utils.GetAbsPath(c.cfg.Contract.Path)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path of the contracts")
+			return errors.New("failed to get the absolute path")
+		}
+		c.cfg.Contract.Path = absPath
+	case "bundle pack", "bundle unpack":
+		absPath, err := utils.GetAbsPath(c.cfg.Path)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path")
+			return errors.New("failed to get the absolute path")
+		}
+		c.cfg.Path = absPath + "/keploy"
+		c.cfg.Bundle.File, err = utils.GetAbsPath(c.cfg.Bundle.File)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path of the bundle")
+			return errors.New("failed to get the absolute path")
+		}
+	case "analyze":
+		absPath, err := utils.GetAbsPath(c.cfg.Path)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path")
+			return errors.New("failed to get the absolute path")
+		}
+		c.cfg.Path = absPath + "/keploy"
+		if c.cfg.Analyze.Plan != "" {
+			c.cfg.Analyze.Plan, err = utils.GetAbsPath(c.cfg.Analyze.Plan)
+			if err != nil {
+				utils.LogError(c.logger, err, "error while getting absolute path of the prune plan")
+				return errors.New("failed to get the absolute path")
+			}
+		}
+	case "generate", "export", "import", "load", "push", "pull", "migrate", "lint", "ui", "mock serve", "replay-ingress", "report diff", "report comment", "approve", "explain", "tag", "mock edit":
+		absPath, err := utils.GetAbsPath(c.cfg.Path)
+		if err != nil {
+			utils.LogError(c.logger, err, "error while getting absolute path")
+			return errors.New("failed to get the absolute path")
+		}
+		c.cfg.Path = absPath + "/keploy"
+	case "update":
+		switch c.cfg.SelfUpdate.Channel {
+		case config.UpdateStable, config.UpdateBeta, config.UpdateNightly:
+		default:
+			errMsg := fmt.Sprintf("invalid release channel: %s, expected stable, beta or nightly", c.cfg.SelfUpdate.Channel)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		if c.cfg.SelfUpdate.Offline != "" {
+			c.cfg.SelfUpdate.Offline, err = utils.GetAbsPath(c.cfg.SelfUpdate.Offline)
+			if err != nil {
+				utils.LogError(c.logger, err, "error while getting absolute path of the offline release")
+				return errors.New("failed to get the absolute path")
+			}
+		}
+	case "record", "test", "agent", "k8s record", "k8s test":
 		bypassPorts, err := cmd.Flags().GetUintSlice("passThroughPorts")
 		if err != nil {
 			errMsg := "failed to read the ports of outgoing calls to be ignored"
@@ -343,7 +914,23 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		}
 		config.SetByPassPorts(c.cfg, bypassPorts)
 
-		if c.cfg.Command == "" {
+		if c.cfg.Record.MockFormat != yaml.FormatYaml && c.cfg.Record.MockFormat != yaml.FormatProtobuf {
+			errMsg := fmt.Sprintf("invalid mock format: %s, expected yaml or protobuf", c.cfg.Record.MockFormat)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+
+		recordApps := false
+		if cmd.Name() == "record" && !inK8s(cmd) {
+			err = c.parseApps(cmd)
+			if err != nil {
+				return err
+			}
+			recordApps = len(c.cfg.Record.Apps) > 0
+		}
+
+		// the command of keploy agent is optional, its clients send theirs, and keploy k8s attaches to the app of the pod
+		if c.cfg.Command == "" && !recordApps && cmd.Name() != "agent" && !inK8s(cmd) && !c.inferCommand(cmd) {
 			utils.LogError(c.logger, nil, "missing required -c flag or appCmd in config file")
 			if c.cfg.InDocker {
 				c.logger.Info(`Example usage: keploy test -c "docker run -p 8080:8080 --network myNetworkName myApplicationImageName" --delay 6`)
@@ -356,7 +943,7 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		// set the command type
 		c.cfg.CommandType = string(utils.FindDockerCmd(c.cfg.Command))
 
-		if c.cfg.GenerateGithubActions {
+		if c.cfg.GenerateGithubActions && cmd.Name() != "agent" && !inK8s(cmd) {
 			defer utils.GenerateGithubActions(c.logger, c.cfg.Command)
 		}
 		if c.cfg.InDocker {
@@ -402,10 +989,63 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 
 		}
 
-		err = utils.StartInDocker(ctx, c.logger, c.cfg)
+		// keploy agent runs the docker apps of its clients
+		if !c.cfg.Daemon {
+			err = utils.StartInDocker(ctx, c.logger, c.cfg)
+			if err != nil {
+				return err
+			}
+			err = c.resolveRedirect(cmd)
+			if err != nil {
+				return err
+			}
+		}
+		if recordApps && c.cfg.Redirect == "proxy" {
+			errMsg := "the apps recorded together are told apart by their processes with the eBPF hooks, they can't be recorded in the proxy mode"
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		err = c.validateUDP()
 		if err != nil {
 			return err
 		}
+		if c.cfg.Record.SampleRate < 0 || c.cfg.Record.SampleRate > 1 {
+			errMsg := fmt.Sprintf("invalid sample rate %v, expected a fraction of the requests between 0 and 1", c.cfg.Record.SampleRate)
+			utils.LogError(c.logger, nil, errMsg)
+			return errors.New(errMsg)
+		}
+		if c.cfg.Record.Continuous {
+			if recordApps {
+				errMsg := "the continuous recording records a single app, it can't be combined with the apps of the config file"
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+			if c.cfg.Record.Rotation < time.Minute {
+				errMsg := fmt.Sprintf("invalid rotation %v of the continuous recording, expected at least a minute", c.cfg.Record.Rotation)
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+		}
+
+		if cmd.Name() == "record" && c.cfg.Record.TUI {
+			switch {
+			case recordApps:
+				c.logger.Warn("the terminal ui is disabled as the apps of the config file are recorded together")
+				c.cfg.Record.TUI = false
+			case !term.IsTerminal(int(os.Stdout.Fd())):
+				c.logger.Warn("the terminal ui is disabled as the output isn't a terminal")
+				c.cfg.Record.TUI = false
+			default:
+				// the logs would scroll the terminal ui away, they are kept in the log file
+				logger, err := log.ToFileOnly()
+				if err != nil {
+					errMsg := "failed to write the logs to the log file only"
+					utils.LogError(c.logger, err, errMsg)
+					return errors.New(errMsg)
+				}
+				*c.logger = *logger
+			}
+		}
 
 		absPath, err := utils.GetAbsPath(c.cfg.Path)
 		if err != nil {
@@ -414,9 +1054,21 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 		}
 
 		c.cfg.Path = absPath + "/keploy"
+		keployDir := c.cfg.Path
+		if c.cfg.Storage.Driver == "bundle" {
+			// the whole keploy directory is unpacked from the bundle and packed back into it
+			c.cfg.Bundle.TestSets = nil
+			c.cfg.Bundle.File, err = utils.GetAbsPath(c.cfg.Bundle.File)
+			if err != nil {
+				utils.LogError(c.logger, err, "error while getting absolute path of the bundle")
+				return errors.New("failed to get the absolute path")
+			}
+			keployDir = c.cfg.Bundle.File
+		}
 		if cmd.Name() == "test" {
-			//check if the keploy folder exists
-			if _, err := os.Stat(c.cfg.Path); os.IsNotExist(err) {
+			//check if the keploy folder exists, keploy k8s test pulls it from the bucket
+			pulled := inK8s(cmd) && c.cfg.Remote.Bucket != ""
+			if _, err := os.Stat(keployDir); os.IsNotExist(err) && !pulled {
 				recordCmd := models.HighlightGrayString("keploy record")
 				errMsg := fmt.Sprintf("No test-sets found. Please record testcases using %s command", recordCmd)
 				utils.LogError(c.logger, nil, errMsg)
@@ -440,7 +1092,52 @@ func (c *CmdConfigurator) ValidateFlags(ctx context.Context, cmd *cobra.Command)
 				c.cfg.Test.CoverageReportPath = goCovPath
 			}
 
-			if c.cfg.Test.Delay <= 5 {
+			if c.cfg.Test.Conditional != config.ConditionalRecorded && c.cfg.Test.Conditional != config.ConditionalRevalidate {
+				errMsg := fmt.Sprintf("invalid conditional requests handling: %s, expected recorded or revalidate", c.cfg.Test.Conditional)
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+
+			for field, mode := range c.cfg.Test.ArrayMatching {
+				switch {
+				case mode == config.ArrayOrdered, mode == config.ArrayUnordered, mode == config.ArraySubset, mode == config.ArrayLength:
+				case strings.HasPrefix(mode, config.ArrayKeyPrefix) && len(mode) > len(config.ArrayKeyPrefix):
+				default:
+					errMsg := fmt.Sprintf("invalid array matching of %s: %s, expected ordered, unordered, subset, length or key:<field>", field, mode)
+					utils.LogError(c.logger, nil, errMsg)
+					return errors.New(errMsg)
+				}
+			}
+
+			switch c.cfg.Test.Annotations {
+			case "", config.AnnotationsAuto, config.AnnotationsGitHub, config.AnnotationsGitLab:
+			default:
+				errMsg := fmt.Sprintf("invalid annotations: %s, expected github, gitlab or auto", c.cfg.Test.Annotations)
+				utils.LogError(c.logger, nil, errMsg)
+				return errors.New(errMsg)
+			}
+
+			if c.cfg.Test.TUI {
+				if !term.IsTerminal(int(os.Stdout.Fd())) {
+					c.logger.Warn("the terminal ui is disabled as the output isn't a terminal")
+					c.cfg.Test.TUI = false
+				} else {
+					// the logs would scroll the terminal ui away, they are kept in the log file
+					logger, err := log.ToFileOnly()
+					if err != nil {
+						errMsg := "failed to write the logs to the log file only"
+						utils.LogError(c.logger, err, errMsg)
+						return errors.New(errMsg)
+					}
+					*c.logger = *logger
+				}
+			}
+
+			if c.cfg.Test.StrictMocking && c.cfg.Test.FallBackOnMiss {
+				c.logger.Warn("fallBackOnMiss is ignored with strictMocking, the outgoing calls without a mock fail the testcase")
+			}
+
+			if c.cfg.Test.Delay <= 5 && c.cfg.Test.ReadinessURL == "" && c.cfg.Test.ReadinessPort == 0 {
 				c.logger.Warn(fmt.Sprintf("Delay is set to %d seconds, incase your app takes more time to start use --delay to set custom delay", c.cfg.Test.Delay))
 				if c.cfg.InDocker {
 					c.logger.Info(`Example usage: keploy test -c "docker run -p 8080:8080 --network myNetworkName myApplicationImageName" --delay 6`)
@@ -2,22 +2,54 @@ package provider
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/core/envproxy"
 	"go.keploy.io/server/v2/pkg/core/hooks"
 	"go.keploy.io/server/v2/pkg/core/proxy"
+	"go.keploy.io/server/v2/pkg/core/redirect"
 	"go.keploy.io/server/v2/pkg/core/tester"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/mongo"
+	mongoMockDB "go.keploy.io/server/v2/pkg/platform/mongo/mockdb"
+	mongoReportDB "go.keploy.io/server/v2/pkg/platform/mongo/reportdb"
+	mongoTestDB "go.keploy.io/server/v2/pkg/platform/mongo/testdb"
+	"go.keploy.io/server/v2/pkg/platform/protoschema"
+	"go.keploy.io/server/v2/pkg/platform/s3"
 	"go.keploy.io/server/v2/pkg/platform/telemetry"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
 	"go.keploy.io/server/v2/pkg/platform/yaml/configdb"
 	mockdb "go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
 	reportdb "go.keploy.io/server/v2/pkg/platform/yaml/reportdb"
 	testdb "go.keploy.io/server/v2/pkg/platform/yaml/testdb"
 
+	"go.keploy.io/server/v2/pkg/service/agent"
+	"go.keploy.io/server/v2/pkg/service/analyze"
+	"go.keploy.io/server/v2/pkg/service/approve"
+	"go.keploy.io/server/v2/pkg/service/bundle"
+	"go.keploy.io/server/v2/pkg/service/contract"
+	"go.keploy.io/server/v2/pkg/service/doctor"
+	"go.keploy.io/server/v2/pkg/service/explain"
+	"go.keploy.io/server/v2/pkg/service/ingress"
+	"go.keploy.io/server/v2/pkg/service/k8s"
+	"go.keploy.io/server/v2/pkg/service/lint"
+	"go.keploy.io/server/v2/pkg/service/load"
+	"go.keploy.io/server/v2/pkg/service/migrate"
+	"go.keploy.io/server/v2/pkg/service/mockserver"
 	"go.keploy.io/server/v2/pkg/service/record"
+	"go.keploy.io/server/v2/pkg/service/remote"
 	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/pkg/service/report"
 	"go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/pkg/service/ui"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
@@ -28,10 +60,38 @@ type ServiceProvider struct {
 	cfg      *config.Config
 }
 
+// TestDB, MockDB and ReportDB are implemented by the storage drivers of the testcases, mocks and reports, for all
+// the services reading or writing them.
+type TestDB interface {
+	record.TestDB
+	replay.TestDB
+	tools.TestDB
+	ui.TestDB
+}
+
+type MockDB interface {
+	record.MockDB
+	replay.MockDB
+	tools.MockDB
+	analyze.MockDB
+}
+
+type ReportDB interface {
+	replay.ReportDB
+	report.ReportDB
+}
+
+// Storage is the testcases, mocks and reports of the storage driver of the config, encrypted and decrypted with
+// the cipher of its encryption key.
+type Storage struct {
+	TestDB   TestDB
+	MockDB   MockDB
+	ReportDB ReportDB
+	Cipher   *yaml.Cipher
+}
+
 type CommonInternalService struct {
-	YamlTestDB      *testdb.TestYaml
-	YamlMockDb      *mockdb.MockYaml
-	YamlReportDb    *reportdb.TestReport
+	Storage
 	Instrumentation *core.Core
 }
 
@@ -53,24 +113,123 @@ func (n *ServiceProvider) GetTelemetryService(ctx context.Context, config config
 		Version:        utils.Version,
 		GlobalMap:      map[string]interface{}{},
 		InstallationID: installationID,
+		Events:         config.TelemetryEvents,
+		LogFile:        config.TelemetryLog,
 	},
 	), nil
 }
 
-func (n *ServiceProvider) GetCommonServices(config config.Config) *CommonInternalService {
-	h := hooks.NewHooks(n.logger, config)
+func (n *ServiceProvider) GetCommonServices(ctx context.Context, config config.Config) (*CommonInternalService, error) {
+	var h core.Hooks
+	switch config.Redirect {
+	case "iptables":
+		h = redirect.New(n.logger, config)
+	case "proxy":
+		h = envproxy.New(n.logger, config)
+	default:
+		h = hooks.NewHooks(n.logger, config)
+	}
 	p := proxy.New(n.logger, h, config)
 	t := tester.New(n.logger, h) //for keploy test bench
 	instrumentation := core.New(n.logger, h, p, t)
-	testDB := testdb.New(n.logger, config.Path)
-	mockDB := mockdb.New(n.logger, config.Path, "")
-	reportDB := reportdb.New(n.logger, config.Path+"/reports")
+	storage, err := n.GetStorage(ctx, config)
+	if err != nil {
+		return nil, err
+	}
 	return &CommonInternalService{
+		Storage:         *storage,
 		Instrumentation: instrumentation,
-		YamlTestDB:      testDB,
-		YamlMockDb:      mockDB,
-		YamlReportDb:    reportDB,
+	}, nil
+}
+
+// GetStorage returns the testcases, mocks and reports of the storage driver of the config, which all the commands
+// read and write them with.
+func (n *ServiceProvider) GetStorage(ctx context.Context, config config.Config) (*Storage, error) {
+	cipher, err := n.GetCipher(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	storage := &Storage{Cipher: cipher}
+	switch config.Storage.Driver {
+	case "bundle":
+		// the test sets are unpacked into the keploy directory and packed back by the record and test commands
+		if _, err := os.Stat(config.Bundle.File); err == nil {
+			err = bundle.New(n.logger, config).Unpack(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+		fallthrough
+	case "", "yaml":
+		storage.TestDB = testdb.New(n.logger, config.Path, cipher)
+		storage.MockDB = mockdb.New(n.logger, config.Path, "", config.Record.MockFormat, int64(config.Record.MaxMockFileSize)<<20, cipher)
+		storage.ReportDB = reportdb.New(n.logger, config.Path+"/reports", cipher)
+	case "mongo":
+		db, err := mongo.Connect(ctx, config.Storage.URI, config.Storage.Database)
+		if err != nil {
+			utils.LogError(n.logger, err, "failed to connect to the mongo storage")
+			return nil, err
+		}
+		storage.TestDB = mongoTestDB.New(n.logger, db, cipher)
+		storage.MockDB = mongoMockDB.New(n.logger, db, cipher)
+		storage.ReportDB = mongoReportDB.New(n.logger, db, cipher)
+	default:
+		return nil, fmt.Errorf("invalid storage driver: %s, expected yaml, bundle or mongo", config.Storage.Driver)
 	}
+	return storage, nil
+}
+
+// GetCipher returns the cipher the testcases, mocks and reports are encrypted and decrypted with, nil if no key is
+// configured.
+func (n *ServiceProvider) GetCipher(ctx context.Context, config config.Config) (*yaml.Cipher, error) {
+	encodedKey := os.Getenv("KEPLOY_ENCRYPTION_KEY")
+	if config.Encryption.KeyCommand != "" {
+		out, err := exec.CommandContext(ctx, "sh", "-c", config.Encryption.KeyCommand).Output()
+		if err != nil {
+			utils.LogError(n.logger, err, "failed to run the command of the encryption key")
+			return nil, err
+		}
+		encodedKey = string(out)
+	}
+	encodedKey = strings.TrimSpace(encodedKey)
+	if encodedKey == "" {
+		if config.Encryption.Enabled {
+			return nil, errors.New("the encryption is enabled but no key is set, set it with KEPLOY_ENCRYPTION_KEY or encryption.keyCommand")
+		}
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		utils.LogError(n.logger, err, "failed to decode the encryption key, it should be base64 encoded")
+		return nil, err
+	}
+	cipher, err := yaml.NewCipher(key, config.Encryption.Enabled)
+	if err != nil {
+		utils.LogError(n.logger, err, "failed to create the cipher of the encryption key")
+		return nil, err
+	}
+	return cipher, nil
+}
+
+// setupProtoSchemas loads the protobuf schemas the grpc mocks are written as JSON with, if a proto directory is configured.
+func (n *ServiceProvider) setupProtoSchemas(ctx context.Context) error {
+	if n.cfg.ProtoDir == "" {
+		return nil
+	}
+	registry, err := protoschema.Load(ctx, n.logger, n.cfg.ProtoDir)
+	if err != nil {
+		utils.LogError(n.logger, err, "failed to load the protobuf schemas")
+		return err
+	}
+	protoschema.SetRegistry(registry)
+	return nil
+}
+
+// GetServiceWithConfig returns the service of the command run with the config instead of the config of keploy,
+// for the sessions keploy agent runs for its clients.
+func (n *ServiceProvider) GetServiceWithConfig(ctx context.Context, cmd string, cfg config.Config) (interface{}, error) {
+	provider := NewServiceProvider(n.logger, n.configDb, &cfg)
+	return provider.GetService(ctx, cmd)
 }
 
 func (n *ServiceProvider) GetService(ctx context.Context, cmd string) (interface{}, error) {
@@ -79,20 +238,110 @@ func (n *ServiceProvider) GetService(ctx context.Context, cmd string) (interface
 		return nil, err
 	}
 	tel.Ping()
+	err = n.setupProtoSchemas(ctx)
+	if err != nil {
+		return nil, err
+	}
 	switch cmd {
-	case "config", "update":
-		return tools.NewTools(n.logger, tel), nil
+	case "config", "update", "generate", "export", "import", "noise", "tag", "mock edit", "init",
+		"contract", "load", "approve", "explain", "report diff", "report comment", "replay-ingress", "analyze", "mock serve", "ui":
+		storage, err := n.GetStorage(ctx, *n.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return n.storageService(cmd, storage, tel)
+	case "push", "pull":
+		if n.cfg.Remote.Bucket == "" {
+			return nil, errors.New("missing the bucket to sync the test sets with, set it with --bucket or in the config file")
+		}
+		region := n.cfg.Remote.Region
+		if region == "" {
+			region = os.Getenv("AWS_REGION")
+		}
+		storage := s3.New(s3.Options{
+			Bucket:       n.cfg.Remote.Bucket,
+			Region:       region,
+			Endpoint:     n.cfg.Remote.Endpoint,
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		})
+		return remote.New(n.logger, storage, *n.cfg), nil
+	case "bundle":
+		return bundle.New(n.logger, *n.cfg), nil
+	case "migrate":
+		cipher, err := n.GetCipher(ctx, *n.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return migrate.New(n.logger, *n.cfg, cipher), nil
+	case "lint":
+		cipher, err := n.GetCipher(ctx, *n.cfg)
+		if err != nil {
+			return nil, err
+		}
+		return lint.New(n.logger, *n.cfg, cipher), nil
+	case "doctor":
+		return doctor.New(n.logger, *n.cfg), nil
+	case "agent":
+		// the agent creates the record and replay services of its sessions with the provider
+		return agent.New(n.logger, n, *n.cfg), nil
+	case "k8s":
+		// the sidecar and the Job create the record, replay and remote services with the provider
+		return k8s.New(n.logger, n, *n.cfg), nil
 	// TODO: add case for mock
 	case "record", "test", "mock":
-		commonServices := n.GetCommonServices(*n.cfg)
+		commonServices, err := n.GetCommonServices(ctx, *n.cfg)
+		if err != nil {
+			return nil, err
+		}
+		if cmd == "record" && len(n.cfg.Record.Apps) > 0 {
+			if n.cfg.Storage.Driver == "mongo" {
+				return nil, errors.New("the apps are recorded together into the keploy directories of their names, which the mongo storage doesn't support")
+			}
+			// each app is recorded into the keploy directory under the directory of its name
+			appDBs := func(name string) (record.TestDB, record.MockDB) {
+				path := filepath.Join(filepath.Dir(n.cfg.Path), name, "keploy")
+				return testdb.New(n.logger, path, commonServices.Cipher), mockdb.New(n.logger, path, "", n.cfg.Record.MockFormat, int64(n.cfg.Record.MaxMockFileSize)<<20, commonServices.Cipher)
+			}
+			return record.NewApps(n.logger, appDBs, tel, commonServices.Instrumentation, *n.cfg), nil
+		}
 		if cmd == "record" {
-			return record.New(n.logger, commonServices.YamlTestDB, commonServices.YamlMockDb, tel, commonServices.Instrumentation, *n.cfg), nil
+			return record.New(n.logger, commonServices.TestDB, commonServices.MockDB, tel, commonServices.Instrumentation, *n.cfg), nil
 		}
 		if cmd == "test" {
-			return replay.NewReplayer(n.logger, commonServices.YamlTestDB, commonServices.YamlMockDb, commonServices.YamlReportDb, tel, commonServices.Instrumentation, *n.cfg), nil
+			return replay.NewReplayer(n.logger, commonServices.TestDB, commonServices.MockDB, commonServices.ReportDB, tel, commonServices.Instrumentation, *n.cfg), nil
 		}
 		return nil, errors.New("invalid command")
 	default:
 		return nil, errors.New("invalid command")
 	}
 }
+
+// storageService returns the service of the command reading or writing the testcases, mocks and reports of the storage.
+func (n *ServiceProvider) storageService(cmd string, storage *Storage, tel *telemetry.Telemetry) (interface{}, error) {
+	switch cmd {
+	case "contract":
+		// the contracts are the testcases of the consumers in the contract path, not in the storage
+		return contract.New(n.logger, storage.TestDB, storage.MockDB, testdb.New(n.logger, n.cfg.Contract.Path, storage.Cipher), *n.cfg), nil
+	case "load":
+		return load.New(n.logger, storage.TestDB, *n.cfg), nil
+	case "approve":
+		return approve.New(n.logger, storage.TestDB, storage.ReportDB, *n.cfg), nil
+	case "explain":
+		return explain.New(n.logger, storage.TestDB, storage.MockDB, storage.ReportDB, *n.cfg), nil
+	case "report diff", "report comment":
+		return report.New(n.logger, storage.TestDB, storage.ReportDB, *n.cfg), nil
+	case "replay-ingress":
+		return ingress.New(n.logger, storage.TestDB, *n.cfg), nil
+	case "analyze":
+		return analyze.New(n.logger, storage.TestDB, storage.MockDB, storage.ReportDB, *n.cfg), nil
+	case "mock serve":
+		stub := proxy.NewMockServer(n.logger, models.OutgoingOptions{MongoPassword: n.cfg.Test.MongoPassword, IgnoreHeaderKeys: true})
+		return mockserver.New(n.logger, storage.TestDB, storage.MockDB, stub, *n.cfg), nil
+	case "ui":
+		return ui.New(n.logger, storage.TestDB, storage.MockDB, storage.ReportDB, *n.cfg), nil
+	default:
+		return tools.NewTools(n.logger, storage.TestDB, storage.MockDB, tel), nil
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 	recordSvc "go.keploy.io/server/v2/pkg/service/record"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
@@ -14,7 +15,7 @@ func init() {
 	Register("record", Record)
 }
 
-func Record(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+func Record(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
 	var cmd = &cobra.Command{
 		Use:     "record",
 		Short:   "record the keploy testcases from the API calls",
@@ -23,6 +24,17 @@ func Record(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFa
 			return cmdConfigurator.Validate(ctx, cmd)
 		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cfg.Daemon {
+				runOnAgent(ctx, logger, cmd.Name(), cfg)
+				return nil
+			}
+			if cfg.Tracing.Enabled {
+				stopTracing, err := tracing.Init(ctx, logger, cfg.Tracing)
+				if err != nil {
+					return nil
+				}
+				defer stopTracing()
+			}
 			svc, err := serviceFactory.GetService(ctx, cmd.Name())
 			if err != nil {
 				utils.LogError(logger, err, "failed to get service")
@@ -36,6 +48,9 @@ func Record(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFa
 			}
 
 			err = record.Start(ctx)
+			if cfg.Storage.Driver == "bundle" {
+				packBundle(ctx, logger, serviceFactory)
+			}
 			if err != nil {
 				utils.LogError(logger, err, "failed to record")
 				return nil
@@ -50,6 +65,45 @@ func Record(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFa
 		utils.LogError(logger, err, "failed to add record flags")
 		return nil
 	}
+	// the flags of the subcommands are added once they are subcommands, as they are looked up by their full name
+	for _, paused := range []bool{true, false} {
+		pauseCmd := RecordPause(ctx, logger, cfg, cmdConfigurator, paused)
+		cmd.AddCommand(pauseCmd)
+		if err := cmdConfigurator.AddFlags(pauseCmd); err != nil {
+			utils.LogError(logger, err, "failed to add record "+pauseCmd.Name()+" cmd flags")
+			return nil
+		}
+	}
 
 	return cmd
 }
+
+// RecordPause retrieves the command to pause the running recording session, or to resume it, without restarting
+// the app or reloading the hooks
+func RecordPause(ctx context.Context, logger *zap.Logger, cfg *config.Config, cmdConfigurator CmdConfigurator, paused bool) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "pause",
+		Short: "pause the running recording session, the testcases and mocks captured are dropped until it is resumed",
+		Example: `keploy record pause
+kill -USR1 <pid of keploy record>`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			err := recordSvc.SetPaused(ctx, logger, cfg.Record.AnnotatePort, paused)
+			if err != nil {
+				utils.LogError(logger, err, "failed to "+cmd.Name()+" the recording")
+			}
+			return nil
+		},
+	}
+	if !paused {
+		cmd.Use = "resume"
+		cmd.Short = "resume the paused recording session"
+		cmd.Example = `keploy record resume
+kill -USR2 <pid of keploy record>`
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	remoteSvc "go.keploy.io/server/v2/pkg/service/remote"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("push", Push)
+	Register("pull", Pull)
+}
+
+// Push retrieves the command to push the test sets, mocks and reports to an S3-compatible bucket
+func Push(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "push",
+		Short:   "push the test sets, mocks and reports to an S3-compatible bucket as a new version",
+		Example: `keploy push --bucket my-bucket --prefix order-service --version $(git rev-parse --short HEAD)`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			remote, ok := getRemoteService(ctx, logger, serviceFactory, cmd.Name())
+			if !ok {
+				return nil
+			}
+			err := remote.Push(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to push the test sets")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add push cmd flags")
+		return nil
+	}
+	return cmd
+}
+
+// Pull retrieves the command to pull the test sets, mocks and reports from an S3-compatible bucket
+func Pull(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "pull",
+		Short:   "pull the latest or the given version of the test sets, mocks and reports from an S3-compatible bucket",
+		Example: `keploy pull --bucket my-bucket --prefix order-service --endpoint http://localhost:9000`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			remote, ok := getRemoteService(ctx, logger, serviceFactory, cmd.Name())
+			if !ok {
+				return nil
+			}
+			err := remote.Pull(ctx)
+			if err != nil {
+				utils.LogError(logger, err, "failed to pull the test sets")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add pull cmd flags")
+		return nil
+	}
+	return cmd
+}
+
+func getRemoteService(ctx context.Context, logger *zap.Logger, serviceFactory ServiceFactory, cmdName string) (remoteSvc.Service, bool) {
+	svc, err := serviceFactory.GetService(ctx, cmdName)
+	if err != nil {
+		utils.LogError(logger, err, "failed to get service")
+		return nil, false
+	}
+	remote, ok := svc.(remoteSvc.Service)
+	if !ok {
+		utils.LogError(logger, nil, "service doesn't satisfy remote service interface")
+		return nil, false
+	}
+	return remote, true
+}
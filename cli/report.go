@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	reportSvc "go.keploy.io/server/v2/pkg/service/report"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("report", Report)
+}
+
+// Report retrieves the command to compare the reports of the test runs
+func Report(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "report",
+		Short: "compare and summarize the reports of the test runs",
+		Example: `keploy report diff test-run-3 test-run-4
+keploy report comment --format markdown`,
+	}
+
+	var diffCmd = &cobra.Command{
+		Use:   "diff <base test run> <head test run>",
+		Short: "summarize the testcases newly failing or passing, with regressed latency or changed mock consumption in a test run, as markdown for a pull request comment",
+		Example: `keploy report diff test-run-3 test-run-4
+keploy report diff test-run-3 test-run-4 --latencyThreshold 50 -o diff.md`,
+		Args: cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				utils.LogError(logger, err, "failed to read the output path")
+				return nil
+			}
+			svc, err := serviceFactory.GetService(ctx, "report diff")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			report, ok := svc.(reportSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy report service interface")
+				return nil
+			}
+			w := os.Stdout
+			if output != "" {
+				w, err = os.Create(output)
+				if err != nil {
+					utils.LogError(logger, err, "failed to create the diff file")
+					return nil
+				}
+				defer w.Close()
+			}
+			err = report.Diff(ctx, args[0], args[1], w)
+			if err != nil {
+				utils.LogError(logger, err, "failed to diff the test runs")
+			}
+			return nil
+		},
+	}
+
+	var commentCmd = &cobra.Command{
+		Use:   "comment [test run]",
+		Short: "summarize a test run, the latest by default, with its pass and fail counts, new failures, flaky testcases and coverage delta, for a pull request comment",
+		Example: `keploy report comment --format markdown
+keploy report comment test-run-4 --base test-run-2 -o comment.md`,
+		Args: cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				utils.LogError(logger, err, "failed to read the output path")
+				return nil
+			}
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				utils.LogError(logger, err, "failed to read the format")
+				return nil
+			}
+			base, err := cmd.Flags().GetString("base")
+			if err != nil {
+				utils.LogError(logger, err, "failed to read the base test run")
+				return nil
+			}
+			var head string
+			if len(args) > 0 {
+				head = args[0]
+			}
+			svc, err := serviceFactory.GetService(ctx, "report comment")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			report, ok := svc.(reportSvc.Service)
+			if !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy report service interface")
+				return nil
+			}
+			w := os.Stdout
+			if output != "" {
+				w, err = os.Create(output)
+				if err != nil {
+					utils.LogError(logger, err, "failed to create the summary file")
+					return nil
+				}
+				defer w.Close()
+			}
+			err = report.Comment(ctx, head, base, format, w)
+			if err != nil {
+				utils.LogError(logger, err, "failed to summarize the test run")
+			}
+			return nil
+		},
+	}
+
+	cmd.AddCommand(diffCmd, commentCmd)
+	for _, c := range []*cobra.Command{cmd, diffCmd, commentCmd} {
+		if err := cmdConfigurator.AddFlags(c); err != nil {
+			utils.LogError(logger, err, "failed to add report cmd flags")
+			return nil
+		}
+	}
+	return cmd
+}
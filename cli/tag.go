@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	toolsSvc "go.keploy.io/server/v2/pkg/service/tools"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("tag", Tag)
+}
+
+// Tag retrieves the command to edit the tags of the testcases, which select the testcases run by keploy test --tags
+func Tag(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:   "tag <testset> [testcase...]",
+		Short: "add tags to or remove tags from the testcases of a test set, all of them if none are given",
+		Example: `keploy tag test-set-0 test-1 test-4 --add smoke,critical
+keploy tag test-set-0 --remove slow`,
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			add, err := cmd.Flags().GetStringSlice("add")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get add flag")
+				return nil
+			}
+			remove, err := cmd.Flags().GetStringSlice("remove")
+			if err != nil {
+				utils.LogError(logger, err, "failed to get remove flag")
+				return nil
+			}
+			if len(add) == 0 && len(remove) == 0 {
+				err := errors.New("missing the tags to --add or --remove")
+				utils.LogError(logger, err, "invalid arguments of tag")
+				return err
+			}
+
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return nil
+			}
+			var tools toolsSvc.Service
+			var ok bool
+			if tools, ok = svc.(toolsSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
+				return nil
+			}
+			err = tools.Tag(ctx, args[0], args[1:], add, remove)
+			if err != nil {
+				utils.LogError(logger, err, "failed to tag the testcases")
+			}
+			return nil
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add tag cmd flags")
+		return nil
+	}
+	return cmd
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 	replaySvc "go.keploy.io/server/v2/pkg/service/replay"
 	"go.uber.org/zap"
 )
@@ -26,6 +27,17 @@ func Test(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFa
 			return cmdConfigurator.Validate(ctx, cmd)
 		},
 		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cfg.Daemon {
+				runOnAgent(ctx, logger, cmd.Name(), cfg)
+				return nil
+			}
+			if cfg.Tracing.Enabled {
+				stopTracing, err := tracing.Init(ctx, logger, cfg.Tracing)
+				if err != nil {
+					return nil
+				}
+				defer stopTracing()
+			}
 			svc, err := serviceFactory.GetService(ctx, cmd.Name())
 			if err != nil {
 				utils.LogError(logger, err, "failed to get service")
@@ -56,6 +68,9 @@ func Test(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFa
 			}
 
 			err = replay.Start(ctx)
+			if cfg.Storage.Driver == "bundle" {
+				packBundle(ctx, logger, serviceFactory)
+			}
 			if err != nil {
 				utils.LogError(logger, err, "failed to replay")
 				return nil
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+	"go.keploy.io/server/v2/config"
+	uiSvc "go.keploy.io/server/v2/pkg/service/ui"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+func init() {
+	Register("ui", UI)
+}
+
+// UI retrieves the command to serve the web dashboard to browse the test sets and the test runs, edit the noise of the testcases and rerun them
+func UI(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+	var cmd = &cobra.Command{
+		Use:     "ui",
+		Short:   "serve the web dashboard to browse the test sets and the test runs, edit the noise and rerun the testcases",
+		Example: `keploy ui --port 6790 -c "/path/to/user/app"`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			svc, err := serviceFactory.GetService(ctx, cmd.Name())
+			if err != nil {
+				utils.LogError(logger, err, "failed to get service")
+				return err
+			}
+			var ui uiSvc.Service
+			var ok bool
+			if ui, ok = svc.(uiSvc.Service); !ok {
+				utils.LogError(logger, nil, "service doesn't satisfy ui service interface")
+				return errors.New("service doesn't satisfy ui service interface")
+			}
+			return ui.Serve(ctx)
+		},
+	}
+	if err := cmdConfigurator.AddFlags(cmd); err != nil {
+		utils.LogError(logger, err, "failed to add ui cmd flags")
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return cmd
+}
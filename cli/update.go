@@ -15,11 +15,15 @@ func init() {
 }
 
 // Update retrieves the command to tools Keploy
-func Update(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
+func Update(ctx context.Context, logger *zap.Logger, cfg *config.Config, serviceFactory ServiceFactory, cmdConfigurator CmdConfigurator) *cobra.Command {
 	var updateCmd = &cobra.Command{
-		Use:     "update",
-		Short:   "Update Keploy ",
-		Example: "keploy update",
+		Use:   "update",
+		Short: "Update Keploy ",
+		Example: `keploy update --channel beta
+keploy update --offline ./keploy_linux_amd64.tar.gz`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			return cmdConfigurator.ValidateFlags(ctx, cmd)
+		},
 		RunE: func(_ *cobra.Command, _ []string) error {
 			svc, err := serviceFactory.GetService(ctx, "update")
 			if err != nil {
@@ -32,7 +36,7 @@ func Update(ctx context.Context, logger *zap.Logger, _ *config.Config, serviceFa
 				utils.LogError(logger, nil, "service doesn't satisfy tools service interface")
 				return nil
 			}
-			err = tools.Update(ctx)
+			err = tools.Update(ctx, cfg.SelfUpdate)
 			if err != nil {
 				utils.LogError(logger, err, "failed to update")
 			}
@@ -2,6 +2,8 @@
 package config
 
 import (
+	"os"
+	"regexp"
 	"time"
 )
 
@@ -12,15 +14,25 @@ type Config struct {
 	Port                  uint32        `json:"port" yaml:"port" mapstructure:"port"`
 	DNSPort               uint32        `json:"dnsPort" yaml:"dnsPort" mapstructure:"dnsPort"`
 	ProxyPort             uint32        `json:"proxyPort" yaml:"proxyPort" mapstructure:"proxyPort"`
+	ProxyDrainTimeout     time.Duration `json:"proxyDrainTimeout" yaml:"proxyDrainTimeout" mapstructure:"proxyDrainTimeout"`       // how long the connections in flight are waited for when the proxy stops
+	ProxyMaxConnections   int           `json:"proxyMaxConnections" yaml:"proxyMaxConnections" mapstructure:"proxyMaxConnections"` // the most connections handled by the proxy at once, the others wait to be accepted, 0 for no limit
+	ProxyReadTimeout      time.Duration `json:"proxyReadTimeout" yaml:"proxyReadTimeout" mapstructure:"proxyReadTimeout"`          // how long the proxy waits for the next chunk of an http request or response
+	ProxyIdleThreshold    time.Duration `json:"proxyIdleThreshold" yaml:"proxyIdleThreshold" mapstructure:"proxyIdleThreshold"`    // how long a recorded conn is idle before its last response is taken as complete
 	Debug                 bool          `json:"debug" yaml:"debug" mapstructure:"debug"`
 	DisableTele           bool          `json:"disableTele" yaml:"disableTele" mapstructure:"disableTele"`
+	TelemetryEvents       []string      `json:"telemetryEvents" yaml:"telemetryEvents" mapstructure:"telemetryEvents"` // categories of the telemetry events sent, all of them if empty
+	TelemetryLog          string        `json:"telemetryLog" yaml:"telemetryLog" mapstructure:"telemetryLog"`          // JSONL file the telemetry events are written to instead of being sent
 	DisableANSI           bool          `json:"disableANSI" yaml:"disableANSI" mapstructure:"disableANSI"`
 	InDocker              bool          `json:"inDocker" yaml:"inDocker" mapstructure:"inDocker"`
 	ContainerName         string        `json:"containerName" yaml:"containerName" mapstructure:"containerName"`
 	NetworkName           string        `json:"networkName" yaml:"networkName" mapstructure:"networkName"`
 	BuildDelay            time.Duration `json:"buildDelay" yaml:"buildDelay" mapstructure:"buildDelay"`
+	RandomSeed            int64         `json:"randomSeed" yaml:"randomSeed" mapstructure:"randomSeed"` // seed of the randomness of the app, 0 disables it
 	Test                  Test          `json:"test" yaml:"test" mapstructure:"test"`
 	Record                Record        `json:"record" yaml:"record" mapstructure:"record"`
+	Load                  Load          `json:"load" yaml:"load" mapstructure:"load"`
+	Contract              Contract      `json:"contract" yaml:"contract" mapstructure:"contract"`
+	Remote                Remote        `json:"remote" yaml:"remote" mapstructure:"remote"`
 	ConfigPath            string        `json:"configPath" yaml:"configPath" mapstructure:"configPath"`
 	BypassRules           []BypassRule  `json:"bypassRules" yaml:"bypassRules" mapstructure:"bypassRules"`
 	EnableTesting         bool          `json:"enableTesting" yaml:"enableTesting" mapstructure:"enableTesting"`
@@ -28,11 +40,299 @@ type Config struct {
 	KeployContainer       string        `json:"keployContainer" yaml:"keployContainer" mapstructure:"keployContainer"`
 	KeployNetwork         string        `json:"keployNetwork" yaml:"keployNetwork" mapstructure:"keployNetwork"`
 	CommandType           string        `json:"cmdType" yaml:"cmdType" mapstructure:"cmdType"`
+	Storage               Storage       `json:"storage" yaml:"storage" mapstructure:"storage"`
+	Bundle                Bundle        `json:"bundle" yaml:"bundle" mapstructure:"bundle"`
+	Analyze               Analyze       `json:"analyze" yaml:"analyze" mapstructure:"analyze"`
+	Encryption            Encryption    `json:"encryption" yaml:"encryption" mapstructure:"encryption"`
+	Tracing               Tracing       `json:"tracing" yaml:"tracing" mapstructure:"tracing"`
+	Serve                 Serve         `json:"serve" yaml:"serve" mapstructure:"serve"`
+	UI                    UI            `json:"ui" yaml:"ui" mapstructure:"ui"`
+	MockServer            MockServer    `json:"mockServer" yaml:"mockServer" mapstructure:"mockServer"`
+	Ingress               Ingress       `json:"ingress" yaml:"ingress" mapstructure:"ingress"`
+	Report                Report        `json:"report" yaml:"report" mapstructure:"report"`
+	Agent                 Agent         `json:"agent" yaml:"agent" mapstructure:"agent"`
+	K8s                   K8s           `json:"k8s" yaml:"k8s" mapstructure:"k8s"`
+	Daemon                bool          `json:"daemon" yaml:"daemon" mapstructure:"daemon"` // run keploy record and test through keploy agent
+	SelfUpdate            Update        `json:"selfUpdate" yaml:"selfUpdate" mapstructure:"selfUpdate"`
+	// Profiles are named sets of config values, e.g. dev, ci and staging, overlaid onto the rest of the config when
+	// selected with Profile, so that one config file serves all the environments.
+	Profiles map[string]map[string]interface{} `json:"profiles" yaml:"profiles" mapstructure:"profiles"`
+	Profile  string                            `json:"profile" yaml:"profile" mapstructure:"profile"`
+	// Hooks are the shell commands run before and after the record session, the test sets and the testcases.
+	Hooks Hooks `json:"hooks" yaml:"hooks" mapstructure:"hooks"`
+	// Redirect is how the outgoing calls of the app are redirected to the proxy: ebpf, iptables, proxy, or auto to
+	// use the eBPF hooks if keploy has their capabilities and iptables otherwise. Only keploy test works with
+	// iptables, as the testcases are captured by the eBPF hooks. The proxy mode passes the proxy to the app with
+	// HTTP_PROXY and HTTPS_PROXY, it is used on macOS and Windows where auto falls back to it.
+	Redirect string `json:"redirect" yaml:"redirect" mapstructure:"redirect"`
+	// UDP are the destination ports whose UDP datagrams from the app are intercepted by the UDP relay of the proxy,
+	// other than the DNS queries which are always answered by keploy. Only the IPv4 datagrams of the native apps
+	// started by keploy are intercepted, with the eBPF hooks or the iptables redirect: the apps are started in a
+	// cgroup v2 of their own, which the iptables rules match, and the datagrams not attributed to an app are dropped.
+	UDP []UDPRule `json:"udp" yaml:"udp" mapstructure:"udp"`
+	// BTFPath is the BTF of the kernel for the eBPF hooks on the kernels not exposing it in /sys/kernel/btf/vmlinux.
+	BTFPath string `json:"btfPath" yaml:"btfPath" mapstructure:"btfPath"`
+	// ProtoDir is the directory of the .proto files or the compiled descriptor sets of the grpc services the app
+	// calls. The protobuf messages of their mocks are written as JSON with the full name of their type too, and the
+	// edits of the JSON are encoded back into the messages during the replay.
+	ProtoDir string `json:"protoDir" yaml:"protoDir" mapstructure:"protoDir"`
+	// Client is the user of keploy agent a session is run for, it is set by the agent for the sessions of the
+	// keploy record and keploy test run as its clients and nil otherwise.
+	Client *Client `json:"-" yaml:"-" mapstructure:"-"`
 }
 
+// Hooks are the lifecycle hooks of keploy, shell commands run with sh -c, e.g. to seed or reset the database of
+// the app between the test sets. The test set and the testcase are passed to the hooks in the KEPLOY_TEST_SET and
+// KEPLOY_TEST_CASE environment variables. A failing pre hook fails what it runs before, the record session or the
+// test set, except preTestCase whose failure is only logged.
+type Hooks struct {
+	PreRecord   string `json:"preRecord" yaml:"preRecord" mapstructure:"preRecord"`
+	PostRecord  string `json:"postRecord" yaml:"postRecord" mapstructure:"postRecord"`
+	PreTestSet  string `json:"preTestSet" yaml:"preTestSet" mapstructure:"preTestSet"`
+	PostTestSet string `json:"postTestSet" yaml:"postTestSet" mapstructure:"postTestSet"`
+	PreTestCase string `json:"preTestCase" yaml:"preTestCase" mapstructure:"preTestCase"`
+}
+
+// Agent is the gRPC control plane of keploy agent, to record and test the app from a remote orchestrator. The
+// calls need the bearer token if it is set, in the config or in the KEPLOY_AGENT_TOKEN environment variable, the
+// port only listens on 127.0.0.1 without it, and the server listens over TLS if both the certificate and its key
+// are set. The agent also listens on the unix socket for keploy record and keploy test run with --daemon by the
+// unprivileged users of the machine.
+type Agent struct {
+	Port    uint32 `json:"port" yaml:"port" mapstructure:"port"`
+	Socket  string `json:"socket" yaml:"socket" mapstructure:"socket"` // path of the unix socket, empty to not listen on it
+	Token   string `json:"token" yaml:"token" mapstructure:"token"`
+	TLSCert string `json:"tlsCert" yaml:"tlsCert" mapstructure:"tlsCert"` // path of the PEM encoded certificate
+	TLSKey  string `json:"tlsKey" yaml:"tlsKey" mapstructure:"tlsKey"`    // path of the PEM encoded private key
+}
+
+// K8s is the kubernetes manifests printed by keploy k8s manifest, to record the app of a pod with keploy as its
+// sidecar and to test the app with a Job. The keploy directory is kept on the persistent volume of the claim, or
+// in an emptyDir synced with the bucket of remote if no claim is set.
+type K8s struct {
+	Image    string `json:"image" yaml:"image" mapstructure:"image"`          // image of keploy
+	AppImage string `json:"appImage" yaml:"appImage" mapstructure:"appImage"` // image of the app, run by the Job
+	PVC      string `json:"pvc" yaml:"pvc" mapstructure:"pvc"`                // claim of the volume of the keploy directory
+}
+
+// Client is the unprivileged user of the machine of keploy agent who ran keploy record or keploy test with
+// --daemon, the app is run as the user in its working directory and environment.
+type Client struct {
+	UID uint32
+	GID uint32
+	Dir string
+	Env []string
+}
+
+// UI is the web dashboard served by keploy ui on the localhost, to browse the test sets and the test runs, edit
+// the noise of the testcases and rerun them.
+type UI struct {
+	Port uint32 `json:"port" yaml:"port" mapstructure:"port"`
+}
+
+// MockServer serves the mocks of a test set on a port with keploy mock serve, as a stub server of the recorded
+// dependencies.
+type MockServer struct {
+	Port    uint32 `json:"port" yaml:"port" mapstructure:"port"`
+	TestSet string `json:"testSet" yaml:"testSet" mapstructure:"testSet"` // test set whose mocks are served, all of them if empty
+}
+
+// Serve secures the GraphQL server which runs the testcases for the unit test library integrations. The requests
+// need the bearer token if it is set, in the config or in the KEPLOY_SERVE_TOKEN environment variable, and the
+// server listens over TLS if both the certificate and its key are set.
+type Serve struct {
+	Token   string `json:"token" yaml:"token" mapstructure:"token"`
+	TLSCert string `json:"tlsCert" yaml:"tlsCert" mapstructure:"tlsCert"` // path of the PEM encoded certificate
+	TLSKey  string `json:"tlsKey" yaml:"tlsKey" mapstructure:"tlsKey"`    // path of the PEM encoded private key
+}
+
+// Storage selects where the testcases, mocks and reports are stored.
+type Storage struct {
+	Driver   string `json:"driver" yaml:"driver" mapstructure:"driver"` // yaml, bundle or mongo
+	URI      string `json:"uri" yaml:"uri" mapstructure:"uri"`          // connection string of the mongo driver
+	Database string `json:"database" yaml:"database" mapstructure:"database"`
+}
+
+// Bundle is the .keploy bundle which the test sets are packed into and unpacked from. The bundle storage driver
+// unpacks it before record and test, and packs the test sets back into it afterwards.
+type Bundle struct {
+	File     string   `json:"file" yaml:"file" mapstructure:"file"`
+	TestSets []string `json:"testsets" yaml:"testsets" mapstructure:"testsets"`
+}
+
+// Tracing exports the OpenTelemetry spans of the record and test runs of keploy over OTLP/HTTP.
+type Tracing struct {
+	Enabled  bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	Endpoint string `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint"` // e.g. http://localhost:4318, OTEL_EXPORTER_OTLP_ENDPOINT by default
+}
+
+// Encryption encrypts the specs of the recorded testcases and mocks, and the reports, at rest with AES-GCM in every
+// storage driver. The key is the base64 encoded AES key (16, 24 or 32 bytes) in the KEPLOY_ENCRYPTION_KEY environment
+// variable, or printed by KeyCommand e.g. the decryption of a data key with a KMS. The encrypted artifacts are
+// decrypted whenever a key is set.
+type Encryption struct {
+	Enabled    bool   `json:"enabled" yaml:"enabled" mapstructure:"enabled"`
+	KeyCommand string `json:"keyCommand" yaml:"keyCommand" mapstructure:"keyCommand"`
+}
+
+// Analyze is the analysis of the consumption of the mocks across the last test runs.
+type Analyze struct {
+	Runs     uint64   `json:"runs" yaml:"runs" mapstructure:"runs"` // number of the last test runs to analyze
+	TestSets []string `json:"testsets" yaml:"testsets" mapstructure:"testsets"`
+	Plan     string   `json:"plan" yaml:"plan" mapstructure:"plan"` // file the prune plan of the never used mocks is written to
+}
+
+// Update is the config of keploy update. The release binaries are verified against the checksums of the release,
+// whose signature is verified with PublicKey, the key of the keploy releases by default, unless SkipSignature is set.
+type Update struct {
+	Channel       string `json:"channel" yaml:"channel" mapstructure:"channel"`                   // the release channel: stable, beta or nightly
+	Offline       string `json:"offline" yaml:"offline" mapstructure:"offline"`                   // the downloaded release archive, or its directory, to update from without the network
+	PublicKey     string `json:"publicKey" yaml:"publicKey" mapstructure:"publicKey"`             // the base64 ed25519 key the signature of the checksums is verified with, the key of the keploy releases if empty
+	SkipSignature bool   `json:"skipSignature" yaml:"skipSignature" mapstructure:"skipSignature"` // don't verify the signature of the checksums, e.g. for releases built without the signing key
+}
+
+// the release channels of keploy update, stable for the latest release, beta for the latest one including the
+// pre-releases, and nightly for the builds of the main branch
+const (
+	UpdateStable  = "stable"
+	UpdateBeta    = "beta"
+	UpdateNightly = "nightly"
+)
+
 type Record struct {
 	Filters     []Filter      `json:"filters" yaml:"filters" mapstructure:"filters"`
 	RecordTimer time.Duration `json:"recordTimer" yaml:"recordTimer" mapstructure:"recordTimer"`
+	// MaxMockFileSize is the size in MB past which the mocks file of a test set is rotated into numbered shards
+	// (mocks-1.yaml, mocks-2.yaml...), 0 disables the rotation.
+	MaxMockFileSize uint64 `json:"maxMockFileSize" yaml:"maxMockFileSize" mapstructure:"maxMockFileSize"`
+	// MockFormat is the format the mocks are recorded in, yaml or protobuf (mocks.pb) which is faster to load for
+	// large mock files. The mocks are replayed in whichever format they were recorded.
+	MockFormat string `json:"mockFormat" yaml:"mockFormat" mapstructure:"mockFormat"`
+	// IngressPort is the port keploy receives the calls to the app on in the proxy mode, forwarding them to
+	// AppPort, the port of the app, to capture the testcases.
+	IngressPort uint32 `json:"ingressPort" yaml:"ingressPort" mapstructure:"ingressPort"`
+	AppPort     uint32 `json:"appPort" yaml:"appPort" mapstructure:"appPort"`
+	// TLSUprobes records the TLS connections of the native apps from their plaintext captured with uprobes on
+	// SSL_read and SSL_write, instead of decrypting them in the proxy with the CA of keploy. The uprobes are attached
+	// to the libssl of the distribution and to TLSLibraries, e.g. the binaries linking OpenSSL or BoringSSL statically.
+	TLSUprobes   bool     `json:"tlsUprobes" yaml:"tlsUprobes" mapstructure:"tlsUprobes"`
+	TLSLibraries []string `json:"tlsLibraries" yaml:"tlsLibraries" mapstructure:"tlsLibraries"`
+	// JavaAgent is the jar of the JSSE agent of keploy, passed to the JVM of the native app to record its TLS
+	// connections from their plaintext without the JVM trusting the CA of keploy.
+	JavaAgent string `json:"javaAgent" yaml:"javaAgent" mapstructure:"javaAgent"`
+	// Apps are recorded together under one session instead of the command, sharing the proxy. The testcases and
+	// mocks of each app are recorded into the keploy directory under the directory of its name in the path, so they
+	// are replayed with `keploy test -p <path>/<name>`.
+	Apps []App `json:"apps" yaml:"apps" mapstructure:"apps"`
+	// MaxConnsPerHost bounds the connections to a destination recorded at once, the others are passed through
+	// without being recorded. 0 disables the limit.
+	MaxConnsPerHost int `json:"maxConnsPerHost" yaml:"maxConnsPerHost" mapstructure:"maxConnsPerHost"`
+	// CircuitBreaker passes through the connections to a destination whose recording keeps failing.
+	CircuitBreaker CircuitBreaker `json:"circuitBreaker" yaml:"circuitBreaker" mapstructure:"circuitBreaker"`
+	// AnnotatePort is the localhost port of the api naming and describing the next captured testcase, POST
+	// /keploy/annotate {name, description, tags}, and pausing and resuming the recording, POST /keploy/pause and
+	// /keploy/resume. 0 disables it.
+	AnnotatePort uint32 `json:"annotatePort" yaml:"annotatePort" mapstructure:"annotatePort"`
+	// SourceIPs are the ips or cidrs of the clients whose requests are recorded, e.g. the machines of a team in a
+	// shared staging, all of them if empty. CaptureUserAgent adds the user agent of the client to the testcases.
+	SourceIPs        []string `json:"sourceIPs" yaml:"sourceIPs" mapstructure:"sourceIPs"`
+	CaptureUserAgent bool     `json:"captureUserAgent" yaml:"captureUserAgent" mapstructure:"captureUserAgent"`
+	// SampleRate is the fraction of the requests recorded, MaxPerEndpoint the testcases recorded per method and
+	// path, with the ids in the path collapsed, and Reservoir the size of the uniform sample of the testcases of each
+	// test set, inserted when the session ends or the test set is rotated. They bound the testcases recorded from a
+	// busy app, 0 disables each of them.
+	SampleRate     float64 `json:"sampleRate" yaml:"sampleRate" mapstructure:"sampleRate"`
+	MaxPerEndpoint int     `json:"maxPerEndpoint" yaml:"maxPerEndpoint" mapstructure:"maxPerEndpoint"`
+	Reservoir      int     `json:"reservoir" yaml:"reservoir" mapstructure:"reservoir"`
+	// Continuous records until stopped, e.g. shadowing a production-like environment, into a new test set every
+	// Rotation. The oldest test sets of the recording, never the ones recorded before it, are deleted once the keploy
+	// directory is larger than MaxDiskSize in MB, 0 disables the quota. The default sensitive headers are always
+	// redacted in this mode.
+	Continuous  bool          `json:"continuous" yaml:"continuous" mapstructure:"continuous"`
+	Rotation    time.Duration `json:"rotation" yaml:"rotation" mapstructure:"rotation"`
+	MaxDiskSize uint64        `json:"maxDiskSize" yaml:"maxDiskSize" mapstructure:"maxDiskSize"`
+	// Redact replaces the sensitive values of the recorded testcases and http mocks before they are written.
+	Redact Redact `json:"redact" yaml:"redact" mapstructure:"redact"`
+	// Dependencies name the dependencies of the app, e.g. payments-api or users-db, by the hosts of their calls. The
+	// recorded mocks are labelled with the name of their dependency, or else with their host, and the test reports
+	// count the mocks of each dependency.
+	Dependencies []Dependency `json:"dependencies" yaml:"dependencies" mapstructure:"dependencies"`
+	// TUI draws the captured testcases and mocks instead of the logs, with the keys discarding the last testcase and
+	// stopping the recording.
+	TUI bool `json:"tui" yaml:"tui" mapstructure:"tui"`
+}
+
+// Dependency is the name of the dependency reached at the hosts, each a host, a host:port or a :port of any host.
+// The hosts may have * wildcards, e.g. *.stripe.com, and are matched against the server name or Host header of the
+// calls and the ip of their destination. The first dependency matching a mock names it.
+type Dependency struct {
+	Name  string   `json:"name" yaml:"name" mapstructure:"name"`
+	Hosts []string `json:"hosts" yaml:"hosts" mapstructure:"hosts"`
+}
+
+// Redact is the rules of the values replaced with [REDACTED], the headers by name, the fields of the json bodies by
+// their path, e.g. user.password or cards.*.number, and the matches of the patterns in the bodies.
+type Redact struct {
+	Headers  []string `json:"headers" yaml:"headers" mapstructure:"headers"`
+	Fields   []string `json:"fields" yaml:"fields" mapstructure:"fields"`
+	Patterns []string `json:"patterns" yaml:"patterns" mapstructure:"patterns"`
+}
+
+// CircuitBreaker passes through the connections to a destination for Cooldown once Failures of them failed in a
+// row, because the destination couldn't be dialed or timed out. The calls passed through aren't recorded, so the
+// test run misses their mocks. 0 Failures disables it, the default.
+type CircuitBreaker struct {
+	Failures int           `json:"failures" yaml:"failures" mapstructure:"failures"`
+	Cooldown time.Duration `json:"cooldown" yaml:"cooldown" mapstructure:"cooldown"`
+}
+
+// App is one of the native apps recorded together.
+type App struct {
+	Name    string `json:"name" yaml:"name" mapstructure:"name"`
+	Command string `json:"command" yaml:"command" mapstructure:"command"`
+}
+
+type Load struct {
+	TestSets   []string      `json:"testset" yaml:"testset" mapstructure:"testset"`
+	RPS        uint64        `json:"rps" yaml:"rps" mapstructure:"rps"`
+	Duration   time.Duration `json:"duration" yaml:"duration" mapstructure:"duration"`
+	BaseURL    string        `json:"baseUrl" yaml:"baseUrl" mapstructure:"baseUrl"` // replaces the scheme and host of the recorded requests
+	APITimeout uint64        `json:"apiTimeout" yaml:"apiTimeout" mapstructure:"apiTimeout"`
+}
+
+// Report is the comparison of the reports of two test runs by keploy report diff.
+type Report struct {
+	TestSets []string `json:"testsets" yaml:"testsets" mapstructure:"testsets"`
+	// LatencyThreshold is the percentage by which the latency of a testcase has to grow to be reported as regressed
+	LatencyThreshold float64 `json:"latencyThreshold" yaml:"latencyThreshold" mapstructure:"latencyThreshold"`
+	// FlakyRuns is the number of the latest test runs in which a testcase both passed and failed is reported flaky
+	FlakyRuns int `json:"flakyRuns" yaml:"flakyRuns" mapstructure:"flakyRuns"`
+}
+
+// Ingress is the environment keploy replay-ingress sends the recorded requests of the test sets to, as a client.
+type Ingress struct {
+	TestSets   []string `json:"testset" yaml:"testset" mapstructure:"testset"`
+	URL        string   `json:"url" yaml:"url" mapstructure:"url"` // replaces the scheme and host of the recorded requests
+	APITimeout uint64   `json:"apiTimeout" yaml:"apiTimeout" mapstructure:"apiTimeout"`
+}
+
+// Remote is the S3-compatible bucket which the test sets are pushed to and pulled from. The credentials are read
+// from the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+type Remote struct {
+	Bucket   string   `json:"bucket" yaml:"bucket" mapstructure:"bucket"`
+	Prefix   string   `json:"prefix" yaml:"prefix" mapstructure:"prefix"`       // key prefix of the pushed versions in the bucket
+	Endpoint string   `json:"endpoint" yaml:"endpoint" mapstructure:"endpoint"` // endpoint of an S3-compatible store, AWS S3 if empty
+	Region   string   `json:"region" yaml:"region" mapstructure:"region"`
+	Version  string   `json:"version" yaml:"version" mapstructure:"version"` // version to push or pull, a timestamp and the latest version by default
+	TestSets []string `json:"testsets" yaml:"testsets" mapstructure:"testsets"`
+}
+
+type Contract struct {
+	Path     string   `json:"contracts" yaml:"contracts" mapstructure:"contracts"` // directory where the contracts are stored
+	TestSets []string `json:"testsets" yaml:"testsets" mapstructure:"testsets"`
+	Service  string   `json:"service" yaml:"service" mapstructure:"service"`    // name of the consumer service exporting the contracts
+	Provider string   `json:"provider" yaml:"provider" mapstructure:"provider"` // name of the provider service verifying the contracts
+	BaseURL  string   `json:"baseUrl" yaml:"baseUrl" mapstructure:"baseUrl"`
 }
 
 type BypassRule struct {
@@ -41,6 +341,21 @@ type BypassRule struct {
 	Port uint   `json:"port" yaml:"port" mapstructure:"port"`
 }
 
+// UDPRule is how the datagrams of the app to the port are handled: passThrough forwards them to their destination,
+// mock records them with their replies as mocks in record and answers them from the mocks in test, and drop
+// discards them e.g. for the metrics sent to statsd. All of them are counted in the summary of the session.
+type UDPRule struct {
+	Port uint32 `json:"port" yaml:"port" mapstructure:"port"`
+	Mode string `json:"mode" yaml:"mode" mapstructure:"mode"`
+}
+
+// the modes of the UDP rules
+const (
+	UDPPassThrough = "passThrough"
+	UDPMock        = "mock"
+	UDPDrop        = "drop"
+)
+
 type Filter struct {
 	BypassRule `mapstructure:",squash"`
 	URLMethods []string          `json:"urlMethods" yaml:"urlMethods" mapstructure:"urlMethods"`
@@ -51,15 +366,88 @@ type Test struct {
 	SelectedTests      map[string][]string `json:"selectedTests" yaml:"selectedTests" mapstructure:"selectedTests"`
 	GlobalNoise        Globalnoise         `json:"globalNoise" yaml:"globalNoise" mapstructure:"globalNoise"`
 	Delay              uint64              `json:"delay" yaml:"delay" mapstructure:"delay"`
+	ReadinessURL       string              `json:"readinessURL" yaml:"readinessURL" mapstructure:"readinessURL"`             // health endpoint of the app polled until it is ready, instead of waiting for the delay
+	ReadinessPort      uint32              `json:"readinessPort" yaml:"readinessPort" mapstructure:"readinessPort"`          // port of the app polled until it accepts connections, instead of waiting for the delay
+	ReadinessTimeout   time.Duration       `json:"readinessTimeout" yaml:"readinessTimeout" mapstructure:"readinessTimeout"` // how long the app is polled before the test set is failed
 	APITimeout         uint64              `json:"apiTimeout" yaml:"apiTimeout" mapstructure:"apiTimeout"`
 	Coverage           bool                `json:"coverage" yaml:"coverage" mapstructure:"coverage"`                                // boolean to capture the coverage in test
 	CoverageReportPath string              `json:"coverageReportPath" yaml:"coverageReportPath " mapstructure:"coverageReportPath"` // directory path to store the coverage files
 	GoCoverage         bool                `json:"goCoverage" yaml:"goCoverage" mapstructure:"goCoverage"`                          // boolean to capture the coverage in test
 	IgnoreOrdering     bool                `json:"ignoreOrdering" yaml:"ignoreOrdering" mapstructure:"ignoreOrdering"`
+	HashAssets         bool                `json:"hashAssets" yaml:"hashAssets" mapstructure:"hashAssets"`          // compare the inline scripts and styles of the html bodies by their sha256
+	ArrayMatching      map[string]string   `json:"arrayMatching" yaml:"arrayMatching" mapstructure:"arrayMatching"` // body field of an array -> how its elements are matched, instead of by ignoreOrdering
 	MongoPassword      string              `json:"mongoPassword" yaml:"mongoPassword" mapstructure:"mongoPassword"`
 	Language           string              `json:"language" yaml:"language" mapstructure:"language"`
 	RemoveUnusedMocks  bool                `json:"removeUnusedMocks" yaml:"removeUnusedMocks" mapstructure:"removeUnusedMocks"`
 	FallBackOnMiss     bool                `json:"fallBackOnMiss" yaml:"fallBackOnMiss" mapstructure:"fallBackOnMiss"`
+	StrictMocking      bool                `json:"strictMocking" yaml:"strictMocking" mapstructure:"strictMocking"` // fail the testcase on an outgoing call which doesn't match any mock
+	Update             bool                `json:"update" yaml:"update" mapstructure:"update"`                      // rewrite the expected response of failed testcases with the actual response
+	GraphQLNoise       GraphQLNoise        `json:"graphqlNoise" yaml:"graphqlNoise" mapstructure:"graphqlNoise"`
+	SimulateLatency    float64             `json:"simulateLatency" yaml:"simulateLatency" mapstructure:"simulateLatency"` // multiplier of the recorded latency of the mocks, 0 disables it
+	Chaos              map[string]Chaos    `json:"chaos" yaml:"chaos" mapstructure:"chaos"`                               // test-set id -> fault injection in its mock responses
+	FreezeTime         bool                `json:"freezeTime" yaml:"freezeTime" mapstructure:"freezeTime"`                // freeze the time of the app at the recorded time of the testcases
+	FreezeTimeLib      string              `json:"freezeTimeLib" yaml:"freezeTimeLib" mapstructure:"freezeTimeLib"`       // path of libfaketime, searched in the default paths if empty
+	CaptureAppLogs     bool                `json:"captureAppLogs" yaml:"captureAppLogs" mapstructure:"captureAppLogs"`    // attach the stdout and stderr of the app to the result of each testcase
+	DBSnapshot         DBSnapshot          `json:"dbSnapshot" yaml:"dbSnapshot" mapstructure:"dbSnapshot"`
+	Changed            string              `json:"changed" yaml:"changed" mapstructure:"changed"`                            // git revision whose changed and untracked files select the test sets and testcases to run by their coverage maps
+	Tags               []string            `json:"tags" yaml:"tags" mapstructure:"tags"`                                     // run only the testcases with any of the tags
+	StreamChunkSize    int                 `json:"streamChunkSize" yaml:"streamChunkSize" mapstructure:"streamChunkSize"`    // size in bytes of the parts the http mock bodies are streamed in, 0 writes them at once
+	Conditional        string              `json:"conditional" yaml:"conditional" mapstructure:"conditional"`                // recorded serves the recorded http mock responses to the conditional requests, revalidate a 304 if still valid
+	OAuthTokens        bool                `json:"oauthTokens" yaml:"oauthTokens" mapstructure:"oauthTokens"`                // match the oauth2 token requests by their grant type, reissue the recorded jwts signed with keys served in the recorded jwks, and match the bearer jwts by their claims
+	StrictMockWindow   bool                `json:"strictMockWindow" yaml:"strictMockWindow" mapstructure:"strictMockWindow"` // bind each testcase to the mocks recorded within its window, besides the config mocks
+	MockWindowSlack    time.Duration       `json:"mockWindowSlack" yaml:"mockWindowSlack" mapstructure:"mockWindowSlack"`    // widens the window of the testcases on both sides
+	Annotations        string              `json:"annotations" yaml:"annotations" mapstructure:"annotations"`                // annotate the failed testcases for the CI, github, gitlab or auto to detect it, empty disables it
+	TUI                bool                `json:"tui" yaml:"tui" mapstructure:"tui"`                                        // draw the live progress of the test run and its mock hits and misses instead of the logs
+}
+
+// the ways the elements of an array of the response body are matched: in order, in any order, the expected
+// elements in any order among more actual ones, only the number of elements, or each expected element with the
+// actual one with the same value of a field, e.g. key:id
+const (
+	ArrayOrdered   = "ordered"
+	ArrayUnordered = "unordered"
+	ArraySubset    = "subset"
+	ArrayLength    = "length"
+	ArrayKeyPrefix = "key:"
+)
+
+// the handling of the conditional requests to the http mocks
+const (
+	ConditionalRecorded   = "recorded"
+	ConditionalRevalidate = "revalidate"
+)
+
+// the categories of the telemetry events: the pings of the running keploy, the test runs, the recordings, and the
+// kinds of the recorded mocks
+const (
+	TelemetryPing      = "ping"
+	TelemetryRuns      = "runs"
+	TelemetryRecords   = "records"
+	TelemetryProtocols = "protocols"
+)
+
+// the CIs the failed testcases are annotated for, github with workflow commands and gitlab with a code quality report
+const (
+	AnnotationsAuto   = "auto"
+	AnnotationsGitHub = "github"
+	AnnotationsGitLab = "gitlab"
+)
+
+// DBSnapshot is the database of the app in a docker container, snapshotted before the first test set and restored
+// before each following one so that the test sets start from the same state. The database is dumped and restored
+// with the client tools of its container, pg_dump and psql for postgres, mysqldump and mysql for mysql.
+type DBSnapshot struct {
+	Container string `json:"container" yaml:"container" mapstructure:"container"` // name of the container, empty to disable the snapshot
+	Type      string `json:"type" yaml:"type" mapstructure:"type"`                // postgres or mysql
+	Database  string `json:"database" yaml:"database" mapstructure:"database"`
+	User      string `json:"user" yaml:"user" mapstructure:"user"` // postgres or root if empty
+	Password  string `json:"password" yaml:"password" mapstructure:"password"`
+}
+
+type Chaos struct {
+	Percentage float64  `json:"percentage" yaml:"percentage" mapstructure:"percentage"` // percentage of the mock responses to be replaced with faults
+	Faults     []string `json:"faults" yaml:"faults" mapstructure:"faults"`             // error, reset, truncate and/or timeout, all of them if empty
+	Seed       int64    `json:"seed" yaml:"seed" mapstructure:"seed"`                   // seed to repeat the same faults across the test runs
 }
 
 type Globalnoise struct {
@@ -69,6 +457,7 @@ type Globalnoise struct {
 
 type (
 	Noise        map[string][]string
+	GraphQLNoise map[string]map[string][]string // operation name ("*" for every operation) -> noisy fields of the response body
 	GlobalNoise  map[string]map[string][]string
 	TestsetNoise map[string]map[string]map[string][]string
 )
@@ -100,3 +489,31 @@ func SetSelectedTests(conf *Config, testSets []string) {
 		conf.Test.SelectedTests[testSet] = []string{}
 	}
 }
+
+// envVar matches ${VAR} and ${VAR:-default} in the config values.
+var envVar = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ExpandEnv replaces ${VAR} in the command, container name, path and mongo password of the config with the value
+// of the environment variable VAR, or with default for ${VAR:-default} if VAR is empty, so that the same config
+// file works across the machines. The $VAR form is left as is, it is expanded by the shell running the command.
+// It returns the variables which are neither set nor have a default.
+func ExpandEnv(conf *Config) []string {
+	var unset []string
+	expand := func(value string) string {
+		return envVar.ReplaceAllStringFunc(value, func(ref string) string {
+			m := envVar.FindStringSubmatch(ref)
+			if v := os.Getenv(m[1]); v != "" {
+				return v
+			}
+			if m[2] == "" {
+				unset = append(unset, m[1])
+			}
+			return m[3]
+		})
+	}
+	conf.Command = expand(conf.Command)
+	conf.ContainerName = expand(conf.ContainerName)
+	conf.Path = expand(conf.Path)
+	conf.Test.MongoPassword = expand(conf.Test.MongoPassword)
+	return unset
+}
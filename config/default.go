@@ -14,33 +14,175 @@ command: ""
 port: 0
 proxyPort: 16789
 dnsPort: 26789
+proxyDrainTimeout: 5s
+proxyMaxConnections: 1024
+proxyReadTimeout: 5s
+proxyIdleThreshold: 2s
 debug: false
 disableANSI: false
 disableTele: false
+telemetryEvents: []
+telemetryLog: ""
 inDocker: false
 generateGithubActions: true
 containerName: ""
 networkName: ""
 buildDelay: 30s
+randomSeed: 0
+daemon: false
+profiles: {}
+profile: ""
+hooks:
+  preRecord: ""
+  postRecord: ""
+  preTestSet: ""
+  postTestSet: ""
+  preTestCase: ""
+redirect: auto
+btfPath: ""
+protoDir: ""
 test:
   selectedTests: {}
   globalNoise:
     global: {}
     test-sets: {}
   delay: 5
+  readinessURL: ""
+  readinessPort: 0
+  readinessTimeout: 1m
   apiTimeout: 5
   coverage: false
   goCoverage: false
   coverageReportPath: ""
   ignoreOrdering: true
+  hashAssets: false
+  arrayMatching: {}
   mongoPassword: "default@123"
   language: ""
   removeUnusedMocks: false
+  strictMocking: false
+  graphqlNoise: {}
+  simulateLatency: 0
+  chaos: {}
+  freezeTime: false
+  freezeTimeLib: ""
+  captureAppLogs: false
+  changed: ""
+  tags: []
+  streamChunkSize: 0
+  conditional: recorded
+  oauthTokens: true
+  strictMockWindow: false
+  mockWindowSlack: 100ms
+  annotations: ""
+  tui: false
+  dbSnapshot:
+    container: ""
+    type: postgres
+    database: ""
+    user: ""
+    password: ""
 record:
   recordTimer: 0s
   filters: []
+  maxMockFileSize: 50
+  mockFormat: yaml
+  ingressPort: 0
+  appPort: 0
+  tlsUprobes: false
+  tlsLibraries: []
+  javaAgent: ""
+  apps: []
+  maxConnsPerHost: 0
+  circuitBreaker:
+    failures: 0
+    cooldown: 30s
+  annotatePort: 16790
+  sourceIPs: []
+  captureUserAgent: false
+  sampleRate: 0
+  maxPerEndpoint: 0
+  reservoir: 0
+  continuous: false
+  rotation: 1h
+  maxDiskSize: 0
+  redact:
+    headers: []
+    fields: []
+    patterns: []
+  dependencies: []
+  tui: false
+load:
+  testset: []
+  rps: 10
+  duration: 1m
+  baseUrl: ""
+  apiTimeout: 5
+contract:
+  contracts: "./contracts"
+  testsets: []
+  service: ""
+  provider: ""
+  baseUrl: ""
+remote:
+  bucket: ""
+  prefix: "keploy"
+  endpoint: ""
+  region: ""
+  version: ""
+  testsets: []
 configPath: ""
 bypassRules: []
+udp: []
+storage:
+  driver: yaml
+  uri: ""
+  database: keploy
+bundle:
+  file: "./keploy.keploy"
+  testsets: []
+tracing:
+  enabled: false
+  endpoint: ""
+ui:
+  port: 6790
+mockServer:
+  port: 6791
+  testSet: ""
+ingress:
+  testset: []
+  url: ""
+  apiTimeout: 5
+report:
+  testsets: []
+  latencyThreshold: 20
+  flakyRuns: 5
+k8s:
+  image: ghcr.io/keploy/keploy
+  appImage: ""
+  pvc: ""
+agent:
+  port: 6791
+  socket: /var/run/keploy-agent.sock
+  token: ""
+  tlsCert: ""
+  tlsKey: ""
+serve:
+  token: ""
+  tlsCert: ""
+  tlsKey: ""
+encryption:
+  enabled: false
+  keyCommand: ""
+analyze:
+  runs: 5
+  testsets: []
+  plan: ""
+selfUpdate:
+  channel: stable
+  offline: ""
+  publicKey: ""
+  skipSignature: false
 `
 
 func GetDefaultConfig() string {
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/spf13/cobra"
 	"go.keploy.io/server/v2/cli"
 	"go.keploy.io/server/v2/cli/provider"
 	"go.keploy.io/server/v2/config"
@@ -51,6 +52,10 @@ func printLogo() {
 		version = "2-dev"
 	}
 	utils.Version = version
+	// the completion scripts and the completions requested by the shell are read from stdout, the logo would break them
+	if len(os.Args) > 1 && (os.Args[1] == "completion" || os.Args[1] == cobra.ShellCompRequestCmd || os.Args[1] == cobra.ShellCompNoDescRequestCmd) {
+		return
+	}
 	if binaryToDocker := os.Getenv("BINARY_TO_DOCKER"); binaryToDocker != "true" {
 		fmt.Println(logo, " ")
 		fmt.Printf("version: %v\n\n", version)
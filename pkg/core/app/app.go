@@ -2,16 +2,22 @@
 package app
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 
 	"github.com/docker/docker/api/types"
@@ -20,6 +26,7 @@ import (
 	"go.keploy.io/server/v2/pkg/core/app/docker"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
 )
 
 func NewApp(logger *zap.Logger, id uint64, cmd string, opts Options) *App {
@@ -32,6 +39,10 @@ func NewApp(logger *zap.Logger, id uint64, cmd string, opts Options) *App {
 		container:        opts.Container,
 		containerDelay:   opts.DockerDelay,
 		containerNetwork: opts.DockerNetwork,
+		env:              opts.Env,
+		randomSeed:       opts.RandomSeed,
+		client:           opts.Client,
+		started:          make(chan uint32, 1),
 	}
 	return app
 }
@@ -49,9 +60,17 @@ type App struct {
 	keployNetwork    string
 	keployContainer  string
 	keployIPv4       string
+	keployIPv6       string // empty if the network of the app has no IPv6
+	ownNetwork       bool   // the network of the app was created by keploy and is removed on exit
 	inodeChan        chan uint64
+	started          chan uint32 // the pid of the command of the native app once it is started
+	env              []string
+	randomSeed       int64
+	randomShimDir    string // removed on exit
+	client           *config.Client
 	EnableTesting    bool
 	Mode             models.Mode
+	logs             logCapture
 }
 
 type Options struct {
@@ -60,6 +79,12 @@ type Options struct {
 	Container     string
 	DockerDelay   time.Duration
 	DockerNetwork string
+	// Env is added to the environment of the native app.
+	Env []string
+	// RandomSeed seeds the randomness of the native app, 0 disables it.
+	RandomSeed int64
+	// Client is the user of keploy agent the app is run as, nil to run it as the user who invoked sudo.
+	Client *config.Client
 }
 
 func (a *App) Setup(_ context.Context) error {
@@ -75,17 +100,32 @@ func (a *App) Setup(_ context.Context) error {
 
 	switch a.kind {
 	case utils.Docker:
+		if a.randomSeed != 0 {
+			a.logger.Warn("seeding the randomness is not supported for docker apps")
+		}
 		err := a.SetupDocker()
 		if err != nil {
 			return err
 		}
 	case utils.DockerCompose:
+		if a.randomSeed != 0 {
+			a.logger.Warn("seeding the randomness is not supported for docker apps")
+		}
 		err = a.SetupCompose()
 		if err != nil {
 			return err
 		}
 	default:
 		// setup native binary
+		if a.randomSeed != 0 {
+			dir, lib, err := buildRandomShim()
+			if err != nil {
+				return err
+			}
+			a.randomShimDir = dir
+			a.env = randomShimEnv(a.env, lib, a.randomSeed)
+			a.logger.Debug("seeding the randomness of the app", zap.String("shim", lib), zap.Int64("seed", a.randomSeed))
+		}
 	}
 	return nil
 }
@@ -94,6 +134,10 @@ func (a *App) KeployIPv4Addr() string {
 	return a.keployIPv4
 }
 
+func (a *App) KeployIPv6Addr() string {
+	return a.keployIPv6
+}
+
 func (a *App) ContainerIPv4Addr() string {
 	return a.containerIPv4
 }
@@ -111,7 +155,21 @@ func (a *App) SetupDocker() error {
 		a.logger.Warn(fmt.Sprintf("given app container:(%v) is different from parsed app container:(%v)", a.container, cont))
 	}
 
-	if a.containerNetwork == "" {
+	if net == "" {
+		// the command of the app has no network, it is put on the given network or on a network of its own
+		if a.containerNetwork == "" {
+			a.containerNetwork, err = a.createNetwork()
+			if err != nil {
+				return err
+			}
+		}
+		a.cmd, err = addDockerNetwork(a.cmd, a.containerNetwork)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to add the network to the docker command", zap.String("cmd", a.cmd))
+			return err
+		}
+		a.logger.Info("running the app on the docker network of keploy", zap.String("network", a.containerNetwork), zap.String("cmd", a.cmd))
+	} else if a.containerNetwork == "" {
 		a.containerNetwork = net
 	} else if a.containerNetwork != net {
 		a.logger.Warn(fmt.Sprintf("given docker network:(%v) is different from parsed docker network:(%v)", a.containerNetwork, net))
@@ -126,6 +184,96 @@ func (a *App) SetupDocker() error {
 	return nil
 }
 
+// createNetwork creates the docker network of the app shared with keploy, named after the container of the app.
+func (a *App) createNetwork() (string, error) {
+	network := "keploy-" + a.container
+	ok, err := a.docker.NetworkExists(network)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to find the network of the app", zap.String("network", network))
+		return "", err
+	}
+	// the network left behind by a keploy which didn't exit cleanly is reused
+	if !ok {
+		err = a.docker.CreateNetwork(network)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to create the network of the app", zap.String("network", network))
+			return "", err
+		}
+	}
+	a.ownNetwork = true
+	return network, nil
+}
+
+// AddEnv adds the environment to the native app e.g. the proxy of keploy in the proxy mode.
+func (a *App) AddEnv(env []string) {
+	a.env = append(a.env, env...)
+}
+
+// Cleanup removes the network created for the app and the random shim, once the app is stopped.
+func (a *App) Cleanup() {
+	if a.randomShimDir != "" {
+		if err := os.RemoveAll(a.randomShimDir); err != nil {
+			utils.LogError(a.logger, err, "failed to remove the random shim", zap.String("dir", a.randomShimDir))
+		}
+	}
+	if !a.ownNetwork {
+		return
+	}
+	// the app container is removed with --rm once it stops, which can take a moment
+	var err error
+	for i := 0; i < 10; i++ {
+		err = a.docker.RemoveNetwork(a.containerNetwork)
+		if err == nil {
+			a.logger.Debug("removed the network of the app", zap.String("network", a.containerNetwork))
+			return
+		}
+		time.Sleep(time.Second)
+	}
+	utils.LogError(a.logger, err, "failed to remove the network of the app", zap.String("network", a.containerNetwork))
+}
+
+// readComposeFile reads the compose file, as the client of keploy agent if any so that the agent never opens a path
+// of the client itself.
+func (a *App) readComposeFile(path string) (*docker.Compose, error) {
+	if a.client == nil {
+		return a.docker.ReadComposeFile(path)
+	}
+	cmd := exec.Command("cat", "--", path)
+	setProcAttr(cmd, a.client)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	var compose docker.Compose
+	err = yaml.Unmarshal(data, &compose)
+	if err != nil {
+		return nil, err
+	}
+	return &compose, nil
+}
+
+// writeComposeFile writes the compose file, as the client of keploy agent if any so that the file is owned by the
+// client.
+func (a *App) writeComposeFile(compose *docker.Compose, path string) error {
+	if a.client == nil {
+		return a.docker.WriteComposeFile(compose, path)
+	}
+	data, err := yaml.Marshal(compose)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command("sh", "-c", `exec cat > "$1"`, "sh", path)
+	setProcAttr(cmd, a.client)
+	cmd.Stdin = bytes.NewReader(data)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
 func (a *App) SetupCompose() error {
 	if a.container == "" {
 		utils.LogError(a.logger, nil, "container name not found", zap.String("AppCmd", a.cmd))
@@ -136,14 +284,20 @@ func (a *App) SetupCompose() error {
 	// TODO currently we just return the first default docker-compose file found in the current directory
 	// we should add support for multiple docker-compose files by either parsing cmd for path
 	// or by asking the user to provide the path
-	path := findComposeFile()
+	// the compose app of a client of keploy agent is run in the directory of the client
+	var dir string
+	if a.client != nil {
+		dir = a.client.Dir
+	}
+	path := findComposeFile(dir)
 	if path == "" {
 		return errors.New("can't find the docker compose file of user. Are you in the right directory? ")
 	}
 	// kdocker-compose.yaml file will be run instead of the user docker-compose.yaml file acc to below cases
-	newPath := "docker-compose-tmp.yaml"
+	newName := "docker-compose-tmp.yaml"
+	newPath := filepath.Join(dir, newName)
 
-	compose, err := a.docker.ReadComposeFile(path)
+	compose, err := a.readComposeFile(path)
 	if err != nil {
 		utils.LogError(a.logger, err, "failed to read the compose file")
 		return err
@@ -200,13 +354,13 @@ func (a *App) SetupCompose() error {
 	}
 
 	if composeChanged {
-		err = a.docker.WriteComposeFile(compose, newPath)
+		err = a.writeComposeFile(compose, newPath)
 		if err != nil {
-			utils.LogError(a.logger, nil, "failed to write the compose file", zap.String("path", newPath))
+			utils.LogError(a.logger, err, "failed to write the compose file", zap.String("path", newPath))
 		}
 		a.logger.Info("Created new docker-compose for keploy internal use", zap.String("path", newPath))
 		//Now replace the running command to run the kdocker-compose.yaml file instead of user docker compose file.
-		a.cmd = modifyDockerComposeCommand(a.cmd, newPath)
+		a.cmd = modifyDockerComposeCommand(a.cmd, newName)
 	}
 
 	if a.containerNetwork == "" {
@@ -251,6 +405,7 @@ func (a *App) injectNetwork(network string) error {
 	for n, settings := range keployNetworks {
 		if n == network {
 			a.keployIPv4 = settings.IPAddress
+			a.keployIPv6 = settings.GlobalIPv6Address
 			a.logger.Info("Successfully injected network to the keploy container", zap.Any("Keploy container", a.keployContainer), zap.Any("appNetwork", network))
 			return nil
 		}
@@ -418,15 +573,41 @@ func (a *App) runDocker(ctx context.Context) models.AppError {
 	}
 }
 
+// Started returns the pid of the command of the native app once it is started by Run.
+func (a *App) Started() <-chan uint32 {
+	return a.started
+}
+
+// StartLogCapture starts capturing the stdout and stderr of the app.
+func (a *App) StartLogCapture() {
+	a.logs.start()
+}
+
+// StopLogCapture stops capturing and returns the stdout and stderr of the app written since StartLogCapture.
+func (a *App) StopLogCapture() string {
+	return a.logs.stop()
+}
+
 func (a *App) Run(ctx context.Context, inodeChan chan uint64) models.AppError {
 	a.inodeChan = inodeChan
 
 	if a.kind == utils.DockerCompose || a.kind == utils.Docker {
 		return a.runDocker(ctx)
 	}
+	if a.cmd == "" {
+		return a.attach(ctx)
+	}
 	return a.run(ctx)
 }
 
+// attach runs without a command, the hooks intercept the processes of the pid namespace of keploy which is shared
+// with the app e.g. when keploy is the sidecar of the app in a kubernetes pod.
+func (a *App) attach(ctx context.Context) models.AppError {
+	a.logger.Info("no command of the app, attached to the processes sharing the pid namespace of keploy")
+	<-ctx.Done()
+	return models.AppError{AppErrorType: models.ErrCtxCanceled, Err: ctx.Err()}
+}
+
 func (a *App) run(ctx context.Context) models.AppError {
 	// Run the app as the user who invoked sudo
 	userCmd := a.cmd
@@ -436,12 +617,19 @@ func (a *App) run(ctx context.Context) models.AppError {
 		userCmd = utils.EnsureRmBeforeName(userCmd)
 	}
 
-	cmd := exec.CommandContext(ctx, "sh", "-c", userCmd)
-	if username != "" {
+	cmd := shellCommand(ctx, userCmd)
+	if a.client != nil {
+		// Run the command as the client of keploy agent, the agent is not run with sudo by the client
+		cmd.Dir = a.client.Dir
+		cmd.Env = append(append([]string{}, a.client.Env...), a.env...)
+	} else if username != "" {
 		// print all environment variables
 		a.logger.Debug("env inherited from the cmd", zap.Any("env", os.Environ()))
 		// Run the command as the user who invoked sudo to preserve the user environment variables and PATH
-		cmd = exec.CommandContext(ctx, "sudo", "-E", "-u", os.Getenv("SUDO_USER"), "env", "PATH="+os.Getenv("PATH"), "sh", "-c", userCmd)
+		args := append([]string{"-E", "-u", os.Getenv("SUDO_USER"), "env", "PATH=" + os.Getenv("PATH")}, a.env...)
+		cmd = exec.CommandContext(ctx, "sudo", append(args, "sh", "-c", userCmd)...)
+	} else if len(a.env) > 0 {
+		cmd.Env = append(os.Environ(), a.env...)
 	}
 
 	// Set the cancel function for the command
@@ -452,20 +640,26 @@ func (a *App) run(ctx context.Context) models.AppError {
 	// wait after sending the interrupt signal, before sending the kill signal
 	cmd.WaitDelay = 10 * time.Second
 
-	cmd.SysProcAttr = &syscall.SysProcAttr{
-		Setpgid: true,
-	}
+	setProcAttr(cmd, a.client)
+	// the UDP datagrams of the app are intercepted by the cgroup of its processes
+	utils.PlaceInAppCgroup(cmd)
 
 	// Set the output of the command
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stdout = a.logs.writer(os.Stdout)
+	cmd.Stderr = a.logs.writer(os.Stderr)
 
 	a.logger.Debug("", zap.Any("executing cli", cmd.String()))
 
+	// the pid of a previous run which wasn't received
+	select {
+	case <-a.started:
+	default:
+	}
 	err := cmd.Start()
 	if err != nil {
 		return models.AppError{AppErrorType: models.ErrCommandError, Err: err}
 	}
+	a.started <- uint32(cmd.Process.Pid)
 
 	err = cmd.Wait()
 	select {
@@ -541,3 +735,26 @@ func (a *App) run(ctx context.Context) models.AppError {
 //	a.docker.SetContainerID(e.ID)
 //
 //	a.logger.Debug("container created for desired app", zap.Any("ID", e.ID))
+
+// UID returns the user the native app is run as, the client of keploy agent or the user who invoked sudo, or nil
+// if it is run as the user of keploy.
+func (a *App) UID() *uint32 {
+	var uid uint32
+	if a.client != nil {
+		uid = a.client.UID
+	} else {
+		u, err := user.Lookup(os.Getenv("SUDO_USER"))
+		if err != nil {
+			return nil
+		}
+		id, err := strconv.ParseUint(u.Uid, 10, 32)
+		if err != nil {
+			return nil
+		}
+		uid = uint32(id)
+	}
+	if int(uid) == os.Geteuid() {
+		return nil
+	}
+	return &uid
+}
@@ -0,0 +1,134 @@
+// random.c is the LD_PRELOAD shim which makes the randomness of the app reproducible. getrandom, getentropy and the
+// reads of /dev/urandom and /dev/random are served from a PRNG seeded with KEPLOY_RANDOM_SEED.
+#define _GNU_SOURCE
+#include <dlfcn.h>
+#include <errno.h>
+#include <fcntl.h>
+#include <pthread.h>
+#include <stdarg.h>
+#include <stdint.h>
+#include <stdlib.h>
+#include <string.h>
+#include <sys/types.h>
+#include <unistd.h>
+
+#define MAX_FDS 4096
+
+static pthread_mutex_t mu = PTHREAD_MUTEX_INITIALIZER;
+static uint64_t state;
+static int seeded;
+// random_fds marks the fds opened on the random devices
+static char random_fds[MAX_FDS];
+
+// splitmix64, good enough for reproducible ids and nonces, not for cryptography
+static uint64_t next(void) {
+    uint64_t z = (state += 0x9e3779b97f4a7c15ULL);
+    z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9ULL;
+    z = (z ^ (z >> 27)) * 0x94d049bb133111ebULL;
+    return z ^ (z >> 31);
+}
+
+static void fill(void *buf, size_t n) {
+    unsigned char *p = buf;
+    pthread_mutex_lock(&mu);
+    if (!seeded) {
+        const char *seed = getenv("KEPLOY_RANDOM_SEED");
+        state = seed ? strtoull(seed, NULL, 10) : 0;
+        seeded = 1;
+    }
+    while (n > 0) {
+        uint64_t v = next();
+        size_t k = n < sizeof(v) ? n : sizeof(v);
+        memcpy(p, &v, k);
+        p += k;
+        n -= k;
+    }
+    pthread_mutex_unlock(&mu);
+}
+
+static int is_random_device(const char *path) {
+    return path && (strcmp(path, "/dev/urandom") == 0 || strcmp(path, "/dev/random") == 0);
+}
+
+static int track(const char *path, int fd) {
+    if (fd >= 0 && fd < MAX_FDS) {
+        random_fds[fd] = is_random_device(path);
+    }
+    return fd;
+}
+
+ssize_t getrandom(void *buf, size_t n, unsigned int flags) {
+    (void)flags;
+    fill(buf, n);
+    return n;
+}
+
+int getentropy(void *buf, size_t n) {
+    if (n > 256) {
+        errno = EIO;
+        return -1;
+    }
+    fill(buf, n);
+    return 0;
+}
+
+#define OPEN_MODE(flags, mode)                  \
+    do {                                        \
+        if ((flags) & (O_CREAT | O_TMPFILE)) {  \
+            va_list args;                       \
+            va_start(args, flags);              \
+            mode = va_arg(args, mode_t);        \
+            va_end(args);                       \
+        }                                       \
+    } while (0)
+
+int open(const char *path, int flags, ...) {
+    static int (*real)(const char *, int, ...);
+    mode_t mode = 0;
+    OPEN_MODE(flags, mode);
+    if (!real) real = dlsym(RTLD_NEXT, "open");
+    return track(path, real(path, flags, mode));
+}
+
+int open64(const char *path, int flags, ...) {
+    static int (*real)(const char *, int, ...);
+    mode_t mode = 0;
+    OPEN_MODE(flags, mode);
+    if (!real) real = dlsym(RTLD_NEXT, "open64");
+    return track(path, real(path, flags, mode));
+}
+
+int openat(int dirfd, const char *path, int flags, ...) {
+    static int (*real)(int, const char *, int, ...);
+    mode_t mode = 0;
+    OPEN_MODE(flags, mode);
+    if (!real) real = dlsym(RTLD_NEXT, "openat");
+    return track(path, real(dirfd, path, flags, mode));
+}
+
+int openat64(int dirfd, const char *path, int flags, ...) {
+    static int (*real)(int, const char *, int, ...);
+    mode_t mode = 0;
+    OPEN_MODE(flags, mode);
+    if (!real) real = dlsym(RTLD_NEXT, "openat64");
+    return track(path, real(dirfd, path, flags, mode));
+}
+
+ssize_t read(int fd, void *buf, size_t n) {
+    static ssize_t (*real)(int, void *, size_t);
+    if (fd >= 0 && fd < MAX_FDS && random_fds[fd]) {
+        fill(buf, n);
+        return n;
+    }
+    if (!real) real = dlsym(RTLD_NEXT, "read");
+    return real(fd, buf, n);
+}
+
+int close(int fd) {
+    static int (*real)(int);
+    if (fd >= 0 && fd < MAX_FDS) {
+        random_fds[fd] = 0;
+    }
+    if (!real) real = dlsym(RTLD_NEXT, "close");
+    return real(fd);
+}
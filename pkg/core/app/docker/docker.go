@@ -200,6 +200,24 @@ func (idc *Impl) CreateNetwork(networkName string) error {
 	return err
 }
 
+// RemoveNetwork disconnects the containers left on the given network and removes it.
+func (idc *Impl) RemoveNetwork(networkName string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), idc.timeoutForDockerQuery)
+	defer cancel()
+
+	info, err := idc.NetworkInspect(ctx, networkName, types.NetworkInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to inspect the network %s: %w", networkName, err)
+	}
+	for id := range info.Containers {
+		err := idc.NetworkDisconnect(ctx, networkName, id, true)
+		if err != nil {
+			return fmt.Errorf("failed to disconnect the container %s from the network %s: %w", id, networkName, err)
+		}
+	}
+	return idc.NetworkRemove(ctx, networkName)
+}
+
 // Compose structure to represent all the fields of a Docker Compose file
 type Compose struct {
 	Version  string    `yaml:"version,omitempty"`
@@ -367,12 +385,16 @@ func (idc *Impl) GetHostWorkingDirectory() (string, error) {
 
 // ForceAbsolutePath replaces relative paths in bind mounts with absolute paths
 func (idc *Impl) ForceAbsolutePath(c *Compose, basePath string) error {
-	hostWorkingDirectory, err := idc.GetHostWorkingDirectory()
-	if err != nil {
-		return err
+	dockerComposeContext := basePath
+	// the relative compose file is in the working directory, of the host if keploy runs in docker
+	if !filepath.IsAbs(basePath) {
+		hostWorkingDirectory, err := idc.GetHostWorkingDirectory()
+		if err != nil {
+			return err
+		}
+		dockerComposeContext = filepath.Join(hostWorkingDirectory, basePath)
 	}
-
-	dockerComposeContext, err := filepath.Abs(filepath.Join(hostWorkingDirectory, basePath))
+	dockerComposeContext, err := filepath.Abs(dockerComposeContext)
 	if err != nil {
 		utils.LogError(idc.logger, err, "error getting absolute path for docker compose file")
 		return err
@@ -21,6 +21,7 @@ type Client interface {
 	GetNetworkInfo(compose *Compose) *NetworkInfo
 
 	CreateNetwork(network string) error
+	RemoveNetwork(network string) error
 	MakeNetworkExternal(c *Compose) error
 	SetKeployNetwork(c *Compose) (*NetworkInfo, error)
 	ReadComposeFile(filePath string) (*Compose, error)
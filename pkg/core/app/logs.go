@@ -0,0 +1,62 @@
+package app
+
+import (
+	"io"
+	"sync"
+)
+
+// maxCapturedLogs is the max size of the captured output of the app, the oldest output is dropped beyond it.
+const maxCapturedLogs = 64 * 1024
+
+// logCapture keeps the stdout and stderr of the app written while it is capturing, the output is still
+// written to the stdout and stderr of keploy.
+type logCapture struct {
+	mu        sync.Mutex
+	capturing bool
+	buf       []byte
+}
+
+// writer returns the writer of the app output which is written to out and captured.
+func (l *logCapture) writer(out io.Writer) io.Writer {
+	return &captureWriter{out: out, capture: l}
+}
+
+// start discards the previously captured output and starts capturing.
+func (l *logCapture) start() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.capturing = true
+	l.buf = l.buf[:0]
+}
+
+// stop stops capturing and returns the output captured since start.
+func (l *logCapture) stop() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.capturing = false
+	logs := string(l.buf)
+	l.buf = l.buf[:0]
+	return logs
+}
+
+func (l *logCapture) write(p []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.capturing {
+		return
+	}
+	l.buf = append(l.buf, p...)
+	if len(l.buf) > maxCapturedLogs {
+		l.buf = append(l.buf[:0], l.buf[len(l.buf)-maxCapturedLogs:]...)
+	}
+}
+
+type captureWriter struct {
+	out     io.Writer
+	capture *logCapture
+}
+
+func (w *captureWriter) Write(p []byte) (int, error) {
+	w.capture.write(p)
+	return w.out.Write(p)
+}
@@ -0,0 +1,68 @@
+//go:build !windows
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+
+	"go.keploy.io/server/v2/config"
+)
+
+// shellCommand runs the command of the app with the shell.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// setProcAttr runs the app in its own process group, so that its whole process tree is interrupted, and as the
+// client of keploy agent if any.
+func setProcAttr(cmd *exec.Cmd, client *config.Client) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Setpgid: true,
+	}
+	if client != nil {
+		cmd.SysProcAttr.Credential = ClientCredential(client)
+	}
+}
+
+func getInode(pid int) (uint64, error) {
+	path := filepath.Join("/proc", strconv.Itoa(pid), "ns", "pid")
+
+	f, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	// Dev := (f.Sys().(*syscall.Stat_t)).Dev
+	i := (f.Sys().(*syscall.Stat_t)).Ino
+	if i == 0 {
+		return 0, fmt.Errorf("failed to get the inode of the process")
+	}
+	return i, nil
+}
+
+// ClientCredential returns the credential of the client of keploy agent with its supplementary groups, e.g. so
+// that its docker commands can still reach the docker daemon.
+func ClientCredential(client *config.Client) *syscall.Credential {
+	cred := &syscall.Credential{Uid: client.UID, Gid: client.GID}
+	u, err := user.LookupId(strconv.FormatUint(uint64(client.UID), 10))
+	if err != nil {
+		return cred
+	}
+	gids, err := u.GroupIds()
+	if err != nil {
+		return cred
+	}
+	for _, gid := range gids {
+		id, err := strconv.ParseUint(gid, 10, 32)
+		if err == nil {
+			cred.Groups = append(cred.Groups, uint32(id))
+		}
+	}
+	return cred
+}
@@ -0,0 +1,21 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+
+	"go.keploy.io/server/v2/config"
+)
+
+// shellCommand runs the command of the app with cmd.exe.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd", "/C", command)
+}
+
+// setProcAttr is a no-op, the app is run as the user of keploy on Windows.
+func setProcAttr(_ *exec.Cmd, _ *config.Client) {}
+
+func getInode(_ int) (uint64, error) {
+	return 0, errors.New("the pid namespaces are only available on linux")
+}
@@ -0,0 +1,66 @@
+package app
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// The random shim is built for amd64 and arm64 by the release and the docker image before keploy is built, and
+// embedded from the asset dir.
+//go:generate x86_64-linux-gnu-gcc -shared -fPIC -O2 -o asset/librandom_amd64.so asset/random.c -ldl -lpthread
+//go:generate aarch64-linux-gnu-gcc -shared -fPIC -O2 -o asset/librandom_arm64.so asset/random.c -ldl -lpthread
+
+//go:embed asset
+var randomShimAssets embed.FS
+
+// buildRandomShim writes the embedded LD_PRELOAD shim which serves the randomness of the app from a seeded PRNG to
+// a temp dir, and returns the dir and the path of the shared library. The apps which make the getrandom syscall by
+// themselves (e.g. go) are not affected. The dir is removed by the caller once the app is stopped.
+func buildRandomShim() (dir string, lib string, err error) {
+	shim, err := randomShimAssets.ReadFile("asset/librandom_" + runtime.GOARCH + ".so")
+	if err != nil {
+		return "", "", fmt.Errorf("keploy is built without the random shim for %s to seed the randomness, build it with go generate ./pkg/core/app before building keploy", runtime.GOARCH)
+	}
+	dir, err = os.MkdirTemp("", "keploy-random-")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create the dir of the random shim: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(dir)
+		}
+	}()
+	// the app runs as the user who invoked sudo, so the shim is kept readable by everyone
+	err = os.Chmod(dir, 0755)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to change the permissions of the dir of the random shim: %w", err)
+	}
+
+	lib = filepath.Join(dir, "librandom.so")
+	err = os.WriteFile(lib, shim, 0755)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to write the random shim: %w", err)
+	}
+	return dir, lib, nil
+}
+
+// randomShimEnv returns the env which preloads the random shim with the given seed, the shim is appended to
+// the LD_PRELOAD of the given env if any.
+func randomShimEnv(env []string, lib string, seed int64) []string {
+	preloaded := false
+	for i, e := range env {
+		if strings.HasPrefix(e, "LD_PRELOAD=") {
+			env[i] = e + ":" + lib
+			preloaded = true
+		}
+	}
+	if !preloaded {
+		env = append(env, "LD_PRELOAD="+lib)
+	}
+	return append(env, "KEPLOY_RANDOM_SEED="+strconv.FormatInt(seed, 10))
+}
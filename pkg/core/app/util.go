@@ -5,17 +5,18 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strconv"
 	"strings"
-	"syscall"
 )
 
-func findComposeFile() string {
+// findComposeFile returns the path of the first default compose file found in the directory, the working directory
+// if it is empty.
+func findComposeFile(dir string) string {
 	filenames := []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"}
 
 	for _, filename := range filenames {
-		if _, err := os.Stat(filename); !os.IsNotExist(err) {
-			return filename
+		path := filepath.Join(dir, filename)
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			return path
 		}
 	}
 
@@ -60,30 +61,25 @@ func ParseDockerCmd(cmd string) (string, string, error) {
 	}
 	containerName := containerNameMatches[1]
 
-	// Extract network name
+	// Extract network name, the app is put on a network of keploy if the command has none
 	networkNameRegex := regexp.MustCompile(networkNamePattern)
 	networkNameMatches := networkNameRegex.FindStringSubmatch(cmd)
 	if len(networkNameMatches) < 3 {
-		return containerName, "", fmt.Errorf("failed to parse network name")
+		return containerName, "", nil
 	}
 	networkName := networkNameMatches[2]
 
 	return containerName, networkName, nil
 }
 
-func getInode(pid int) (uint64, error) {
-	path := filepath.Join("/proc", strconv.Itoa(pid), "ns", "pid")
-
-	f, err := os.Stat(path)
-	if err != nil {
-		return 0, err
-	}
-	// Dev := (f.Sys().(*syscall.Stat_t)).Dev
-	i := (f.Sys().(*syscall.Stat_t)).Ino
-	if i == 0 {
-		return 0, fmt.Errorf("failed to get the inode of the process")
+// addDockerNetwork adds the network to the docker run command of the app.
+func addDockerNetwork(cmd, network string) (string, error) {
+	runRegex := regexp.MustCompile(`docker\s+(container\s+)?run`)
+	loc := runRegex.FindStringIndex(cmd)
+	if loc == nil {
+		return "", fmt.Errorf("failed to find docker run in the command of the app")
 	}
-	return i, nil
+	return fmt.Sprintf("%s --network %s%s", cmd[:loc[1]], network, cmd[loc[1]:]), nil
 }
 
 func IsDetachMode(command string) bool {
@@ -41,6 +41,9 @@ func (c *Core) Setup(ctx context.Context, cmd string, opts models.SetupOptions)
 		DockerNetwork: opts.DockerNetwork,
 		Container:     opts.Container,
 		DockerDelay:   opts.DockerDelay,
+		Env:           opts.Env,
+		RandomSeed:    opts.RandomSeed,
+		Client:        opts.Client,
 	})
 	c.apps.Store(id, a)
 
@@ -121,6 +124,8 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 			utils.LogError(c.logger, err, "failed to unload the hooks")
 		}
 
+		// the app is stopped before the hooks are unloaded
+		a.Cleanup()
 		return nil
 	})
 
@@ -130,12 +135,17 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 		Pid:        0,
 		IsDocker:   isDocker,
 		KeployIPV4: a.KeployIPv4Addr(),
+		KeployIPV6: a.KeployIPv6Addr(),
 		Mode:       opts.Mode,
+		AppUID:     a.UID(),
 	})
 	if err != nil {
 		utils.LogError(c.logger, err, "failed to load hooks")
 		return hookErr
 	}
+	if envInfo, ok := c.Hooks.(EnvInfo); ok {
+		a.AddEnv(envInfo.Env(id))
+	}
 
 	if c.proxyStarted {
 		c.logger.Debug("Proxy already started")
@@ -154,7 +164,7 @@ func (c *Core) Hook(ctx context.Context, id uint64, opts models.HookOptions) err
 	// start proxy
 	err = c.Proxy.StartProxy(proxyCtx, ProxyOptions{
 		DNSIPv4Addr: a.KeployIPv4Addr(),
-		//DnsIPv6Addr: ""
+		DNSIPv6Addr: a.KeployIPv6Addr(),
 	})
 	if err != nil {
 		utils.LogError(c.logger, err, "failed to start proxy")
@@ -206,6 +216,18 @@ func (c *Core) Run(ctx context.Context, id uint64, _ models.RunOptions) models.A
 	runAppErrGrp.Go(func() error {
 		defer utils.Recover(c.logger)
 		if a.Kind(ctx) == utils.Native {
+			processInfo, ok := c.Hooks.(ProcessInfo)
+			if !ok {
+				return nil
+			}
+			select {
+			case pid := <-a.Started():
+				err := processInfo.TrackProcess(ctx, id, pid)
+				if err != nil {
+					utils.LogError(c.logger, err, "failed to track the processes of the app")
+				}
+			case <-ctx.Done():
+			}
 			return nil
 		}
 		select {
@@ -253,3 +275,24 @@ func (c *Core) GetAppIP(_ context.Context, id uint64) (string, error) {
 
 	return a.ContainerIPv4Addr(), nil
 }
+
+// CaptureAppLogs starts capturing the stdout and stderr of the app, until GetAppLogs is called.
+func (c *Core) CaptureAppLogs(_ context.Context, id uint64) error {
+	a, err := c.getApp(id)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to get app")
+		return err
+	}
+	a.StartLogCapture()
+	return nil
+}
+
+// GetAppLogs stops capturing and returns the stdout and stderr of the app written since CaptureAppLogs.
+func (c *Core) GetAppLogs(_ context.Context, id uint64) (string, error) {
+	a, err := c.getApp(id)
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to get app")
+		return "", err
+	}
+	return a.StopLogCapture(), nil
+}
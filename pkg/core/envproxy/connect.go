@@ -0,0 +1,129 @@
+package envproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// GetConnDest reads the destination from the first request of the app to the proxy. The CONNECT request of a
+// tunnel is answered and dropped, the first request of a plain http connection is kept for the proxy.
+func (e *EnvProxy) GetConnDest(ctx context.Context, conn net.Conn) (*core.NetworkAddress, net.Conn, error) {
+	reader := bufio.NewReader(conn)
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the request of the app to the proxy: %w", err)
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 3 || !strings.HasPrefix(fields[2], "HTTP/") {
+		return nil, nil, fmt.Errorf("the app didn't send an http proxy request: %q", strings.TrimSpace(line))
+	}
+	method, target := fields[0], fields[1]
+
+	var hostPort string
+	var kept []byte
+	if method == http.MethodConnect {
+		for {
+			header, err := reader.ReadString('\n')
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to read the CONNECT request of the app: %w", err)
+			}
+			if header == "\r\n" || header == "\n" {
+				break
+			}
+		}
+		_, err = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to answer the CONNECT request of the app: %w", err)
+		}
+		hostPort = target
+	} else {
+		u, err := url.Parse(target)
+		if err != nil || u.Host == "" {
+			return nil, nil, fmt.Errorf("the app sent a request to the proxy without an absolute url: %q", target)
+		}
+		hostPort = u.Host
+		if u.Port() == "" {
+			port := "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+			hostPort = net.JoinHostPort(u.Hostname(), port)
+		}
+		kept = []byte(line)
+	}
+
+	addr, err := e.resolve(ctx, hostPort)
+	if err != nil {
+		return nil, nil, err
+	}
+	return addr, &bufferedConn{Conn: conn, r: io.MultiReader(bytes.NewReader(kept), reader)}, nil
+}
+
+// resolve resolves the destination of the app, preferring its IPv4 address.
+func (e *EnvProxy) resolve(ctx context.Context, hostPort string) (*core.NetworkAddress, error) {
+	host, portStr, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination %q of the app: %w", hostPort, err)
+	}
+	port, err := strconv.ParseUint(portStr, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port of the destination %q of the app: %w", hostPort, err)
+	}
+
+	e.mu.Lock()
+	addr := &core.NetworkAddress{AppID: e.appID, Version: 4, Port: uint32(port)}
+	mode := e.mode
+	e.mu.Unlock()
+
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		// the mocked dependencies needn't resolve in keploy test, e.g. on a CI runner outside their network
+		if mode == models.MODE_TEST {
+			e.logger.Debug("failed to resolve the destination of the app, it is mocked", zap.String("host", host), zap.Error(err))
+			return addr, nil
+		}
+		return nil, fmt.Errorf("failed to resolve the destination %s of the app: %w", host, err)
+	}
+	ip := ips[0]
+	for _, candidate := range ips {
+		if candidate.Unmap().Is4() {
+			ip = candidate
+			break
+		}
+	}
+	ip = ip.Unmap()
+	if ip.Is4() {
+		b := ip.As4()
+		addr.IPv4Addr = binary.BigEndian.Uint32(b[:])
+		return addr, nil
+	}
+	addr.Version = 6
+	b := ip.As16()
+	for i := range addr.IPv6Addr {
+		addr.IPv6Addr[i] = binary.BigEndian.Uint32(b[i*4:])
+	}
+	return addr, nil
+}
+
+// bufferedConn is the connection of the app still yielding the bytes read from it to find the destination.
+type bufferedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
@@ -0,0 +1,94 @@
+// Package envproxy provides the proxy mode of keploy, used in place of the eBPF hooks on macOS and Windows. The app
+// is given the proxy of keploy with HTTP_PROXY and HTTPS_PROXY, so only the calls of the app honoring them are
+// recorded and mocked, and the testcases are captured by forwarding the calls to the app from the ingress port.
+// The calls of a plain http connection to the proxy are assumed to go to the host of its first call.
+package envproxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+func New(logger *zap.Logger, cfg config.Config) *EnvProxy {
+	return &EnvProxy{
+		logger:      logger,
+		sess:        core.NewSessions(),
+		proxyPort:   cfg.ProxyPort,
+		ingressPort: cfg.Record.IngressPort,
+		appPort:     cfg.Record.AppPort,
+	}
+}
+
+type EnvProxy struct {
+	logger      *zap.Logger
+	sess        *core.Sessions
+	proxyPort   uint32
+	ingressPort uint32
+	appPort     uint32
+
+	mu    sync.Mutex
+	appID uint64
+	mode  models.Mode
+}
+
+func (e *EnvProxy) Load(_ context.Context, id uint64, opts core.HookCfg) error {
+	if opts.IsDocker {
+		return errors.New("the proxy mode only supports native apps, the docker apps are run with the linux keploy in docker")
+	}
+	e.sess.Set(id, &core.Session{ID: id, Mode: opts.Mode})
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.appID = id
+	e.mode = opts.Mode
+	e.logger.Warn("running in the proxy mode, only the http and https calls of the app honoring HTTP_PROXY and HTTPS_PROXY are recorded and mocked, the calls to the databases and the DNS queries aren't intercepted")
+	return nil
+}
+
+// Env is the environment passing the proxy to the app.
+func (e *EnvProxy) Env(_ uint64) []string {
+	proxy := fmt.Sprintf("http://127.0.0.1:%d", e.proxyPort)
+	return []string{
+		"HTTP_PROXY=" + proxy,
+		"HTTPS_PROXY=" + proxy,
+		"http_proxy=" + proxy,
+		"https_proxy=" + proxy,
+	}
+}
+
+// Get isn't used, the proxy reads the destinations with GetConnDest.
+func (e *EnvProxy) Get(_ context.Context, srcPort uint16) (*core.NetworkAddress, error) {
+	return nil, fmt.Errorf("no destination of source port %d, the destinations are read from the connections", srcPort)
+}
+
+func (e *EnvProxy) Delete(_ context.Context, _ uint16) error {
+	return nil
+}
+
+// PassThroughPortsInKernel can't make the calls skip the proxy, the hosts to skip are set with NO_PROXY instead.
+func (e *EnvProxy) PassThroughPortsInKernel(_ context.Context, _ uint64, ports []uint) error {
+	if len(ports) > 0 {
+		e.logger.Debug("the ports aren't passed through in the proxy mode, set the hosts to skip the proxy with NO_PROXY", zap.Uints("ports", ports))
+	}
+	return nil
+}
+
+func (e *EnvProxy) SendInode(_ context.Context, _ uint64, _ uint64) error {
+	return nil
+}
+
+// SendKeployPids and SendKeployPorts are only used by the test bench of keploy, which needs the eBPF hooks.
+func (e *EnvProxy) SendKeployPids(_ models.ModeKey, _ uint32) error {
+	return errors.New("the test bench needs the eBPF hooks")
+}
+
+func (e *EnvProxy) SendKeployPorts(_ models.ModeKey, _ uint32) error {
+	return errors.New("the test bench needs the eBPF hooks")
+}
@@ -0,0 +1,134 @@
+package envproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/core/hooks/conn"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// Record captures the testcases of the calls to the app sent to the ingress port, forwarding them to the app.
+func (e *EnvProxy) Record(ctx context.Context, _ uint64) (<-chan *models.TestCase, error) {
+	if e.ingressPort == 0 || e.appPort == 0 {
+		return nil, errors.New("recording in the proxy mode needs the port keploy receives the calls to the app on and the port of the app, set them with --ingressPort and --appPort")
+	}
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		return nil, errors.New("failed to get the error group from the context")
+	}
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", e.ingressPort))
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to listen on the ingress port", zap.Uint32("port", e.ingressPort))
+		return nil, err
+	}
+
+	t := make(chan *models.TestCase, 500)
+	g.Go(func() error {
+		defer utils.Recover(e.logger)
+		stop := context.AfterFunc(ctx, func() {
+			_ = lis.Close()
+		})
+		defer stop()
+
+		var wg sync.WaitGroup
+		for {
+			clientConn, err := lis.Accept()
+			if err != nil {
+				break
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer utils.Recover(e.logger)
+				e.forward(ctx, clientConn, t)
+			}()
+		}
+		wg.Wait()
+		close(t)
+		return nil
+	})
+	e.logger.Info(fmt.Sprintf("send the calls to the app to localhost:%d to record them", e.ingressPort), zap.Uint32("app port", e.appPort))
+	return t, nil
+}
+
+// forward forwards the calls of the client connection to the app, capturing each call and its response.
+func (e *EnvProxy) forward(ctx context.Context, clientConn net.Conn, t chan *models.TestCase) {
+	defer clientConn.Close()
+	appConn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", e.appPort))
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to forward the call to the app", zap.Uint32("app port", e.appPort))
+		return
+	}
+	defer appConn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = clientConn.Close()
+		_ = appConn.Close()
+	})
+	defer stop()
+
+	// the app is called on its own port, as keploy test calls it
+	appHost := fmt.Sprintf("localhost:%d", e.appPort)
+	clientReader := bufio.NewReader(clientConn)
+	appReader := bufio.NewReader(appConn)
+	for {
+		req, err := http.ReadRequest(clientReader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				e.logger.Debug("failed to read the call to the app", zap.Error(err))
+			}
+			return
+		}
+		reqBody, err := io.ReadAll(req.Body)
+		if err != nil {
+			utils.LogError(e.logger, err, "failed to read the body of the call to the app")
+			return
+		}
+		reqTime := time.Now()
+		req.Host = appHost
+		req.RemoteAddr = clientConn.RemoteAddr().String()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		err = req.Write(appConn)
+		if err != nil {
+			utils.LogError(e.logger, err, "failed to forward the call to the app")
+			return
+		}
+
+		resp, err := http.ReadResponse(appReader, req)
+		if err != nil {
+			utils.LogError(e.logger, err, "failed to read the response of the app")
+			return
+		}
+		respBody, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			utils.LogError(e.logger, err, "failed to read the body of the response of the app")
+			return
+		}
+		resTime := time.Now()
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		err = resp.Write(clientConn)
+		if err != nil {
+			utils.LogError(e.logger, err, "failed to forward the response of the app")
+			return
+		}
+
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+		resp.Body = io.NopCloser(bytes.NewReader(respBody))
+		conn.Capture(ctx, e.logger, t, req, resp, reqTime, resTime)
+		if req.Close || resp.Close {
+			return
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// TrackProcess filters the traffic of the native app to the process tree of its command started as pid, which also
+// tells apart the apps recorded together.
+func (h *Hooks) TrackProcess(ctx context.Context, id uint64, pid uint32) error {
+	tree := newProcTree(pid)
+	h.appTrees.Store(id, tree)
+	go func() {
+		defer utils.Recover(h.logger)
+		tree.watch(ctx)
+	}()
+	h.logger.Debug("tracking the process tree of the app", zap.Uint64("app", id), zap.Uint32("pid", pid))
+	return nil
+}
+
+// AppOf returns the app the process, or the thread, is of. Until the command of an app is tracked, or if there is
+// none, e.g. when keploy is attached to the processes of its pid namespace, all the processes but keploy are of the
+// app hooked last.
+func (h *Hooks) AppOf(pid uint32) (uint64, bool) {
+	var id uint64
+	found, tracked := false, false
+	h.appTrees.Range(func(k, v any) bool {
+		tracked = true
+		if v.(*procTree).Contains(pid) {
+			id, found = k.(uint64), true
+			return false
+		}
+		return true
+	})
+	if tracked {
+		return id, found
+	}
+	if pid == uint32(os.Getpid()) {
+		return 0, false
+	}
+	return h.lastApp.Load(), true
+}
+
+// appRouter routes the events captured for all the apps by the shared eBPF programs to the apps they are of.
+type appRouter[T any] struct {
+	m      sync.Mutex
+	chans  map[uint64]chan T
+	closed bool
+}
+
+func newAppRouter[T any]() *appRouter[T] {
+	return &appRouter[T]{chans: map[uint64]chan T{}}
+}
+
+// add returns the channel of the events of the app.
+func (r *appRouter[T]) add(id uint64) <-chan T {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if ch, ok := r.chans[id]; ok {
+		return ch
+	}
+	ch := make(chan T, 500)
+	if r.closed {
+		close(ch)
+		return ch
+	}
+	r.chans[id] = ch
+	return ch
+}
+
+// run sends the events to the apps appOf returns until the source is closed, then closes the channels of the apps.
+// The events of the apps which weren't added are dropped.
+func (r *appRouter[T]) run(source <-chan T, appOf func(T) (uint64, bool)) {
+	for v := range source {
+		id, ok := appOf(v)
+		if !ok {
+			continue
+		}
+		r.m.Lock()
+		ch := r.chans[id]
+		r.m.Unlock()
+		if ch != nil {
+			ch <- v
+		}
+	}
+	r.m.Lock()
+	defer r.m.Unlock()
+	r.closed = true
+	for _, ch := range r.chans {
+		close(ch)
+	}
+}
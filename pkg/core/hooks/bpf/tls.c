@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: GPL-2.0
+//
+// The uprobes on SSL_read and SSL_write of OpenSSL and BoringSSL, they send the plaintext of the TLS connections of
+// the app in the events of the tls_events ring buffer. Generated into tls_*_bpfel.go by the go:generate of tls.go.
+//
+// The programs only read the registers of the calls, so the few types and helpers they use are declared here
+// instead of including vmlinux.h and libbpf.
+
+typedef unsigned char __u8;
+typedef unsigned int __u32;
+typedef long long __s64;
+typedef unsigned long long __u64;
+
+#define SEC(name) __attribute__((section(name), used))
+#define __uint(name, val) int (*name)[val]
+#define __type(name, val) typeof(val) *name
+
+enum {
+	BPF_MAP_TYPE_HASH = 1,
+	BPF_MAP_TYPE_PERCPU_ARRAY = 6,
+	BPF_MAP_TYPE_RINGBUF = 27,
+};
+
+static void *(*bpf_map_lookup_elem)(void *map, const void *key) = (void *)1;
+static long (*bpf_map_update_elem)(void *map, const void *key, const void *value, __u64 flags) = (void *)2;
+static long (*bpf_map_delete_elem)(void *map, const void *key) = (void *)3;
+static __u64 (*bpf_get_current_pid_tgid)(void) = (void *)14;
+static long (*bpf_probe_read_user)(void *dst, __u32 size, const void *unsafe_ptr) = (void *)112;
+static long (*bpf_ringbuf_output)(void *ringbuf, void *data, __u64 size, __u64 flags) = (void *)130;
+
+#if defined(__TARGET_ARCH_x86)
+struct pt_regs {
+	__u64 r15, r14, r13, r12, bp, bx, r11, r10, r9, r8;
+	__u64 ax, cx, dx, si, di;
+	__u64 orig_ax, ip, cs, flags, sp, ss;
+};
+#define PARM1(x) ((x)->di)
+#define PARM2(x) ((x)->si)
+#define PARM4(x) ((x)->cx)
+#define RC(x) ((x)->ax)
+#elif defined(__TARGET_ARCH_arm64)
+struct pt_regs {
+	__u64 regs[31];
+	__u64 sp, pc, pstate;
+};
+#define PARM1(x) ((x)->regs[0])
+#define PARM2(x) ((x)->regs[1])
+#define PARM4(x) ((x)->regs[3])
+#define RC(x) ((x)->regs[0])
+#else
+#error "the TLS uprobes are only built for amd64 and arm64"
+#endif
+
+#define TLS_CHUNK_SIZE (16 << 10)
+// TLS_MAX_CHUNKS bounds the plaintext sent of a call to 1MiB, the verifier needs the programs bounded
+#define TLS_MAX_CHUNKS 64
+
+enum tls_kind {
+	TLS_WRITE = 0,
+	TLS_READ = 1,
+	TLS_FREE = 2,
+};
+
+struct tls_event {
+	__u64 ssl; // the SSL* of the connection
+	__u64 pid_tgid;
+	__u32 len;  // the length of data, 0 when the connection is freed
+	__u32 kind; // enum tls_kind
+	__u8 data[TLS_CHUNK_SIZE];
+};
+
+// tls_heap holds the event being sent, too big for the stack, and the length left to send after it.
+struct tls_heap {
+	struct tls_event event;
+	__u64 remaining;
+};
+
+// tls_args are the arguments of a call in flight, the length pointer is only set by the _ex variants.
+struct tls_args {
+	__u64 ssl;
+	__u64 buf;
+	__u64 len_ptr;
+};
+
+struct {
+	__uint(type, BPF_MAP_TYPE_HASH);
+	__uint(max_entries, 10240);
+	__type(key, __u64);
+	__type(value, struct tls_args);
+} tls_args SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_PERCPU_ARRAY);
+	__uint(max_entries, 1);
+	__type(key, __u32);
+	__type(value, struct tls_heap);
+} tls_heap SEC(".maps");
+
+struct {
+	__uint(type, BPF_MAP_TYPE_RINGBUF);
+	__uint(max_entries, 8 << 20);
+} tls_events SEC(".maps");
+
+// tls_entry saves the SSL*, the buffer and the pointer to the length of the _ex variants of the call by its thread.
+SEC("uprobe/tls_entry")
+int tls_entry(struct pt_regs *ctx) {
+	__u64 id = bpf_get_current_pid_tgid();
+	struct tls_args args = {
+		.ssl = PARM1(ctx),
+		.buf = PARM2(ctx),
+		.len_ptr = PARM4(ctx),
+	};
+	bpf_map_update_elem(&tls_args, &id, &args, 0);
+	return 0;
+}
+
+// tls_free sends the event of SSL_free, so that the connection is closed and its SSL* can be reused.
+SEC("uprobe/tls_free")
+int tls_free(struct pt_regs *ctx) {
+	__u32 zero = 0;
+	struct tls_heap *heap = bpf_map_lookup_elem(&tls_heap, &zero);
+	if (!heap) {
+		return 0;
+	}
+	heap->event.ssl = PARM1(ctx);
+	heap->event.pid_tgid = bpf_get_current_pid_tgid();
+	heap->event.len = 0;
+	heap->event.kind = TLS_FREE;
+	bpf_ringbuf_output(&tls_events, &heap->event, __builtin_offsetof(struct tls_event, data), 0);
+	return 0;
+}
+
+// tls_return sends the plaintext written or read by the call in chunks of TLS_CHUNK_SIZE. The length left is kept in
+// the map value between the chunks, so that the verifier forgets its bounds and prunes the paths of the chunks.
+static __attribute__((always_inline)) int tls_return(struct pt_regs *ctx, enum tls_kind kind, int ex) {
+	__u64 id = bpf_get_current_pid_tgid();
+	struct tls_args *saved = bpf_map_lookup_elem(&tls_args, &id);
+	if (!saved) {
+		return 0;
+	}
+	struct tls_args args = *saved;
+	bpf_map_delete_elem(&tls_args, &id);
+
+	// the return value is an int
+	__s64 len = (int)RC(ctx);
+	if (ex) {
+		// 1 on success, with the length in the last argument
+		if (len != 1) {
+			return 0;
+		}
+		__u64 written = 0;
+		if (bpf_probe_read_user(&written, sizeof(written), (void *)args.len_ptr)) {
+			return 0;
+		}
+		len = written;
+	}
+	if (len <= 0) {
+		return 0;
+	}
+
+	__u32 zero = 0;
+	struct tls_heap *heap = bpf_map_lookup_elem(&tls_heap, &zero);
+	if (!heap) {
+		return 0;
+	}
+	heap->event.ssl = args.ssl;
+	heap->event.pid_tgid = id;
+	heap->event.kind = kind;
+	heap->remaining = len;
+
+	const __u8 *buf = (const __u8 *)args.buf;
+	for (int i = 0; i < TLS_MAX_CHUNKS; i++) {
+		__u64 remaining = *(volatile __u64 *)&heap->remaining;
+		if (remaining == 0) {
+			return 0;
+		}
+		__u64 size = remaining;
+		if (size > TLS_CHUNK_SIZE) {
+			size = TLS_CHUNK_SIZE;
+		}
+		heap->event.len = size;
+		if (bpf_probe_read_user(heap->event.data, size, buf)) {
+			return 0;
+		}
+		bpf_ringbuf_output(&tls_events, &heap->event, __builtin_offsetof(struct tls_event, data) + size, 0);
+		heap->remaining = remaining - size;
+		buf += size;
+	}
+	return 0;
+}
+
+SEC("uretprobe/tls_write_ret")
+int tls_write_ret(struct pt_regs *ctx) {
+	return tls_return(ctx, TLS_WRITE, 0);
+}
+
+SEC("uretprobe/tls_read_ret")
+int tls_read_ret(struct pt_regs *ctx) {
+	return tls_return(ctx, TLS_READ, 0);
+}
+
+SEC("uretprobe/tls_write_ret_ex")
+int tls_write_ret_ex(struct pt_regs *ctx) {
+	return tls_return(ctx, TLS_WRITE, 1);
+}
+
+SEC("uretprobe/tls_read_ret_ex")
+int tls_read_ret_ex(struct pt_regs *ctx) {
+	return tls_return(ctx, TLS_READ, 1);
+}
+
+char _license[] SEC("license") = "GPL";
@@ -4,6 +4,9 @@ package conn
 // constant for the maximum size of the event body
 const (
 	EventBodyMaxSize = 16384 // 16 KB
+	// MessageMaxSize is the most bytes of a request or response held by a tracker, the testcases of the bigger ones
+	// aren't recorded.
+	MessageMaxSize = 16 << 20 // 16 MB
 )
 
 // ID is a conversion of the following C-Struct into GO.
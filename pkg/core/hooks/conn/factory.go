@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
@@ -23,6 +25,16 @@ type Factory struct {
 	inactivityThreshold time.Duration
 	mutex               *sync.RWMutex
 	logger              *zap.Logger
+	// appOf returns the app the process is of, the events of the processes outside the apps are dropped
+	appOf func(pid uint32) (uint64, bool)
+	// idleThreshold is how long a conn is idle before its last response is taken as complete
+	idleThreshold time.Duration
+}
+
+// AppTestCase is a testcase captured from the app of AppID.
+type AppTestCase struct {
+	AppID    uint64
+	TestCase *models.TestCase
 }
 
 // NewFactory creates a new instance of the factory.
@@ -32,12 +44,14 @@ func NewFactory(inactivityThreshold time.Duration, logger *zap.Logger) *Factory
 		mutex:               &sync.RWMutex{},
 		inactivityThreshold: inactivityThreshold,
 		logger:              logger,
+		appOf:               func(uint32) (uint64, bool) { return 0, true },
+		idleThreshold:       2 * time.Second,
 	}
 }
 
 // ProcessActiveTrackers iterates over all conn the trackers and checks if they are complete. If so, it captures the ingress call and
 // deletes the tracker. If the tracker is inactive for a long time, it deletes it.
-func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *models.TestCase) {
+func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *AppTestCase) {
 	factory.mutex.Lock()
 	defer factory.mutex.Unlock()
 	var trackersToDelete []ID
@@ -46,38 +60,86 @@ func (factory *Factory) ProcessActiveTrackers(ctx context.Context, t chan *model
 		case <-ctx.Done():
 			return
 		default:
-			ok, requestBuf, responseBuf, reqTimestampTest, resTimestampTest := tracker.IsComplete()
-			if ok {
+			ok, requestBuf, responseBuf, reqTimestampTest, resTimestampTest := tracker.IsComplete(factory.idleThreshold)
+			// the process may have exited, the app is then only known if the process was seen in it
+			appID, inApp := factory.appOf(connID.TGID)
 
-				if len(requestBuf) == 0 || len(responseBuf) == 0 {
-					factory.logger.Warn("failed processing a request due to invalid request or response", zap.Any("Request Size", len(requestBuf)), zap.Any("Response Size", len(responseBuf)))
-					continue
+			if tracker.grpc == nil && isHTTP2Preface(requestBuf) {
+				tracker.grpc = newGrpcDecoder(factory.logger)
+			}
+			if tracker.grpc != nil {
+				// HTTP/2 streams are multiplexed on the conn, so every chunk is decoded even if its size could not be verified.
+				if len(requestBuf) > 0 || len(responseBuf) > 0 {
+					if reqTimestampTest.IsZero() {
+						reqTimestampTest = time.Now()
+					}
+					if resTimestampTest.IsZero() {
+						resTimestampTest = time.Now()
+					}
+					for _, tc := range tracker.grpc.Feed(requestBuf, responseBuf, reqTimestampTest, resTimestampTest) {
+						tc.ClientIP, tc.ClientPort = tracker.clientAddr()
+						if inApp {
+							t <- &AppTestCase{AppID: appID, TestCase: tc}
+						}
+					}
+					// the decoder copies the chunks into its own buffers
+					putMsgBuf(requestBuf)
+					putMsgBuf(responseBuf)
+				} else if tracker.IsInactive(factory.inactivityThreshold) {
+					trackersToDelete = append(trackersToDelete, connID)
 				}
+				continue
+			}
 
-				parsedHTTPReq, err := pkg.ParseHTTPRequest(requestBuf)
-				if err != nil {
-					utils.LogError(factory.logger, err, "failed to parse the http request from byte array", zap.Any("requestBuf", requestBuf))
-					continue
+			if ok {
+				tc, ok := factory.parseTestCase(requestBuf, responseBuf, reqTimestampTest, resTimestampTest)
+				if ok {
+					tc.ClientIP, tc.ClientPort = tracker.clientAddr()
 				}
-				parsedHTTPRes, err := pkg.ParseHTTPResponse(responseBuf, parsedHTTPReq)
-				if err != nil {
-					utils.LogError(factory.logger, err, "failed to parse the http response from byte array", zap.Any("responseBuf", responseBuf))
-					continue
+				if ok && inApp {
+					t <- &AppTestCase{AppID: appID, TestCase: tc}
+				}
+			} else {
+				// the request and response popped without being recorded
+				putMsgBuf(requestBuf)
+				putMsgBuf(responseBuf)
+				if tracker.IsInactive(factory.inactivityThreshold) {
+					trackersToDelete = append(trackersToDelete, connID)
 				}
-				capture(ctx, factory.logger, t, parsedHTTPReq, parsedHTTPRes, reqTimestampTest, resTimestampTest)
-
-			} else if tracker.IsInactive(factory.inactivityThreshold) {
-				trackersToDelete = append(trackersToDelete, connID)
 			}
 		}
 	}
 
 	// Delete all the processed trackers.
 	for _, key := range trackersToDelete {
+		factory.connections[key].release()
 		delete(factory.connections, key)
 	}
 }
 
+// parseTestCase returns the testcase of the http request and response, and puts their buffers back in the pool.
+func (factory *Factory) parseTestCase(requestBuf, responseBuf []byte, reqTimestampTest, resTimestampTest time.Time) (*models.TestCase, bool) {
+	defer putMsgBuf(requestBuf)
+	defer putMsgBuf(responseBuf)
+
+	if len(requestBuf) == 0 || len(responseBuf) == 0 {
+		factory.logger.Warn("failed processing a request due to invalid request or response", zap.Any("Request Size", len(requestBuf)), zap.Any("Response Size", len(responseBuf)))
+		return nil, false
+	}
+
+	parsedHTTPReq, err := pkg.ParseHTTPRequest(requestBuf)
+	if err != nil {
+		utils.LogError(factory.logger, err, "failed to parse the http request from byte array", zap.Any("requestBuf", requestBuf))
+		return nil, false
+	}
+	parsedHTTPRes, err := pkg.ParseHTTPResponse(responseBuf, parsedHTTPReq)
+	if err != nil {
+		utils.LogError(factory.logger, err, "failed to parse the http response from byte array", zap.Any("responseBuf", responseBuf))
+		return nil, false
+	}
+	return newTestCase(factory.logger, parsedHTTPReq, parsedHTTPRes, reqTimestampTest, resTimestampTest)
+}
+
 // GetOrCreate returns a tracker that related to the given conn and transaction ids. If there is no such tracker
 // we create a new one.
 func (factory *Factory) GetOrCreate(connectionID ID) *Tracker {
@@ -91,11 +153,20 @@ func (factory *Factory) GetOrCreate(connectionID ID) *Tracker {
 	return tracker
 }
 
-func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req *http.Request, resp *http.Response, reqTimeTest time.Time, resTimeTest time.Time) {
+// Capture sends the testcase of the http request and its response, it is also used by the proxy mode which
+// captures them without the eBPF hooks.
+func Capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req *http.Request, resp *http.Response, reqTimeTest time.Time, resTimeTest time.Time) {
+	if tc, ok := newTestCase(logger, req, resp, reqTimeTest, resTimeTest); ok {
+		t <- tc
+	}
+}
+
+// newTestCase returns the testcase of the http request and its response, false if their bodies can't be read.
+func newTestCase(logger *zap.Logger, req *http.Request, resp *http.Response, reqTimeTest time.Time, resTimeTest time.Time) (*models.TestCase, bool) {
 	reqBody, err := io.ReadAll(req.Body)
 	if err != nil {
 		utils.LogError(logger, err, "failed to read the http request body")
-		return
+		return nil, false
 	}
 
 	defer func() {
@@ -108,13 +179,20 @@ func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
 		utils.LogError(logger, err, "failed to read the http response body")
-		return
+		return nil, false
 	}
-	t <- &models.TestCase{
-		Version: models.GetVersion(),
-		Name:    pkg.ToYamlHTTPHeader(req.Header)["Keploy-Test-Name"],
-		Kind:    models.HTTP,
-		Created: time.Now().Unix(),
+	reqHeader := pkg.ToYamlHTTPHeader(req.Header)
+	// the remote address is only known in the proxy mode, the hooks set the client from the conn
+	clientIP, clientPort := splitClientAddr(req.RemoteAddr)
+	return &models.TestCase{
+		Version:       models.GetVersion(),
+		Name:          pkg.ToYamlHTTPHeader(req.Header)["Keploy-Test-Name"],
+		Kind:          models.HTTP,
+		Created:       time.Now().Unix(),
+		CorrelationID: pkg.CorrelationID(reqHeader),
+		Tags:          pkg.Tags(reqHeader),
+		ClientIP:      clientIP,
+		ClientPort:    clientPort,
 		HTTPReq: models.HTTPReq{
 			Method:     models.Method(req.Method),
 			ProtoMajor: req.ProtoMajor,
@@ -123,9 +201,10 @@ func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req
 			// URL: fmt.Sprintf("%s://%s%s?%s", req.URL.Scheme, req.Host, req.URL.Path, req.URL.RawQuery),
 			URL: fmt.Sprintf("http://%s%s", req.Host, req.URL.RequestURI()),
 			//  URL: string(b),
-			Header:    pkg.ToYamlHTTPHeader(req.Header),
+			Header:    reqHeader,
 			Body:      string(reqBody),
 			URLParams: pkg.URLParams(req),
+			GraphQL:   pkg.ParseGraphQLRequest(req.Method, reqHeader, string(reqBody)),
 			Timestamp: reqTimeTest,
 		},
 		HTTPResp: models.HTTPResp{
@@ -137,5 +216,18 @@ func capture(_ context.Context, logger *zap.Logger, t chan *models.TestCase, req
 		},
 		Noise: map[string][]string{},
 		// Mocks: mocks,
+	}, true
+}
+
+// splitClientAddr returns the ip and the port of the host:port address of a client, empty if it can't be parsed.
+func splitClientAddr(addr string) (string, uint16) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", 0
+	}
+	p, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", 0
 	}
+	return host, uint16(p)
 }
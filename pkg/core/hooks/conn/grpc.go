@@ -0,0 +1,250 @@
+package conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+)
+
+// maxFrameSize is the largest frame size allowed by the HTTP/2 spec (2^24-1).
+const maxFrameSize = 1<<24 - 1
+
+// grpcDecoder reassembles the gRPC calls from the HTTP/2 frames of an ingress conn.
+// The frames of both the directions are read in order, since the streams of a conn are multiplexed.
+type grpcDecoder struct {
+	logger  *zap.Logger
+	client  *h2Direction
+	server  *h2Direction
+	streams map[uint32]*grpcStream
+	// prefaceRead is set once the client preface is skipped from the request data.
+	prefaceRead bool
+}
+
+// h2Direction holds the framing state of one direction of an HTTP/2 conn.
+type h2Direction struct {
+	buf    *bytes.Buffer
+	framer *http2.Framer
+	hpack  *hpack.Decoder
+
+	// headerBlock accumulates the header block fragments of a HEADERS frame and its CONTINUATION frames.
+	headerBlock     []byte
+	headerStreamID  uint32
+	headerEndStream bool
+}
+
+type grpcStream struct {
+	reqHeaders  models.GrpcHeaders
+	respHeaders models.GrpcHeaders
+	trailers    models.GrpcHeaders
+	reqBody     []byte
+	respBody    []byte
+	reqTime     time.Time
+	respTime    time.Time
+	// respStarted is set once the response headers are received, the next header block is the trailers.
+	respStarted bool
+}
+
+func newH2Direction() *h2Direction {
+	buf := &bytes.Buffer{}
+	framer := http2.NewFramer(nil, buf)
+	framer.SetMaxReadFrameSize(maxFrameSize)
+	return &h2Direction{
+		buf:    buf,
+		framer: framer,
+		hpack:  hpack.NewDecoder(4096, nil),
+	}
+}
+
+func newGrpcDecoder(logger *zap.Logger) *grpcDecoder {
+	return &grpcDecoder{
+		logger:  logger,
+		client:  newH2Direction(),
+		server:  newH2Direction(),
+		streams: make(map[uint32]*grpcStream),
+	}
+}
+
+// isHTTP2Preface reports whether the request data of a conn starts with the HTTP/2 client preface.
+func isHTTP2Preface(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(http2.ClientPreface))
+}
+
+// Feed decodes the request and response data of the conn and returns the testcases of the completed gRPC calls.
+func (d *grpcDecoder) Feed(reqData, respData []byte, reqTime, respTime time.Time) []*models.TestCase {
+	d.client.buf.Write(reqData)
+	d.server.buf.Write(respData)
+	if !d.prefaceRead {
+		// the preface could be split across the chunks of the conn
+		if d.client.buf.Len() < len(http2.ClientPreface) {
+			return nil
+		}
+		if isHTTP2Preface(d.client.buf.Bytes()) {
+			d.client.buf.Next(len(http2.ClientPreface))
+		}
+		d.prefaceRead = true
+	}
+	d.readFrames(d.client, true, reqTime)
+	return d.readFrames(d.server, false, respTime)
+}
+
+// readFrames reads all the complete frames available in the buffer of the given direction.
+func (d *grpcDecoder) readFrames(dir *h2Direction, isRequest bool, timestamp time.Time) []*models.TestCase {
+	var tcs []*models.TestCase
+	for {
+		data := dir.buf.Bytes()
+		if len(data) < 9 {
+			return tcs
+		}
+		// the first 3 bytes of the frame header are the length of the payload
+		length := int(binary.BigEndian.Uint32(append([]byte{0}, data[:3]...)))
+		if len(data) < 9+length {
+			return tcs
+		}
+
+		frame, err := dir.framer.ReadFrame()
+		if err != nil {
+			utils.LogError(d.logger, err, "failed to read the http2 frame of the grpc call, dropping the buffered data")
+			dir.buf.Reset()
+			dir.headerBlock = nil
+			return tcs
+		}
+
+		if tc := d.handleFrame(dir, frame, isRequest, timestamp); tc != nil {
+			tcs = append(tcs, tc)
+		}
+	}
+}
+
+func (d *grpcDecoder) handleFrame(dir *h2Direction, frame http2.Frame, isRequest bool, timestamp time.Time) *models.TestCase {
+	streamID := frame.Header().StreamID
+
+	switch f := frame.(type) {
+	case *http2.HeadersFrame:
+		dir.headerBlock = append([]byte{}, f.HeaderBlockFragment()...)
+		dir.headerStreamID = streamID
+		dir.headerEndStream = f.StreamEnded()
+		if !f.HeadersEnded() {
+			return nil
+		}
+		return d.handleHeaderBlock(dir, isRequest, timestamp)
+	case *http2.ContinuationFrame:
+		dir.headerBlock = append(dir.headerBlock, f.HeaderBlockFragment()...)
+		if !f.HeadersEnded() {
+			return nil
+		}
+		return d.handleHeaderBlock(dir, isRequest, timestamp)
+	case *http2.DataFrame:
+		stream, ok := d.streams[streamID]
+		if !ok {
+			return nil
+		}
+		if isRequest {
+			stream.reqBody = append(stream.reqBody, f.Data()...)
+		} else {
+			stream.respBody = append(stream.respBody, f.Data()...)
+		}
+	case *http2.RSTStreamFrame:
+		d.logger.Debug("grpc stream was reset, dropping the call", zap.Any("stream id", streamID), zap.Any("error code", f.ErrCode))
+		delete(d.streams, streamID)
+	}
+	return nil
+}
+
+// handleHeaderBlock decodes the complete header block of the direction. The headers of the server which end
+// the stream are the trailers of the call, so the testcase of the call is returned.
+func (d *grpcDecoder) handleHeaderBlock(dir *h2Direction, isRequest bool, timestamp time.Time) *models.TestCase {
+	streamID := dir.headerStreamID
+	fields, err := dir.hpack.DecodeFull(dir.headerBlock)
+	dir.headerBlock = nil
+	if err != nil {
+		utils.LogError(d.logger, err, "failed to decode the http2 headers of the grpc call", zap.Any("stream id", streamID))
+		delete(d.streams, streamID)
+		return nil
+	}
+
+	headers := models.GrpcHeaders{
+		PseudoHeaders:   make(map[string]string),
+		OrdinaryHeaders: make(map[string]string),
+	}
+	for _, field := range fields {
+		if field.IsPseudo() {
+			headers.PseudoHeaders[field.Name] = field.Value
+			continue
+		}
+		headers.OrdinaryHeaders[field.Name] = field.Value
+	}
+
+	if isRequest {
+		// the probes of the schema by the tools and the service meshes are not calls of the app to test
+		if path := headers.PseudoHeaders[":path"]; pkg.IsGrpcReflection(path) {
+			d.logger.Debug("skipping the grpc reflection stream", zap.Any("stream id", streamID), zap.String("path", path))
+			return nil
+		}
+		d.streams[streamID] = &grpcStream{
+			reqHeaders: headers,
+			reqTime:    timestamp,
+		}
+		return nil
+	}
+
+	stream, ok := d.streams[streamID]
+	if !ok {
+		return nil
+	}
+	if !stream.respStarted && !dir.headerEndStream {
+		stream.respHeaders = headers
+		stream.respTime = timestamp
+		stream.respStarted = true
+		return nil
+	}
+	if !stream.respStarted {
+		// a trailers-only response carries the status of the call in its only header block
+		trailers := models.GrpcHeaders{
+			PseudoHeaders:   make(map[string]string),
+			OrdinaryHeaders: make(map[string]string),
+		}
+		for _, key := range []string{"grpc-status", "grpc-message"} {
+			if value, ok := headers.OrdinaryHeaders[key]; ok {
+				trailers.OrdinaryHeaders[key] = value
+				delete(headers.OrdinaryHeaders, key)
+			}
+		}
+		stream.respHeaders = headers
+		stream.respTime = timestamp
+		headers = trailers
+	}
+	stream.trailers = headers
+	delete(d.streams, streamID)
+
+	return stream.testCase()
+}
+
+func (s *grpcStream) testCase() *models.TestCase {
+	return &models.TestCase{
+		Version:       models.GetVersion(),
+		Name:          s.reqHeaders.OrdinaryHeaders["keploy-test-name"],
+		Kind:          models.GRPC_EXPORT,
+		Created:       time.Now().Unix(),
+		CorrelationID: pkg.CorrelationID(s.reqHeaders.OrdinaryHeaders),
+		Tags:          pkg.Tags(s.reqHeaders.OrdinaryHeaders),
+		GrpcReq: models.GrpcReq{
+			Headers:   s.reqHeaders,
+			Body:      pkg.CreateLengthPrefixedMessageFromPayload(s.reqBody),
+			Timestamp: s.reqTime,
+		},
+		GrpcResp: models.GrpcResp{
+			Headers:   s.respHeaders,
+			Body:      pkg.CreateLengthPrefixedMessageFromPayload(s.respBody),
+			Trailers:  s.trailers,
+			Timestamp: s.respTime,
+		},
+		Noise: map[string][]string{},
+	}
+}
@@ -0,0 +1,28 @@
+package conn
+
+import "sync"
+
+// msgBufMaxPooledSize is the capacity above which the buffers aren't put back in the pool, so that a few big
+// messages don't keep their memory for the rest of the session.
+const msgBufMaxPooledSize = 64 << 10 // 64 KB
+
+// msgBufPool holds the buffers of the requests and responses of the trackers, they are put back once their
+// testcase is parsed, the testcase holding copies of their data.
+var msgBufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, 0, EventBodyMaxSize)
+		return &buf
+	},
+}
+
+func getMsgBuf() []byte {
+	return (*msgBufPool.Get().(*[]byte))[:0]
+}
+
+func putMsgBuf(buf []byte) {
+	if cap(buf) == 0 || cap(buf) > msgBufMaxPooledSize {
+		return
+	}
+	buf = buf[:0]
+	msgBufPool.Put(&buf)
+}
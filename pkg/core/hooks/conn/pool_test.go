@@ -0,0 +1,34 @@
+package conn
+
+import "testing"
+
+var msgSink []byte
+
+// BenchmarkMsgBuf compares growing the buffer of each message of a tracker from empty, as before, with taking it
+// from msgBufPool. A message is appended in chunks of EventBodyMaxSize bytes, as the data events carry them.
+func BenchmarkMsgBuf(b *testing.B) {
+	var chunk [EventBodyMaxSize]byte
+	const chunks = 3
+
+	b.Run("alloc", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := []byte{}
+			for j := 0; j < chunks; j++ {
+				buf = append(buf, chunk[:]...)
+			}
+			msgSink = buf
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := getMsgBuf()
+			for j := 0; j < chunks; j++ {
+				buf = append(buf, chunk[:]...)
+			}
+			msgSink = buf
+			putMsgBuf(buf)
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package conn
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// eventWorkers is the number of workers adding the socket events to the trackers. The events of a conn are
+	// always handled by the same worker, in the order they are read.
+	eventWorkers = 4
+	// eventQueueSize is the number of events each worker can hold. A reader blocks once the queue of a worker is full,
+	// which leaves the events in the buffers of the kernel instead of the memory of keploy.
+	eventQueueSize = 256
+	// eventQueueStatsInterval is how often the depth of the queues is logged.
+	eventQueueStatsInterval = 10 * time.Second
+)
+
+// socketEvent is one of the open, data and close events of a conn.
+type socketEvent struct {
+	open  *SocketOpenEvent
+	data  *SocketDataEvent
+	close *SocketCloseEvent
+}
+
+// eventQueue is the bounded queues between the readers of the socket events and the trackers.
+type eventQueue struct {
+	logger  *zap.Logger
+	factory *Factory
+	queues  []chan socketEvent
+
+	// events is the number of events queued, full the number of times a reader waited for a full queue and
+	// maxDepth the most events held by a queue
+	events   atomic.Int64
+	full     atomic.Int64
+	maxDepth atomic.Int64
+}
+
+func newEventQueue(logger *zap.Logger, factory *Factory) *eventQueue {
+	q := &eventQueue{
+		logger:  logger,
+		factory: factory,
+		queues:  make([]chan socketEvent, eventWorkers),
+	}
+	for i := range q.queues {
+		q.queues[i] = make(chan socketEvent, eventQueueSize)
+	}
+	return q
+}
+
+// run starts the workers of the queues and logs their depth until the context is done.
+func (q *eventQueue) run(ctx context.Context) {
+	for _, queue := range q.queues {
+		go func(queue chan socketEvent) {
+			defer utils.Recover(q.logger)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case e := <-queue:
+					q.handle(e)
+				}
+			}
+		}(queue)
+	}
+
+	go func() {
+		defer utils.Recover(q.logger)
+		ticker := time.NewTicker(eventQueueStatsInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				depth := make([]int, len(q.queues))
+				for i, queue := range q.queues {
+					depth[i] = len(queue)
+				}
+				q.logger.Debug("socket event queues", zap.Ints("depth", depth), zap.Int64("maxDepth", q.maxDepth.Load()), zap.Int64("events", q.events.Load()), zap.Int64("timesFull", q.full.Load()))
+			}
+		}
+	}()
+}
+
+// push queues the event of the conn, waiting if the queue of its worker is full. It returns false if the context
+// is done first.
+func (q *eventQueue) push(ctx context.Context, connID ID, e socketEvent) bool {
+	queue := q.queues[(uint64(connID.TGID)<<32|uint64(connID.FD))%uint64(len(q.queues))]
+	q.events.Add(1)
+	select {
+	case queue <- e:
+	default:
+		if q.full.Add(1) == 1 {
+			q.logger.Debug("the socket event queue is full, the events are read slower than the app sends them")
+		}
+		select {
+		case queue <- e:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	if depth := int64(len(queue)); depth > q.maxDepth.Load() {
+		q.maxDepth.Store(depth)
+	}
+	return true
+}
+
+func (q *eventQueue) handle(e socketEvent) {
+	switch {
+	case e.open != nil:
+		q.factory.GetOrCreate(e.open.ConnID).AddOpenEvent(*e.open)
+	case e.data != nil:
+		q.factory.GetOrCreate(e.data.ConnID).AddDataEvent(*e.data)
+	case e.close != nil:
+		q.factory.GetOrCreate(e.close.ConnID).AddCloseEvent(*e.close)
+	}
+}
@@ -0,0 +1,25 @@
+//go:build !windows
+
+package conn
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// InitRealTimeOffset calculates the offset between the real clock and the monotonic clock used in the BPF.
+func initRealTimeOffset() error {
+	var monotonicTime, realTime unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &monotonicTime); err != nil {
+		return fmt.Errorf("failed getting monotonic clock due to: %v", err)
+	}
+	if err := unix.ClockGettime(unix.CLOCK_REALTIME, &realTime); err != nil {
+		return fmt.Errorf("failed getting real clock time due to: %v", err)
+	}
+	realTimeOffset = uint64(time.Second)*(uint64(realTime.Sec)-uint64(monotonicTime.Sec)) + uint64(realTime.Nsec) - uint64(monotonicTime.Nsec)
+	// realTimeCopy := time.Unix(int64(realTimeOffset/1e9), int64(realTimeOffset%1e9))
+	// log.Debug(fmt.Sprintf("%s real time offset is: %v", Emoji, realTimeCopy))
+	return nil
+}
@@ -0,0 +1,8 @@
+package conn
+
+import "errors"
+
+// initRealTimeOffset isn't needed on Windows, the eBPF hooks are only loaded on linux.
+func initRealTimeOffset() error {
+	return errors.New("the eBPF hooks are only supported on linux")
+}
@@ -23,19 +23,27 @@ import (
 
 var eventAttributesSize = int(unsafe.Sizeof(SocketDataEvent{}))
 
-// ListenSocket starts the socket event listeners
-func ListenSocket(ctx context.Context, l *zap.Logger, openMap, dataMap, closeMap *ebpf.Map) (<-chan *models.TestCase, error) {
-	t := make(chan *models.TestCase, 500)
+// ListenSocket starts the socket event listeners, the testcases are sent with the app appOf returns for their
+// process, and the events of the processes outside the apps are dropped. The last response of a conn is taken as
+// complete once the conn is idle for idleThreshold.
+func ListenSocket(ctx context.Context, l *zap.Logger, appOf func(pid uint32) (uint64, bool), idleThreshold time.Duration, openMap, dataMap, closeMap *ebpf.Map) (<-chan *AppTestCase, error) {
+	t := make(chan *AppTestCase, 500)
 	err := initRealTimeOffset()
 	if err != nil {
 		utils.LogError(l, err, "failed to initialize real time offset")
 		return nil, errors.New("failed to start socket listeners")
 	}
 	c := NewFactory(time.Minute, l)
+	c.appOf = appOf
+	if idleThreshold > 0 {
+		c.idleThreshold = idleThreshold
+	}
 	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
 	if !ok {
 		return nil, errors.New("failed to get the error group from the context")
 	}
+	q := newEventQueue(l, c)
+	q.run(ctx)
 	g.Go(func() error {
 		defer utils.Recover(l)
 		go func() {
@@ -56,17 +64,17 @@ func ListenSocket(ctx context.Context, l *zap.Logger, openMap, dataMap, closeMap
 		return nil
 	})
 
-	err = open(ctx, c, l, openMap)
+	err = open(ctx, c, q, l, openMap)
 	if err != nil {
 		utils.LogError(l, err, "failed to start open socket listener")
 		return nil, errors.New("failed to start socket listeners")
 	}
-	err = data(ctx, c, l, dataMap)
+	err = data(ctx, c, q, l, dataMap)
 	if err != nil {
 		utils.LogError(l, err, "failed to start data socket listener")
 		return nil, errors.New("failed to start socket listeners")
 	}
-	err = exit(ctx, c, l, closeMap)
+	err = exit(ctx, c, q, l, closeMap)
 	if err != nil {
 		utils.LogError(l, err, "failed to start close socket listener")
 		return nil, errors.New("failed to start socket listeners")
@@ -74,7 +82,7 @@ func ListenSocket(ctx context.Context, l *zap.Logger, openMap, dataMap, closeMap
 	return t, err
 }
 
-func open(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
+func open(ctx context.Context, c *Factory, q *eventQueue, l *zap.Logger, m *ebpf.Map) error {
 
 	r, err := perf.NewReader(m, os.Getpagesize())
 	if err != nil {
@@ -112,8 +120,13 @@ func open(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 					continue
 				}
 
+				if _, ok := c.appOf(event.ConnID.TGID); !ok {
+					continue
+				}
 				event.TimestampNano += getRealTimeOffset()
-				c.GetOrCreate(event.ConnID).AddOpenEvent(event)
+				if !q.push(ctx, event.ConnID, socketEvent{open: &event}) {
+					return
+				}
 			}
 		}()
 		<-ctx.Done() // Check for context cancellation
@@ -126,7 +139,7 @@ func open(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 	return nil
 }
 
-func data(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
+func data(ctx context.Context, c *Factory, q *eventQueue, l *zap.Logger, m *ebpf.Map) error {
 	r, err := ringbuf.NewReader(m)
 	if err != nil {
 		utils.LogError(l, nil, "failed to create ring buffer of socketDataEvent")
@@ -167,6 +180,9 @@ func data(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 					continue
 				}
 
+				if _, ok := c.appOf(event.ConnID.TGID); !ok {
+					continue
+				}
 				event.TimestampNano += getRealTimeOffset()
 
 				if event.Direction == IngressTraffic {
@@ -174,7 +190,9 @@ func data(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 					l.Debug(fmt.Sprintf("Request EntryTimestamp :%v\n", convertUnixNanoToTime(event.EntryTimestampNano)))
 				}
 
-				c.GetOrCreate(event.ConnID).AddDataEvent(event)
+				if !q.push(ctx, event.ConnID, socketEvent{data: &event}) {
+					return
+				}
 			}
 		}()
 		<-ctx.Done() // Check for context cancellation
@@ -187,7 +205,7 @@ func data(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 	return nil
 }
 
-func exit(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
+func exit(ctx context.Context, c *Factory, q *eventQueue, l *zap.Logger, m *ebpf.Map) error {
 
 	r, err := perf.NewReader(m, os.Getpagesize())
 	if err != nil {
@@ -224,8 +242,13 @@ func exit(ctx context.Context, c *Factory, l *zap.Logger, m *ebpf.Map) error {
 					continue
 				}
 
+				if _, ok := c.appOf(event.ConnID.TGID); !ok {
+					continue
+				}
 				event.TimestampNano += getRealTimeOffset()
-				c.GetOrCreate(event.ConnID).AddCloseEvent(event)
+				if !q.push(ctx, event.ConnID, socketEvent{close: &event}) {
+					return
+				}
 			}
 		}()
 
@@ -2,9 +2,11 @@ package conn
 
 import (
 	"fmt"
+	"net"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"syscall"
 	"time"
 
 	"go.keploy.io/server/v2/pkg/models"
@@ -64,6 +66,9 @@ type Tracker struct {
 
 	reqTimestamps []time.Time
 	isNewRequest  bool
+
+	// grpc decodes the HTTP/2 frames of the conn, it is set only when the conn starts with the HTTP/2 client preface.
+	grpc *grpcDecoder
 }
 
 func NewTracker(connID ID, logger *zap.Logger) *Tracker {
@@ -105,7 +110,8 @@ func (conn *Tracker) decRecordTestCount() {
 }
 
 // IsComplete checks if the current conn has valid request & response info to capture and also returns the request and response data buffer.
-func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
+// The last response of the conn is taken as complete once the conn is idle for idleThreshold.
+func (conn *Tracker) IsComplete(idleThreshold time.Duration) (bool, []byte, []byte, time.Time, time.Time) {
 	conn.mutex.Lock()
 	defer conn.mutex.Unlock()
 
@@ -115,7 +121,7 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 	// Calculate the time elapsed since the last activity in nanoseconds.
 	elapsedTime := currentTimestamp - conn.lastActivityTimestamp
 
-	//Caveat: Added a timeout of 4 seconds, after this duration we assume that the last response data event would have come.
+	//Caveat: Added a timeout of idleThreshold, after this duration we assume that the last response data event would have come.
 	// This will ensure that we capture the requests responses where Connection:keep-alive is enabled.
 
 	recordTraffic := false
@@ -183,7 +189,7 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 		// // decrease the recTestCounter
 		conn.decRecordTestCount()
 		conn.logger.Debug("verified recording", zap.Any("recordTraffic", recordTraffic))
-	} else if conn.lastChunkWasResp && elapsedTime >= uint64(time.Second*2) { // Check if 2 seconds has passed since the last activity.
+	} else if conn.lastChunkWasResp && elapsedTime >= uint64(idleThreshold) { // Check if idleThreshold has passed since the last activity.
 		conn.logger.Debug("might be last request on the conn")
 
 		if len(conn.userReqSizes) > 0 && len(conn.kernelReqSizes) > 0 {
@@ -212,6 +218,8 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 				conn.userReqs = conn.userReqs[1:]
 
 				responseBuf = conn.resp
+				// the buffer is handed to the caller, so it isn't put back by reset
+				conn.resp = nil
 				respTimestamp = time.Now()
 			} else {
 				conn.logger.Debug("no data buffer for request", zap.Any("Length of RecvBufQueue", len(conn.userReqs)))
@@ -224,13 +232,18 @@ func (conn *Tracker) IsComplete() (bool, []byte, []byte, time.Time, time.Time) {
 		}
 
 		conn.logger.Debug(fmt.Sprintf("recording traffic after verifying the request data (but not response data):%v", recordTraffic))
-		//treat immediate next request as first request (2 seconds after last activity)
+		//treat immediate next request as first request (idleThreshold after last activity)
 		// this can be to avoid potential corruption in the conn
 		conn.reset()
 
 		conn.logger.Debug("unverified recording", zap.Any("recordTraffic", recordTraffic))
 	}
 
+	if recordTraffic && (len(requestBuf) >= MessageMaxSize || len(responseBuf) >= MessageMaxSize) {
+		conn.logger.Warn("skipping the testcase of a request or response bigger than the maximum held by keploy", zap.Int("maxBytes", MessageMaxSize))
+		recordTraffic = false
+	}
+
 	// Checking if record traffic is recorded and request & response timestamp is captured or not.
 	if recordTraffic {
 		if len(conn.reqTimestamps) > 0 {
@@ -262,10 +275,27 @@ func (conn *Tracker) reset() {
 	conn.lastChunkWasReq = false
 	conn.reqSize = 0
 	conn.respSize = 0
+	putMsgBuf(conn.resp)
+	putMsgBuf(conn.req)
 	conn.resp = []byte{}
 	conn.req = []byte{}
 }
 
+// release puts the buffers of the requests and responses not parsed yet back in the pool, once the conn is
+// dropped.
+func (conn *Tracker) release() {
+	conn.mutex.Lock()
+	defer conn.mutex.Unlock()
+	for _, buf := range conn.userReqs {
+		putMsgBuf(buf)
+	}
+	for _, buf := range conn.userResps {
+		putMsgBuf(buf)
+	}
+	conn.userReqs, conn.userResps = nil, nil
+	conn.reset()
+}
+
 func (conn *Tracker) verifyRequestData(expectedRecvBytes, actualRecvBytes uint64) bool {
 	return expectedRecvBytes == actualRecvBytes
 }
@@ -308,8 +338,13 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 		if event.MsgSize > EventBodyMaxSize {
 			msgLength = EventBodyMaxSize
 		}
-		// Append the message (up to msgLength) to the conn's sent buffer
-		conn.resp = append(conn.resp, event.Msg[:msgLength]...)
+		// Append the message (up to msgLength) to the conn's sent buffer, until it holds MessageMaxSize bytes
+		if cap(conn.resp) == 0 {
+			conn.resp = getMsgBuf()
+		}
+		if len(conn.resp) < MessageMaxSize {
+			conn.resp = append(conn.resp, event.Msg[:min(msgLength, uint32(MessageMaxSize-len(conn.resp)))]...)
+		}
 		conn.respSize += uint64(event.MsgSize)
 
 		//Handling multiple request on same conn to support conn:keep-alive
@@ -341,8 +376,13 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 		if event.MsgSize > EventBodyMaxSize {
 			msgLength = EventBodyMaxSize
 		}
-		// Append the message (up to msgLength) to the conn's receive buffer
-		conn.req = append(conn.req, event.Msg[:msgLength]...)
+		// Append the message (up to msgLength) to the conn's receive buffer, until it holds MessageMaxSize bytes
+		if cap(conn.req) == 0 {
+			conn.req = getMsgBuf()
+		}
+		if len(conn.req) < MessageMaxSize {
+			conn.req = append(conn.req, event.Msg[:min(msgLength, uint32(MessageMaxSize-len(conn.req)))]...)
+		}
 		conn.reqSize += uint64(event.MsgSize)
 
 		//Handling multiple request on same conn to support conn:keep-alive
@@ -368,6 +408,20 @@ func (conn *Tracker) AddDataEvent(event SocketDataEvent) {
 	}
 }
 
+// clientAddr returns the ip and the port of the peer of the conn, from the address of its accept call, empty if
+// it isn't an ipv4 address.
+func (conn *Tracker) clientAddr() (string, uint16) {
+	conn.mutex.RLock()
+	defer conn.mutex.RUnlock()
+	// the address is in the network byte order, read as little endian
+	if conn.addr.SinFamily != syscall.AF_INET || conn.addr.SinAddr == 0 {
+		return "", 0
+	}
+	a := conn.addr.SinAddr
+	ip := net.IPv4(byte(a), byte(a>>8), byte(a>>16), byte(a>>24))
+	return ip.String(), conn.addr.SinPort>>8 | conn.addr.SinPort<<8
+}
+
 func (conn *Tracker) AddOpenEvent(event SocketOpenEvent) {
 	conn.mutex.Lock()
 	defer conn.mutex.Unlock()
@@ -5,8 +5,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 
@@ -14,6 +17,7 @@ import (
 	"go.keploy.io/server/v2/utils"
 
 	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/btf"
 	"github.com/cilium/ebpf/link"
 	"github.com/cilium/ebpf/rlimit"
 
@@ -29,8 +33,17 @@ func NewHooks(logger *zap.Logger, cfg config.Config) *Hooks {
 		sess:      core.NewSessions(),
 		m:         sync.Mutex{},
 		proxyIP:   "127.0.0.1",
+		proxyIP6:  "::1",
 		proxyPort: cfg.ProxyPort,
 		dnsPort:   cfg.DNSPort,
+		btfPath:   cfg.BTFPath,
+
+		idleThreshold: cfg.ProxyIdleThreshold,
+
+		tlsUprobes:      cfg.Record.TLSUprobes,
+		tlsLibraryPaths: cfg.Record.TLSLibraries,
+		appCommand:      cfg.Command,
+		javaAgent:       cfg.Record.JavaAgent,
 	}
 }
 
@@ -38,10 +51,38 @@ type Hooks struct {
 	logger    *zap.Logger
 	sess      *core.Sessions
 	proxyIP   string
+	proxyIP6  string
 	proxyPort uint32
 	dnsPort   uint32
+	btfPath   string
+	isDocker  bool
+	// idleThreshold is how long a recorded conn is idle before its last response is taken as complete
+	idleThreshold time.Duration
+
+	// tlsUprobes captures the plaintext of the TLS connections of the app with uprobes on tlsLibraryPaths and libssl
+	tlsUprobes      bool
+	tlsLibraryPaths []string
+	// appCommand is searched for the TLS libraries of its interpreter
+	appCommand string
+	// appTrees are the process trees of the native apps by their id, once their commands are started
+	appTrees sync.Map
+	// lastApp is the app hooked last, which the processes are of until the commands of the apps are tracked
+	lastApp atomic.Uint64
+	// loaded is set once the eBPF hooks are loaded, they are shared by the apps hooked after the first
+	loaded bool
+	// recordMu guards testCases and tlsData, which route the testcases and the TLS plaintext to the apps
+	recordMu  sync.Mutex
+	testCases *appRouter[*conn.AppTestCase]
+	tlsData   *appRouter[*core.TLSData]
+	// javaAgent is the jar of the JSSE agent passed to the JVM of the app, which sends its plaintext to jsseListener
+	// after jsseToken
+	javaAgent    string
+	jsseListener net.Listener
+	jsseToken    string
 
 	m sync.Mutex
+	// retprobeFallback is set once the kretprobes fell back to the kprobe PMU, which can't raise their maxactive
+	retprobeFallback bool
 	// eBPF C shared maps
 	proxyInfoMap     *ebpf.Map
 	inodeMap         *ebpf.Map
@@ -98,36 +139,49 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 	h.sess.Set(id, &core.Session{
 		ID: id,
 	})
+	h.lastApp.Store(id)
 
-	err := h.load(ctx, opts)
-	if err != nil {
-		return err
-	}
+	if !h.loaded {
+		err := h.load(ctx, opts)
+		if err != nil {
+			return err
+		}
 
-	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
-	if !ok {
-		return errors.New("failed to get the error group from the context")
-	}
+		g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+		if !ok {
+			return errors.New("failed to get the error group from the context")
+		}
 
-	g.Go(func() error {
-		defer utils.Recover(h.logger)
-		<-ctx.Done()
-		h.unLoad(ctx)
-		return nil
-	})
+		g.Go(func() error {
+			defer utils.Recover(h.logger)
+			<-ctx.Done()
+			h.unLoad(ctx)
+			return nil
+		})
+		h.loaded = true
+	}
 
 	if opts.IsDocker {
 		h.proxyIP = opts.KeployIPV4
+		// the IPv6 connections of an app on a network without IPv6 can't reach the proxy anyway
+		if opts.KeployIPV6 != "" {
+			h.proxyIP6 = opts.KeployIPV6
+		}
 	}
+	h.isDocker = opts.IsDocker
 
 	proxyIP, err := IPv4ToUint32(h.proxyIP)
 	if err != nil {
 		return fmt.Errorf("failed to convert ip string:[%v] to 32-bit integer", opts.KeployIPV4)
 	}
+	proxyIP6, err := IPv6ToUint32(h.proxyIP6)
+	if err != nil {
+		return fmt.Errorf("failed to convert ip string:[%v] to 32-bit integers", h.proxyIP6)
+	}
 
-	err = h.SendProxyInfo(proxyIP, h.proxyPort, [4]uint32{0000, 0000, 0000, 0001})
+	err = h.SendProxyInfo(proxyIP, h.proxyPort, proxyIP6)
 	if err != nil {
-		utils.LogError(h.logger, err, "failed to send proxy info to kernel", zap.Any("NewProxyIp", proxyIP))
+		utils.LogError(h.logger, err, "failed to send proxy info to kernel", zap.Any("NewProxyIp", proxyIP), zap.Any("NewProxyIp6", proxyIP6))
 		return err
 	}
 
@@ -143,10 +197,23 @@ func (h *Hooks) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
 		return err
 	}
 
+	if h.javaAgent != "" && h.jsseListener == nil && opts.Mode == models.MODE_RECORD {
+		err = h.listenJSSE(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
 func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
+	checks := Preflight(h.btfPath)
+	logChecks(h.logger, checks)
+	if err := PreflightError(checks); err != nil {
+		return fmt.Errorf("the eBPF hooks can't be loaded on this machine, run `keploy doctor` for the fixes: %w", err)
+	}
+
 	// Allow the current process to lock memory for eBPF resources.
 	if err := rlimit.RemoveMemlock(); err != nil {
 		utils.LogError(h.logger, err, "failed to lock memory for eBPF resources")
@@ -155,7 +222,20 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Load pre-compiled programs and maps into the kernel.
 	objs := bpfObjects{}
-	if err := loadBpfObjects(&objs, nil); err != nil {
+	collOpts, err := h.collectionOptions()
+	if err != nil {
+		return err
+	}
+	if err := loadBpfObjects(&objs, collOpts); err != nil {
+		var ve *ebpf.VerifierError
+		if errors.As(err, &ve) {
+			utils.LogError(h.logger, err, "the verifier of the kernel rejected the eBPF hooks, please report it with the kernel version", zap.String("verifier log", fmt.Sprintf("%+v", ve)))
+			return err
+		}
+		if errors.Is(err, ebpf.ErrNotSupported) {
+			utils.LogError(h.logger, err, "the kernel doesn't support a feature of the eBPF hooks")
+			return err
+		}
 		utils.LogError(h.logger, err, "failed to load eBPF objects")
 		return err
 	}
@@ -216,7 +296,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 	}
 	h.tcpv4 = tcpC4
 
-	tcpRC4, err := link.Kretprobe("tcp_v4_connect", objs.SyscallProbeRetTcpV4Connect, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	tcpRC4, err := h.kretprobe("tcp_v4_connect", objs.SyscallProbeRetTcpV4Connect)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on tcp_v4_connect")
 		return err
@@ -270,7 +350,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 	}
 	h.tcpv6 = tcpC6
 
-	tcpRC6, err := link.Kretprobe("tcp_v6_connect", objs.SyscallProbeRetTcpV6Connect, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	tcpRC6, err := h.kretprobe("tcp_v6_connect", objs.SyscallProbeRetTcpV6Connect)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on tcp_v6_connect")
 		return err
@@ -310,7 +390,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 	h.sendto = snd
 
 	//Opening a kretprobe at the exit of sendto syscall
-	sndr, err := link.Kretprobe("sys_sendto", objs.SyscallProbeRetSendto, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	sndr, err := h.kretprobe("sys_sendto", objs.SyscallProbeRetSendto)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_sendto")
 		return err
@@ -330,7 +410,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Open a Kprobe at the exit point of the kernel function and attach the
 	// pre-compiled program.
-	acRet, err := link.Kretprobe("sys_accept", objs.SyscallProbeRetAccept, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	acRet, err := h.kretprobe("sys_accept", objs.SyscallProbeRetAccept)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_accept")
 		return err
@@ -348,7 +428,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Open a Kprobe at the exit point of the kernel function and attach the
 	// pre-compiled program.
-	ac4Ret, err := link.Kretprobe("sys_accept4", objs.SyscallProbeRetAccept4, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	ac4Ret, err := h.kretprobe("sys_accept4", objs.SyscallProbeRetAccept4)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_accept4")
 		return err
@@ -366,7 +446,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Open a Kprobe at the exit point of the kernel function and attach the
 	// pre-compiled program.
-	rdRet, err := link.Kretprobe("sys_read", objs.SyscallProbeRetRead, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	rdRet, err := h.kretprobe("sys_read", objs.SyscallProbeRetRead)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_read")
 		return err
@@ -384,7 +464,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Open a Kprobe at the exit point of the kernel function and attach the
 	// pre-compiled program.
-	wtRet, err := link.Kretprobe("sys_write", objs.SyscallProbeRetWrite, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	wtRet, err := h.kretprobe("sys_write", objs.SyscallProbeRetWrite)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_write")
 		return err
@@ -402,7 +482,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Open a Kprobe at the exit point of the kernel function and attach the
 	// pre-compiled program for writev.
-	wtvRet, err := link.Kretprobe("sys_writev", objs.SyscallProbeRetWritev, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	wtvRet, err := h.kretprobe("sys_writev", objs.SyscallProbeRetWritev)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_writev")
 		return err
@@ -427,7 +507,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 	h.recvfrom = rcv
 
 	//Attaching a kretprobe at the exit of recvfrom syscall
-	rcvr, err := link.Kretprobe("sys_recvfrom", objs.SyscallProbeRetRecvfrom, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	rcvr, err := h.kretprobe("sys_recvfrom", objs.SyscallProbeRetRecvfrom)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_recvfrom")
 		return err
@@ -436,7 +516,7 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 
 	// Open a Kprobe at the exit point of the kernel function and attach the
 	// pre-compiled program.
-	clRet, err := link.Kretprobe("sys_close", objs.SyscallProbeRetClose, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	clRet, err := h.kretprobe("sys_close", objs.SyscallProbeRetClose)
 	if err != nil {
 		utils.LogError(h.logger, err, "failed to attach the kretprobe hook on sys_close")
 		return err
@@ -492,11 +572,34 @@ func (h *Hooks) load(_ context.Context, opts core.HookCfg) error {
 	return nil
 }
 
-func (h *Hooks) Record(ctx context.Context, _ uint64) (<-chan *models.TestCase, error) {
-	// TODO use the session to get the app id
-	// and then use the app id to get the test cases chan
-	// and pass that to eBPF consumers/listeners
-	return conn.ListenSocket(ctx, h.logger, h.objects.SocketOpenEvents, h.objects.SocketDataEvents, h.objects.SocketCloseEvents)
+// Record returns the testcases of the app, the socket events of all the apps are read once and routed to them.
+func (h *Hooks) Record(ctx context.Context, id uint64) (<-chan *models.TestCase, error) {
+	h.recordMu.Lock()
+	defer h.recordMu.Unlock()
+	if h.testCases == nil {
+		events, err := conn.ListenSocket(ctx, h.logger, h.AppOf, h.idleThreshold, h.objects.SocketOpenEvents, h.objects.SocketDataEvents, h.objects.SocketCloseEvents)
+		if err != nil {
+			return nil, err
+		}
+		h.testCases = newAppRouter[*conn.AppTestCase]()
+		go func() {
+			defer utils.Recover(h.logger)
+			h.testCases.run(events, func(tc *conn.AppTestCase) (uint64, bool) {
+				return tc.AppID, true
+			})
+		}()
+	}
+
+	appTestCases := h.testCases.add(id)
+	t := make(chan *models.TestCase, 500)
+	go func() {
+		defer utils.Recover(h.logger)
+		defer close(t)
+		for tc := range appTestCases {
+			t <- tc.TestCase
+		}
+	}()
+	return t, nil
 }
 
 func (h *Hooks) unLoad(_ context.Context) {
@@ -601,3 +704,36 @@ func (h *Hooks) unLoad(_ context.Context) {
 	}
 	h.logger.Info("eBPF resources released successfully...")
 }
+
+// collectionOptions loads the BTF of the kernel from the btfPath for the CO-RE relocations of the eBPF hooks, on the
+// kernels not exposing it in /sys/kernel/btf/vmlinux.
+func (h *Hooks) collectionOptions() (*ebpf.CollectionOptions, error) {
+	if h.btfPath == "" {
+		return nil, nil
+	}
+	spec, err := btf.LoadSpec(h.btfPath)
+	if err != nil {
+		utils.LogError(h.logger, err, "failed to load the BTF of the kernel", zap.String("path", h.btfPath))
+		return nil, err
+	}
+	return &ebpf.CollectionOptions{Programs: ebpf.ProgramOptions{KernelTypes: spec}}, nil
+}
+
+// kretprobe attaches the kretprobe through tracefs to raise its maxactive, falling back to the kprobe PMU with the
+// default maxactive if tracefs isn't mounted, e.g. in the containers without /sys/kernel/debug.
+func (h *Hooks) kretprobe(symbol string, prog *ebpf.Program) (link.Link, error) {
+	if h.retprobeFallback {
+		return link.Kretprobe(symbol, prog, nil)
+	}
+	l, err := link.Kretprobe(symbol, prog, &link.KprobeOptions{RetprobeMaxActive: 1024})
+	if err == nil {
+		return l, nil
+	}
+	l, fallbackErr := link.Kretprobe(symbol, prog, nil)
+	if fallbackErr != nil {
+		return nil, err
+	}
+	h.logger.Warn("failed to attach the kretprobes through tracefs, attaching them with the default maxactive, the calls of a busy app may be missed", zap.Error(err))
+	h.retprobeFallback = true
+	return l, nil
+}
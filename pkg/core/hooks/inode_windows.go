@@ -0,0 +1,7 @@
+package hooks
+
+import "errors"
+
+func getSelfInodeNumber() (uint64, error) {
+	return 0, errors.New("the eBPF hooks are only supported on linux")
+}
@@ -0,0 +1,201 @@
+package hooks
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// The JSSE agent (see jsse/README.md) wraps the SSLSocket and SSLEngine of the JVM of the app, and sends their
+// plaintext to keploy over TCP in frames of:
+//
+//	u32 pid | u64 connection | u8 kind (tlsWrite, tlsRead or tlsFree) | u32 length | data
+//
+// in big endian, so that the Java apps are recorded without trusting the CA of keploy. The agent first sends the
+// token keploy passed to it with its address, so that the other local processes can't inject plaintext.
+const (
+	jsseFrameHeaderSize = 17
+	// jsseMaxFrame bounds the frames of a broken agent, the agent sends its data in chunks of 64KiB
+	jsseMaxFrame = 1 << 20
+	// jsseTokenSize is the size of the random token of the run, sent in hex by the agent
+	jsseTokenSize = 32
+	// jsseAuthTimeout bounds the wait for the token of a connection
+	jsseAuthTimeout = 5 * time.Second
+	// jsseAgentJar is the name of the jar of the JSSE agent shipped next to the keploy binary
+	jsseAgentJar = "keploy-jsse-agent.jar"
+)
+
+// listenJSSE listens for the JSSE agent in the JVM of the app, which is passed the address in Env.
+func (h *Hooks) listenJSSE(ctx context.Context) error {
+	if h.isDocker {
+		h.logger.Warn("the JSSE agent only supports the native apps, the TLS connections are decrypted by the proxy")
+		return nil
+	}
+	agent, err := findJSSEAgent(h.javaAgent)
+	if err != nil {
+		utils.LogError(h.logger, err, "failed to find the JSSE agent", zap.String("path", h.javaAgent))
+		return err
+	}
+	h.javaAgent = agent
+	token := make([]byte, jsseTokenSize)
+	if _, err := rand.Read(token); err != nil {
+		utils.LogError(h.logger, err, "failed to generate the token of the JSSE agent")
+		return err
+	}
+	h.jsseToken = hex.EncodeToString(token)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		utils.LogError(h.logger, err, "failed to listen for the JSSE agent")
+		return err
+	}
+	context.AfterFunc(ctx, func() {
+		_ = lis.Close()
+	})
+	h.jsseListener = lis
+	return nil
+}
+
+// findJSSEAgent returns the absolute path of the jar of the JSSE agent. A relative path which doesn't exist is
+// looked up next to the keploy binary too, where the releases ship keploy-jsse-agent.jar.
+func findJSSEAgent(path string) (string, error) {
+	agent, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	_, err = os.Stat(agent)
+	if err == nil || filepath.IsAbs(path) {
+		return agent, err
+	}
+	exe, exeErr := os.Executable()
+	if exeErr != nil {
+		return "", err
+	}
+	shipped := filepath.Join(filepath.Dir(exe), path)
+	if _, shippedErr := os.Stat(shipped); shippedErr != nil {
+		return "", err
+	}
+	return shipped, nil
+}
+
+// Env passes the JSSE agent to the JVM of the app with the address and the token of the run, keeping the
+// JAVA_TOOL_OPTIONS set for keploy.
+func (h *Hooks) Env(_ uint64) []string {
+	if h.jsseListener == nil {
+		return nil
+	}
+	opts := fmt.Sprintf("-javaagent:%s=%s,%s", h.javaAgent, h.jsseListener.Addr().String(), h.jsseToken)
+	if existing := os.Getenv("JAVA_TOOL_OPTIONS"); existing != "" {
+		opts = existing + " " + opts
+	}
+	return []string{"JAVA_TOOL_OPTIONS=" + opts}
+}
+
+// jsseData returns the plaintext sent by the JSSE agents of the JVMs of the app.
+func (h *Hooks) jsseData(ctx context.Context) <-chan *core.TLSData {
+	data := make(chan *core.TLSData, 500)
+	go func() {
+		defer utils.Recover(h.logger)
+		var wg sync.WaitGroup
+		defer func() {
+			wg.Wait()
+			close(data)
+		}()
+		for {
+			conn, err := h.jsseListener.Accept()
+			if err != nil {
+				if ctx.Err() == nil {
+					utils.LogError(h.logger, err, "failed to accept the connection of the JSSE agent")
+				}
+				return
+			}
+			h.logger.Debug("the JSSE agent connected", zap.String("address", conn.RemoteAddr().String()))
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer utils.Recover(h.logger)
+				defer conn.Close()
+				stop := context.AfterFunc(ctx, func() {
+					_ = conn.Close()
+				})
+				defer stop()
+				err := authenticateJSSE(conn, h.jsseToken)
+				if err != nil {
+					utils.LogError(h.logger, err, "rejected a connection to the listener of the JSSE agent", zap.String("address", conn.RemoteAddr().String()))
+					return
+				}
+				err = readJSSEFrames(conn, data)
+				if err != nil && ctx.Err() == nil {
+					utils.LogError(h.logger, err, "failed to read the plaintext sent by the JSSE agent")
+				}
+			}()
+		}
+	}()
+	return data
+}
+
+// authenticateJSSE checks that the connection starts with the token of the run.
+func authenticateJSSE(conn net.Conn, token string) error {
+	err := conn.SetReadDeadline(time.Now().Add(jsseAuthTimeout))
+	if err != nil {
+		return err
+	}
+	got := make([]byte, len(token))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		return fmt.Errorf("failed to read the token: %w", err)
+	}
+	if subtle.ConstantTimeCompare(got, []byte(token)) != 1 {
+		return errors.New("the token doesn't match the one of the run")
+	}
+	return conn.SetReadDeadline(time.Time{})
+}
+
+// readJSSEFrames reads the frames of a JSSE agent until it disconnects.
+func readJSSEFrames(conn net.Conn, data chan<- *core.TLSData) error {
+	r := bufio.NewReader(conn)
+	header := make([]byte, jsseFrameHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		size := binary.BigEndian.Uint32(header[13:17])
+		if size > jsseMaxFrame {
+			return fmt.Errorf("the frame of %d bytes is larger than %d", size, jsseMaxFrame)
+		}
+		d := &core.TLSData{
+			Pid:  binary.BigEndian.Uint32(header[0:4]),
+			Conn: binary.BigEndian.Uint64(header[4:12]),
+			Data: make([]byte, size),
+		}
+		switch header[12] {
+		case tlsWrite:
+		case tlsRead:
+			d.Read = true
+		case tlsFree:
+			d.Closed = true
+		default:
+			return fmt.Errorf("unknown kind %d of the frame", header[12])
+		}
+		if _, err := io.ReadFull(r, d.Data); err != nil {
+			return err
+		}
+		data <- d
+	}
+}
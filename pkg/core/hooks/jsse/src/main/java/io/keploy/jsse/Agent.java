@@ -0,0 +1,33 @@
+package io.keploy.jsse;
+
+import java.lang.instrument.Instrumentation;
+import java.security.Security;
+
+/**
+ * Agent registers the keploy provider in front of SunJSSE, so the SSLContexts of the app capture their plaintext.
+ * The argument of the agent is the address keploy listens on for it and the token of the run, separated by a comma.
+ */
+public final class Agent {
+    private Agent() {
+    }
+
+    public static void premain(String args, Instrumentation inst) {
+        install(args);
+    }
+
+    public static void agentmain(String args, Instrumentation inst) {
+        install(args);
+    }
+
+    private static void install(String args) {
+        int i = args == null ? -1 : args.indexOf(',');
+        if (i < 0) {
+            System.err.println("keploy: the JSSE agent needs the address of keploy and the token of the run, it is disabled");
+            return;
+        }
+        if (!Exporter.connect(args.substring(0, i), args.substring(i + 1))) {
+            return;
+        }
+        Security.insertProviderAt(new KeployProvider(), 1);
+    }
+}
@@ -0,0 +1,91 @@
+package io.keploy.jsse;
+
+import java.io.BufferedOutputStream;
+import java.io.DataOutputStream;
+import java.io.IOException;
+import java.net.InetSocketAddress;
+import java.net.Socket;
+import java.nio.charset.StandardCharsets;
+import java.util.concurrent.atomic.AtomicLong;
+
+/**
+ * Exporter sends the plaintext of the TLS connections to keploy in frames of
+ *
+ * <pre>
+ * u32 pid | u64 connection | u8 kind | u32 length | data
+ * </pre>
+ *
+ * in big endian, read by pkg/core/hooks/jsse.go. The frames are preceded by the token of the run, which keploy
+ * checks before reading them.
+ */
+final class Exporter {
+    static final byte WRITE = 0;
+    static final byte READ = 1;
+    static final byte CLOSE = 2;
+
+    // keploy drops the frames larger than 1MiB
+    private static final int CHUNK = 64 * 1024;
+
+    private static final AtomicLong CONNECTIONS = new AtomicLong();
+    private static final int PID = (int) ProcessHandle.current().pid();
+
+    private static DataOutputStream out;
+
+    private Exporter() {
+    }
+
+    static synchronized boolean connect(String address, String token) {
+        int i = address.lastIndexOf(':');
+        if (i < 0) {
+            System.err.println("keploy: the address " + address + " of keploy has no port, the JSSE agent is disabled");
+            return false;
+        }
+        try {
+            Socket socket = new Socket();
+            socket.connect(new InetSocketAddress(address.substring(0, i), Integer.parseInt(address.substring(i + 1))));
+            socket.setTcpNoDelay(true);
+            out = new DataOutputStream(new BufferedOutputStream(socket.getOutputStream(), CHUNK));
+            out.write(token.getBytes(StandardCharsets.US_ASCII));
+            out.flush();
+            return true;
+        } catch (IOException | NumberFormatException e) {
+            System.err.println("keploy: failed to connect to keploy at " + address + ", the JSSE agent is disabled: " + e);
+            return false;
+        }
+    }
+
+    /** nextConnection returns the id of a new TLS connection. */
+    static long nextConnection() {
+        return CONNECTIONS.incrementAndGet();
+    }
+
+    static void send(long conn, byte kind, byte[] data, int off, int len) {
+        do {
+            int n = Math.min(len, CHUNK);
+            frame(conn, kind, data, off, n);
+            off += n;
+            len -= n;
+        } while (len > 0);
+    }
+
+    static void close(long conn) {
+        frame(conn, CLOSE, new byte[0], 0, 0);
+    }
+
+    private static synchronized void frame(long conn, byte kind, byte[] data, int off, int len) {
+        if (out == null) {
+            return;
+        }
+        try {
+            out.writeInt(PID);
+            out.writeLong(conn);
+            out.writeByte(kind);
+            out.writeInt(len);
+            out.write(data, off, len);
+            out.flush();
+        } catch (IOException e) {
+            // keploy stopped, the app goes on without being recorded
+            out = null;
+        }
+    }
+}
@@ -0,0 +1,91 @@
+package io.keploy.jsse;
+
+import java.security.KeyManagementException;
+import java.security.NoSuchAlgorithmException;
+import java.security.NoSuchProviderException;
+import java.security.SecureRandom;
+import javax.net.ssl.KeyManager;
+import javax.net.ssl.SSLContext;
+import javax.net.ssl.SSLContextSpi;
+import javax.net.ssl.SSLEngine;
+import javax.net.ssl.SSLServerSocketFactory;
+import javax.net.ssl.SSLSessionContext;
+import javax.net.ssl.SSLSocketFactory;
+import javax.net.ssl.TrustManager;
+
+/**
+ * KeployContextSpi is an SSLContext of SunJSSE whose sockets and engines capture their plaintext. The server
+ * sockets aren't wrapped, only the calls of the app to its dependencies are recorded.
+ */
+abstract class KeployContextSpi extends SSLContextSpi {
+    private final SSLContext delegate;
+
+    KeployContextSpi(String protocol) {
+        try {
+            delegate = SSLContext.getInstance(protocol, "SunJSSE");
+        } catch (NoSuchAlgorithmException | NoSuchProviderException e) {
+            throw new IllegalStateException("keploy: failed to get the " + protocol + " SSLContext of SunJSSE", e);
+        }
+    }
+
+    @Override
+    protected void engineInit(KeyManager[] km, TrustManager[] tm, SecureRandom sr) throws KeyManagementException {
+        delegate.init(km, tm, sr);
+    }
+
+    @Override
+    protected SSLSocketFactory engineGetSocketFactory() {
+        return new TeeSSLSocketFactory(delegate.getSocketFactory());
+    }
+
+    @Override
+    protected SSLServerSocketFactory engineGetServerSocketFactory() {
+        return delegate.getServerSocketFactory();
+    }
+
+    @Override
+    protected SSLEngine engineCreateSSLEngine() {
+        return new TeeSSLEngine(delegate.createSSLEngine());
+    }
+
+    @Override
+    protected SSLEngine engineCreateSSLEngine(String host, int port) {
+        return new TeeSSLEngine(delegate.createSSLEngine(host, port));
+    }
+
+    @Override
+    protected SSLSessionContext engineGetServerSessionContext() {
+        return delegate.getServerSessionContext();
+    }
+
+    @Override
+    protected SSLSessionContext engineGetClientSessionContext() {
+        return delegate.getClientSessionContext();
+    }
+
+    // the provider instantiates the SSLContextSpi of each protocol from its class name
+
+    public static final class Default extends KeployContextSpi {
+        public Default() {
+            super("Default");
+        }
+    }
+
+    public static final class TLS extends KeployContextSpi {
+        public TLS() {
+            super("TLS");
+        }
+    }
+
+    public static final class TLSv12 extends KeployContextSpi {
+        public TLSv12() {
+            super("TLSv1.2");
+        }
+    }
+
+    public static final class TLSv13 extends KeployContextSpi {
+        public TLSv13() {
+            super("TLSv1.3");
+        }
+    }
+}
@@ -0,0 +1,18 @@
+package io.keploy.jsse;
+
+import java.security.Provider;
+
+/**
+ * KeployProvider provides the SSLContexts of SunJSSE wrapped by KeployContextSpi.
+ */
+final class KeployProvider extends Provider {
+    private static final long serialVersionUID = 1L;
+
+    KeployProvider() {
+        super("Keploy", "1.0", "captures the plaintext of the TLS connections for keploy");
+        put("SSLContext.Default", KeployContextSpi.Default.class.getName());
+        put("SSLContext.TLS", KeployContextSpi.TLS.class.getName());
+        put("SSLContext.TLSv1.2", KeployContextSpi.TLSv12.class.getName());
+        put("SSLContext.TLSv1.3", KeployContextSpi.TLSv13.class.getName());
+    }
+}
@@ -0,0 +1,217 @@
+package io.keploy.jsse;
+
+import java.nio.ByteBuffer;
+import java.util.List;
+import java.util.concurrent.atomic.AtomicBoolean;
+import java.util.function.BiFunction;
+import javax.net.ssl.SSLEngine;
+import javax.net.ssl.SSLEngineResult;
+import javax.net.ssl.SSLException;
+import javax.net.ssl.SSLParameters;
+import javax.net.ssl.SSLSession;
+
+/**
+ * TeeSSLEngine is an SSLEngine of SunJSSE, used by the HTTP client of the JDK and netty, whose plaintext is sent to
+ * keploy if it is a client engine. The plaintext consumed by wrap is what the app writes, and the plaintext produced
+ * by unwrap what it reads.
+ */
+final class TeeSSLEngine extends SSLEngine {
+    private final SSLEngine delegate;
+    private final long conn = Exporter.nextConnection();
+    private final AtomicBoolean closed = new AtomicBoolean();
+
+    TeeSSLEngine(SSLEngine delegate) {
+        super(delegate.getPeerHost(), delegate.getPeerPort());
+        this.delegate = delegate;
+    }
+
+    @Override
+    public SSLEngineResult wrap(ByteBuffer[] srcs, int offset, int length, ByteBuffer dst) throws SSLException {
+        ByteBuffer[] plain = duplicate(srcs, offset, length);
+        SSLEngineResult result = delegate.wrap(srcs, offset, length, dst);
+        export(Exporter.WRITE, plain, result.bytesConsumed());
+        return result;
+    }
+
+    @Override
+    public SSLEngineResult unwrap(ByteBuffer src, ByteBuffer[] dsts, int offset, int length) throws SSLException {
+        ByteBuffer[] plain = duplicate(dsts, offset, length);
+        SSLEngineResult result = delegate.unwrap(src, dsts, offset, length);
+        export(Exporter.READ, plain, result.bytesProduced());
+        return result;
+    }
+
+    private static ByteBuffer[] duplicate(ByteBuffer[] buffers, int offset, int length) {
+        ByteBuffer[] dups = new ByteBuffer[length];
+        for (int i = 0; i < length; i++) {
+            dups[i] = buffers[offset + i].duplicate();
+        }
+        return dups;
+    }
+
+    // export sends the n bytes from the positions of the buffers before the call
+    private void export(byte kind, ByteBuffer[] buffers, int n) {
+        if (n <= 0 || !delegate.getUseClientMode()) {
+            return;
+        }
+        byte[] data = new byte[n];
+        int off = 0;
+        for (ByteBuffer b : buffers) {
+            int size = Math.min(b.remaining(), n - off);
+            b.get(data, off, size);
+            off += size;
+            if (off == n) {
+                break;
+            }
+        }
+        Exporter.send(conn, kind, data, 0, off);
+    }
+
+    @Override
+    public void closeOutbound() {
+        delegate.closeOutbound();
+        if (closed.compareAndSet(false, true)) {
+            Exporter.close(conn);
+        }
+    }
+
+    @Override
+    public boolean isOutboundDone() {
+        return delegate.isOutboundDone();
+    }
+
+    @Override
+    public void closeInbound() throws SSLException {
+        delegate.closeInbound();
+    }
+
+    @Override
+    public boolean isInboundDone() {
+        return delegate.isInboundDone();
+    }
+
+    @Override
+    public Runnable getDelegatedTask() {
+        return delegate.getDelegatedTask();
+    }
+
+    @Override
+    public String[] getSupportedCipherSuites() {
+        return delegate.getSupportedCipherSuites();
+    }
+
+    @Override
+    public String[] getEnabledCipherSuites() {
+        return delegate.getEnabledCipherSuites();
+    }
+
+    @Override
+    public void setEnabledCipherSuites(String[] suites) {
+        delegate.setEnabledCipherSuites(suites);
+    }
+
+    @Override
+    public String[] getSupportedProtocols() {
+        return delegate.getSupportedProtocols();
+    }
+
+    @Override
+    public String[] getEnabledProtocols() {
+        return delegate.getEnabledProtocols();
+    }
+
+    @Override
+    public void setEnabledProtocols(String[] protocols) {
+        delegate.setEnabledProtocols(protocols);
+    }
+
+    @Override
+    public SSLSession getSession() {
+        return delegate.getSession();
+    }
+
+    @Override
+    public SSLSession getHandshakeSession() {
+        return delegate.getHandshakeSession();
+    }
+
+    @Override
+    public void beginHandshake() throws SSLException {
+        delegate.beginHandshake();
+    }
+
+    @Override
+    public SSLEngineResult.HandshakeStatus getHandshakeStatus() {
+        return delegate.getHandshakeStatus();
+    }
+
+    @Override
+    public void setUseClientMode(boolean mode) {
+        delegate.setUseClientMode(mode);
+    }
+
+    @Override
+    public boolean getUseClientMode() {
+        return delegate.getUseClientMode();
+    }
+
+    @Override
+    public void setNeedClientAuth(boolean need) {
+        delegate.setNeedClientAuth(need);
+    }
+
+    @Override
+    public boolean getNeedClientAuth() {
+        return delegate.getNeedClientAuth();
+    }
+
+    @Override
+    public void setWantClientAuth(boolean want) {
+        delegate.setWantClientAuth(want);
+    }
+
+    @Override
+    public boolean getWantClientAuth() {
+        return delegate.getWantClientAuth();
+    }
+
+    @Override
+    public void setEnableSessionCreation(boolean flag) {
+        delegate.setEnableSessionCreation(flag);
+    }
+
+    @Override
+    public boolean getEnableSessionCreation() {
+        return delegate.getEnableSessionCreation();
+    }
+
+    @Override
+    public SSLParameters getSSLParameters() {
+        return delegate.getSSLParameters();
+    }
+
+    @Override
+    public void setSSLParameters(SSLParameters params) {
+        delegate.setSSLParameters(params);
+    }
+
+    @Override
+    public String getApplicationProtocol() {
+        return delegate.getApplicationProtocol();
+    }
+
+    @Override
+    public String getHandshakeApplicationProtocol() {
+        return delegate.getHandshakeApplicationProtocol();
+    }
+
+    @Override
+    public void setHandshakeApplicationProtocolSelector(BiFunction<SSLEngine, List<String>, String> selector) {
+        delegate.setHandshakeApplicationProtocolSelector(selector);
+    }
+
+    @Override
+    public BiFunction<SSLEngine, List<String>, String> getHandshakeApplicationProtocolSelector() {
+        return delegate.getHandshakeApplicationProtocolSelector();
+    }
+}
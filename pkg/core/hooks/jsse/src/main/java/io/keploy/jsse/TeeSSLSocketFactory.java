@@ -0,0 +1,62 @@
+package io.keploy.jsse;
+
+import java.io.IOException;
+import java.net.InetAddress;
+import java.net.Socket;
+import javax.net.ssl.SSLSocket;
+import javax.net.ssl.SSLSocketFactory;
+
+/**
+ * TeeSSLSocketFactory wraps the sockets of SunJSSE in TeeSSLSocket.
+ */
+final class TeeSSLSocketFactory extends SSLSocketFactory {
+    private final SSLSocketFactory delegate;
+
+    TeeSSLSocketFactory(SSLSocketFactory delegate) {
+        this.delegate = delegate;
+    }
+
+    private static Socket tee(Socket s) {
+        return s instanceof SSLSocket ? new TeeSSLSocket((SSLSocket) s) : s;
+    }
+
+    @Override
+    public String[] getDefaultCipherSuites() {
+        return delegate.getDefaultCipherSuites();
+    }
+
+    @Override
+    public String[] getSupportedCipherSuites() {
+        return delegate.getSupportedCipherSuites();
+    }
+
+    @Override
+    public Socket createSocket() throws IOException {
+        return tee(delegate.createSocket());
+    }
+
+    @Override
+    public Socket createSocket(Socket s, String host, int port, boolean autoClose) throws IOException {
+        return tee(delegate.createSocket(s, host, port, autoClose));
+    }
+
+    @Override
+    public Socket createSocket(String host, int port) throws IOException {
+        return tee(delegate.createSocket(host, port));
+    }
+
+    @Override
+    public Socket createSocket(String host, int port, InetAddress localHost, int localPort) throws IOException {
+        return tee(delegate.createSocket(host, port, localHost, localPort));
+    }
+
+    @Override
+    public Socket createSocket(InetAddress host, int port) throws IOException {
+        return tee(delegate.createSocket(host, port));
+    }
+
+    @Override
+    public Socket createSocket(InetAddress address, int port, InetAddress localAddress, int localPort) throws IOException {
+        return tee(delegate.createSocket(address, port, localAddress, localPort));
+    }
+}
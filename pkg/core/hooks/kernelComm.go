@@ -20,18 +20,26 @@ func (h *Hooks) Get(_ context.Context, srcPort uint16) (*core.NetworkAddress, er
 	if err != nil {
 		return nil, err
 	}
-	// TODO : need to implement eBPF code to differentiate between different apps
-	s, ok := h.sess.Get(0)
+	// the apps are told apart by the process of the connection, its pid isn't known for the connections
+	// redirected before it was set by the hooks
+	appID, inApp := h.lastApp.Load(), true
+	if d.KernelPid != 0 {
+		if appID, inApp = h.AppOf(d.KernelPid); !inApp {
+			appID = h.lastApp.Load()
+		}
+	}
+	s, ok := h.sess.Get(appID)
 	if !ok {
 		return nil, fmt.Errorf("session not found")
 	}
 
 	return &core.NetworkAddress{
-		AppID:    s.ID,
-		Version:  d.IPVersion,
-		IPv4Addr: d.DestIP4,
-		IPv6Addr: d.DestIP6,
-		Port:     d.DestPort,
+		AppID:      s.ID,
+		Version:    d.IPVersion,
+		IPv4Addr:   d.DestIP4,
+		IPv6Addr:   d.DestIP6,
+		Port:       d.DestPort,
+		OutsideApp: !inApp,
 	}, nil
 }
 
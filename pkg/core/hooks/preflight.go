@@ -0,0 +1,244 @@
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// MinKernel is the oldest kernel the eBPF hooks are loaded on.
+var MinKernel = [2]int{5, 15}
+
+// Check is the result of a check of the machine before loading the eBPF hooks, Err is nil if it passed.
+type Check struct {
+	Name   string
+	Detail string
+	Err    error
+	// Hint tells how to fix the failed check.
+	Hint string
+	// Warn is set on the failed checks which may not stop the eBPF hooks from loading.
+	Warn bool
+}
+
+// Preflight checks that the eBPF hooks can be loaded on the machine, so that an unsupported kernel fails with
+// the fix instead of an error of the verifier. The BTF of the kernel is read from btfPath if it is set.
+func Preflight(btfPath string) []Check {
+	return []Check{
+		CheckKernel(),
+		CheckBTF(btfPath),
+		CheckProbes(),
+		CheckCgroup(),
+		CheckCapabilities(),
+		CheckLockdown(),
+		CheckLSM(),
+		CheckSeccomp(),
+	}
+}
+
+// PreflightError returns the failed checks as an error, or nil if they all passed or only warn.
+func PreflightError(checks []Check) error {
+	var errs []error
+	for _, c := range checks {
+		if c.Err != nil && !c.Warn {
+			errs = append(errs, fmt.Errorf("%s: %w", c.Name, c.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// logChecks logs the failed checks with their hints.
+func logChecks(logger *zap.Logger, checks []Check) {
+	for _, c := range checks {
+		if c.Err != nil && c.Warn {
+			logger.Warn(fmt.Sprintf("the %s check of the eBPF hooks failed, they may still load", c.Name), zap.Error(c.Err), zap.String("fix", c.Hint))
+			continue
+		}
+		if c.Err != nil {
+			utils.LogError(logger, c.Err, fmt.Sprintf("the %s check of the eBPF hooks failed", c.Name), zap.String("found", c.Detail), zap.String("fix", c.Hint))
+			continue
+		}
+		logger.Debug(fmt.Sprintf("the %s check of the eBPF hooks passed", c.Name), zap.String("found", c.Detail))
+	}
+}
+
+// CheckKernel checks that the kernel is at least MinKernel.
+func CheckKernel() Check {
+	c := Check{Name: "kernel"}
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		c.Err = fmt.Errorf("failed to read the version of the kernel: %w", err)
+		c.Hint = "the eBPF hooks are only supported on linux, use --redirect proxy elsewhere"
+		return c
+	}
+	c.Detail = fmt.Sprintf("%s on %s", strings.TrimSpace(string(release)), runtime.GOARCH)
+	major, minor, err := parseKernelRelease(string(release))
+	if err != nil {
+		c.Err = err
+		return c
+	}
+	if major < MinKernel[0] || (major == MinKernel[0] && minor < MinKernel[1]) {
+		c.Err = fmt.Errorf("the kernel %d.%d is older than %d.%d", major, minor, MinKernel[0], MinKernel[1])
+		c.Hint = fmt.Sprintf("upgrade the kernel to %d.%d or later, or run keploy test with --redirect iptables", MinKernel[0], MinKernel[1])
+	}
+	return c
+}
+
+// parseKernelRelease parses the major and minor versions of a kernel release e.g. 6.5.0-1016-azure.
+func parseKernelRelease(release string) (int, int, error) {
+	parts := strings.SplitN(strings.TrimSpace(release), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("failed to parse the kernel release %q", release)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse the kernel release %q: %w", release, err)
+	}
+	minor := parts[1]
+	if i := strings.IndexFunc(minor, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		minor = minor[:i]
+	}
+	m, err := strconv.Atoi(minor)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to parse the kernel release %q: %w", release, err)
+	}
+	return major, m, nil
+}
+
+// CheckBTF checks that the BTF of the kernel the CO-RE relocations of the eBPF hooks need is there.
+func CheckBTF(btfPath string) Check {
+	c := Check{Name: "BTF"}
+	if btfPath != "" {
+		c.Detail = btfPath
+		if _, err := os.Stat(btfPath); err != nil {
+			c.Err = fmt.Errorf("failed to read the BTF of the kernel: %w", err)
+			c.Hint = "set --btfPath to the BTF of the running kernel, e.g. from https://github.com/aquasecurity/btfhub-archive"
+		}
+		return c
+	}
+	c.Detail = "/sys/kernel/btf/vmlinux"
+	if _, err := os.Stat("/sys/kernel/btf/vmlinux"); err != nil {
+		c.Err = errors.New("the kernel doesn't expose its BTF")
+		c.Hint = "use a kernel built with CONFIG_DEBUG_INFO_BTF=y, or download its BTF from https://github.com/aquasecurity/btfhub-archive and set --btfPath"
+	}
+	return c
+}
+
+// CheckProbes checks that the kprobes can be attached, through the kprobe PMU or tracefs.
+func CheckProbes() Check {
+	c := Check{Name: "kprobes"}
+	if _, err := os.Stat("/sys/bus/event_source/devices/kprobe/type"); err == nil {
+		c.Detail = "kprobe PMU"
+		return c
+	}
+	for _, path := range []string{"/sys/kernel/tracing", "/sys/kernel/debug/tracing"} {
+		if _, err := os.Stat(path + "/kprobe_events"); err == nil {
+			c.Detail = "tracefs at " + path
+			return c
+		}
+	}
+	c.Err = errors.New("neither the kprobe PMU nor tracefs is available")
+	c.Hint = "mount tracefs with `mount -t tracefs nodev /sys/kernel/tracing`, or mount /sys/kernel/debug into the keploy container"
+	return c
+}
+
+// CheckCgroup checks that cgroup v2 is mounted, the connect hooks are attached to its root.
+func CheckCgroup() Check {
+	c := Check{Name: "cgroup v2"}
+	path, err := detectCgroupPath(zap.NewNop())
+	if err != nil {
+		c.Err = err
+		c.Hint = "mount cgroup v2 with `mount -t cgroup2 none /sys/fs/cgroup`, or boot with systemd.unified_cgroup_hierarchy=1"
+		return c
+	}
+	c.Detail = path
+	return c
+}
+
+// CheckCapabilities checks that keploy has the capabilities of the eBPF hooks.
+func CheckCapabilities() Check {
+	c := Check{Name: "capabilities"}
+	missing, err := utils.MissingCapabilities(utils.EBPFCapabilities...)
+	if err != nil {
+		c.Err = fmt.Errorf("failed to read the capabilities of keploy: %w", err)
+		return c
+	}
+	if len(missing) > 0 {
+		c.Err = fmt.Errorf("keploy is missing %s", strings.Join(missing, ", "))
+		c.Hint = "run keploy with sudo, or grant the capabilities with setcap"
+		return c
+	}
+	c.Detail = "CAP_BPF, CAP_PERFMON and CAP_NET_ADMIN"
+	return c
+}
+
+// CheckLockdown checks that the lockdown of the kernel lets the eBPF hooks read its memory, which the
+// confidentiality mode forbids.
+func CheckLockdown() Check {
+	c := Check{Name: "lockdown"}
+	data, err := os.ReadFile("/sys/kernel/security/lockdown")
+	if err != nil {
+		// the kernels without the lockdown LSM or securityfs mounted
+		c.Detail = "none"
+		return c
+	}
+	// e.g. none [integrity] confidentiality, the mode in use is bracketed
+	c.Detail = strings.TrimSpace(string(data))
+	if strings.Contains(c.Detail, "[confidentiality]") {
+		c.Err = errors.New("the kernel is locked down in the confidentiality mode, which forbids the eBPF hooks to read its memory")
+		c.Hint = "boot with lockdown=integrity or lockdown=none, the confidentiality mode is often set by secure boot"
+	}
+	return c
+}
+
+// CheckLSM checks that SELinux doesn't enforce a policy, which may deny the bpf and perf_event_open calls of keploy.
+func CheckLSM() Check {
+	c := Check{Name: "LSM", Detail: "none"}
+	data, err := os.ReadFile("/sys/kernel/security/lsm")
+	if err == nil && len(strings.TrimSpace(string(data))) > 0 {
+		c.Detail = strings.TrimSpace(string(data))
+	}
+	enforce, err := os.ReadFile("/sys/fs/selinux/enforce")
+	if err == nil && strings.TrimSpace(string(enforce)) == "1" {
+		c.Err = errors.New("SELinux is enforcing, its policy may deny the bpf calls of keploy")
+		c.Hint = "allow the bpf and perfmon permissions to keploy in the policy, or try it with `setenforce 0`"
+		c.Warn = true
+	}
+	return c
+}
+
+// CheckSeccomp checks that keploy isn't run under a seccomp filter, like the default profile of docker, which may
+// deny the bpf and perf_event_open calls.
+func CheckSeccomp() Check {
+	c := Check{Name: "seccomp"}
+	status, err := os.ReadFile("/proc/self/status")
+	if err != nil {
+		c.Err = fmt.Errorf("failed to read the seccomp mode of keploy: %w", err)
+		c.Warn = true
+		return c
+	}
+	for _, line := range strings.Split(string(status), "\n") {
+		mode, ok := strings.CutPrefix(line, "Seccomp:")
+		if !ok {
+			continue
+		}
+		// 0 is disabled, 1 is strict and 2 is a filter
+		switch strings.TrimSpace(mode) {
+		case "0":
+			c.Detail = "disabled"
+		default:
+			c.Detail = "filtered"
+			c.Err = errors.New("keploy runs under a seccomp filter, which may deny the bpf calls")
+			c.Hint = "run the keploy container with --privileged or --security-opt seccomp=unconfined"
+			c.Warn = true
+		}
+		return c
+	}
+	c.Detail = "not supported by the kernel"
+	return c
+}
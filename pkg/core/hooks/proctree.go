@@ -0,0 +1,123 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// procTree is the process tree of a native app: the processes forked by its command, like the workers of a
+// pre-fork server, npm spawning node, or gunicorn spawning its workers. The eBPF hooks intercept every process of
+// the pid namespace, so the traffic of the processes outside the tree is filtered in userspace.
+type procTree struct {
+	m sync.Mutex
+	// members caches the processes found in the tree, so that they stay in it once their parent exits and they are
+	// reparented to init
+	members map[uint32]bool
+}
+
+func newProcTree(root uint32) *procTree {
+	return &procTree{
+		members: map[uint32]bool{root: true},
+	}
+}
+
+// Contains reports whether the process, or the thread, is in the tree. /proc is only read for the processes not
+// known to be in it yet.
+func (t *procTree) Contains(pid uint32) bool {
+	t.m.Lock()
+	known := t.members[pid]
+	t.m.Unlock()
+	if known {
+		return true
+	}
+
+	tgid, ppid, err := procParent(pid)
+	if err != nil {
+		// the process exited without being seen in the tree
+		return false
+	}
+
+	t.m.Lock()
+	defer t.m.Unlock()
+	// the thread is cached too, so that its next events don't read /proc
+	chain := []uint32{pid}
+	for cur, parent := tgid, ppid; ; {
+		if t.members[cur] {
+			for _, p := range chain {
+				t.members[p] = true
+			}
+			return true
+		}
+		chain = append(chain, cur)
+		if parent <= 1 {
+			return false
+		}
+		cur = parent
+		if _, parent, err = procParent(cur); err != nil {
+			return false
+		}
+	}
+}
+
+// watch adds the processes of the tree to the members until the context is done, so that the ones reparented to
+// init later, e.g. the workers of a master which exited, are still known.
+func (t *procTree) watch(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		entries, err := os.ReadDir("/proc")
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			pid, err := strconv.ParseUint(e.Name(), 10, 32)
+			if err != nil {
+				continue
+			}
+			t.Contains(uint32(pid))
+		}
+	}
+}
+
+// procParent returns the process of the thread and its parent from /proc.
+func procParent(pid uint32) (tgid uint32, ppid uint32, err error) {
+	status, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return 0, 0, err
+	}
+	var foundTgid, foundPpid bool
+	for _, line := range strings.Split(string(status), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "Tgid":
+			v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+			if err != nil {
+				return 0, 0, err
+			}
+			tgid, foundTgid = uint32(v), true
+		case "PPid":
+			v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 32)
+			if err != nil {
+				return 0, 0, err
+			}
+			ppid, foundPpid = uint32(v), true
+		}
+		if foundTgid && foundPpid {
+			return tgid, ppid, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("no parent of the process %d in /proc", pid)
+}
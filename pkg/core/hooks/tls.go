@@ -0,0 +1,274 @@
+package hooks
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/cilium/ebpf"
+	"github.com/cilium/ebpf/link"
+	"github.com/cilium/ebpf/ringbuf"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+//go:generate go run github.com/cilium/ebpf/cmd/bpf2go -target amd64,arm64 tls bpf/tls.c
+
+// The uprobes on SSL_read and SSL_write of OpenSSL and BoringSSL of bpf/tls.c send the plaintext of the TLS
+// connections of the app in the events of the tls_events ring buffer:
+//
+//	struct tls_event {
+//		u64 ssl;      // the SSL* of the connection
+//		u64 pid_tgid;
+//		u32 len;      // the length of data, 0 when the connection is freed
+//		u32 kind;     // tlsWrite, tlsRead or tlsFree
+//		u8  data[];
+//	};
+const (
+	tlsHeaderSize = 24
+
+	tlsWrite = 0
+	tlsRead  = 1
+	tlsFree  = 2
+)
+
+// tlsSymbols are the functions of the TLS libraries the uprobes are attached to, the _ex variants are used by e.g.
+// Python, and not exported by BoringSSL.
+var tlsSymbols = []struct {
+	symbol string
+	kind   int32
+	ex     bool
+}{
+	{"SSL_write", tlsWrite, false},
+	{"SSL_read", tlsRead, false},
+	{"SSL_write_ex", tlsWrite, true},
+	{"SSL_read_ex", tlsRead, true},
+}
+
+// tlsLibraryGlobs are where the shared libssl of the distributions is found.
+var tlsLibraryGlobs = []string{
+	"/lib/*-linux-gnu/libssl.so*",
+	"/usr/lib/*-linux-gnu/libssl.so*",
+	"/lib64/libssl.so*",
+	"/usr/lib64/libssl.so*",
+	"/usr/lib/libssl.so*",
+	"/lib/libssl.so*",
+}
+
+// tlsReturnProgram returns the uretprobe of the call of the symbol.
+func (objs *tlsObjects) tlsReturnProgram(kind int32, ex bool) *ebpf.Program {
+	switch {
+	case kind == tlsRead && ex:
+		return objs.TlsReadRetEx
+	case kind == tlsRead:
+		return objs.TlsReadRet
+	case ex:
+		return objs.TlsWriteRetEx
+	default:
+		return objs.TlsWriteRet
+	}
+}
+
+// TLSData returns the plaintext of the TLS connections of the app captured by the uprobes on its TLS libraries and
+// by the JSSE agent in its JVM, or nil if the TLS connections are decrypted by the proxy instead. The plaintext is
+// captured once for all the apps and routed to them by its process.
+func (h *Hooks) TLSData(ctx context.Context, id uint64) (<-chan *core.TLSData, error) {
+	h.recordMu.Lock()
+	defer h.recordMu.Unlock()
+	if h.tlsData == nil {
+		data, err := h.tlsSources(ctx)
+		if err != nil || data == nil {
+			return nil, err
+		}
+		h.tlsData = newAppRouter[*core.TLSData]()
+		go func() {
+			defer utils.Recover(h.logger)
+			h.tlsData.run(data, func(d *core.TLSData) (uint64, bool) {
+				return h.AppOf(d.Pid)
+			})
+		}()
+	}
+	return h.tlsData.add(id), nil
+}
+
+// tlsSources returns the plaintext captured by the uprobes and the JSSE agent, or nil if neither is enabled.
+func (h *Hooks) tlsSources(ctx context.Context) (<-chan *core.TLSData, error) {
+	var sources []<-chan *core.TLSData
+	if h.tlsUprobes && h.isDocker {
+		h.logger.Warn("the TLS uprobes only support the native apps, the TLS connections are decrypted by the proxy")
+	} else if h.tlsUprobes {
+		data, err := h.tlsUprobeData(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, data)
+	}
+	if h.jsseListener != nil {
+		sources = append(sources, h.jsseData(ctx))
+	}
+
+	switch len(sources) {
+	case 0:
+		return nil, nil
+	case 1:
+		return sources[0], nil
+	}
+	data := make(chan *core.TLSData, 500)
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range source {
+				data <- d
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(data)
+	}()
+	return data, nil
+}
+
+// tlsUprobeData attaches the uprobes to the TLS libraries of the app and returns the plaintext they capture.
+func (h *Hooks) tlsUprobeData(ctx context.Context) (<-chan *core.TLSData, error) {
+	var objs tlsObjects
+	if err := loadTlsObjects(&objs, nil); err != nil {
+		utils.LogError(h.logger, err, "failed to load the TLS uprobes")
+		return nil, err
+	}
+
+	var links []link.Link
+	closeAll := func() {
+		for _, l := range links {
+			_ = l.Close()
+		}
+		_ = objs.Close()
+	}
+	for _, lib := range h.tlsLibraries() {
+		ex, err := link.OpenExecutable(lib)
+		if err != nil {
+			h.logger.Debug("failed to open the TLS library", zap.String("library", lib), zap.Error(err))
+			continue
+		}
+		attached := 0
+		for _, s := range tlsSymbols {
+			entry, err := ex.Uprobe(s.symbol, objs.TlsEntry, nil)
+			if err != nil {
+				// e.g. the _ex variants aren't exported by BoringSSL and OpenSSL 1.1.0
+				h.logger.Debug("failed to attach the TLS uprobe", zap.String("library", lib), zap.String("symbol", s.symbol), zap.Error(err))
+				continue
+			}
+			links = append(links, entry)
+			ret, err := ex.Uretprobe(s.symbol, objs.tlsReturnProgram(s.kind, s.ex), nil)
+			if err != nil {
+				utils.LogError(h.logger, err, "failed to attach the TLS uretprobe", zap.String("library", lib), zap.String("symbol", s.symbol))
+				closeAll()
+				return nil, err
+			}
+			links = append(links, ret)
+			attached++
+		}
+		if free, err := ex.Uprobe("SSL_free", objs.TlsFree, nil); err == nil {
+			links = append(links, free)
+		}
+		if attached > 0 {
+			h.logger.Info("capturing the plaintext of the TLS connections of the app", zap.String("library", lib))
+		}
+	}
+	if len(links) == 0 {
+		closeAll()
+		return nil, errors.New("no TLS library with SSL_read and SSL_write found, set the libraries or binaries of the app with --tlsLibraries")
+	}
+
+	reader, err := ringbuf.NewReader(objs.TlsEvents)
+	if err != nil {
+		closeAll()
+		utils.LogError(h.logger, err, "failed to read the events of the TLS uprobes")
+		return nil, err
+	}
+
+	data := make(chan *core.TLSData, 500)
+	go func() {
+		defer utils.Recover(h.logger)
+		defer close(data)
+		for {
+			record, err := reader.Read()
+			if err != nil {
+				if !errors.Is(err, ringbuf.ErrClosed) {
+					utils.LogError(h.logger, err, "failed to read the event of the TLS uprobes")
+				}
+				return
+			}
+			event, err := decodeTLSEvent(record.RawSample)
+			if err != nil {
+				h.logger.Debug("failed to decode the event of the TLS uprobes", zap.Error(err))
+				continue
+			}
+			data <- event
+		}
+	}()
+	go func() {
+		defer utils.Recover(h.logger)
+		<-ctx.Done()
+		_ = reader.Close()
+		closeAll()
+	}()
+	return data, nil
+}
+
+func decodeTLSEvent(raw []byte) (*core.TLSData, error) {
+	if len(raw) < tlsHeaderSize {
+		return nil, fmt.Errorf("the event is %d bytes, shorter than its header", len(raw))
+	}
+	size := binary.LittleEndian.Uint32(raw[16:20])
+	if int(size) > len(raw)-tlsHeaderSize {
+		return nil, fmt.Errorf("the event has %d bytes of data out of %d", len(raw)-tlsHeaderSize, size)
+	}
+	event := &core.TLSData{
+		Conn: binary.LittleEndian.Uint64(raw[0:8]),
+		Pid:  uint32(binary.LittleEndian.Uint64(raw[8:16]) >> 32),
+	}
+	switch binary.LittleEndian.Uint32(raw[20:24]) {
+	case tlsRead:
+		event.Read = true
+	case tlsFree:
+		event.Closed = true
+	}
+	event.Data = append([]byte(nil), raw[tlsHeaderSize:tlsHeaderSize+int(size)]...)
+	return event, nil
+}
+
+// tlsLibraries returns the shared libssl of the distribution, the TLS libraries of the interpreter of the app e.g.
+// node or python, and the libraries and binaries set in the config, e.g. the binaries linking OpenSSL or BoringSSL
+// statically.
+func (h *Hooks) tlsLibraries() []string {
+	seen := map[string]bool{}
+	var libs []string
+	add := func(path string) {
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil || seen[resolved] {
+			return
+		}
+		seen[resolved] = true
+		libs = append(libs, resolved)
+	}
+	for _, pattern := range tlsLibraryGlobs {
+		matches, _ := filepath.Glob(pattern)
+		for _, m := range matches {
+			add(m)
+		}
+	}
+	for _, lib := range tlsRuntimeLibraries(h.appCommand) {
+		add(lib)
+	}
+	for _, lib := range h.tlsLibraryPaths {
+		add(lib)
+	}
+	return libs
+}
@@ -0,0 +1,157 @@
+// Code generated by bpf2go; DO NOT EDIT.
+//go:build arm64
+
+package hooks
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+
+	"github.com/cilium/ebpf"
+)
+
+type tlsTlsArgs struct {
+	Ssl    uint64
+	Buf    uint64
+	LenPtr uint64
+}
+
+type tlsTlsHeap struct {
+	Event struct {
+		Ssl     uint64
+		PidTgid uint64
+		Len     uint32
+		Kind    uint32
+		Data    [16384]uint8
+	}
+	Remaining uint64
+}
+
+// loadTls returns the embedded CollectionSpec for tls.
+func loadTls() (*ebpf.CollectionSpec, error) {
+	reader := bytes.NewReader(_TlsBytes)
+	spec, err := ebpf.LoadCollectionSpecFromReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("can't load tls: %w", err)
+	}
+
+	return spec, err
+}
+
+// loadTlsObjects loads tls and converts it into a struct.
+//
+// The following types are suitable as obj argument:
+//
+//	*tlsObjects
+//	*tlsPrograms
+//	*tlsMaps
+//
+// See ebpf.CollectionSpec.LoadAndAssign documentation for details.
+func loadTlsObjects(obj interface{}, opts *ebpf.CollectionOptions) error {
+	spec, err := loadTls()
+	if err != nil {
+		return err
+	}
+
+	return spec.LoadAndAssign(obj, opts)
+}
+
+// tlsSpecs contains maps and programs before they are loaded into the kernel.
+//
+// It can be passed ebpf.CollectionSpec.Assign.
+type tlsSpecs struct {
+	tlsProgramSpecs
+	tlsMapSpecs
+}
+
+// tlsSpecs contains programs before they are loaded into the kernel.
+//
+// It can be passed ebpf.CollectionSpec.Assign.
+type tlsProgramSpecs struct {
+	TlsEntry      *ebpf.ProgramSpec `ebpf:"tls_entry"`
+	TlsFree       *ebpf.ProgramSpec `ebpf:"tls_free"`
+	TlsReadRet    *ebpf.ProgramSpec `ebpf:"tls_read_ret"`
+	TlsReadRetEx  *ebpf.ProgramSpec `ebpf:"tls_read_ret_ex"`
+	TlsWriteRet   *ebpf.ProgramSpec `ebpf:"tls_write_ret"`
+	TlsWriteRetEx *ebpf.ProgramSpec `ebpf:"tls_write_ret_ex"`
+}
+
+// tlsMapSpecs contains maps before they are loaded into the kernel.
+//
+// It can be passed ebpf.CollectionSpec.Assign.
+type tlsMapSpecs struct {
+	TlsArgs   *ebpf.MapSpec `ebpf:"tls_args"`
+	TlsEvents *ebpf.MapSpec `ebpf:"tls_events"`
+	TlsHeap   *ebpf.MapSpec `ebpf:"tls_heap"`
+}
+
+// tlsObjects contains all objects after they have been loaded into the kernel.
+//
+// It can be passed to loadTlsObjects or ebpf.CollectionSpec.LoadAndAssign.
+type tlsObjects struct {
+	tlsPrograms
+	tlsMaps
+}
+
+func (o *tlsObjects) Close() error {
+	return _TlsClose(
+		&o.tlsPrograms,
+		&o.tlsMaps,
+	)
+}
+
+// tlsMaps contains all maps after they have been loaded into the kernel.
+//
+// It can be passed to loadTlsObjects or ebpf.CollectionSpec.LoadAndAssign.
+type tlsMaps struct {
+	TlsArgs   *ebpf.Map `ebpf:"tls_args"`
+	TlsEvents *ebpf.Map `ebpf:"tls_events"`
+	TlsHeap   *ebpf.Map `ebpf:"tls_heap"`
+}
+
+func (m *tlsMaps) Close() error {
+	return _TlsClose(
+		m.TlsArgs,
+		m.TlsEvents,
+		m.TlsHeap,
+	)
+}
+
+// tlsPrograms contains all programs after they have been loaded into the kernel.
+//
+// It can be passed to loadTlsObjects or ebpf.CollectionSpec.LoadAndAssign.
+type tlsPrograms struct {
+	TlsEntry      *ebpf.Program `ebpf:"tls_entry"`
+	TlsFree       *ebpf.Program `ebpf:"tls_free"`
+	TlsReadRet    *ebpf.Program `ebpf:"tls_read_ret"`
+	TlsReadRetEx  *ebpf.Program `ebpf:"tls_read_ret_ex"`
+	TlsWriteRet   *ebpf.Program `ebpf:"tls_write_ret"`
+	TlsWriteRetEx *ebpf.Program `ebpf:"tls_write_ret_ex"`
+}
+
+func (p *tlsPrograms) Close() error {
+	return _TlsClose(
+		p.TlsEntry,
+		p.TlsFree,
+		p.TlsReadRet,
+		p.TlsReadRetEx,
+		p.TlsWriteRet,
+		p.TlsWriteRetEx,
+	)
+}
+
+func _TlsClose(closers ...io.Closer) error {
+	for _, closer := range closers {
+		if err := closer.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Do not access this directly.
+//
+//go:embed tls_arm64_bpfel.o
+var _TlsBytes []byte
@@ -0,0 +1,223 @@
+package hooks
+
+import (
+	"bufio"
+	"debug/elf"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// tlsLibrarySearchDirs are where the libraries needed by the interpreters are found when they have no runpath.
+var tlsLibrarySearchDirs = []string{
+	"/lib/*-linux-gnu",
+	"/usr/lib/*-linux-gnu",
+	"/lib64",
+	"/usr/lib64",
+	"/usr/lib",
+	"/usr/local/lib",
+}
+
+// tlsRuntimeLibraries returns the TLS libraries of the interpreter running the command of the app, which often
+// isn't the libssl of the distribution: node links OpenSSL statically, and the pythons of pyenv, conda or built
+// from source bring their own libssl, or link it statically into their _ssl module or libpython.
+func tlsRuntimeLibraries(command string) []string {
+	var candidates []string
+	for _, exe := range commandExecutables(command) {
+		candidates = append(candidates, exe)
+		if strings.HasPrefix(filepath.Base(exe), "python") {
+			candidates = append(candidates, pythonSSLModules(exe)...)
+		}
+	}
+	var libs []string
+	for _, c := range candidates {
+		libs = append(libs, elfTLSLibraries(c, true)...)
+	}
+	return libs
+}
+
+// commandExecutables returns the binaries the command may run, which are the interpreters of all the versions
+// installed by pyenv or nodenv if it runs one of their shims.
+func commandExecutables(command string) []string {
+	exe := commandExecutable(command)
+	if exe == "" {
+		return nil
+	}
+	shims := filepath.Dir(exe)
+	if filepath.Base(shims) != "shims" {
+		return []string{exe}
+	}
+	// the shims are shell scripts running the version selected by the environment or the .python-version file
+	versions, _ := filepath.Glob(filepath.Join(filepath.Dir(shims), "versions", "*", "bin", filepath.Base(exe)))
+	var exes []string
+	for _, v := range versions {
+		if resolved, err := filepath.EvalSymlinks(v); err == nil {
+			exes = append(exes, resolved)
+		}
+	}
+	return exes
+}
+
+// commandExecutable returns the binary run by the command, following the shebang of the scripts e.g. of npm or
+// gunicorn to their interpreter.
+func commandExecutable(command string) string {
+	var name string
+	for _, field := range strings.Fields(command) {
+		// the environment variables set before the command
+		if strings.Contains(field, "=") || field == "exec" {
+			continue
+		}
+		name = field
+		break
+	}
+	if name == "" {
+		return ""
+	}
+	exe, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return ""
+	}
+	if filepath.Base(filepath.Dir(exe)) == "shims" {
+		return exe
+	}
+	if interpreter := shebangInterpreter(exe); interpreter != "" {
+		return interpreter
+	}
+	return exe
+}
+
+// shebangInterpreter returns the interpreter of the script e.g. node for `#!/usr/bin/env node`, or "" if the file
+// isn't a script.
+func shebangInterpreter(path string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && line == "" {
+		return ""
+	}
+	fields := strings.Fields(strings.TrimPrefix(line, "#!"))
+	if !strings.HasPrefix(line, "#!") || len(fields) == 0 {
+		return ""
+	}
+	name := fields[0]
+	if filepath.Base(name) == "env" {
+		name = ""
+		for _, arg := range fields[1:] {
+			if !strings.HasPrefix(arg, "-") && !strings.Contains(arg, "=") {
+				name = arg
+				break
+			}
+		}
+		if name == "" {
+			return ""
+		}
+	}
+	exe, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+	exe, err = filepath.EvalSymlinks(exe)
+	if err != nil {
+		return ""
+	}
+	return exe
+}
+
+// pythonSSLModules returns the _ssl extension modules of the python installed in the prefix of the interpreter.
+func pythonSSLModules(exe string) []string {
+	prefix := filepath.Dir(filepath.Dir(exe))
+	var modules []string
+	for _, lib := range []string{"lib", "lib64"} {
+		matches, _ := filepath.Glob(filepath.Join(prefix, lib, "python3*", "lib-dynload", "_ssl*.so"))
+		modules = append(modules, matches...)
+	}
+	return modules
+}
+
+// elfTLSLibraries returns the binary if it defines SSL_read and SSL_write, i.e. links OpenSSL or BoringSSL
+// statically, and the libssl it needs. The libpython and libnode it needs are searched too if deep is set.
+func elfTLSLibraries(path string, deep bool) []string {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var libs []string
+	if definesTLS(f) {
+		libs = append(libs, path)
+	}
+	needed, err := f.ImportedLibraries()
+	if err != nil {
+		return libs
+	}
+	for _, name := range needed {
+		switch {
+		case strings.HasPrefix(name, "libssl"):
+			if lib := findNeededLibrary(f, path, name); lib != "" {
+				libs = append(libs, lib)
+			}
+		case deep && (strings.HasPrefix(name, "libpython") || strings.HasPrefix(name, "libnode")):
+			if lib := findNeededLibrary(f, path, name); lib != "" {
+				libs = append(libs, elfTLSLibraries(lib, false)...)
+			}
+		}
+	}
+	return libs
+}
+
+// definesTLS reports whether the binary defines SSL_read and SSL_write, rather than importing them.
+func definesTLS(f *elf.File) bool {
+	found := map[string]bool{}
+	check := func(syms []elf.Symbol) {
+		for _, s := range syms {
+			if (s.Name == "SSL_read" || s.Name == "SSL_write") && s.Section != elf.SHN_UNDEF && s.Value != 0 {
+				found[s.Name] = true
+			}
+		}
+	}
+	// the stripped binaries only have the dynamic symbols, node exports OpenSSL there for its addons
+	if syms, err := f.DynamicSymbols(); err == nil {
+		check(syms)
+	}
+	if syms, err := f.Symbols(); err == nil {
+		check(syms)
+	}
+	return found["SSL_read"] && found["SSL_write"]
+}
+
+// findNeededLibrary finds the library needed by the binary at path in its runpath, next to it, or in the
+// directories of the system.
+func findNeededLibrary(f *elf.File, path, name string) string {
+	origin := filepath.Dir(path)
+	var dirs []string
+	for _, tag := range []elf.DynTag{elf.DT_RUNPATH, elf.DT_RPATH} {
+		values, _ := f.DynString(tag)
+		for _, v := range values {
+			for _, dir := range strings.Split(v, ":") {
+				dir = strings.ReplaceAll(dir, "${ORIGIN}", origin)
+				dirs = append(dirs, strings.ReplaceAll(dir, "$ORIGIN", origin))
+			}
+		}
+	}
+	dirs = append(dirs, origin, filepath.Join(origin, "..", "lib"))
+	for _, pattern := range tlsLibrarySearchDirs {
+		matches, _ := filepath.Glob(pattern)
+		dirs = append(dirs, matches...)
+	}
+	for _, dir := range dirs {
+		lib := filepath.Join(dir, name)
+		if _, err := os.Stat(lib); err == nil {
+			return lib
+		}
+	}
+	return ""
+}
@@ -6,9 +6,7 @@ import (
 	"errors"
 	"net"
 	"os"
-	"path/filepath"
 	"strings"
-	"syscall"
 
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
@@ -27,6 +25,23 @@ func IPv4ToUint32(ipStr string) (uint32, error) {
 	return 0, errors.New("failed to parse IP address")
 }
 
+// IPv6ToUint32 converts a string representation of an IPv6 address to the 32-bit integers of its four groups of
+// bytes, the first byte being the most significant one, e.g. [0, 0, 0, 1] for ::1.
+func IPv6ToUint32(ipStr string) ([4]uint32, error) {
+	var ip6 [4]uint32
+	ipAddr := net.ParseIP(ipStr)
+	if ipAddr == nil {
+		return ip6, errors.New("failed to parse IP address")
+	}
+	if ipAddr.To4() != nil {
+		return ip6, errors.New("not a valid IPv6 address")
+	}
+	for i := range ip6 {
+		ip6[i] = binary.BigEndian.Uint32(ipAddr[i*4 : i*4+4])
+	}
+	return ip6, nil
+}
+
 // detectCgroupPath returns the first-found mount point of type cgroup2
 // and stores it in the cgroupPath global variable.
 func detectCgroupPath(logger *zap.Logger) (string, error) {
@@ -52,18 +67,3 @@ func detectCgroupPath(logger *zap.Logger) (string, error) {
 
 	return "", errors.New("cgroup2 not mounted")
 }
-
-func getSelfInodeNumber() (uint64, error) {
-	p := filepath.Join("/proc", "self", "ns", "pid")
-
-	f, err := os.Stat(p)
-	if err != nil {
-		return 0, errors.New("failed to get inode of the keploy process")
-	}
-	// Dev := (f.Sys().(*syscall.Stat_t)).Dev
-	Ino := (f.Sys().(*syscall.Stat_t)).Ino
-	if Ino != 0 {
-		return Ino, nil
-	}
-	return 0, nil
-}
@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"sort"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// destination is where a connection of the app was sent, it is recorded in the metadata of the mocks of the
+// connection so that the destinations of a host can be compared between record and test.
+type destination struct {
+	addr string
+	// sni is the server name of the TLS connection, empty if the connection is not TLS
+	sni string
+}
+
+// auditMocks returns the channel the parsers send the mocks of the connection to, which adds the destination to
+// their metadata and forwards them to mocks. The returned func is called once the parsers are done.
+func (p *Proxy) auditMocks(mocks chan<- *models.Mock, dest *destination) (chan<- *models.Mock, func()) {
+	audited := make(chan *models.Mock)
+	done := make(chan struct{})
+	go func() {
+		defer utils.Recover(p.logger)
+		defer close(done)
+		for mock := range audited {
+			if mock.Spec.Metadata == nil {
+				mock.Spec.Metadata = map[string]string{}
+			}
+			mock.Spec.Metadata[models.DestinationAddrKey] = dest.addr
+			if dest.sni != "" {
+				mock.Spec.Metadata[models.SNIKey] = dest.sni
+			}
+			mocks <- mock
+		}
+	}()
+	return audited, func() {
+		close(audited)
+		<-done
+	}
+}
+
+// mockHost returns the host the mock was recorded for, the server name of its TLS connection or the Host header of
+// its http request.
+func mockHost(mock *models.Mock) string {
+	if sni := mock.Spec.Metadata[models.SNIKey]; sni != "" {
+		return sni
+	}
+	return mock.Spec.Metadata["host"]
+}
+
+// httpHost returns the Host header of the http request in buf, empty if buf isn't an http request.
+func httpHost(buf []byte) string {
+	req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(buf)))
+	if err != nil {
+		return ""
+	}
+	return req.Host
+}
+
+// destinationAudit is the destinations the hosts of the mocks were recorded with, the connections of the app to
+// the hosts are checked against them in test mode.
+type destinationAudit struct {
+	m        sync.Mutex
+	recorded map[string]map[string]bool
+	warned   map[string]bool
+}
+
+func newDestinationAudit() *destinationAudit {
+	return &destinationAudit{
+		recorded: map[string]map[string]bool{},
+		warned:   map[string]bool{},
+	}
+}
+
+// reset sets the destinations of the hosts of the mocks, the mocks recorded without their destination are skipped.
+func (a *destinationAudit) reset(mocks []*models.Mock) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	a.recorded = map[string]map[string]bool{}
+	for _, mock := range mocks {
+		host, addr := mockHost(mock), mock.Spec.Metadata[models.DestinationAddrKey]
+		if host == "" || addr == "" {
+			continue
+		}
+		if a.recorded[host] == nil {
+			a.recorded[host] = map[string]bool{}
+		}
+		a.recorded[host][addr] = true
+	}
+}
+
+// check warns once if the connection to the host is sent to a destination the host wasn't recorded with, e.g. if
+// the host resolved to another address or the virtual host is served by another server.
+func (a *destinationAudit) check(logger *zap.Logger, host, addr string) {
+	a.m.Lock()
+	defer a.m.Unlock()
+	recorded, ok := a.recorded[host]
+	if host == "" || !ok || recorded[addr] || a.warned[host+" "+addr] {
+		return
+	}
+	a.warned[host+" "+addr] = true
+	addrs := make([]string, 0, len(recorded))
+	for recordedAddr := range recorded {
+		addrs = append(addrs, recordedAddr)
+	}
+	sort.Strings(addrs)
+	logger.Warn("the host is reached at another destination than during record, its mocks may not match", zap.String("host", host), zap.String("destination", addr), zap.Strings("recorded destinations", addrs))
+}
@@ -0,0 +1,119 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// hostBreaker bounds the connections recorded at once to each destination, and passes through the connections to
+// a destination for a cooldown after its consecutive failures, so that a slow or failing dependency doesn't stall
+// the recording of the others.
+type hostBreaker struct {
+	maxConns int
+	failures int
+	cooldown time.Duration
+
+	m     sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	conns       int
+	consecutive int
+	openUntil   time.Time
+	// trips is the number of times the connections to the destination were switched to passthrough, limited the
+	// number of connections passed through over maxConns
+	trips   int
+	limited int
+}
+
+func newHostBreaker(cfg config.Record) *hostBreaker {
+	return &hostBreaker{
+		maxConns: cfg.MaxConnsPerHost,
+		failures: cfg.CircuitBreaker.Failures,
+		cooldown: cfg.CircuitBreaker.Cooldown,
+		hosts:    map[string]*hostState{},
+	}
+}
+
+// acquire returns false if the connection to the destination is to be passed through instead of recorded, as the
+// destination failed or has maxConns connections being recorded. Otherwise release is called once it is recorded.
+func (b *hostBreaker) acquire(host string) bool {
+	b.m.Lock()
+	defer b.m.Unlock()
+	s, ok := b.hosts[host]
+	if !ok {
+		s = &hostState{}
+		b.hosts[host] = s
+	}
+	if time.Now().Before(s.openUntil) {
+		return false
+	}
+	if b.maxConns > 0 && s.conns >= b.maxConns {
+		s.limited++
+		return false
+	}
+	s.conns++
+	return true
+}
+
+// release counts the outcome of the recording of the connection to the destination. The connections to the
+// destination are passed through for the cooldown once it failed the configured times in a row, and again after
+// the first failure following the cooldown. Only the failures of the destination are counted.
+func (b *hostBreaker) release(logger *zap.Logger, host string, err error) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	s := b.hosts[host]
+	s.conns--
+	if err == nil || errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+		s.consecutive = 0
+		return
+	}
+	if !upstreamFailure(err) {
+		return
+	}
+	s.consecutive++
+	if b.failures > 0 && s.consecutive >= b.failures {
+		s.openUntil = time.Now().Add(b.cooldown)
+		s.trips++
+		utils.LogError(logger, err, "the destination keeps failing, its calls are passed through WITHOUT being recorded for the cooldown, so the test run will miss their mocks", zap.String("destination", host), zap.Int("consecutive failures", s.consecutive), zap.Duration("cooldown", b.cooldown))
+	}
+}
+
+// upstreamFailure reports whether err is a failure of the destination, i.e. it couldn't be dialed or timed out,
+// and not of the application, e.g. a client which reset the conn.
+func upstreamFailure(err error) bool {
+	var proxyErr models.ProxyError
+	if errors.As(err, &proxyErr) {
+		return proxyErr.ProxyErrorType == models.ErrUpstreamTimeout
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// summary logs the destinations whose connections were passed through instead of recorded.
+func (b *hostBreaker) summary(logger *zap.Logger) {
+	b.m.Lock()
+	defer b.m.Unlock()
+	hosts := make([]string, 0, len(b.hosts))
+	for host, s := range b.hosts {
+		if s.trips > 0 || s.limited > 0 {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		s := b.hosts[host]
+		utils.LogError(logger, nil, "some calls to the destination were passed through without being recorded", zap.String("destination", host), zap.Int("times failing", s.trips), zap.Int("calls over maxConnsPerHost", s.limited))
+	}
+}
@@ -223,7 +223,12 @@ func SetupCA(ctx context.Context, logger *zap.Logger) error {
 		utils.LogError(logger, err, "Failed to update the CA store")
 		return err
 	}
+	return setCAEnv(logger)
+}
 
+// setCAEnv passes the CA to the node and python apps through their environment, the proxy mode only passes it this
+// way as the CA store of the system isn't updated outside linux.
+func setCAEnv(logger *zap.Logger) error {
 	tempCertPath, err := extractCertToTemp()
 	if err != nil {
 		utils.LogError(logger, err, "Failed to extract certificate to tmp folder")
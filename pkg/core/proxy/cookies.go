@@ -0,0 +1,41 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"sync"
+)
+
+// cookieJar holds the cookies set by the http mock responses of a testcase, so that the later calls of the app
+// with them, e.g. after a login, match the mocks recorded with them. It's reset with the mocks of each testcase.
+type cookieJar struct {
+	m   sync.Mutex
+	jar *cookiejar.Jar
+}
+
+func newCookieJar() *cookieJar {
+	j := &cookieJar{}
+	j.reset()
+	return j
+}
+
+func (j *cookieJar) reset() {
+	// the jar without the public suffix list never fails
+	jar, _ := cookiejar.New(nil)
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.jar = jar
+}
+
+func (j *cookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.jar.SetCookies(u, cookies)
+}
+
+func (j *cookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.m.Lock()
+	defer j.m.Unlock()
+	return j.jar.Cookies(u)
+}
@@ -90,11 +90,14 @@ func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 		if !found {
 			// If not found in cache, resolve the DNS query only in case of record mode
 			//TODO: Add support for passThrough here using the src<->dst mapping
+			resolved := false
 			if models.GetMode() == models.MODE_RECORD {
-				answers = resolveDNSQuery(p.logger, question.Name)
+				answers, resolved = resolveDNSQuery(p.logger, question.Name, question.Qtype)
 			}
 
-			if len(answers) == 0 {
+			// a name resolved without addresses of the type, e.g. with only IPv4 addresses for an AAAA query, gets an
+			// empty answer rather than the proxy, so that the app connects to its addresses of the other type
+			if len(answers) == 0 && !resolved {
 				// If the resolution failed, return a default A record with Proxy IP
 				if question.Qtype == dns.TypeA {
 					answers = []dns.RR{&dns.A{
@@ -102,7 +105,7 @@ func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 						A:   net.ParseIP(p.IP4),
 					}}
 					p.logger.Debug("failed to resolve dns query hence sending proxy ip4", zap.Any("proxy Ip", p.IP4))
-				} else if question.Qtype == dns.TypeAAAA {
+				} else if question.Qtype == dns.TypeAAAA && p.IP6 != "" {
 					answers = []dns.RR{&dns.AAAA{
 						Hdr:  dns.RR_Header{Name: question.Name, Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 3600},
 						AAAA: net.ParseIP(p.IP6),
@@ -135,8 +138,10 @@ func (p *Proxy) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
 	}
 }
 
+// resolveDNSQuery returns the addresses of the domain of the type of the query, A or AAAA, and whether the domain
+// was resolved at all.
 // TODO: passThrough the dns queries rather than resolving them.
-func resolveDNSQuery(logger *zap.Logger, domain string) []dns.RR {
+func resolveDNSQuery(logger *zap.Logger, domain string, qtype uint16) ([]dns.RR, bool) {
 	// Remove the last dot from the domain name if it exists
 	domain = strings.TrimSuffix(domain, ".")
 
@@ -147,18 +152,21 @@ func resolveDNSQuery(logger *zap.Logger, domain string) []dns.RR {
 	ips, err := resolver.LookupIPAddr(context.Background(), domain)
 	if err != nil {
 		logger.Debug(fmt.Sprintf("failed to resolve the dns query for:%v", domain), zap.Error(err))
-		return nil
+		return nil, false
 	}
 
-	// Convert the resolved IPs to dns.RR
+	// Convert the resolved IPs of the type of the query to dns.RR
 	var answers []dns.RR
 	for _, ip := range ips {
 		if ipv4 := ip.IP.To4(); ipv4 != nil {
+			if qtype != dns.TypeA {
+				continue
+			}
 			answers = append(answers, &dns.A{
 				Hdr: dns.RR_Header{Name: dns.Fqdn(domain), Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 3600},
 				A:   ipv4,
 			})
-		} else {
+		} else if qtype == dns.TypeAAAA {
 			answers = append(answers, &dns.AAAA{
 				Hdr:  dns.RR_Header{Name: dns.Fqdn(domain), Rrtype: dns.TypeAAAA, Class: dns.ClassINET, Ttl: 3600},
 				AAAA: ip.IP,
@@ -170,7 +178,7 @@ func resolveDNSQuery(logger *zap.Logger, domain string) []dns.RR {
 		logger.Debug("net.LookupIP resolved the ip address...")
 	}
 
-	return answers
+	return answers, true
 }
 
 func (p *Proxy) stopDNSServers(_ context.Context) error {
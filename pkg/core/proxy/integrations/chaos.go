@@ -0,0 +1,58 @@
+package integrations
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// InjectFault decides, as per the chaos options, whether the response of the matched mock is to be replaced
+// with one of the faults supported by the integration. The injected fault is flagged on the mock db so that
+// it is reported with the result of the testcase.
+func InjectFault(logger *zap.Logger, mock *models.Mock, mockDb MockMemDb, opts models.OutgoingOptions, supported ...models.FaultKind) (models.FaultKind, bool) {
+	fault, ok := opts.Chaos.Pick(supported...)
+	if !ok || mock == nil {
+		return "", false
+	}
+	logger.Debug("injecting a fault in place of the mock response", zap.Any("mock", mock.Name), zap.Any("fault", fault))
+	mockDb.FlagMockAsFaulted(mock, fault)
+	return fault, true
+}
+
+// ResetConn closes the conn with a TCP RST instead of the graceful FIN, so the application sees a connection reset.
+func ResetConn(logger *zap.Logger, conn net.Conn) {
+	if tcpConn, ok := conn.(*net.TCPConn); ok {
+		err := tcpConn.SetLinger(0)
+		if err != nil {
+			utils.LogError(logger, err, "failed to set linger on the conn to reset it")
+		}
+	}
+	err := conn.Close()
+	if err != nil {
+		utils.LogError(logger, err, "failed to close the conn to reset it")
+	}
+}
+
+// HoldConn withholds the response of the timeout fault. Nothing is written to the conn until the application closes
+// it on its timeout, or the context is done.
+func HoldConn(ctx context.Context, logger *zap.Logger, conn net.Conn) {
+	closed := make(chan struct{})
+	go func() {
+		defer utils.Recover(logger)
+		// the requests sent meanwhile are discarded, the read fails once the application closes the conn
+		_, _ = io.Copy(io.Discard, conn)
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-ctx.Done():
+	}
+	err := conn.Close()
+	if err != nil {
+		logger.Debug("failed to close the conn held for the timeout fault", zap.Error(err))
+	}
+}
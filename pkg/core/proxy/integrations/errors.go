@@ -0,0 +1,38 @@
+package integrations
+
+import (
+	"errors"
+	"io"
+	"net"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// UpstreamError types the error of reading from the destination server, so that a timeout is reported as
+// models.ErrUpstreamTimeout instead of a failure of the application.
+func UpstreamError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return models.ProxyError{ProxyErrorType: models.ErrUpstreamTimeout, Err: err}
+	}
+	return err
+}
+
+// ClientError types the error of reading from the application, so that a conn closed in the middle of a
+// message is reported as models.ErrClientClosed.
+func ClientError(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
+		return models.ProxyError{ProxyErrorType: models.ErrClientClosed, Err: err}
+	}
+	return err
+}
+
+// MissingMockError types the error of an outgoing call which didn't match any mock as models.ErrMockMissing.
+func MissingMockError(err error) error {
+	return models.ProxyError{ProxyErrorType: models.ErrMockMissing, Err: err}
+}
+
+// ParseError types the error of parsing an outgoing message as models.ErrParse.
+func ParseError(err error) error {
+	return models.ProxyError{ProxyErrorType: models.ErrParse, Err: err}
+}
@@ -3,6 +3,7 @@ package generic
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"net"
 	"time"
@@ -15,7 +16,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	genericRequests := [][]byte{reqBuf}
 	logger.Debug("Into the generic parser in test mode")
 	errCh := make(chan error, 1)
@@ -36,6 +37,7 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 				buffer, err := pUtil.ReadBytes(ctx, logger, clientConn)
 				if netErr, ok := err.(net.Error); !(ok && netErr.Timeout()) && err != nil && err.Error() != "EOF" {
 					utils.LogError(logger, err, "failed to read the request message in proxy for generic dependency")
+					errCh <- integrations.ClientError(err)
 					return
 				}
 				if netErr, ok := err.(net.Error); (ok && netErr.Timeout()) || (err != nil && err.Error() == "EOF") {
@@ -57,6 +59,11 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 				utils.LogError(logger, err, "error while matching generic mocks")
 			}
 
+			if !matched && opts.StrictMocking {
+				errCh <- integrations.MissingMockError(fmt.Errorf("unmatched generic request of %d packets", len(genericRequests)))
+				return
+			}
+
 			if !matched {
 				err := clientConn.SetReadDeadline(time.Time{})
 				if err != nil {
@@ -83,23 +90,8 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 				logger.Debug("the length of genericRequests after passThrough ", zap.Any("length", len(genericRequests)))
 				continue
 			}
-			for _, genericResponse := range genericResponses {
-				encoded := []byte(genericResponse.Message[0].Data)
-				if genericResponse.Message[0].Type != models.String {
-					encoded, err = util.DecodeBase64(genericResponse.Message[0].Data)
-					if err != nil {
-						utils.LogError(logger, err, "failed to decode the base64 response")
-						return
-					}
-				}
-				_, err := clientConn.Write(encoded)
-				if err != nil {
-					if ctx.Err() != nil {
-						return
-					}
-					utils.LogError(logger, err, "failed to write the response message to the client application")
-					return
-				}
+			if writeResponses(ctx, logger, clientConn, genericResponses) != nil {
+				return
 			}
 
 			// Clear the genericRequests buffer for the next dependency call
@@ -118,3 +110,27 @@ func decodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 		return err
 	}
 }
+
+// writeResponses writes the messages of the mock to the client.
+func writeResponses(ctx context.Context, logger *zap.Logger, clientConn net.Conn, genericResponses []models.GenericPayload) error {
+	for _, genericResponse := range genericResponses {
+		encoded := []byte(genericResponse.Message[0].Data)
+		if genericResponse.Message[0].Type != models.String {
+			var err error
+			encoded, err = util.DecodeBase64(genericResponse.Message[0].Data)
+			if err != nil {
+				utils.LogError(logger, err, "failed to decode the base64 response")
+				return integrations.ParseError(err)
+			}
+		}
+		_, err := clientConn.Write(encoded)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			utils.LogError(logger, err, "failed to write the response message to the client application")
+			return integrations.ClientError(err)
+		}
+	}
+	return nil
+}
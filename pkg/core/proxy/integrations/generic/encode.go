@@ -11,6 +11,7 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
@@ -18,7 +19,9 @@ import (
 	"go.uber.org/zap"
 )
 
-func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn, destConn net.Conn, mocks chan<- *models.Mock, _ models.OutgoingOptions) error {
+// encodeGeneric records the messages of the connection, starting with the request in reqBuf. The messages the server
+// sent first, starting with serverBuf which is already forwarded to the client, are saved as a mock without requests.
+func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, serverBuf []byte, clientConn, destConn net.Conn, mocks chan<- *models.Mock, _ models.OutgoingOptions) error {
 
 	var genericRequests []models.GenericPayload
 
@@ -40,18 +43,22 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 			},
 		})
 	}
-	_, err := destConn.Write(reqBuf)
-	if err != nil {
-		utils.LogError(logger, err, "failed to write request message to the destination server")
-		return err
+	if len(reqBuf) > 0 {
+		_, err := destConn.Write(reqBuf)
+		if err != nil {
+			utils.LogError(logger, err, "failed to write request message to the destination server")
+			return integrations.UpstreamError(err)
+		}
 	}
 	var genericResponses []models.GenericPayload
+	if len(serverBuf) > 0 {
+		genericResponses = append(genericResponses, serverPayload(serverBuf))
+	}
 
 	clientBuffChan := make(chan []byte)
 	destBuffChan := make(chan []byte)
-	errChan := make(chan error)
-	//TODO: where to close the error channel since it is used in both the go routines
-	//close(errChan)
+	clientErrChan := make(chan error)
+	destErrChan := make(chan error)
 
 	//get the error group from the context
 	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
@@ -63,14 +70,14 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 	g.Go(func() error {
 		defer pUtil.Recover(logger, clientConn, nil)
 		defer close(clientBuffChan)
-		pUtil.ReadBuffConn(ctx, logger, clientConn, clientBuffChan, errChan)
+		pUtil.ReadBuffConn(ctx, logger, clientConn, clientBuffChan, clientErrChan)
 		return nil
 	})
 	// read responses from destination
 	g.Go(func() error {
 		defer pUtil.Recover(logger, nil, destConn)
 		defer close(destBuffChan)
-		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, errChan)
+		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, destErrChan)
 		return nil
 	})
 
@@ -78,44 +85,49 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 	var reqTimestampMock = time.Now()
 	var resTimestampMock time.Time
 
+	// persist saves the last exchange of the connection once it ends, if it got its responses
+	persist := func() {
+		if !prevChunkWasReq && len(genericResponses) > 0 {
+			genericRequestsCopy := make([]models.GenericPayload, len(genericRequests))
+			genericResponsesCopy := make([]models.GenericPayload, len(genericResponses))
+			copy(genericResponsesCopy, genericResponses)
+			copy(genericRequestsCopy, genericRequests)
+
+			metadata := make(map[string]string)
+			metadata["type"] = "config"
+			// Save the mock
+			mocks <- &models.Mock{
+				Version: models.GetVersion(),
+				Name:    "mocks",
+				Kind:    models.GENERIC,
+				Spec: models.MockSpec{
+					GenericRequests:  genericRequestsCopy,
+					GenericResponses: genericResponsesCopy,
+					ReqTimestampMock: reqTimestampMock,
+					ResTimestampMock: resTimestampMock,
+					Metadata:         metadata,
+				},
+			}
+		}
+	}
+
 	// ticker := time.NewTicker(1 * time.Second)
 	logger.Debug("the iteration for the generic request starts", zap.Any("genericReqs", len(genericRequests)), zap.Any("genericResps", len(genericResponses)))
 	for {
 		select {
 		case <-ctx.Done():
-			if !prevChunkWasReq && len(genericRequests) > 0 && len(genericResponses) > 0 {
-				genericRequestsCopy := make([]models.GenericPayload, len(genericRequests))
-				genericResponsesCopy := make([]models.GenericPayload, len(genericResponses))
-				copy(genericResponsesCopy, genericResponses)
-				copy(genericRequestsCopy, genericRequests)
-
-				metadata := make(map[string]string)
-				metadata["type"] = "config"
-				// Save the mock
-				mocks <- &models.Mock{
-					Version: models.GetVersion(),
-					Name:    "mocks",
-					Kind:    models.GENERIC,
-					Spec: models.MockSpec{
-						GenericRequests:  genericRequestsCopy,
-						GenericResponses: genericResponsesCopy,
-						ReqTimestampMock: reqTimestampMock,
-						ResTimestampMock: resTimestampMock,
-						Metadata:         metadata,
-					},
-				}
-				return ctx.Err()
-			}
+			persist()
+			return ctx.Err()
 		case buffer := <-clientBuffChan:
 			// Write the request message to the destination
 			_, err := destConn.Write(buffer)
 			if err != nil {
 				utils.LogError(logger, err, "failed to write request message to the destination server")
-				return err
+				return integrations.UpstreamError(err)
 			}
 
 			logger.Debug("the iteration for the generic request ends with no of genericReqs:" + strconv.Itoa(len(genericRequests)) + " and genericResps: " + strconv.Itoa(len(genericResponses)))
-			if !prevChunkWasReq && len(genericRequests) > 0 && len(genericResponses) > 0 {
+			if !prevChunkWasReq && len(genericResponses) > 0 {
 				genericRequestsCopy := make([]models.GenericPayload, len(genericRequests))
 				genericResponseCopy := make([]models.GenericPayload, len(genericResponses))
 				copy(genericResponseCopy, genericResponses)
@@ -171,37 +183,48 @@ func encodeGeneric(ctx context.Context, logger *zap.Logger, reqBuf []byte, clien
 			_, err := clientConn.Write(buffer)
 			if err != nil {
 				utils.LogError(logger, err, "failed to write response message to the client")
-				return err
-			}
-
-			bufStr := string(buffer)
-			buffDataType := models.String
-			if !util.IsASCIIPrintable(string(buffer)) {
-				bufStr = base64.StdEncoding.EncodeToString(buffer)
-				buffDataType = "binary"
+				return integrations.ClientError(err)
 			}
 
-			if bufStr != "" {
-				genericResponses = append(genericResponses, models.GenericPayload{
-					Origin: models.FromServer,
-					Message: []models.OutputBinary{
-						{
-							Type: buffDataType,
-							Data: bufStr,
-						},
-					},
-				})
+			if len(buffer) > 0 {
+				genericResponses = append(genericResponses, serverPayload(buffer))
 			}
 
 			resTimestampMock = time.Now()
 
 			logger.Debug("the iteration for the generic response ends with no of genericReqs:" + strconv.Itoa(len(genericRequests)) + " and genericResps: " + strconv.Itoa(len(genericResponses)))
 			prevChunkWasReq = false
-		case err := <-errChan:
+		case err := <-clientErrChan:
 			if err == io.EOF {
+				persist()
 				return nil
 			}
-			return err
+			return integrations.ClientError(err)
+		case err := <-destErrChan:
+			if err == io.EOF {
+				persist()
+				return nil
+			}
+			return integrations.UpstreamError(err)
 		}
 	}
 }
+
+// serverPayload returns the message of the server in buffer.
+func serverPayload(buffer []byte) models.GenericPayload {
+	bufStr := string(buffer)
+	buffDataType := models.String
+	if !util.IsASCIIPrintable(string(buffer)) {
+		bufStr = base64.StdEncoding.EncodeToString(buffer)
+		buffDataType = "binary"
+	}
+	return models.GenericPayload{
+		Origin: models.FromServer,
+		Message: []models.OutputBinary{
+			{
+				Type: buffDataType,
+				Data: bufStr,
+			},
+		},
+	}
+}
@@ -39,7 +39,7 @@ func (g *Generic) RecordOutgoing(ctx context.Context, src net.Conn, dst net.Conn
 		return err
 	}
 
-	err = encodeGeneric(ctx, logger, reqBuf, src, dst, mocks, opts)
+	err = encodeGeneric(ctx, logger, reqBuf, nil, src, dst, mocks, opts)
 	if err != nil {
 		utils.LogError(logger, err, "failed to encode the generic message into the yaml")
 		return err
@@ -63,3 +63,43 @@ func (g *Generic) MockOutgoing(ctx context.Context, src net.Conn, dstCfg *integr
 	}
 	return nil
 }
+
+// RecordUpgraded records the connection after it switched protocols without waiting for the client, the messages the
+// server sends first, starting with serverBuf, are saved as a mock without requests.
+func (g *Generic) RecordUpgraded(ctx context.Context, src net.Conn, dst net.Conn, serverBuf []byte, mocks chan<- *models.Mock, opts models.OutgoingOptions) error {
+	logger := g.logger.With(zap.Any("Client IP Address", src.RemoteAddr().String()), zap.Any("Client ConnectionID", ctx.Value(models.ClientConnectionIDKey).(string)), zap.Any("Destination ConnectionID", ctx.Value(models.DestConnectionIDKey).(string)))
+
+	err := encodeGeneric(ctx, logger, nil, serverBuf, src, dst, mocks, opts)
+	if err != nil {
+		utils.LogError(logger, err, "failed to encode the generic message of the upgraded connection into the yaml")
+		return err
+	}
+	return nil
+}
+
+// MockUpgraded mocks the connection after it switched protocols, the messages of a mock without requests are sent
+// before waiting for the client.
+func (g *Generic) MockUpgraded(ctx context.Context, src net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
+	logger := g.logger.With(zap.Any("Client IP Address", src.RemoteAddr().String()), zap.Any("Client ConnectionID", util.GetNextID()), zap.Any("Destination ConnectionID", util.GetNextID()))
+
+	greeting, err := greetingMatch(ctx, mockDb)
+	if err != nil {
+		utils.LogError(logger, err, "error while matching the generic mocks sent first by the server")
+	}
+	if err := writeResponses(ctx, logger, src, greeting); err != nil {
+		return err
+	}
+
+	reqBuf, err := util.ReadInitialBuf(ctx, logger, src)
+	if err != nil {
+		utils.LogError(logger, err, "failed to read the initial generic message")
+		return err
+	}
+
+	err = decodeGeneric(ctx, logger, reqBuf, src, dstCfg, mockDb, opts)
+	if err != nil {
+		utils.LogError(logger, err, "failed to decode the generic message")
+		return err
+	}
+	return nil
+}
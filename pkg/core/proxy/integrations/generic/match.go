@@ -10,6 +10,7 @@ import (
 
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 )
 
 // fuzzyMatch performs a fuzzy matching algorithm to find the best matching mock for the given request.
@@ -18,7 +19,10 @@ import (
 // If a match is found, it returns the corresponding response mock and a boolean value indicating success.
 // If no match is found, it returns false and a nil response.
 // If an error occurs during the matching process, it returns an error.
-func fuzzyMatch(ctx context.Context, reqBuff [][]byte, mockDb integrations.MockMemDb) (bool, []models.GenericPayload, error) {
+func fuzzyMatch(ctx context.Context, reqBuff [][]byte, mockDb integrations.MockMemDb) (matched bool, resp []models.GenericPayload, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.GENERIC)
+	defer func() { tracing.EndMockMatching(span, matched, err) }()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -33,6 +37,10 @@ func fuzzyMatch(ctx context.Context, reqBuff [][]byte, mockDb integrations.MockM
 			var unfilteredMocks []*models.Mock
 
 			for _, mock := range mocks {
+				// the mocks of the UDP datagrams are matched by the UDP relay of the proxy
+				if mock.Spec.Metadata["type"] == "udp" {
+					continue
+				}
 				if mock.TestModeInfo.IsFiltered {
 					filteredMocks = append(filteredMocks, mock)
 				} else {
@@ -89,6 +97,45 @@ func fuzzyMatch(ctx context.Context, reqBuff [][]byte, mockDb integrations.MockM
 	}
 }
 
+// greetingMatch returns the messages of a generic mock the server sent first, without requests, those of the
+// testcase first. The mock is consumed like the ones matched by the requests.
+func greetingMatch(ctx context.Context, mockDb integrations.MockMemDb) ([]models.GenericPayload, error) {
+	for ctx.Err() == nil {
+		mocks, err := mockDb.GetUnFilteredMocks()
+		if err != nil {
+			return nil, fmt.Errorf("error while getting unfiltered mocks %v", err)
+		}
+		var greeting *models.Mock
+		for _, mock := range mocks {
+			if mock.Kind != models.GENERIC || len(mock.Spec.GenericRequests) > 0 || len(mock.Spec.GenericResponses) == 0 {
+				continue
+			}
+			if mock.TestModeInfo.IsFiltered {
+				greeting = mock
+				break
+			}
+			if greeting == nil {
+				greeting = mock
+			}
+		}
+		if greeting == nil {
+			return nil, nil
+		}
+		responses := make([]models.GenericPayload, len(greeting.Spec.GenericResponses))
+		copy(responses, greeting.Spec.GenericResponses)
+		if greeting.TestModeInfo.IsFiltered {
+			original := *greeting
+			greeting.TestModeInfo.IsFiltered = false
+			greeting.TestModeInfo.SortOrder = math.MaxInt64
+			if !mockDb.UpdateUnFilteredMock(&original, greeting) {
+				continue
+			}
+		}
+		return responses, nil
+	}
+	return nil, ctx.Err()
+}
+
 // TODO: need to generalize this function for different types of integrations.
 func findBinaryMatch(tcsMocks []*models.Mock, reqBuffs [][]byte, mxSim float64) int {
 	// TODO: need find a proper similarity index to set a benchmark for matching or need to find another way to do approximate matching
@@ -106,6 +106,7 @@ func transferFrame(ctx context.Context, lhs net.Conn, rhs net.Conn, sic *StreamI
 
 					sic.AddPayloadForResponse(dataFrame.StreamID, dataFrame.Data())
 				}
+				sic.PersistReflectionMocks(ctx, dataFrame.StreamID, mocks)
 			case *http2.PingFrame:
 				pingFrame := frame
 				err := framer.WritePing(pingFrame.IsAck(), pingFrame.Data)
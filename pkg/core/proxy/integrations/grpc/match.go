@@ -8,6 +8,7 @@ import (
 	"go.uber.org/zap"
 
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 )
 
 // constants for the pseudo headers.
@@ -30,7 +31,10 @@ func FilterMocksRelatedToGrpc(mocks []*models.Mock) []*models.Mock {
 	return res
 }
 
-func FilterMocksBasedOnGrpcRequest(ctx context.Context, _ *zap.Logger, grpcReq models.GrpcReq, mockDb integrations.MockMemDb) (*models.Mock, error) {
+func FilterMocksBasedOnGrpcRequest(ctx context.Context, _ *zap.Logger, grpcReq models.GrpcReq, mockDb integrations.MockMemDb) (mock *models.Mock, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.GRPC_EXPORT)
+	defer func() { tracing.EndMockMatching(span, mock != nil, err) }()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -49,33 +53,7 @@ func FilterMocksBasedOnGrpcRequest(ctx context.Context, _ *zap.Logger, grpcReq m
 				if ctx.Err() != nil {
 					return nil, ctx.Err()
 				}
-				have := mock.Spec.GRPCReq
-				// Investigate pseudo headers.
-				if have.Headers.PseudoHeaders[KLabelForAuthority] != grpcReq.Headers.PseudoHeaders[KLabelForAuthority] {
-					continue
-				}
-				if have.Headers.PseudoHeaders[KLabelForMethod] != grpcReq.Headers.PseudoHeaders[KLabelForMethod] {
-					continue
-				}
-				if have.Headers.PseudoHeaders[KLabelForPath] != grpcReq.Headers.PseudoHeaders[KLabelForPath] {
-					continue
-				}
-				if have.Headers.PseudoHeaders[KLabelForScheme] != grpcReq.Headers.PseudoHeaders[KLabelForScheme] {
-					continue
-				}
-
-				// Investigate ordinary headers.
-				if have.Headers.OrdinaryHeaders[KLabelForContentType] != grpcReq.Headers.OrdinaryHeaders[KLabelForContentType] {
-					continue
-				}
-
-				// Investigate the compression flag.
-				if have.Body.CompressionFlag != grpcReq.Body.CompressionFlag {
-					continue
-				}
-
-				// Investigate the body.
-				if have.Body.DecodedData != grpcReq.Body.DecodedData {
+				if !requestMatches(mock.Spec.GRPCReq, grpcReq) {
 					continue
 				}
 
@@ -95,3 +73,52 @@ func FilterMocksBasedOnGrpcRequest(ctx context.Context, _ *zap.Logger, grpcReq m
 		}
 	}
 }
+
+// MatchReflectionMock returns the mock of the server reflection request. The reflection responses are the same for
+// every probe of the tools, so the mocks are looked up in all the mocks of the test set and are not consumed.
+func MatchReflectionMock(ctx context.Context, logger *zap.Logger, grpcReq models.GrpcReq, mockDb integrations.MockMemDb) (mock *models.Mock, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.GRPC_EXPORT)
+	defer func() { tracing.EndMockMatching(span, mock != nil, err) }()
+
+	filtered, err := mockDb.GetFilteredMocks()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting tsc mocks %v", err)
+	}
+	unfiltered, err := mockDb.GetUnFilteredMocks()
+	if err != nil {
+		return nil, fmt.Errorf("error while getting config mocks %v", err)
+	}
+	grpcMocks := append(FilterMocksRelatedToGrpc(filtered), FilterMocksRelatedToGrpc(unfiltered)...)
+	for _, mock := range grpcMocks {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if !requestMatches(mock.Spec.GRPCReq, grpcReq) {
+			continue
+		}
+		if err := mockDb.FlagMockAsUsed(mock); err != nil {
+			logger.Debug("failed to flag the reflection mock as used", zap.String("mock", mock.Name), zap.Error(err))
+		}
+		return mock, nil
+	}
+	return nil, nil
+}
+
+// requestMatches reports whether the recorded request of the mock is the grpc request, by its pseudo headers, its
+// content type and its message.
+func requestMatches(have *models.GrpcReq, grpcReq models.GrpcReq) bool {
+	// Investigate pseudo headers.
+	for _, key := range []string{KLabelForAuthority, KLabelForMethod, KLabelForPath, KLabelForScheme} {
+		if have.Headers.PseudoHeaders[key] != grpcReq.Headers.PseudoHeaders[key] {
+			return false
+		}
+	}
+
+	// Investigate ordinary headers.
+	if have.Headers.OrdinaryHeaders[KLabelForContentType] != grpcReq.Headers.OrdinaryHeaders[KLabelForContentType] {
+		return false
+	}
+
+	// Investigate the compression flag and the body.
+	return have.Body.CompressionFlag == grpcReq.Body.CompressionFlag && have.Body.DecodedData == grpcReq.Body.DecodedData
+}
@@ -3,11 +3,10 @@ package grpc
 import (
 	"context"
 	"encoding/binary"
-	"fmt"
-	"github.com/protocolbuffers/protoscope"
 	"sync"
 	"time"
 
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 )
 
@@ -17,16 +16,50 @@ import (
 type StreamInfoCollection struct {
 	mutex            sync.Mutex
 	StreamInfo       map[uint32]models.GrpcStream
+	messages         map[uint32]*streamMessages
 	ReqTimestampMock time.Time
 	ResTimestampMock time.Time
 }
 
+// streamMessages are the length prefixed messages of a stream, which can span several DATA frames. The partial
+// message of each direction is buffered until its DATA frames are all received.
+type streamMessages struct {
+	reqBuf  []byte
+	respBuf []byte
+	reqs    []models.GrpcLengthPrefixedMessage
+	resps   []models.GrpcLengthPrefixedMessage
+}
+
 func NewStreamInfoCollection() *StreamInfoCollection {
 	return &StreamInfoCollection{
 		StreamInfo: make(map[uint32]models.GrpcStream),
+		messages:   make(map[uint32]*streamMessages),
 	}
 }
 
+func (sic *StreamInfoCollection) messagesOf(streamID uint32) *streamMessages {
+	msgs, ok := sic.messages[streamID]
+	if !ok {
+		msgs = &streamMessages{}
+		sic.messages[streamID] = msgs
+	}
+	return msgs
+}
+
+// splitMessages splits the complete length prefixed messages off the buffer, and returns them with the rest of it.
+func splitMessages(buf []byte) ([]models.GrpcLengthPrefixedMessage, []byte) {
+	var msgs []models.GrpcLengthPrefixedMessage
+	for len(buf) >= 5 {
+		n := 5 + int(binary.BigEndian.Uint32(buf[1:5]))
+		if len(buf) < n {
+			break
+		}
+		msgs = append(msgs, pkg.CreateLengthPrefixedMessageFromPayload(buf[:n]))
+		buf = buf[n:]
+	}
+	return msgs, buf
+}
+
 func (sic *StreamInfoCollection) InitialiseStream(streamID uint32) {
 	sic.mutex.Lock()
 	defer sic.mutex.Unlock()
@@ -82,10 +115,18 @@ func (sic *StreamInfoCollection) AddPayloadForRequest(streamID uint32, payload [
 	sic.mutex.Lock()
 	defer sic.mutex.Unlock()
 
+	msgs := sic.messagesOf(streamID)
+	var complete []models.GrpcLengthPrefixedMessage
+	complete, msgs.reqBuf = splitMessages(append(msgs.reqBuf, payload...))
+	if len(complete) == 0 {
+		return
+	}
+	msgs.reqs = append(msgs.reqs, complete...)
+
 	// We cannot modify non pointer values in nested entries in map.
 	// Create a copy and overwrite it.
 	info := sic.StreamInfo[streamID]
-	info.GrpcReq.Body = createLengthPrefixedMessageFromPayload(payload)
+	info.GrpcReq.Body = complete[len(complete)-1]
 	sic.StreamInfo[streamID] = info
 }
 
@@ -96,24 +137,89 @@ func (sic *StreamInfoCollection) AddPayloadForResponse(streamID uint32, payload
 	sic.mutex.Lock()
 	defer sic.mutex.Unlock()
 
+	msgs := sic.messagesOf(streamID)
+	var complete []models.GrpcLengthPrefixedMessage
+	complete, msgs.respBuf = splitMessages(append(msgs.respBuf, payload...))
+	if len(complete) == 0 {
+		return
+	}
+	msgs.resps = append(msgs.resps, complete...)
+
 	// We cannot modify non pointer values in nested entries in map.
 	// Create a copy and overwrite it.
 	info := sic.StreamInfo[streamID]
-	info.GrpcResp.Body = createLengthPrefixedMessageFromPayload(payload)
+	info.GrpcResp.Body = complete[len(complete)-1]
 	sic.StreamInfo[streamID] = info
 }
 
+// TakeRequests returns the request messages of the stream completed since the last call.
+func (sic *StreamInfoCollection) TakeRequests(streamID uint32) []models.GrpcLengthPrefixedMessage {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	msgs := sic.messagesOf(streamID)
+	reqs := msgs.reqs
+	msgs.reqs = nil
+	return reqs
+}
+
+// PersistReflectionMocks saves a mock for each request message of the reflection stream answered so far, the
+// messages of the other streams are dropped. The reflection server answers each request with one response message,
+// in order, and the tools keep the stream open across their lookups, so the mocks are saved as the responses come
+// rather than once the stream ends.
+func (sic *StreamInfoCollection) PersistReflectionMocks(_ context.Context, streamID uint32, mocks chan<- *models.Mock) {
+	sic.mutex.Lock()
+	defer sic.mutex.Unlock()
+
+	info, ok := sic.StreamInfo[streamID]
+	if !ok {
+		return
+	}
+	msgs := sic.messagesOf(streamID)
+	if !pkg.IsGrpcReflection(info.GrpcReq.Headers.PseudoHeaders[KLabelForPath]) {
+		// only the last messages of the other streams are recorded, in their body
+		msgs.reqs, msgs.resps = nil, nil
+		return
+	}
+	for len(msgs.reqs) > 0 && len(msgs.resps) > 0 {
+		// the headers are copied, the trailers of the stream are yet to be added to them
+		grpcReq := models.GrpcReq{Headers: copyHeaders(info.GrpcReq.Headers), Body: msgs.reqs[0]}
+		grpcResp := models.GrpcResp{Headers: copyHeaders(info.GrpcResp.Headers), Body: msgs.resps[0], Trailers: copyHeaders(info.GrpcResp.Trailers)}
+		msgs.reqs, msgs.resps = msgs.reqs[1:], msgs.resps[1:]
+		mocks <- &models.Mock{
+			Version: models.GetVersion(),
+			Name:    "mocks",
+			Kind:    models.GRPC_EXPORT,
+			Spec: models.MockSpec{
+				GRPCReq:          &grpcReq,
+				GRPCResp:         &grpcResp,
+				ReqTimestampMock: sic.ReqTimestampMock,
+				ResTimestampMock: sic.ResTimestampMock,
+			},
+		}
+	}
+}
+
 func (sic *StreamInfoCollection) PersistMockForStream(_ context.Context, streamID uint32, mocks chan<- *models.Mock) {
 	sic.mutex.Lock()
 	defer sic.mutex.Unlock()
 	grpcReq := sic.StreamInfo[streamID].GrpcReq
 	grpcResp := sic.StreamInfo[streamID].GrpcResp
+	// the messages of the reflection streams are saved by PersistReflectionMocks
+	if pkg.IsGrpcReflection(grpcReq.Headers.PseudoHeaders[KLabelForPath]) {
+		return
+	}
+	var meta map[string]string
+	if id := pkg.CorrelationID(grpcReq.Headers.OrdinaryHeaders); id != "" {
+		meta = map[string]string{models.CorrelationIDKey: id}
+	}
 	// save the mock
 	mocks <- &models.Mock{
 		Version: models.GetVersion(),
 		Name:    "mocks",
 		Kind:    models.GRPC_EXPORT,
 		Spec: models.MockSpec{
+			Metadata:         meta,
 			GRPCReq:          &grpcReq,
 			GRPCResp:         &grpcResp,
 			ReqTimestampMock: sic.ReqTimestampMock,
@@ -122,6 +228,20 @@ func (sic *StreamInfoCollection) PersistMockForStream(_ context.Context, streamI
 	}
 }
 
+func copyHeaders(headers models.GrpcHeaders) models.GrpcHeaders {
+	c := models.GrpcHeaders{
+		PseudoHeaders:   make(map[string]string, len(headers.PseudoHeaders)),
+		OrdinaryHeaders: make(map[string]string, len(headers.OrdinaryHeaders)),
+	}
+	for key, value := range headers.PseudoHeaders {
+		c.PseudoHeaders[key] = value
+	}
+	for key, value := range headers.OrdinaryHeaders {
+		c.OrdinaryHeaders[key] = value
+	}
+	return c
+}
+
 func (sic *StreamInfoCollection) FetchRequestForStream(streamID uint32) models.GrpcReq {
 	sic.mutex.Lock()
 	defer sic.mutex.Unlock()
@@ -134,46 +254,5 @@ func (sic *StreamInfoCollection) ResetStream(streamID uint32) {
 	defer sic.mutex.Unlock()
 
 	delete(sic.StreamInfo, streamID)
-}
-
-func createLengthPrefixedMessageFromPayload(data []byte) models.GrpcLengthPrefixedMessage {
-	msg := models.GrpcLengthPrefixedMessage{}
-
-	// If the body is not length prefixed, we return the default value.
-	if len(data) < 5 {
-		return msg
-	}
-
-	// The first byte is the compression flag.
-	msg.CompressionFlag = uint(data[0])
-
-	// The next 4 bytes are message length.
-	msg.MessageLength = binary.BigEndian.Uint32(data[1:5])
-
-	// The payload could be empty. We only parse it if it is present.
-	if len(data) >= 5 {
-		// Use protoscope to decode the message.
-		msg.DecodedData = protoscope.Write(data[5:], protoscope.WriterOptions{})
-	}
-
-	return msg
-}
-
-func createPayloadFromLengthPrefixedMessage(msg models.GrpcLengthPrefixedMessage) ([]byte, error) {
-	scanner := protoscope.NewScanner(msg.DecodedData)
-	encodedData, err := scanner.Exec()
-	if err != nil {
-		return nil, fmt.Errorf("could not encode grpc msg using protoscope: %v", err)
-	}
-
-	// Note that the encoded length is present in the msg, but it is also equal to the len of encodedData.
-	// We should give the preference to the length of encodedData, since the mocks might have been altered.
-
-	// Reserve 1 byte for compression flag, 4 bytes for length capture.
-	payload := make([]byte, 1+4)
-	payload[0] = uint8(msg.CompressionFlag)
-	binary.BigEndian.PutUint32(payload[1:5], uint32(len(encodedData)))
-	payload = append(payload, encodedData...)
-
-	return payload, nil
+	delete(sic.messages, streamID)
 }
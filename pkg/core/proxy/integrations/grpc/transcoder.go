@@ -5,7 +5,9 @@ import (
 	"context"
 	"fmt"
 
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 
 	"go.uber.org/zap"
@@ -19,15 +21,21 @@ type Transcoder struct {
 	logger  *zap.Logger
 	framer  *http2.Framer
 	decoder *hpack.Decoder
+	opts    models.OutgoingOptions
+	// the reflection streams whose response headers are written
+	respStarted map[uint32]bool
 }
 
-func NewTranscoder(logger *zap.Logger, framer *http2.Framer, mockDb integrations.MockMemDb) *Transcoder {
+func NewTranscoder(logger *zap.Logger, framer *http2.Framer, mockDb integrations.MockMemDb, opts models.OutgoingOptions) *Transcoder {
 	return &Transcoder{
 		logger:  logger,
 		framer:  framer,
 		mockDb:  mockDb,
 		sic:     NewStreamInfoCollection(),
 		decoder: NewDecoder(),
+		opts:    opts,
+
+		respStarted: make(map[uint32]bool),
 	}
 }
 
@@ -76,6 +84,13 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 	}
 
 	grpcReq := srv.sic.FetchRequestForStream(id)
+	if pkg.IsGrpcReflection(grpcReq.Headers.PseudoHeaders[KLabelForPath]) {
+		return srv.serveReflection(ctx, id, grpcReq, dataFrame.StreamEnded())
+	}
+	// The message can span several DATA frames, it is answered once all of them are received.
+	if len(srv.sic.TakeRequests(id)) == 0 {
+		return nil
+	}
 
 	// Fetch all the mocks. We can't assume that the grpc calls are made in a certain order.
 	mock, err := FilterMocksBasedOnGrpcRequest(ctx, srv.logger, grpcReq, srv.mockDb)
@@ -83,7 +98,13 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 		return fmt.Errorf("failed match mocks: %v", err)
 	}
 	if mock == nil {
-		return fmt.Errorf("failed to mock the output for unrecorded outgoing grpc call")
+		return integrations.MissingMockError(fmt.Errorf("unrecorded outgoing grpc call to %s", grpcReq.Headers.PseudoHeaders[":path"]))
+	}
+
+	integrations.SimulateLatency(ctx, mock, srv.opts)
+
+	if fault, ok := integrations.InjectFault(srv.logger, mock, srv.mockDb, srv.opts, models.FaultError, models.FaultReset, models.FaultTimeout); ok {
+		return srv.writeFault(id, fault)
 	}
 
 	grpcMockResp := mock.Spec.GRPCResp
@@ -127,7 +148,7 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 		return err
 	}
 
-	payload, err := createPayloadFromLengthPrefixedMessage(grpcMockResp.Body)
+	payload, err := pkg.CreatePayloadFromLengthPrefixedMessage(grpcMockResp.Body)
 	if err != nil {
 		utils.LogError(srv.logger, err, "could not create grpc payload from mocks")
 		return err
@@ -183,6 +204,116 @@ func (srv *Transcoder) ProcessDataFrame(ctx context.Context, dataFrame *http2.Da
 	return nil
 }
 
+// serveReflection answers each request message of the server reflection stream with the response of its mock. The
+// tools keep the stream open across their lookups, so the trailers are written once the client ends it.
+func (srv *Transcoder) serveReflection(ctx context.Context, id uint32, grpcReq models.GrpcReq, ended bool) error {
+	for _, msg := range srv.sic.TakeRequests(id) {
+		grpcReq.Body = msg
+		mock, err := MatchReflectionMock(ctx, srv.logger, grpcReq, srv.mockDb)
+		if err != nil {
+			return fmt.Errorf("failed match mocks: %v", err)
+		}
+		if mock == nil {
+			return integrations.MissingMockError(fmt.Errorf("unrecorded grpc reflection request %s to %s", msg.DecodedData, grpcReq.Headers.PseudoHeaders[KLabelForPath]))
+		}
+
+		if !srv.respStarted[id] {
+			err = srv.writeHeaders(id, mock.Spec.GRPCResp.Headers, false)
+			if err != nil {
+				utils.LogError(srv.logger, err, "could not write the headers of the reflection stream onto client")
+				return err
+			}
+			srv.respStarted[id] = true
+		}
+
+		payload, err := pkg.CreatePayloadFromLengthPrefixedMessage(mock.Spec.GRPCResp.Body)
+		if err != nil {
+			utils.LogError(srv.logger, err, "could not create grpc payload from mocks")
+			return err
+		}
+		err = srv.framer.WriteData(id, false, payload)
+		if err != nil {
+			utils.LogError(srv.logger, err, "could not write the data frame onto the client")
+			return err
+		}
+	}
+	if !ended {
+		return nil
+	}
+
+	// The client has ended the stream, end it with the OK status.
+	trailers := models.GrpcHeaders{
+		PseudoHeaders:   map[string]string{},
+		OrdinaryHeaders: map[string]string{"grpc-status": "0"},
+	}
+	if !srv.respStarted[id] {
+		// a trailers-only response
+		trailers.PseudoHeaders[":status"] = "200"
+		trailers.OrdinaryHeaders[KLabelForContentType] = "application/grpc"
+	}
+	delete(srv.respStarted, id)
+	return srv.writeHeaders(id, trailers, true)
+}
+
+// writeHeaders writes the headers onto the stream in a single HEADERS frame, the pseudo headers before the ordinary ones.
+func (srv *Transcoder) writeHeaders(id uint32, headers models.GrpcHeaders, endStream bool) error {
+	buf := new(bytes.Buffer)
+	encoder := hpack.NewEncoder(buf)
+	for key, value := range headers.PseudoHeaders {
+		err := encoder.WriteField(hpack.HeaderField{Name: key, Value: value})
+		if err != nil {
+			utils.LogError(srv.logger, err, "could not encode pseudo header", zap.Any("key", key), zap.Any("value", value))
+			return err
+		}
+	}
+	for key, value := range headers.OrdinaryHeaders {
+		err := encoder.WriteField(hpack.HeaderField{Name: key, Value: value})
+		if err != nil {
+			utils.LogError(srv.logger, err, "could not encode ordinary header", zap.Any("key", key), zap.Any("value", value))
+			return err
+		}
+	}
+	return srv.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      id,
+		BlockFragment: buf.Bytes(),
+		EndStream:     endStream,
+		EndHeaders:    true,
+	})
+}
+
+// writeFault responds to the stream with the injected fault instead of the mock response. An error fault is
+// a trailers-only response with the UNAVAILABLE status, a reset fault resets the stream and a timeout fault leaves
+// the stream without a response until the deadline of the call.
+func (srv *Transcoder) writeFault(id uint32, fault models.FaultKind) error {
+	switch fault {
+	case models.FaultReset:
+		return srv.framer.WriteRSTStream(id, http2.ErrCodeInternal)
+	case models.FaultTimeout:
+		return nil
+	}
+
+	buf := new(bytes.Buffer)
+	encoder := hpack.NewEncoder(buf)
+	for _, field := range []hpack.HeaderField{
+		{Name: ":status", Value: "200"},
+		{Name: "content-type", Value: "application/grpc"},
+		{Name: "grpc-status", Value: "14"},
+		{Name: "grpc-message", Value: "fault injected by keploy"},
+	} {
+		err := encoder.WriteField(field)
+		if err != nil {
+			utils.LogError(srv.logger, err, "could not encode the header of the fault", zap.Any("key", field.Name))
+			return err
+		}
+	}
+	return srv.framer.WriteHeaders(http2.HeadersFrameParam{
+		StreamID:      id,
+		BlockFragment: buf.Bytes(),
+		EndStream:     true,
+		EndHeaders:    true,
+	})
+}
+
 func (srv *Transcoder) ProcessWindowUpdateFrame(_ *http2.WindowUpdateFrame) error {
 	// Silently ignore Window tools frames, as we already know the mock payloads that we would send.
 	srv.logger.Info("Received Window Update Frame. Skipping it...")
@@ -191,6 +322,7 @@ func (srv *Transcoder) ProcessWindowUpdateFrame(_ *http2.WindowUpdateFrame) erro
 
 func (srv *Transcoder) ProcessResetStreamFrame(resetStreamFrame *http2.RSTStreamFrame) error {
 	srv.sic.ResetStream(resetStreamFrame.StreamID)
+	delete(srv.respStarted, resetStreamFrame.StreamID)
 	return nil
 }
 
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// conditionalHeaders are the headers of the conditional requests, sent by the http caches of the apps revalidating
+// a cached response.
+var conditionalHeaders = []string{"If-None-Match", "If-Modified-Since"}
+
+// notModifiedHeaders are the headers of the response sent in its 304 Not Modified response.
+var notModifiedHeaders = []string{"Cache-Control", "Content-Location", "Date", "ETag", "Expires", "Last-Modified", "Vary"}
+
+// isConditional checks if the request revalidates a cached response.
+func isConditional(header http.Header) bool {
+	for _, h := range conditionalHeaders {
+		if header.Get(h) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified checks if the response of the mock is still valid for the conditional request, by its ETag or else
+// its Last-Modified, as a server evaluates the preconditions.
+func notModified(method string, reqHeader http.Header, resp *models.HTTPResp) bool {
+	if resp.StatusCode != http.StatusOK {
+		return false
+	}
+	header := pkg.ToHTTPHeader(resp.Header)
+	if inm := reqHeader.Get("If-None-Match"); inm != "" {
+		etag := header.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, tag := range strings.Split(inm, ",") {
+			tag = strings.TrimSpace(tag)
+			// the weak comparison of the entity tags
+			if tag == "*" || strings.TrimPrefix(tag, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if method != http.MethodGet && method != http.MethodHead {
+		return false
+	}
+	ims, err := http.ParseTime(reqHeader.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(header.Get("Last-Modified"))
+	if err != nil {
+		return false
+	}
+	return !lastModified.After(ims)
+}
+
+// notModifiedResponse returns the 304 Not Modified response of the mock, with its validators and cache headers.
+func notModifiedResponse(mock *models.Mock) string {
+	header := pkg.ToHTTPHeader(mock.Spec.HTTPResp.Header)
+	resp := fmt.Sprintf("HTTP/%d.%d %d %s\r\n", mock.Spec.HTTPReq.ProtoMajor, mock.Spec.HTTPReq.ProtoMinor, http.StatusNotModified, http.StatusText(http.StatusNotModified))
+	for _, key := range notModifiedHeaders {
+		for _, value := range header.Values(key) {
+			resp += fmt.Sprintf("%s: %s\r\n", key, strings.TrimSpace(value))
+		}
+	}
+	return resp + "\r\n"
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// cookieURL returns the url of the request of the app the cookies are looked up by. It's https so that the secure
+// cookies are kept too.
+func cookieURL(request *http.Request) *url.URL {
+	return &url.URL{Scheme: "https", Host: request.Host, Path: request.URL.Path}
+}
+
+// storeCookies adds the cookies set by the mock response to the cookie jar of the testcase.
+func storeCookies(jar http.CookieJar, request *http.Request, resp *models.HTTPResp) {
+	header := http.Header{}
+	for key, value := range resp.Header {
+		if http.CanonicalHeaderKey(key) != "Set-Cookie" {
+			continue
+		}
+		for _, cookie := range splitSetCookie(value) {
+			header.Add("Set-Cookie", cookie)
+		}
+	}
+	if cookies := (&http.Response{Header: header}).Cookies(); len(cookies) > 0 {
+		jar.SetCookies(cookieURL(request), cookies)
+	}
+}
+
+// hasJarCookies checks if the cookie jar of the testcase has cookies for the request.
+func hasJarCookies(jar http.CookieJar, request *http.Request) bool {
+	return len(jar.Cookies(cookieURL(request))) > 0
+}
+
+// splitSetCookie splits the Set-Cookie headers recorded joined with commas. The commas of the Expires dates are
+// kept, the part after them has no name=value before its first attribute.
+func splitSetCookie(value string) []string {
+	var cookies []string
+	for _, part := range strings.Split(value, ",") {
+		pair, _, _ := strings.Cut(part, ";")
+		if len(cookies) > 0 && !strings.Contains(pair, "=") {
+			cookies[len(cookies)-1] += "," + part
+			continue
+		}
+		cookies = append(cookies, strings.TrimSpace(part))
+	}
+	return cookies
+}
@@ -4,13 +4,14 @@ package http
 import (
 	"bufio"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
@@ -20,6 +21,9 @@ import (
 	"go.uber.org/zap"
 )
 
+// faultResponse is sent in place of the mock response when an error fault is injected.
+const faultResponse = "HTTP/1.1 500 Internal Server Error\r\nContent-Type: text/plain\r\nContent-Length: 26\r\n\r\nfault injected by keploy\r\n"
+
 // Decodes the mocks in test mode so that they can be sent to the user application.
 func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	errCh := make(chan error, 1)
@@ -29,7 +33,7 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 		defer close(errCh)
 		for {
 			//Check if the expected header is present
-			if bytes.Contains(reqBuf, []byte("Expect: 100-continue")) {
+			if expectsContinue(reqBuf) {
 				logger.Debug("The expect header is present in the request buffer and writing the 100 continue response to the client")
 				//Send the 100 continue response
 				_, err := clientConn.Write([]byte("HTTP/1.1 100 Continue\r\n\r\n"))
@@ -46,7 +50,7 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 				newRequest, err := pUtil.ReadBytes(ctx, logger, clientConn)
 				if err != nil {
 					utils.LogError(logger, err, "failed to read the request buffer from the user application")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return
 				}
 				//Append the new request buffer to the old request buffer
@@ -54,10 +58,10 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			}
 
 			logger.Debug("handling the chunked requests to read the complete request")
-			err := handleChunkedRequests(ctx, logger, &reqBuf, clientConn, nil)
+			err := handleChunkedRequests(ctx, logger, &reqBuf, clientConn, nil, readTimeout(opts))
 			if err != nil {
 				utils.LogError(logger, err, "failed to handle chunked requests")
-				errCh <- err
+				errCh <- integrations.ClientError(err)
 				return
 			}
 
@@ -67,7 +71,7 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			request, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(reqBuf)))
 			if err != nil {
 				utils.LogError(logger, err, "failed to parse the http request message")
-				errCh <- err
+				errCh <- integrations.ParseError(err)
 				return
 			}
 
@@ -80,12 +84,19 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 
 			input := &req{
 				method: request.Method,
+				host:   request.Host,
 				url:    request.URL,
 				header: request.Header,
 				body:   reqBody,
 				raw:    reqBuf,
+				// the cookies set by the earlier mocks of the testcase are matched whether the app sends them or not
+				jarCookies: hasJarCookies(mockDb.CookieJar(), request),
+			}
+			ok, stub, err := match(ctx, logger, input, mockDb, opts, false)
+			// the shared mocks of the test sets are consulted once the ones of the test set don't match
+			if err == nil && !ok {
+				ok, stub, err = match(ctx, logger, input, mockDb, opts, true)
 			}
-			ok, stub, err := match(ctx, logger, input, mockDb)
 			if err != nil {
 				utils.LogError(logger, err, "error while matching http mocks", zap.Any("metadata", getReqMeta(request)))
 				errCh <- err
@@ -94,7 +105,8 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			logger.Debug("after matching the http request", zap.Any("isMatched", ok), zap.Any("stub", stub), zap.Error(err))
 
 			if !ok {
-				if !isPassThrough(logger, request, dstCfg.Port, opts) {
+				passThrough := isPassThrough(logger, request, dstCfg.Port, opts)
+				if !passThrough {
 					utils.LogError(logger, nil, "Didn't match any preExisting http mock", zap.Any("metadata", getReqMeta(request)))
 				}
 				if opts.FallBackOnMiss {
@@ -104,59 +116,120 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 						errCh <- err
 						return
 					}
+				} else if !passThrough {
+					errCh <- integrations.MissingMockError(fmt.Errorf("%s %s", request.Method, request.URL))
+					return
 				}
 				errCh <- nil
 				return
 			}
 
+			integrations.SimulateLatency(ctx, stub, opts)
+
 			statusLine := fmt.Sprintf("HTTP/%d.%d %d %s\r\n", stub.Spec.HTTPReq.ProtoMajor, stub.Spec.HTTPReq.ProtoMinor, stub.Spec.HTTPResp.StatusCode, http.StatusText(stub.Spec.HTTPResp.StatusCode))
 
-			body := stub.Spec.HTTPResp.Body
-			var respBody string
 			var responseString string
 
 			// Fetching the response headers
 			header := pkg.ToHTTPHeader(stub.Spec.HTTPResp.Header)
 
-			//Check if the gzip encoding is present in the header
-			if header["Content-Encoding"] != nil && header["Content-Encoding"][0] == "gzip" {
-				var compressedBuffer bytes.Buffer
-				gw := gzip.NewWriter(&compressedBuffer)
-				_, err := gw.Write([]byte(body))
-				if err != nil {
-					utils.LogError(logger, err, "failed to compress the response body", zap.Any("metadata", getReqMeta(request)))
-					errCh <- err
-					return
-				}
-				err = gw.Close()
-				if err != nil {
-					utils.LogError(logger, err, "failed to close the gzip writer", zap.Any("metadata", getReqMeta(request)))
-					errCh <- err
-					return
+			// the body is encoded with the codings of its Content-Encoding, its length is the one of the bytes written
+			body := stub.Spec.HTTPResp.Body
+			// the recorded JWTs of the token responses are reissued so that the app doesn't find them expired, and the
+			// JWKS of the issuers have the keys they are signed with
+			if opts.OAuthTokens {
+				if grantType(request.Method, request.Header, reqBody) != "" {
+					body = reissueTokens(body, time.Now())
+				} else {
+					body = withSigningKeys(body)
 				}
-				logger.Debug("the length of the response body: " + strconv.Itoa(len(compressedBuffer.String())))
-				respBody = compressedBuffer.String()
-				// responseString = statusLine + headers + "\r\n" + compressedBuffer.String()
-			} else {
-				respBody = body
-				// responseString = statusLine + headers + "\r\n" + body
 			}
+			encoded, err := encodeBody([]byte(body), header.Get("Content-Encoding"))
+			if err != nil {
+				utils.LogError(logger, err, "failed to encode the response body", zap.Any("metadata", getReqMeta(request)))
+				errCh <- err
+				return
+			}
+			respBody := string(encoded)
+			logger.Debug("the length of the response body: " + strconv.Itoa(len(respBody)))
 
+			// the response with trailers is sent with a chunked body, which they follow. The recorded chunked
+			// response is also sent chunked when it's streamed, in chunks of the stream chunk size.
+			trailer := pkg.ToHTTPHeader(stub.Spec.HTTPResp.Trailer)
+			chunked := len(trailer) > 0 || (opts.StreamChunkSize > 0 && isChunked(strings.Join(stub.Spec.HTTPResp.TransferEncoding, ",")))
 			var headers string
+			withBody := hasBody(request.Method, stub.Spec.HTTPResp.StatusCode)
 			for key, values := range header {
 				if key == "Content-Length" {
-					values = []string{strconv.Itoa(len(respBody))}
+					if chunked {
+						continue
+					}
+					if withBody {
+						values = []string{strconv.Itoa(len(respBody))}
+					}
+				}
+				if key == "Transfer-Encoding" && chunked {
+					continue
 				}
 				for _, value := range values {
 					headerLine := fmt.Sprintf("%s: %s\r\n", key, value)
 					headers += headerLine
 				}
 			}
-			responseString = statusLine + headers + "\r\n" + "" + respBody
+			// the body of a response recorded without a Content-Length is framed by one, the app would wait for the
+			// conn to be closed otherwise
+			if !chunked && withBody && header.Get("Content-Length") == "" {
+				headers += fmt.Sprintf("Content-Length: %d\r\n", len(respBody))
+			}
+			if chunked {
+				headers += "Transfer-Encoding: chunked\r\n"
+				for key := range trailer {
+					headers += fmt.Sprintf("Trailer: %s\r\n", key)
+				}
+			}
+			head := interimResponses(stub, expectsContinue(reqBuf)) + statusLine + headers + "\r\n"
+			parts := bodyParts(respBody, opts.StreamChunkSize, chunked, trailer)
+			responseString = head + strings.Join(parts, "")
+
+			// the app revalidating its cached response gets a 304 if the response of the mock is still valid for it
+			if opts.Revalidate && notModified(request.Method, request.Header, stub.Spec.HTTPResp) {
+				logger.Debug("the conditional request is not modified, sending a 304 for the mock", zap.Any("metadata", getReqMeta(request)))
+				parts = nil
+				responseString = notModifiedResponse(stub)
+			}
+
+			fault, faulted := integrations.InjectFault(logger, stub, mockDb, opts, models.FaultError, models.FaultReset, models.FaultTruncate, models.FaultTimeout)
+			if faulted {
+				switch fault {
+				case models.FaultError:
+					responseString = faultResponse
+				case models.FaultTruncate:
+					responseString = responseString[:len(responseString)/2]
+				case models.FaultReset:
+					integrations.ResetConn(logger, clientConn)
+					errCh <- nil
+					return
+				case models.FaultTimeout:
+					integrations.HoldConn(ctx, logger, clientConn)
+					errCh <- nil
+					return
+				}
+			}
 
 			logger.Debug(fmt.Sprintf("Mock Response sending back to client:\n%v", responseString))
 
-			_, err = clientConn.Write([]byte(responseString))
+			// the large bodies are streamed in parts for the apps processing them incrementally, the faults are
+			// written at once
+			writes := []string{responseString}
+			if opts.StreamChunkSize > 0 && !faulted && len(parts) > 1 {
+				writes = append([]string{head}, parts...)
+			}
+			for _, w := range writes {
+				_, err = clientConn.Write([]byte(w))
+				if err != nil {
+					break
+				}
+			}
 			if err != nil {
 				if ctx.Err() != nil {
 					return
@@ -166,6 +239,26 @@ func decodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 				return
 			}
 
+			if !faulted {
+				storeCookies(mockDb.CookieJar(), request, stub.Spec.HTTPResp)
+			}
+
+			// the conn is closed after a truncated response, so the application can't wait for the rest of it
+			if faulted && fault == models.FaultTruncate {
+				err = clientConn.Close()
+				if err != nil {
+					utils.LogError(logger, err, "failed to close the conn after the truncated mock output", zap.Any("metadata", getReqMeta(request)))
+				}
+				errCh <- nil
+				return
+			}
+
+			// the rest of the conn isn't http once the mock switched protocols
+			if stub.Spec.HTTPResp.StatusCode == http.StatusSwitchingProtocols {
+				errCh <- upgradeParser(logger, header.Get("Upgrade")).MockUpgraded(ctx, clientConn, dstCfg, mockDb, opts)
+				return
+			}
+
 			reqBuf, err = pUtil.ReadBytes(ctx, logger, clientConn)
 			if err != nil {
 				logger.Debug("failed to read the request buffer from the client", zap.Error(err))
@@ -6,11 +6,11 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"strings"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/core/proxy/util"
 	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
@@ -52,47 +52,29 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 		defer pUtil.Recover(logger, clientConn, destConn)
 		defer close(errCh)
 		for {
+			// interimResp are the interim responses of the server to the Expect header, recorded with the response.
+			// earlyResp is the start of the final response of the server if it answered without 100 Continue, e.g.
+			// with 417 Expectation Failed, the client then doesn't send the body of the request.
+			var interimResp, earlyResp []byte
 			//check if expect : 100-continue header is present
-			lines := strings.Split(string(finalReq), "\n")
-			var expectHeader string
-			for _, line := range lines {
-				if strings.HasPrefix(line, "Expect:") {
-					expectHeader = strings.TrimSpace(strings.TrimPrefix(line, "Expect:"))
-					break
-				}
-			}
-			if expectHeader == "100-continue" {
-				//Read if the response from the server is 100-continue
-				resp, err := util.ReadBytes(ctx, logger, destConn)
-				if err != nil {
-					utils.LogError(logger, err, "failed to read the response message from the server after 100-continue request")
-					errCh <- err
-					return nil
-				}
-
-				// write the response message to the client
-				_, err = clientConn.Write(resp)
+			if expectsContinue(finalReq) {
+				var err error
+				interimResp, earlyResp, err = readContinue(ctx, logger, clientConn, destConn)
 				if err != nil {
 					if ctx.Err() != nil {
 						return ctx.Err()
 					}
-					utils.LogError(logger, err, "failed to write response message to the user client")
-					errCh <- err
-					return nil
-				}
-
-				logger.Debug("This is the response from the server after the expect header" + string(resp))
-
-				if string(resp) != "HTTP/1.1 100 Continue\r\n\r\n" {
-					utils.LogError(logger, nil, "failed to get the 100 continue response from the user client")
-					errCh <- err
+					utils.LogError(logger, err, "failed to read the response message from the server after 100-continue request")
+					errCh <- integrations.UpstreamError(err)
 					return nil
 				}
+			}
+			if len(interimResp) > 0 && earlyResp == nil {
 				//Reading the request buffer again
 				reqBuf, err = util.ReadBytes(ctx, logger, clientConn)
 				if err != nil {
 					utils.LogError(logger, err, "failed to read the request buffer from the user client")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return nil
 				}
 				// write the request message to the actual destination server
@@ -111,16 +93,22 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			// Capture the request timestamp
 			reqTimestampMock := time.Now()
 
-			err := handleChunkedRequests(ctx, logger, &finalReq, clientConn, destConn)
-			if err != nil {
-				utils.LogError(logger, err, "failed to handle chunked requests")
-				errCh <- err
-				return nil
+			// the body isn't sent if the server answered the Expect header with its response
+			if earlyResp == nil {
+				err := handleChunkedRequests(ctx, logger, &finalReq, clientConn, destConn, readTimeout(opts))
+				if err != nil {
+					utils.LogError(logger, err, "failed to handle chunked requests")
+					errCh <- integrations.ClientError(err)
+					return nil
+				}
 			}
 
 			logger.Debug(fmt.Sprintf("This is the complete request:\n%v", string(finalReq)))
-			// read the response from the actual server
-			resp, err := util.ReadBytes(ctx, logger, destConn)
+			// read the response from the actual server, unless it already started with the interim responses
+			resp, err := earlyResp, error(nil)
+			if earlyResp == nil {
+				resp, err = util.ReadBytes(ctx, logger, destConn)
+			}
 			if err != nil {
 				if err == io.EOF {
 					logger.Debug("Response complete, exiting the loop.")
@@ -143,70 +131,80 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 						// saving last request/response on this conn.
 						m := &finalHTTP{
 							req:              finalReq,
-							resp:             resp,
+							resp:             append(interimResp, resp...),
 							reqTimestampMock: reqTimestampMock,
 							resTimestampMock: resTimestampMock,
 						}
 						err := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts)
 						if err != nil {
 							utils.LogError(logger, err, "failed to parse the final http request and response")
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return nil
 						}
 					}
 					break
 				}
 				utils.LogError(logger, err, "failed to read the response message from the destination server")
-				errCh <- err
+				errCh <- integrations.UpstreamError(err)
 				return nil
 			}
 
 			// Capturing the response timestamp
 			resTimestampMock := time.Now()
 
-			// write the response message to the user client
-			_, err = clientConn.Write(resp)
-			if err != nil {
-				if ctx.Err() != nil {
-					return ctx.Err()
+			// write the response message to the user client, the start of the early response is already forwarded
+			if earlyResp == nil {
+				_, err = clientConn.Write(resp)
+				if err != nil {
+					if ctx.Err() != nil {
+						return ctx.Err()
+					}
+					utils.LogError(logger, err, "failed to write response message to the user client")
+					errCh <- err
+					return nil
 				}
-				utils.LogError(logger, err, "failed to write response message to the user client")
-				errCh <- err
-				return nil
 			}
 			var finalResp []byte
 			finalResp = append(finalResp, resp...)
 			logger.Debug("This is the initial response: " + string(resp))
 
-			err = handleChunkedResponses(ctx, logger, &finalResp, clientConn, destConn, resp)
+			err = handleChunkedResponses(ctx, logger, &finalResp, clientConn, destConn, resp, readTimeout(opts))
 			if err != nil {
 				if err == io.EOF {
 					logger.Debug("conn closed by the server", zap.Error(err))
 					//check if before EOF complete response came, and try to parse it.
 					m := &finalHTTP{
 						req:              finalReq,
-						resp:             finalResp,
+						resp:             append(interimResp, finalResp...),
 						reqTimestampMock: reqTimestampMock,
 						resTimestampMock: resTimestampMock,
 					}
 					parseErr := ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts)
 					if parseErr != nil {
 						utils.LogError(logger, parseErr, "failed to parse the final http request and response")
-						errCh <- parseErr
+						errCh <- integrations.ParseError(parseErr)
 					}
 					errCh <- nil
 					return nil
 				}
 				utils.LogError(logger, err, "failed to handle chunk response")
-				errCh <- err
+				errCh <- integrations.UpstreamError(err)
 				return nil
 			}
 
 			logger.Debug("This is the final response: " + string(finalResp))
 
+			// the bytes the server sent after switching protocols belong to the protocol switched to
+			var serverBuf []byte
+			switched := switchedProtocols(finalResp)
+			if switched {
+				serverBuf = messageBody(finalResp)
+				finalResp = finalResp[:len(finalResp)-len(serverBuf)]
+			}
+
 			m := &finalHTTP{
 				req:              finalReq,
-				resp:             finalResp,
+				resp:             append(interimResp, finalResp...),
 				reqTimestampMock: reqTimestampMock,
 				resTimestampMock: resTimestampMock,
 			}
@@ -214,7 +212,13 @@ func encodeHTTP(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientCo
 			err = ParseFinalHTTP(ctx, logger, m, destPort, mocks, opts)
 			if err != nil {
 				utils.LogError(logger, err, "failed to parse the final http request and response")
-				errCh <- err
+				errCh <- integrations.ParseError(err)
+				return nil
+			}
+
+			// the rest of the conn isn't http once the server switched protocols
+			if switched {
+				errCh <- upgradeParser(logger, upgradeOf(finalResp)).RecordUpgraded(ctx, clientConn, destConn, serverBuf, mocks, opts)
 				return nil
 			}
 
@@ -3,7 +3,6 @@ package http
 import (
 	"bufio"
 	"bytes"
-	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
@@ -116,39 +115,49 @@ func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, dest
 		}
 	}
 
-	// converts the response message buffer to http response
-	respParsed, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(mock.resp)), req)
-	if err != nil {
-		utils.LogError(logger, err, "failed to parse the http response message", zap.Any("metadata", getReqMeta(req)))
-		return err
+	// converts the response message buffer to http response, after the interim responses preceding it
+	respReader := bufio.NewReader(bytes.NewReader(mock.resp))
+	var interim []models.HTTPInterimResp
+	var respParsed *http.Response
+	for {
+		respParsed, err = http.ReadResponse(respReader, req)
+		if err != nil {
+			utils.LogError(logger, err, "failed to parse the http response message", zap.Any("metadata", getReqMeta(req)))
+			return err
+		}
+		if respParsed.StatusCode < 100 || respParsed.StatusCode > 199 || respParsed.StatusCode == http.StatusSwitchingProtocols {
+			break
+		}
+		interim = append(interim, models.HTTPInterimResp{
+			StatusCode: respParsed.StatusCode,
+			Header:     pkg.ToYamlHTTPHeader(respParsed.Header),
+		})
 	}
 
 	//Add the content length to the headers.
 	var respBody []byte
+	var trailer map[string]string
 	//Checking if the body of the response is empty or does not exist.
-	if respParsed.Body != nil { // Read
-		if respParsed.Header.Get("Content-Encoding") == "gzip" {
-			check := respParsed.Body
-			ok, reader := isGZipped(check, logger)
-			logger.Debug("The body is gzip? " + strconv.FormatBool(ok))
-			logger.Debug("", zap.Any("isGzipped", ok))
-			if ok {
-				gzipReader, err := gzip.NewReader(reader)
-				if err != nil {
-					utils.LogError(logger, err, "failed to create a gzip reader", zap.Any("metadata", getReqMeta(req)))
-					return err
-				}
-				respParsed.Body = gzipReader
-			}
-		}
+	//The data after 101 Switching Protocols is of the protocol switched to, not the body of the response.
+	if respParsed.Body != nil && respParsed.StatusCode != http.StatusSwitchingProtocols { // Read
 		respBody, err = io.ReadAll(respParsed.Body)
 		if err != nil {
 			utils.LogError(logger, err, "failed to read the the http response body", zap.Any("metadata", getReqMeta(req)))
 			return err
 		}
+		// the gzip and deflate bodies are recorded decoded, and encoded again when replayed
+		if decoded, ok := decodeBody(respBody, respParsed.Header.Get("Content-Encoding"), true); ok {
+			respBody = decoded
+		}
 		logger.Debug("This is the response body: " + string(respBody))
-		//Set the content length to the headers.
-		respParsed.Header.Set("Content-Length", strconv.Itoa(len(respBody)))
+		// the trailers are read with the end of the chunked body
+		if len(respParsed.Trailer) > 0 {
+			trailer = pkg.ToYamlHTTPHeader(respParsed.Trailer)
+		}
+		//Set the content length to the headers, the one of a response without a body is kept as recorded.
+		if hasBody(req.Method, respParsed.StatusCode) {
+			respParsed.Header.Set("Content-Length", strconv.Itoa(len(respBody)))
+		}
 	}
 
 	// store the request and responses as mocks
@@ -156,6 +165,11 @@ func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, dest
 		"name":      "Http",
 		"type":      models.HTTPClient,
 		"operation": req.Method,
+		// the upstream host is not part of the url of the request, it is used to identify the provider in contracts
+		"host": req.Host,
+	}
+	if id := pkg.CorrelationID(pkg.ToYamlHTTPHeader(req.Header)); id != "" {
+		meta[models.CorrelationIDKey] = id
 	}
 
 	// Check if the request is a passThrough request
@@ -180,9 +194,12 @@ func ParseFinalHTTP(_ context.Context, logger *zap.Logger, mock *finalHTTP, dest
 				URLParams:  pkg.URLParams(req),
 			},
 			HTTPResp: &models.HTTPResp{
-				StatusCode: respParsed.StatusCode,
-				Header:     pkg.ToYamlHTTPHeader(respParsed.Header),
-				Body:       string(respBody),
+				StatusCode:       respParsed.StatusCode,
+				Header:           pkg.ToYamlHTTPHeader(respParsed.Header),
+				Body:             string(respBody),
+				Interim:          interim,
+				Trailer:          trailer,
+				TransferEncoding: respParsed.TransferEncoding,
 			},
 			Created:          time.Now().Unix(),
 			ReqTimestampMock: mock.resTimestampMock,
@@ -0,0 +1,175 @@
+package http
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"sync"
+)
+
+// signingKeys are the keys keploy signs the reissued JWTs with, generated once per run. Their public keys are added
+// to the JWKS served from the recorded mocks of the issuers, so that the apps verifying the signature of their
+// tokens accept the reissued ones.
+type signingKeys struct {
+	rsa *rsa.PrivateKey
+	ec  map[string]*ecdsa.PrivateKey // by the curve of the alg, e.g. P-256 for ES256
+	// kids are the ids of the keys in the JWKS, by the family of the alg, e.g. RS or P-256
+	kids map[string]string
+	err  error
+}
+
+var (
+	keysOnce sync.Once
+	keys     signingKeys
+)
+
+// ecCurves are the curves of the ES algs.
+var ecCurves = map[string]elliptic.Curve{"ES256": elliptic.P256(), "ES384": elliptic.P384(), "ES512": elliptic.P521()}
+
+// hashes are the hashes of the RS and ES algs by their size.
+var hashes = map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+
+func getSigningKeys() (*signingKeys, error) {
+	keysOnce.Do(func() {
+		id := make([]byte, 8)
+		if _, keys.err = rand.Read(id); keys.err != nil {
+			return
+		}
+		prefix := "keploy-" + hex.EncodeToString(id) + "-"
+		keys.kids = map[string]string{"RS": prefix + "rsa"}
+		keys.rsa, keys.err = rsa.GenerateKey(rand.Reader, 2048)
+		if keys.err != nil {
+			return
+		}
+		keys.ec = make(map[string]*ecdsa.PrivateKey)
+		for _, curve := range ecCurves {
+			name := curve.Params().Name
+			keys.ec[name], keys.err = ecdsa.GenerateKey(curve, rand.Reader)
+			if keys.err != nil {
+				return
+			}
+			keys.kids[name] = prefix + strings.ToLower(strings.ReplaceAll(name, "-", ""))
+		}
+	})
+	return &keys, keys.err
+}
+
+// signJWT signs the header and the payload of the JWT with the keploy key of the alg, which is set as the kid of the
+// header. It reports false if keploy doesn't sign with the alg, e.g. the HS algs whose secret only the app and the
+// issuer know.
+func signJWT(header map[string]interface{}, payload []byte) (string, bool) {
+	alg, _ := header["alg"].(string)
+	if len(alg) != 5 || (!strings.HasPrefix(alg, "RS") && !strings.HasPrefix(alg, "ES")) {
+		return "", false
+	}
+	hash, ok := hashes[alg[2:]]
+	if !ok {
+		return "", false
+	}
+	k, err := getSigningKeys()
+	if err != nil {
+		return "", false
+	}
+	family := "RS"
+	if curve, ok := ecCurves[alg]; ok {
+		family = curve.Params().Name
+	}
+	header["kid"] = k.kids[family]
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", false
+	}
+	signed := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := hash.New()
+	digest.Write([]byte(signed))
+
+	var sig []byte
+	if family == "RS" {
+		sig, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, hash, digest.Sum(nil))
+	} else {
+		key := k.ec[family]
+		var r, s *big.Int
+		r, s, err = ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		if err == nil {
+			// the signature of the ES algs is r and s of the size of the curve
+			size := (key.Curve.Params().BitSize + 7) / 8
+			sig = make([]byte, 2*size)
+			r.FillBytes(sig[:size])
+			s.FillBytes(sig[size:])
+		}
+	}
+	if err != nil {
+		return "", false
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig), true
+}
+
+// withSigningKeys returns the body of the JWKS response with the public keys keploy signs the reissued JWTs with
+// added to its keys. The bodies which aren't a JWKS are kept as is.
+func withSigningKeys(body string) string {
+	if !strings.Contains(body, `"keys"`) {
+		return body
+	}
+	var jwks map[string]json.RawMessage
+	if json.Unmarshal([]byte(body), &jwks) != nil {
+		return body
+	}
+	var set []map[string]json.RawMessage
+	if json.Unmarshal(jwks["keys"], &set) != nil || len(set) == 0 {
+		return body
+	}
+	for _, key := range set {
+		if _, ok := key["kty"]; !ok {
+			return body
+		}
+	}
+	k, err := getSigningKeys()
+	if err != nil {
+		return body
+	}
+
+	var added []interface{}
+	for _, key := range set {
+		added = append(added, key)
+	}
+	added = append(added, map[string]string{
+		"kty": "RSA",
+		"use": "sig",
+		"kid": k.kids["RS"],
+		"n":   base64.RawURLEncoding.EncodeToString(k.rsa.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.rsa.E)).Bytes()),
+	})
+	for _, curve := range []string{"P-256", "P-384", "P-521"} {
+		pub, err := k.ec[curve].PublicKey.ECDH()
+		if err != nil {
+			return body
+		}
+		// the uncompressed point is 0x04 followed by x and y
+		point := pub.Bytes()[1:]
+		added = append(added, map[string]string{
+			"kty": "EC",
+			"use": "sig",
+			"kid": k.kids[curve],
+			"crv": curve,
+			"x":   base64.RawURLEncoding.EncodeToString(point[:len(point)/2]),
+			"y":   base64.RawURLEncoding.EncodeToString(point[len(point)/2:]),
+		})
+	}
+	keysJSON, err := json.Marshal(added)
+	if err != nil {
+		return body
+	}
+	jwks["keys"] = keysJSON
+	out, err := json.Marshal(jwks)
+	if err != nil {
+		return body
+	}
+	return string(out)
+}
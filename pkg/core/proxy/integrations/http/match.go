@@ -15,91 +15,59 @@ import (
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
 
 type req struct {
 	method string
+	host   string
 	url    *url.URL
 	header http.Header
 	body   []byte
 	raw    []byte
+	// jarCookies is set if the cookie jar of the testcase has cookies for the request
+	jarCookies bool
 }
 
-func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrations.MockMemDb) (bool, *models.Mock, error) {
+// match returns the mock of the request among the mocks of the test set, or the shared mocks if shared is set. With
+// Revalidate, the conditional headers are left out of the header keys matched and the recorded 304 responses only
+// match the conditional requests. With OAuthTokens, the token requests match the mocks of their grant type and the
+// requests with a bearer JWT match the mocks whose token has its template.
+func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrations.MockMemDb, opts models.OutgoingOptions, shared bool) (matched bool, mock *models.Mock, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.HTTP)
+	defer func() { tracing.EndMockMatching(span, matched, err) }()
+
 	for {
 		if ctx.Err() != nil {
 			return false, nil, ctx.Err()
 		}
 
-		mocks, err := mockDb.GetUnFilteredMocks()
+		// the mocks are looked up by the method, host, url path and body of the request, and by its method and url
+		// path if none of those match
+		mocksOf := mockDb.GetHTTPMocks
+		if shared {
+			mocksOf = mockDb.GetSharedHTTPMocks
+		}
+		exact, mocks, err := mocksOf(input.method, input.host, input.url.Path, input.body)
 
 		if err != nil {
 			utils.LogError(logger, err, "failed to get unfilteredMocks mocks")
 			return false, nil, errors.New("error while matching the request with the mocks")
 		}
 
-		logger.Debug(fmt.Sprintf("Length of unfilteredMocks:%v", len(mocks)))
-
-		var schemaMatched []*models.Mock
+		logger.Debug(fmt.Sprintf("Length of unfilteredMocks with the same endpoint:%v", len(mocks)), zap.Int("sameBody", len(exact)))
 
-		for _, mock := range mocks {
-			if ctx.Err() != nil {
-				return false, nil, ctx.Err()
-			}
-			if mock.Kind != models.HTTP {
-				continue
-			}
-
-			//if the content type is present in http request then we need to check for the same type in the mock
-			if input.header.Get("Content-Type") != "" {
-				if input.header.Get("Content-Type") != mock.Spec.HTTPReq.Header["Content-Type"] {
-					logger.Debug("The content type of mock and request aren't the same")
-					continue
-				}
-			}
-
-			// check the type of the body if content type is not present
-			if !matchBodyType(mock.Spec.HTTPReq.Body, input.body) {
-				logger.Debug("The body of mock and request aren't of same type")
-				continue
-			}
-
-			//parse request body url
-			parsedURL, err := url.Parse(mock.Spec.HTTPReq.URL)
+		schemaMatched, err := schemaMatch(ctx, logger, input, exact, opts)
+		if err != nil {
+			return false, nil, err
+		}
+		if len(schemaMatched) == 0 {
+			schemaMatched, err = schemaMatch(ctx, logger, input, mocks, opts)
 			if err != nil {
-				utils.LogError(logger, err, "failed to parse mock url")
-				continue
-			}
-
-			//Check if the path matches
-			if parsedURL.Path != input.url.Path {
-				//If it is not the same, continue
-				logger.Debug("The url path of mock and request aren't the same")
-				continue
-			}
-
-			//Check if the method matches
-			if mock.Spec.HTTPReq.Method != models.Method(input.method) {
-				//If it is not the same, continue
-				logger.Debug("The method of mock and request aren't the same")
-				continue
-			}
-
-			// Check if the header keys match
-			if !mapsHaveSameKeys(mock.Spec.HTTPReq.Header, input.header) {
-				// Different headers, so not a match
-				logger.Debug("The header keys of mock and request aren't the same")
-				continue
-			}
-
-			if !mapsHaveSameKeys(mock.Spec.HTTPReq.URLParams, input.url.Query()) {
-				// Different query params, so not a match
-				logger.Debug("The query params of mock and request aren't the same")
-				continue
+				return false, nil, err
 			}
-			schemaMatched = append(schemaMatched, mock)
 		}
 
 		if len(schemaMatched) == 0 {
@@ -107,6 +75,11 @@ func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrati
 			return false, nil, nil
 		}
 
+		// the app sends the JWTs reissued in the token responses instead of the recorded ones
+		if opts.OAuthTokens {
+			schemaMatched = bearerMatch(input.header, schemaMatched)
+		}
+
 		// do exact body match
 		ok, bestMatch := exactBodyMatch(input.body, schemaMatched)
 		if ok {
@@ -116,6 +89,16 @@ func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrati
 			return true, bestMatch, nil
 		}
 
+		// the refresh tokens and client assertions of the token requests change between the runs
+		if opts.OAuthTokens {
+			if tokenMock := tokenMatch(input, schemaMatched); tokenMock != nil {
+				if !updateMock(ctx, logger, tokenMock, mockDb) {
+					continue
+				}
+				return true, tokenMock, nil
+			}
+		}
+
 		shortlisted := schemaMatched
 		// If the body is JSON we do a schema match. we can add more custom type matching
 		if isJSON(input.body) {
@@ -171,6 +154,65 @@ func match(ctx context.Context, logger *zap.Logger, input *req, mockDb integrati
 
 }
 
+// schemaMatch returns the mocks whose content type, body type, header keys and query params match those of the request.
+func schemaMatch(ctx context.Context, logger *zap.Logger, input *req, mocks []*models.Mock, opts models.OutgoingOptions) ([]*models.Mock, error) {
+	var schemaMatched []*models.Mock
+	for _, mock := range mocks {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		//if the content type is present in http request then we need to check for the same type in the mock
+		if input.header.Get("Content-Type") != "" {
+			if input.header.Get("Content-Type") != mock.Spec.HTTPReq.Header["Content-Type"] {
+				logger.Debug("The content type of mock and request aren't the same")
+				continue
+			}
+		}
+
+		// check the type of the body if content type is not present
+		if !matchBodyType(mock.Spec.HTTPReq.Body, input.body) {
+			logger.Debug("The body of mock and request aren't of same type")
+			continue
+		}
+
+		// Check if the header keys match
+		mockHeader, header := mock.Spec.HTTPReq.Header, input.header
+		var skipped []string
+		if opts.Revalidate {
+			if mock.Spec.HTTPResp.StatusCode == http.StatusNotModified && !isConditional(input.header) {
+				logger.Debug("The mock is a not modified response of a conditional request")
+				continue
+			}
+			skipped = append(skipped, conditionalHeaders...)
+		}
+		// the cookies set by the earlier mocks of the testcase are tolerated, whether the app sends them or not
+		if input.jarCookies {
+			skipped = append(skipped, "Cookie")
+		}
+		if len(skipped) > 0 {
+			mockHeader = withoutHeaders(mockHeader, skipped)
+			header = input.header.Clone()
+			for _, h := range skipped {
+				header.Del(h)
+			}
+		}
+		if !opts.IgnoreHeaderKeys && !mapsHaveSameKeys(mockHeader, header) {
+			// Different headers, so not a match
+			logger.Debug("The header keys of mock and request aren't the same")
+			continue
+		}
+
+		if !mapsHaveSameKeys(mock.Spec.HTTPReq.URLParams, input.url.Query()) {
+			// Different query params, so not a match
+			logger.Debug("The query params of mock and request aren't the same")
+			continue
+		}
+		schemaMatched = append(schemaMatched, mock)
+	}
+	return schemaMatched, nil
+}
+
 func exactBodyMatch(body []byte, schemaMatched []*models.Mock) (bool, *models.Mock) {
 	for _, mock := range schemaMatched {
 		if mock.Spec.HTTPReq.Body == string(body) {
@@ -204,6 +246,18 @@ func bodyMatch(logger *zap.Logger, mockBody, reqBody []byte) (bool, error) {
 	return true, nil
 }
 
+// withoutHeaders returns a copy of the header without the keys.
+func withoutHeaders(header map[string]string, keys []string) map[string]string {
+	stripped := make(map[string]string, len(header))
+	for k, v := range header {
+		stripped[k] = v
+	}
+	for _, k := range keys {
+		delete(stripped, k)
+	}
+	return stripped
+}
+
 func mapsHaveSameKeys(map1 map[string]string, map2 map[string][]string) bool {
 	if len(map1) != len(map2) {
 		return false
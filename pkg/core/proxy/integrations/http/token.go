@@ -0,0 +1,220 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// tokenFields are the tokens of the response of an OAuth2 token endpoint, which are reissued when they're JWTs.
+var tokenFields = []string{"access_token", "id_token", "refresh_token"}
+
+// defaultTokenLifetime is the lifetime of a reissued JWT without an iat claim or an expires_in.
+const defaultTokenLifetime = time.Hour
+
+// grantType returns the grant_type of the OAuth2 token request, in its form or json body, empty if the request
+// isn't one.
+func grantType(method string, header http.Header, body []byte) string {
+	if method != http.MethodPost {
+		return ""
+	}
+	if strings.HasPrefix(header.Get("Content-Type"), "application/json") || isJSON(body) {
+		var req struct {
+			GrantType string `json:"grant_type"`
+		}
+		if json.Unmarshal(body, &req) != nil {
+			return ""
+		}
+		return req.GrantType
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return ""
+	}
+	return form.Get("grant_type")
+}
+
+// timeClaims are the claims of a JWT which are reissued, left out when comparing the templates of two tokens.
+var timeClaims = []string{"iat", "nbf", "exp"}
+
+// bearerMatch returns the mocks whose bearer JWT has the same template as the bearer JWT of the request, the same
+// header and claims apart from their times, so that the tokens reissued in the token responses, or issued again with
+// the same claims, match the mocks of the recorded ones. The mocks are returned as is if the request has no bearer
+// JWT or none of them has its template.
+func bearerMatch(header http.Header, mocks []*models.Mock) []*models.Mock {
+	template, ok := bearerTemplate(header.Get("Authorization"))
+	if !ok {
+		return mocks
+	}
+	var matched []*models.Mock
+	for _, mock := range mocks {
+		if t, ok := bearerTemplate(mock.Spec.HTTPReq.Header["Authorization"]); ok && t == template {
+			matched = append(matched, mock)
+		}
+	}
+	if len(matched) == 0 {
+		return mocks
+	}
+	return matched
+}
+
+// bearerTemplate returns the header without its kid and the claims without their times of the bearer JWT of the
+// authorization header, it reports false if the header isn't a bearer JWT. The kid of a reissued JWT is the one of
+// the keploy key it is signed with.
+func bearerTemplate(authorization string) (string, bool) {
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return "", false
+	}
+	header, ok := decodeJWTPart(parts[0])
+	if !ok {
+		return "", false
+	}
+	claims, ok := decodeJWTPart(parts[1])
+	if !ok {
+		return "", false
+	}
+	delete(header, "kid")
+	for _, claim := range timeClaims {
+		delete(claims, claim)
+	}
+	// the keys of the maps are marshalled in order
+	template, err := json.Marshal([]map[string]interface{}{header, claims})
+	if err != nil {
+		return "", false
+	}
+	return string(template), true
+}
+
+// decodeJWTPart decodes the base64url json of the header or the claims of a JWT.
+func decodeJWTPart(part string) (map[string]interface{}, bool) {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return nil, false
+	}
+	var decoded map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if dec.Decode(&decoded) != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// tokenMatch returns the mock of the token request with the same grant type, the refresh tokens, codes and client
+// assertions of the request change between the runs. The mocks of the testcase come first.
+func tokenMatch(input *req, mocks []*models.Mock) *models.Mock {
+	grant := grantType(input.method, input.header, input.body)
+	if grant == "" {
+		return nil
+	}
+	var match *models.Mock
+	for _, mock := range mocks {
+		if grantType(string(mock.Spec.HTTPReq.Method), http.Header{"Content-Type": {mock.Spec.HTTPReq.Header["Content-Type"]}}, []byte(mock.Spec.HTTPReq.Body)) != grant {
+			continue
+		}
+		if mock.TestModeInfo.IsFiltered {
+			return mock
+		}
+		if match == nil {
+			match = mock
+		}
+	}
+	return match
+}
+
+// reissueTokens returns the body of the token response with its JWTs reissued at now, keeping their lifetime, so
+// that the app doesn't find the recorded tokens expired. The other tokens and the bodies which aren't json are kept
+// as is. The reissued JWTs are signed with the keys of keploy, see withSigningKeys.
+func reissueTokens(body string, now time.Time) string {
+	var resp map[string]interface{}
+	dec := json.NewDecoder(strings.NewReader(body))
+	dec.UseNumber()
+	if dec.Decode(&resp) != nil {
+		return body
+	}
+	lifetime := defaultTokenLifetime
+	if n, ok := resp["expires_in"].(json.Number); ok {
+		if secs, err := n.Int64(); err == nil && secs > 0 {
+			lifetime = time.Duration(secs) * time.Second
+		}
+	}
+	changed := false
+	for _, field := range tokenFields {
+		token, ok := resp[field].(string)
+		if !ok {
+			continue
+		}
+		if reissued, ok := reissueJWT(token, now, lifetime); ok {
+			resp[field] = reissued
+			changed = true
+		}
+	}
+	if !changed {
+		return body
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if enc.Encode(resp) != nil {
+		return body
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// reissueJWT sets the iat and nbf claims of the JWT to now and its exp to now plus its recorded lifetime, exp minus
+// iat, or else the given lifetime, and signs it again with the keploy key of its alg. It reports false if the token
+// isn't a JWT with an exp claim, or keploy can't sign it, e.g. with the secret of the HS algs, as its recorded
+// signature would not match its new claims.
+func reissueJWT(token string, now time.Time, lifetime time.Duration) (string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return token, false
+	}
+	header, ok := decodeJWTPart(parts[0])
+	if !ok {
+		return token, false
+	}
+	claims, ok := decodeJWTPart(parts[1])
+	if !ok {
+		return token, false
+	}
+	exp, ok := claims["exp"].(json.Number)
+	if !ok {
+		return token, false
+	}
+	if iat, ok := claims["iat"].(json.Number); ok {
+		e, errExp := exp.Int64()
+		i, errIat := iat.Int64()
+		if errExp == nil && errIat == nil && e > i {
+			lifetime = time.Duration(e-i) * time.Second
+		}
+	}
+	claims["exp"] = now.Add(lifetime).Unix()
+	if _, ok := claims["iat"]; ok {
+		claims["iat"] = now.Unix()
+	}
+	if _, ok := claims["nbf"]; ok {
+		claims["nbf"] = now.Unix()
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return token, false
+	}
+	// the unsecured JWTs have no signature
+	if header["alg"] == "none" {
+		parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+		return strings.Join(parts, "."), true
+	}
+	return signJWT(header, payload)
+}
@@ -0,0 +1,28 @@
+package http
+
+import (
+	"bytes"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.uber.org/zap"
+)
+
+// switchedProtocols checks if the response in resp is 101 Switching Protocols, after the interim responses
+// preceding it.
+func switchedProtocols(resp []byte) bool {
+	resp = resp[interimLen(resp):]
+	return len(resp) >= 12 && bytes.HasPrefix(resp, []byte("HTTP/")) && string(resp[9:12]) == "101"
+}
+
+// upgradeParser returns the parser of the connection after it switched to the protocol of the Upgrade header. The
+// messages of every protocol, e.g. the frames of a websocket or the HTTP/2 frames of h2c, are recorded and replayed
+// as generic messages, the server may send first on the connection.
+func upgradeParser(logger *zap.Logger, upgrade string) integrations.Upgrader {
+	logger.Debug("the connection switched protocols, handing it off to the generic parser", zap.String("upgrade", upgrade))
+	return integrations.Registered[string(integrations.GENERIC)](logger).(integrations.Upgrader)
+}
+
+// upgradeOf returns the Upgrade header of the response in resp.
+func upgradeOf(resp []byte) string {
+	return parseHeaders(resp[interimLen(resp):]).Get("Upgrade")
+}
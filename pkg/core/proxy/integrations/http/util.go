@@ -3,24 +3,40 @@ package http
 import (
 	"bufio"
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"net/textproto"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
 
+	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
 )
 
-func handleChunkedRequests(ctx context.Context, logger *zap.Logger, finalReq *[]byte, clientConn, destConn net.Conn) error {
+// defaultReadTimeout is how long the next chunk of a message is waited for if the options don't set it.
+const defaultReadTimeout = 5 * time.Second
+
+// readTimeout returns how long the next chunk of a message is waited for.
+func readTimeout(opts models.OutgoingOptions) time.Duration {
+	if opts.ReadTimeout <= 0 {
+		return defaultReadTimeout
+	}
+	return opts.ReadTimeout
+}
+
+func handleChunkedRequests(ctx context.Context, logger *zap.Logger, finalReq *[]byte, clientConn, destConn net.Conn, readTimeout time.Duration) error {
 
 	if hasCompleteHeaders(*finalReq) {
 		logger.Debug("this request has complete headers in the first chunk itself.")
@@ -48,21 +64,10 @@ func handleChunkedRequests(ctx context.Context, logger *zap.Logger, finalReq *[]
 		*finalReq = append(*finalReq, reqHeader...)
 	}
 
-	lines := strings.Split(string(*finalReq), "\n")
-	var contentLengthHeader string
-	var transferEncodingHeader string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "Content-Length:") {
-			contentLengthHeader = strings.TrimSpace(strings.TrimPrefix(line, "Content-Length:"))
-			break
-		} else if strings.HasPrefix(line, "Transfer-Encoding:") {
-			transferEncodingHeader = strings.TrimSpace(strings.TrimPrefix(line, "Transfer-Encoding:"))
-			break
-		}
-	}
+	contentLengthHeader, transferEncodingHeader := bodyFraming(parseHeaders(*finalReq))
 
 	//Handle chunked requests
-	if contentLengthHeader != "" {
+	if transferEncodingHeader == "" && contentLengthHeader != "" {
 		contentLength, err := strconv.Atoi(contentLengthHeader)
 		if err != nil {
 			utils.LogError(logger, err, "failed to get the content-length header")
@@ -72,18 +77,18 @@ func handleChunkedRequests(ctx context.Context, logger *zap.Logger, finalReq *[]
 		bodyLength := len(*finalReq) - strings.Index(string(*finalReq), "\r\n\r\n") - 4
 		contentLength -= bodyLength
 		if contentLength > 0 {
-			err := contentLengthRequest(ctx, logger, finalReq, clientConn, destConn, contentLength)
+			err := contentLengthRequest(ctx, logger, finalReq, clientConn, destConn, contentLength, readTimeout)
 			if err != nil {
 				return err
 			}
 		}
 	} else if transferEncodingHeader != "" {
 		// check if the initial request is the complete request.
-		if strings.HasSuffix(string(*finalReq), "0\r\n\r\n") {
+		if chunkedBodyComplete(messageBody(*finalReq)) {
 			return nil
 		}
-		if transferEncodingHeader == "chunked" {
-			err := chunkedRequest(ctx, logger, finalReq, clientConn, destConn, transferEncodingHeader)
+		if isChunked(transferEncodingHeader) {
+			err := chunkedRequest(ctx, logger, finalReq, clientConn, destConn, transferEncodingHeader, readTimeout)
 			if err != nil {
 				return err
 			}
@@ -92,13 +97,14 @@ func handleChunkedRequests(ctx context.Context, logger *zap.Logger, finalReq *[]
 	return nil
 }
 
-func handleChunkedResponses(ctx context.Context, logger *zap.Logger, finalResp *[]byte, clientConn, destConn net.Conn, resp []byte) error {
+func handleChunkedResponses(ctx context.Context, logger *zap.Logger, finalResp *[]byte, clientConn, destConn net.Conn, resp []byte, readTimeout time.Duration) error {
 
 	if hasCompleteHeaders(*finalResp) {
 		logger.Debug("this response has complete headers in the first chunk itself.")
 	}
 
-	for !hasCompleteHeaders(resp) {
+	// the interim 1xx responses are followed by the final response, whose headers frame the body
+	for !hasCompleteHeaders(resp[interimLen(resp):]) {
 		logger.Debug("couldn't get complete headers in first chunk so reading more chunks")
 		respHeader, err := util.ReadBytes(ctx, logger, destConn)
 		if err != nil {
@@ -137,38 +143,28 @@ func handleChunkedResponses(ctx context.Context, logger *zap.Logger, finalResp *
 	}
 
 	//Getting the content-length or the transfer-encoding header
-	var contentLengthHeader, transferEncodingHeader string
-	lines := strings.Split(string(resp), "\n")
-	for _, line := range lines {
-		if strings.HasPrefix(line, "Content-Length:") {
-			contentLengthHeader = strings.TrimSpace(strings.TrimPrefix(line, "Content-Length:"))
-			break
-		} else if strings.HasPrefix(line, "Transfer-Encoding:") {
-			transferEncodingHeader = strings.TrimSpace(strings.TrimPrefix(line, "Transfer-Encoding:"))
-			break
-		}
-	}
+	contentLengthHeader, transferEncodingHeader := bodyFraming(parseHeaders(resp[interimLen(resp):]))
 	//Handle chunked responses
-	if contentLengthHeader != "" {
+	if transferEncodingHeader == "" && contentLengthHeader != "" {
 		contentLength, err := strconv.Atoi(contentLengthHeader)
 		if err != nil {
 			utils.LogError(logger, err, "failed to get the content-length header")
 			return fmt.Errorf("failed to handle chunked response")
 		}
-		bodyLength := len(resp) - strings.Index(string(resp), "\r\n\r\n") - 4
+		bodyLength := len(messageBody(resp))
 		contentLength -= bodyLength
 		if contentLength > 0 {
-			err := contentLengthResponse(ctx, logger, finalResp, clientConn, destConn, contentLength)
+			err := contentLengthResponse(ctx, logger, finalResp, clientConn, destConn, contentLength, readTimeout)
 			if err != nil {
 				return err
 			}
 		}
 	} else if transferEncodingHeader != "" {
 		//check if the initial response is the complete response.
-		if strings.HasSuffix(string(*finalResp), "0\r\n\r\n") {
+		if chunkedBodyComplete(messageBody(*finalResp)) {
 			return nil
 		}
-		if transferEncodingHeader == "chunked" {
+		if isChunked(transferEncodingHeader) {
 			err := chunkedResponse(ctx, logger, finalResp, clientConn, destConn)
 			if err != nil {
 				return err
@@ -179,9 +175,9 @@ func handleChunkedResponses(ctx context.Context, logger *zap.Logger, finalResp *
 }
 
 // Handled chunked requests when content-length is given.
-func contentLengthRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, clientConn, destConn net.Conn, contentLength int) error {
+func contentLengthRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, clientConn, destConn net.Conn, contentLength int, readTimeout time.Duration) error {
 	for contentLength > 0 {
-		err := clientConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		err := clientConn.SetReadDeadline(time.Now().Add(readTimeout))
 		if err != nil {
 			utils.LogError(logger, err, "failed to set the read deadline for the client conn")
 			return err
@@ -218,7 +214,7 @@ func contentLengthRequest(ctx context.Context, logger *zap.Logger, finalReq *[]b
 }
 
 // Handled chunked requests when transfer-encoding is given.
-func chunkedRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, clientConn, destConn net.Conn, _ string) error {
+func chunkedRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, clientConn, destConn net.Conn, _ string, readTimeout time.Duration) error {
 
 	for {
 		select {
@@ -226,8 +222,8 @@ func chunkedRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, c
 			return ctx.Err()
 		default:
 			//TODO: we have to implement a way to read the buffer chunk wise according to the chunk size (chunk size comes in hexadecimal)
-			// because it can happen that some chunks come after 5 seconds.
-			err := clientConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+			// because it can happen that some chunks come after the read timeout.
+			err := clientConn.SetReadDeadline(time.Now().Add(readTimeout))
 			if err != nil {
 				utils.LogError(logger, err, "failed to set the read deadline for the client conn")
 				return err
@@ -254,8 +250,8 @@ func chunkedRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, c
 				}
 			}
 
-			//check if the initial request is completed
-			if strings.HasSuffix(string(requestChunked), "0\r\n\r\n") {
+			//check if the request is completed, with its trailers
+			if chunkedBodyComplete(messageBody(*finalReq)) {
 				return nil
 			}
 		}
@@ -263,11 +259,10 @@ func chunkedRequest(ctx context.Context, logger *zap.Logger, finalReq *[]byte, c
 }
 
 // Handled chunked responses when content-length is given.
-func contentLengthResponse(ctx context.Context, logger *zap.Logger, finalResp *[]byte, clientConn, destConn net.Conn, contentLength int) error {
+func contentLengthResponse(ctx context.Context, logger *zap.Logger, finalResp *[]byte, clientConn, destConn net.Conn, contentLength int, readTimeout time.Duration) error {
 	isEOF := false
 	for contentLength > 0 {
-		//Set deadline of 5 seconds
-		err := destConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		err := destConn.SetReadDeadline(time.Now().Add(readTimeout))
 		if err != nil {
 			utils.LogError(logger, err, "failed to set the read deadline for the destination conn")
 			return err
@@ -349,28 +344,98 @@ func chunkedResponse(ctx context.Context, logger *zap.Logger, finalResp *[]byte,
 				break
 			}
 
-			if string(resp) == "0\r\n\r\n" {
+			//check if the response is completed, with its trailers
+			if chunkedBodyComplete(messageBody(*finalResp)) {
 				return nil
 			}
 		}
 	}
 }
 
-// Checks if the response is gzipped
-func isGZipped(check io.ReadCloser, l *zap.Logger) (bool, *bufio.Reader) {
-	bufReader := bufio.NewReader(check)
-	peekedBytes, err := bufReader.Peek(2)
-	if err != nil && err != io.EOF {
-		l.Debug("failed to peek the response", zap.Error(err))
-		return false, nil
+// contentCodings returns the content codings of the Content-Encoding header in the order they were applied.
+func contentCodings(contentEncoding string) []string {
+	var codings []string
+	for _, coding := range strings.Split(contentEncoding, ",") {
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "" && coding != "identity" {
+			codings = append(codings, coding)
+		}
+	}
+	return codings
+}
+
+// decodeBody decompresses the gzip and deflate body of the Content-Encoding, so that it's recorded readable. It
+// reports false with the body as is if the body isn't encoded with the codings, or one of them is another coding.
+// The deflate bodies are zlib streams, rawDeflate also accepts the raw deflate streams sent by some servers.
+func decodeBody(body []byte, contentEncoding string, rawDeflate bool) ([]byte, bool) {
+	codings := contentCodings(contentEncoding)
+	if len(codings) == 0 {
+		return body, false
+	}
+	decoded := body
+	for i := len(codings) - 1; i >= 0; i-- {
+		var r io.Reader
+		var err error
+		switch codings[i] {
+		case "gzip", "x-gzip":
+			r, err = gzip.NewReader(bytes.NewReader(decoded))
+		case "deflate":
+			r, err = zlib.NewReader(bytes.NewReader(decoded))
+			if err != nil && rawDeflate {
+				r, err = flate.NewReader(bytes.NewReader(decoded)), nil
+			}
+		default:
+			return body, false
+		}
+		if err != nil {
+			return body, false
+		}
+		decoded, err = io.ReadAll(r)
+		if err != nil {
+			return body, false
+		}
+	}
+	return decoded, true
+}
+
+// encodeBody compresses the body of the mock response with the gzip and deflate codings of its Content-Encoding,
+// in their order, into the exact bytes written to the app. The bodies of the other codings and the ones recorded
+// still encoded are sent as is.
+func encodeBody(body []byte, contentEncoding string) ([]byte, error) {
+	codings := contentCodings(contentEncoding)
+	if len(codings) == 0 {
+		return body, nil
 	}
-	if len(peekedBytes) < 2 {
-		return false, nil
+	if _, ok := decodeBody(body, contentEncoding, false); ok {
+		return body, nil
 	}
-	if peekedBytes[0] == 0x1f && peekedBytes[1] == 0x8b {
-		return true, bufReader
+	for _, coding := range codings {
+		if coding != "gzip" && coding != "x-gzip" && coding != "deflate" {
+			return body, nil
+		}
+	}
+	encoded := body
+	for _, coding := range codings {
+		var buf bytes.Buffer
+		var w io.WriteCloser = zlib.NewWriter(&buf)
+		if coding != "deflate" {
+			w = gzip.NewWriter(&buf)
+		}
+		if _, err := w.Write(encoded); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		encoded = buf.Bytes()
 	}
-	return false, nil
+	return encoded, nil
+}
+
+// hasBody checks if the response to the request has a body, so that its Content-Length is its length. The
+// Content-Length of a response to HEAD, 1xx, 204 and 304 is the one of the response they stand for.
+func hasBody(method string, statusCode int) bool {
+	return method != http.MethodHead && statusCode >= 200 && statusCode != http.StatusNoContent && statusCode != http.StatusNotModified
 }
 
 // hasCompleteHeaders checks if the given byte slice contains the complete HTTP headers
@@ -382,6 +447,195 @@ func hasCompleteHeaders(httpChunk []byte) bool {
 	return bytes.Contains(httpChunk, headerEndSequence)
 }
 
+// interimLen returns the length of the complete interim 1xx responses at the start of resp, e.g. 100 Continue or
+// 103 Early Hints, which precede the final response. 101 Switching Protocols is a final response.
+func interimLen(resp []byte) int {
+	n := 0
+	for isInterim(resp[n:]) {
+		end := bytes.Index(resp[n:], []byte("\r\n\r\n"))
+		if end < 0 {
+			break
+		}
+		n += end + 4
+	}
+	return n
+}
+
+// isInterim checks if the http response in resp is an interim 1xx response.
+func isInterim(resp []byte) bool {
+	// HTTP/1.1 1xx
+	if len(resp) < 12 || !bytes.HasPrefix(resp, []byte("HTTP/")) {
+		return false
+	}
+	code := string(resp[9:12])
+	return code[0] == '1' && code != "101"
+}
+
+// messageBody returns the body of the http message in buf, after its headers and the interim responses preceding
+// it, nil if its headers aren't complete.
+func messageBody(buf []byte) []byte {
+	buf = buf[interimLen(buf):]
+	end := bytes.Index(buf, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil
+	}
+	return buf[end+4:]
+}
+
+// chunkedBodyComplete checks if the chunked body is complete, up to the empty line ending the trailers after its
+// last chunk.
+func chunkedBodyComplete(body []byte) bool {
+	for {
+		end := bytes.Index(body, []byte("\r\n"))
+		if end < 0 {
+			return false
+		}
+		// the size of the chunk can be followed by its extensions
+		sizeField, _, _ := strings.Cut(string(body[:end]), ";")
+		size, err := strconv.ParseUint(strings.TrimSpace(sizeField), 16, 63)
+		if err != nil {
+			return false
+		}
+		body = body[end+2:]
+		if size == 0 {
+			return bytes.HasPrefix(body, []byte("\r\n")) || bytes.Contains(body, []byte("\r\n\r\n"))
+		}
+		if uint64(len(body)) < size+2 {
+			return false
+		}
+		body = body[size+2:]
+	}
+}
+
+// interimResponses returns the interim 1xx responses of the mock, which are sent before its response. The 100
+// Continue response is skipped if it was already sent for the Expect header of the request.
+func interimResponses(mock *models.Mock, continueSent bool) string {
+	var resp string
+	for _, interim := range mock.Spec.HTTPResp.Interim {
+		if interim.StatusCode == http.StatusContinue && continueSent {
+			continue
+		}
+		resp += fmt.Sprintf("HTTP/%d.%d %d %s\r\n", mock.Spec.HTTPReq.ProtoMajor, mock.Spec.HTTPReq.ProtoMinor, interim.StatusCode, http.StatusText(interim.StatusCode))
+		for key, values := range pkg.ToHTTPHeader(interim.Header) {
+			for _, value := range values {
+				resp += fmt.Sprintf("%s: %s\r\n", key, value)
+			}
+		}
+		resp += "\r\n"
+	}
+	return resp
+}
+
+// bodyParts splits the body of the mock response into the parts written to the app one at a time, of size bytes
+// each or the whole body if size is 0. The parts of a chunked body are its chunks followed by the last chunk with
+// the trailers.
+func bodyParts(body string, size int, chunked bool, trailer http.Header) []string {
+	if size <= 0 {
+		size = len(body)
+	}
+	var parts []string
+	for start := 0; start < len(body); start += size {
+		part := body[start:min(start+size, len(body))]
+		if chunked {
+			part = fmt.Sprintf("%x\r\n%s\r\n", len(part), part)
+		}
+		parts = append(parts, part)
+	}
+	if !chunked {
+		return parts
+	}
+	last := "0\r\n"
+	for key, values := range trailer {
+		for _, value := range values {
+			last += fmt.Sprintf("%s: %s\r\n", key, value)
+		}
+	}
+	return append(parts, last+"\r\n")
+}
+
+// parseHeaders parses the headers of the http message in buf, their names are case-insensitive and the folded
+// lines are joined. It returns nil if buf doesn't have the complete headers.
+func parseHeaders(buf []byte) textproto.MIMEHeader {
+	end := bytes.Index(buf, []byte("\r\n\r\n"))
+	if end < 0 {
+		return nil
+	}
+	r := textproto.NewReader(bufio.NewReader(bytes.NewReader(buf[:end+4])))
+	// skip the request or the status line
+	if _, err := r.ReadLine(); err != nil {
+		return nil
+	}
+	// the headers before a malformed line are still returned
+	header, _ := r.ReadMIMEHeader()
+	return header
+}
+
+// bodyFraming returns the Content-Length and the Transfer-Encoding headers framing the body of the message, the
+// Content-Length is ignored if the message has a Transfer-Encoding.
+func bodyFraming(header textproto.MIMEHeader) (string, string) {
+	if te := header.Values("Transfer-Encoding"); len(te) > 0 {
+		return "", strings.TrimSpace(strings.Join(te, ","))
+	}
+	return strings.TrimSpace(header.Get("Content-Length")), ""
+}
+
+// isChunked checks if chunked is the last of the codings of the Transfer-Encoding.
+func isChunked(transferEncoding string) bool {
+	codings := strings.Split(transferEncoding, ",")
+	return strings.EqualFold(strings.TrimSpace(codings[len(codings)-1]), "chunked")
+}
+
+// expectsContinue checks if the request has the Expect: 100-continue header.
+func expectsContinue(req []byte) bool {
+	return strings.EqualFold(strings.TrimSpace(parseHeaders(req).Get("Expect")), "100-continue")
+}
+
+// readContinue reads the answer of the server to a request with the Expect: 100-continue header and forwards it to
+// the client. It returns the interim responses once the server sent 100 Continue, along with the start of the final
+// response if the server answered with it instead, e.g. with 417 Expectation Failed.
+func readContinue(ctx context.Context, logger *zap.Logger, clientConn, destConn net.Conn) ([]byte, []byte, error) {
+	var got []byte
+	for {
+		buf, err := util.ReadBytes(ctx, logger, destConn)
+		if err != nil && (err != io.EOF || len(buf) == 0) {
+			return nil, nil, err
+		}
+		_, werr := clientConn.Write(buf)
+		if werr != nil {
+			return nil, nil, werr
+		}
+		got = append(got, buf...)
+		logger.Debug("This is the response from the server after the expect header: " + string(got))
+
+		n := interimLen(got)
+		// the final response started, the client isn't expected to send the body of the request
+		if rest := got[n:]; len(rest) >= 12 && !isInterim(rest) {
+			return got[:n], rest, nil
+		}
+		if n == len(got) && continued(got) {
+			return got, nil, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// continued checks if the interim responses in resp include 100 Continue.
+func continued(resp []byte) bool {
+	for len(resp) >= 12 {
+		if string(resp[9:12]) == "100" {
+			return true
+		}
+		end := bytes.Index(resp, []byte("\r\n\r\n"))
+		if end < 0 {
+			return false
+		}
+		resp = resp[end+4:]
+	}
+	return false
+}
+
 // extract the request metadata from the request
 func getReqMeta(req *http.Request) map[string]string {
 	reqMeta := map[string]string{}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"crypto/tls"
 	"net"
+	"net/http"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.uber.org/zap"
@@ -39,6 +40,13 @@ type Integrations interface {
 	MockOutgoing(ctx context.Context, src net.Conn, dstCfg *ConditionalDstCfg, mockDb MockMemDb, opts models.OutgoingOptions) error
 }
 
+// Upgrader is implemented by the parsers which take over the http connections after they switched protocols. The
+// server may send first on them, and the bytes it sent along with the switch are already forwarded to the client.
+type Upgrader interface {
+	RecordUpgraded(ctx context.Context, src net.Conn, dst net.Conn, serverBuf []byte, mocks chan<- *models.Mock, opts models.OutgoingOptions) error
+	MockUpgraded(ctx context.Context, src net.Conn, dstCfg *ConditionalDstCfg, mockDb MockMemDb, opts models.OutgoingOptions) error
+}
+
 func Register(name string, i Initializer) {
 	Registered[name] = i
 }
@@ -46,9 +54,19 @@ func Register(name string, i Initializer) {
 type MockMemDb interface {
 	GetFilteredMocks() ([]*models.Mock, error)
 	GetUnFilteredMocks() ([]*models.Mock, error)
+	// GetHTTPMocks returns the unfiltered http mocks recorded for the given method, host, url path and body, and
+	// those recorded for the method and url path to fall back to
+	GetHTTPMocks(method, host, path string, body []byte) ([]*models.Mock, []*models.Mock, error)
+	// GetSharedHTTPMocks returns the http mocks shared by the test sets for the given method, host, url path and
+	// body, and those for the method and url path to fall back to
+	GetSharedHTTPMocks(method, host, path string, body []byte) ([]*models.Mock, []*models.Mock, error)
 	UpdateUnFilteredMock(old *models.Mock, new *models.Mock) bool
 	DeleteFilteredMock(mock *models.Mock) bool
 	DeleteUnFilteredMock(mock *models.Mock) bool
 	// Flag the mock as used which matches the external request from application in test mode
 	FlagMockAsUsed(mock *models.Mock) error
+	// Flag the mock whose response was replaced with a fault in test mode
+	FlagMockAsFaulted(mock *models.Mock, fault models.FaultKind)
+	// CookieJar returns the cookies set by the http mock responses of the testcase
+	CookieJar() http.CookieJar
 }
@@ -0,0 +1,27 @@
+package integrations
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// SimulateLatency delays the response of the matched mock by its recorded latency, i.e. the time between
+// its request and response, scaled with the latency multiplier of the options. It returns early if the
+// context is cancelled.
+func SimulateLatency(ctx context.Context, mock *models.Mock, opts models.OutgoingOptions) {
+	if opts.LatencyMultiplier <= 0 || mock == nil {
+		return
+	}
+	latency := mock.Spec.ResTimestampMock.Sub(mock.Spec.ReqTimestampMock)
+	if mock.Spec.ReqTimestampMock.IsZero() || latency <= 0 {
+		return
+	}
+	timer := time.NewTimer(time.Duration(float64(latency) * opts.LatencyMultiplier))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
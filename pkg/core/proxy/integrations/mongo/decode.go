@@ -48,7 +48,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						return
 					}
 					utils.LogError(logger, err, "failed to read request from the mongo client")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return
 				}
 				requestBuffers = append(requestBuffers, reqBuf)
@@ -63,7 +63,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 			opReq, requestHeader, mongoRequest, err := Decode(reqBuf, logger)
 			if err != nil {
 				utils.LogError(logger, err, "failed to decode the mongo wire message from the client")
-				errCh <- err
+				errCh <- integrations.ParseError(err)
 				return
 			}
 			mongoRequests = append(mongoRequests, models.MongoRequest{
@@ -83,7 +83,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 							return
 						}
 						utils.LogError(logger, err, "failed to read request from the mongo client", zap.String("mongo server address", dstCfg.Addr))
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return
 					}
 					requestBuffers = append(requestBuffers, reqBuf)
@@ -96,7 +96,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 					_, reqHeader, mongoReq, err := Decode(requestBuffer1, logger)
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode the mongo wire message from the mongo client")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return
 					}
 					if mongoReqVal, ok := mongoReq.(models.MongoOpMessage); ok && !hasSecondSetBit(mongoReqVal.FlagBits) {
@@ -181,6 +181,10 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 				responseTo := mongoRequests[0].Header.RequestID
 				if bestMatchIndex == -1 || maxMatchScore == 0.0 {
 					logger.Debug("the mongo request do not matches with any config mocks", zap.Any("request", mongoRequests))
+					if opts.StrictMocking {
+						errCh <- integrations.MissingMockError(fmt.Errorf("unmatched mongo heartbeat"))
+						return
+					}
 					continue
 				}
 				// set the config as used in the mockManager
@@ -197,7 +201,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						replyMessage, err := encodeOpReply(replySpec, logger)
 						if err != nil {
 							utils.LogError(logger, err, "failed to encode the recorded OpReply yaml", zap.Any("for request with id", responseTo))
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return
 						}
 						requestID := wiremessage.NextRequestID()
@@ -210,7 +214,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 								return
 							}
 							utils.LogError(logger, err, "failed to write the health check reply to mongo client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return
 						}
 					case wiremessage.OpMsg:
@@ -223,7 +227,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 						message, err := encodeOpMsg(respMessage, mongoRequest.(*models.MongoOpMessage).Sections, expectedRequestSections, opts.MongoPassword, logger)
 						if err != nil {
 							utils.LogError(logger, err, "failed to encode the recorded OpMsg response", zap.Any("for request with id", responseTo))
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return
 						}
 						_, err = clientConn.Write(message.Encode(responseTo, wiremessage.NextRequestID()))
@@ -232,7 +236,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 								return
 							}
 							utils.LogError(logger, err, "failed to write the health check opmsg to mongo client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return
 						}
 					}
@@ -246,10 +250,14 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 				}
 				if !matched {
 					logger.Debug("mongo request not matched with any tcsMocks", zap.Any("request", mongoRequests))
+					if opts.StrictMocking {
+						errCh <- integrations.MissingMockError(fmt.Errorf("unmatched mongo request"))
+						return
+					}
 					reqBuf, err = util.PassThrough(ctx, logger, clientConn, dstCfg, requestBuffers)
 					if err != nil {
 						utils.LogError(logger, err, "failed to passthrough the mongo request to the actual database server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return
 					}
 					continue
@@ -257,6 +265,16 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 
 				responseTo := mongoRequests[0].Header.RequestID
 				logger.Debug("the mock matched with the current request", zap.Any("mock", matchedMock), zap.Any("responseTo", responseTo))
+				integrations.SimulateLatency(ctx, matchedMock, opts)
+				if fault, ok := integrations.InjectFault(logger, matchedMock, mockDb, opts, models.FaultReset, models.FaultTimeout); ok {
+					if fault == models.FaultTimeout {
+						integrations.HoldConn(ctx, logger, clientConn)
+					} else {
+						integrations.ResetConn(logger, clientConn)
+					}
+					errCh <- nil
+					return
+				}
 
 				for _, resp := range matchedMock.Spec.MongoResponses {
 					respMessage := resp.Message.(*models.MongoOpMessage)
@@ -267,7 +285,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 					message, err := encodeOpMsg(respMessage, mongoRequest.(*models.MongoOpMessage).Sections, expectedRequestSections, opts.MongoPassword, logger)
 					if err != nil {
 						utils.LogError(logger, err, "failed to encode the recorded OpMsg response", zap.Any("for request with id", responseTo))
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return
 					}
 					requestID := wiremessage.NextRequestID()
@@ -277,7 +295,7 @@ func decodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientC
 							return
 						}
 						utils.LogError(logger, err, "failed to write the health check opmsg to mongo client", zap.Any("for request with id", responseTo))
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return
 					}
 					responseTo = requestID
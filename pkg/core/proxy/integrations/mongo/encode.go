@@ -10,6 +10,7 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -47,7 +48,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 						return nil
 					}
 					utils.LogError(logger, err, "failed to read request from the mongo client", zap.String("mongo client address", clientConn.RemoteAddr().String()))
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return nil
 				}
 				readRequestDelay = time.Since(started)
@@ -62,7 +63,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 			opReq, requestHeader, mongoRequest, err := Decode(reqBuf, logger)
 			if err != nil {
 				utils.LogError(logger, err, "failed to decode the mongo wire message from the client")
-				errCh <- err
+				errCh <- integrations.ParseError(err)
 				return nil
 			}
 
@@ -77,7 +78,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 					return ctx.Err()
 				}
 				utils.LogError(logger, err, "failed to write the request buffer to mongo server", zap.String("mongo server address", destConn.RemoteAddr().String()))
-				errCh <- err
+				errCh <- integrations.UpstreamError(err)
 				return nil
 			}
 			logger.Debug(fmt.Sprintf("the request in the mongo parser after passing to dest: %v", len(reqBuf)))
@@ -95,7 +96,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 							return nil
 						}
 						utils.LogError(logger, err, "failed to read request from the mongo client", zap.String("mongo client address", clientConn.RemoteAddr().String()))
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 
@@ -106,7 +107,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write the reply message to mongo client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 
@@ -119,7 +120,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 					_, reqHeader, mongoReq, err := Decode(requestBuffer1, logger)
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode the mongo wire message from the destination server")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					if mongoReqVal, ok := mongoReq.(models.MongoOpMessage); ok && !hasSecondSetBit(mongoReqVal.FlagBits) {
@@ -146,7 +147,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 					return nil
 				}
 				utils.LogError(logger, err, "failed to read reply from the mongo server", zap.String("mongo server address", destConn.RemoteAddr().String()))
-				errCh <- err
+				errCh <- integrations.UpstreamError(err)
 				return nil
 			}
 
@@ -169,7 +170,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 					return nil
 				}
 				utils.LogError(logger, err, "failed to read reply from the mongo server", zap.String("mongo server address", destConn.RemoteAddr().String()))
-				errCh <- err
+				errCh <- integrations.UpstreamError(err)
 				return nil
 			}
 			readResponseDelay := time.Since(started)
@@ -181,7 +182,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 					return ctx.Err()
 				}
 				utils.LogError(logger, err, "failed to write the reply message to mongo client")
-				errCh <- err
+				errCh <- integrations.ClientError(err)
 				return nil
 			}
 
@@ -190,7 +191,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 			_, responseHeader, mongoResponse, err := Decode(responseBuffer, logger)
 			if err != nil {
 				utils.LogError(logger, err, "failed to decode the mongo wire message from the destination server")
-				errCh <- err
+				errCh <- integrations.ParseError(err)
 				return nil
 			}
 			mongoResponses = append(mongoResponses, models.MongoResponse{
@@ -213,7 +214,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 							return nil
 						}
 						utils.LogError(logger, err, "failed to read reply from the mongo server", zap.String("mongo server address", destConn.RemoteAddr().String()))
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					logger.Debug(fmt.Sprintf("the response in the mongo parser before passing to client: %v", len(responseBuffer)))
@@ -227,7 +228,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write the reply message to mongo client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					logger.Debug(fmt.Sprintf("the response in the mongo parser after passing to client: %v", len(responseBuffer)))
@@ -241,7 +242,7 @@ func (m *Mongo) encodeMongo(ctx context.Context, logger *zap.Logger, reqBuf []by
 					_, respHeader, mongoResp, err := Decode(responseBuffer, logger)
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode the mongo wire message from the destination server")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					if mongoRespVal, ok := mongoResp.(models.MongoOpMessage); ok && !hasSecondSetBit(mongoRespVal.FlagBits) {
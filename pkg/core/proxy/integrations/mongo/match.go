@@ -11,11 +11,15 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
 	"go.uber.org/zap"
 )
 
-func match(ctx context.Context, logger *zap.Logger, mongoRequests []models.MongoRequest, mockDb integrations.MockMemDb) (bool, *models.Mock, error) {
+func match(ctx context.Context, logger *zap.Logger, mongoRequests []models.MongoRequest, mockDb integrations.MockMemDb) (matched bool, mock *models.Mock, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.Mongo)
+	defer func() { tracing.EndMockMatching(span, matched, err) }()
+
 	for {
 		select {
 		case <-ctx.Done():
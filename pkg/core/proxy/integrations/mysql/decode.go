@@ -56,7 +56,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 				binaryPacket, err := encodeToBinary(&packet, header, opr, 0)
 				if err != nil {
 					utils.LogError(logger, err, "Failed to encode to binary")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 					return
 				}
 
@@ -66,7 +66,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 						return
 					}
 					utils.LogError(logger, err, "Failed to write binary packet")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return
 				}
 				matchedIndex := 0
@@ -108,7 +108,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					}
 					// Handle other errors
 					// log.Error("Failed to read bytes from clientConn", zap.Error(err))
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return
 				}
 
@@ -134,7 +134,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 				oprRequest, requestHeader, decodedRequest, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(requestBuffer))
 				if err != nil {
 					utils.LogError(logger, err, "Failed to decode MySQL packet")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 					return
 				}
 				if oprRequest == "COM_QUIT" {
@@ -181,7 +181,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 					responseBuffer, err := pUtil.PassThrough(ctx, logger, clientConn, dstCfg, requestBuffers)
 					if err != nil {
 						utils.LogError(logger, err, "Failed to passthrough the mysql request to the actual database server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return
 					}
 					_, err = clientConn.Write(responseBuffer)
@@ -190,7 +190,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 							return
 						}
 						utils.LogError(logger, err, "Failed to write response to clientConn")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return
 					}
 					continue
@@ -203,7 +203,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 
 				if err != nil {
 					utils.LogError(logger, err, "Failed to encode response to binary")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 					return
 				}
 
@@ -213,7 +213,7 @@ func decodeMySQL(ctx context.Context, logger *zap.Logger, clientConn net.Conn, d
 						return
 					}
 					utils.LogError(logger, err, "Failed to write response to clientConn")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return
 				}
 			}
@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/sync/errgroup"
 
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -52,13 +53,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						return ctx.Err()
 					}
 					utils.LogError(logger, err, "failed to write handshake response to client")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return nil
 				}
 				handshakeResponseFromClient, err := pUtil.ReadBytes(ctx, logger, clientConn)
 				if err != nil {
 					utils.LogError(logger, err, "failed to read handshake response from client")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return nil
 				}
 				_, err = destConn.Write(handshakeResponseFromClient)
@@ -67,7 +68,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						return ctx.Err()
 					}
 					utils.LogError(logger, err, "failed to write handshake response to server")
-					errCh <- err
+					errCh <- integrations.UpstreamError(err)
 					return nil
 				}
 				//TODO: why is this sleep here?
@@ -75,7 +76,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 				okPacket1, err := pUtil.ReadBytes(ctx, logger, destConn)
 				if err != nil {
 					utils.LogError(logger, err, "failed to read packet from server after handshake")
-					errCh <- err
+					errCh <- integrations.UpstreamError(err)
 					return nil
 				}
 				_, err = clientConn.Write(okPacket1)
@@ -84,14 +85,14 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						return ctx.Err()
 					}
 					utils.LogError(logger, err, "failed to write packet to client after handshake")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 					return nil
 				}
 				expectingHandshakeResponse = true
 				oprRequest, requestHeader, mysqlRequest, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(handshakeResponseFromClient))
 				if err != nil {
 					utils.LogError(logger, err, "failed to decode MySQL packet from client")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 					return nil
 				}
 				mysqlRequests = append(mysqlRequests, models.MySQLRequest{
@@ -106,7 +107,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 				oprResponse1, responseHeader1, mysqlResp1, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(handshakeResponseBuffer))
 				if err != nil {
 					utils.LogError(logger, err, "failed to decode MySQL packet from destination")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 					return nil
 				}
 				mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -120,7 +121,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 				oprResponse2, responseHeader2, mysqlResp2, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(okPacket1))
 				if err != nil {
 					utils.LogError(logger, err, "failed to decode MySQL packet from destination")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 					return nil
 				}
 				mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -136,7 +137,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					authSwitchResponse, err := pUtil.ReadBytes(ctx, logger, clientConn)
 					if err != nil {
 						utils.LogError(logger, err, "failed to read AuthSwitchResponse from client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					_, err = destConn.Write(authSwitchResponse)
@@ -145,13 +146,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write AuthSwitchResponse to server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					serverResponse, err := pUtil.ReadBytes(ctx, logger, destConn)
 					if err != nil {
 						utils.LogError(logger, err, "failed to read response from server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					_, err = clientConn.Write(serverResponse)
@@ -160,7 +161,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write response to client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					expectingAuthSwitchResponse = true
@@ -168,7 +169,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					oprRequestFinal, requestHeaderFinal, mysqlRequestFinal, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(authSwitchResponse))
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode MySQL packet from client after full authentication")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					mysqlRequests = append(mysqlRequests, models.MySQLRequest{
@@ -186,7 +187,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					isPluginData = false
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode MySQL packet from destination after full authentication")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -207,7 +208,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						clientResponse, err := pUtil.ReadBytes(ctx, logger, clientConn)
 						if err != nil {
 							utils.LogError(logger, err, "failed to read response from client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return nil
 						}
 						_, err = destConn.Write(clientResponse)
@@ -216,13 +217,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 								return ctx.Err()
 							}
 							utils.LogError(logger, err, "failed to write client's response to server")
-							errCh <- err
+							errCh <- integrations.UpstreamError(err)
 							return nil
 						}
 						finalServerResponse, err := pUtil.ReadBytes(ctx, logger, destConn)
 						if err != nil {
 							utils.LogError(logger, err, "failed to read final response from server")
-							errCh <- err
+							errCh <- integrations.UpstreamError(err)
 							return nil
 						}
 						_, err = clientConn.Write(finalServerResponse)
@@ -231,13 +232,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 								return ctx.Err()
 							}
 							utils.LogError(logger, err, "failed to write final response to client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return nil
 						}
 						oprRequestFinal, requestHeaderFinal, mysqlRequestFinal, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(clientResponse))
 						if err != nil {
 							utils.LogError(logger, err, "failed to decode MySQL packet from client after full authentication")
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return nil
 						}
 						mysqlRequests = append(mysqlRequests, models.MySQLRequest{
@@ -253,7 +254,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						isPluginData = false
 						if err != nil {
 							utils.LogError(logger, err, "failed to decode MySQL packet from destination after full authentication")
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return nil
 						}
 						mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -267,7 +268,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						clientResponse1, err := pUtil.ReadBytes(ctx, logger, clientConn)
 						if err != nil {
 							utils.LogError(logger, err, "failed to read response from client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return nil
 						}
 						_, err = destConn.Write(clientResponse1)
@@ -276,13 +277,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 								return ctx.Err()
 							}
 							utils.LogError(logger, err, "failed to write client's response to server")
-							errCh <- err
+							errCh <- integrations.UpstreamError(err)
 							return nil
 						}
 						finalServerResponse1, err := pUtil.ReadBytes(ctx, logger, destConn)
 						if err != nil {
 							utils.LogError(logger, err, "failed to read final response from server")
-							errCh <- err
+							errCh <- integrations.UpstreamError(err)
 							return nil
 						}
 						_, err = clientConn.Write(finalServerResponse1)
@@ -291,13 +292,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 								return ctx.Err()
 							}
 							utils.LogError(logger, err, "failed to write final response to client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return nil
 						}
 						finalServerResponsetype1, finalServerResponseHeader1, mysqlRespfinalServerResponse, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(finalServerResponse1))
 						if err != nil {
 							utils.LogError(logger, err, "failed to decode MySQL packet from final server response")
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return nil
 						}
 						mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -311,7 +312,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						oprRequestFinal1, requestHeaderFinal1, err := decodeEncryptPassword(clientResponse1)
 						if err != nil {
 							utils.LogError(logger, err, "failed to decode MySQL packet from client after full authentication")
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return nil
 						}
 						type DataMessage struct {
@@ -332,7 +333,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 						finalServerResponse, err := pUtil.ReadBytes(ctx, logger, destConn)
 						if err != nil {
 							utils.LogError(logger, err, "failed to read final response from server")
-							errCh <- err
+							errCh <- integrations.UpstreamError(err)
 							return nil
 						}
 						_, err = clientConn.Write(finalServerResponse)
@@ -341,14 +342,14 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 								return ctx.Err()
 							}
 							utils.LogError(logger, err, "failed to write final response to client")
-							errCh <- err
+							errCh <- integrations.ClientError(err)
 							return nil
 						}
 						oprResponseFinal, responseHeaderFinal, mysqlRespFinal, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(finalServerResponse))
 						isPluginData = false
 						if err != nil {
 							utils.LogError(logger, err, "failed to decode MySQL packet from destination after full authentication")
-							errCh <- err
+							errCh <- integrations.ParseError(err)
 							return nil
 						}
 						mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -373,7 +374,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					clientResponse, err := pUtil.ReadBytes(ctx, logger, clientConn)
 					if err != nil {
 						utils.LogError(logger, err, "failed to read response from client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					_, err = destConn.Write(clientResponse)
@@ -382,13 +383,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write client's response to server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					finalServerResponse, err := pUtil.ReadBytes(ctx, logger, destConn)
 					if err != nil {
 						utils.LogError(logger, err, "failed to read final response from server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					_, err = clientConn.Write(finalServerResponse)
@@ -397,13 +398,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write final response to client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					oprRequestFinal, requestHeaderFinal, mysqlRequestFinal, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(clientResponse))
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode MySQL packet from client after full authentication")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					mysqlRequests = append(mysqlRequests, models.MySQLRequest{
@@ -419,7 +420,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					isPluginData = false
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode MySQL packet from destination after full authentication")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -433,7 +434,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					clientResponse1, err := pUtil.ReadBytes(ctx, logger, clientConn)
 					if err != nil {
 						utils.LogError(logger, err, "failed to read response from client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					_, err = destConn.Write(clientResponse1)
@@ -442,13 +443,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write client's response to server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					finalServerResponse1, err := pUtil.ReadBytes(ctx, logger, destConn)
 					if err != nil {
 						utils.LogError(logger, err, "failed to read final response from server")
-						errCh <- err
+						errCh <- integrations.UpstreamError(err)
 						return nil
 					}
 					_, err = clientConn.Write(finalServerResponse1)
@@ -457,13 +458,13 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 							return ctx.Err()
 						}
 						utils.LogError(logger, err, "failed to write final response to client")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 						return nil
 					}
 					finalServerResponsetype1, finalServerResponseHeader1, mysqlRespfinalServerResponse, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(finalServerResponse1))
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode MySQL packet from final server response")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					mysqlResponses = append(mysqlResponses, models.MySQLResponse{
@@ -477,7 +478,7 @@ func encodeMySQL(ctx context.Context, logger *zap.Logger, clientConn, destConn n
 					oprRequestFinal1, requestHeaderFinal1, err := decodeEncryptPassword(clientResponse1)
 					if err != nil {
 						utils.LogError(logger, err, "failed to decode MySQL packet from client after full authentication")
-						errCh <- err
+						errCh <- integrations.ParseError(err)
 						return nil
 					}
 					type DataMessage struct {
@@ -545,7 +546,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 				queryBuffer, err = pUtil.ReadBytes(ctx, logger, clientConn)
 				if err != nil {
 					utils.LogError(logger, err, "failed to read query from the mysql client")
-					return err
+					return integrations.ClientError(err)
 				}
 			}
 			if len(queryBuffer) == 0 {
@@ -554,7 +555,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 			operation, requestHeader, mysqlRequest, err := DecodeMySQLPacket(logger, bytesToMySQLPacket(queryBuffer))
 			if err != nil {
 				utils.LogError(logger, err, "failed to decode the MySQL packet from the client")
-				return err
+				return integrations.ParseError(err)
 			}
 			mysqlRequests = append([]models.MySQLRequest{}, models.MySQLRequest{
 				Header: &models.MySQLPacketHeader{
@@ -570,7 +571,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 					return ctx.Err()
 				}
 				utils.LogError(logger, err, "failed to write query to mysql server")
-				return err
+				return integrations.UpstreamError(err)
 			}
 			if res == 9 {
 				return nil
@@ -578,7 +579,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 			queryResponse, err := pUtil.ReadBytes(ctx, logger, destConn)
 			if err != nil {
 				utils.LogError(logger, err, "failed to read query response from mysql server")
-				return err
+				return integrations.UpstreamError(err)
 			}
 			_, err = clientConn.Write(queryResponse)
 			if err != nil {
@@ -586,7 +587,7 @@ func handleClientQueries(ctx context.Context, logger *zap.Logger, initialBuffer
 					return ctx.Err()
 				}
 				utils.LogError(logger, err, "failed to write query response to mysql client")
-				return err
+				return integrations.ClientError(err)
 			}
 			if len(queryResponse) == 0 {
 				break
@@ -6,9 +6,13 @@ import (
 
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 )
 
-func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest, configMocks, tcsMocks []*models.Mock, mockDb integrations.MockMemDb) (*models.MySQLResponse, int, string, error) {
+func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest, configMocks, tcsMocks []*models.Mock, mockDb integrations.MockMemDb) (resp *models.MySQLResponse, _ int, _ string, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.SQL)
+	defer func() { tracing.EndMockMatching(span, resp != nil, err) }()
+
 	//TODO: any reason to write the similar code twice?
 	allMocks := append([]*models.Mock(nil), configMocks...)
 	allMocks = append(allMocks, tcsMocks...)
@@ -45,7 +49,7 @@ func matchRequestWithMock(ctx context.Context, mysqlRequest models.MySQLRequest,
 	}
 
 	if bestMatch == nil {
-		return nil, -1, "", fmt.Errorf("no matching mock found")
+		return nil, -1, "", integrations.MissingMockError(fmt.Errorf("no matching mock found"))
 	}
 
 	if mockType == "config" {
@@ -17,7 +17,7 @@ import (
 	"go.uber.org/zap"
 )
 
-func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, _ models.OutgoingOptions) error {
+func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clientConn net.Conn, dstCfg *integrations.ConditionalDstCfg, mockDb integrations.MockMemDb, opts models.OutgoingOptions) error {
 	pgRequests := [][]byte{reqBuf}
 	errCh := make(chan error, 1)
 
@@ -44,7 +44,7 @@ func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 							errCh <- err
 						}
 						logger.Debug("failed to read the request message in proxy for postgres dependency")
-						errCh <- err
+						errCh <- integrations.ClientError(err)
 					}
 				}
 				if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
@@ -64,6 +64,11 @@ func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 				return
 			}
 
+			if !matched && opts.StrictMocking {
+				errCh <- integrations.MissingMockError(fmt.Errorf("unmatched postgres request of %d packets", len(pgRequests)))
+				return
+			}
+
 			if !matched {
 				logger.Debug("MISMATCHED REQ is" + string(pgRequests[0]))
 				_, err = pUtil.PassThrough(ctx, logger, clientConn, dstCfg, pgRequests)
@@ -80,12 +85,12 @@ func decodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 				}
 				if err != nil {
 					utils.LogError(logger, err, "failed to decode the response message in proxy for postgres dependency")
-					errCh <- err
+					errCh <- integrations.ParseError(err)
 				}
 				_, err = clientConn.Write(encoded)
 				if err != nil {
 					utils.LogError(logger, err, "failed to write the response message to the client application")
-					errCh <- err
+					errCh <- integrations.ClientError(err)
 				}
 			}
 			// Clear the buffer for the next dependency call
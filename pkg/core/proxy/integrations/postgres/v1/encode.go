@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/jackc/pgproto3/v2"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	pUtil "go.keploy.io/server/v2/pkg/core/proxy/util"
 	"go.keploy.io/server/v2/pkg/models"
@@ -68,13 +69,14 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 	_, err = destConn.Write(reqBuf)
 	if err != nil {
 		utils.LogError(logger, err, "failed to write request message to the destination server")
-		return err
+		return integrations.UpstreamError(err)
 	}
 	var pgResponses []models.Frontend
 
 	clientBuffChan := make(chan []byte)
 	destBuffChan := make(chan []byte)
-	errChan := make(chan error, 1)
+	clientErrChan := make(chan error, 1)
+	destErrChan := make(chan error, 1)
 
 	//get the error group from the context
 	g := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
@@ -83,7 +85,7 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 	g.Go(func() error {
 		defer pUtil.Recover(logger, clientConn, destConn)
 		defer close(clientBuffChan)
-		pUtil.ReadBuffConn(ctx, logger, clientConn, clientBuffChan, errChan)
+		pUtil.ReadBuffConn(ctx, logger, clientConn, clientBuffChan, clientErrChan)
 		return nil
 	})
 
@@ -91,7 +93,7 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 	g.Go(func() error {
 		defer pUtil.Recover(logger, clientConn, destConn)
 		defer close(destBuffChan)
-		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, errChan)
+		pUtil.ReadBuffConn(ctx, logger, destConn, destBuffChan, destErrChan)
 		return nil
 	})
 
@@ -101,7 +103,8 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 		if err != nil {
 			logger.Info("error group is returning an error", zap.Error(err))
 		}
-		close(errChan)
+		close(clientErrChan)
+		close(destErrChan)
 	}()
 
 	prevChunkWasReq := false
@@ -138,7 +141,7 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 			_, err := destConn.Write(buffer)
 			if err != nil {
 				utils.LogError(logger, err, "failed to write request message to the destination server")
-				return err
+				return integrations.UpstreamError(err)
 			}
 
 			logger.Debug("the iteration for the pg request ends with no of pgReqs:" + strconv.Itoa(len(pgRequests)) + " and pgResps: " + strconv.Itoa(len(pgResponses)))
@@ -270,7 +273,7 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 			_, err := clientConn.Write(buffer)
 			if err != nil {
 				utils.LogError(logger, err, "failed to write response message to the client")
-				return err
+				return integrations.ClientError(err)
 			}
 
 			bufStr := util.EncodeBase64(buffer)
@@ -390,11 +393,16 @@ func encodePostgres(ctx context.Context, logger *zap.Logger, reqBuf []byte, clie
 
 			logger.Debug("the iteration for the postgres response ends with no of postgresReqs:" + strconv.Itoa(len(pgRequests)) + " and pgResps: " + strconv.Itoa(len(pgResponses)))
 			prevChunkWasReq = false
-		case err := <-errChan:
-			if err == io.EOF {
+		case err, ok := <-clientErrChan:
+			if !ok || err == io.EOF {
 				return nil
 			}
-			return err
+			return integrations.ClientError(err)
+		case err, ok := <-destErrChan:
+			if !ok || err == io.EOF {
+				return nil
+			}
+			return integrations.UpstreamError(err)
 		}
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
 	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 	"go.uber.org/zap"
 )
 
@@ -62,7 +63,10 @@ func IsValuePresent(connectionid string, value string) bool {
 	return false
 }
 
-func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers [][]byte, mockDb integrations.MockMemDb) (bool, []models.Frontend, error) {
+func matchingReadablePG(ctx context.Context, logger *zap.Logger, requestBuffers [][]byte, mockDb integrations.MockMemDb) (matched bool, resp []models.Frontend, err error) {
+	ctx, span := tracing.StartMockMatching(ctx, models.Postgres)
+	defer func() { tracing.EndMockMatching(span, matched, err) }()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -0,0 +1,171 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// mockIndex indexes the unfiltered http mocks by their method, host, url path and body, and by their method and url
+// path to fall back to, so that matching a request goes through the mocks of its endpoint instead of all the mocks
+// of the test set.
+type mockIndex struct {
+	mutex sync.RWMutex
+	// http holds the mocks of each endpoint, and exact those of each endpoint, host and body. The buckets are kept
+	// in the order of the unfiltered mocks, and are replaced instead of modified so that they are returned as is.
+	http  map[string][]*models.Mock
+	exact map[string][]*models.Mock
+}
+
+func newMockIndex() *mockIndex {
+	return &mockIndex{
+		http:  make(map[string][]*models.Mock),
+		exact: make(map[string][]*models.Mock),
+	}
+}
+
+func httpKey(method, path string) string {
+	return method + " " + path
+}
+
+// exactKey returns the key of the endpoint, host and body, false if the host isn't known.
+func exactKey(method, host, path string, body []byte) (string, bool) {
+	if host == "" {
+		return "", false
+	}
+	sum := sha256.Sum256(body)
+	return httpKey(method, path) + " " + strings.ToLower(host) + " " + string(sum[:]), true
+}
+
+// httpMockKeys returns the keys of the endpoint of the http mock and of its host and body, the latter is empty if
+// the host wasn't recorded. It returns false if the mock is not indexed.
+func httpMockKeys(mock *models.Mock) (string, string, bool) {
+	if mock == nil || mock.Kind != models.HTTP || mock.Spec.HTTPReq == nil {
+		return "", "", false
+	}
+	parsedURL, err := url.Parse(mock.Spec.HTTPReq.URL)
+	if err != nil {
+		return "", "", false
+	}
+	method := string(mock.Spec.HTTPReq.Method)
+	host := parsedURL.Host
+	if host == "" {
+		host = mock.Spec.Metadata["host"]
+	}
+	exact, _ := exactKey(method, host, parsedURL.Path, []byte(mock.Spec.HTTPReq.Body))
+	return httpKey(method, parsedURL.Path), exact, true
+}
+
+// mockID identifies the mock among the mocks of all the test sets, e.g. those served together by the mock server.
+func mockID(mock *models.Mock) string {
+	return mock.TestModeInfo.TestSetID + "/" + mock.Name
+}
+
+func (idx *mockIndex) reset(mocks []*models.Mock) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.http = make(map[string][]*models.Mock)
+	idx.exact = make(map[string][]*models.Mock)
+	for _, mock := range mocks {
+		key, exact, ok := httpMockKeys(mock)
+		if !ok {
+			continue
+		}
+		idx.http[key] = append(idx.http[key], mock)
+		if exact != "" {
+			idx.exact[exact] = append(idx.exact[exact], mock)
+		}
+	}
+	// the buckets are sorted once here, the mocks added later are inserted at their place
+	for _, buckets := range []map[string][]*models.Mock{idx.http, idx.exact} {
+		for _, mocks := range buckets {
+			sort.SliceStable(mocks, func(i, j int) bool {
+				return customComparator(mocks[i].TestModeInfo, mocks[j].TestModeInfo) < 0
+			})
+		}
+	}
+}
+
+func (idx *mockIndex) update(old *models.Mock, new *models.Mock) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(old)
+	idx.add(new)
+}
+
+func (idx *mockIndex) delete(mock *models.Mock) {
+	idx.mutex.Lock()
+	defer idx.mutex.Unlock()
+	idx.remove(mock)
+}
+
+// add and remove are called with the mutex held.
+func (idx *mockIndex) add(mock *models.Mock) {
+	key, exact, ok := httpMockKeys(mock)
+	if !ok {
+		return
+	}
+	insertMock(idx.http, key, mock)
+	if exact != "" {
+		insertMock(idx.exact, exact, mock)
+	}
+}
+
+func (idx *mockIndex) remove(mock *models.Mock) {
+	key, exact, ok := httpMockKeys(mock)
+	if !ok {
+		return
+	}
+	withoutMock(idx.http, key, mockID(mock))
+	if exact != "" {
+		withoutMock(idx.exact, exact, mockID(mock))
+	}
+}
+
+// insertMock replaces the bucket of the key with one having the mock at its place in the order of the unfiltered
+// mocks, instead of any mock with the same id.
+func insertMock(buckets map[string][]*models.Mock, key string, mock *models.Mock) {
+	withoutMock(buckets, key, mockID(mock))
+	bucket := buckets[key]
+	i := sort.Search(len(bucket), func(i int) bool {
+		return customComparator(bucket[i].TestModeInfo, mock.TestModeInfo) > 0
+	})
+	mocks := make([]*models.Mock, 0, len(bucket)+1)
+	mocks = append(mocks, bucket[:i]...)
+	mocks = append(mocks, mock)
+	buckets[key] = append(mocks, bucket[i:]...)
+}
+
+// withoutMock replaces the bucket of the key with one without the mock of the id.
+func withoutMock(buckets map[string][]*models.Mock, key string, id string) {
+	bucket := buckets[key]
+	for i, mock := range bucket {
+		if mockID(mock) != id {
+			continue
+		}
+		if len(bucket) == 1 {
+			delete(buckets, key)
+			return
+		}
+		mocks := make([]*models.Mock, 0, len(bucket)-1)
+		mocks = append(mocks, bucket[:i]...)
+		buckets[key] = append(mocks, bucket[i+1:]...)
+		return
+	}
+}
+
+// httpMocks returns the mocks of the endpoint, host and body, and those of the endpoint to fall back to, in the
+// same order as the unfiltered mocks. The returned slices must not be modified.
+func (idx *mockIndex) httpMocks(method, host, path string, body []byte) ([]*models.Mock, []*models.Mock) {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	var exact []*models.Mock
+	if key, ok := exactKey(method, host, path, body); ok {
+		exact = idx.exact[key]
+	}
+	return exact, idx.http[httpKey(method, path)]
+}
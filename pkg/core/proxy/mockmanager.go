@@ -1,11 +1,15 @@
 package proxy
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"net/http"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"go.keploy.io/server/v2/pkg/models"
 	"go.uber.org/zap"
@@ -14,16 +18,34 @@ import (
 type MockManager struct {
 	filtered      *TreeDb
 	unfiltered    *TreeDb
+	index         *mockIndex
 	logger        *zap.Logger
 	consumedMocks sync.Map
+	faultsMutex   sync.Mutex
+	faults        []models.InjectedFault
+	errorsMutex   sync.Mutex
+	errors        []models.OutgoingError
+	// destinations are the destinations the hosts of the mocks were recorded with
+	destinations *destinationAudit
+	// cookies are the cookies set by the http mock responses of the testcase
+	cookies *cookieJar
+	// shared are the mocks shared by the test sets, indexed by sharedIndex
+	shared      []*models.Mock
+	sharedIndex *mockIndex
+	// onEvent is called with the outcome of each outgoing call, nil if nobody listens
+	onEvent func(models.MockEvent)
 }
 
 func NewMockManager(filtered, unfiltered *TreeDb, logger *zap.Logger) *MockManager {
 	return &MockManager{
 		filtered:      filtered,
 		unfiltered:    unfiltered,
+		index:         newMockIndex(),
 		logger:        logger,
 		consumedMocks: sync.Map{},
+		destinations:  newDestinationAudit(),
+		cookies:       newCookieJar(),
+		sharedIndex:   newMockIndex(),
 	}
 }
 
@@ -43,6 +65,19 @@ func (m *MockManager) SetUnFilteredMocks(mocks []*models.Mock) {
 		mock.TestModeInfo.ID = index
 		m.unfiltered.insert(mock.TestModeInfo, mock)
 	}
+	m.index.reset(mocks)
+}
+
+// SetSharedMocks sets the mocks shared by the test sets. They are named after their directory so that they aren't
+// taken for the mocks of the test set, and are never consumed.
+func (m *MockManager) SetSharedMocks(mocks []*models.Mock) {
+	for _, mock := range mocks {
+		mock.Name = models.SharedMocks + "/" + mock.Name
+		mock.TestModeInfo.IsFiltered = false
+		mock.TestModeInfo.SortOrder = math.MaxInt
+	}
+	m.shared = mocks
+	m.sharedIndex.reset(mocks)
 }
 
 func (m *MockManager) GetFilteredMocks() ([]*models.Mock, error) {
@@ -68,12 +103,28 @@ func (m *MockManager) GetUnFilteredMocks() ([]*models.Mock, error) {
 			return nil, fmt.Errorf("expected mock instance, got %v", m)
 		}
 	}
-	return configMocks, nil
+	// the shared mocks come after the ones of the test set
+	return append(configMocks, m.shared...), nil
+}
+
+// GetHTTPMocks returns the unfiltered http mocks recorded for the given method, host, url path and body, and those
+// recorded for the method and url path to fall back to.
+func (m *MockManager) GetHTTPMocks(method, host, path string, body []byte) ([]*models.Mock, []*models.Mock, error) {
+	exact, mocks := m.index.httpMocks(method, host, path, body)
+	return exact, mocks, nil
+}
+
+// GetSharedHTTPMocks returns the shared http mocks recorded for the given method, host, url path and body, and those
+// recorded for the method and url path to fall back to.
+func (m *MockManager) GetSharedHTTPMocks(method, host, path string, body []byte) ([]*models.Mock, []*models.Mock, error) {
+	exact, mocks := m.sharedIndex.httpMocks(method, host, path, body)
+	return exact, mocks, nil
 }
 
 func (m *MockManager) UpdateUnFilteredMock(old *models.Mock, new *models.Mock) bool {
 	updated := m.unfiltered.update(old.TestModeInfo, new.TestModeInfo, new)
 	if updated {
+		m.index.update(old, new)
 		// mark the unfiltered mock as used for the current simulated test-case
 		go func() {
 			if err := m.FlagMockAsUsed(old); err != nil {
@@ -84,14 +135,76 @@ func (m *MockManager) UpdateUnFilteredMock(old *models.Mock, new *models.Mock) b
 	return updated
 }
 
+// SetEventHandler sets the function called with the outcome of each outgoing call, nil to stop calling it.
+func (m *MockManager) SetEventHandler(onEvent func(models.MockEvent)) {
+	m.onEvent = onEvent
+}
+
 func (m *MockManager) FlagMockAsUsed(mock *models.Mock) error {
 	if mock == nil {
 		return fmt.Errorf("mock is empty")
 	}
 	m.consumedMocks.Store(mock.Name, true)
+	if m.onEvent != nil {
+		m.onEvent(models.MockEvent{Time: time.Now(), Hit: true, Mock: mock.Name, Kind: mock.Kind})
+	}
 	return nil
 }
 
+// FlagMockAsFaulted records the fault injected in place of the response of the mock.
+func (m *MockManager) FlagMockAsFaulted(mock *models.Mock, fault models.FaultKind) {
+	if mock == nil {
+		return
+	}
+	m.faultsMutex.Lock()
+	defer m.faultsMutex.Unlock()
+	m.faults = append(m.faults, models.InjectedFault{
+		Mock:  mock.Name,
+		Kind:  mock.Kind,
+		Fault: fault,
+	})
+}
+
+// GetInjectedFaults returns the faults injected since the last call.
+// CookieJar returns the cookies set by the http mock responses of the testcase.
+func (m *MockManager) CookieJar() http.CookieJar {
+	return m.cookies
+}
+
+func (m *MockManager) GetInjectedFaults() []models.InjectedFault {
+	m.faultsMutex.Lock()
+	defer m.faultsMutex.Unlock()
+	faults := m.faults
+	m.faults = nil
+	return faults
+}
+
+// FlagProxyError records the error of an outgoing call, if it is a models.ProxyError.
+func (m *MockManager) FlagProxyError(err error) {
+	var proxyErr models.ProxyError
+	if !errors.As(err, &proxyErr) {
+		return
+	}
+	if m.onEvent != nil {
+		m.onEvent(models.MockEvent{Time: time.Now(), Error: proxyErr.Error()})
+	}
+	m.errorsMutex.Lock()
+	defer m.errorsMutex.Unlock()
+	m.errors = append(m.errors, models.OutgoingError{
+		Type:    proxyErr.ProxyErrorType,
+		Message: proxyErr.Error(),
+	})
+}
+
+// GetProxyErrors returns the errors of the outgoing calls since the last call.
+func (m *MockManager) GetProxyErrors() []models.OutgoingError {
+	m.errorsMutex.Lock()
+	defer m.errorsMutex.Unlock()
+	errs := m.errors
+	m.errors = nil
+	return errs
+}
+
 func (m *MockManager) DeleteFilteredMock(mock *models.Mock) bool {
 	isDeleted := m.filtered.delete(mock.TestModeInfo)
 	if isDeleted {
@@ -107,6 +220,7 @@ func (m *MockManager) DeleteFilteredMock(mock *models.Mock) bool {
 func (m *MockManager) DeleteUnFilteredMock(mock *models.Mock) bool {
 	isDeleted := m.unfiltered.delete(mock.TestModeInfo)
 	if isDeleted {
+		m.index.delete(mock)
 		go func() {
 			if err := m.FlagMockAsUsed(mock); err != nil {
 				m.logger.Error("failed to flag mock as used", zap.Error(err))
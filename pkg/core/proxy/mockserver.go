@@ -0,0 +1,128 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations"
+	"go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// MockServer serves the mocks on a port, without an app, hooks or redirection, to the clients connecting to it
+// directly, e.g. the dev server of a frontend or the integration tests of another repository. The parser of a
+// connection is picked from its first message like in the proxy, so only the protocols whose clients send the
+// first message are served, e.g. not mysql. The mocks are never consumed, so that they are served to every client.
+type MockServer struct {
+	logger       *zap.Logger
+	integrations map[string]integrations.Integrations
+	mocks        *MockManager
+	opts         models.OutgoingOptions
+}
+
+func NewMockServer(logger *zap.Logger, opts models.OutgoingOptions) *MockServer {
+	s := &MockServer{
+		logger:       logger,
+		integrations: make(map[string]integrations.Integrations),
+		mocks:        NewMockManager(NewTreeDb(customComparator), NewTreeDb(customComparator), logger),
+		opts:         opts,
+	}
+	for parserType, parser := range integrations.Registered {
+		s.integrations[parserType] = parser(logger)
+	}
+	return s
+}
+
+// SetMocks sets the mocks served, they are all kept as unfiltered mocks which are reused across the calls.
+func (s *MockServer) SetMocks(mocks []*models.Mock) {
+	s.mocks.SetUnFilteredMocks(mocks)
+}
+
+// Serve accepts the connections on the address until the context is cancelled.
+func (s *MockServer) Serve(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.logger.Info("serving the mocks", zap.String("address", listener.Addr().String()))
+
+	connErrGrp, connCtx := errgroup.WithContext(ctx)
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			utils.LogError(s.logger, err, "failed to accept the connection")
+			continue
+		}
+		connErrGrp.Go(func() error {
+			defer utils.Recover(s.logger)
+			s.handle(connCtx, conn)
+			return nil
+		})
+	}
+	return connErrGrp.Wait()
+}
+
+// handle serves the mocks on the connection with the parser of its first message, the generic one if none matches.
+func (s *MockServer) handle(ctx context.Context, conn net.Conn) {
+	clientConnID := util.GetNextID()
+	logger := s.logger.With(zap.Any("Client IP Address", conn.RemoteAddr().String()), zap.Any("Client ConnectionID", clientConnID))
+
+	parserErrGrp, parserCtx := errgroup.WithContext(ctx)
+	parserCtx = context.WithValue(parserCtx, models.ErrGroupKey, parserErrGrp)
+	parserCtx = context.WithValue(parserCtx, models.ClientConnectionIDKey, fmt.Sprint(clientConnID))
+	parserCtx = context.WithValue(parserCtx, models.DestConnectionIDKey, fmt.Sprint(util.GetNextID()))
+	parserCtx, parserCtxCancel := context.WithCancel(parserCtx)
+	defer func() {
+		parserCtxCancel()
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			utils.LogError(logger, err, "failed to close the client connection")
+		}
+		if err := parserErrGrp.Wait(); err != nil {
+			utils.LogError(logger, err, "failed to handle the parser cleanUp")
+		}
+	}()
+
+	initialBuf, err := util.ReadInitialBuf(parserCtx, logger, conn)
+	if err != nil {
+		logger.Debug("failed to read the first message of the connection", zap.Error(err))
+		return
+	}
+	conn = &Conn{
+		Conn:   conn,
+		r:      io.MultiReader(bytes.NewReader(initialBuf), conn),
+		logger: logger,
+	}
+
+	parser := s.integrations["generic"]
+	for parserType, p := range s.integrations {
+		if parserType != "generic" && p.MatchType(parserCtx, initialBuf) {
+			parser = p
+			break
+		}
+	}
+	addr := conn.LocalAddr().(*net.TCPAddr)
+	dstCfg := &integrations.ConditionalDstCfg{Addr: addr.String(), Port: uint(addr.Port)}
+	err = parser.MockOutgoing(parserCtx, conn, dstCfg, s.mocks, s.opts)
+	var proxyErr models.ProxyError
+	switch {
+	case err == nil || err == io.EOF:
+	case errors.As(err, &proxyErr) && proxyErr.ProxyErrorType == models.ErrClientClosed:
+		logger.Debug("the client closed the conn while serving the mocks", zap.Error(err))
+	default:
+		utils.LogError(logger, err, "failed to serve the mocks")
+	}
+}
@@ -0,0 +1,204 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/core/proxy/util"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// RecordTLS records the mocks of the TLS connections of the app from their plaintext captured by the hooks. The
+// plaintext of each connection is replayed to its parser over pipes as if the parser proxied it, so the TLS calls
+// are recorded by the same parsers as the plaintext ones, while the proxy passes the encrypted bytes through.
+func (p *Proxy) RecordTLS(ctx context.Context, id uint64, data <-chan *core.TLSData) error {
+	rule, ok := p.sessions.Get(id)
+	if !ok {
+		return fmt.Errorf("no session %d to record the TLS connections of", id)
+	}
+	rule.PlainTLS = true
+
+	go func() {
+		defer utils.Recover(p.logger)
+		streams := map[tlsStreamKey]*tlsStream{}
+		defer func() {
+			for _, s := range streams {
+				s.close()
+			}
+		}()
+		for d := range data {
+			key := tlsStreamKey{pid: d.Pid, conn: d.Conn}
+			s, ok := streams[key]
+			if d.Closed {
+				if ok {
+					s.close()
+					delete(streams, key)
+				}
+				continue
+			}
+			if !ok {
+				s = p.newTLSStream(ctx, rule, d)
+				streams[key] = s
+			}
+			s.feed(d)
+		}
+	}()
+	return nil
+}
+
+type tlsStreamKey struct {
+	pid  uint32
+	conn uint64
+}
+
+// tlsStream is a TLS connection of the app replayed to its parser, the plaintext written by the app is sent to the
+// parser as coming from the app, and the plaintext read by the app as coming from the server.
+type tlsStream struct {
+	writes chan []byte
+	reads  chan []byte
+}
+
+func (p *Proxy) newTLSStream(ctx context.Context, rule *core.Session, first *core.TLSData) *tlsStream {
+	s := &tlsStream{
+		writes: make(chan []byte, 1024),
+		reads:  make(chan []byte, 1024),
+	}
+	logger := p.logger.With(zap.Uint32("pid", first.Pid), zap.Uint64("TLS connection", first.Conn))
+
+	// the destination of the connection isn't captured, it is assumed to be on the port of https
+	srcConn := newTLSPipeConn(s.writes, &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	dstConn := newTLSPipeConn(s.reads, &net.TCPAddr{IP: net.IPv4zero, Port: 443})
+
+	go func() {
+		defer utils.Recover(logger)
+		defer srcConn.Close()
+		defer dstConn.Close()
+
+		parser := p.Integrations["generic"]
+		if !first.Read {
+			for _, candidate := range p.Integrations {
+				if candidate.MatchType(ctx, first.Data) {
+					parser = candidate
+					break
+				}
+			}
+		}
+		parserErrGrp, parserCtx := errgroup.WithContext(ctx)
+		parserCtx = context.WithValue(parserCtx, models.ErrGroupKey, parserErrGrp)
+		parserCtx = context.WithValue(parserCtx, models.ClientConnectionIDKey, fmt.Sprint(util.GetNextID()))
+		parserCtx = context.WithValue(parserCtx, models.DestConnectionIDKey, fmt.Sprint(util.GetNextID()))
+		parserCtx, cancel := context.WithCancel(parserCtx)
+		defer cancel()
+
+		err := parser.RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
+		if err != nil && !errors.Is(err, io.EOF) {
+			utils.LogError(logger, err, "failed to record the TLS connection from its plaintext")
+		}
+		cancel()
+		if err := parserErrGrp.Wait(); err != nil {
+			logger.Debug("failed to stop the parser of the TLS connection", zap.Error(err))
+		}
+	}()
+	return s
+}
+
+// tlsPipeConn is the connection of the parser to the app or the server, reading the plaintext captured from them.
+// What the parser writes to it was already sent over the real connection and is dropped. It has the address of a
+// TCP connection, the parsers read the destination port from it e.g. to match the bypass rules.
+type tlsPipeConn struct {
+	r    *io.PipeReader
+	addr *net.TCPAddr
+}
+
+func newTLSPipeConn(chunks <-chan []byte, addr *net.TCPAddr) *tlsPipeConn {
+	r, w := io.Pipe()
+	go func() {
+		for chunk := range chunks {
+			// the rest of the connection is drained once the parser is done with it
+			_, _ = w.Write(chunk)
+		}
+		_ = w.Close()
+	}()
+	return &tlsPipeConn{r: r, addr: addr}
+}
+
+func (c *tlsPipeConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *tlsPipeConn) Write(b []byte) (int, error) {
+	return len(b), nil
+}
+
+func (c *tlsPipeConn) Close() error {
+	return c.r.Close()
+}
+
+func (c *tlsPipeConn) LocalAddr() net.Addr {
+	return c.addr
+}
+
+func (c *tlsPipeConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+func (c *tlsPipeConn) SetDeadline(_ time.Time) error {
+	return nil
+}
+
+func (c *tlsPipeConn) SetReadDeadline(_ time.Time) error {
+	return nil
+}
+
+func (c *tlsPipeConn) SetWriteDeadline(_ time.Time) error {
+	return nil
+}
+
+// feed sends the plaintext to the parser in the order it was written and read by the app.
+func (s *tlsStream) feed(d *core.TLSData) {
+	if d.Read {
+		s.reads <- d.Data
+		return
+	}
+	s.writes <- d.Data
+}
+
+// close ends the connection once the plaintext sent to the parser was read.
+func (s *tlsStream) close() {
+	close(s.writes)
+	close(s.reads)
+}
+
+// passThrough relays the bytes of the connection to its destination unchanged, for the TLS connections whose
+// plaintext is recorded from the hooks and the connections of the processes outside the app.
+func (p *Proxy) passThrough(ctx context.Context, logger *zap.Logger, srcConn net.Conn, dstAddr string) error {
+	dstConn, err := net.Dial("tcp", dstAddr)
+	if err != nil {
+		utils.LogError(logger, err, "failed to dial the conn to destination server", zap.Any("server address", dstAddr))
+		return err
+	}
+	defer dstConn.Close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = srcConn.Close()
+		_ = dstConn.Close()
+	})
+	defer stop()
+
+	done := make(chan struct{}, 2)
+	relay := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go relay(dstConn, srcConn)
+	go relay(srcConn, dstConn)
+	<-done
+	return nil
+}
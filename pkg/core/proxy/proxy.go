@@ -11,6 +11,7 @@ import (
 	"net"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/errgroup"
@@ -33,6 +34,22 @@ type Proxy struct {
 	IP6     string
 	Port    uint32
 	DNSPort uint32
+	// redirect is how the calls of the app are redirected to the proxy, see config.Config.Redirect
+	redirect string
+	// udpRules are the ports whose UDP datagrams are relayed by the proxy
+	udpRules []config.UDPRule
+	// udpCgroup is the cgroup v2 of the apps whose UDP datagrams are relayed, empty if they aren't
+	udpCgroup string
+	// drainTimeout is how long the connections in flight are waited for when the proxy stops
+	drainTimeout time.Duration
+	inFlight     atomic.Int64
+	// connSlots bounds the connections handled at once, while all the slots are taken no connection is accepted so
+	// that the new ones wait in the backlog of the listener. It is nil if the connections aren't bounded.
+	connSlots chan struct{}
+	// peakInFlight is the most connections handled at once, logged when the proxy stops
+	peakInFlight atomic.Int64
+	// breaker passes through the connections to the destinations failing or busy in record mode
+	breaker *hostBreaker
 
 	DestInfo     core.DestInfo
 	Integrations map[string]integrations.Integrations
@@ -55,12 +72,21 @@ type Proxy struct {
 }
 
 func New(logger *zap.Logger, info core.DestInfo, opts config.Config) *Proxy {
+	var connSlots chan struct{}
+	if opts.ProxyMaxConnections > 0 {
+		connSlots = make(chan struct{}, opts.ProxyMaxConnections)
+	}
 	return &Proxy{
 		logger:       logger,
 		Port:         opts.ProxyPort, // default: 16789
 		DNSPort:      opts.DNSPort,   // default: 26789
 		IP4:          "127.0.0.1",    // default: "127.0.0.1" <-> (2130706433)
 		IP6:          "::1",          //default: "::1" <-> ([4]uint32{0000, 0000, 0000, 0001})
+		redirect:     opts.Redirect,
+		udpRules:     opts.UDP,
+		drainTimeout: opts.ProxyDrainTimeout,
+		connSlots:    connSlots,
+		breaker:      newHostBreaker(opts.Record),
 		ipMutex:      &sync.Mutex{},
 		connMutex:    &sync.Mutex{},
 		DestInfo:     info,
@@ -89,7 +115,11 @@ func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 	}
 
 	// set up the CA for tls connections
-	err = SetupCA(ctx, p.logger)
+	if p.redirect == "proxy" {
+		err = setCAEnv(p.logger)
+	} else {
+		err = SetupCA(ctx, p.logger)
+	}
 	if err != nil {
 		utils.LogError(p.logger, err, "failed to setup CA")
 		return err
@@ -99,6 +129,15 @@ func (p *Proxy) StartProxy(ctx context.Context, opts core.ProxyOptions) error {
 		return errors.New("failed to get the error group from the context")
 	}
 
+	// the cgroup of the apps whose UDP datagrams are intercepted is created before the app is started in it
+	if len(p.udpRules) > 0 && p.redirect != "proxy" {
+		cgroup, err := utils.CreateAppCgroup()
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to create the cgroup of the apps, their UDP datagrams aren't intercepted")
+		}
+		p.udpCgroup = cgroup
+	}
+
 	// start the proxy server
 	g.Go(func() error {
 		defer utils.Recover(p.logger)
@@ -191,20 +230,21 @@ func (p *Proxy) start(ctx context.Context) error {
 	defer func(listener net.Listener) {
 		err := listener.Close()
 
-		if err != nil {
+		if err != nil && !errors.Is(err, net.ErrClosed) {
 			p.logger.Error("failed to close the listener", zap.Error(err))
 		}
 		p.logger.Info("proxy stopped...")
 	}(listener)
 
-	clientConnCtx, clientConnCancel := context.WithCancel(ctx)
+	// the client connections outlive the context of the proxy to be drained once it is done
+	clientConnCtx, clientConnCancel := context.WithCancel(context.WithoutCancel(ctx))
 	clientConnErrGrp, _ := errgroup.WithContext(clientConnCtx)
 	defer func() {
-		clientConnCancel()
-		err := clientConnErrGrp.Wait()
+		err := p.drain(listener, clientConnErrGrp, clientConnCancel)
 		if err != nil {
 			p.logger.Debug("failed to handle the client connection", zap.Error(err))
 		}
+		p.breaker.summary(p.logger)
 		//closing all the mock channels (if any in record mode)
 		for _, mc := range p.sessions.GetAllMC() {
 			if mc != nil {
@@ -221,7 +261,20 @@ func (p *Proxy) start(ctx context.Context) error {
 		}
 	}()
 
+	// the UDP relay stops with the proxy, and is waited for with the client connections before the mock channels
+	// are closed
+	if p.udpCgroup != "" {
+		clientConnErrGrp.Go(func() error {
+			defer utils.Recover(p.logger)
+			return p.relayUDP(ctx)
+		})
+	}
+
 	for {
+		// a slot is taken before accepting, so that the app's connections wait instead of being dropped
+		if !p.acquireConnSlot(ctx) {
+			return nil
+		}
 		clientConnCh := make(chan net.Conn, 1)
 		errCh := make(chan error, 1)
 		go func() {
@@ -240,12 +293,19 @@ func (p *Proxy) start(ctx context.Context) error {
 		}()
 		select {
 		case <-ctx.Done():
+			p.releaseConnSlot()
 			return nil
 		case <-errCh:
+			p.releaseConnSlot()
 			return err
 		// handle the client connection
 		case clientConn := <-clientConnCh:
+			if n := p.inFlight.Add(1); n > p.peakInFlight.Load() {
+				p.peakInFlight.Store(n)
+			}
 			clientConnErrGrp.Go(func() error {
+				defer p.releaseConnSlot()
+				defer p.inFlight.Add(-1)
 				defer util.Recover(p.logger, clientConn, nil)
 				err := p.handleConnection(clientConnCtx, clientConn)
 				if err != nil && err != io.EOF {
@@ -257,8 +317,88 @@ func (p *Proxy) start(ctx context.Context) error {
 	}
 }
 
+// acquireConnSlot waits for a free slot to handle a connection, it returns false if the context is done first.
+func (p *Proxy) acquireConnSlot(ctx context.Context) bool {
+	if p.connSlots == nil {
+		return true
+	}
+	select {
+	case p.connSlots <- struct{}{}:
+		return true
+	default:
+	}
+	p.logger.Debug("the proxy is handling its maximum of connections, waiting for one to end before accepting", zap.Int("proxyMaxConnections", cap(p.connSlots)))
+	select {
+	case p.connSlots <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (p *Proxy) releaseConnSlot() {
+	if p.connSlots != nil {
+		<-p.connSlots
+	}
+}
+
+// drain stops accepting the connections and waits for the ones in flight to end, so that their parsers persist
+// their last mocks, for up to drainTimeout before closing them.
+func (p *Proxy) drain(listener net.Listener, clientConnErrGrp *errgroup.Group, clientConnCancel context.CancelFunc) error {
+	defer clientConnCancel()
+	err := listener.Close()
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		utils.LogError(p.logger, err, "failed to stop accepting the connections")
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- clientConnErrGrp.Wait()
+	}()
+	p.logger.Debug("the most connections handled at once by the proxy", zap.Int64("connections", p.peakInFlight.Load()), zap.Int("proxyMaxConnections", cap(p.connSlots)))
+	if n := p.inFlight.Load(); n > 0 {
+		p.logger.Debug("draining the connections in flight", zap.Int64("connections", n), zap.Duration("timeout", p.drainTimeout))
+	}
+	select {
+	case err = <-done:
+		return err
+	case <-time.After(p.drainTimeout):
+		p.logger.Warn("the connections in flight didn't end in time, closing them, their last mocks may be lost", zap.Int64("connections", p.inFlight.Load()), zap.Duration("proxyDrainTimeout", p.drainTimeout))
+	}
+	clientConnCancel()
+	return <-done
+}
+
 // handleConnection function executes the actual outgoing network call and captures/forwards the request and response messages.
-func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
+// getDestInfo returns the actual destination of the connection redirected to the proxy.
+func (p *Proxy) getDestInfo(ctx context.Context, srcConn net.Conn, sourcePort uint16) (*core.NetworkAddress, net.Conn, error) {
+	// the destination of a connection redirected by iptables or sent to the proxy by the app itself is read from
+	// the connection
+	if connDest, ok := p.DestInfo.(core.ConnDestInfo); ok {
+		destInfo, conn, err := connDest.GetConnDest(ctx, srcConn)
+		if err != nil {
+			utils.LogError(p.logger, err, "failed to fetch the destination info", zap.Any("Source port", sourcePort))
+			return nil, nil, err
+		}
+		return destInfo, conn, nil
+	}
+
+	destInfo, err := p.DestInfo.Get(ctx, sourcePort)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to fetch the destination info", zap.Any("Source port", sourcePort))
+		return nil, nil, err
+	}
+
+	// releases the occupied source port when done fetching the destination info
+	err = p.DestInfo.Delete(ctx, sourcePort)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to delete the destination info", zap.Any("Source port", sourcePort))
+		return nil, nil, err
+	}
+	return destInfo, srcConn, nil
+}
+
+func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) (err error) {
 	//checking how much time proxy takes to execute the flow.
 	start := time.Now()
 
@@ -280,16 +420,8 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 
 	p.logger.Debug("Inside handleConnection of proxyServer", zap.Any("source port", sourcePort), zap.Any("Time", time.Now().Unix()))
 
-	destInfo, err := p.DestInfo.Get(ctx, uint16(sourcePort))
+	destInfo, srcConn, err := p.getDestInfo(ctx, srcConn, uint16(sourcePort))
 	if err != nil {
-		utils.LogError(p.logger, err, "failed to fetch the destination info", zap.Any("Source port", sourcePort))
-		return err
-	}
-
-	// releases the occupied source port when done fetching the destination info
-	err = p.DestInfo.Delete(ctx, uint16(sourcePort))
-	if err != nil {
-		utils.LogError(p.logger, err, "failed to delete the destination info", zap.Any("Source port", sourcePort))
 		return err
 	}
 
@@ -310,6 +442,15 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		p.logger.Debug("", zap.Any("DestIp6", destInfo.IPv6Addr), zap.Any("DestPort", destInfo.Port))
 	}
 
+	// the mocks recorded on the connection are sent with its destination, once the parsers are done
+	dest := &destination{addr: dstAddr}
+	mocks := rule.MC
+	if rule.Mode == models.MODE_RECORD && !destInfo.OutsideApp {
+		var stopAudit func()
+		mocks, stopAudit = p.auditMocks(rule.MC, dest)
+		defer stopAudit()
+	}
+
 	// This is used to handle the parser errors
 	parserErrGrp, parserCtx := errgroup.WithContext(ctx)
 	parserCtx = context.WithValue(parserCtx, models.ErrGroupKey, parserErrGrp)
@@ -319,21 +460,18 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	defer func() {
 		parserCtxCancel()
 
+		// the parsers are waited for even if the connections fail to close, they may still send their mocks
 		err := srcConn.Close()
 		if err != nil {
 			utils.LogError(p.logger, err, "failed to close the source connection", zap.Any("clientConnID", clientConnID))
-			return
 		}
 
 		if dstConn != nil {
 			err = dstConn.Close()
-			if err != nil {
-				// Use string matching as a last resort to check for the specific error
-				if !strings.Contains(err.Error(), "use of closed network connection") {
-					// Log other errors
-					utils.LogError(p.logger, err, "failed to close the destination connection")
-				}
-				return
+			// Use string matching as a last resort to check for the specific error
+			if err != nil && !strings.Contains(err.Error(), "use of closed network connection") {
+				// Log other errors
+				utils.LogError(p.logger, err, "failed to close the destination connection")
 			}
 		}
 
@@ -343,6 +481,21 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		}
 	}()
 
+	if destInfo.OutsideApp {
+		p.logger.Debug("passing through the connection of a process outside the app", zap.String("destination", dstAddr))
+		return p.passThrough(parserCtx, p.logger.With(zap.Any("Client ConnectionID", clientConnID)), srcConn, dstAddr)
+	}
+
+	if rule.Mode == models.MODE_RECORD {
+		if !p.breaker.acquire(dstAddr) {
+			p.logger.Debug("passing through the connection to a failing or busy destination", zap.String("destination", dstAddr))
+			return p.passThrough(parserCtx, p.logger.With(zap.Any("Client ConnectionID", clientConnID)), srcConn, dstAddr)
+		}
+		defer func() {
+			p.breaker.release(p.logger, dstAddr, err)
+		}()
+	}
+
 	//checking for the destination port of "mysql"
 	if destInfo.Port == 3306 {
 		var dstConn net.Conn
@@ -353,7 +506,7 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 				return err
 			}
 			// Record the outgoing message into a mock
-			err := p.Integrations["mysql"].RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
+			err := p.Integrations["mysql"].RecordOutgoing(parserCtx, srcConn, dstConn, mocks, rule.OutgoingOptions)
 			if err != nil {
 				utils.LogError(p.logger, err, "failed to record the outgoing message")
 				return err
@@ -397,12 +550,16 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	}
 
 	isTLS := isTLSHandshake(testBuffer)
+	if isTLS && rule.PlainTLS && rule.Mode == models.MODE_RECORD {
+		return p.passThrough(parserCtx, p.logger.With(zap.Any("Client ConnectionID", clientConnID)), srcConn, dstAddr)
+	}
 	if isTLS {
 		srcConn, err = p.handleTLSConnection(srcConn)
 		if err != nil {
 			utils.LogError(p.logger, err, "failed to handle TLS conn")
 			return err
 		}
+		dest.sni = dstURL
 	}
 
 	// attempt to read conn until buffer is either filled or conn is closed
@@ -463,13 +620,21 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		return err
 	}
 
+	if rule.Mode == models.MODE_TEST {
+		host := dest.sni
+		if host == "" {
+			host = httpHost(initialBuf)
+		}
+		m.(*MockManager).destinations.check(logger, host, dstAddr)
+	}
+
 	generic := true
 
 	//Checking for all the parsers.
 	for _, parser := range p.Integrations {
 		if parser.MatchType(parserCtx, initialBuf) {
 			if rule.Mode == models.MODE_RECORD {
-				err := parser.RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
+				err := parser.RecordOutgoing(parserCtx, srcConn, dstConn, mocks, rule.OutgoingOptions)
 				if err != nil {
 					utils.LogError(logger, err, "failed to record the outgoing message")
 					return err
@@ -477,8 +642,7 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 			} else {
 				err := parser.MockOutgoing(parserCtx, srcConn, dstCfg, m.(*MockManager), rule.OutgoingOptions)
 				if err != nil && err != io.EOF {
-					utils.LogError(logger, err, "failed to mock the outgoing message")
-					return err
+					return mockError(logger, m.(*MockManager), err)
 				}
 			}
 			generic = false
@@ -488,7 +652,7 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 	if generic {
 		logger.Debug("The external dependency is not supported. Hence using generic parser")
 		if rule.Mode == models.MODE_RECORD {
-			err := p.Integrations["generic"].RecordOutgoing(parserCtx, srcConn, dstConn, rule.MC, rule.OutgoingOptions)
+			err := p.Integrations["generic"].RecordOutgoing(parserCtx, srcConn, dstConn, mocks, rule.OutgoingOptions)
 			if err != nil {
 				utils.LogError(logger, err, "failed to record the outgoing message")
 				return err
@@ -496,14 +660,26 @@ func (p *Proxy) handleConnection(ctx context.Context, srcConn net.Conn) error {
 		} else {
 			err := p.Integrations["generic"].MockOutgoing(parserCtx, srcConn, dstCfg, m.(*MockManager), rule.OutgoingOptions)
 			if err != nil {
-				utils.LogError(logger, err, "failed to mock the outgoing message")
-				return err
+				return mockError(logger, m.(*MockManager), err)
 			}
 		}
 	}
 	return nil
 }
 
+// mockError records the error of mocking an outgoing call for the test run of the current testcase. A client
+// which closed the conn in the middle of a message is not a failure of the proxy, so it isn't logged as one.
+func mockError(logger *zap.Logger, m *MockManager, err error) error {
+	m.FlagProxyError(err)
+	var proxyErr models.ProxyError
+	if errors.As(err, &proxyErr) && proxyErr.ProxyErrorType == models.ErrClientClosed {
+		logger.Debug("the client closed the conn while mocking the outgoing message", zap.Error(err))
+		return err
+	}
+	utils.LogError(logger, err, "failed to mock the outgoing message")
+	return err
+}
+
 func (p *Proxy) StopProxyServer(ctx context.Context) {
 	<-ctx.Done()
 
@@ -560,7 +736,10 @@ func (p *Proxy) Mock(_ context.Context, id uint64, opts models.OutgoingOptions)
 		Mode:            models.MODE_TEST,
 		OutgoingOptions: opts,
 	})
-	p.MockManagers.Store(id, NewMockManager(NewTreeDb(customComparator), NewTreeDb(customComparator), p.logger))
+	mgr := NewMockManager(NewTreeDb(customComparator), NewTreeDb(customComparator), p.logger)
+	mgr.SetSharedMocks(opts.SharedMocks)
+	mgr.SetEventHandler(opts.OnMockEvent)
+	p.MockManagers.Store(id, mgr)
 
 	if string(p.nsswitchData) == "" {
 		// setup the nsswitch config to redirect the DNS queries to the proxy
@@ -589,6 +768,8 @@ func (p *Proxy) SetMocks(_ context.Context, id uint64, filtered []*models.Mock,
 	if ok {
 		m.(*MockManager).SetFilteredMocks(filtered)
 		m.(*MockManager).SetUnFilteredMocks(unFiltered)
+		m.(*MockManager).destinations.reset(append(append([]*models.Mock{}, filtered...), unFiltered...))
+		m.(*MockManager).cookies.reset()
 	}
 
 	return nil
@@ -602,3 +783,21 @@ func (p *Proxy) GetConsumedMocks(_ context.Context, id uint64) ([]string, error)
 	}
 	return m.(*MockManager).GetConsumedMocks(), nil
 }
+
+// GetProxyErrors returns the errors of the outgoing calls mocked for a given app id
+func (p *Proxy) GetProxyErrors(_ context.Context, id uint64) ([]models.OutgoingError, error) {
+	m, ok := p.MockManagers.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("mock manager not found to get proxy errors")
+	}
+	return m.(*MockManager).GetProxyErrors(), nil
+}
+
+// GetInjectedFaults returns the faults injected in place of the mock responses for a given app id
+func (p *Proxy) GetInjectedFaults(_ context.Context, id uint64) ([]models.InjectedFault, error) {
+	m, ok := p.MockManagers.Load(id)
+	if !ok {
+		return nil, fmt.Errorf("mock manager not found to get injected faults")
+	}
+	return m.(*MockManager).GetInjectedFaults(), nil
+}
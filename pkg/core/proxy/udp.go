@@ -0,0 +1,387 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/core/proxy/integrations/util"
+	"go.keploy.io/server/v2/pkg/core/redirect"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// udpFlowTimeout is how long a flow of datagrams between the app and a destination is kept without any datagram.
+// The replies recorded with a request are the ones received until the next request of the flow or its timeout.
+const udpFlowTimeout = 30 * time.Second
+
+// udpMockType is the type in the metadata of the generic mocks of the UDP datagrams.
+const udpMockType = "udp"
+
+// relayUDP relays the UDP datagrams of the apps to the ports of the UDP rules, which are intercepted with TPROXY
+// from the processes of the cgroup of the apps and received on the port of the proxy, until the context is done. It
+// returns once the mocks being recorded are sent.
+func (p *Proxy) relayUDP(ctx context.Context) error {
+	defer func() {
+		if err := utils.RemoveAppCgroup(); err != nil {
+			p.logger.Debug("failed to remove the cgroup of the apps", zap.Error(err))
+		}
+	}()
+	conn, err := listenUDPRelay(ctx, p.Port)
+	if err != nil {
+		utils.LogError(p.logger, err, "failed to start the UDP relay", zap.Uint32("port", p.Port))
+		return err
+	}
+	defer conn.Close()
+
+	ports := make([]uint32, 0, len(p.udpRules))
+	for _, rule := range p.udpRules {
+		ports = append(ports, rule.Port)
+	}
+	err = redirect.InterceptUDP(ctx, p.logger, ports, p.Port, p.udpCgroup)
+	if err != nil {
+		return err
+	}
+	p.logger.Info(fmt.Sprintf("UDP relay started at port:%v", p.Port), zap.Any("intercepted ports", ports))
+
+	r := newUDPRelay(p)
+	defer r.close()
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.Close()
+	})
+	defer stop()
+
+	buf := make([]byte, 65535)
+	oob := make([]byte, 1024)
+	for {
+		n, src, dst, err := readDatagram(conn, buf, oob)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			utils.LogError(p.logger, err, "failed to read the intercepted UDP datagram")
+			continue
+		}
+		r.handle(src, dst, append([]byte(nil), buf[:n]...))
+	}
+}
+
+// udpRelay keeps the flows of datagrams between the app and the destinations, and counts the datagrams of each
+// port for the summary of the session.
+type udpRelay struct {
+	p      *Proxy
+	logger *zap.Logger
+	modes  map[int]string
+
+	m      sync.Mutex
+	flows  map[string]*udpFlow
+	stats  map[int]*udpStats
+	closed bool
+}
+
+type udpStats struct {
+	datagrams int
+	bytes     int
+	replies   int
+}
+
+// udpFlow is the datagrams between a socket of the app and a destination.
+type udpFlow struct {
+	key      string
+	src, dst *net.UDPAddr
+	mode     string
+	session  *core.Session
+	// upstream is the socket to the destination, reply the socket sending the replies to the app from the address of
+	// the destination, it is opened with the first reply
+	upstream *net.UDPConn
+	reply    *net.UDPConn
+	// mock is the request being recorded with its replies
+	mock  *models.Mock
+	timer *time.Timer
+}
+
+func newUDPRelay(p *Proxy) *udpRelay {
+	modes := map[int]string{}
+	for _, rule := range p.udpRules {
+		modes[int(rule.Port)] = rule.Mode
+	}
+	return &udpRelay{
+		p:      p,
+		logger: p.logger,
+		modes:  modes,
+		flows:  map[string]*udpFlow{},
+		stats:  map[int]*udpStats{},
+	}
+}
+
+// recordedMock is a mock recorded by a flow, sent to the mock channel of its session once the lock of the relay is
+// released as the send blocks until the mock is consumed.
+type recordedMock struct {
+	mc   chan<- *models.Mock
+	mock *models.Mock
+}
+
+func sendMocks(mocks []recordedMock) {
+	for _, m := range mocks {
+		m.mc <- m.mock
+	}
+}
+
+// handle relays the datagram sent by the app from src to dst.
+func (r *udpRelay) handle(src, dst *net.UDPAddr, data []byte) {
+	var recorded []recordedMock
+	defer func() {
+		sendMocks(recorded)
+	}()
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed {
+		return
+	}
+
+	stats, ok := r.stats[dst.Port]
+	if !ok {
+		stats = &udpStats{}
+		r.stats[dst.Port] = stats
+	}
+	stats.datagrams++
+	stats.bytes += len(data)
+
+	f, ok := r.flows[src.String()+"->"+dst.String()]
+	if !ok {
+		f = r.newFlow(src, dst)
+	}
+	f.timer.Reset(udpFlowTimeout)
+	logger := r.logger.With(zap.String("source", src.String()), zap.String("destination", dst.String()))
+
+	switch {
+	case f.mode == config.UDPDrop:
+		logger.Debug("dropping the UDP datagram")
+	case f.mode == config.UDPMock && f.session.Mode == models.MODE_TEST:
+		for _, reply := range r.mockReplies(f, data) {
+			r.sendReply(f, reply)
+		}
+	default:
+		if f.mode == config.UDPMock {
+			recorded = r.flush(f, recorded)
+			f.mock = &models.Mock{
+				Version: models.GetVersion(),
+				Name:    "mocks",
+				Kind:    models.GENERIC,
+				Spec: models.MockSpec{
+					Metadata:         map[string]string{"type": udpMockType, "destination": dst.String()},
+					GenericRequests:  []models.GenericPayload{udpPayload(models.FromClient, data)},
+					ReqTimestampMock: time.Now(),
+				},
+			}
+		}
+		if f.upstream == nil {
+			if err := r.dialUpstream(f); err != nil {
+				utils.LogError(logger, err, "failed to dial the destination of the UDP datagram")
+				return
+			}
+		}
+		if _, err := f.upstream.Write(data); err != nil {
+			logger.Debug("failed to forward the UDP datagram to its destination", zap.Error(err))
+		}
+	}
+}
+
+// newFlow adds the flow of the datagrams from src to dst. The datagrams which aren't attributed to an app, e.g. of
+// a socket already closed, are dropped rather than being recorded or replayed for the wrong app.
+func (r *udpRelay) newFlow(src, dst *net.UDPAddr) *udpFlow {
+	f := &udpFlow{
+		key:  src.String() + "->" + dst.String(),
+		src:  src,
+		dst:  dst,
+		mode: r.modes[dst.Port],
+	}
+	id, inApp := r.p.udpApp(src)
+	session, ok := r.p.sessions.Get(id)
+	if !inApp || !ok {
+		r.logger.Debug("dropping the UDP datagrams which aren't attributed to an app", zap.String("source", src.String()), zap.String("destination", dst.String()))
+		f.mode = config.UDPDrop
+	}
+	f.session = session
+	f.timer = time.AfterFunc(udpFlowTimeout, func() {
+		var recorded []recordedMock
+		r.m.Lock()
+		if r.flows[f.key] == f {
+			recorded = r.closeFlow(f, recorded)
+		}
+		r.m.Unlock()
+		sendMocks(recorded)
+	})
+	r.flows[f.key] = f
+	return f
+}
+
+// dialUpstream opens the socket of the flow to the destination and relays its replies back to the app.
+func (r *udpRelay) dialUpstream(f *udpFlow) error {
+	conn, err := dialUDPUpstream(f.dst)
+	if err != nil {
+		return err
+	}
+	f.upstream = conn
+	go func() {
+		defer utils.Recover(r.logger)
+		buf := make([]byte, 65535)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				// the socket is closed with the flow, the errors of ICMP e.g. of an unreachable port are ignored
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				continue
+			}
+			r.onReply(f, append([]byte(nil), buf[:n]...))
+		}
+	}()
+	return nil
+}
+
+// onReply sends the reply of the destination back to the app, recording it with the request of the flow.
+func (r *udpRelay) onReply(f *udpFlow, data []byte) {
+	r.m.Lock()
+	defer r.m.Unlock()
+	if r.closed || r.flows[f.key] != f {
+		return
+	}
+	r.stats[f.dst.Port].replies++
+	f.timer.Reset(udpFlowTimeout)
+	if f.mock != nil {
+		f.mock.Spec.GenericResponses = append(f.mock.Spec.GenericResponses, udpPayload(models.FromServer, data))
+		f.mock.Spec.ResTimestampMock = time.Now()
+	}
+	r.sendReply(f, data)
+}
+
+// sendReply sends the datagram to the app from the address of the destination of the flow.
+func (r *udpRelay) sendReply(f *udpFlow, data []byte) {
+	if f.reply == nil {
+		conn, err := dialUDPReply(f.dst, f.src)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to open the socket sending the UDP replies to the app", zap.String("from", f.dst.String()))
+			return
+		}
+		f.reply = conn
+	}
+	if _, err := f.reply.Write(data); err != nil {
+		r.logger.Debug("failed to send the UDP reply to the app", zap.String("source", f.dst.String()), zap.Error(err))
+	}
+}
+
+// mockReplies returns the replies of the mock of the datagram sent to the port of the flow, and flags the mock as
+// used. The datagram has no reply if no mock matches it, as if it was lost.
+func (r *udpRelay) mockReplies(f *udpFlow, data []byte) [][]byte {
+	m, ok := r.p.MockManagers.Load(f.session.ID)
+	if !ok {
+		return nil
+	}
+	mocks, err := m.(*MockManager).GetUnFilteredMocks()
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the mocks of the UDP datagram")
+		return nil
+	}
+	request := udpPayload(models.FromClient, data).Message[0]
+	for _, mock := range mocks {
+		if mock.Kind != models.GENERIC || mock.Spec.Metadata["type"] != udpMockType || len(mock.Spec.GenericRequests) != 1 {
+			continue
+		}
+		// the destination is matched by its port, its address resolved by keploy in test is the proxy
+		_, port, err := net.SplitHostPort(mock.Spec.Metadata["destination"])
+		if err != nil || port != strconv.Itoa(f.dst.Port) || mock.Spec.GenericRequests[0].Message[0] != request {
+			continue
+		}
+		if err := m.(*MockManager).FlagMockAsUsed(mock); err != nil {
+			utils.LogError(r.logger, err, "failed to flag the mock of the UDP datagram as used")
+		}
+		var replies [][]byte
+		for _, resp := range mock.Spec.GenericResponses {
+			reply := []byte(resp.Message[0].Data)
+			if resp.Message[0].Type != models.String {
+				reply, err = util.DecodeBase64(resp.Message[0].Data)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to decode the reply of the mock of the UDP datagram")
+					return nil
+				}
+			}
+			replies = append(replies, reply)
+		}
+		return replies
+	}
+	r.logger.Debug("no mock of the UDP datagram, dropping it", zap.String("destination", f.dst.String()))
+	return nil
+}
+
+// flush adds the mock being recorded by the flow to the recorded mocks to send.
+func (r *udpRelay) flush(f *udpFlow, recorded []recordedMock) []recordedMock {
+	if f.mock == nil {
+		return recorded
+	}
+	if f.session.MC != nil {
+		recorded = append(recorded, recordedMock{mc: f.session.MC, mock: f.mock})
+	}
+	f.mock = nil
+	return recorded
+}
+
+func (r *udpRelay) closeFlow(f *udpFlow, recorded []recordedMock) []recordedMock {
+	f.timer.Stop()
+	recorded = r.flush(f, recorded)
+	if f.upstream != nil {
+		_ = f.upstream.Close()
+	}
+	if f.reply != nil {
+		_ = f.reply.Close()
+	}
+	delete(r.flows, f.key)
+	return recorded
+}
+
+// close closes the flows, sending the mocks being recorded, and logs the summary of the intercepted datagrams.
+func (r *udpRelay) close() {
+	var recorded []recordedMock
+	defer func() {
+		sendMocks(recorded)
+	}()
+	r.m.Lock()
+	defer r.m.Unlock()
+	for _, f := range r.flows {
+		recorded = r.closeFlow(f, recorded)
+	}
+	r.closed = true
+	for port, s := range r.stats {
+		r.logger.Info("intercepted the UDP datagrams of the app", zap.Int("port", port), zap.String("mode", r.modes[port]), zap.Int("datagrams", s.datagrams), zap.Int("bytes", s.bytes), zap.Int("replies", s.replies))
+	}
+}
+
+// udpApp returns the app of the process of the socket sending from the address, and false if the process isn't
+// found or isn't of an app.
+func (p *Proxy) udpApp(src *net.UDPAddr) (uint64, bool) {
+	apps, ok := p.DestInfo.(core.AppOfProcess)
+	if !ok {
+		return 0, false
+	}
+	pid, found := udpSocketOwner(uint16(src.Port))
+	if !found {
+		return 0, false
+	}
+	return apps.AppOf(pid)
+}
+
+func udpPayload(origin models.OriginType, data []byte) models.GenericPayload {
+	msg := models.OutputBinary{Type: models.String, Data: string(data)}
+	if !util.IsASCIIPrintable(string(data)) {
+		msg = models.OutputBinary{Type: "binary", Data: util.EncodeBase64(data)}
+	}
+	return models.GenericPayload{Origin: origin, Message: []models.OutputBinary{msg}}
+}
@@ -0,0 +1,141 @@
+package proxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"go.keploy.io/server/v2/pkg/core/redirect"
+	"golang.org/x/sys/unix"
+)
+
+// listenUDPRelay listens for the datagrams delivered by TPROXY, with their original destination.
+func listenUDPRelay(ctx context.Context, port uint32) (*net.UDPConn, error) {
+	lc := net.ListenConfig{Control: sockopts(map[int]int{unix.IP_TRANSPARENT: 1, unix.IP_RECVORIGDSTADDR: 1}, 0)}
+	conn, err := lc.ListenPacket(ctx, "udp4", fmt.Sprintf("127.0.0.1:%d", port))
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// readDatagram reads a datagram of the relay with its source and original destination.
+func readDatagram(conn *net.UDPConn, buf, oob []byte) (int, *net.UDPAddr, *net.UDPAddr, error) {
+	n, oobn, _, src, err := conn.ReadMsgUDP(buf, oob)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	msgs, err := unix.ParseSocketControlMessage(oob[:oobn])
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	for _, m := range msgs {
+		// the data is the sockaddr_in of the original destination
+		if m.Header.Level == unix.SOL_IP && m.Header.Type == unix.IP_ORIGDSTADDR && len(m.Data) >= 8 {
+			dst := &net.UDPAddr{
+				IP:   net.IPv4(m.Data[4], m.Data[5], m.Data[6], m.Data[7]),
+				Port: int(binary.BigEndian.Uint16(m.Data[2:4])),
+			}
+			return n, src, dst, nil
+		}
+	}
+	return 0, nil, nil, fmt.Errorf("no original destination of the datagram from %v", src)
+}
+
+// dialUDPUpstream opens the socket forwarding the datagrams to their destination, marked to not be intercepted again.
+func dialUDPUpstream(dst *net.UDPAddr) (*net.UDPConn, error) {
+	d := net.Dialer{Control: sockopts(nil, redirect.UDPBypassMark)}
+	conn, err := d.Dial("udp4", dst.String())
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// dialUDPReply opens the socket sending the replies to the app from the address of their destination, which isn't
+// local and is bound with IP_TRANSPARENT.
+func dialUDPReply(from, to *net.UDPAddr) (*net.UDPConn, error) {
+	d := net.Dialer{
+		LocalAddr: from,
+		Control:   sockopts(map[int]int{unix.IP_TRANSPARENT: 1}, redirect.UDPBypassMark),
+	}
+	conn, err := d.Dial("udp4", to.String())
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// sockopts sets the IP options, SO_REUSEADDR to share the addresses of the destinations between the flows, and the
+// mark if it isn't 0 on the sockets.
+func sockopts(ipOpts map[int]int, mark int) func(_, _ string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		var sockErr error
+		err := c.Control(func(fd uintptr) {
+			set := func(level, opt, value int) {
+				if sockErr == nil {
+					sockErr = unix.SetsockoptInt(int(fd), level, opt, value)
+				}
+			}
+			set(unix.SOL_SOCKET, unix.SO_REUSEADDR, 1)
+			for opt, value := range ipOpts {
+				set(unix.SOL_IP, opt, value)
+			}
+			if mark != 0 {
+				set(unix.SOL_SOCKET, unix.SO_MARK, mark)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
+
+// udpSocketOwner returns the process of the IPv4 UDP socket bound to the port, from its inode in /proc/net/udp.
+func udpSocketOwner(port uint16) (uint32, bool) {
+	f, err := os.Open("/proc/net/udp")
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+	var inode string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 10 {
+			continue
+		}
+		_, localPort, ok := strings.Cut(fields[1], ":")
+		if !ok {
+			continue
+		}
+		if p, err := strconv.ParseUint(localPort, 16, 16); err == nil && uint16(p) == port {
+			inode = fields[9]
+			break
+		}
+	}
+	if inode == "" || inode == "0" {
+		return 0, false
+	}
+
+	socket := "socket:[" + inode + "]"
+	fds, _ := filepath.Glob("/proc/[0-9]*/fd/*")
+	for _, fd := range fds {
+		if link, err := os.Readlink(fd); err == nil && link == socket {
+			pid, err := strconv.ParseUint(strings.Split(fd, "/")[2], 10, 32)
+			if err == nil {
+				return uint32(pid), true
+			}
+		}
+	}
+	return 0, false
+}
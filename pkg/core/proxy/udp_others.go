@@ -0,0 +1,31 @@
+//go:build !linux
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+)
+
+var errUDPNotLinux = errors.New("the UDP datagrams of the app are only intercepted on linux")
+
+func listenUDPRelay(_ context.Context, _ uint32) (*net.UDPConn, error) {
+	return nil, errUDPNotLinux
+}
+
+func readDatagram(_ *net.UDPConn, _, _ []byte) (int, *net.UDPAddr, *net.UDPAddr, error) {
+	return 0, nil, nil, errUDPNotLinux
+}
+
+func dialUDPUpstream(_ *net.UDPAddr) (*net.UDPConn, error) {
+	return nil, errUDPNotLinux
+}
+
+func dialUDPReply(_, _ *net.UDPAddr) (*net.UDPConn, error) {
+	return nil, errUDPNotLinux
+}
+
+func udpSocketOwner(_ uint16) (uint32, bool) {
+	return 0, false
+}
@@ -0,0 +1,23 @@
+package util
+
+import "sync"
+
+// readBufSize is the size of the reads of ReadBytes, a read filling the buffer means more data may be available.
+const readBufSize = 1024
+
+// readBufPool holds the buffers the connections are read into, the data read is copied out of them so that they
+// are reused by the next reads instead of being allocated for each of them.
+var readBufPool = sync.Pool{
+	New: func() any {
+		buf := make([]byte, readBufSize)
+		return &buf
+	},
+}
+
+func getReadBuf() *[]byte {
+	return readBufPool.Get().(*[]byte)
+}
+
+func putReadBuf(buf *[]byte) {
+	readBufPool.Put(buf)
+}
@@ -0,0 +1,54 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+var readSink []byte
+
+// BenchmarkReadBuf compares allocating the buffer of each read, as ReadBytes did before, with taking it from
+// readBufPool.
+func BenchmarkReadBuf(b *testing.B) {
+	msg := bytes.Repeat([]byte("k"), readBufSize)
+	r := bytes.NewReader(msg)
+
+	b.Run("alloc", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Reset(msg)
+			buf := make([]byte, readBufSize)
+			n, _ := r.Read(buf)
+			readSink = buf[:n]
+		}
+	})
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			r.Reset(msg)
+			buf := getReadBuf()
+			n, _ := r.Read(*buf)
+			readSink = (*buf)[:n]
+			putReadBuf(buf)
+		}
+	})
+}
+
+// BenchmarkReadBytes reads a message spanning a few reads with ReadBytes.
+func BenchmarkReadBytes(b *testing.B) {
+	msg := bytes.Repeat([]byte("k"), 3*readBufSize+100)
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		buf, err := ReadBytes(ctx, logger, bytes.NewReader(msg))
+		if err != nil {
+			b.Fatal(err)
+		}
+		readSink = buf
+	}
+}
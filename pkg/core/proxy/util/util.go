@@ -101,7 +101,7 @@ func ReadBytes(ctx context.Context, logger *zap.Logger, reader io.Reader) ([]byt
 	readResult := make(chan struct {
 		n   int
 		err error
-		buf []byte
+		buf *[]byte
 	})
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -118,16 +118,18 @@ func ReadBytes(ctx context.Context, logger *zap.Logger, reader io.Reader) ([]byt
 		// Start a goroutine to perform the read operation
 		g.Go(func() error {
 			defer Recover(logger, nil, nil)
-			buf := make([]byte, 1024)
-			n, err := reader.Read(buf)
-			if ctx.Err() != nil {
-				return nil
-			}
-			readResult <- struct {
+			buf := getReadBuf()
+			n, err := reader.Read(*buf)
+			// the buffer is put back here if the result isn't received, as the read ended after the context
+			select {
+			case readResult <- struct {
 				n   int
 				err error
-				buf []byte
-			}{n, err, buf}
+				buf *[]byte
+			}{n, err, buf}:
+			case <-ctx.Done():
+				putReadBuf(buf)
+			}
 			return nil
 		})
 
@@ -137,9 +139,10 @@ func ReadBytes(ctx context.Context, logger *zap.Logger, reader io.Reader) ([]byt
 			return buffer, ctx.Err()
 		case result := <-readResult:
 			if result.n > 0 {
-				buffer = append(buffer, result.buf[:result.n]...)
+				buffer = append(buffer, (*result.buf)[:result.n]...)
 				emptyReads = 0 // Reset the counter because we got some data
 			}
+			putReadBuf(result.buf)
 
 			if result.err != nil {
 				if result.err == io.EOF {
@@ -152,7 +155,7 @@ func ReadBytes(ctx context.Context, logger *zap.Logger, reader io.Reader) ([]byt
 				}
 				return buffer, result.err
 			}
-			if result.n < len(result.buf) {
+			if result.n < readBufSize {
 				return buffer, nil
 			}
 		}
@@ -168,9 +171,10 @@ func ReadRequiredBytes(ctx context.Context, logger *zap.Logger, reader io.Reader
 
 	// Channel to communicate read results
 	readResult := make(chan struct {
-		n   int
-		err error
-		buf []byte
+		n      int
+		err    error
+		buf    []byte
+		pooled *[]byte
 	})
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -187,16 +191,28 @@ func ReadRequiredBytes(ctx context.Context, logger *zap.Logger, reader io.Reader
 		// Start a goroutine to perform the read operation
 		g.Go(func() error {
 			defer Recover(logger, nil, nil)
-			buf := make([]byte, numBytes)
+			// the reads of up to readBufSize bytes are made into the pooled buffers
+			var pooled *[]byte
+			var buf []byte
+			if numBytes <= readBufSize {
+				pooled = getReadBuf()
+				buf = (*pooled)[:numBytes]
+			} else {
+				buf = make([]byte, numBytes)
+			}
 			n, err := reader.Read(buf)
-			if ctx.Err() != nil {
-				return nil
+			select {
+			case readResult <- struct {
+				n      int
+				err    error
+				buf    []byte
+				pooled *[]byte
+			}{n, err, buf, pooled}:
+			case <-ctx.Done():
+				if pooled != nil {
+					putReadBuf(pooled)
+				}
 			}
-			readResult <- struct {
-				n   int
-				err error
-				buf []byte
-			}{n, err, buf}
 			return nil
 		})
 
@@ -210,6 +226,9 @@ func ReadRequiredBytes(ctx context.Context, logger *zap.Logger, reader io.Reader
 				numBytes -= result.n
 				emptyReads = 0 // Reset the counter because we got some data
 			}
+			if result.pooled != nil {
+				putReadBuf(result.pooled)
+			}
 
 			if result.err != nil {
 				if result.err == io.EOF {
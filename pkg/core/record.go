@@ -26,5 +26,20 @@ func (c *Core) GetOutgoing(ctx context.Context, id uint64, opts models.OutgoingO
 		return nil, err
 	}
 
+	tlsInfo, ok := c.Hooks.(TLSInfo)
+	if !ok {
+		return m, nil
+	}
+	tlsData, err := tlsInfo.TLSData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tlsRecorder, ok := c.Proxy.(TLSRecorder); ok && tlsData != nil {
+		err = tlsRecorder.RecordTLS(ctx, id, tlsData)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return m, nil
 }
@@ -0,0 +1,193 @@
+// Package redirect provides the iptables redirect of the outgoing calls of the app to the proxy, used in place of
+// the eBPF hooks when keploy doesn't have their capabilities e.g. on hardened CI runners. Only the TCP connections
+// and the DNS queries of the user of the app, or of its cgroup if it runs as the user of keploy, are redirected, the IPv6 ones if ip6tables has the nat table, and the
+// testcases aren't captured, so it supports keploy test but not keploy record.
+package redirect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// chain is the nat chain of the redirect rules, jumped to from OUTPUT for the user of the app.
+const chain = "KEPLOY"
+
+func New(logger *zap.Logger, cfg config.Config) *Redirect {
+	return &Redirect{
+		logger:      logger,
+		sess:        core.NewSessions(),
+		proxyPort:   cfg.ProxyPort,
+		dnsPort:     cfg.DNSPort,
+		passThrough: map[uint]bool{},
+	}
+}
+
+type Redirect struct {
+	logger    *zap.Logger
+	sess      *core.Sessions
+	proxyPort uint32
+	dnsPort   uint32
+
+	mu          sync.Mutex
+	appID       uint64
+	jump        []string // the rule of OUTPUT jumping to the chain
+	passThrough map[uint]bool
+	ipv6        bool // whether the IPv6 connections are redirected too
+}
+
+func (r *Redirect) Load(ctx context.Context, id uint64, opts core.HookCfg) error {
+	if opts.IsDocker {
+		return errors.New("the iptables redirect only supports native apps")
+	}
+	// the calls of the app are matched by its user, or by the cgroup it is started in if it runs as the user of
+	// keploy, whose own calls to the destinations mustn't be redirected
+	var app []string
+	var cgroup string
+	if opts.AppUID != nil {
+		app = []string{"-m", "owner", "--uid-owner", strconv.FormatUint(uint64(*opts.AppUID), 10)}
+	} else {
+		var err error
+		cgroup, err = utils.CreateAppCgroup()
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to create the cgroup of the app, the iptables redirect needs it when the app runs as the user of keploy")
+			return err
+		}
+		app = []string{"-m", "cgroup", "--path", cgroup}
+	}
+	r.sess.Set(id, &core.Session{ID: id})
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.appID = id
+	// the rules left behind by a keploy which didn't exit cleanly are replaced
+	r.jump = append(append([]string{"OUTPUT"}, app...), "-j", chain)
+	r.unload()
+
+	proxyPort := strconv.FormatUint(uint64(r.proxyPort), 10)
+	dnsPort := strconv.FormatUint(uint64(r.dnsPort), 10)
+	rules := [][]string{
+		{"-N", chain},
+		{"-A", chain, "-p", "tcp", "--dport", proxyPort, "-j", "RETURN"},
+		{"-A", chain, "-p", "tcp", "--dport", dnsPort, "-j", "RETURN"},
+		{"-A", chain, "-p", "udp", "--dport", "53", "-j", "REDIRECT", "--to-ports", dnsPort},
+		{"-A", chain, "-p", "tcp", "--dport", "53", "-j", "REDIRECT", "--to-ports", dnsPort},
+		{"-A", chain, "-p", "tcp", "-j", "REDIRECT", "--to-ports", proxyPort},
+		append([]string{"-A"}, r.jump...),
+	}
+	for _, rule := range rules {
+		if err := iptables(rule...); err != nil {
+			utils.LogError(r.logger, err, "failed to add the iptables redirect")
+			r.unload()
+			return err
+		}
+	}
+	r.ipv6 = true
+	for _, rule := range rules {
+		if err := ip6tables(rule...); err != nil {
+			r.logger.Warn("failed to add the ip6tables redirect, only the IPv4 connections of the app are redirected", zap.Error(err))
+			r.unloadIPv6()
+			r.ipv6 = false
+			break
+		}
+	}
+
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		r.unload()
+		return errors.New("failed to get the error group from the context")
+	}
+	g.Go(func() error {
+		defer utils.Recover(r.logger)
+		<-ctx.Done()
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.unload()
+		if cgroup != "" {
+			// the app is stopped before the hooks are unloaded, so its cgroup is empty
+			if err := utils.RemoveAppCgroup(); err != nil {
+				r.logger.Debug("failed to remove the cgroup of the app", zap.Error(err))
+			}
+		}
+		return nil
+	})
+	if cgroup != "" {
+		r.logger.Info("redirecting the outgoing calls of the app with iptables", zap.String("cgroup", cgroup))
+	} else {
+		r.logger.Info("redirecting the outgoing calls of the app with iptables", zap.Uint32("uid", *opts.AppUID))
+	}
+	return nil
+}
+
+// unload deletes the redirect rules, ignoring the rules which don't exist.
+func (r *Redirect) unload() {
+	_ = iptables(append([]string{"-D"}, r.jump...)...)
+	_ = iptables("-F", chain)
+	_ = iptables("-X", chain)
+	r.unloadIPv6()
+	r.passThrough = map[uint]bool{}
+}
+
+func (r *Redirect) unloadIPv6() {
+	_ = ip6tables(append([]string{"-D"}, r.jump...)...)
+	_ = ip6tables("-F", chain)
+	_ = ip6tables("-X", chain)
+}
+
+// Record isn't supported, the testcases are captured by the eBPF hooks.
+func (r *Redirect) Record(_ context.Context, _ uint64) (<-chan *models.TestCase, error) {
+	return nil, errors.New("recording the testcases needs the eBPF hooks, the iptables redirect only supports keploy test")
+}
+
+// Get isn't used, the proxy reads the destinations with GetConnDest.
+func (r *Redirect) Get(_ context.Context, srcPort uint16) (*core.NetworkAddress, error) {
+	return nil, fmt.Errorf("no destination of source port %d, the destinations are read from the connections", srcPort)
+}
+
+func (r *Redirect) Delete(_ context.Context, _ uint16) error {
+	return nil
+}
+
+// PassThroughPortsInKernel makes the calls to the ports skip the proxy.
+func (r *Redirect) PassThroughPortsInKernel(_ context.Context, _ uint64, ports []uint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, port := range ports {
+		if r.passThrough[port] {
+			continue
+		}
+		rule := []string{"-I", chain, "1", "-p", "tcp", "--dport", strconv.FormatUint(uint64(port), 10), "-j", "RETURN"}
+		err := iptables(rule...)
+		if err == nil && r.ipv6 {
+			err = ip6tables(rule...)
+		}
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to pass through the port", zap.Uint("port", port))
+			return err
+		}
+		r.passThrough[port] = true
+	}
+	return nil
+}
+
+func (r *Redirect) SendInode(_ context.Context, _ uint64, _ uint64) error {
+	return nil
+}
+
+// SendKeployPids and SendKeployPorts are only used by the test bench of keploy, which needs the eBPF hooks.
+func (r *Redirect) SendKeployPids(_ models.ModeKey, _ uint32) error {
+	return errors.New("the test bench needs the eBPF hooks")
+}
+
+func (r *Redirect) SendKeployPorts(_ models.ModeKey, _ uint32) error {
+	return errors.New("the test bench needs the eBPF hooks")
+}
@@ -0,0 +1,115 @@
+package redirect
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"go.keploy.io/server/v2/pkg/core"
+	"golang.org/x/sys/unix"
+)
+
+// iptables runs iptables on the nat table.
+func iptables(args ...string) error {
+	return run("iptables", append([]string{"-w", "-t", "nat"}, args...)...)
+}
+
+// ip6tables runs ip6tables on the nat table.
+func ip6tables(args ...string) error {
+	return run("ip6tables", append([]string{"-w", "-t", "nat"}, args...)...)
+}
+
+// iptablesMangle runs iptables on the mangle table.
+func iptablesMangle(args ...string) error {
+	return run("iptables", append([]string{"-w", "-t", "mangle"}, args...)...)
+}
+
+// ip runs ip of iproute2.
+func ip(args ...string) error {
+	return run("ip", args...)
+}
+
+// run runs the network tool with the capabilities of keploy when keploy isn't root.
+func run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	if os.Geteuid() != 0 {
+		cmd.SysProcAttr = &syscall.SysProcAttr{AmbientCaps: []uintptr{unix.CAP_NET_ADMIN, unix.CAP_NET_RAW}}
+	}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// ip6tSOOriginalDst is IP6T_SO_ORIGINAL_DST of linux/netfilter_ipv6/ip6_tables.h.
+const ip6tSOOriginalDst = 80
+
+// GetConnDest reads the original destination of the redirected connection.
+func (r *Redirect) GetConnDest(_ context.Context, conn net.Conn) (*core.NetworkAddress, net.Conn, error) {
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return nil, nil, errors.New("the redirected connection isn't a tcp connection")
+	}
+	raw, err := tcpConn.SyscallConn()
+	if err != nil {
+		return nil, nil, err
+	}
+	if local, ok := conn.LocalAddr().(*net.TCPAddr); ok && local.IP.To4() == nil {
+		return r.getConnDest6(conn, raw)
+	}
+	// the sockaddr_in of the original destination is read into the bytes of an ipv6_mreq
+	var addr *unix.IPv6Mreq
+	var addrErr error
+	err = raw.Control(func(fd uintptr) {
+		addr, addrErr = unix.GetsockoptIPv6Mreq(int(fd), unix.SOL_IP, unix.SO_ORIGINAL_DST)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if addrErr != nil {
+		return nil, nil, fmt.Errorf("failed to get the original destination of the connection: %w", addrErr)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &core.NetworkAddress{
+		AppID:    r.appID,
+		Version:  4,
+		IPv4Addr: binary.BigEndian.Uint32(addr.Multiaddr[4:8]),
+		Port:     uint32(binary.BigEndian.Uint16(addr.Multiaddr[2:4])),
+	}, conn, nil
+}
+
+// getConnDest6 reads the original destination of the IPv6 connection redirected by ip6tables.
+func (r *Redirect) getConnDest6(conn net.Conn, raw syscall.RawConn) (*core.NetworkAddress, net.Conn, error) {
+	// the sockaddr_in6 of the original destination is read into the bytes of an ip6_mtuinfo
+	var info *unix.IPv6MTUInfo
+	var infoErr error
+	err := raw.Control(func(fd uintptr) {
+		info, infoErr = unix.GetsockoptIPv6MTUInfo(int(fd), unix.SOL_IPV6, ip6tSOOriginalDst)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if infoErr != nil {
+		return nil, nil, fmt.Errorf("failed to get the original destination of the connection: %w", infoErr)
+	}
+	addr := &core.NetworkAddress{
+		Version: 6,
+		// the port is in network byte order in the memory of the struct
+		Port: uint32(binary.BigEndian.Uint16(binary.NativeEndian.AppendUint16(nil, info.Addr.Port))),
+	}
+	for i := range addr.IPv6Addr {
+		addr.IPv6Addr[i] = binary.BigEndian.Uint32(info.Addr.Addr[i*4 : i*4+4])
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	addr.AppID = r.appID
+	return addr, conn, nil
+}
@@ -0,0 +1,33 @@
+//go:build !linux
+
+package redirect
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	"go.keploy.io/server/v2/pkg/core"
+)
+
+var errNotLinux = errors.New("the iptables redirect is only supported on linux")
+
+func iptables(_ ...string) error {
+	return errNotLinux
+}
+
+func ip6tables(_ ...string) error {
+	return errNotLinux
+}
+
+func iptablesMangle(_ ...string) error {
+	return errNotLinux
+}
+
+func ip(_ ...string) error {
+	return errNotLinux
+}
+
+func (r *Redirect) GetConnDest(_ context.Context, _ net.Conn) (*core.NetworkAddress, net.Conn, error) {
+	return nil, nil, errNotLinux
+}
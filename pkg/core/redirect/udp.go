@@ -0,0 +1,97 @@
+package redirect
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// udpChain is the mangle chain of OUTPUT marking the UDP datagrams to the intercepted ports.
+const udpChain = "KEPLOY_UDP"
+
+const (
+	// UDPMark marks the intercepted datagrams, they are routed to the loopback by the routing table of the mark and
+	// delivered to the UDP relay of the proxy by TPROXY, keeping their original destination.
+	UDPMark = 0x4b50
+	// UDPBypassMark is set on the sockets of the UDP relay, so that the datagrams it forwards and the replies it
+	// sends back to the app aren't intercepted again.
+	UDPBypassMark = 0x4b51
+	udpTable      = "19280"
+)
+
+// InterceptUDP delivers the IPv4 UDP datagrams sent to the ports by the processes of the cgroup v2 of the apps,
+// its path relative to the root of the hierarchy, to the UDP relay listening with IP_TRANSPARENT on
+// 127.0.0.1:relayPort, until the context is done. The datagrams of the other processes of the machine aren't
+// intercepted. It works the same with the eBPF hooks, which only redirect the TCP connections and the DNS queries,
+// and with the iptables redirect. The IPv6 datagrams aren't intercepted, TPROXY would need the same rules and routes
+// with ip6tables and the relay a socket of its own for them.
+func InterceptUDP(ctx context.Context, logger *zap.Logger, ports []uint32, relayPort uint32, cgroup string) error {
+	// the rules left behind by a keploy which didn't exit cleanly are replaced
+	unloadUDP(relayPort)
+
+	mark := strconv.Itoa(UDPMark)
+	rules := [][]string{
+		{"-N", udpChain},
+		{"-A", udpChain, "-m", "mark", "--mark", strconv.Itoa(UDPBypassMark), "-j", "RETURN"},
+		{"-A", udpChain, "-m", "cgroup", "!", "--path", cgroup, "-j", "RETURN"},
+	}
+	for _, port := range ports {
+		rules = append(rules, []string{"-A", udpChain, "-p", "udp", "--dport", strconv.FormatUint(uint64(port), 10), "-j", "MARK", "--set-mark", mark})
+	}
+	rules = append(rules,
+		[]string{"-A", "OUTPUT", "-p", "udp", "-j", udpChain},
+		tproxyRule("-A", relayPort),
+	)
+	for _, rule := range rules {
+		if err := iptablesMangle(rule...); err != nil {
+			utils.LogError(logger, err, "failed to add the iptables rules intercepting the UDP datagrams")
+			unloadUDP(relayPort)
+			return err
+		}
+	}
+	for _, route := range [][]string{
+		{"rule", "add", "fwmark", mark, "lookup", udpTable},
+		{"route", "add", "local", "0.0.0.0/0", "dev", "lo", "table", udpTable},
+	} {
+		if err := ip(route...); err != nil {
+			utils.LogError(logger, err, "failed to add the route of the intercepted UDP datagrams")
+			unloadUDP(relayPort)
+			return err
+		}
+	}
+
+	g, ok := ctx.Value(models.ErrGroupKey).(*errgroup.Group)
+	if !ok {
+		unloadUDP(relayPort)
+		return errors.New("failed to get the error group from the context")
+	}
+	g.Go(func() error {
+		defer utils.Recover(logger)
+		<-ctx.Done()
+		unloadUDP(relayPort)
+		return nil
+	})
+	logger.Debug("intercepting the UDP datagrams", zap.Any("ports", ports), zap.String("cgroup", cgroup))
+	return nil
+}
+
+// tproxyRule is the rule of PREROUTING delivering the marked datagrams to the relay.
+func tproxyRule(op string, relayPort uint32) []string {
+	mark := strconv.Itoa(UDPMark)
+	return []string{op, "PREROUTING", "-p", "udp", "-m", "mark", "--mark", mark, "-j", "TPROXY", "--on-ip", "127.0.0.1", "--on-port", strconv.FormatUint(uint64(relayPort), 10), "--tproxy-mark", mark}
+}
+
+// unloadUDP deletes the rules and the routes of the interception, ignoring the ones which don't exist.
+func unloadUDP(relayPort uint32) {
+	_ = iptablesMangle("-D", "OUTPUT", "-p", "udp", "-j", udpChain)
+	_ = iptablesMangle(tproxyRule("-D", relayPort)...)
+	_ = iptablesMangle("-F", udpChain)
+	_ = iptablesMangle("-X", udpChain)
+	_ = ip("rule", "del", "fwmark", strconv.Itoa(UDPMark), "lookup", udpTable)
+	_ = ip("route", "flush", "table", udpTable)
+}
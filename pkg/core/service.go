@@ -2,6 +2,7 @@ package core
 
 import (
 	"context"
+	"net"
 	"sync"
 
 	"go.keploy.io/server/v2/pkg/core/app"
@@ -24,7 +25,11 @@ type HookCfg struct {
 	Pid        uint32
 	IsDocker   bool
 	KeployIPV4 string
+	// KeployIPV6 is the IPv6 of keploy on the network of the docker app, empty if the network has no IPv6
+	KeployIPV6 string
 	Mode       models.Mode
+	// AppUID is the user the native app is run as if it isn't the user of keploy, nil otherwise.
+	AppUID *uint32
 }
 
 type App interface {
@@ -32,6 +37,7 @@ type App interface {
 	Run(ctx context.Context, inodeChan chan uint64, opts app.Options) error
 	Kind(ctx context.Context) utils.CmdType
 	KeployIPv4Addr() string
+	KeployIPv6Addr() string
 }
 
 // Proxy listens on all available interfaces and forwards traffic to the destination
@@ -41,6 +47,8 @@ type Proxy interface {
 	Mock(ctx context.Context, id uint64, opts models.OutgoingOptions) error
 	SetMocks(ctx context.Context, id uint64, filtered []*models.Mock, unFiltered []*models.Mock) error
 	GetConsumedMocks(ctx context.Context, id uint64) ([]string, error)
+	GetInjectedFaults(ctx context.Context, id uint64) ([]models.InjectedFault, error)
+	GetProxyErrors(ctx context.Context, id uint64) ([]models.OutgoingError, error)
 }
 
 type ProxyOptions struct {
@@ -55,6 +63,54 @@ type DestInfo interface {
 	Delete(ctx context.Context, srcPort uint16) error
 }
 
+// ConnDestInfo is implemented by the DestInfo which reads the destination of a redirected connection from the
+// connection itself, instead of by its source port. It returns the connection to proxy, which still yields the
+// bytes read from it to find the destination, if any.
+type ConnDestInfo interface {
+	GetConnDest(ctx context.Context, conn net.Conn) (*NetworkAddress, net.Conn, error)
+}
+
+// EnvInfo is implemented by the Hooks which redirect the app to the proxy through the environment of the app.
+type EnvInfo interface {
+	Env(id uint64) []string
+}
+
+// ProcessInfo is implemented by the Hooks which filter the traffic of a native app to the process tree of its
+// command, started as pid.
+type ProcessInfo interface {
+	TrackProcess(ctx context.Context, id uint64, pid uint32) error
+}
+
+// AppOfProcess is implemented by the DestInfo which knows the app a process is of, for the calls which aren't seen
+// by the hooks, like the UDP datagrams relayed by the proxy. It returns false for the processes outside the apps.
+type AppOfProcess interface {
+	AppOf(pid uint32) (uint64, bool)
+}
+
+// TLSInfo is implemented by the Hooks which capture the plaintext of the TLS connections of the app with uprobes on
+// its TLS library, so that they are recorded without the proxy decrypting them. TLSData returns nil if the TLS
+// connections are decrypted by the proxy instead.
+type TLSInfo interface {
+	TLSData(ctx context.Context, id uint64) (<-chan *TLSData, error)
+}
+
+// TLSRecorder is implemented by the Proxy which records the mocks of the plaintext captured by TLSInfo, passing the
+// TLS connections through to their destination.
+type TLSRecorder interface {
+	RecordTLS(ctx context.Context, id uint64, data <-chan *TLSData) error
+}
+
+// TLSData is the plaintext written or read by the app on one of its TLS connections.
+type TLSData struct {
+	Pid uint32
+	// Conn identifies the connection in the process, e.g. the address of its SSL struct
+	Conn uint64
+	Read bool
+	// Closed is set once the connection is freed, without data
+	Closed bool
+	Data   []byte
+}
+
 type AppInfo interface {
 	SendInode(ctx context.Context, id uint64, inode uint64) error
 }
@@ -81,6 +137,8 @@ type NetworkAddress struct {
 	IPv4Addr uint32
 	IPv6Addr [4]uint32
 	Port     uint32
+	// OutsideApp is set if the connection is of a process outside the app, it is passed through by the proxy.
+	OutsideApp bool
 }
 
 type Sessions struct {
@@ -128,5 +186,8 @@ type Session struct {
 	Mode models.Mode
 	TC   chan<- *models.TestCase
 	MC   chan<- *models.Mock
+	// PlainTLS is set when the TLS connections are recorded from their plaintext captured by the hooks, the proxy
+	// passes them through without decrypting them.
+	PlainTLS bool
 	models.OutgoingOptions
 }
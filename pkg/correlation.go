@@ -0,0 +1,30 @@
+package pkg
+
+import (
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// CorrelationID returns the correlation id carried by the headers of a request, the value of the
+// Keploy-Correlation-Id header or else the trace id of the W3C traceparent header. It is empty if the
+// request carries neither of them.
+func CorrelationID(header map[string]string) string {
+	var traceparent string
+	for k, v := range header {
+		switch {
+		case strings.EqualFold(k, models.CorrelationHeader):
+			if v = strings.TrimSpace(v); v != "" {
+				return v
+			}
+		case strings.EqualFold(k, "traceparent"):
+			traceparent = v
+		}
+	}
+	// traceparent is version-traceid-parentid-flags, the parent id differs in every hop of the request
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
@@ -0,0 +1,34 @@
+package graph
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// serveToken returns the bearer token required by the GraphQL server, KEPLOY_SERVE_TOKEN takes precedence over
+// the config. It is empty if the server is open.
+func (g *Graph) serveToken() string {
+	if token := os.Getenv("KEPLOY_SERVE_TOKEN"); token != "" {
+		return token
+	}
+	return g.config.Serve.Token
+}
+
+// authenticate rejects the requests without the bearer token, if any, as the queries can run the testcases and
+// return the recorded payloads.
+func authenticate(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="keploy"`)
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,153 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// runnerPrefix is the prefix of the endpoints of the runner protocol, versioned so that the adapters of the test
+// frameworks keep working across the releases of keploy. See RUNNER.md for the protocol.
+const runnerPrefix = "/runner/v1"
+
+// runner serves the runner protocol, with which the adapters of the test frameworks, e.g. JUnit5, pytest and Jest,
+// run each test set as a native test. Unlike the GraphQL API, the app is started and stopped by keploy for each
+// test set, so a test set is run with a single blocking call.
+type runner struct {
+	logger *zap.Logger
+	replay replay.Service
+	// mutex guards the session and serialises the test sets, as they share the hooks and the proxy
+	mutex      sync.Mutex
+	hookCtx    context.Context
+	hookCancel context.CancelFunc
+	testRunID  string
+	appID      uint64
+}
+
+// runnerTestSet is the result of a test set in the runner protocol.
+type runnerTestSet struct {
+	TestSetID string           `json:"testSetId"`
+	Status    string           `json:"status"`
+	TestCases []runnerTestCase `json:"testCases"`
+}
+
+type runnerTestCase struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Failure string `json:"failure,omitempty"`
+}
+
+func (r *runner) routes(mux *http.ServeMux, token string) {
+	mux.Handle("GET "+runnerPrefix+"/test-sets", authenticate(token, http.HandlerFunc(r.testSets)))
+	mux.Handle("POST "+runnerPrefix+"/session", authenticate(token, http.HandlerFunc(r.start)))
+	mux.Handle("DELETE "+runnerPrefix+"/session", authenticate(token, http.HandlerFunc(r.stop)))
+	mux.Handle("POST "+runnerPrefix+"/test-sets/{id}/run", authenticate(token, http.HandlerFunc(r.run)))
+}
+
+func (r *runner) testSets(w http.ResponseWriter, req *http.Request) {
+	ids, err := r.replay.GetAllTestSetIDs(req.Context())
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get all test set ids")
+		writeError(w, http.StatusInternalServerError, "failed to get the test sets")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string][]string{"testSets": ids})
+}
+
+// start boots the replay, loading the hooks and starting the proxy, for the test sets run after it.
+func (r *runner) start(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hookCancel != nil {
+		writeError(w, http.StatusConflict, "a session is already started")
+		return
+	}
+
+	g, ctx := errgroup.WithContext(context.WithoutCancel(req.Context()))
+	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
+	testRunID, appID, hookCancel, err := r.replay.BootReplay(ctx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to boot replay")
+		writeError(w, http.StatusInternalServerError, "failed to hook the application")
+		return
+	}
+	r.hookCtx, r.hookCancel, r.testRunID, r.appID = ctx, hookCancel, testRunID, appID
+	writeJSON(w, http.StatusOK, map[string]string{"testRunId": testRunID})
+}
+
+func (r *runner) stop(w http.ResponseWriter, _ *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hookCancel == nil {
+		writeError(w, http.StatusConflict, "no session is started")
+		return
+	}
+	r.stopSession()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// stopSession stops the hooks and the proxy of the session, the mutex is held by the caller.
+func (r *runner) stopSession() {
+	if r.hookCancel == nil {
+		return
+	}
+	r.hookCancel()
+	if g, ok := r.hookCtx.Value(models.ErrGroupKey).(*errgroup.Group); ok {
+		if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+			utils.LogError(r.logger, err, "failed to stop the hooks gracefully")
+		}
+	}
+	r.hookCtx, r.hookCancel = nil, nil
+}
+
+// run runs the test set, starting and stopping the app, and responds with the results of its testcases.
+func (r *runner) run(w http.ResponseWriter, req *http.Request) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.hookCancel == nil {
+		writeError(w, http.StatusConflict, "no session is started")
+		return
+	}
+
+	testSetID := req.PathValue("id")
+	status, err := r.replay.RunTestSet(r.hookCtx, testSetID, r.testRunID, r.appID, false)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to run the test set", zap.String("testSetID", testSetID))
+		writeError(w, http.StatusInternalServerError, "failed to run the test set")
+		return
+	}
+	results, err := r.replay.GetTestCaseResults(req.Context(), r.testRunID, testSetID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the testcase results", zap.String("testSetID", testSetID))
+		writeError(w, http.StatusInternalServerError, "failed to get the results of the test set")
+		return
+	}
+
+	res := runnerTestSet{TestSetID: testSetID, Status: string(status), TestCases: []runnerTestCase{}}
+	for _, result := range results {
+		tc := runnerTestCase{Name: result.TestCaseID, Status: string(result.Status)}
+		if result.Status == models.TestStatusFailed {
+			tc.Failure = replay.Mismatch(result)
+		}
+		res.TestCases = append(res.TestCases, tc)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -42,6 +43,15 @@ func (g *Graph) Serve(ctx context.Context) error {
 		g.config.Port = defaultPort
 	}
 
+	tlsCert, tlsKey := g.config.Serve.TLSCert, g.config.Serve.TLSKey
+	if (tlsCert == "") != (tlsKey == "") {
+		return errors.New("both serve.tlsCert and serve.tlsKey are required to serve over TLS")
+	}
+	token := g.serveToken()
+	if token == "" {
+		g.logger.Warn("the GraphQL server is not protected by a token, set it with KEPLOY_SERVE_TOKEN or serve.token")
+	}
+
 	graphGrp, graphCtx := errgroup.WithContext(ctx)
 
 	resolver := &Resolver{
@@ -49,6 +59,11 @@ func (g *Graph) Serve(ctx context.Context) error {
 		replay: g.replay,
 	}
 
+	run := &runner{
+		logger: g.logger,
+		replay: g.replay,
+	}
+
 	srv := handler.NewDefaultServer(NewExecutableSchema(Config{
 		Resolvers: resolver,
 	}))
@@ -66,6 +81,11 @@ func (g *Graph) Serve(ctx context.Context) error {
 			}
 		}
 
+		// stop the session of the runner protocol, if any
+		run.mutex.Lock()
+		run.stopSession()
+		run.mutex.Unlock()
+
 		// cancel the context of the app in case of sudden stop if the app was started
 		appCtx, appCancel := resolver.getAppCtxWithCancel()
 		if appCtx != nil && appCancel != nil {
@@ -84,8 +104,10 @@ func (g *Graph) Serve(ctx context.Context) error {
 		}
 	}()
 
+	// the playground is a static page, the queries from it need the token in its headers
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", srv)
+	http.Handle("/query", authenticate(token, srv))
+	run.routes(http.DefaultServeMux, token)
 
 	// Create a new http.Server instance
 	httpSrv := &http.Server{
@@ -93,14 +115,25 @@ func (g *Graph) Serve(ctx context.Context) error {
 		Handler: nil, // Use the default http.DefaultServeMux
 	}
 
+	scheme := "http"
+	if tlsCert != "" {
+		scheme = "https"
+	}
+
 	graphGrp.Go(func() error {
 		defer utils.Recover(g.logger)
 		return g.stopGraphqlServer(graphCtx, httpSrv)
 	})
 
-	g.logger.Debug(fmt.Sprintf("connect to http://localhost:%d/ for GraphQL playground", int(g.config.Port)))
-	g.logger.Info("Graphql server started", zap.Int("port", int(g.config.Port)))
-	if err := httpSrv.ListenAndServe(); err != http.ErrServerClosed {
+	g.logger.Debug(fmt.Sprintf("connect to %s://localhost:%d/ for GraphQL playground", scheme, int(g.config.Port)))
+	g.logger.Info("Graphql server started", zap.Int("port", int(g.config.Port)), zap.Bool("tls", tlsCert != ""))
+	var err error
+	if tlsCert != "" {
+		err = httpSrv.ListenAndServeTLS(tlsCert, tlsKey)
+	} else {
+		err = httpSrv.ListenAndServe()
+	}
+	if err != http.ErrServerClosed {
 		stopErr := utils.Stop(g.logger, "Graphql server failed to start")
 		if stopErr != nil {
 			utils.LogError(g.logger, stopErr, "failed to stop Graphql server gracefully")
@@ -0,0 +1,117 @@
+package pkg
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// ParseGraphQLRequest parses the GraphQL operation of a http request. It returns nil if the request is
+// not a POST with a json body carrying the query of the operation.
+func ParseGraphQLRequest(method string, header map[string]string, body string) *models.GraphQLReq {
+	if !strings.EqualFold(method, http.MethodPost) {
+		return nil
+	}
+	for k, v := range header {
+		if strings.EqualFold(k, "Content-Type") && !strings.Contains(strings.ToLower(v), "json") {
+			return nil
+		}
+	}
+
+	var payload struct {
+		Query         *string                `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil || payload.Query == nil {
+		return nil
+	}
+	return &models.GraphQLReq{
+		Query:         *payload.Query,
+		OperationName: payload.OperationName,
+		Variables:     payload.Variables,
+	}
+}
+
+// CompareGraphQLReq reports whether two GraphQL operations are the same. The whitespace, commas and comments of
+// the queries are ignored, and the variables are compared irrespective of the ordering of their keys.
+func CompareGraphQLReq(expected, actual *models.GraphQLReq) bool {
+	if expected == nil || actual == nil {
+		return expected == actual
+	}
+	if expected.OperationName != actual.OperationName {
+		return false
+	}
+	if NormalizeGraphQLQuery(expected.Query) != NormalizeGraphQLQuery(actual.Query) {
+		return false
+	}
+	if len(expected.Variables) == 0 && len(actual.Variables) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(expected.Variables, actual.Variables)
+}
+
+// NormalizeGraphQLQuery strips the insignificant tokens of a GraphQL query i.e. whitespace, commas and comments.
+// A single space is kept between two names so that the tokens of the query stay apart.
+// String literals are copied as is.
+func NormalizeGraphQLQuery(query string) string {
+	var b strings.Builder
+	// last is the last byte written to the normalized query
+	var last byte
+	pendingSpace := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '"':
+			// copy the string literal, including the block strings ("""...""")
+			end := i + 1
+			if strings.HasPrefix(query[i:], `"""`) {
+				if j := strings.Index(query[i+3:], `"""`); j >= 0 {
+					end = i + 3 + j + 3
+				} else {
+					end = len(query)
+				}
+			} else {
+				for end < len(query) && query[end] != '"' {
+					if query[end] == '\\' {
+						end++
+					}
+					end++
+				}
+				end++
+			}
+			if end > len(query) {
+				end = len(query)
+			}
+			if pendingSpace && isGraphQLNameChar(last) {
+				b.WriteByte(' ')
+			}
+			pendingSpace = false
+			b.WriteString(query[i:end])
+			last = '"'
+			i = end - 1
+		case c == '#':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			pendingSpace = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			pendingSpace = true
+		default:
+			if pendingSpace && isGraphQLNameChar(c) && isGraphQLNameChar(last) {
+				b.WriteByte(' ')
+			}
+			pendingSpace = false
+			b.WriteByte(c)
+			last = c
+		}
+	}
+	return b.String()
+}
+
+func isGraphQLNameChar(c byte) bool {
+	return c == '_' || c == '$' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,170 @@
+package pkg
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/protocolbuffers/protoscope"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+)
+
+// the methods of the grpc server reflection service, the bidi streams the tools like grpcurl and the service meshes
+// probe the schema of a server with, one request message per lookup
+var grpcReflectionMethods = map[string]bool{
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      true,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": true,
+}
+
+// IsGrpcReflection reports whether the :path of the grpc call is the server reflection stream.
+func IsGrpcReflection(path string) bool {
+	return grpcReflectionMethods[path]
+}
+
+// CreateLengthPrefixedMessageFromPayload decodes the length prefixed grpc message of a DATA frame payload.
+func CreateLengthPrefixedMessageFromPayload(data []byte) models.GrpcLengthPrefixedMessage {
+	msg := models.GrpcLengthPrefixedMessage{}
+
+	// If the body is not length prefixed, we return the default value.
+	if len(data) < 5 {
+		return msg
+	}
+
+	// The first byte is the compression flag.
+	msg.CompressionFlag = uint(data[0])
+
+	// The next 4 bytes are message length.
+	msg.MessageLength = binary.BigEndian.Uint32(data[1:5])
+
+	// The payload could be empty. We only parse it if it is present.
+	if len(data) >= 5 {
+		// Use protoscope to decode the message.
+		msg.DecodedData = protoscope.Write(data[5:], protoscope.WriterOptions{})
+	}
+
+	return msg
+}
+
+// CreatePayloadFromLengthPrefixedMessage encodes the decoded grpc message back into a DATA frame payload.
+func CreatePayloadFromLengthPrefixedMessage(msg models.GrpcLengthPrefixedMessage) ([]byte, error) {
+	scanner := protoscope.NewScanner(msg.DecodedData)
+	encodedData, err := scanner.Exec()
+	if err != nil {
+		return nil, fmt.Errorf("could not encode grpc msg using protoscope: %v", err)
+	}
+
+	// Note that the encoded length is present in the msg, but it is also equal to the len of encodedData.
+	// We should give the preference to the length of encodedData, since the mocks might have been altered.
+
+	// Reserve 1 byte for compression flag, 4 bytes for length capture.
+	payload := make([]byte, 1+4)
+	payload[0] = uint8(msg.CompressionFlag)
+	binary.BigEndian.PutUint32(payload[1:5], uint32(len(encodedData)))
+	payload = append(payload, encodedData...)
+
+	return payload, nil
+}
+
+// SimulateGRPC sends the recorded grpc request of the testcase to the user app over a plain text
+// HTTP/2 connection and returns the grpc response.
+func SimulateGRPC(ctx context.Context, tc models.TestCase, testSet string, logger *zap.Logger, apiTimeout uint64) (*models.GrpcResp, error) {
+	logger.Info("starting test for of", zap.Any("test case", models.HighlightString(tc.Name)), zap.Any("test set", models.HighlightString(testSet)))
+
+	payload, err := CreatePayloadFromLengthPrefixedMessage(tc.GrpcReq.Body)
+	if err != nil {
+		utils.LogError(logger, err, "failed to create the grpc payload from the yaml document")
+		return nil, err
+	}
+
+	pseudoHeaders := tc.GrpcReq.Headers.PseudoHeaders
+	reqURL := fmt.Sprintf("http://%s%s", pseudoHeaders[":authority"], pseudoHeaders[":path"])
+	method := pseudoHeaders[":method"]
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, bytes.NewReader(payload))
+	if err != nil {
+		utils.LogError(logger, err, "failed to create a grpc request from the yaml document")
+		return nil, err
+	}
+	for key, value := range tc.GrpcReq.Headers.OrdinaryHeaders {
+		// the content length is set from the payload by the transport
+		if strings.EqualFold(key, "content-length") {
+			continue
+		}
+		req.Header.Set(key, value)
+	}
+	req.Header.Set("keploy-test-id", tc.Name)
+	logger.Debug(fmt.Sprintf("Sending grpc request to user app:%v", req))
+
+	client := &http.Client{
+		Timeout: time.Second * time.Duration(apiTimeout),
+		Transport: &http2.Transport{
+			// grpc servers of the user app are reached over h2c (HTTP/2 without tls)
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, addr)
+			},
+		},
+	}
+
+	httpResp, err := client.Do(req)
+	if err != nil {
+		utils.LogError(logger, err, "failed to send testcase request to app")
+		return nil, err
+	}
+	defer func() {
+		if cerr := httpResp.Body.Close(); cerr != nil {
+			utils.LogError(logger, cerr, "failed to close the grpc response body")
+		}
+	}()
+
+	// the trailers are populated only after the body is read completely
+	respBody, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		utils.LogError(logger, err, "failed reading grpc response body")
+		return nil, err
+	}
+
+	resp := &models.GrpcResp{
+		Headers: models.GrpcHeaders{
+			PseudoHeaders:   map[string]string{":status": strconv.Itoa(httpResp.StatusCode)},
+			OrdinaryHeaders: toGrpcHeaders(httpResp.Header),
+		},
+		Body: CreateLengthPrefixedMessageFromPayload(respBody),
+		Trailers: models.GrpcHeaders{
+			PseudoHeaders:   map[string]string{},
+			OrdinaryHeaders: toGrpcHeaders(httpResp.Trailer),
+		},
+	}
+	// a trailers-only response carries the grpc status in the headers
+	if _, ok := resp.Headers.OrdinaryHeaders["grpc-status"]; ok && len(respBody) == 0 {
+		for _, key := range []string{"grpc-status", "grpc-message"} {
+			if value, ok := resp.Headers.OrdinaryHeaders[key]; ok {
+				resp.Trailers.OrdinaryHeaders[key] = value
+				delete(resp.Headers.OrdinaryHeaders, key)
+			}
+		}
+	}
+	return resp, nil
+}
+
+func toGrpcHeaders(header http.Header) map[string]string {
+	headers := map[string]string{}
+	for key, values := range header {
+		headers[strings.ToLower(key)] = strings.Join(values, ",")
+	}
+	return headers
+}
@@ -0,0 +1,162 @@
+// Package keploytest runs the keploy testcases of an app from go test, each test set and each testcase as a
+// subtest, so that their results show up in the go tooling and the IDEs. The test is run with the privileges of
+// keploy test, e.g. sudo -E go test, as it loads the eBPF hooks to mock the outgoing calls of the app.
+//
+//	func TestKeploy(t *testing.T) {
+//		keploytest.Run(t, keploytest.Options{Command: "go run ."})
+//	}
+package keploytest
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.keploy.io/server/v2/cli/provider"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml/configdb"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest"
+	"golang.org/x/sync/errgroup"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// Options of the test run, the keploy.yml in Path is used for the rest of the config if it exists.
+type Options struct {
+	// Command starts the app, it is run for each test set.
+	Command string
+	// Path is the directory of the keploy folder of the app, the working directory if empty.
+	Path string
+	// TestSets are the test sets to run, all of them if empty.
+	TestSets []string
+	// Delay is the time in seconds the app is given to start, the delay of keploy.yml if zero.
+	Delay uint64
+	// Logger logs keploy, the log of the test by default.
+	Logger *zap.Logger
+}
+
+// Run replays the testcases of the app, running each test set as a subtest of t named by its id, and each testcase
+// as a subtest of its test set named by its name. A testcase whose response doesn't match the recorded one fails
+// its subtest with the mismatching fields.
+func Run(t *testing.T, opts Options) {
+	t.Helper()
+	logger := opts.Logger
+	if logger == nil {
+		logger = zaptest.NewLogger(t)
+	}
+	cfg, err := newConfig(opts)
+	if err != nil {
+		t.Fatalf("failed to load the keploy config: %v", err)
+	}
+
+	ctx := context.Background()
+	svcProvider := provider.NewServiceProvider(logger, configdb.NewConfigDb(logger), cfg)
+	services, err := svcProvider.GetCommonServices(ctx, *cfg)
+	if err != nil {
+		t.Fatalf("failed to setup keploy: %v", err)
+	}
+	tel, err := svcProvider.GetTelemetryService(ctx, *cfg)
+	if err != nil {
+		t.Fatalf("failed to setup keploy: %v", err)
+	}
+	replayer := replay.NewReplayer(logger, services.TestDB, services.MockDB, services.ReportDB, tel, services.Instrumentation, *cfg)
+
+	testSetIDs, err := replayer.GetAllTestSetIDs(ctx)
+	if err != nil {
+		t.Fatalf("failed to get the test sets: %v", err)
+	}
+	if len(testSetIDs) == 0 {
+		t.Fatalf("no test sets found in %s, record the testcases with keploy record", cfg.Path)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
+	testRunID, appID, hookCancel, err := replayer.BootReplay(ctx)
+	if err != nil {
+		t.Fatalf("failed to boot the replay: %v", err)
+	}
+	defer func() {
+		hookCancel()
+		if err := g.Wait(); err != nil && !errors.Is(err, context.Canceled) {
+			t.Errorf("failed to stop keploy: %v", err)
+		}
+	}()
+
+	selected := utils.Keys(cfg.Test.SelectedTests)
+	for _, testSetID := range testSetIDs {
+		if len(selected) != 0 && !contains(selected, testSetID) {
+			continue
+		}
+		t.Run(testSetID, func(t *testing.T) {
+			status, err := replayer.RunTestSet(ctx, testSetID, testRunID, appID, false)
+			if err != nil {
+				t.Fatalf("failed to run the test set: %v", err)
+			}
+			results, err := services.ReportDB.GetTestCaseResults(ctx, testRunID, testSetID)
+			if err != nil {
+				t.Fatalf("failed to get the results of the test set: %v", err)
+			}
+			for _, result := range results {
+				t.Run(result.TestCaseID, func(t *testing.T) {
+					if result.Status == models.TestStatusFailed {
+						t.Error(replay.Mismatch(result))
+					}
+				})
+			}
+			switch status {
+			case models.TestSetStatusPassed, models.TestSetStatusFailed:
+			default:
+				t.Errorf("the test set ended with %s", status)
+			}
+		})
+	}
+}
+
+// newConfig returns the config of keploy test for the options, over the keploy.yml of the app if any.
+func newConfig(opts Options) (*config.Config, error) {
+	cfg := *config.New()
+	path, err := filepath.Abs(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(path, "keploy.yml"))
+	if err == nil {
+		err = yamlLib.Unmarshal(data, &cfg)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if opts.Command != "" {
+		cfg.Command = opts.Command
+	}
+	if cfg.Command == "" {
+		return nil, errors.New("missing the command of the app")
+	}
+	if opts.Delay != 0 {
+		cfg.Test.Delay = opts.Delay
+	}
+	if len(opts.TestSets) != 0 {
+		// the selected tests of the defaults are shared with the other configs
+		cfg.Test.SelectedTests = nil
+		config.SetSelectedTests(&cfg, opts.TestSets)
+	}
+	config.ExpandEnv(&cfg)
+	cfg.Path = filepath.Join(path, "keploy")
+	cfg.CommandType = string(utils.FindDockerCmd(cfg.Command))
+	return &cfg, nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
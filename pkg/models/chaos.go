@@ -0,0 +1,86 @@
+package models
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// FaultKind is the kind of fault injected in place of a mock response.
+type FaultKind string
+
+// constants for the faults injected by the chaos options
+const (
+	FaultError    FaultKind = "error"    // an error response e.g. 500 for http
+	FaultReset    FaultKind = "reset"    // the conn is reset without any response
+	FaultTruncate FaultKind = "truncate" // a part of the response is written before the conn is closed
+	FaultTimeout  FaultKind = "timeout"  // no response is written until the application gives up on the call
+)
+
+// ChaosOptions picks the mock responses to be replaced with faults during a test run.
+// It is safe to be used by the concurrent conns of the proxy.
+type ChaosOptions struct {
+	// Percentage of the mock responses to be replaced with faults
+	Percentage float64
+	// Faults to pick from, all the faults are picked if empty
+	Faults []FaultKind
+
+	mutex sync.Mutex
+	rng   *rand.Rand
+}
+
+// NewChaosOptions returns the chaos options with a random source seeded with the given seed, so that a
+// test run can be repeated with the same faults.
+func NewChaosOptions(percentage float64, faults []FaultKind, seed int64) *ChaosOptions {
+	return &ChaosOptions{
+		Percentage: percentage,
+		Faults:     faults,
+		rng:        rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Pick decides whether the current mock response is to be replaced with a fault and returns the fault.
+// Only the faults supported by the caller are picked.
+func (c *ChaosOptions) Pick(supported ...FaultKind) (FaultKind, bool) {
+	if c == nil || c.Percentage <= 0 {
+		return "", false
+	}
+	var faults []FaultKind
+	for _, fault := range supported {
+		if len(c.Faults) == 0 {
+			faults = append(faults, fault)
+			continue
+		}
+		for _, f := range c.Faults {
+			if f == fault {
+				faults = append(faults, fault)
+				break
+			}
+		}
+	}
+	if len(faults) == 0 {
+		return "", false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.rng.Float64()*100 >= c.Percentage {
+		return "", false
+	}
+	return faults[c.rng.Intn(len(faults))], true
+}
+
+// InjectedFault is a fault injected in place of the response of a mock.
+type InjectedFault struct {
+	Mock  string    `json:"mock" yaml:"mock"`
+	Kind  Kind      `json:"kind" yaml:"kind"`
+	Fault FaultKind `json:"fault" yaml:"fault"`
+}
+
+// ChaosReport summarises the testcases of a test set which received injected faults. These testcases are
+// not counted in the success and failure of the test set.
+type ChaosReport struct {
+	Faults int `json:"faults" yaml:"faults"`
+	Total  int `json:"total" yaml:"total"`
+	Passed int `json:"passed" yaml:"passed"`
+	Failed int `json:"failed" yaml:"failed"`
+}
@@ -16,6 +16,9 @@ const (
 	TestSetPattern      string = "test-set-"
 	String              string = "string"
 	TestRunTemplateName string = "test-run-"
+	// SharedMocks is the directory of the mocks shared by the test sets, e.g. the connection handshakes and the
+	// schema fetches at the startup of the app, consulted after the mocks of the test set.
+	SharedMocks string = "shared"
 )
 
 var (
@@ -25,3 +25,53 @@ const (
 	ErrCtxCanceled    AppErrorType = "context canceled"
 	ErrTestBinStopped AppErrorType = "test binary stopped"
 )
+
+// ProxyError is an error of the proxy while handling an outgoing call of the application. Its type tells the
+// failures of the infra apart from the ones of the application under test.
+type ProxyError struct {
+	ProxyErrorType ProxyErrorType
+	Err            error
+}
+
+type ProxyErrorType string
+
+func (e ProxyError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.ProxyErrorType.Message(), e.Err)
+	}
+	return e.ProxyErrorType.Message()
+}
+
+func (e ProxyError) Unwrap() error {
+	return e.Err
+}
+
+// ProxyErrorType is a type of error that can be returned by the parsers of the proxy. Its value is a stable
+// identifier, as it is stored in the reports and sent in the telemetry.
+const (
+	ErrMockMissing     ProxyErrorType = "mock_missing"
+	ErrUpstreamTimeout ProxyErrorType = "upstream_timeout"
+	ErrParse           ProxyErrorType = "parse"
+	ErrClientClosed    ProxyErrorType = "client_closed"
+)
+
+var proxyErrorMessages = map[ProxyErrorType]string{
+	ErrMockMissing:     "no mock matched the outgoing call",
+	ErrUpstreamTimeout: "timed out waiting for the upstream",
+	ErrParse:           "failed to parse the outgoing message",
+	ErrClientClosed:    "client closed the connection",
+}
+
+// Message returns the human readable description of the type.
+func (t ProxyErrorType) Message() string {
+	if msg, ok := proxyErrorMessages[t]; ok {
+		return msg
+	}
+	return string(t)
+}
+
+// OutgoingError is the proxy error of an outgoing call made during the test run of a testcase.
+type OutgoingError struct {
+	Type    ProxyErrorType `json:"type" yaml:"type"`
+	Message string         `json:"message" yaml:"message"`
+}
@@ -0,0 +1,8 @@
+package models
+
+// GraphQLReq holds the parsed operation of a GraphQL request sent over http.
+type GraphQLReq struct {
+	Query         string                 `json:"query" yaml:"query"`
+	OperationName string                 `json:"operationName" yaml:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables" yaml:"variables,omitempty"`
+}
@@ -5,10 +5,13 @@ import (
 )
 
 type GrpcSpec struct {
-	GrpcReq          GrpcReq   `json:"grpcReq" yaml:"grpcReq"`
-	GrpcResp         GrpcResp  `json:"grpcResp" yaml:"grpcResp"`
-	ReqTimestampMock time.Time `json:"reqTimestampMock" yaml:"reqTimestampMock,omitempty"`
-	ResTimestampMock time.Time `json:"resTimestampMock" yaml:"resTimestampMock,omitempty"`
+	Metadata         map[string]string      `json:"metadata" yaml:"metadata,omitempty"`
+	GrpcReq          GrpcReq                `json:"grpcReq" yaml:"grpcReq"`
+	GrpcResp         GrpcResp               `json:"grpcResp" yaml:"grpcResp"`
+	Assertions       map[string]interface{} `json:"assertions" yaml:"assertions,omitempty"`
+	Created          int64                  `json:"created" yaml:"created,omitempty"`
+	ReqTimestampMock time.Time              `json:"reqTimestampMock" yaml:"reqTimestampMock,omitempty"`
+	ResTimestampMock time.Time              `json:"resTimestampMock" yaml:"resTimestampMock,omitempty"`
 }
 
 type GrpcHeaders struct {
@@ -20,17 +23,23 @@ type GrpcLengthPrefixedMessage struct {
 	CompressionFlag uint   `json:"compression_flag" yaml:"compression_flag"`
 	MessageLength   uint32 `json:"message_length" yaml:"message_length"`
 	DecodedData     string `json:"decoded_data" yaml:"decoded_data"`
+	// Type and JSON are the full name of the type of the message and the message as JSON, set for the messages
+	// whose schema is in the protoDir of the config.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+	JSON string `json:"json,omitempty" yaml:"json,omitempty"`
 }
 
 type GrpcReq struct {
-	Headers GrpcHeaders               `json:"headers" yaml:"headers"`
-	Body    GrpcLengthPrefixedMessage `json:"body" yaml:"body"`
+	Headers   GrpcHeaders               `json:"headers" yaml:"headers"`
+	Body      GrpcLengthPrefixedMessage `json:"body" yaml:"body"`
+	Timestamp time.Time                 `json:"timestamp" yaml:"timestamp,omitempty"` // set for the ingress grpc calls captured as testcases
 }
 
 type GrpcResp struct {
-	Headers  GrpcHeaders               `json:"headers" yaml:"headers"`
-	Body     GrpcLengthPrefixedMessage `json:"body" yaml:"body"`
-	Trailers GrpcHeaders               `json:"trailers" yaml:"trailers"`
+	Headers   GrpcHeaders               `json:"headers" yaml:"headers"`
+	Body      GrpcLengthPrefixedMessage `json:"body" yaml:"body"`
+	Trailers  GrpcHeaders               `json:"trailers" yaml:"trailers"`
+	Timestamp time.Time                 `json:"timestamp" yaml:"timestamp,omitempty"`
 }
 
 // GrpcStream is a helper function to combine the request-response model in a single struct.
@@ -16,6 +16,7 @@ type HTTPReq struct {
 	Body       string            `json:"body" yaml:"body"`
 	Binary     string            `json:"binary" yaml:"binary,omitempty"`
 	Form       []FormData        `json:"form" yaml:"form,omitempty"`
+	GraphQL    *GraphQLReq       `json:"graphql,omitempty" yaml:"graphql,omitempty"` // set when the body is a GraphQL operation
 	Timestamp  time.Time         `json:"timestamp" yaml:"timestamp"`
 }
 
@@ -45,4 +46,16 @@ type HTTPResp struct {
 	ProtoMinor    int               `json:"proto_minor" yaml:"proto_minor"`
 	Binary        string            `json:"binary" yaml:"binary,omitempty"`
 	Timestamp     time.Time         `json:"timestamp" yaml:"timestamp"`
+	// Interim are the 1xx responses sent before the response, e.g. 100 Continue or 103 Early Hints.
+	Interim []HTTPInterimResp `json:"interim,omitempty" yaml:"interim,omitempty"`
+	// Trailer are the trailers sent after the chunked body of the response.
+	Trailer map[string]string `json:"trailer,omitempty" yaml:"trailer,omitempty"`
+	// TransferEncoding are the recorded transfer codings of the body, e.g. chunked for a streamed response.
+	TransferEncoding []string `json:"transfer_encoding,omitempty" yaml:"transfer_encoding,omitempty"`
+}
+
+// HTTPInterimResp is an interim 1xx response.
+type HTTPInterimResp struct {
+	StatusCode int               `json:"status_code" yaml:"status_code"`
+	Header     map[string]string `json:"header" yaml:"header"`
 }
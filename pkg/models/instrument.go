@@ -17,6 +17,45 @@ type OutgoingOptions struct {
 	// TODO: role of SQLDelay should be mentioned in the comments.
 	SQLDelay       time.Duration // This is the same as Application delay.
 	FallBackOnMiss bool          // this enables to pass the request to the actual server if no mock is found during test mode.
+	// StrictMocking fails an outgoing call which doesn't match any mock with models.ErrMockMissing during test mode,
+	// instead of passing it through to the actual server.
+	StrictMocking bool
+	// LatencyMultiplier scales the recorded latency of a mock to delay its response with during test mode, 0 disables the delay.
+	LatencyMultiplier float64
+	// Chaos replaces a percentage of the mock responses with faults during test mode, nil disables it.
+	Chaos *ChaosOptions
+	// ReadTimeout is how long the parsers wait for the next chunk of a message, 0 for their default.
+	ReadTimeout time.Duration
+	// IgnoreHeaderKeys matches the http mocks regardless of the header keys of the request, for the clients other
+	// than the recorded app which send other headers.
+	IgnoreHeaderKeys bool
+	// StreamChunkSize is the size in bytes of the parts the http mock bodies are written to the app in, the
+	// recorded chunked bodies are sent chunked. 0 writes the response at once.
+	StreamChunkSize int
+	// Revalidate sends a 304 Not Modified for the http mock whose response is still valid for the conditional
+	// request, instead of the recorded response.
+	Revalidate bool
+	// OAuthTokens matches the OAuth2 token requests to the http mocks by their grant type and reissues the JWTs of
+	// their responses at the time of the replay, keeping their lifetime. The reissued JWTs are signed with keys of
+	// keploy which are added to the JWKS responses of the mocks. The later calls with the reissued tokens match as
+	// the values of the headers aren't matched.
+	OAuthTokens bool
+	// SharedMocks are the mocks shared by the test sets, matched once the mocks of the test set don't match. They
+	// are reused across the calls.
+	SharedMocks []*Mock
+	// OnMockEvent is called with each outgoing call mocked during test mode, whether it matched a mock or not, e.g.
+	// to show the calls live. It is called from the goroutines of the parsers.
+	OnMockEvent func(MockEvent) `json:"-"`
+}
+
+// MockEvent is the outcome of an outgoing call during test mode: the mock it matched, or the error of the proxy
+// if it matched none.
+type MockEvent struct {
+	Time  time.Time
+	Hit   bool
+	Mock  string
+	Kind  Kind
+	Error string
 }
 
 type IncomingOptions struct {
@@ -27,6 +66,13 @@ type SetupOptions struct {
 	Container     string
 	DockerNetwork string
 	DockerDelay   time.Duration
+	// Env is added to the environment of the native app e.g. to preload a shim in it.
+	Env []string
+	// RandomSeed seeds the getrandom and /dev/urandom of the native app, 0 disables it.
+	RandomSeed int64
+	// Client runs the app as the client of keploy agent in its directory and environment, nil to run it as the
+	// user who invoked sudo.
+	Client *config.Client
 }
 
 type RunOptions struct {
@@ -11,10 +11,32 @@ type Mock struct {
 	ConnectionID string       `json:"ConnectionId,omitempty" bson:"ConnectionId,omitempty"`
 }
 
+// CorrelationIDKey is the metadata key of the correlation id of a testcase and of the mocks recorded while
+// serving it, the id is carried by the CorrelationHeader of the request of the testcase and propagated by the
+// app to its outgoing calls.
+const CorrelationIDKey = "correlationId"
+
+// DestinationAddrKey and SNIKey are the metadata keys of the address the connection of the mock was sent to and
+// of the server name of its TLS connection.
+const (
+	DestinationAddrKey = "destinationAddr"
+	SNIKey             = "sni"
+)
+
+// DependencyKey is the metadata key of the logical name of the dependency the mock was recorded from, e.g.
+// payments-api, see config.Dependency.
+const DependencyKey = "dependency"
+
+// CorrelationHeader carries the correlation id of a request, the trace id of the traceparent header is used
+// in its absence.
+const CorrelationHeader = "Keploy-Correlation-Id"
+
 type TestModeInfo struct {
 	ID         int  `json:"Id,omitempty" bson:"Id,omitempty"`
 	IsFiltered bool `json:"isFiltered,omitempty" bson:"isFiltered,omitempty"`
 	SortOrder  int  `json:"sortOrder,omitempty" bson:"SortOrder,omitempty"`
+	// TestSetID is the test set the mock is read from, the names of the mocks repeat across the test sets
+	TestSetID string `json:"testSetId,omitempty" bson:"TestSetId,omitempty"`
 }
 
 func (m *Mock) GetKind() string {
@@ -0,0 +1,132 @@
+package models
+
+// OpenAPI is a minimal representation of an OpenAPI 3 document. Only the fields
+// required to generate testcases from a spec and to infer a spec from testcases are modelled.
+type OpenAPI struct {
+	OpenAPI    string                      `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIInfo                 `json:"info" yaml:"info"`
+	Servers    []OpenAPIServer             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      map[string]*OpenAPIPathItem `json:"paths" yaml:"paths"`
+	Components *OpenAPIComponents          `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+type OpenAPIServer struct {
+	URL         string `json:"url" yaml:"url"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas    map[string]*OpenAPISchema    `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Parameters map[string]*OpenAPIParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+}
+
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Get        *OpenAPIOperation   `json:"get,omitempty" yaml:"get,omitempty"`
+	Put        *OpenAPIOperation   `json:"put,omitempty" yaml:"put,omitempty"`
+	Post       *OpenAPIOperation   `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete     *OpenAPIOperation   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options    *OpenAPIOperation   `json:"options,omitempty" yaml:"options,omitempty"`
+	Head       *OpenAPIOperation   `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch      *OpenAPIOperation   `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+// Operations returns the operations of the path item keyed by their http method, in a stable order.
+func (p *OpenAPIPathItem) Operations() ([]string, map[string]*OpenAPIOperation) {
+	methods := []string{"GET", "PUT", "POST", "DELETE", "OPTIONS", "HEAD", "PATCH"}
+	ops := map[string]*OpenAPIOperation{
+		"GET":     p.Get,
+		"PUT":     p.Put,
+		"POST":    p.Post,
+		"DELETE":  p.Delete,
+		"OPTIONS": p.Options,
+		"HEAD":    p.Head,
+		"PATCH":   p.Patch,
+	}
+	var present []string
+	for _, m := range methods {
+		if ops[m] != nil {
+			present = append(present, m)
+		} else {
+			delete(ops, m)
+		}
+	}
+	return present, ops
+}
+
+// SetOperation sets the operation of the path item for the given http method.
+func (p *OpenAPIPathItem) SetOperation(method string, op *OpenAPIOperation) {
+	switch method {
+	case "GET":
+		p.Get = op
+	case "PUT":
+		p.Put = op
+	case "POST":
+		p.Post = op
+	case "DELETE":
+		p.Delete = op
+	case "OPTIONS":
+		p.Options = op
+	case "HEAD":
+		p.Head = op
+	case "PATCH":
+		p.Patch = op
+	}
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Summary     string                      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Tags        []string                    `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Ref      string         `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name     string         `json:"name,omitempty" yaml:"name,omitempty"`
+	In       string         `json:"in,omitempty" yaml:"in,omitempty"` // one of path, query, header or cookie
+	Required bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  interface{}    `json:"example,omitempty" yaml:"example,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                         `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content" yaml:"content"`
+}
+
+type OpenAPIResponse struct {
+	Description string                       `json:"description" yaml:"description"`
+	Content     map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema            `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  interface{}               `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples map[string]OpenAPIExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Summary string      `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Value   interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+type OpenAPISchema struct {
+	Ref        string                    `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type       string                    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format     string                    `json:"format,omitempty" yaml:"format,omitempty"`
+	Properties map[string]*OpenAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Items      *OpenAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Required   []string                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Enum       []interface{}             `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Example    interface{}               `json:"example,omitempty" yaml:"example,omitempty"`
+	Default    interface{}               `json:"default,omitempty" yaml:"default,omitempty"`
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+)
+
+// PostmanSchemaV21 is the schema of the postman collections generated by keploy
+const PostmanSchemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// PostmanCollection is a minimal representation of a Postman v2.1 collection.
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanVariable `json:"variable,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID string `json:"_postman_id,omitempty"`
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+}
+
+// PostmanItem is either a request or a folder of items.
+type PostmanItem struct {
+	Name     string            `json:"name"`
+	Item     []PostmanItem     `json:"item,omitempty"`
+	Request  *PostmanRequest   `json:"request,omitempty"`
+	Response []PostmanResponse `json:"response,omitempty"`
+}
+
+type PostmanRequest struct {
+	Method string          `json:"method"`
+	Header []PostmanHeader `json:"header"`
+	Body   *PostmanBody    `json:"body,omitempty"`
+	URL    PostmanURL      `json:"url"`
+}
+
+type PostmanHeader struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type PostmanBody struct {
+	Mode       string          `json:"mode"`
+	Raw        string          `json:"raw,omitempty"`
+	URLEncoded []PostmanHeader `json:"urlencoded,omitempty"`
+	Options    *PostmanOptions `json:"options,omitempty"`
+}
+
+type PostmanOptions struct {
+	Raw PostmanRawOptions `json:"raw"`
+}
+
+type PostmanRawOptions struct {
+	Language string `json:"language"`
+}
+
+// PostmanURL is the url of a postman request. Postman allows it to be either a string or an object.
+type PostmanURL struct {
+	Raw   string          `json:"raw"`
+	Host  []string        `json:"host,omitempty"`
+	Path  []string        `json:"path,omitempty"`
+	Query []PostmanHeader `json:"query,omitempty"`
+}
+
+func (u *PostmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type postmanURL PostmanURL
+	var url postmanURL
+	if err := json.Unmarshal(data, &url); err != nil {
+		return err
+	}
+	*u = PostmanURL(url)
+	return nil
+}
+
+type PostmanResponse struct {
+	Name            string          `json:"name"`
+	OriginalRequest *PostmanRequest `json:"originalRequest,omitempty"`
+	Status          string          `json:"status"`
+	Code            int             `json:"code"`
+	Header          []PostmanHeader `json:"header"`
+	Body            string          `json:"body"`
+}
+
+type PostmanVariable struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+// PostmanEnvironment is an exported postman environment file.
+type PostmanEnvironment struct {
+	Name   string            `json:"name"`
+	Values []PostmanVariable `json:"values"`
+}
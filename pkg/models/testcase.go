@@ -1,5 +1,10 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type Kind string
 type BodyType string
 type Version string
@@ -30,6 +35,8 @@ const (
 	BodyTypeBinary BodyType = "binary"
 	BodyTypePlain  BodyType = "PLAIN"
 	BodyTypeJSON   BodyType = "JSON"
+	BodyTypeXML    BodyType = "XML"
+	BodyTypeHTML   BodyType = "HTML"
 	BodyTypeError  BodyType = "ERROR"
 )
 
@@ -50,8 +57,74 @@ type TestCase struct {
 	Mocks    []*Mock             `json:"mocks" bson:"mocks"`
 	Type     string              `json:"type" bson:"type"`
 	Curl     string              `json:"curl" bson:"curl"`
+	// MaxLatencyMs is the maximum latency in milliseconds allowed for the response of the testcase during replay.
+	MaxLatencyMs uint64 `json:"max_latency_ms" bson:"max_latency_ms"`
+	// Capture are the JSONPath expressions of the fields of the actual response which are captured into the test
+	// report, whether the testcase passes or not, e.g. $.data.id, or header.X-Request-Id for a header.
+	Capture []string `json:"capture" bson:"capture"`
+	// CorrelationID is the correlation id of the request of the testcase, the mocks recorded while serving it have the same id.
+	CorrelationID string `json:"correlation_id" bson:"correlation_id"`
+	// Tags are the user-defined labels of the testcase e.g. smoke, to run a subset of the testcases with --tags.
+	Tags []string `json:"tags" bson:"tags"`
+	// Description is what the testcase is about, annotated while recording it.
+	Description string `json:"description" bson:"description"`
+	// ClientIP and ClientPort are the address of the client which sent the request of the testcase, and UserAgent
+	// its user agent if record.captureUserAgent is set.
+	ClientIP   string `json:"client_ip" bson:"client_ip"`
+	ClientPort uint16 `json:"client_port" bson:"client_port"`
+	UserAgent  string `json:"user_agent" bson:"user_agent"`
 }
 
+// TagsKey is the metadata key of the comma separated tags of a testcase, and TagHeader carries the comma separated
+// tags of a request recorded as a testcase. DescriptionKey is the metadata key of the description of a testcase,
+// and the ClientIPKey, ClientPortKey and UserAgentKey are of the client which sent its request.
+const (
+	TagsKey        = "tags"
+	TagHeader      = "X-Keploy-Tag"
+	DescriptionKey = "description"
+	ClientIPKey    = "clientIP"
+	ClientPortKey  = "clientPort"
+	UserAgentKey   = "userAgent"
+)
+
 func (tc *TestCase) GetKind() string {
 	return string(tc.Kind)
 }
+
+// HasAnyTag reports whether the testcase has any of the tags.
+func (tc *TestCase) HasAnyTag(tags []string) bool {
+	for _, tag := range tags {
+		for _, t := range tc.Tags {
+			if strings.EqualFold(t, tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ReqTimestamp returns the time at which the request of the testcase was captured.
+func (tc *TestCase) ReqTimestamp() time.Time {
+	if tc.Kind == GRPC_EXPORT {
+		return tc.GrpcReq.Timestamp
+	}
+	return tc.HTTPReq.Timestamp
+}
+
+// RespTimestamp returns the time at which the response of the testcase was captured.
+func (tc *TestCase) RespTimestamp() time.Time {
+	if tc.Kind == GRPC_EXPORT {
+		return tc.GrpcResp.Timestamp
+	}
+	return tc.HTTPResp.Timestamp
+}
+
+// RecordedLatency returns the latency of the response captured while recording the testcase.
+// It is zero if either of the timestamps is missing.
+func (tc *TestCase) RecordedLatency() time.Duration {
+	reqTime, respTime := tc.ReqTimestamp(), tc.RespTimestamp()
+	if reqTime.IsZero() || respTime.IsZero() || respTime.Before(reqTime) {
+		return 0
+	}
+	return respTime.Sub(reqTime)
+}
@@ -5,14 +5,45 @@ import (
 )
 
 type TestReport struct {
-	Version Version      `json:"version" yaml:"version"`
-	Name    string       `json:"name" yaml:"name"`
-	Status  string       `json:"status" yaml:"status"`
-	Success int          `json:"success" yaml:"success"`
-	Failure int          `json:"failure" yaml:"failure"`
-	Total   int          `json:"total" yaml:"total"`
-	Tests   []TestResult `json:"tests" yaml:"tests,omitempty"`
-	TestSet string       `json:"testSet" yaml:"test_set"`
+	Version Version       `json:"version" yaml:"version"`
+	Name    string        `json:"name" yaml:"name"`
+	Status  string        `json:"status" yaml:"status"`
+	Success int           `json:"success" yaml:"success"`
+	Failure int           `json:"failure" yaml:"failure"`
+	Total   int           `json:"total" yaml:"total"`
+	Tests   []TestResult  `json:"tests" yaml:"tests,omitempty"`
+	TestSet string        `json:"testSet" yaml:"test_set"`
+	Timing  *TimingReport `json:"timing,omitempty" yaml:"timing,omitempty"`
+	Chaos   *ChaosReport  `json:"chaos,omitempty" yaml:"chaos,omitempty"`
+	// ProxyErrors counts the errors of the proxy while mocking the outgoing calls of the testcases, by type
+	ProxyErrors map[ProxyErrorType]int `json:"proxyErrors,omitempty" yaml:"proxy_errors,omitempty"`
+	// Dependencies are the stats of the mocks of each dependency the mocks were recorded from, by its name
+	Dependencies []DependencyReport `json:"dependencies,omitempty" yaml:"dependencies,omitempty"`
+}
+
+// CoverageReport is the statement coverage of the app over a test run, in percent, compared between the test runs.
+type CoverageReport struct {
+	Percent float64 `json:"percent" yaml:"percent"`
+}
+
+// DependencyReport counts the mocks of a dependency of the test set, the ones consumed during the test run, and the
+// failed testcases which consumed them.
+type DependencyReport struct {
+	Name     string `json:"name" yaml:"name"`
+	Mocks    int    `json:"mocks" yaml:"mocks"`
+	Consumed int    `json:"consumed" yaml:"consumed"`
+	Failed   int    `json:"failed" yaml:"failed"`
+}
+
+// TimingReport compares the latencies of the responses during the test run with the ones recorded for the testcases.
+// All the latencies are in milliseconds.
+type TimingReport struct {
+	RecordedP50 float64 `json:"recordedP50" yaml:"recorded_p50"`
+	RecordedP95 float64 `json:"recordedP95" yaml:"recorded_p95"`
+	ActualP50   float64 `json:"actualP50" yaml:"actual_p50"`
+	ActualP95   float64 `json:"actualP95" yaml:"actual_p95"`
+	P50Delta    float64 `json:"p50Delta" yaml:"p50_delta"`
+	P95Delta    float64 `json:"p95Delta" yaml:"p95_delta"`
 }
 
 func (tr *TestReport) GetKind() string {
@@ -20,18 +51,45 @@ func (tr *TestReport) GetKind() string {
 }
 
 type TestResult struct {
-	Kind         Kind       `json:"kind" yaml:"kind"`
-	Name         string     `json:"name" yaml:"name"`
-	Status       TestStatus `json:"status" yaml:"status"`
-	Started      int64      `json:"started" yaml:"started"`
-	Completed    int64      `json:"completed" yaml:"completed"`
-	TestCasePath string     `json:"testCasePath" yaml:"test_case_path"`
-	MockPath     string     `json:"mockPath" yaml:"mock_path"`
-	TestCaseID   string     `json:"testCaseID" yaml:"test_case_id"`
-	Req          HTTPReq    `json:"req" yaml:"req,omitempty"`
-	Res          HTTPResp   `json:"resp" yaml:"resp,omitempty"`
-	Noise        Noise      `json:"noise" yaml:"noise,omitempty"`
-	Result       Result     `json:"result" yaml:"result"`
+	Kind         Kind            `json:"kind" yaml:"kind"`
+	Name         string          `json:"name" yaml:"name"`
+	Status       TestStatus      `json:"status" yaml:"status"`
+	Started      int64           `json:"started" yaml:"started"`
+	Completed    int64           `json:"completed" yaml:"completed"`
+	TestCasePath string          `json:"testCasePath" yaml:"test_case_path"`
+	MockPath     string          `json:"mockPath" yaml:"mock_path"`
+	TestCaseID   string          `json:"testCaseID" yaml:"test_case_id"`
+	Req          HTTPReq         `json:"req" yaml:"req,omitempty"`
+	Res          HTTPResp        `json:"resp" yaml:"resp,omitempty"`
+	GrpcReq      GrpcReq         `json:"grpcReq" yaml:"grpcReq,omitempty"`
+	GrpcRes      GrpcResp        `json:"grpcResp" yaml:"grpcResp,omitempty"`
+	Noise        Noise           `json:"noise" yaml:"noise,omitempty"`
+	Result       Result          `json:"result" yaml:"result"`
+	Faults       []InjectedFault `json:"faults" yaml:"faults,omitempty"` // faults injected in place of the mock responses
+	// ProxyErrors are the errors of the proxy while mocking the outgoing calls of the testcase, which tell a failure
+	// of the infra apart from a failed assertion.
+	ProxyErrors []OutgoingError `json:"proxyErrors" yaml:"proxy_errors,omitempty"`
+	// OutgoingDiff are the outgoing calls of the testcase which differ from the mocks recorded for it, nil if none.
+	OutgoingDiff *OutgoingDiff `json:"outgoingDiff,omitempty" yaml:"outgoing_diff,omitempty"`
+	// ConsumedMocks are the names of the mocks consumed during the test run of the testcase. It is not omitted when
+	// empty, so that the results which consumed no mocks can be told apart from the results recorded before it was added.
+	ConsumedMocks []string `json:"consumedMocks" yaml:"consumed_mocks"`
+	// AppLogs is the stdout and stderr of the app written during the test run of the testcase, if test.captureAppLogs is set.
+	AppLogs string `json:"appLogs" yaml:"app_logs,omitempty"`
+	// CorrelationID is the correlation id of the testcase and CorrelatedMocks are the names of the mocks recorded
+	// with the same id, to be compared with the ConsumedMocks.
+	CorrelationID   string   `json:"correlationID" yaml:"correlation_id,omitempty"`
+	CorrelatedMocks []string `json:"correlatedMocks" yaml:"correlated_mocks,omitempty"`
+	// Captured are the values of the fields of the actual response captured by the capture expressions of the
+	// testcase, by the expression. The value of an expression which matched nothing is null.
+	Captured map[string]interface{} `json:"captured,omitempty" yaml:"captured,omitempty"`
+}
+
+// OutgoingDiff compares the outgoing calls made during the test run of a testcase with the mocks recorded in
+// its time window.
+type OutgoingDiff struct {
+	Missing []string `json:"missing" yaml:"missing,omitempty"` // mocks recorded for the testcase which weren't called
+	Extra   []string `json:"extra" yaml:"extra,omitempty"`     // calls made which weren't recorded for the testcase
 }
 
 func (tr *TestResult) GetKind() string {
@@ -77,6 +135,16 @@ type Result struct {
 	HeadersResult []HeaderResult `json:"headers_result" bson:"headers_result" yaml:"headers_result"`
 	BodyResult    []BodyResult   `json:"body_result" bson:"body_result" yaml:"body_result"`
 	DepResult     []DepResult    `json:"dep_result" bson:"dep_result" yaml:"dep_result"`
+	LatencyResult *LatencyResult `json:"latency_result,omitempty" bson:"latency_result,omitempty" yaml:"latency_result,omitempty"`
+}
+
+// LatencyResult holds the latencies of the response of a testcase in milliseconds. Max is zero if the
+// testcase has no latency assertion.
+type LatencyResult struct {
+	Normal   bool    `json:"normal" bson:"normal" yaml:"normal"`
+	Max      uint64  `json:"max" bson:"max" yaml:"max,omitempty"`
+	Recorded float64 `json:"recorded" bson:"recorded" yaml:"recorded"`
+	Actual   float64 `json:"actual" bson:"actual" yaml:"actual"`
 }
 
 type DepResult struct {
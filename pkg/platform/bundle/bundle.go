@@ -0,0 +1,285 @@
+// Package bundle provides the .keploy bundle, a single zstd compressed tar of the keploy directory. The first entry
+// of the tar is the index of the bundled files, so the content of a bundle can be listed without extracting it.
+package bundle
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// IndexName is the name of the index entry of the bundle.
+const IndexName = "index.json"
+
+type Index struct {
+	Version   string    `json:"version"` // version of keploy which packed the bundle
+	CreatedAt time.Time `json:"createdAt"`
+	Files     []File    `json:"files"`
+}
+
+type File struct {
+	Name   string `json:"name"` // slash separated path of the file relative to the keploy directory
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Pack writes the regular files of dir, for which selected returns true, into the bundle file. The bundle is
+// written next to the file first and renamed over it once complete, so a failed pack keeps the previous bundle.
+// Nothing is written if no file is selected.
+func Pack(ctx context.Context, dir, file, version string, selected func(name string) bool) (*Index, error) {
+	index := &Index{
+		Version:   version,
+		CreatedAt: time.Now().UTC(),
+	}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !selected(name) {
+			return nil
+		}
+		size, sum, err := hashFile(p)
+		if err != nil {
+			return err
+		}
+		index.Files = append(index.Files, File{Name: name, Size: size, SHA256: sum})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(index.Files) == 0 {
+		return index, nil
+	}
+
+	err = os.MkdirAll(filepath.Dir(file), fs.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".tmp-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+	err = write(ctx, tmp, dir, index)
+	if err != nil {
+		return nil, err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return nil, err
+	}
+	err = os.Rename(tmp.Name(), file)
+	if err != nil {
+		return nil, err
+	}
+	return index, nil
+}
+
+func write(ctx context.Context, w io.Writer, dir string, index *Index) error {
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(zw)
+
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = tw.WriteHeader(&tar.Header{
+		Name:    IndexName,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: index.CreatedAt,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = tw.Write(data)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range index.Files {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err = writeFile(tw, filepath.Join(dir, filepath.FromSlash(f.Name)), f, index.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to bundle %s: %w", f.Name, err)
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeFile(tw *tar.Writer, p string, f File, modTime time.Time) error {
+	src, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	err = tw.WriteHeader(&tar.Header{
+		Name:    f.Name,
+		Mode:    0644,
+		Size:    f.Size,
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+	// the size in the header was hashed before, a file changed since then fails the copy instead of corrupting the tar
+	_, err = io.CopyN(tw, src, f.Size)
+	return err
+}
+
+// Unpack extracts the files of the bundle, for which selected returns true, into dir. The content of each file is
+// verified against the checksum of the index.
+func Unpack(ctx context.Context, file, dir string, selected func(name string) bool) (*Index, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	index, err := readIndex(tr)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]File, len(index.Files))
+	for _, f := range index.Files {
+		files[f.Name] = f
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return index, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the bundle: %w", err)
+		}
+		entry, ok := files[hdr.Name]
+		if !ok {
+			return nil, fmt.Errorf("the file %s is missing in the index of the bundle", hdr.Name)
+		}
+		if !selected(entry.Name) {
+			continue
+		}
+		name := path.Clean(entry.Name)
+		if name == "." || name == ".." || path.IsAbs(name) || strings.HasPrefix(name, "../") {
+			return nil, fmt.Errorf("the file %s of the bundle is outside of the keploy directory", entry.Name)
+		}
+		err = extract(tr, filepath.Join(dir, filepath.FromSlash(name)), entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract %s: %w", entry.Name, err)
+		}
+	}
+}
+
+func extract(r io.Reader, p string, f File) error {
+	err := os.MkdirAll(filepath.Dir(p), fs.ModePerm)
+	if err != nil {
+		return err
+	}
+	dst, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer dst.Close()
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(dst, h), r)
+	if err != nil {
+		return err
+	}
+	if n != f.Size || hex.EncodeToString(h.Sum(nil)) != f.SHA256 {
+		return errors.New("the content doesn't match the checksum of the index, the bundle is corrupted")
+	}
+	return dst.Close()
+}
+
+// ReadIndex returns the index of the bundle without extracting its files.
+func ReadIndex(file string) (*Index, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return readIndex(tar.NewReader(zr))
+}
+
+func readIndex(tr *tar.Reader) (*Index, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the bundle: %w", err)
+	}
+	if hdr.Name != IndexName {
+		return nil, fmt.Errorf("invalid bundle, expected %s as the first entry but found %s", IndexName, hdr.Name)
+	}
+	var index Index
+	err = json.NewDecoder(tr).Decode(&index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the index of the bundle: %w", err)
+	}
+	return &index, nil
+}
+
+func hashFile(p string) (int64, string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
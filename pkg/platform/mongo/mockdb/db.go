@@ -0,0 +1,166 @@
+// Package mockdb provides the storage of the mocks in MongoDB.
+package mockdb
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/mongo"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	yamlMockDB "go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoLib "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// MockMongo stores each mock as a doc of the mocks collection, in the order of their recording.
+// The doc holds the same fields as the yaml doc of the mock.
+type MockMongo struct {
+	collection *mongoLib.Collection
+	logger     *zap.Logger
+	idCounter  int64
+	// cipher encrypts and decrypts the mocks, nil keeps them in plain text
+	cipher *yaml.Cipher
+}
+
+type mockDoc struct {
+	TestSetID string `bson:"testSetId"`
+	Name      string `bson:"name"`
+	Doc       bson.D `bson:"doc"`
+	Encrypted bool   `bson:"encrypted,omitempty"`
+}
+
+func New(logger *zap.Logger, db *mongoLib.Database, cipher *yaml.Cipher) *MockMongo {
+	return &MockMongo{
+		collection: db.Collection(mongo.Mocks),
+		logger:     logger,
+		idCounter:  -1,
+		cipher:     cipher,
+	}
+}
+
+// UpdateMocks deletes the mocks of the test set except the ones with given names
+//
+// mockNames is a map which contains the name of the mocks as key and a isConfig boolean as value
+func (ms *MockMongo) UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error {
+	names := make([]string, 0, len(mockNames))
+	for name := range mockNames {
+		names = append(names, name)
+	}
+	ms.logger.Debug("logging the names of the used mocks", zap.Any("mockNames", names), zap.Any("for testset", testSetID))
+
+	filter := bson.D{
+		{Key: "testSetId", Value: testSetID},
+		{Key: "name", Value: bson.D{{Key: "$nin", Value: names}}},
+	}
+	res, err := ms.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to delete the unused mocks from mongodb", zap.Any("for testset", testSetID))
+		return err
+	}
+	ms.logger.Debug("deleted the unused mocks", zap.Int64("count", res.DeletedCount), zap.Any("for testset", testSetID))
+	return nil
+}
+
+func (ms *MockMongo) InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error {
+	mock.Name = fmt.Sprint("mock-", ms.getNextID())
+	mockYaml, err := yamlMockDB.EncodeMock(mock, ms.logger)
+	if err != nil {
+		return err
+	}
+	doc, encrypted, err := mongo.EncodeTrafficDoc(mockYaml, ms.cipher)
+	if err != nil {
+		return err
+	}
+	_, err = ms.collection.InsertOne(ctx, mockDoc{TestSetID: testSetID, Name: mock.Name, Doc: doc, Encrypted: encrypted})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (ms *MockMongo) GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
+	mocks, err := ms.GetMocks(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	return yamlMockDB.FilterTcsMocks(mocks, afterTime, beforeTime, ms.logger), nil
+}
+
+func (ms *MockMongo) GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
+	mocks, err := ms.GetMocks(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	return yamlMockDB.FilterConfigMocks(mocks, afterTime, beforeTime, ms.logger), nil
+}
+
+// GetMocks returns all the mocks of the test set in the order of their recording.
+func (ms *MockMongo) GetMocks(ctx context.Context, testSetID string) ([]*models.Mock, error) {
+	cursor, err := ms.collection.Find(ctx, bson.D{{Key: "testSetId", Value: testSetID}}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to find the mocks in mongodb", zap.Any("session", testSetID))
+		return nil, err
+	}
+	var docs []mockDoc
+	err = cursor.All(ctx, &docs)
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to read the mocks from mongodb", zap.Any("session", testSetID))
+		return nil, err
+	}
+
+	mockYamls := make([]*yaml.NetworkTrafficDoc, 0, len(docs))
+	for _, doc := range docs {
+		var mockYaml yaml.NetworkTrafficDoc
+		err = mongo.DecodeTrafficDoc(doc.Doc, doc.Encrypted, &mockYaml)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the mock doc %s. error: %v", doc.Name, err.Error())
+		}
+		mockYamls = append(mockYamls, &mockYaml)
+	}
+	mocks, err := yamlMockDB.DecodeMocks(mockYamls, ms.cipher, ms.logger)
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to decode the mocks from mongodb docs", zap.Any("session", testSetID))
+		return nil, err
+	}
+	for _, mock := range mocks {
+		mock.TestModeInfo.TestSetID = testSetID
+	}
+	return mocks, nil
+}
+
+// UpdateMock replaces the doc of the mock of the test set with the same name, keeping its order.
+func (ms *MockMongo) UpdateMock(ctx context.Context, testSetID string, mock *models.Mock) error {
+	mockYaml, err := yamlMockDB.EncodeMock(mock, ms.logger)
+	if err != nil {
+		return err
+	}
+	doc, encrypted, err := mongo.EncodeTrafficDoc(mockYaml, ms.cipher)
+	if err != nil {
+		return err
+	}
+	filter := bson.D{{Key: "testSetId", Value: testSetID}, {Key: "name", Value: mock.Name}}
+	res, err := ms.collection.UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: bson.D{{Key: "doc", Value: doc}, {Key: "encrypted", Value: encrypted}}}})
+	if err != nil {
+		utils.LogError(ms.logger, err, "failed to update the mock in mongodb", zap.Any("for testset", testSetID))
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("no mock named %s found in the test set %s", mock.Name, testSetID)
+	}
+	return nil
+}
+
+// Close does nothing, the mocks are inserted into mongodb without keeping anything open.
+func (ms *MockMongo) Close() error {
+	return nil
+}
+
+func (ms *MockMongo) getNextID() int64 {
+	return atomic.AddInt64(&ms.idCounter, 1)
+}
@@ -0,0 +1,214 @@
+// Package mongo provides the storage of the testcases, mocks and reports in MongoDB, so that the recorded
+// artifacts of many services can be kept in one place instead of the yaml files of each repository.
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	mongoLib "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// The collections of the keploy database.
+const (
+	TestCases = "testcases"
+	Mocks     = "mocks"
+	Reports   = "reports"
+)
+
+// Connect connects to the MongoDB server of the uri and returns the given database, after creating the indexes
+// of its collections.
+func Connect(ctx context.Context, uri string, database string) (*mongoLib.Database, error) {
+	client, err := mongoLib.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to mongodb: %w", err)
+	}
+	err = client.Ping(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ping mongodb: %w", err)
+	}
+	db := client.Database(database)
+
+	indexes := map[string]mongoLib.IndexModel{
+		TestCases: {
+			Keys:    bson.D{{Key: "testSetId", Value: 1}, {Key: "name", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		Mocks: {
+			Keys: bson.D{{Key: "testSetId", Value: 1}, {Key: "_id", Value: 1}},
+		},
+		Reports: {
+			Keys:    bson.D{{Key: "testRunId", Value: 1}, {Key: "testSetId", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+	}
+	for collection, index := range indexes {
+		_, err = db.Collection(collection).Indexes().CreateOne(ctx, index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the index of the %s collection: %w", collection, err)
+		}
+	}
+	return db, nil
+}
+
+// Distinct returns the sorted distinct values of the string field in the collection.
+func Distinct(ctx context.Context, collection *mongoLib.Collection, field string) ([]string, error) {
+	values, err := collection.Distinct(ctx, field, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, v := range values {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// EncodeDoc converts a yaml doc (e.g. the NetworkTrafficDoc of a testcase) into a bson doc. The order of the
+// fields is kept so that the doc reads the same as the yaml file of the yaml storage.
+func EncodeDoc(doc interface{}) (bson.D, error) {
+	var node yamlLib.Node
+	err := node.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	d, ok := fromNode(&node).(bson.D)
+	if !ok {
+		return nil, fmt.Errorf("failed to encode the doc, expected a mapping node but got kind %v", node.Kind)
+	}
+	return d, nil
+}
+
+// DecodeDoc decodes a bson doc written by EncodeDoc into the given yaml doc.
+func DecodeDoc(d bson.D, out interface{}) error {
+	return toNode(d).Decode(out)
+}
+
+// EncodeTrafficDoc encrypts the doc of a testcase or mock with the cipher if the encryption is enabled and converts
+// it into a bson doc. It reports whether the doc is encrypted, bson doesn't keep the tag of the encrypted spec.
+func EncodeTrafficDoc(doc *yaml.NetworkTrafficDoc, cipher *yaml.Cipher) (bson.D, bool, error) {
+	err := cipher.Encrypt(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	d, err := EncodeDoc(doc)
+	if err != nil {
+		return nil, false, err
+	}
+	return d, yaml.IsEncrypted(doc), nil
+}
+
+// DecodeTrafficDoc decodes a bson doc written by EncodeTrafficDoc into the doc, the encrypted spec is decrypted
+// along with the decoding of the testcase or mock.
+func DecodeTrafficDoc(d bson.D, encrypted bool, out *yaml.NetworkTrafficDoc) error {
+	err := DecodeDoc(d, out)
+	if err != nil {
+		return err
+	}
+	if encrypted && out.Spec.Kind == yamlLib.ScalarNode {
+		out.Spec.Tag = yaml.EncryptedTag
+	}
+	return nil
+}
+
+func fromNode(node *yamlLib.Node) interface{} {
+	switch node.Kind {
+	case yamlLib.DocumentNode:
+		if len(node.Content) == 0 {
+			return nil
+		}
+		return fromNode(node.Content[0])
+	case yamlLib.AliasNode:
+		return fromNode(node.Alias)
+	case yamlLib.MappingNode:
+		d := bson.D{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			d = append(d, bson.E{Key: node.Content[i].Value, Value: fromNode(node.Content[i+1])})
+		}
+		return d
+	case yamlLib.SequenceNode:
+		a := bson.A{}
+		for _, n := range node.Content {
+			a = append(a, fromNode(n))
+		}
+		return a
+	}
+
+	switch node.ShortTag() {
+	case "!!int":
+		var i int64
+		if err := node.Decode(&i); err == nil {
+			return i
+		}
+	case "!!float":
+		var f float64
+		if err := node.Decode(&f); err == nil {
+			return f
+		}
+	case "!!bool":
+		var b bool
+		if err := node.Decode(&b); err == nil {
+			return b
+		}
+	case "!!null":
+		return nil
+	}
+	// strings, timestamps and the numbers overflowing int64 are kept as they are written in the yaml
+	return node.Value
+}
+
+func toNode(v interface{}) *yamlLib.Node {
+	switch v := v.(type) {
+	case bson.D:
+		node := &yamlLib.Node{Kind: yamlLib.MappingNode, Tag: "!!map"}
+		for _, e := range v {
+			node.Content = append(node.Content, scalar("!!str", e.Key), toNode(e.Value))
+		}
+		return node
+	case bson.M:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		node := &yamlLib.Node{Kind: yamlLib.MappingNode, Tag: "!!map"}
+		for _, k := range keys {
+			node.Content = append(node.Content, scalar("!!str", k), toNode(v[k]))
+		}
+		return node
+	case bson.A:
+		node := &yamlLib.Node{Kind: yamlLib.SequenceNode, Tag: "!!seq"}
+		for _, e := range v {
+			node.Content = append(node.Content, toNode(e))
+		}
+		return node
+	case string:
+		return scalar("!!str", v)
+	case int32:
+		return scalar("!!int", strconv.FormatInt(int64(v), 10))
+	case int64:
+		return scalar("!!int", strconv.FormatInt(v, 10))
+	case float64:
+		return scalar("!!float", strconv.FormatFloat(v, 'g', -1, 64))
+	case bool:
+		return scalar("!!bool", strconv.FormatBool(v))
+	case nil, primitive.Null:
+		return scalar("!!null", "null")
+	default:
+		return scalar("!!str", fmt.Sprint(v))
+	}
+}
+
+func scalar(tag, value string) *yamlLib.Node {
+	return &yamlLib.Node{Kind: yamlLib.ScalarNode, Tag: tag, Value: value}
+}
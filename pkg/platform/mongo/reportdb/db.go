@@ -0,0 +1,183 @@
+// Package reportdb provides the storage of the test reports in MongoDB.
+package reportdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/mongo"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoLib "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// TestReport stores the report of each test set of a test run as a doc of the reports collection. The results
+// of the testcases are kept in memory until the report of their test set is inserted, same as the yaml storage.
+type TestReport struct {
+	tests      map[string]map[string][]models.TestResult
+	m          sync.Mutex
+	collection *mongoLib.Collection
+	Logger     *zap.Logger
+	// cipher encrypts and decrypts the reports, nil keeps them in plain text
+	cipher *yaml.Cipher
+}
+
+// reportDoc holds the report in Doc, or sealed by the cipher in Encrypted.
+type reportDoc struct {
+	TestRunID string `bson:"testRunId"`
+	TestSetID string `bson:"testSetId"`
+	Doc       bson.D `bson:"doc"`
+	Encrypted string `bson:"encrypted,omitempty"`
+}
+
+func New(logger *zap.Logger, db *mongoLib.Database, cipher *yaml.Cipher) *TestReport {
+	return &TestReport{
+		tests:      make(map[string]map[string][]models.TestResult),
+		m:          sync.Mutex{},
+		collection: db.Collection(mongo.Reports),
+		Logger:     logger,
+		cipher:     cipher,
+	}
+}
+
+func (fe *TestReport) GetAllTestRunIDs(ctx context.Context) ([]string, error) {
+	return mongo.Distinct(ctx, fe.collection, "testRunId")
+}
+
+func (fe *TestReport) InsertTestCaseResult(_ context.Context, testRunID string, testSetID string, result *models.TestResult) error {
+	fe.m.Lock()
+	defer fe.m.Unlock()
+
+	testSet := fe.tests[testRunID]
+	if testSet == nil {
+		testSet = make(map[string][]models.TestResult)
+	}
+	testSet[testSetID] = append(testSet[testSetID], *result)
+	fe.tests[testRunID] = testSet
+	return nil
+}
+
+func (fe *TestReport) GetTestCaseResults(_ context.Context, testRunID string, testSetID string) ([]models.TestResult, error) {
+	fe.m.Lock()
+	defer fe.m.Unlock()
+
+	testRun, ok := fe.tests[testRunID]
+	if !ok {
+		return []models.TestResult{}, fmt.Errorf("%s found no test results for test report with id: %s", utils.Emoji, testRunID)
+	}
+	testSetResults, ok := testRun[testSetID]
+	if !ok {
+		return []models.TestResult{}, fmt.Errorf("%s found no test results for test set with id: %s", utils.Emoji, testSetID)
+	}
+	return testSetResults, nil
+}
+
+func (fe *TestReport) GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error) {
+	var doc reportDoc
+	err := fe.collection.FindOne(ctx, bson.D{{Key: "testRunId", Value: testRunID}, {Key: "testSetId", Value: testSetID}}).Decode(&doc)
+	if errors.Is(err, mongoLib.ErrNoDocuments) {
+		return nil, fmt.Errorf("%s found no report of test set %s in test run %s", utils.Emoji, testSetID, testRunID)
+	}
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to read the report from mongodb", zap.Any("session", testRunID))
+		return nil, err
+	}
+
+	var report models.TestReport
+	err = fe.decode(doc, &report)
+	if err != nil {
+		return &models.TestReport{}, fmt.Errorf("%s failed to decode the report doc. error: %v", utils.Emoji, err.Error())
+	}
+	return &report, nil
+}
+
+func (fe *TestReport) InsertReport(ctx context.Context, testRunID string, testSetID string, testReport *models.TestReport) error {
+	if testReport.Name == "" {
+		testReport.Name = testSetID + "-report"
+	}
+
+	doc, err := fe.encode(testRunID, testSetID, testReport)
+	if err != nil {
+		return fmt.Errorf("%s failed to encode the report. error: %s", utils.Emoji, err.Error())
+	}
+
+	filter := bson.D{{Key: "testRunId", Value: testRunID}, {Key: "testSetId", Value: testSetID}}
+	_, err = fe.collection.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the report to mongodb", zap.Any("session", testRunID))
+		return err
+	}
+	return nil
+}
+
+// coverageTestSet is the test set id of the doc of the coverage of a test run, no test set has an empty id.
+const coverageTestSet = ""
+
+// InsertCoverage stores the coverage of the app over the test run, as a doc of the test run without a test set.
+func (fe *TestReport) InsertCoverage(ctx context.Context, testRunID string, coverage *models.CoverageReport) error {
+	doc, err := fe.encode(testRunID, coverageTestSet, coverage)
+	if err != nil {
+		return fmt.Errorf("%s failed to encode the coverage. error: %s", utils.Emoji, err.Error())
+	}
+	filter := bson.D{{Key: "testRunId", Value: testRunID}, {Key: "testSetId", Value: coverageTestSet}}
+	_, err = fe.collection.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true))
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the coverage to mongodb", zap.Any("session", testRunID))
+		return err
+	}
+	return nil
+}
+
+// GetCoverage returns the coverage of the app over the test run, an error if it wasn't collected.
+func (fe *TestReport) GetCoverage(ctx context.Context, testRunID string) (*models.CoverageReport, error) {
+	var doc reportDoc
+	err := fe.collection.FindOne(ctx, bson.D{{Key: "testRunId", Value: testRunID}, {Key: "testSetId", Value: coverageTestSet}}).Decode(&doc)
+	if errors.Is(err, mongoLib.ErrNoDocuments) {
+		return nil, fmt.Errorf("%s found no coverage of test run %s", utils.Emoji, testRunID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var coverage models.CoverageReport
+	err = fe.decode(doc, &coverage)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed to decode the coverage doc. error: %v", utils.Emoji, err.Error())
+	}
+	return &coverage, nil
+}
+
+// encode returns the doc of the report of the test set of the test run, sealed by the cipher if the encryption is
+// enabled. The test run and test set are authenticated along with the report.
+func (fe *TestReport) encode(testRunID string, testSetID string, report interface{}) (reportDoc, error) {
+	doc := reportDoc{TestRunID: testRunID, TestSetID: testSetID}
+	if !fe.cipher.Enabled() {
+		d, err := mongo.EncodeDoc(report)
+		doc.Doc = d
+		return doc, err
+	}
+	data, err := yamlLib.Marshal(report)
+	if err != nil {
+		return doc, err
+	}
+	doc.Encrypted, err = fe.cipher.Seal(testRunID+"/"+testSetID, data)
+	return doc, err
+}
+
+// decode decodes the report of the doc written by encode into out.
+func (fe *TestReport) decode(doc reportDoc, out interface{}) error {
+	if doc.Encrypted == "" {
+		return mongo.DecodeDoc(doc.Doc, out)
+	}
+	data, err := fe.cipher.Open(doc.TestRunID+"/"+doc.TestSetID, doc.Encrypted)
+	if err != nil {
+		return err
+	}
+	return yamlLib.Unmarshal(data, out)
+}
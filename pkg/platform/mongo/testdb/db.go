@@ -0,0 +1,169 @@
+// Package testdb provides the storage of the testcases in MongoDB.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/mongo"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	yamlTestDB "go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	mongoLib "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+)
+
+// TestMongo stores each testcase as a doc of the testcases collection, keyed by its test set and name.
+// The doc holds the same fields as the yaml file of the testcase.
+type TestMongo struct {
+	collection *mongoLib.Collection
+	logger     *zap.Logger
+	// cipher encrypts and decrypts the testcases, nil keeps them in plain text
+	cipher *yaml.Cipher
+}
+
+type testCaseDoc struct {
+	TestSetID string `bson:"testSetId"`
+	Name      string `bson:"name"`
+	Doc       bson.D `bson:"doc"`
+	Encrypted bool   `bson:"encrypted,omitempty"`
+}
+
+func New(logger *zap.Logger, db *mongoLib.Database, cipher *yaml.Cipher) *TestMongo {
+	return &TestMongo{
+		collection: db.Collection(mongo.TestCases),
+		logger:     logger,
+		cipher:     cipher,
+	}
+}
+
+func (ts *TestMongo) InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error {
+	name, err := ts.upsert(ctx, testSetID, tc)
+	if err != nil {
+		return err
+	}
+
+	ts.logger.Info("🟠 Keploy has captured test cases for the user's application.", zap.String("test-set", testSetID), zap.String("testcase name", name))
+
+	return nil
+}
+
+func (ts *TestMongo) GetAllTestSetIDs(ctx context.Context) ([]string, error) {
+	return mongo.Distinct(ctx, ts.collection, "testSetId")
+}
+
+func (ts *TestMongo) GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error) {
+	cursor, err := ts.collection.Find(ctx, bson.D{{Key: "testSetId", Value: testSetID}})
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to find the testcases in mongodb", zap.String("test-set", testSetID))
+		return nil, err
+	}
+	var docs []testCaseDoc
+	err = cursor.All(ctx, &docs)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to read the testcases from mongodb", zap.String("test-set", testSetID))
+		return nil, err
+	}
+
+	tcs := []*models.TestCase{}
+	for _, doc := range docs {
+		var testCase yaml.NetworkTrafficDoc
+		err = mongo.DecodeTrafficDoc(doc.Doc, doc.Encrypted, &testCase)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to decode the testcase doc", zap.String("testcase name", doc.Name))
+			return nil, err
+		}
+		tc, err := yamlTestDB.Decode(&testCase, ts.cipher, ts.logger)
+		if err != nil {
+			utils.LogError(ts.logger, err, "failed to decode the testcase")
+			return nil, err
+		}
+		tcs = append(tcs, tc)
+	}
+	sort.SliceStable(tcs, func(i, j int) bool {
+		return tcs[i].ReqTimestamp().Before(tcs[j].ReqTimestamp())
+	})
+	return tcs, nil
+}
+
+func (ts *TestMongo) UpdateTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error {
+	name, err := ts.upsert(ctx, testSetID, tc)
+	if err != nil {
+		return err
+	}
+
+	ts.logger.Info("🔄 Keploy has updated the test cases for the user's application.", zap.String("test-set", testSetID), zap.String("testcase name", name))
+	return nil
+}
+
+// DeleteTestCase removes the testcase of the test set with the given name.
+func (ts *TestMongo) DeleteTestCase(ctx context.Context, testSetID string, name string) error {
+	_, err := ts.collection.DeleteOne(ctx, bson.D{{Key: "testSetId", Value: testSetID}, {Key: "name", Value: name}})
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to delete the testcase from mongodb", zap.String("test-set", testSetID), zap.String("testcase name", name))
+		return err
+	}
+	ts.logger.Info("🗑️ Keploy has discarded the test case.", zap.String("test-set", testSetID), zap.String("testcase name", name))
+	return nil
+}
+
+func (ts *TestMongo) upsert(ctx context.Context, testSetID string, tc *models.TestCase) (string, error) {
+	tcsName := tc.Name
+	if tcsName == "" {
+		lastIndx, err := ts.findLastIndex(ctx, testSetID)
+		if err != nil {
+			return "", err
+		}
+		tcsName = fmt.Sprintf("test-%v", lastIndx)
+	}
+	yamlTc, err := yamlTestDB.EncodeTestcase(*tc, ts.logger)
+	if err != nil {
+		return tcsName, err
+	}
+	yamlTc.Name = tcsName
+	tc.Name = tcsName
+	doc, encrypted, err := mongo.EncodeTrafficDoc(yamlTc, ts.cipher)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to encode the testcase")
+		return tcsName, err
+	}
+
+	filter := bson.D{{Key: "testSetId", Value: testSetID}, {Key: "name", Value: tcsName}}
+	_, err = ts.collection.ReplaceOne(ctx, filter, testCaseDoc{TestSetID: testSetID, Name: tcsName, Doc: doc, Encrypted: encrypted}, options.Replace().SetUpsert(true))
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to write the testcase to mongodb")
+		return tcsName, err
+	}
+	return tcsName, nil
+}
+
+// findLastIndex returns the index of the next testcase of the test set, i.e. the largest index of the testcases
+// named test-<index> plus one.
+func (ts *TestMongo) findLastIndex(ctx context.Context, testSetID string) (int, error) {
+	names, err := ts.collection.Distinct(ctx, "name", bson.D{{Key: "testSetId", Value: testSetID}})
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to find the testcase names in mongodb", zap.String("test-set", testSetID))
+		return 0, err
+	}
+	lastIndex := 0
+	for _, v := range names {
+		name, _ := v.(string)
+		if !strings.HasPrefix(name, "test-") {
+			continue
+		}
+		indx, err := strconv.Atoi(strings.TrimPrefix(name, "test-"))
+		if err != nil {
+			continue
+		}
+		if indx > lastIndex {
+			lastIndex = indx
+		}
+	}
+	return lastIndex + 1, nil
+}
@@ -0,0 +1,273 @@
+// Package protoschema renders the protobuf messages of the mocks as readable JSON, with the schemas of a directory
+// of .proto files or compiled descriptor sets. The JSON is kept next to the protoscope text of the messages, along
+// with the full name of their type, and is encoded back into them when the mocks are read, so that the edits of
+// the JSON are what the calls are matched against and answered with during the replay.
+package protoschema
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/protocolbuffers/protoscope"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// the extensions of the compiled descriptor sets, e.g. from protoc --descriptor_set_out or buf build -o
+var descriptorSetExts = map[string]bool{
+	".pb":       true,
+	".desc":     true,
+	".protoset": true,
+	".binpb":    true,
+}
+
+// Registry holds the message and service descriptors of the schemas.
+type Registry struct {
+	files *protoregistry.Files
+	types *dynamicpb.Types
+}
+
+// Load compiles the .proto files of the directory and reads its descriptor sets, recursively. The imports of the
+// .proto files are resolved relative to the directory, the well known types are built in. A type defined in
+// several files is registered once, from the first of them.
+func Load(ctx context.Context, logger *zap.Logger, dir string) (*Registry, error) {
+	var protoFiles, setFiles []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		switch {
+		case ext == ".proto":
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			protoFiles = append(protoFiles, filepath.ToSlash(rel))
+		case descriptorSetExts[ext]:
+			setFiles = append(setFiles, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the proto directory %s: %w", dir, err)
+	}
+
+	files := &protoregistry.Files{}
+	register := func(fd protoreflect.FileDescriptor) {
+		if _, err := files.FindFileByPath(fd.Path()); err == nil {
+			return
+		}
+		if err := files.RegisterFile(fd); err != nil {
+			logger.Debug("skipping the conflicting proto file", zap.String("file", fd.Path()), zap.Error(err))
+		}
+	}
+
+	if len(protoFiles) > 0 {
+		compiler := protocompile.Compiler{
+			Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{dir}}),
+		}
+		compiled, err := compiler.Compile(ctx, protoFiles...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile the proto files of %s: %w", dir, err)
+		}
+		for _, fd := range compiled {
+			register(fd)
+		}
+	}
+	for _, path := range setFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the descriptor set %s: %w", path, err)
+		}
+		set := &descriptorpb.FileDescriptorSet{}
+		err = proto.Unmarshal(data, set)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode the descriptor set %s: %w", path, err)
+		}
+		setFiles, err := protodesc.NewFiles(set)
+		if err != nil {
+			return nil, fmt.Errorf("invalid descriptor set %s: %w", path, err)
+		}
+		setFiles.RangeFiles(func(fd protoreflect.FileDescriptor) bool {
+			register(fd)
+			return true
+		})
+	}
+	if files.NumFiles() == 0 {
+		return nil, fmt.Errorf("found no .proto files or descriptor sets in %s", dir)
+	}
+	logger.Debug("loaded the protobuf schemas", zap.String("dir", dir), zap.Int("files", files.NumFiles()))
+	return &Registry{files: files, types: dynamicpb.NewTypes(files)}, nil
+}
+
+// Method returns the descriptor of the grpc method of the :path, e.g. /helloworld.Greeter/SayHello.
+func (r *Registry) Method(path string) (protoreflect.MethodDescriptor, bool) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !ok {
+		return nil, false
+	}
+	d, err := r.files.FindDescriptorByName(protoreflect.FullName(service + "." + method))
+	if err != nil {
+		return nil, false
+	}
+	md, ok := d.(protoreflect.MethodDescriptor)
+	return md, ok
+}
+
+// Message returns the descriptor of the message type by its full name.
+func (r *Registry) Message(name string) (protoreflect.MessageDescriptor, bool) {
+	d, err := r.files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		return nil, false
+	}
+	md, ok := d.(protoreflect.MessageDescriptor)
+	return md, ok
+}
+
+// Decode renders the wire format of the message of the type as JSON.
+func (r *Registry) Decode(desc protoreflect.MessageDescriptor, data []byte) (string, error) {
+	msg := dynamicpb.NewMessage(desc)
+	err := proto.UnmarshalOptions{Resolver: r.types}.Unmarshal(data, msg)
+	if err != nil {
+		return "", err
+	}
+	out, err := protojson.MarshalOptions{Multiline: true, Indent: "  ", Resolver: r.types}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Encode encodes the JSON of the message of the type into its wire format.
+func (r *Registry) Encode(desc protoreflect.MessageDescriptor, data string) ([]byte, error) {
+	msg := dynamicpb.NewMessage(desc)
+	err := protojson.UnmarshalOptions{Resolver: r.types}.Unmarshal([]byte(data), msg)
+	if err != nil {
+		return nil, err
+	}
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+var current struct {
+	mutex    sync.RWMutex
+	registry *Registry
+}
+
+// SetRegistry sets the registry the mocks are rendered with, the mocks are kept as they are if it is nil.
+func SetRegistry(r *Registry) {
+	current.mutex.Lock()
+	defer current.mutex.Unlock()
+	current.registry = r
+}
+
+func registry() *Registry {
+	current.mutex.RLock()
+	defer current.mutex.RUnlock()
+	return current.registry
+}
+
+// RenderGrpc sets the JSON of the request and the response messages of the grpc call, by the input and output
+// types of its method. The messages of the methods missing from the schemas and the compressed messages are
+// left as they are.
+func RenderGrpc(logger *zap.Logger, req *models.GrpcReq, resp *models.GrpcResp) {
+	r := registry()
+	if r == nil {
+		return
+	}
+	path := req.Headers.PseudoHeaders[":path"]
+	method, ok := r.Method(path)
+	if !ok {
+		logger.Debug("found no schema of the grpc method, keeping its messages undecoded", zap.String("method", path))
+		return
+	}
+	for _, m := range []struct {
+		msg  *models.GrpcLengthPrefixedMessage
+		desc protoreflect.MessageDescriptor
+	}{
+		{&req.Body, method.Input()},
+		{&resp.Body, method.Output()},
+	} {
+		err := r.render(m.msg, m.desc)
+		if err != nil {
+			logger.Debug("failed to render the grpc message with its schema", zap.String("method", path), zap.String("type", string(m.desc.FullName())), zap.Error(err))
+		}
+	}
+}
+
+// ApplyGrpc encodes the JSON of the request and the response messages of the grpc call back into them, if it was
+// edited since they were rendered.
+func ApplyGrpc(req *models.GrpcReq, resp *models.GrpcResp) error {
+	r := registry()
+	if r == nil {
+		return nil
+	}
+	for _, msg := range []*models.GrpcLengthPrefixedMessage{&req.Body, &resp.Body} {
+		err := r.apply(msg)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) render(msg *models.GrpcLengthPrefixedMessage, desc protoreflect.MessageDescriptor) error {
+	if msg.CompressionFlag != 0 {
+		return nil
+	}
+	data, err := protoscope.NewScanner(msg.DecodedData).Exec()
+	if err != nil {
+		return err
+	}
+	out, err := r.Decode(desc, data)
+	if err != nil {
+		return err
+	}
+	msg.Type = string(desc.FullName())
+	msg.JSON = out
+	return nil
+}
+
+func (r *Registry) apply(msg *models.GrpcLengthPrefixedMessage) error {
+	if msg.Type == "" || msg.JSON == "" {
+		return nil
+	}
+	desc, ok := r.Message(msg.Type)
+	if !ok {
+		return fmt.Errorf("found no schema of the message type %s", msg.Type)
+	}
+	edited := dynamicpb.NewMessage(desc)
+	err := protojson.UnmarshalOptions{Resolver: r.types}.Unmarshal([]byte(msg.JSON), edited)
+	if err != nil {
+		return fmt.Errorf("invalid json of the %s message: %w", msg.Type, err)
+	}
+	// the recorded bytes are kept unless the JSON is edited, they may not be in the order proto.Marshal encodes in
+	data, err := protoscope.NewScanner(msg.DecodedData).Exec()
+	if err == nil {
+		recorded := dynamicpb.NewMessage(desc)
+		unmarshal := proto.UnmarshalOptions{Resolver: r.types}
+		if unmarshal.Unmarshal(data, recorded) == nil && proto.Equal(recorded, edited) {
+			return nil
+		}
+	}
+	data, err = proto.MarshalOptions{Deterministic: true}.Marshal(edited)
+	if err != nil {
+		return fmt.Errorf("failed to encode the %s message: %w", msg.Type, err)
+	}
+	msg.DecodedData = protoscope.Write(data, protoscope.WriterOptions{})
+	msg.MessageLength = uint32(len(data))
+	return nil
+}
@@ -0,0 +1,68 @@
+package telemetry
+
+import (
+	"bytes"
+	"net/http"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// sink is where the marshalled telemetry events go, the telemetry server or a local event log.
+type sink interface {
+	write(event []byte)
+}
+
+// httpSink posts the events to the telemetry server.
+type httpSink struct {
+	url    string
+	client *http.Client
+	logger *zap.Logger
+}
+
+func (s *httpSink) write(event []byte) {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewBuffer(event))
+	if err != nil {
+		s.logger.Debug("failed to create request for analytics", zap.Error(err))
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Debug("failed to send request for analytics", zap.Error(err))
+		return
+	}
+	_, err = unmarshalResp(resp, s.logger)
+	if err != nil {
+		s.logger.Debug("failed to unmarshal response", zap.Error(err))
+		return
+	}
+}
+
+// fileSink appends the events to a JSONL file, one event per line, so that they can be inspected without any
+// of them leaving the machine. The events are sent concurrently, their lines are written one at a time.
+type fileSink struct {
+	path   string
+	logger *zap.Logger
+	mu     sync.Mutex
+}
+
+func (s *fileSink) write(event []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		s.logger.Debug("failed to open the telemetry log", zap.String("file", s.path), zap.Error(err))
+		return
+	}
+	_, err = f.Write(append(event, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		s.logger.Debug("failed to write the telemetry event to the log", zap.String("file", s.path), zap.Error(err))
+	}
+}
@@ -2,11 +2,11 @@
 package telemetry
 
 import (
-	"bytes"
 	"net/http"
 	"runtime"
 	"time"
 
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.uber.org/zap"
 )
@@ -20,7 +20,8 @@ type Telemetry struct {
 	InstallationID string
 	KeployVersion  string
 	GlobalMap      map[string]interface{}
-	client         *http.Client
+	events         map[string]bool // the categories of the events sent, all of them if nil
+	sink           sink
 }
 
 type Options struct {
@@ -28,21 +29,38 @@ type Options struct {
 	Version        string
 	GlobalMap      map[string]interface{}
 	InstallationID string
+	Events         []string // the categories of the events sent, all of them if empty
+	LogFile        string   // the JSONL file the events are written to instead of being sent, if set
 }
 
 func NewTelemetry(logger *zap.Logger, opt Options) *Telemetry {
-	return &Telemetry{
+	tel := &Telemetry{
 		Enabled:        opt.Enabled,
 		logger:         logger,
 		KeployVersion:  opt.Version,
 		GlobalMap:      opt.GlobalMap,
 		InstallationID: opt.InstallationID,
-		client:         &http.Client{Timeout: 10 * time.Second},
+		sink:           &httpSink{url: teleURL, client: &http.Client{Timeout: 10 * time.Second}, logger: logger},
 	}
+	if len(opt.Events) > 0 {
+		tel.events = map[string]bool{}
+		for _, category := range opt.Events {
+			tel.events[category] = true
+		}
+	}
+	if opt.LogFile != "" {
+		tel.sink = &fileSink{path: opt.LogFile, logger: logger}
+	}
+	return tel
+}
+
+// allowed reports whether the events of the category are sent.
+func (tel *Telemetry) allowed(category string) bool {
+	return tel.Enabled && (tel.events == nil || tel.events[category])
 }
 
 func (tel *Telemetry) Ping() {
-	if !tel.Enabled {
+	if !tel.allowed(config.TelemetryPing) {
 		return
 	}
 	go func() {
@@ -54,34 +72,59 @@ func (tel *Telemetry) Ping() {
 }
 
 func (tel *Telemetry) TestSetRun(success int, failure int, testSet string, runStatus string) {
-	go tel.SendTelemetry("TestSetRun", map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Set": testSet, "Run-Status": runStatus})
+	tel.send(config.TelemetryRuns, "TestSetRun", map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Set": testSet, "Run-Status": runStatus})
 }
 
 func (tel *Telemetry) TestRun(success int, failure int, testSets int, runStatus string) {
-	go tel.SendTelemetry("TestRun", map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Sets": testSets, "Run-Status": runStatus})
+	tel.send(config.TelemetryRuns, "TestRun", map[string]interface{}{"Passed-Tests": success, "Failed-Tests": failure, "Test-Sets": testSets, "Run-Status": runStatus})
 }
 
 // MockTestRun is Telemetry event for the Mocking feature test run
 func (tel *Telemetry) MockTestRun(utilizedMocks int) {
-	go tel.SendTelemetry("MockTestRun", map[string]interface{}{"Utilized-Mocks": utilizedMocks})
+	tel.send(config.TelemetryRuns, "MockTestRun", map[string]interface{}{"Utilized-Mocks": utilizedMocks})
+}
+
+// ProxyErrors is Telemetry event for the errors of the proxy while mocking the outgoing calls of a test set, by type
+func (tel *Telemetry) ProxyErrors(testSet string, proxyErrors map[string]int) {
+	tel.send(config.TelemetryRuns, "ProxyErrors", map[string]interface{}{"Test-Set": testSet, "Proxy-Errors": proxyErrors})
 }
 
 // RecordedTestSuite is Telemetry event for the tests and mocks that are recorded
 func (tel *Telemetry) RecordedTestSuite(testSet string, testsTotal int, mockTotal map[string]int) {
-	go tel.SendTelemetry("RecordedTestSuite", map[string]interface{}{"test-set": testSet, "tests": testsTotal, "mocks": mockTotal})
+	tel.send(config.TelemetryRecords, "RecordedTestSuite", map[string]interface{}{"test-set": testSet, "tests": testsTotal, "mocks": tel.mockKinds(mockTotal)})
 }
 
 func (tel *Telemetry) RecordedTestAndMocks() {
-	go tel.SendTelemetry("RecordedTestAndMocks", map[string]interface{}{"mocks": make(map[string]int)})
+	tel.send(config.TelemetryRecords, "RecordedTestAndMocks", map[string]interface{}{"mocks": make(map[string]int)})
 }
 
 // RecordedMocks is Telemetry event for the mocks that are recorded in the mocking feature
 func (tel *Telemetry) RecordedMocks(mockTotal map[string]int) {
-	go tel.SendTelemetry("RecordedMocks", map[string]interface{}{"mocks": mockTotal})
+	tel.send(config.TelemetryRecords, "RecordedMocks", map[string]interface{}{"mocks": tel.mockKinds(mockTotal)})
 }
 
 func (tel *Telemetry) RecordedTestCaseMock(mockType string) {
-	go tel.SendTelemetry("RecordedTestCaseMock", map[string]interface{}{"mock": mockType})
+	tel.send(config.TelemetryProtocols, "RecordedTestCaseMock", map[string]interface{}{"mock": mockType})
+}
+
+// mockKinds returns the counts of the recorded mocks by kind, or only their total if the protocols aren't sent.
+func (tel *Telemetry) mockKinds(mockTotal map[string]int) map[string]int {
+	if tel.allowed(config.TelemetryProtocols) {
+		return mockTotal
+	}
+	total := 0
+	for _, count := range mockTotal {
+		total += count
+	}
+	return map[string]int{"total": total}
+}
+
+// send sends the event in the background if its category is sent.
+func (tel *Telemetry) send(category string, eventType string, meta map[string]interface{}) {
+	if !tel.allowed(category) {
+		return
+	}
+	go tel.SendTelemetry(eventType, meta)
 }
 
 func (tel *Telemetry) SendTelemetry(eventType string, output ...map[string]interface{}) {
@@ -108,24 +151,6 @@ func (tel *Telemetry) SendTelemetry(eventType string, output ...map[string]inter
 			tel.logger.Debug("failed to marshal event", zap.Error(err))
 			return
 		}
-
-		req, err := http.NewRequest(http.MethodPost, teleURL, bytes.NewBuffer(bin))
-		if err != nil {
-			tel.logger.Debug("failed to create request for analytics", zap.Error(err))
-			return
-		}
-
-		req.Header.Set("Content-Type", "application/json; charset=utf-8")
-
-		resp, err := tel.client.Do(req)
-		if err != nil {
-			tel.logger.Debug("failed to send request for analytics", zap.Error(err))
-			return
-		}
-		_, err = unmarshalResp(resp, tel.logger)
-		if err != nil {
-			tel.logger.Debug("failed to unmarshal response", zap.Error(err))
-			return
-		}
+		tel.sink.write(bin)
 	}
 }
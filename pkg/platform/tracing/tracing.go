@@ -0,0 +1,82 @@
+// Package tracing provides the OpenTelemetry tracing of the record and test runs of keploy itself, so that the
+// time spent in the setup, the hooks, the testcases and the mock matching of a slow run can be found. The spans
+// are exported over OTLP/HTTP only if the tracing is enabled, otherwise they are no-ops.
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const tracerName = "go.keploy.io/server/v2"
+
+// Init starts exporting the spans to the OTLP/HTTP endpoint of the config, the endpoint of the
+// OTEL_EXPORTER_OTLP_ENDPOINT environment variable or http://localhost:4318 by default. The returned function
+// flushes the pending spans and stops the export.
+func Init(ctx context.Context, logger *zap.Logger, cfg config.Tracing) (func(), error) {
+	var opts []otlptracehttp.Option
+	if cfg.Endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		utils.LogError(logger, err, "failed to create the otlp exporter of the spans")
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(
+			semconv.ServiceName("keploy"),
+			semconv.ServiceVersion(utils.Version),
+		)),
+	)
+	otel.SetTracerProvider(provider)
+	logger.Debug("exporting the spans of keploy", zap.String("endpoint", cfg.Endpoint))
+
+	return func() {
+		// the spans are flushed even if the run was cancelled
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := provider.Shutdown(shutdownCtx); err != nil {
+			utils.LogError(logger, err, "failed to flush the spans")
+		}
+	}, nil
+}
+
+// Start starts a span of keploy as a child of the span of the context, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartMockMatching starts the span of the matching of an outgoing request of the kind with the mocks.
+func StartMockMatching(ctx context.Context, kind models.Kind) (context.Context, trace.Span) {
+	return Start(ctx, "mock matching", attribute.String("kind", string(kind)))
+}
+
+// EndMockMatching records whether a mock was matched and the error, if any, on the span and ends it.
+func EndMockMatching(span trace.Span, matched bool, err error) {
+	span.SetAttributes(attribute.Bool("matched", matched))
+	End(span, err)
+}
+
+// End records the error, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,166 @@
+package yaml
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// EncryptedTag is the tag of the spec of an encrypted doc, its value is the base64 encoded nonce and AES-GCM
+// ciphertext of the spec and the curl of the doc. The version, kind and name of the doc are kept in plain text,
+// so that the encrypted test sets can still be listed and diffed. The encrypted reports are a single value of
+// this tag.
+const EncryptedTag = "!encrypted"
+
+// ErrNoEncryptionKey is returned for an encrypted doc when no encryption key is set.
+var ErrNoEncryptionKey = errors.New("the doc is encrypted but no encryption key is set, set it with KEPLOY_ENCRYPTION_KEY or encryption.keyCommand")
+
+// Cipher encrypts and decrypts the testcases, mocks and reports of the storage with an AES key. A nil Cipher has
+// no key, it leaves the docs in plain text and fails on the encrypted ones.
+type Cipher struct {
+	aead    cipher.AEAD
+	encrypt bool
+}
+
+// encryptedSpec is the plain text of an encrypted doc.
+type encryptedSpec struct {
+	Spec yamlLib.Node `yaml:"spec"`
+	Curl string       `yaml:"curl,omitempty"`
+}
+
+// NewCipher returns the cipher of the AES key (16, 24 or 32 bytes). The docs are encrypted with it on encoding
+// only if encrypt is true, so that a key can be set just to replay encrypted docs.
+func NewCipher(key []byte, encrypt bool) (*Cipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Cipher{aead: aead, encrypt: encrypt}, nil
+}
+
+// Enabled reports whether the docs are encrypted on encoding.
+func (c *Cipher) Enabled() bool {
+	return c != nil && c.encrypt
+}
+
+// IsEncrypted reports whether the spec of the doc is encrypted.
+func IsEncrypted(doc *NetworkTrafficDoc) bool {
+	return doc.Spec.Kind == yamlLib.ScalarNode && doc.Spec.Tag == EncryptedTag
+}
+
+// Encrypt encrypts the spec and the curl of the doc in place if the encryption is enabled.
+func (c *Cipher) Encrypt(doc *NetworkTrafficDoc) error {
+	if !c.Enabled() || IsEncrypted(doc) {
+		return nil
+	}
+	return c.Reencrypt(doc)
+}
+
+// Reencrypt encrypts the spec and the curl of the doc decrypted by Decrypt again, whether or not the encryption
+// is enabled.
+func (c *Cipher) Reencrypt(doc *NetworkTrafficDoc) error {
+	plaintext, err := yamlLib.Marshal(&encryptedSpec{Spec: doc.Spec, Curl: doc.Curl})
+	if err != nil {
+		return fmt.Errorf("failed to marshal the spec of %s: %w", doc.Name, err)
+	}
+	// the name is authenticated along with the spec, so the spec of a doc can't be swapped with the one of another
+	sealed, err := c.Seal(doc.Name, plaintext)
+	if err != nil {
+		return err
+	}
+	doc.Spec = yamlLib.Node{
+		Kind:  yamlLib.ScalarNode,
+		Tag:   EncryptedTag,
+		Value: sealed,
+	}
+	doc.Curl = ""
+	return nil
+}
+
+// Decrypt decrypts the spec and the curl of the doc in place if the doc is encrypted.
+func (c *Cipher) Decrypt(doc *NetworkTrafficDoc) error {
+	if !IsEncrypted(doc) {
+		return nil
+	}
+	plaintext, err := c.Open(doc.Name, doc.Spec.Value)
+	if err != nil {
+		return err
+	}
+	var spec encryptedSpec
+	err = yamlLib.Unmarshal(plaintext, &spec)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the decrypted spec of %s: %w", doc.Name, err)
+	}
+	doc.Spec = spec.Spec
+	doc.Curl = spec.Curl
+	return nil
+}
+
+// Seal returns the base64 encoded nonce and ciphertext of the plaintext, authenticated along with the name.
+func (c *Cipher) Seal(name string, plaintext []byte) (string, error) {
+	if c == nil {
+		return "", fmt.Errorf("%w: %s", ErrNoEncryptionKey, name)
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate the nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, plaintext, []byte(name))), nil
+}
+
+// Open returns the plaintext of the value sealed with the name by Seal.
+func (c *Cipher) Open(name string, sealed string) ([]byte, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoEncryptionKey, name)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode the encrypted %s: %w", name, err)
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, fmt.Errorf("the encrypted %s is too short", name)
+	}
+	plaintext, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], []byte(name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s, the encryption key may be wrong: %w", name, err)
+	}
+	return plaintext, nil
+}
+
+// EncryptFile returns the yaml file of the given name, e.g. a report, as a single encrypted value if the encryption
+// is enabled.
+func (c *Cipher) EncryptFile(name string, data []byte) ([]byte, error) {
+	if !c.Enabled() {
+		return data, nil
+	}
+	sealed, err := c.Seal(name, data)
+	if err != nil {
+		return nil, err
+	}
+	return yamlLib.Marshal(&yamlLib.Node{Kind: yamlLib.ScalarNode, Tag: EncryptedTag, Value: sealed})
+}
+
+// DecryptFile returns the plain text of the yaml file written by EncryptFile, the files which aren't encrypted
+// are returned as is.
+func (c *Cipher) DecryptFile(name string, data []byte) ([]byte, error) {
+	var node yamlLib.Node
+	if yamlLib.Unmarshal(data, &node) != nil || len(node.Content) != 1 {
+		return data, nil
+	}
+	if value := node.Content[0]; value.Kind == yamlLib.ScalarNode && value.Tag == EncryptedTag {
+		return c.Open(name, value.Value)
+	}
+	return data, nil
+}
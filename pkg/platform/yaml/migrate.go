@@ -0,0 +1,167 @@
+package yaml
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// SchemaVersion is the schema version of the testcases and mocks written by this version of keploy. The docs
+// written before the schema version was introduced have no schemaVersion and are of version 1.
+const SchemaVersion = 2
+
+// ErrNewerSchema is returned for the docs written by a newer version of keploy, which can't be read safely.
+var ErrNewerSchema = errors.New("the doc was written with a newer schema version, please upgrade keploy")
+
+// migrations[i] migrates a doc of schema version i+1 to version i+2. A change of the format of the docs should
+// add a migration here and increment SchemaVersion, so that the recorded suites are upgraded instead of breaking.
+var migrations = []struct {
+	description string
+	migrate     func(doc *NetworkTrafficDoc) error
+}{
+	{
+		description: "convert the list of noisy fields of the testcases into the map of the fields to their regexes",
+		migrate:     migrateNoiseList,
+	},
+}
+
+// Migrate upgrades the doc in place to the current schema version and reports whether the doc was changed.
+func Migrate(doc *NetworkTrafficDoc) (bool, error) {
+	version := doc.SchemaVersion
+	if version == 0 {
+		version = 1
+	}
+	if version > SchemaVersion {
+		return false, fmt.Errorf("%w: %s has schema version %d but the supported version is %d", ErrNewerSchema, doc.Name, version, SchemaVersion)
+	}
+	if doc.SchemaVersion == SchemaVersion {
+		return false, nil
+	}
+	for ; version < SchemaVersion; version++ {
+		err := migrations[version-1].migrate(doc)
+		if err != nil {
+			return false, fmt.Errorf("failed to %s of %s: %w", migrations[version-1].description, doc.Name, err)
+		}
+	}
+	doc.SchemaVersion = SchemaVersion
+	return true, nil
+}
+
+// MigrateFile upgrades the docs of the yaml or protobuf file in place and returns the number of the upgraded docs.
+// The file is rewritten only if a doc was upgraded, and not at all with dryRun. The encrypted docs are decrypted and
+// encrypted again with the cipher.
+func MigrateFile(ctx context.Context, logger *zap.Logger, cipher *Cipher, filePath string, dryRun bool) (int, error) {
+	docs, err := ReadShard(ctx, logger, filePath)
+	if err != nil {
+		return 0, err
+	}
+	migrated := 0
+	encrypted := make([]bool, len(docs))
+	for i, doc := range docs {
+		encrypted[i] = IsEncrypted(doc)
+		err := cipher.Decrypt(doc)
+		if err != nil {
+			return 0, err
+		}
+		changed, err := Migrate(doc)
+		if err != nil {
+			return 0, err
+		}
+		if changed {
+			migrated++
+		}
+	}
+	if migrated == 0 || dryRun {
+		return migrated, nil
+	}
+
+	var buf bytes.Buffer
+	for i, doc := range docs {
+		if encrypted[i] {
+			err := cipher.Reencrypt(doc)
+			if err != nil {
+				return 0, err
+			}
+		}
+		if filepath.Ext(filePath) == Ext(FormatProtobuf) {
+			data, err := MarshalProtobuf(doc)
+			if err != nil {
+				return 0, err
+			}
+			buf.Write(protowire.AppendBytes(nil, data))
+			continue
+		}
+		data, err := yamlLib.Marshal(doc)
+		if err != nil {
+			return 0, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+
+	// the migrated docs are written next to the file first, so that a failed write keeps the original docs
+	tmp := filePath + ".migrate"
+	err = os.WriteFile(tmp, buf.Bytes(), 0777)
+	if err != nil {
+		utils.LogError(logger, err, "failed to write the migrated file", zap.String("file", tmp))
+		return 0, err
+	}
+	err = os.Rename(tmp, filePath)
+	if err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	return migrated, nil
+}
+
+// migrateNoiseList converts the noise assertion of the testcases written as a list of fields e.g.
+// noise: [header.Date], into the map of the fields to their regexes e.g. noise: {header.Date: []}.
+func migrateNoiseList(doc *NetworkTrafficDoc) error {
+	assertions := mappingValue(&doc.Spec, "assertions")
+	if assertions == nil {
+		return nil
+	}
+	noise := mappingValue(assertions, "noise")
+	if noise == nil || noise.Kind != yamlLib.SequenceNode {
+		return nil
+	}
+	fields := noise.Content
+	*noise = yamlLib.Node{Kind: yamlLib.MappingNode, Tag: "!!map"}
+	for _, field := range fields {
+		if field.Kind != yamlLib.ScalarNode {
+			return fmt.Errorf("invalid noisy field of kind %v", field.Kind)
+		}
+		noise.Content = append(noise.Content,
+			&yamlLib.Node{Kind: yamlLib.ScalarNode, Tag: "!!str", Value: strings.TrimSpace(field.Value)},
+			&yamlLib.Node{Kind: yamlLib.SequenceNode, Tag: "!!seq", Style: yamlLib.FlowStyle},
+		)
+	}
+	return nil
+}
+
+// mappingValue returns the value of the key in the mapping node, nil if the node is not a mapping or has no key.
+func mappingValue(node *yamlLib.Node, key string) *yamlLib.Node {
+	if node.Kind == yamlLib.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yamlLib.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
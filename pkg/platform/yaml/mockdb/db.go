@@ -2,14 +2,12 @@
 package mockdb
 
 import (
-	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"io"
-	"os"
 	"path/filepath"
 	"sort"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -25,14 +23,26 @@ type MockYaml struct {
 	MockName  string
 	Logger    *zap.Logger
 	idCounter int64
+	// format is the format the mocks are written in (yaml or protobuf), the mocks are read in any format.
+	format string
+	// maxFileSize is the size in bytes past which the mocks file is rotated into numbered shards, 0 disables it.
+	maxFileSize int64
+	mutex       sync.Mutex
+	writers     map[string]*yaml.ShardWriter
+	// cipher encrypts and decrypts the mocks, nil keeps them in plain text
+	cipher *yaml.Cipher
 }
 
-func New(Logger *zap.Logger, mockPath string, mockName string) *MockYaml {
+func New(Logger *zap.Logger, mockPath string, mockName string, format string, maxFileSize int64, cipher *yaml.Cipher) *MockYaml {
 	return &MockYaml{
-		MockPath:  mockPath,
-		MockName:  mockName,
-		Logger:    Logger,
-		idCounter: -1,
+		MockPath:    mockPath,
+		MockName:    mockName,
+		Logger:      Logger,
+		idCounter:   -1,
+		format:      format,
+		maxFileSize: maxFileSize,
+		writers:     map[string]*yaml.ShardWriter{},
+		cipher:      cipher,
 	}
 }
 
@@ -40,10 +50,7 @@ func New(Logger *zap.Logger, mockPath string, mockName string) *MockYaml {
 //
 // mockNames is a map which contains the name of the mocks as key and a isConfig boolean as value
 func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error {
-	mockFileName := "mocks"
-	if ys.MockName != "" {
-		mockFileName = ys.MockName
-	}
+	mockFileName := ys.mockFileName()
 	path := filepath.Join(ys.MockPath, testSetID)
 	ys.Logger.Debug("logging the names of the unused mocks to be removed", zap.Any("mockNames", mockNames), zap.Any("for testset", testSetID), zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
 
@@ -53,35 +60,19 @@ func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames
 		utils.LogError(ys.Logger, err, "failed to read mocks due to inaccessible path", zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
 		return err
 	}
-	if _, err := os.Stat(mockPath); err != nil {
+	found, err := yaml.HasShards(path, mockFileName)
+	if err != nil || !found {
+		if err == nil {
+			err = fmt.Errorf("no mocks file found at %s", mockPath)
+		}
 		utils.LogError(ys.Logger, err, "failed to find the mocks yaml file")
 		return err
 	}
-	data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
+	mocks, err := ys.readMocks(ctx, path, mockFileName)
 	if err != nil {
 		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml file", zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
 		return err
 	}
-
-	// decode the mocks read from the yaml file
-	dec := yamlLib.NewDecoder(bytes.NewReader(data))
-	var mockYamls []*yaml.NetworkTrafficDoc
-	for {
-		var doc *yaml.NetworkTrafficDoc
-		err := dec.Decode(&doc)
-		if errors.Is(err, io.EOF) {
-			break
-		}
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to decode the yaml file documents", zap.Any("at path", filepath.Join(path, mockFileName+".yaml")))
-			return fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
-		}
-		mockYamls = append(mockYamls, doc)
-	}
-	mocks, err := decodeMocks(mockYamls, ys.Logger)
-	if err != nil {
-		return err
-	}
 	var newMocks []*models.Mock
 	for _, mock := range mocks {
 		if _, ok := mockNames[mock.Name]; ok {
@@ -90,32 +81,76 @@ func (ys *MockYaml) UpdateMocks(ctx context.Context, testSetID string, mockNames
 		}
 	}
 	ys.Logger.Debug("logging the names of the used mocks", zap.Any("mockNames", newMocks), zap.Any("for testset", testSetID))
+	return ys.rewriteMocks(ctx, testSetID, newMocks)
+}
+
+// GetMocks returns all the mocks of the test set, in the order they are written in the mocks file.
+func (ys *MockYaml) GetMocks(ctx context.Context, testSetID string) ([]*models.Mock, error) {
+	path := filepath.Join(ys.MockPath, testSetID)
+	found, err := yaml.HasShards(path, ys.mockFileName())
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("no mocks file found for the test set %s", testSetID)
+	}
+	return ys.readMocks(ctx, path, ys.mockFileName())
+}
 
-	// remove the old mock yaml file
-	err = os.Remove(filepath.Join(path, mockFileName+".yaml"))
+// UpdateMock replaces the mock of the test set with the same name, keeping the order of the mocks file.
+func (ys *MockYaml) UpdateMock(ctx context.Context, testSetID string, mock *models.Mock) error {
+	mocks, err := ys.GetMocks(ctx, testSetID)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i, m := range mocks {
+		if m.Name == mock.Name {
+			mocks[i] = mock
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		return fmt.Errorf("no mock named %s found in the test set %s", mock.Name, testSetID)
+	}
+	return ys.rewriteMocks(ctx, testSetID, mocks)
+}
+
+// rewriteMocks replaces the mocks file of the test set, and its shards, with the given mocks.
+func (ys *MockYaml) rewriteMocks(ctx context.Context, testSetID string, mocks []*models.Mock) error {
+	path := filepath.Join(ys.MockPath, testSetID)
+
+	// remove the old mock yaml files
+	writer := ys.writer(testSetID)
+	err := writer.Close()
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to close the mocks yaml file", zap.Any("for testset", testSetID))
+	}
+	err = yaml.RemoveShards(path, ys.mockFileName())
 	if err != nil {
 		return err
 	}
 
-	// write the new mocks to the new yaml file
-	for _, newMock := range newMocks {
+	// write the new mocks to the new yaml files
+	for _, newMock := range mocks {
 		mockYaml, err := EncodeMock(newMock, ys.Logger)
 		if err != nil {
 			utils.LogError(ys.Logger, err, "failed to encode the mock to yaml", zap.Any("mock", newMock.Name), zap.Any("for testset", testSetID))
 			return err
 		}
-		data, err = yamlLib.Marshal(&mockYaml)
+		data, err := ys.marshal(mockYaml)
 		if err != nil {
 			utils.LogError(ys.Logger, err, "failed to marshal the mock to yaml", zap.Any("mock", newMock.Name), zap.Any("for testset", testSetID))
 			return err
 		}
-		err = yaml.WriteFile(ctx, ys.Logger, path, mockFileName, data, true)
+		err = writer.Write(ctx, data)
 		if err != nil {
 			utils.LogError(ys.Logger, err, "failed to write the mock to yaml", zap.Any("mock", newMock.Name), zap.Any("for testset", testSetID))
 			return err
 		}
 	}
-	return nil
+	return writer.Close()
 }
 
 func (ys *MockYaml) InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error {
@@ -124,145 +159,79 @@ func (ys *MockYaml) InsertMock(ctx context.Context, mock *models.Mock, testSetID
 	if err != nil {
 		return err
 	}
-	mockPath := filepath.Join(ys.MockPath, testSetID)
-	mockFileName := ys.MockName
-	if mockFileName == "" {
-		mockFileName = "mocks"
-	}
-	data, err := yamlLib.Marshal(&mockYaml)
+	data, err := ys.marshal(mockYaml)
 	if err != nil {
 		return err
 	}
-	err = yaml.WriteFile(ctx, ys.Logger, mockPath, mockFileName, data, true)
-	if err != nil {
-		return err
-	}
-	return nil
+	return ys.writer(testSetID).Write(ctx, data)
 }
 
 func (ys *MockYaml) GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
-
-	var tcsMocks = make([]*models.Mock, 0)
-	var filteredTcsMocks = make([]*models.Mock, 0)
-
-	mockFileName := "mocks"
-	if ys.MockName != "" {
-		mockFileName = ys.MockName
-	}
-
 	path := filepath.Join(ys.MockPath, testSetID)
-	mockPath, err := yaml.ValidatePath(path + "/" + mockFileName + ".yaml")
+	mocks, err := ys.readMocks(ctx, path, ys.mockFileName())
 	if err != nil {
 		return nil, err
 	}
+	return FilterTcsMocks(mocks, afterTime, beforeTime, ys.Logger), nil
+}
 
-	if _, err := os.Stat(mockPath); err == nil {
-		var mockYamls []*yaml.NetworkTrafficDoc
-		data, err := yaml.ReadFile(ctx, ys.Logger, path, mockFileName)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to read the mocks from config yaml", zap.Any("session", filepath.Base(path)))
-			return nil, err
-		}
-		dec := yamlLib.NewDecoder(bytes.NewReader(data))
-		for {
-			var doc *yaml.NetworkTrafficDoc
-			err := dec.Decode(&doc)
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
-			}
-			mockYamls = append(mockYamls, doc)
-		}
-		mocks, err := decodeMocks(mockYamls, ys.Logger)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to decode the config mocks from yaml docs", zap.Any("session", filepath.Base(path)))
-			return nil, err
+// FilterTcsMocks returns the mocks of the testcases, i.e. the mocks other than the config mocks and the mocks of the
+// kinds matched without filtering, recorded between afterTime and beforeTime. The mocks are sorted by their request time.
+func FilterTcsMocks(mocks []*models.Mock, afterTime time.Time, beforeTime time.Time, logger *zap.Logger) []*models.Mock {
+	var tcsMocks = make([]*models.Mock, 0)
+	for _, mock := range mocks {
+		isFilteredMock := true
+		switch mock.Kind {
+		case "Generic":
+			isFilteredMock = false
+		case "Postgres":
+			isFilteredMock = false
+		case "Http":
+			isFilteredMock = false
 		}
-
-		for _, mock := range mocks {
-			isFilteredMock := true
-			switch mock.Kind {
-			case "Generic":
-				isFilteredMock = false
-			case "Postgres":
-				isFilteredMock = false
-			case "Http":
-				isFilteredMock = false
-			}
-			if mock.Spec.Metadata["type"] != "config" && isFilteredMock {
-				tcsMocks = append(tcsMocks, mock)
-			}
+		if mock.Spec.Metadata["type"] != "config" && isFilteredMock {
+			tcsMocks = append(tcsMocks, mock)
 		}
 	}
 
-	filteredTcsMocks, _ = ys.filterByTimeStamp(ctx, tcsMocks, afterTime, beforeTime, ys.Logger)
+	filteredTcsMocks, _ := filterByTimeStamp(tcsMocks, afterTime, beforeTime, logger)
 
 	sort.SliceStable(filteredTcsMocks, func(i, j int) bool {
 		return filteredTcsMocks[i].Spec.ReqTimestampMock.Before(filteredTcsMocks[j].Spec.ReqTimestampMock)
 	})
 
-	return filteredTcsMocks, nil
+	return filteredTcsMocks
 }
 
 func (ys *MockYaml) GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error) {
-
-	var configMocks = make([]*models.Mock, 0)
-
-	mockName := "mocks"
-	if ys.MockName != "" {
-		mockName = ys.MockName
-	}
-
 	path := filepath.Join(ys.MockPath, testSetID)
-
-	mockPath, err := yaml.ValidatePath(path + "/" + mockName + ".yaml")
+	mocks, err := ys.readMocks(ctx, path, ys.mockFileName())
 	if err != nil {
 		return nil, err
 	}
+	return FilterConfigMocks(mocks, afterTime, beforeTime, ys.Logger), nil
+}
 
-	if _, err := os.Stat(mockPath); err == nil {
-		var mockYamls []*yaml.NetworkTrafficDoc
-		data, err := yaml.ReadFile(ctx, ys.Logger, path, mockName)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to read the mocks from config yaml", zap.Any("session", filepath.Base(path)))
-			return nil, err
-		}
-		dec := yamlLib.NewDecoder(bytes.NewReader(data))
-		for {
-			var doc *yaml.NetworkTrafficDoc
-			err := dec.Decode(&doc)
-			if errors.Is(err, io.EOF) {
-				break
-			}
-			if err != nil {
-				return nil, fmt.Errorf("failed to decode the yaml file documents. error: %v", err.Error())
-			}
-			mockYamls = append(mockYamls, doc)
-		}
-		mocks, err := decodeMocks(mockYamls, ys.Logger)
-		if err != nil {
-			utils.LogError(ys.Logger, err, "failed to decode the config mocks from yaml docs", zap.Any("session", filepath.Base(path)))
-			return nil, err
+// FilterConfigMocks returns the config mocks and the mocks of the kinds matched without filtering. The mocks recorded
+// between afterTime and beforeTime come first, each group is sorted by the request time of the mocks.
+func FilterConfigMocks(mocks []*models.Mock, afterTime time.Time, beforeTime time.Time, logger *zap.Logger) []*models.Mock {
+	var configMocks = make([]*models.Mock, 0)
+	for _, mock := range mocks {
+		isUnFilteredMock := false
+		switch mock.Kind {
+		case "Generic":
+			isUnFilteredMock = true
+		case "Postgres":
+			isUnFilteredMock = true
+		case "Http":
+			isUnFilteredMock = true
 		}
-		for _, mock := range mocks {
-			isUnFilteredMock := false
-			switch mock.Kind {
-			case "Generic":
-				isUnFilteredMock = true
-			case "Postgres":
-				isUnFilteredMock = true
-			case "Http":
-				isUnFilteredMock = true
-			}
-			if mock.Spec.Metadata["type"] == "config" || isUnFilteredMock {
-				configMocks = append(configMocks, mock)
-			}
+		if mock.Spec.Metadata["type"] == "config" || isUnFilteredMock {
+			configMocks = append(configMocks, mock)
 		}
 	}
 
-	filteredMocks, unfilteredMocks := ys.filterByTimeStamp(ctx, configMocks, afterTime, beforeTime, ys.Logger)
+	filteredMocks, unfilteredMocks := filterByTimeStamp(configMocks, afterTime, beforeTime, logger)
 
 	sort.SliceStable(filteredMocks, func(i, j int) bool {
 		return filteredMocks[i].Spec.ReqTimestampMock.Before(filteredMocks[j].Spec.ReqTimestampMock)
@@ -276,16 +245,81 @@ func (ys *MockYaml) GetUnFilteredMocks(ctx context.Context, testSetID string, af
 	// 	unfilteredMocks = unfilteredMocks[:10]
 	// }
 
-	mocks := append(filteredMocks, unfilteredMocks...)
+	return append(filteredMocks, unfilteredMocks...)
+}
 
+// readMocks reads the mocks of all the shards of the mocks file, no mocks are returned if the file doesn't exist.
+func (ys *MockYaml) readMocks(ctx context.Context, path, mockFileName string) ([]*models.Mock, error) {
+	_, err := yaml.ValidatePath(filepath.Join(path, mockFileName+".yaml"))
+	if err != nil {
+		return nil, err
+	}
+	mockYamls, err := yaml.ReadDocs(ctx, ys.Logger, path, mockFileName)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to read the mocks from yaml", zap.Any("session", filepath.Base(path)))
+		return nil, err
+	}
+	mocks, err := DecodeMocks(mockYamls, ys.cipher, ys.Logger)
+	if err != nil {
+		utils.LogError(ys.Logger, err, "failed to decode the mocks from yaml docs", zap.Any("session", filepath.Base(path)))
+		return nil, err
+	}
+	for _, mock := range mocks {
+		mock.TestModeInfo.TestSetID = filepath.Base(path)
+	}
 	return mocks, nil
 }
 
+// Close closes the mocks files of the test sets kept open for the next mocks.
+func (ys *MockYaml) Close() error {
+	ys.mutex.Lock()
+	defer ys.mutex.Unlock()
+	var errs []error
+	for testSetID, writer := range ys.writers {
+		if err := writer.Close(); err != nil {
+			utils.LogError(ys.Logger, err, "failed to close the mocks file", zap.Any("for testset", testSetID))
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// writer returns the writer of the mocks file of the test set, the file is kept open for the next mocks.
+func (ys *MockYaml) writer(testSetID string) *yaml.ShardWriter {
+	ys.mutex.Lock()
+	defer ys.mutex.Unlock()
+	writer, ok := ys.writers[testSetID]
+	if !ok {
+		writer = yaml.NewShardWriter(ys.Logger, filepath.Join(ys.MockPath, testSetID), ys.mockFileName(), ys.format, ys.maxFileSize)
+		ys.writers[testSetID] = writer
+	}
+	return writer
+}
+
+// marshal encrypts the mock if the encryption is enabled and marshals it in the format of the mocks file.
+func (ys *MockYaml) marshal(mockYaml *yaml.NetworkTrafficDoc) ([]byte, error) {
+	err := ys.cipher.Encrypt(mockYaml)
+	if err != nil {
+		return nil, err
+	}
+	if ys.format == yaml.FormatProtobuf {
+		return yaml.MarshalProtobuf(mockYaml)
+	}
+	return yamlLib.Marshal(mockYaml)
+}
+
+func (ys *MockYaml) mockFileName() string {
+	if ys.MockName != "" {
+		return ys.MockName
+	}
+	return "mocks"
+}
+
 func (ys *MockYaml) getNextID() int64 {
 	return atomic.AddInt64(&ys.idCounter, 1)
 }
 
-func (ys *MockYaml) filterByTimeStamp(_ context.Context, m []*models.Mock, afterTime time.Time, beforeTime time.Time, logger *zap.Logger) ([]*models.Mock, []*models.Mock) {
+func filterByTimeStamp(m []*models.Mock, afterTime time.Time, beforeTime time.Time, logger *zap.Logger) ([]*models.Mock, []*models.Mock) {
 
 	filteredMocks := make([]*models.Mock, 0)
 	unfilteredMocks := make([]*models.Mock, 0)
@@ -320,7 +354,7 @@ func (ys *MockYaml) filterByTimeStamp(_ context.Context, m []*models.Mock, after
 		unfilteredMocks = append(unfilteredMocks, mock)
 	}
 	if isNonKeploy {
-		ys.Logger.Debug("Few mocks in the mock File are not recorded by keploy ignoring them")
+		logger.Debug("Few mocks in the mock File are not recorded by keploy ignoring them")
 	}
 	return filteredMocks, unfilteredMocks
 }
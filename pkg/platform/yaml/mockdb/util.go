@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/protoschema"
 	"go.keploy.io/server/v2/pkg/platform/yaml"
 	"go.keploy.io/server/v2/utils"
 	"go.mongodb.org/mongo-driver/x/mongo/driver/wiremessage"
@@ -13,10 +14,11 @@ import (
 
 func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc, error) {
 	yamlDoc := yaml.NetworkTrafficDoc{
-		Version:      mock.Version,
-		Kind:         mock.Kind,
-		Name:         mock.Name,
-		ConnectionID: mock.ConnectionID,
+		Version:       mock.Version,
+		SchemaVersion: yaml.SchemaVersion,
+		Kind:          mock.Kind,
+		Name:          mock.Name,
+		ConnectionID:  mock.ConnectionID,
 	}
 	switch mock.Kind {
 	case models.Mongo:
@@ -106,11 +108,13 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 		}
 	case models.GRPC_EXPORT:
 		gRPCSpec := models.GrpcSpec{
+			Metadata:         mock.Spec.Metadata,
 			GrpcReq:          *mock.Spec.GRPCReq,
 			GrpcResp:         *mock.Spec.GRPCResp,
 			ReqTimestampMock: mock.Spec.ReqTimestampMock,
 			ResTimestampMock: mock.Spec.ResTimestampMock,
 		}
+		protoschema.RenderGrpc(logger, &gRPCSpec.GrpcReq, &gRPCSpec.GrpcResp)
 		err := yamlDoc.Spec.Encode(gRPCSpec)
 		if err != nil {
 			utils.LogError(logger, err, "failed to marshal gRPC of external call into yaml")
@@ -164,10 +168,22 @@ func EncodeMock(mock *models.Mock, logger *zap.Logger) (*yaml.NetworkTrafficDoc,
 	return &yamlDoc, nil
 }
 
-func decodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*models.Mock, error) {
+// DecodeMocks decodes the yaml docs of the mocks, the mocks of the enterprise kinds are skipped. The encrypted docs
+// are decrypted with the cipher and the docs of an older schema version are migrated first.
+func DecodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, cipher *yaml.Cipher, logger *zap.Logger) ([]*models.Mock, error) {
 	mocks := []*models.Mock{}
 
 	for _, m := range yamlMocks {
+		err := cipher.Decrypt(m)
+		if err != nil {
+			utils.LogError(logger, err, "failed to decrypt the mock", zap.String("mock", m.Name))
+			return nil, err
+		}
+		_, err = yaml.Migrate(m)
+		if err != nil {
+			utils.LogError(logger, err, "failed to migrate the mock to the current schema version", zap.String("mock", m.Name))
+			return nil, err
+		}
 		mock := models.Mock{
 			Version:      m.Version,
 			Name:         m.Name,
@@ -216,7 +232,13 @@ func decodeMocks(yamlMocks []*yaml.NetworkTrafficDoc, logger *zap.Logger) ([]*mo
 				utils.LogError(logger, err, "failed to unmarshal a yaml doc into http mock", zap.Any("mock name", m.Name))
 				return nil, err
 			}
+			err = protoschema.ApplyGrpc(&grpcSpec.GrpcReq, &grpcSpec.GrpcResp)
+			if err != nil {
+				utils.LogError(logger, err, "failed to encode the json of the grpc messages of the mock", zap.Any("mock name", m.Name))
+				return nil, err
+			}
 			mock.Spec = models.MockSpec{
+				Metadata:         grpcSpec.Metadata,
 				GRPCResp:         &grpcSpec.GrpcResp,
 				GRPCReq:          &grpcSpec.GrpcReq,
 				ReqTimestampMock: grpcSpec.ReqTimestampMock,
@@ -0,0 +1,156 @@
+package yaml
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// The docs are encoded in protobuf as the tree of their yaml nodes, so that any doc of the yaml files can be
+// stored without a schema per kind of mock, and decoded without parsing the yaml text:
+//
+//	message Node {
+//	  uint32 kind = 1;
+//	  string tag = 2;
+//	  string value = 3;
+//	  repeated Node content = 4;
+//	}
+//
+// The protobuf files hold a stream of the encoded docs, each prefixed with its varint encoded length.
+const (
+	nodeKind    protowire.Number = 1
+	nodeTag     protowire.Number = 2
+	nodeValue   protowire.Number = 3
+	nodeContent protowire.Number = 4
+)
+
+// MarshalProtobuf encodes the doc into the protobuf message of its yaml node.
+func MarshalProtobuf(doc interface{}) ([]byte, error) {
+	var node yamlLib.Node
+	err := node.Encode(doc)
+	if err != nil {
+		return nil, err
+	}
+	return appendNode(nil, &node), nil
+}
+
+// UnmarshalProtobuf decodes the protobuf message of a yaml node into the doc.
+func UnmarshalProtobuf(data []byte, doc interface{}) error {
+	node, err := consumeNode(data)
+	if err != nil {
+		return err
+	}
+	return node.Decode(doc)
+}
+
+func appendNode(b []byte, node *yamlLib.Node) []byte {
+	if node.Kind == yamlLib.AliasNode {
+		node = node.Alias
+	}
+	b = protowire.AppendTag(b, nodeKind, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(node.Kind))
+	// the resolved tag keeps the quoted scalars as strings, the style of the nodes is not kept
+	if tag := node.ShortTag(); tag != "" {
+		b = protowire.AppendTag(b, nodeTag, protowire.BytesType)
+		b = protowire.AppendString(b, tag)
+	}
+	if node.Value != "" {
+		b = protowire.AppendTag(b, nodeValue, protowire.BytesType)
+		b = protowire.AppendString(b, node.Value)
+	}
+	for _, child := range node.Content {
+		b = protowire.AppendTag(b, nodeContent, protowire.BytesType)
+		b = protowire.AppendBytes(b, appendNode(nil, child))
+	}
+	return b
+}
+
+func consumeNode(b []byte) (*yamlLib.Node, error) {
+	node := &yamlLib.Node{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch {
+		case num == nodeKind && typ == protowire.VarintType:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			node.Kind = yamlLib.Kind(v)
+			b = b[n:]
+		case num == nodeTag && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			node.Tag = v
+			b = b[n:]
+		case num == nodeValue && typ == protowire.BytesType:
+			v, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			node.Value = v
+			b = b[n:]
+		case num == nodeContent && typ == protowire.BytesType:
+			v, n := protowire.ConsumeBytes(b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			child, err := consumeNode(v)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+			b = b[n:]
+		default:
+			// skip the unknown fields, written by a newer version
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return nil, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return node, nil
+}
+
+// decodeProtobufDocs decodes the length prefixed docs of a protobuf file.
+func decodeProtobufDocs(ctx context.Context, r io.Reader) ([]*NetworkTrafficDoc, error) {
+	br := bufio.NewReader(&ctxReader{
+		ctx: ctx,
+		r:   r,
+	})
+	var docs []*NetworkTrafficDoc
+	for {
+		size, err := binary.ReadUvarint(br)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		data := make([]byte, size)
+		_, err = io.ReadFull(br, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the protobuf doc: %w", err)
+		}
+		var doc *NetworkTrafficDoc
+		err = UnmarshalProtobuf(data, &doc)
+		if err != nil {
+			return nil, err
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+}
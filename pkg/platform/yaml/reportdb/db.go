@@ -21,14 +21,17 @@ type TestReport struct {
 	Logger *zap.Logger
 	Path   string
 	Name   string
+	// cipher encrypts and decrypts the reports, nil keeps them in plain text
+	cipher *yaml.Cipher
 }
 
-func New(logger *zap.Logger, reportPath string) *TestReport {
+func New(logger *zap.Logger, reportPath string, cipher *yaml.Cipher) *TestReport {
 	return &TestReport{
 		tests:  make(map[string]map[string][]models.TestResult),
 		m:      sync.Mutex{},
 		Logger: logger,
 		Path:   reportPath,
+		cipher: cipher,
 	}
 }
 
@@ -75,6 +78,11 @@ func (fe *TestReport) GetReport(ctx context.Context, testRunID string, testSetID
 		utils.LogError(fe.Logger, err, "failed to read the mocks from config yaml", zap.Any("session", filepath.Base(path)))
 		return nil, err
 	}
+	data, err = fe.cipher.DecryptFile(reportName, data)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to decrypt the report", zap.Any("session", filepath.Base(path)))
+		return nil, err
+	}
 
 	decoder := yamlLib.NewDecoder(bytes.NewReader(data))
 	var doc models.TestReport
@@ -99,6 +107,11 @@ func (fe *TestReport) InsertReport(ctx context.Context, testRunID string, testSe
 		return fmt.Errorf("%s failed to marshal document to yaml. error: %s", utils.Emoji, err.Error())
 	}
 	data = append(data, d...)
+	data, err = fe.cipher.EncryptFile(testReport.Name, data)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to encrypt the report", zap.Any("session", filepath.Base(reportPath)))
+		return err
+	}
 
 	err = yaml.WriteFile(ctx, fe.Logger, reportPath, testReport.Name, data, false)
 	if err != nil {
@@ -107,3 +120,44 @@ func (fe *TestReport) InsertReport(ctx context.Context, testRunID string, testSe
 	}
 	return nil
 }
+
+// coverageReport is the file of the coverage of the app in the directory of the test run.
+const coverageReport = "coverage"
+
+// InsertCoverage writes the coverage of the app over the test run.
+func (fe *TestReport) InsertCoverage(ctx context.Context, testRunID string, coverage *models.CoverageReport) error {
+	data, err := yamlLib.Marshal(coverage)
+	if err != nil {
+		return fmt.Errorf("%s failed to marshal the coverage to yaml. error: %s", utils.Emoji, err.Error())
+	}
+	reportPath := filepath.Join(fe.Path, testRunID)
+	data, err = fe.cipher.EncryptFile(coverageReport, data)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to encrypt the coverage", zap.Any("session", filepath.Base(reportPath)))
+		return err
+	}
+	err = yaml.WriteFile(ctx, fe.Logger, reportPath, coverageReport, data, false)
+	if err != nil {
+		utils.LogError(fe.Logger, err, "failed to write the coverage to yaml", zap.Any("session", filepath.Base(reportPath)))
+		return err
+	}
+	return nil
+}
+
+// GetCoverage returns the coverage of the app over the test run, an error if it wasn't collected.
+func (fe *TestReport) GetCoverage(ctx context.Context, testRunID string) (*models.CoverageReport, error) {
+	path := filepath.Join(fe.Path, testRunID)
+	data, err := yaml.ReadFile(ctx, fe.Logger, path, coverageReport)
+	if err != nil {
+		return nil, err
+	}
+	data, err = fe.cipher.DecryptFile(coverageReport, data)
+	if err != nil {
+		return nil, err
+	}
+	var coverage models.CoverageReport
+	if err := yamlLib.Unmarshal(data, &coverage); err != nil {
+		return nil, fmt.Errorf("%s failed to decode the coverage of %s. error: %v", utils.Emoji, testRunID, err)
+	}
+	return &coverage, nil
+}
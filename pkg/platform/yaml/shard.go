@@ -0,0 +1,327 @@
+package yaml
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protowire"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// The formats of the sharded files.
+const (
+	FormatYaml     = "yaml"
+	FormatProtobuf = "protobuf"
+)
+
+// formats maps the formats to the extensions of their files, in the order the shards are read.
+var formats = []struct {
+	format string
+	ext    string
+}{
+	{FormatYaml, ".yaml"},
+	{FormatProtobuf, ".pb"},
+}
+
+// Ext returns the extension of the files of the format, the yaml extension is returned for an unknown format.
+func Ext(format string) string {
+	for _, f := range formats {
+		if f.format == format {
+			return f.ext
+		}
+	}
+	return ".yaml"
+}
+
+// ShardWriter appends docs to <name>.<ext> and rotates it into the numbered shards <name>-1.<ext>,
+// <name>-2.<ext>... once the current shard would grow past maxSize bytes. The current shard is kept open
+// between the writes, so a doc is written without reading or rewriting the previous docs. The yaml docs are
+// separated by "---", the protobuf docs are prefixed with their length.
+type ShardWriter struct {
+	logger  *zap.Logger
+	path    string
+	name    string
+	format  string
+	maxSize int64
+
+	mutex sync.Mutex
+	file  *os.File
+	shard int
+	size  int64
+}
+
+// NewShardWriter returns the writer of the shards of <path>/<name> in the format, maxSize <= 0 disables the rotation.
+func NewShardWriter(logger *zap.Logger, path, name, format string, maxSize int64) *ShardWriter {
+	return &ShardWriter{
+		logger:  logger,
+		path:    path,
+		name:    name,
+		format:  format,
+		maxSize: maxSize,
+	}
+}
+
+// Write appends the doc to the last shard, after a new shard is started if the doc doesn't fit in it.
+func (w *ShardWriter) Write(ctx context.Context, doc []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.file == nil {
+		// continue the last shard of the docs written before
+		shards, err := ShardNames(w.path, w.name, w.format)
+		if err != nil {
+			return err
+		}
+		if len(shards) > 0 {
+			w.shard = shardIndex(w.name, shards[len(shards)-1])
+		}
+		err = w.open()
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.maxSize > 0 && w.size > 0 && w.size+int64(len(doc))+4 > w.maxSize {
+		err := w.file.Close()
+		if err != nil {
+			utils.LogError(w.logger, err, "failed to close the shard", zap.String("file", w.file.Name()))
+		}
+		w.shard++
+		err = w.open()
+		if err != nil {
+			return err
+		}
+		w.logger.Debug("rotated the file into a new shard", zap.String("file", w.file.Name()))
+	}
+
+	if w.format == FormatProtobuf {
+		doc = protowire.AppendBytes(nil, doc)
+	} else if w.size > 0 {
+		doc = append([]byte("---\n"), doc...)
+	}
+	cw := &ctxWriter{
+		ctx:    ctx,
+		writer: w.file,
+	}
+	n, err := cw.Write(doc)
+	w.size += int64(n)
+	if err != nil {
+		if err == ctx.Err() {
+			return nil // Ignore context cancellation error
+		}
+		utils.LogError(w.logger, err, "failed to write the document", zap.String("file name", w.file.Name()))
+		return err
+	}
+	return nil
+}
+
+// Close closes the current shard, the next write opens the last shard again.
+func (w *ShardWriter) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+func (w *ShardWriter) open() error {
+	fileName := shardName(w.name, w.shard)
+	if w.format != FormatProtobuf {
+		_, err := CreateYamlFile(context.Background(), w.logger, w.path, fileName)
+		if err != nil {
+			return err
+		}
+	}
+	err := os.MkdirAll(w.path, 0777)
+	if err != nil {
+		utils.LogError(w.logger, err, "failed to create a directory for the file", zap.String("path directory", w.path))
+		return err
+	}
+	filePath := filepath.Join(w.path, fileName+Ext(w.format))
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0777)
+	if err != nil {
+		utils.LogError(w.logger, err, "failed to open file for writing", zap.String("file", filePath))
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	w.file = file
+	w.size = info.Size()
+	return nil
+}
+
+// ShardNames returns the names (without the extension) of the shards of <path>/<name> in the format in their order.
+func ShardNames(path, name, format string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var shards []string
+	for _, entry := range entries {
+		fileName := entry.Name()
+		if entry.IsDir() || filepath.Ext(fileName) != Ext(format) {
+			continue
+		}
+		fileName = strings.TrimSuffix(fileName, Ext(format))
+		if shardIndex(name, fileName) >= 0 {
+			shards = append(shards, fileName)
+		}
+	}
+	sort.Slice(shards, func(i, j int) bool {
+		return shardIndex(name, shards[i]) < shardIndex(name, shards[j])
+	})
+	return shards, nil
+}
+
+// ShardFiles returns the paths of the shards of <path>/<name> in every format.
+func ShardFiles(path, name string) ([]string, error) {
+	var files []string
+	for _, f := range formats {
+		shards, err := ShardNames(path, name, f.format)
+		if err != nil {
+			return nil, err
+		}
+		for _, shard := range shards {
+			files = append(files, filepath.Join(path, shard+f.ext))
+		}
+	}
+	return files, nil
+}
+
+// HasShards reports whether <path>/<name> has a shard in any format.
+func HasShards(path, name string) (bool, error) {
+	files, err := ShardFiles(path, name)
+	return len(files) > 0, err
+}
+
+// RemoveShards deletes all the shards of <path>/<name> in every format.
+func RemoveShards(path, name string) error {
+	files, err := ShardFiles(path, name)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		err = os.Remove(file)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadDocs decodes the docs of all the shards of <path>/<name>, the yaml shards first and then the protobuf shards.
+// The shards are decoded as they are read, without loading them in memory first.
+func ReadDocs(ctx context.Context, logger *zap.Logger, path, name string) ([]*NetworkTrafficDoc, error) {
+	var docs []*NetworkTrafficDoc
+	for _, f := range formats {
+		shards, err := ShardNames(path, name, f.format)
+		if err != nil {
+			return nil, err
+		}
+		for _, shard := range shards {
+			shardDocs, err := readShard(ctx, logger, filepath.Join(path, shard+f.ext), f.format)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, shardDocs...)
+		}
+	}
+	return docs, nil
+}
+
+// ReadShard decodes the docs of a yaml or protobuf file, the format is found from the extension of the file.
+func ReadShard(ctx context.Context, logger *zap.Logger, filePath string) ([]*NetworkTrafficDoc, error) {
+	format := FormatYaml
+	if filepath.Ext(filePath) == Ext(FormatProtobuf) {
+		format = FormatProtobuf
+	}
+	return readShard(ctx, logger, filePath, format)
+}
+
+func readShard(ctx context.Context, logger *zap.Logger, filePath, format string) ([]*NetworkTrafficDoc, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the file: %v", err)
+	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close file", zap.String("file", filePath))
+		}
+	}()
+
+	if format == FormatProtobuf {
+		docs, err := decodeProtobufDocs(ctx, file)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to decode the protobuf file documents of %s. error: %v", filepath.Base(filePath), err.Error())
+		}
+		return docs, nil
+	}
+
+	dec := yamlLib.NewDecoder(&ctxReader{
+		ctx: ctx,
+		r:   file,
+	})
+	var docs []*NetworkTrafficDoc
+	for {
+		var doc *NetworkTrafficDoc
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return docs, nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to decode the yaml file documents of %s. error: %v", filepath.Base(filePath), err.Error())
+		}
+		if doc != nil {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// shardName returns the file name of the shard, the first shard is the file itself.
+func shardName(name string, shard int) string {
+	if shard == 0 {
+		return name
+	}
+	return name + "-" + strconv.Itoa(shard)
+}
+
+// shardIndex returns the index of the shard of name with the given file name, -1 if it is not a shard of name.
+func shardIndex(name, fileName string) int {
+	if fileName == name {
+		return 0
+	}
+	suffix, ok := strings.CutPrefix(fileName, name+"-")
+	if !ok {
+		return -1
+	}
+	index, err := strconv.Atoi(suffix)
+	if err != nil || index <= 0 {
+		return -1
+	}
+	return index
+}
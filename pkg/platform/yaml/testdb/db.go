@@ -20,12 +20,15 @@ import (
 type TestYaml struct {
 	TcsPath string
 	logger  *zap.Logger
+	// cipher encrypts and decrypts the testcases, nil keeps them in plain text
+	cipher *yaml.Cipher
 }
 
-func New(logger *zap.Logger, tcsPath string) *TestYaml {
+func New(logger *zap.Logger, tcsPath string, cipher *yaml.Cipher) *TestYaml {
 	return &TestYaml{
 		TcsPath: tcsPath,
 		logger:  logger,
+		cipher:  cipher,
 	}
 }
 
@@ -90,7 +93,7 @@ func (ts *TestYaml) GetTestCases(ctx context.Context, testSetID string) ([]*mode
 			return nil, err
 		}
 
-		tc, err := Decode(testCase, ts.logger)
+		tc, err := Decode(testCase, ts.cipher, ts.logger)
 		if err != nil {
 			utils.LogError(ts.logger, err, "failed to decode the testcase")
 			return nil, err
@@ -98,7 +101,7 @@ func (ts *TestYaml) GetTestCases(ctx context.Context, testSetID string) ([]*mode
 		tcs = append(tcs, tc)
 	}
 	sort.SliceStable(tcs, func(i, j int) bool {
-		return tcs[i].HTTPReq.Timestamp.Before(tcs[j].HTTPReq.Timestamp)
+		return tcs[i].ReqTimestamp().Before(tcs[j].ReqTimestamp())
 	})
 	return tcs, nil
 }
@@ -114,6 +117,21 @@ func (ts *TestYaml) UpdateTestCase(ctx context.Context, tc *models.TestCase, tes
 	return nil
 }
 
+// DeleteTestCase removes the testcase of the test set with the given name.
+func (ts *TestYaml) DeleteTestCase(_ context.Context, testSetID string, name string) error {
+	tcPath, err := yaml.ValidatePath(filepath.Join(ts.TcsPath, testSetID, "tests", name+".yaml"))
+	if err != nil {
+		return err
+	}
+	err = os.Remove(tcPath)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to delete the testcase yaml file", zap.String("path", tcPath))
+		return err
+	}
+	ts.logger.Info("🗑️ Keploy has discarded the test case.", zap.String("path", filepath.Dir(tcPath)), zap.String("testcase name", name))
+	return nil
+}
+
 func (ts *TestYaml) upsert(ctx context.Context, testSetID string, tc *models.TestCase) (tcsInfo, error) {
 	tcsPath := filepath.Join(ts.TcsPath, testSetID, "tests")
 	var tcsName string
@@ -131,6 +149,12 @@ func (ts *TestYaml) upsert(ctx context.Context, testSetID string, tc *models.Tes
 		return tcsInfo{name: tcsName, path: tcsPath}, err
 	}
 	yamlTc.Name = tcsName
+	tc.Name = tcsName
+	err = ts.cipher.Encrypt(yamlTc)
+	if err != nil {
+		utils.LogError(ts.logger, err, "failed to encrypt the testcase")
+		return tcsInfo{name: tcsName, path: tcsPath}, err
+	}
 	data, err := yamlLib.Marshal(&yamlTc)
 	if err != nil {
 		return tcsInfo{name: tcsName, path: tcsPath}, err
@@ -20,52 +20,65 @@ import (
 
 func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTrafficDoc, error) {
 
-	header := pkg.ToHTTPHeader(tc.HTTPReq.Header)
-	curl := pkg.MakeCurlCommand(string(tc.HTTPReq.Method), tc.HTTPReq.URL, pkg.ToYamlHTTPHeader(header), tc.HTTPReq.Body)
 	doc := &yaml.NetworkTrafficDoc{
-		Version: tc.Version,
-		Kind:    tc.Kind,
-		Name:    tc.Name,
-		Curl:    curl,
+		Version:       tc.Version,
+		SchemaVersion: yaml.SchemaVersion,
+		Kind:          tc.Kind,
+		Name:          tc.Name,
 	}
-	// find noisy fields
-	m, err := FlattenHTTPResponse(pkg.ToHTTPHeader(tc.HTTPResp.Header), tc.HTTPResp.Body)
-	if err != nil {
-		msg := "error in flattening http response"
-		utils.LogError(logger, err, msg)
-	}
-	noise := tc.Noise
 
-	noiseFieldsFound := FindNoisyFields(m, func(_ string, vals []string) bool {
-		// check if k is date
-		for _, v := range vals {
-			if pkg.IsTime(v) {
-				return true
-			}
+	switch tc.Kind {
+	case models.HTTP:
+		header := pkg.ToHTTPHeader(tc.HTTPReq.Header)
+		doc.Curl = pkg.MakeCurlCommand(string(tc.HTTPReq.Method), tc.HTTPReq.URL, pkg.ToYamlHTTPHeader(header), tc.HTTPReq.Body)
+
+		// find noisy fields
+		m, err := FlattenHTTPResponse(pkg.ToHTTPHeader(tc.HTTPResp.Header), tc.HTTPResp.Body)
+		if err != nil {
+			msg := "error in flattening http response"
+			utils.LogError(logger, err, msg)
 		}
+		noise := tc.Noise
+
+		noiseFieldsFound := FindNoisyFields(m, func(_ string, vals []string) bool {
+			// check if k is date
+			for _, v := range vals {
+				if pkg.IsTime(v) {
+					return true
+				}
+			}
 
-		// maybe we need to concatenate the values
-		return pkg.IsTime(strings.Join(vals, ", "))
-	})
+			// maybe we need to concatenate the values
+			return pkg.IsTime(strings.Join(vals, ", "))
+		})
 
-	for _, v := range noiseFieldsFound {
-		noise[v] = []string{}
-	}
+		for _, v := range noiseFieldsFound {
+			noise[v] = []string{}
+		}
 
-	switch tc.Kind {
-	case models.HTTP:
-		err := doc.Spec.Encode(models.HTTPSchema{
-			Request:  tc.HTTPReq,
-			Response: tc.HTTPResp,
-			Created:  tc.Created,
-			Assertions: map[string]interface{}{
-				"noise": noise,
-			},
+		err = doc.Spec.Encode(models.HTTPSchema{
+			Metadata:   encodeMetadata(tc),
+			Request:    tc.HTTPReq,
+			Response:   tc.HTTPResp,
+			Created:    tc.Created,
+			Assertions: encodeAssertions(noise, tc.MaxLatencyMs, tc.Capture),
 		})
 		if err != nil {
 			utils.LogError(logger, err, "failed to encode testcase into a yaml doc")
 			return nil, err
 		}
+	case models.GRPC_EXPORT:
+		err := doc.Spec.Encode(models.GrpcSpec{
+			Metadata:   encodeMetadata(tc),
+			GrpcReq:    tc.GrpcReq,
+			GrpcResp:   tc.GrpcResp,
+			Created:    tc.Created,
+			Assertions: encodeAssertions(tc.Noise, tc.MaxLatencyMs, tc.Capture),
+		})
+		if err != nil {
+			utils.LogError(logger, err, "failed to encode gRPC testcase into a yaml doc")
+			return nil, err
+		}
 	default:
 		utils.LogError(logger, nil, "failed to marshal the testcase into yaml due to invalid kind of testcase")
 		return nil, errors.New("type of testcases is invalid")
@@ -73,6 +86,32 @@ func EncodeTestcase(tc models.TestCase, logger *zap.Logger) (*yaml.NetworkTraffi
 	return doc, nil
 }
 
+// encodeMetadata returns the metadata of a yaml testcase with its correlation id, tags, description and client,
+// nil if it has none of them.
+func encodeMetadata(tc models.TestCase) map[string]string {
+	metadata := map[string]string{}
+	if tc.CorrelationID != "" {
+		metadata[models.CorrelationIDKey] = tc.CorrelationID
+	}
+	if len(tc.Tags) > 0 {
+		metadata[models.TagsKey] = strings.Join(tc.Tags, ",")
+	}
+	if tc.Description != "" {
+		metadata[models.DescriptionKey] = tc.Description
+	}
+	if tc.ClientIP != "" {
+		metadata[models.ClientIPKey] = tc.ClientIP
+		metadata[models.ClientPortKey] = strconv.Itoa(int(tc.ClientPort))
+	}
+	if tc.UserAgent != "" {
+		metadata[models.UserAgentKey] = tc.UserAgent
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return metadata
+}
+
 func FindNoisyFields(m map[string][]string, comparator func(string, []string) bool) []string {
 	var noise []string
 	for k, v := range m {
@@ -224,7 +263,17 @@ func HasBannedHeaders(object map[string]string, bannedHeaders map[string]string)
 	return false, nil
 }
 
-func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.TestCase, error) {
+func Decode(yamlTestcase *yaml.NetworkTrafficDoc, cipher *yaml.Cipher, logger *zap.Logger) (*models.TestCase, error) {
+	err := cipher.Decrypt(yamlTestcase)
+	if err != nil {
+		utils.LogError(logger, err, "failed to decrypt the testcase", zap.String("testcase", yamlTestcase.Name))
+		return nil, err
+	}
+	_, err = yaml.Migrate(yamlTestcase)
+	if err != nil {
+		utils.LogError(logger, err, "failed to migrate the testcase to the current schema version", zap.String("testcase", yamlTestcase.Name))
+		return nil, err
+	}
 	tc := models.TestCase{
 		Version: yamlTestcase.Version,
 		Kind:    yamlTestcase.Kind,
@@ -242,20 +291,13 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 		tc.Created = httpSpec.Created
 		tc.HTTPReq = httpSpec.Request
 		tc.HTTPResp = httpSpec.Response
-		tc.Noise = map[string][]string{}
-		switch reflect.ValueOf(httpSpec.Assertions["noise"]).Kind() {
-		case reflect.Map:
-			for k, v := range httpSpec.Assertions["noise"].(map[string]interface{}) {
-				tc.Noise[k] = []string{}
-				for _, val := range v.([]interface{}) {
-					tc.Noise[k] = append(tc.Noise[k], val.(string))
-				}
-			}
-		case reflect.Slice:
-			for _, v := range httpSpec.Assertions["noise"].([]interface{}) {
-				tc.Noise[v.(string)] = []string{}
-			}
-		}
+		tc.Noise = decodeNoise(httpSpec.Assertions["noise"])
+		tc.MaxLatencyMs = decodeMaxLatency(httpSpec.Assertions["maxLatencyMs"])
+		tc.Capture = decodeCapture(httpSpec.Assertions["capture"])
+		tc.CorrelationID = httpSpec.Metadata[models.CorrelationIDKey]
+		tc.Tags = pkg.SplitTags(httpSpec.Metadata[models.TagsKey])
+		tc.Description = httpSpec.Metadata[models.DescriptionKey]
+		decodeClient(&tc, httpSpec.Metadata)
 	// unmarshal its mocks from yaml docs to go struct
 	case models.GRPC_EXPORT:
 		grpcSpec := models.GrpcSpec{}
@@ -264,11 +306,98 @@ func Decode(yamlTestcase *yaml.NetworkTrafficDoc, logger *zap.Logger) (*models.T
 			utils.LogError(logger, err, "failed to unmarshal a yaml doc into the gRPC testcase")
 			return nil, err
 		}
+		tc.Created = grpcSpec.Created
 		tc.GrpcReq = grpcSpec.GrpcReq
 		tc.GrpcResp = grpcSpec.GrpcResp
+		tc.Noise = decodeNoise(grpcSpec.Assertions["noise"])
+		tc.MaxLatencyMs = decodeMaxLatency(grpcSpec.Assertions["maxLatencyMs"])
+		tc.Capture = decodeCapture(grpcSpec.Assertions["capture"])
+		tc.CorrelationID = grpcSpec.Metadata[models.CorrelationIDKey]
+		tc.Tags = pkg.SplitTags(grpcSpec.Metadata[models.TagsKey])
+		tc.Description = grpcSpec.Metadata[models.DescriptionKey]
+		decodeClient(&tc, grpcSpec.Metadata)
 	default:
 		utils.LogError(logger, nil, "failed to unmarshal yaml doc of unknown type", zap.Any("type of yaml doc", tc.Kind))
 		return nil, errors.New("yaml doc of unknown type")
 	}
 	return &tc, nil
 }
+
+// decodeClient sets the client of the testcase from the metadata of the yaml testcase.
+func decodeClient(tc *models.TestCase, metadata map[string]string) {
+	tc.ClientIP = metadata[models.ClientIPKey]
+	if port, err := strconv.ParseUint(metadata[models.ClientPortKey], 10, 16); err == nil {
+		tc.ClientPort = uint16(port)
+	}
+	tc.UserAgent = metadata[models.UserAgentKey]
+}
+
+// decodeNoise converts the noise assertion of a yaml testcase, stored either as a map of the fields to
+// their regexes or as a list of fields, into the noise of the testcase.
+func decodeNoise(assertion interface{}) map[string][]string {
+	noise := map[string][]string{}
+	switch reflect.ValueOf(assertion).Kind() {
+	case reflect.Map:
+		for k, v := range assertion.(map[string]interface{}) {
+			noise[k] = []string{}
+			for _, val := range v.([]interface{}) {
+				noise[k] = append(noise[k], val.(string))
+			}
+		}
+	case reflect.Slice:
+		for _, v := range assertion.([]interface{}) {
+			noise[v.(string)] = []string{}
+		}
+	}
+	return noise
+}
+
+// encodeAssertions returns the assertions of a yaml testcase. The latency assertion and the capture expressions are
+// added only if they are set.
+func encodeAssertions(noise map[string][]string, maxLatencyMs uint64, capture []string) map[string]interface{} {
+	assertions := map[string]interface{}{
+		"noise": noise,
+	}
+	if maxLatencyMs > 0 {
+		assertions["maxLatencyMs"] = maxLatencyMs
+	}
+	if len(capture) > 0 {
+		assertions["capture"] = capture
+	}
+	return assertions
+}
+
+// decodeCapture converts the capture assertion of a yaml testcase, a list of expressions or a single one, into the
+// capture expressions of the testcase.
+func decodeCapture(assertion interface{}) []string {
+	switch v := assertion.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		var capture []string
+		for _, expr := range v {
+			if expr, ok := expr.(string); ok && expr != "" {
+				capture = append(capture, expr)
+			}
+		}
+		return capture
+	}
+	return nil
+}
+
+// decodeMaxLatency converts the maxLatencyMs assertion of a yaml testcase into milliseconds.
+func decodeMaxLatency(assertion interface{}) uint64 {
+	switch v := assertion.(type) {
+	case int:
+		if v > 0 {
+			return uint64(v)
+		}
+	case uint64:
+		return v
+	case float64:
+		if v > 0 {
+			return uint64(v)
+		}
+	}
+	return 0
+}
@@ -16,12 +16,13 @@ import (
 
 // NetworkTrafficDoc stores the request-response data of a network call (ingress or egress)
 type NetworkTrafficDoc struct {
-	Version      models.Version `json:"version" yaml:"version"`
-	Kind         models.Kind    `json:"kind" yaml:"kind"`
-	Name         string         `json:"name" yaml:"name"`
-	Spec         yamlLib.Node   `json:"spec" yaml:"spec"`
-	Curl         string         `json:"curl" yaml:"curl,omitempty"`
-	ConnectionID string         `json:"connectionId" yaml:"connectionId,omitempty"`
+	Version       models.Version `json:"version" yaml:"version"`
+	SchemaVersion int            `json:"schemaVersion" yaml:"schemaVersion,omitempty"` // see SchemaVersion, 1 if empty
+	Kind          models.Kind    `json:"kind" yaml:"kind"`
+	Name          string         `json:"name" yaml:"name"`
+	Spec          yamlLib.Node   `json:"spec" yaml:"spec"`
+	Curl          string         `json:"curl" yaml:"curl,omitempty"`
+	ConnectionID  string         `json:"connectionId" yaml:"connectionId,omitempty"`
 }
 
 // ctxReader wraps an io.Reader with a context for cancellation support
@@ -182,7 +183,7 @@ func ReadSessionIndices(_ context.Context, path string, Logger *zap.Logger) ([]s
 	}
 
 	for _, v := range files {
-		if v.Name() != "reports" && v.Name() != "testReports" {
+		if v.Name() != "reports" && v.Name() != "testReports" && v.Name() != models.SharedMocks {
 			indices = append(indices, v.Name())
 		}
 	}
@@ -0,0 +1,502 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/agent/agentpb"
+	"go.keploy.io/server/v2/pkg/service/bundle"
+	"go.keploy.io/server/v2/pkg/service/record"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type Agent struct {
+	agentpb.UnimplementedAgentServer
+	logger   *zap.Logger
+	services ServiceFactory
+	config   config.Config
+
+	// ctx is the context of the agent, the sessions are cancelled with it
+	ctx      context.Context
+	mu       sync.Mutex
+	current  *session
+	sessions map[string]*session
+	id       utils.AutoInc
+}
+
+func New(logger *zap.Logger, services ServiceFactory, config config.Config) Service {
+	return &Agent{
+		logger:   logger,
+		services: services,
+		config:   config,
+		sessions: map[string]*session{},
+	}
+}
+
+// session is a recording or a test run, run in the background until it completes or is stopped.
+type session struct {
+	mu        sync.Mutex
+	id        string
+	kind      agentpb.SessionKind
+	state     agentpb.SessionState
+	err       string
+	started   time.Time
+	completed time.Time
+	testRunID string
+	statuses  map[string]string
+	// client is the user of keploy agent the session is run for, nil for the sessions run with the agent config
+	client *config.Client
+	// stopped is set once the session is stopped by a client, it is ended by the cancel otherwise
+	stopped bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func (s *session) stop() {
+	s.mu.Lock()
+	s.stopped = true
+	s.mu.Unlock()
+	s.cancel()
+}
+
+func (s *session) proto() *agentpb.Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := &agentpb.Session{
+		Id:              s.id,
+		Kind:            s.kind,
+		State:           s.state,
+		Error:           s.err,
+		Started:         s.started.Unix(),
+		TestRunId:       s.testRunID,
+		TestSetStatuses: map[string]string{},
+	}
+	if !s.completed.IsZero() {
+		res.Completed = s.completed.Unix()
+	}
+	for testSetID, status := range s.statuses {
+		res.TestSetStatuses[testSetID] = status
+	}
+	return res
+}
+
+// Serve serves the gRPC control plane on the agent port and on the unix socket of its clients.
+func (a *Agent) Serve(ctx context.Context) error {
+	a.ctx = ctx
+	tlsCert, tlsKey := a.config.Agent.TLSCert, a.config.Agent.TLSKey
+	if (tlsCert == "") != (tlsKey == "") {
+		return errors.New("both agent.tlsCert and agent.tlsKey are required to serve over TLS")
+	}
+	if a.config.Agent.Port == 0 && a.config.Agent.Socket == "" {
+		return errors.New("neither the agent port nor the agent socket is set")
+	}
+	token := agentToken(a.config.Agent)
+	// the agent port is only reachable from the other hosts when it is protected by a token
+	host := ""
+	if token == "" {
+		host = "127.0.0.1"
+		a.logger.Warn("the agent is not protected by a token so its port only listens on 127.0.0.1, set the token with KEPLOY_AGENT_TOKEN or agent.token to reach it from the other hosts")
+	}
+
+	var servers []*grpc.Server
+	g, ctx := errgroup.WithContext(ctx)
+	if a.config.Agent.Port != 0 {
+		opts := []grpc.ServerOption{grpc.UnaryInterceptor(authenticate(token))}
+		if tlsCert != "" {
+			creds, err := credentials.NewServerTLSFromFile(tlsCert, tlsKey)
+			if err != nil {
+				utils.LogError(a.logger, err, "failed to load the tls certificate of the agent")
+				return err
+			}
+			opts = append(opts, grpc.Creds(creds))
+		}
+		lis, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(int(a.config.Agent.Port))))
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to listen on the agent port", zap.Uint32("port", a.config.Agent.Port))
+			return err
+		}
+		srv := grpc.NewServer(opts...)
+		servers = append(servers, srv)
+		g.Go(func() error {
+			defer utils.Recover(a.logger)
+			a.logger.Info("keploy agent started", zap.String("address", lis.Addr().String()), zap.Bool("tls", tlsCert != ""))
+			return srv.Serve(lis)
+		})
+	}
+	if a.config.Agent.Socket != "" {
+		lis, err := listenUnix(a.config.Agent.Socket)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to listen on the agent socket", zap.String("socket", a.config.Agent.Socket))
+			return err
+		}
+		// the peer credentials identify the user of each client, to run its sessions as the user
+		srv := grpc.NewServer(grpc.Creds(peerCredentials{}), grpc.UnaryInterceptor(authenticate(token)))
+		servers = append(servers, srv)
+		g.Go(func() error {
+			defer utils.Recover(a.logger)
+			a.logger.Info("keploy agent is listening for its clients", zap.String("socket", a.config.Agent.Socket))
+			return srv.Serve(lis)
+		})
+	}
+	for _, srv := range servers {
+		agentpb.RegisterAgentServer(srv, a)
+	}
+
+	g.Go(func() error {
+		defer utils.Recover(a.logger)
+		<-ctx.Done()
+		// the running session is stopped before the servers, so that its state can still be read
+		a.stopCurrent()
+		for _, srv := range servers {
+			srv.GracefulStop()
+		}
+		return nil
+	})
+	return g.Wait()
+}
+
+// agentToken returns the token of the agent, KEPLOY_AGENT_TOKEN takes precedence over the config.
+func agentToken(cfg config.Agent) string {
+	token := os.Getenv("KEPLOY_AGENT_TOKEN")
+	if token == "" {
+		token = cfg.Token
+	}
+	return token
+}
+
+// authenticate rejects the calls without the bearer token, if any, in their authorization metadata.
+func authenticate(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if token == "" {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, auth := range md.Get("authorization") {
+			bearer, ok := strings.CutPrefix(auth, "Bearer ")
+			if ok && subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) == 1 {
+				return handler(ctx, req)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "missing or invalid bearer token")
+	}
+}
+
+func (a *Agent) StartRecording(ctx context.Context, req *agentpb.StartRecordingRequest) (*agentpb.Session, error) {
+	cfg, err := a.clientConfig(ctx, req.GetClient())
+	if err != nil {
+		return nil, err
+	}
+	return a.start(agentpb.SessionKind_SESSION_KIND_RECORD, cfg, func(ctx context.Context, _ *session) error {
+		svc, err := a.service(ctx, "record", cfg)
+		if err != nil {
+			return err
+		}
+		recorder, ok := svc.(record.Service)
+		if !ok {
+			return errors.New("service doesn't satisfy record service interface")
+		}
+		return recorder.Start(ctx)
+	})
+}
+
+func (a *Agent) StartTestRun(ctx context.Context, req *agentpb.StartTestRunRequest) (*agentpb.Session, error) {
+	cfg, err := a.clientConfig(ctx, req.GetClient())
+	if err != nil {
+		return nil, err
+	}
+	testSets := req.GetTestSets()
+	return a.start(agentpb.SessionKind_SESSION_KIND_TEST, cfg, func(ctx context.Context, s *session) error {
+		return a.runTests(ctx, s, cfg, testSets)
+	})
+}
+
+// service returns the service of the command for a session, with the config of its client if any.
+func (a *Agent) service(ctx context.Context, cmd string, cfg *config.Config) (interface{}, error) {
+	if cfg == nil {
+		return a.services.GetService(ctx, cmd)
+	}
+	return a.services.GetServiceWithConfig(ctx, cmd, *cfg)
+}
+
+// runTests runs the test sets in a new test run like keploy test, without stopping keploy once they are run.
+func (a *Agent) runTests(ctx context.Context, s *session, cfg *config.Config, testSetIDs []string) error {
+	g, ctx := errgroup.WithContext(ctx)
+	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
+	var hookCancel context.CancelFunc
+	defer func() {
+		if hookCancel != nil {
+			hookCancel()
+		}
+		if err := g.Wait(); err != nil {
+			utils.LogError(a.logger, err, "failed to stop the test run")
+		}
+	}()
+
+	svc, err := a.service(ctx, "test", cfg)
+	if err != nil {
+		return err
+	}
+	replayer, ok := svc.(replay.Service)
+	if !ok {
+		return errors.New("service doesn't satisfy replay service interface")
+	}
+	if len(testSetIDs) == 0 {
+		testSetIDs, err = replayer.GetAllTestSetIDs(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	testRunID, appID, cancel, err := replayer.BootReplay(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to boot replay: %w", err)
+	}
+	hookCancel = cancel
+	s.mu.Lock()
+	s.testRunID = testRunID
+	s.mu.Unlock()
+
+	for _, testSetID := range testSetIDs {
+		testSetStatus, err := replayer.RunTestSet(ctx, testSetID, testRunID, appID, false)
+		if err != nil {
+			return fmt.Errorf("failed to run test set %s: %w", testSetID, err)
+		}
+		s.mu.Lock()
+		s.statuses[testSetID] = string(testSetStatus)
+		s.mu.Unlock()
+		switch testSetStatus {
+		case models.TestSetStatusAppHalted, models.TestSetStatusInternalErr, models.TestSetStatusFaultUserApp:
+			return fmt.Errorf("test run aborted, test set %s: %s", testSetID, testSetStatus)
+		case models.TestSetStatusUserAbort:
+			return context.Canceled
+		}
+	}
+	return nil
+}
+
+// start runs the session in the background, the agent runs one session at a time as the app is hooked by it. The
+// sessions of the clients are run on a staging copy of their keploy directory, written back to them once it ends.
+func (a *Agent) start(kind agentpb.SessionKind, cfg *config.Config, run func(ctx context.Context, s *session) error) (*agentpb.Session, error) {
+	var client *config.Client
+	var files *staging
+	if cfg != nil && cfg.Client != nil {
+		client = cfg.Client
+		var err error
+		files, err = stage(a.ctx, cfg)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to stage the keploy directory of the client", zap.String("path", cfg.Path))
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current != nil {
+		if files != nil {
+			files.remove()
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "session %s is still running, stop it first", a.current.id)
+	}
+
+	ctx, cancel := context.WithCancel(a.ctx)
+	// the record and replay services end the session instead of stopping the agent
+	ctx = utils.WithSessionCancel(ctx, cancel)
+	s := &session{
+		id:       fmt.Sprintf("session-%d", a.id.Next()),
+		kind:     kind,
+		state:    agentpb.SessionState_SESSION_STATE_RUNNING,
+		started:  time.Now(),
+		statuses: map[string]string{},
+		client:   client,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+	}
+	a.current = s
+	a.sessions[s.id] = s
+	a.logger.Info("started the session", zap.String("session", s.id), zap.String("kind", kind.String()))
+
+	go func() {
+		defer utils.Recover(a.logger)
+		err := run(ctx, s)
+		if files != nil && cfg.Storage.Driver == "bundle" {
+			// the test sets are packed into the staged bundle like keploy record and keploy test do
+			if packErr := a.packBundle(context.WithoutCancel(ctx), cfg); packErr != nil {
+				err = errors.Join(err, packErr)
+			}
+		}
+		if files != nil {
+			// the files are written back even once the agent is stopping
+			if handErr := files.handBack(context.WithoutCancel(ctx)); handErr != nil {
+				utils.LogError(a.logger, handErr, "failed to write the keploy directory back to the client", zap.String("path", files.path))
+				err = errors.Join(err, handErr)
+			}
+			files.remove()
+		}
+		a.finish(s, err)
+	}()
+	return s.proto(), nil
+}
+
+// packBundle packs the staged keploy directory of the client into its staged bundle.
+func (a *Agent) packBundle(ctx context.Context, cfg *config.Config) error {
+	svc, err := a.service(ctx, "bundle", cfg)
+	if err != nil {
+		return err
+	}
+	packer, ok := svc.(bundle.Service)
+	if !ok {
+		return errors.New("service doesn't satisfy bundle service interface")
+	}
+	if err := packer.Pack(ctx); err != nil {
+		utils.LogError(a.logger, err, "failed to pack the test sets into the bundle of the client", zap.String("bundle", cfg.Bundle.File))
+		return err
+	}
+	return nil
+}
+
+func (a *Agent) finish(s *session, err error) {
+	s.mu.Lock()
+	s.completed = time.Now()
+	switch {
+	case s.stopped:
+		s.state = agentpb.SessionState_SESSION_STATE_STOPPED
+	case err != nil && !errors.Is(err, context.Canceled):
+		s.state = agentpb.SessionState_SESSION_STATE_FAILED
+		s.err = err.Error()
+		utils.LogError(a.logger, err, "the session failed", zap.String("session", s.id))
+	default:
+		s.state = agentpb.SessionState_SESSION_STATE_COMPLETED
+	}
+	s.mu.Unlock()
+	s.cancel()
+
+	a.mu.Lock()
+	if a.current == s {
+		a.current = nil
+	}
+	a.mu.Unlock()
+	close(s.done)
+	a.logger.Info("the session ended", zap.String("session", s.id), zap.String("state", s.state.String()))
+}
+
+func (a *Agent) StopRecording(ctx context.Context, req *agentpb.StopRequest) (*agentpb.Session, error) {
+	return a.stop(ctx, req.GetSessionId(), agentpb.SessionKind_SESSION_KIND_RECORD)
+}
+
+func (a *Agent) StopTestRun(ctx context.Context, req *agentpb.StopRequest) (*agentpb.Session, error) {
+	return a.stop(ctx, req.GetSessionId(), agentpb.SessionKind_SESSION_KIND_TEST)
+}
+
+// stop cancels the session and waits for it to end, so that the recorded testcases and mocks or the reports are written.
+func (a *Agent) stop(ctx context.Context, id string, kind agentpb.SessionKind) (*agentpb.Session, error) {
+	s, err := a.session(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, s); err != nil {
+		return nil, err
+	}
+	if s.kind != kind {
+		return nil, status.Errorf(codes.InvalidArgument, "session %s is a %s session", id, s.kind)
+	}
+	s.stop()
+	select {
+	case <-s.done:
+		return s.proto(), nil
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+}
+
+func (a *Agent) stopCurrent() {
+	a.mu.Lock()
+	s := a.current
+	a.mu.Unlock()
+	if s != nil {
+		s.stop()
+		<-s.done
+	}
+}
+
+func (a *Agent) session(id string) (*session, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s, ok := a.sessions[id]
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "session %s not found", id)
+	}
+	return s, nil
+}
+
+func (a *Agent) GetSession(ctx context.Context, req *agentpb.GetSessionRequest) (*agentpb.Session, error) {
+	s, err := a.session(req.GetSessionId())
+	if err != nil {
+		return nil, err
+	}
+	if err := authorize(ctx, s); err != nil {
+		return nil, err
+	}
+	return s.proto(), nil
+}
+
+func (a *Agent) ListTestSets(ctx context.Context, _ *agentpb.ListTestSetsRequest) (*agentpb.ListTestSetsResponse, error) {
+	replayer, err := a.replayer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := replayer.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the test sets")
+		return nil, status.Error(codes.Internal, "failed to get the test sets")
+	}
+	return &agentpb.ListTestSetsResponse{TestSets: ids}, nil
+}
+
+func (a *Agent) GetTestSetStatus(ctx context.Context, req *agentpb.GetTestSetStatusRequest) (*agentpb.GetTestSetStatusResponse, error) {
+	replayer, err := a.replayer(ctx)
+	if err != nil {
+		return nil, err
+	}
+	testSetStatus, err := replayer.GetTestSetStatus(ctx, req.GetTestRunId(), req.GetTestSetId())
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the test set status")
+		return nil, status.Error(codes.NotFound, "failed to get the test set status")
+	}
+	return &agentpb.GetTestSetStatusResponse{Status: string(testSetStatus)}, nil
+}
+
+// replayer returns a replay service to read the test sets and the reports of the agent with, which the clients
+// over the unix socket only read as root.
+func (a *Agent) replayer(ctx context.Context) (replay.Service, error) {
+	if user, onSocket := socketPeer(ctx); onSocket && user.uid != 0 {
+		return nil, status.Error(codes.PermissionDenied, "the test sets of the agent are only read by root over the agent socket")
+	}
+	svc, err := a.services.GetService(ctx, "test")
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get service")
+		return nil, status.Error(codes.Internal, "failed to get the replay service")
+	}
+	replayer, ok := svc.(replay.Service)
+	if !ok {
+		return nil, status.Error(codes.Internal, "service doesn't satisfy replay service interface")
+	}
+	return replayer, nil
+}
@@ -0,0 +1,801 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: agent.proto
+
+// The control plane of a keploy agent, to record and test the app of a remote machine from an orchestrator.
+
+package agentpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type SessionKind int32
+
+const (
+	SessionKind_SESSION_KIND_UNSPECIFIED SessionKind = 0
+	SessionKind_SESSION_KIND_RECORD      SessionKind = 1
+	SessionKind_SESSION_KIND_TEST        SessionKind = 2
+)
+
+// Enum value maps for SessionKind.
+var (
+	SessionKind_name = map[int32]string{
+		0: "SESSION_KIND_UNSPECIFIED",
+		1: "SESSION_KIND_RECORD",
+		2: "SESSION_KIND_TEST",
+	}
+	SessionKind_value = map[string]int32{
+		"SESSION_KIND_UNSPECIFIED": 0,
+		"SESSION_KIND_RECORD":      1,
+		"SESSION_KIND_TEST":        2,
+	}
+)
+
+func (x SessionKind) Enum() *SessionKind {
+	p := new(SessionKind)
+	*p = x
+	return p
+}
+
+func (x SessionKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_agent_proto_enumTypes[0].Descriptor()
+}
+
+func (SessionKind) Type() protoreflect.EnumType {
+	return &file_agent_proto_enumTypes[0]
+}
+
+func (x SessionKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionKind.Descriptor instead.
+func (SessionKind) EnumDescriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{0}
+}
+
+type SessionState int32
+
+const (
+	SessionState_SESSION_STATE_UNSPECIFIED SessionState = 0
+	SessionState_SESSION_STATE_RUNNING     SessionState = 1
+	SessionState_SESSION_STATE_COMPLETED   SessionState = 2
+	SessionState_SESSION_STATE_FAILED      SessionState = 3
+	SessionState_SESSION_STATE_STOPPED     SessionState = 4
+)
+
+// Enum value maps for SessionState.
+var (
+	SessionState_name = map[int32]string{
+		0: "SESSION_STATE_UNSPECIFIED",
+		1: "SESSION_STATE_RUNNING",
+		2: "SESSION_STATE_COMPLETED",
+		3: "SESSION_STATE_FAILED",
+		4: "SESSION_STATE_STOPPED",
+	}
+	SessionState_value = map[string]int32{
+		"SESSION_STATE_UNSPECIFIED": 0,
+		"SESSION_STATE_RUNNING":     1,
+		"SESSION_STATE_COMPLETED":   2,
+		"SESSION_STATE_FAILED":      3,
+		"SESSION_STATE_STOPPED":     4,
+	}
+)
+
+func (x SessionState) Enum() *SessionState {
+	p := new(SessionState)
+	*p = x
+	return p
+}
+
+func (x SessionState) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionState) Descriptor() protoreflect.EnumDescriptor {
+	return file_agent_proto_enumTypes[1].Descriptor()
+}
+
+func (SessionState) Type() protoreflect.EnumType {
+	return &file_agent_proto_enumTypes[1]
+}
+
+func (x SessionState) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionState.Descriptor instead.
+func (SessionState) EnumDescriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{1}
+}
+
+// Session is a recording or a test run of the agent, the agent runs one of them at a time.
+type Session struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind  SessionKind            `protobuf:"varint,2,opt,name=kind,proto3,enum=keploy.agent.v1.SessionKind" json:"kind,omitempty"`
+	State SessionState           `protobuf:"varint,3,opt,name=state,proto3,enum=keploy.agent.v1.SessionState" json:"state,omitempty"`
+	// error is the reason of the failure of the session
+	Error     string `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`
+	Started   int64  `protobuf:"varint,5,opt,name=started,proto3" json:"started,omitempty"`
+	Completed int64  `protobuf:"varint,6,opt,name=completed,proto3" json:"completed,omitempty"`
+	// test_run_id is the test run of a test session, once the app is hooked
+	TestRunId string `protobuf:"bytes,7,opt,name=test_run_id,json=testRunId,proto3" json:"test_run_id,omitempty"`
+	// test_set_statuses are the statuses of the test sets run so far in a test session
+	TestSetStatuses map[string]string `protobuf:"bytes,8,rep,name=test_set_statuses,json=testSetStatuses,proto3" json:"test_set_statuses,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Session) Reset() {
+	*x = Session{}
+	mi := &file_agent_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Session) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Session) ProtoMessage() {}
+
+func (x *Session) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Session.ProtoReflect.Descriptor instead.
+func (*Session) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Session) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Session) GetKind() SessionKind {
+	if x != nil {
+		return x.Kind
+	}
+	return SessionKind_SESSION_KIND_UNSPECIFIED
+}
+
+func (x *Session) GetState() SessionState {
+	if x != nil {
+		return x.State
+	}
+	return SessionState_SESSION_STATE_UNSPECIFIED
+}
+
+func (x *Session) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Session) GetStarted() int64 {
+	if x != nil {
+		return x.Started
+	}
+	return 0
+}
+
+func (x *Session) GetCompleted() int64 {
+	if x != nil {
+		return x.Completed
+	}
+	return 0
+}
+
+func (x *Session) GetTestRunId() string {
+	if x != nil {
+		return x.TestRunId
+	}
+	return ""
+}
+
+func (x *Session) GetTestSetStatuses() map[string]string {
+	if x != nil {
+		return x.TestSetStatuses
+	}
+	return nil
+}
+
+// Client is keploy record or keploy test run with --daemon by a user of the machine of the agent, the session is
+// run with its config and the app is run as the user. It is only accepted over the unix socket of the agent.
+type Client struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// config is the JSON encoded config of the client
+	Config []byte `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	// dir is the working directory of the client
+	Dir string `protobuf:"bytes,2,opt,name=dir,proto3" json:"dir,omitempty"`
+	// env is the environment of the client
+	Env           []string `protobuf:"bytes,3,rep,name=env,proto3" json:"env,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Client) Reset() {
+	*x = Client{}
+	mi := &file_agent_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Client) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Client) ProtoMessage() {}
+
+func (x *Client) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Client.ProtoReflect.Descriptor instead.
+func (*Client) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Client) GetConfig() []byte {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *Client) GetDir() string {
+	if x != nil {
+		return x.Dir
+	}
+	return ""
+}
+
+func (x *Client) GetEnv() []string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+type StartRecordingRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// client runs the recording for the client instead of with the config of the agent
+	Client        *Client `protobuf:"bytes,1,opt,name=client,proto3" json:"client,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartRecordingRequest) Reset() {
+	*x = StartRecordingRequest{}
+	mi := &file_agent_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRecordingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRecordingRequest) ProtoMessage() {}
+
+func (x *StartRecordingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRecordingRequest.ProtoReflect.Descriptor instead.
+func (*StartRecordingRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StartRecordingRequest) GetClient() *Client {
+	if x != nil {
+		return x.Client
+	}
+	return nil
+}
+
+type StartTestRunRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// test_sets to run, all the test sets if empty
+	TestSets []string `protobuf:"bytes,1,rep,name=test_sets,json=testSets,proto3" json:"test_sets,omitempty"`
+	// client runs the test run for the client instead of with the config of the agent
+	Client        *Client `protobuf:"bytes,2,opt,name=client,proto3" json:"client,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartTestRunRequest) Reset() {
+	*x = StartTestRunRequest{}
+	mi := &file_agent_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartTestRunRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartTestRunRequest) ProtoMessage() {}
+
+func (x *StartTestRunRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartTestRunRequest.ProtoReflect.Descriptor instead.
+func (*StartTestRunRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StartTestRunRequest) GetTestSets() []string {
+	if x != nil {
+		return x.TestSets
+	}
+	return nil
+}
+
+func (x *StartTestRunRequest) GetClient() *Client {
+	if x != nil {
+		return x.Client
+	}
+	return nil
+}
+
+type StopRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRequest) Reset() {
+	*x = StopRequest{}
+	mi := &file_agent_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRequest) ProtoMessage() {}
+
+func (x *StopRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRequest.ProtoReflect.Descriptor instead.
+func (*StopRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StopRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type GetSessionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetSessionRequest) Reset() {
+	*x = GetSessionRequest{}
+	mi := &file_agent_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetSessionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetSessionRequest) ProtoMessage() {}
+
+func (x *GetSessionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetSessionRequest.ProtoReflect.Descriptor instead.
+func (*GetSessionRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetSessionRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type ListTestSetsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTestSetsRequest) Reset() {
+	*x = ListTestSetsRequest{}
+	mi := &file_agent_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTestSetsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTestSetsRequest) ProtoMessage() {}
+
+func (x *ListTestSetsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTestSetsRequest.ProtoReflect.Descriptor instead.
+func (*ListTestSetsRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{6}
+}
+
+type ListTestSetsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TestSets      []string               `protobuf:"bytes,1,rep,name=test_sets,json=testSets,proto3" json:"test_sets,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTestSetsResponse) Reset() {
+	*x = ListTestSetsResponse{}
+	mi := &file_agent_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTestSetsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTestSetsResponse) ProtoMessage() {}
+
+func (x *ListTestSetsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTestSetsResponse.ProtoReflect.Descriptor instead.
+func (*ListTestSetsResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListTestSetsResponse) GetTestSets() []string {
+	if x != nil {
+		return x.TestSets
+	}
+	return nil
+}
+
+type GetTestSetStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TestRunId     string                 `protobuf:"bytes,1,opt,name=test_run_id,json=testRunId,proto3" json:"test_run_id,omitempty"`
+	TestSetId     string                 `protobuf:"bytes,2,opt,name=test_set_id,json=testSetId,proto3" json:"test_set_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTestSetStatusRequest) Reset() {
+	*x = GetTestSetStatusRequest{}
+	mi := &file_agent_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTestSetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTestSetStatusRequest) ProtoMessage() {}
+
+func (x *GetTestSetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTestSetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetTestSetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetTestSetStatusRequest) GetTestRunId() string {
+	if x != nil {
+		return x.TestRunId
+	}
+	return ""
+}
+
+func (x *GetTestSetStatusRequest) GetTestSetId() string {
+	if x != nil {
+		return x.TestSetId
+	}
+	return ""
+}
+
+type GetTestSetStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTestSetStatusResponse) Reset() {
+	*x = GetTestSetStatusResponse{}
+	mi := &file_agent_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTestSetStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTestSetStatusResponse) ProtoMessage() {}
+
+func (x *GetTestSetStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agent_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTestSetStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetTestSetStatusResponse) Descriptor() ([]byte, []int) {
+	return file_agent_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetTestSetStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+var File_agent_proto protoreflect.FileDescriptor
+
+const file_agent_proto_rawDesc = "" +
+	"\n" +
+	"\vagent.proto\x12\x0fkeploy.agent.v1\"\x8d\x03\n" +
+	"\aSession\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x120\n" +
+	"\x04kind\x18\x02 \x01(\x0e2\x1c.keploy.agent.v1.SessionKindR\x04kind\x123\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x1d.keploy.agent.v1.SessionStateR\x05state\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x18\n" +
+	"\astarted\x18\x05 \x01(\x03R\astarted\x12\x1c\n" +
+	"\tcompleted\x18\x06 \x01(\x03R\tcompleted\x12\x1e\n" +
+	"\vtest_run_id\x18\a \x01(\tR\ttestRunId\x12Y\n" +
+	"\x11test_set_statuses\x18\b \x03(\v2-.keploy.agent.v1.Session.TestSetStatusesEntryR\x0ftestSetStatuses\x1aB\n" +
+	"\x14TestSetStatusesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"D\n" +
+	"\x06Client\x12\x16\n" +
+	"\x06config\x18\x01 \x01(\fR\x06config\x12\x10\n" +
+	"\x03dir\x18\x02 \x01(\tR\x03dir\x12\x10\n" +
+	"\x03env\x18\x03 \x03(\tR\x03env\"H\n" +
+	"\x15StartRecordingRequest\x12/\n" +
+	"\x06client\x18\x01 \x01(\v2\x17.keploy.agent.v1.ClientR\x06client\"c\n" +
+	"\x13StartTestRunRequest\x12\x1b\n" +
+	"\ttest_sets\x18\x01 \x03(\tR\btestSets\x12/\n" +
+	"\x06client\x18\x02 \x01(\v2\x17.keploy.agent.v1.ClientR\x06client\",\n" +
+	"\vStopRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"2\n" +
+	"\x11GetSessionRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"\x15\n" +
+	"\x13ListTestSetsRequest\"3\n" +
+	"\x14ListTestSetsResponse\x12\x1b\n" +
+	"\ttest_sets\x18\x01 \x03(\tR\btestSets\"Y\n" +
+	"\x17GetTestSetStatusRequest\x12\x1e\n" +
+	"\vtest_run_id\x18\x01 \x01(\tR\ttestRunId\x12\x1e\n" +
+	"\vtest_set_id\x18\x02 \x01(\tR\ttestSetId\"2\n" +
+	"\x18GetTestSetStatusResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status*[\n" +
+	"\vSessionKind\x12\x1c\n" +
+	"\x18SESSION_KIND_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13SESSION_KIND_RECORD\x10\x01\x12\x15\n" +
+	"\x11SESSION_KIND_TEST\x10\x02*\x9a\x01\n" +
+	"\fSessionState\x12\x1d\n" +
+	"\x19SESSION_STATE_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15SESSION_STATE_RUNNING\x10\x01\x12\x1b\n" +
+	"\x17SESSION_STATE_COMPLETED\x10\x02\x12\x18\n" +
+	"\x14SESSION_STATE_FAILED\x10\x03\x12\x19\n" +
+	"\x15SESSION_STATE_STOPPED\x10\x042\xcd\x04\n" +
+	"\x05Agent\x12R\n" +
+	"\x0eStartRecording\x12&.keploy.agent.v1.StartRecordingRequest\x1a\x18.keploy.agent.v1.Session\x12G\n" +
+	"\rStopRecording\x12\x1c.keploy.agent.v1.StopRequest\x1a\x18.keploy.agent.v1.Session\x12N\n" +
+	"\fStartTestRun\x12$.keploy.agent.v1.StartTestRunRequest\x1a\x18.keploy.agent.v1.Session\x12E\n" +
+	"\vStopTestRun\x12\x1c.keploy.agent.v1.StopRequest\x1a\x18.keploy.agent.v1.Session\x12J\n" +
+	"\n" +
+	"GetSession\x12\".keploy.agent.v1.GetSessionRequest\x1a\x18.keploy.agent.v1.Session\x12[\n" +
+	"\fListTestSets\x12$.keploy.agent.v1.ListTestSetsRequest\x1a%.keploy.agent.v1.ListTestSetsResponse\x12g\n" +
+	"\x10GetTestSetStatus\x12(.keploy.agent.v1.GetTestSetStatusRequest\x1a).keploy.agent.v1.GetTestSetStatusResponseB2Z0go.keploy.io/server/v2/pkg/service/agent/agentpbb\x06proto3"
+
+var (
+	file_agent_proto_rawDescOnce sync.Once
+	file_agent_proto_rawDescData []byte
+)
+
+func file_agent_proto_rawDescGZIP() []byte {
+	file_agent_proto_rawDescOnce.Do(func() {
+		file_agent_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)))
+	})
+	return file_agent_proto_rawDescData
+}
+
+var file_agent_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_agent_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_agent_proto_goTypes = []any{
+	(SessionKind)(0),                 // 0: keploy.agent.v1.SessionKind
+	(SessionState)(0),                // 1: keploy.agent.v1.SessionState
+	(*Session)(nil),                  // 2: keploy.agent.v1.Session
+	(*Client)(nil),                   // 3: keploy.agent.v1.Client
+	(*StartRecordingRequest)(nil),    // 4: keploy.agent.v1.StartRecordingRequest
+	(*StartTestRunRequest)(nil),      // 5: keploy.agent.v1.StartTestRunRequest
+	(*StopRequest)(nil),              // 6: keploy.agent.v1.StopRequest
+	(*GetSessionRequest)(nil),        // 7: keploy.agent.v1.GetSessionRequest
+	(*ListTestSetsRequest)(nil),      // 8: keploy.agent.v1.ListTestSetsRequest
+	(*ListTestSetsResponse)(nil),     // 9: keploy.agent.v1.ListTestSetsResponse
+	(*GetTestSetStatusRequest)(nil),  // 10: keploy.agent.v1.GetTestSetStatusRequest
+	(*GetTestSetStatusResponse)(nil), // 11: keploy.agent.v1.GetTestSetStatusResponse
+	nil,                              // 12: keploy.agent.v1.Session.TestSetStatusesEntry
+}
+var file_agent_proto_depIdxs = []int32{
+	0,  // 0: keploy.agent.v1.Session.kind:type_name -> keploy.agent.v1.SessionKind
+	1,  // 1: keploy.agent.v1.Session.state:type_name -> keploy.agent.v1.SessionState
+	12, // 2: keploy.agent.v1.Session.test_set_statuses:type_name -> keploy.agent.v1.Session.TestSetStatusesEntry
+	3,  // 3: keploy.agent.v1.StartRecordingRequest.client:type_name -> keploy.agent.v1.Client
+	3,  // 4: keploy.agent.v1.StartTestRunRequest.client:type_name -> keploy.agent.v1.Client
+	4,  // 5: keploy.agent.v1.Agent.StartRecording:input_type -> keploy.agent.v1.StartRecordingRequest
+	6,  // 6: keploy.agent.v1.Agent.StopRecording:input_type -> keploy.agent.v1.StopRequest
+	5,  // 7: keploy.agent.v1.Agent.StartTestRun:input_type -> keploy.agent.v1.StartTestRunRequest
+	6,  // 8: keploy.agent.v1.Agent.StopTestRun:input_type -> keploy.agent.v1.StopRequest
+	7,  // 9: keploy.agent.v1.Agent.GetSession:input_type -> keploy.agent.v1.GetSessionRequest
+	8,  // 10: keploy.agent.v1.Agent.ListTestSets:input_type -> keploy.agent.v1.ListTestSetsRequest
+	10, // 11: keploy.agent.v1.Agent.GetTestSetStatus:input_type -> keploy.agent.v1.GetTestSetStatusRequest
+	2,  // 12: keploy.agent.v1.Agent.StartRecording:output_type -> keploy.agent.v1.Session
+	2,  // 13: keploy.agent.v1.Agent.StopRecording:output_type -> keploy.agent.v1.Session
+	2,  // 14: keploy.agent.v1.Agent.StartTestRun:output_type -> keploy.agent.v1.Session
+	2,  // 15: keploy.agent.v1.Agent.StopTestRun:output_type -> keploy.agent.v1.Session
+	2,  // 16: keploy.agent.v1.Agent.GetSession:output_type -> keploy.agent.v1.Session
+	9,  // 17: keploy.agent.v1.Agent.ListTestSets:output_type -> keploy.agent.v1.ListTestSetsResponse
+	11, // 18: keploy.agent.v1.Agent.GetTestSetStatus:output_type -> keploy.agent.v1.GetTestSetStatusResponse
+	12, // [12:19] is the sub-list for method output_type
+	5,  // [5:12] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_agent_proto_init() }
+func file_agent_proto_init() {
+	if File_agent_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agent_proto_rawDesc), len(file_agent_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agent_proto_goTypes,
+		DependencyIndexes: file_agent_proto_depIdxs,
+		EnumInfos:         file_agent_proto_enumTypes,
+		MessageInfos:      file_agent_proto_msgTypes,
+	}.Build()
+	File_agent_proto = out.File
+	file_agent_proto_goTypes = nil
+	file_agent_proto_depIdxs = nil
+}
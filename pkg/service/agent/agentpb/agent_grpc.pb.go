@@ -0,0 +1,361 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: agent.proto
+
+// The control plane of a keploy agent, to record and test the app of a remote machine from an orchestrator.
+
+package agentpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Agent_StartRecording_FullMethodName   = "/keploy.agent.v1.Agent/StartRecording"
+	Agent_StopRecording_FullMethodName    = "/keploy.agent.v1.Agent/StopRecording"
+	Agent_StartTestRun_FullMethodName     = "/keploy.agent.v1.Agent/StartTestRun"
+	Agent_StopTestRun_FullMethodName      = "/keploy.agent.v1.Agent/StopTestRun"
+	Agent_GetSession_FullMethodName       = "/keploy.agent.v1.Agent/GetSession"
+	Agent_ListTestSets_FullMethodName     = "/keploy.agent.v1.Agent/ListTestSets"
+	Agent_GetTestSetStatus_FullMethodName = "/keploy.agent.v1.Agent/GetTestSetStatus"
+)
+
+// AgentClient is the client API for Agent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AgentClient interface {
+	// StartRecording records the testcases and mocks of the app into a new test set, until StopRecording.
+	StartRecording(ctx context.Context, in *StartRecordingRequest, opts ...grpc.CallOption) (*Session, error)
+	// StopRecording stops the recording and waits for the testcases and mocks to be written.
+	StopRecording(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*Session, error)
+	// StartTestRun runs the test sets against the app in a new test run.
+	StartTestRun(ctx context.Context, in *StartTestRunRequest, opts ...grpc.CallOption) (*Session, error)
+	// StopTestRun aborts the test run.
+	StopTestRun(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*Session, error)
+	// GetSession returns the state of the recording or the test run.
+	GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error)
+	ListTestSets(ctx context.Context, in *ListTestSetsRequest, opts ...grpc.CallOption) (*ListTestSetsResponse, error)
+	GetTestSetStatus(ctx context.Context, in *GetTestSetStatusRequest, opts ...grpc.CallOption) (*GetTestSetStatusResponse, error)
+}
+
+type agentClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAgentClient(cc grpc.ClientConnInterface) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) StartRecording(ctx context.Context, in *StartRecordingRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Agent_StartRecording_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) StopRecording(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Agent_StopRecording_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) StartTestRun(ctx context.Context, in *StartTestRunRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Agent_StartTestRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) StopTestRun(ctx context.Context, in *StopRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Agent_StopTestRun_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) GetSession(ctx context.Context, in *GetSessionRequest, opts ...grpc.CallOption) (*Session, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Session)
+	err := c.cc.Invoke(ctx, Agent_GetSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) ListTestSets(ctx context.Context, in *ListTestSetsRequest, opts ...grpc.CallOption) (*ListTestSetsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTestSetsResponse)
+	err := c.cc.Invoke(ctx, Agent_ListTestSets_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) GetTestSetStatus(ctx context.Context, in *GetTestSetStatusRequest, opts ...grpc.CallOption) (*GetTestSetStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetTestSetStatusResponse)
+	err := c.cc.Invoke(ctx, Agent_GetTestSetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentServer is the server API for Agent service.
+// All implementations must embed UnimplementedAgentServer
+// for forward compatibility.
+type AgentServer interface {
+	// StartRecording records the testcases and mocks of the app into a new test set, until StopRecording.
+	StartRecording(context.Context, *StartRecordingRequest) (*Session, error)
+	// StopRecording stops the recording and waits for the testcases and mocks to be written.
+	StopRecording(context.Context, *StopRequest) (*Session, error)
+	// StartTestRun runs the test sets against the app in a new test run.
+	StartTestRun(context.Context, *StartTestRunRequest) (*Session, error)
+	// StopTestRun aborts the test run.
+	StopTestRun(context.Context, *StopRequest) (*Session, error)
+	// GetSession returns the state of the recording or the test run.
+	GetSession(context.Context, *GetSessionRequest) (*Session, error)
+	ListTestSets(context.Context, *ListTestSetsRequest) (*ListTestSetsResponse, error)
+	GetTestSetStatus(context.Context, *GetTestSetStatusRequest) (*GetTestSetStatusResponse, error)
+	mustEmbedUnimplementedAgentServer()
+}
+
+// UnimplementedAgentServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAgentServer struct{}
+
+func (UnimplementedAgentServer) StartRecording(context.Context, *StartRecordingRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartRecording not implemented")
+}
+func (UnimplementedAgentServer) StopRecording(context.Context, *StopRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopRecording not implemented")
+}
+func (UnimplementedAgentServer) StartTestRun(context.Context, *StartTestRunRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartTestRun not implemented")
+}
+func (UnimplementedAgentServer) StopTestRun(context.Context, *StopRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StopTestRun not implemented")
+}
+func (UnimplementedAgentServer) GetSession(context.Context, *GetSessionRequest) (*Session, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSession not implemented")
+}
+func (UnimplementedAgentServer) ListTestSets(context.Context, *ListTestSetsRequest) (*ListTestSetsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTestSets not implemented")
+}
+func (UnimplementedAgentServer) GetTestSetStatus(context.Context, *GetTestSetStatusRequest) (*GetTestSetStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTestSetStatus not implemented")
+}
+func (UnimplementedAgentServer) mustEmbedUnimplementedAgentServer() {}
+func (UnimplementedAgentServer) testEmbeddedByValue()               {}
+
+// UnsafeAgentServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AgentServer will
+// result in compilation errors.
+type UnsafeAgentServer interface {
+	mustEmbedUnimplementedAgentServer()
+}
+
+func RegisterAgentServer(s grpc.ServiceRegistrar, srv AgentServer) {
+	// If the following call pancis, it indicates UnimplementedAgentServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Agent_ServiceDesc, srv)
+}
+
+func _Agent_StartRecording_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartRecordingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).StartRecording(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_StartRecording_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).StartRecording(ctx, req.(*StartRecordingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_StopRecording_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).StopRecording(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_StopRecording_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).StopRecording(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_StartTestRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartTestRunRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).StartTestRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_StartTestRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).StartTestRun(ctx, req.(*StartTestRunRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_StopTestRun_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StopRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).StopTestRun(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_StopTestRun_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).StopTestRun(ctx, req.(*StopRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_GetSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSessionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_GetSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetSession(ctx, req.(*GetSessionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_ListTestSets_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTestSetsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).ListTestSets(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_ListTestSets_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).ListTestSets(ctx, req.(*ListTestSetsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_GetTestSetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTestSetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetTestSetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Agent_GetTestSetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetTestSetStatus(ctx, req.(*GetTestSetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Agent_ServiceDesc is the grpc.ServiceDesc for Agent service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Agent_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "keploy.agent.v1.Agent",
+	HandlerType: (*AgentServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartRecording",
+			Handler:    _Agent_StartRecording_Handler,
+		},
+		{
+			MethodName: "StopRecording",
+			Handler:    _Agent_StopRecording_Handler,
+		},
+		{
+			MethodName: "StartTestRun",
+			Handler:    _Agent_StartTestRun_Handler,
+		},
+		{
+			MethodName: "StopTestRun",
+			Handler:    _Agent_StopTestRun_Handler,
+		},
+		{
+			MethodName: "GetSession",
+			Handler:    _Agent_GetSession_Handler,
+		},
+		{
+			MethodName: "ListTestSets",
+			Handler:    _Agent_ListTestSets_Handler,
+		},
+		{
+			MethodName: "GetTestSetStatus",
+			Handler:    _Agent_GetTestSetStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "agent.proto",
+}
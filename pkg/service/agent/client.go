@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/service/agent/agentpb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// RunClient runs keploy record or keploy test with the config as a client of keploy agent over its unix socket,
+// until the session ends or the context is cancelled, which stops the session.
+func RunClient(ctx context.Context, logger *zap.Logger, cmd string, cfg config.Config) error {
+	conn, err := grpc.NewClient("unix://"+cfg.Agent.Socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close the connection to keploy agent")
+		}
+	}()
+	agent := agentpb.NewAgentClient(conn)
+	token := agentToken(cfg.Agent)
+	withToken := func(ctx context.Context) context.Context {
+		if token == "" {
+			return ctx
+		}
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+
+	encoded, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	client := &agentpb.Client{Config: encoded, Dir: dir, Env: os.Environ()}
+
+	var s *agentpb.Session
+	switch cmd {
+	case "record":
+		s, err = agent.StartRecording(withToken(ctx), &agentpb.StartRecordingRequest{Client: client})
+	case "test":
+		s, err = agent.StartTestRun(withToken(ctx), &agentpb.StartTestRunRequest{TestSets: utils.Keys(cfg.Test.SelectedTests), Client: client})
+	default:
+		return fmt.Errorf("%s can't be run through keploy agent", cmd)
+	}
+	if status.Code(err) == codes.Unavailable {
+		return fmt.Errorf("keploy agent is not listening on %s, start it with sudo keploy agent: %w", cfg.Agent.Socket, err)
+	}
+	if err != nil {
+		return err
+	}
+	logger.Info("started the session on keploy agent", zap.String("session", s.GetId()))
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	reported := map[string]bool{}
+	for s.GetState() == agentpb.SessionState_SESSION_STATE_RUNNING {
+		select {
+		case <-ctx.Done():
+			// the session is stopped with a new context, waiting for the agent to write the testcases or the reports
+			stopCtx := withToken(context.Background())
+			stop := &agentpb.StopRequest{SessionId: s.GetId()}
+			if cmd == "record" {
+				s, err = agent.StopRecording(stopCtx, stop)
+			} else {
+				s, err = agent.StopTestRun(stopCtx, stop)
+			}
+		case <-ticker.C:
+			s, err = agent.GetSession(withToken(ctx), &agentpb.GetSessionRequest{SessionId: s.GetId()})
+		}
+		if err != nil {
+			return err
+		}
+		for testSetID, testSetStatus := range s.GetTestSetStatuses() {
+			if !reported[testSetID] {
+				reported[testSetID] = true
+				logger.Info("test set completed", zap.String("testSet", testSetID), zap.String("status", testSetStatus))
+			}
+		}
+	}
+
+	logger.Info("the session on keploy agent ended", zap.String("session", s.GetId()), zap.String("state", s.GetState().String()), zap.String("testRunID", s.GetTestRunId()))
+	if s.GetState() == agentpb.SessionState_SESSION_STATE_FAILED {
+		return fmt.Errorf("the session failed: %s", s.GetError())
+	}
+	return nil
+}
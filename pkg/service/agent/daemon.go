@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/service/agent/agentpb"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// listenUnix listens on the unix socket of the clients, replacing the socket left behind by a previous agent. The
+// socket is writable by every user, the sessions of the clients are run as their users.
+func listenUnix(socket string) (net.Listener, error) {
+	if conn, err := net.Dial("unix", socket); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("another keploy agent is listening on %s", socket)
+	}
+	if err := os.Remove(socket); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(socket, 0o666); err != nil {
+		_ = lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
+
+// peerCredentials are the transport credentials of the unix socket, they read the user of the client from the
+// socket in place of a handshake.
+type peerCredentials struct{}
+
+// peerInfo is the user of a client connected over the unix socket.
+type peerInfo struct {
+	credentials.CommonAuthInfo
+	uid uint32
+	gid uint32
+}
+
+func (peerInfo) AuthType() string {
+	return "peercred"
+}
+
+func (peerCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return nil, nil, errors.New("the peer credentials are only available over a unix socket")
+	}
+	uid, gid, err := peerCred(unixConn)
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, peerInfo{
+		CommonAuthInfo: credentials.CommonAuthInfo{SecurityLevel: credentials.NoSecurity},
+		uid:            uid,
+		gid:            gid,
+	}, nil
+}
+
+func (peerCredentials) ClientHandshake(context.Context, string, net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("the peer credentials are only used by the agent")
+}
+
+func (peerCredentials) Info() credentials.ProtocolInfo {
+	return credentials.ProtocolInfo{SecurityProtocol: "peercred"}
+}
+
+func (peerCredentials) Clone() credentials.TransportCredentials {
+	return peerCredentials{}
+}
+
+func (peerCredentials) OverrideServerName(string) error {
+	return nil
+}
+
+// clientConfig returns the config to run the session of the client with, or nil to run it with the config of the
+// agent. Only the fields of the client config describing its app and its testcases and mocks are taken, the rest
+// is the config of the agent, e.g. the paths it would read or the commands it would run as root. The app of the
+// client is run as its user.
+func (a *Agent) clientConfig(ctx context.Context, client *agentpb.Client) (*config.Config, error) {
+	user, onSocket := socketPeer(ctx)
+	if client == nil {
+		if onSocket && user.uid != 0 {
+			return nil, status.Error(codes.PermissionDenied, "the sessions over the agent socket are run for a client")
+		}
+		return nil, nil
+	}
+	if !onSocket {
+		return nil, status.Error(codes.PermissionDenied, "the sessions of the clients are only run over the agent socket")
+	}
+
+	req := &config.Config{}
+	if err := json.Unmarshal(client.GetConfig(), req); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid config of the client: %v", err)
+	}
+	if req.Command == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing the command of the app of the client")
+	}
+	// the agent runs as root, the commands of the clients are only run as their users
+	if req.Encryption.KeyCommand != "" {
+		return nil, status.Error(codes.InvalidArgument, "encryption.keyCommand is not supported with --daemon")
+	}
+	if req.Hooks != (config.Hooks{}) {
+		return nil, status.Error(codes.InvalidArgument, "the hooks are not supported with --daemon, they would run as the user of keploy agent")
+	}
+	if !filepath.IsAbs(req.Path) || !filepath.IsAbs(client.GetDir()) {
+		return nil, status.Error(codes.InvalidArgument, "the keploy path and the directory of the client must be absolute")
+	}
+	switch req.Storage.Driver {
+	case "", "yaml":
+	case "bundle":
+		if !filepath.IsAbs(req.Bundle.File) {
+			return nil, status.Error(codes.InvalidArgument, "the bundle of the client must be absolute")
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "the %s storage is not supported with --daemon", req.Storage.Driver)
+	}
+
+	cfg := a.config
+	cfg.Daemon = false
+	cfg.Path = req.Path
+	cfg.Storage = config.Storage{Driver: req.Storage.Driver}
+	cfg.Bundle = config.Bundle{File: req.Bundle.File, TestSets: req.Bundle.TestSets}
+	cfg.ReRecord = req.ReRecord
+	cfg.Command = req.Command
+	cfg.CommandType = req.CommandType
+	cfg.ContainerName = req.ContainerName
+	cfg.NetworkName = req.NetworkName
+	cfg.BuildDelay = req.BuildDelay
+	cfg.RandomSeed = req.RandomSeed
+	cfg.BypassRules = req.BypassRules
+	cfg.Record = clientRecord(cfg.Record, req.Record)
+	cfg.Test = clientTest(cfg.Test, req.Test)
+	cfg.Client = &config.Client{
+		UID: user.uid,
+		GID: user.gid,
+		Dir: client.GetDir(),
+		Env: client.GetEnv(),
+	}
+	a.logger.Info("running the session of a client", zap.Uint32("uid", user.uid), zap.String("dir", client.GetDir()))
+	return &cfg, nil
+}
+
+// clientRecord takes the fields of the record config of the client which only affect what is recorded.
+func clientRecord(cfg config.Record, req config.Record) config.Record {
+	cfg.Filters = req.Filters
+	cfg.RecordTimer = req.RecordTimer
+	cfg.MaxMockFileSize = req.MaxMockFileSize
+	cfg.MockFormat = req.MockFormat
+	cfg.SourceIPs = req.SourceIPs
+	cfg.CaptureUserAgent = req.CaptureUserAgent
+	cfg.SampleRate = req.SampleRate
+	cfg.MaxPerEndpoint = req.MaxPerEndpoint
+	cfg.Reservoir = req.Reservoir
+	cfg.Continuous = req.Continuous
+	cfg.Rotation = req.Rotation
+	cfg.MaxDiskSize = req.MaxDiskSize
+	cfg.Redact = req.Redact
+	cfg.Dependencies = req.Dependencies
+	return cfg
+}
+
+// clientTest takes the fields of the test config of the client which only affect how its testcases are run and
+// compared.
+func clientTest(cfg config.Test, req config.Test) config.Test {
+	cfg.SelectedTests = req.SelectedTests
+	cfg.GlobalNoise = req.GlobalNoise
+	cfg.Delay = req.Delay
+	cfg.ReadinessURL = req.ReadinessURL
+	cfg.ReadinessPort = req.ReadinessPort
+	cfg.ReadinessTimeout = req.ReadinessTimeout
+	cfg.APITimeout = req.APITimeout
+	cfg.IgnoreOrdering = req.IgnoreOrdering
+	cfg.HashAssets = req.HashAssets
+	cfg.ArrayMatching = req.ArrayMatching
+	cfg.MongoPassword = req.MongoPassword
+	cfg.Language = req.Language
+	cfg.RemoveUnusedMocks = req.RemoveUnusedMocks
+	cfg.FallBackOnMiss = req.FallBackOnMiss
+	cfg.StrictMocking = req.StrictMocking
+	cfg.Update = req.Update
+	cfg.GraphQLNoise = req.GraphQLNoise
+	cfg.SimulateLatency = req.SimulateLatency
+	cfg.Chaos = req.Chaos
+	cfg.FreezeTime = req.FreezeTime
+	cfg.CaptureAppLogs = req.CaptureAppLogs
+	cfg.Tags = req.Tags
+	cfg.StreamChunkSize = req.StreamChunkSize
+	cfg.Conditional = req.Conditional
+	cfg.OAuthTokens = req.OAuthTokens
+	cfg.StrictMockWindow = req.StrictMockWindow
+	cfg.MockWindowSlack = req.MockWindowSlack
+	return cfg
+}
+
+// socketPeer returns the user of the caller connected over the unix socket, false for the callers of the agent port.
+func socketPeer(ctx context.Context) (peerInfo, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return peerInfo{}, false
+	}
+	user, ok := p.AuthInfo.(peerInfo)
+	return user, ok
+}
+
+// authorize checks that the caller may read or stop the session. The clients over the unix socket only reach the
+// sessions run for them, unless they are root, while the callers of the agent port hold its token.
+func authorize(ctx context.Context, s *session) error {
+	user, onSocket := socketPeer(ctx)
+	if !onSocket || user.uid == 0 {
+		return nil
+	}
+	if s.client == nil || s.client.UID != user.uid {
+		return status.Errorf(codes.PermissionDenied, "session %s is not run for the user of the client", s.id)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package agent
+
+import (
+	"net"
+	"os/exec"
+	"syscall"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core/app"
+)
+
+// noFollow makes the agent fail on a symlink instead of following it when it opens the files of the sessions.
+const noFollow = syscall.O_NOFOLLOW
+
+// peerCred reads the user of the client connected over the unix socket.
+func peerCred(conn *net.UnixConn) (uint32, uint32, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, 0, err
+	}
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	if credErr != nil {
+		return 0, 0, credErr
+	}
+	return cred.Uid, cred.Gid, nil
+}
+
+// runAsClient runs the command as the user of the client, with its supplementary groups.
+func runAsClient(cmd *exec.Cmd, client *config.Client) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: app.ClientCredential(client)}
+}
@@ -0,0 +1,20 @@
+//go:build !linux
+
+package agent
+
+import (
+	"errors"
+	"net"
+	"os/exec"
+
+	"go.keploy.io/server/v2/config"
+)
+
+const noFollow = 0
+
+// peerCred isn't supported outside linux, keploy record and test are run with --daemon on linux only.
+func peerCred(_ *net.UnixConn) (uint32, uint32, error) {
+	return 0, 0, errors.New("the clients of keploy agent are only supported on linux")
+}
+
+func runAsClient(_ *exec.Cmd, _ *config.Client) {}
@@ -0,0 +1,22 @@
+// Package agent provides the gRPC control plane of keploy, so that a remote orchestrator can start and stop the
+// recordings and launch the test runs of the app of the machine keploy agent runs on. The agent also runs as the
+// privileged daemon of the machine, keploy record and keploy test run with --daemon are its unprivileged clients.
+package agent
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/config"
+)
+
+type Service interface {
+	// Serve serves the control plane until the context is cancelled.
+	Serve(ctx context.Context) error
+}
+
+// ServiceFactory creates the record and replay services of the sessions, a new one for each session.
+type ServiceFactory interface {
+	GetService(ctx context.Context, cmd string) (interface{}, error)
+	// GetServiceWithConfig creates the service of a session of a client with the config of the client.
+	GetServiceWithConfig(ctx context.Context, cmd string, cfg config.Config) (interface{}, error)
+}
@@ -0,0 +1,236 @@
+package agent
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+)
+
+// staging is the copy of the keploy directory of a client, only accessible by the agent, which the session of the
+// client is run on. The files are read from the client and written back to it by tar run as the user of the
+// client, so the agent never opens a path of the client itself, e.g. a symlink into the files of another user.
+type staging struct {
+	dir    string
+	path   string
+	client *config.Client
+	// bundle is the bundle of the client with the bundle driver, which is handed back instead of the keploy directory
+	bundle string
+	// copied are the files read from the client by their relative path, to write back only the new and changed
+	// files and to remove the deleted ones
+	copied map[string]fileStamp
+}
+
+type fileStamp struct {
+	size int64
+	mod  time.Time
+}
+
+// stage copies the keploy directory of the client, and its bundle with the bundle driver, into a new staging
+// directory and points the config of the session to them.
+func stage(ctx context.Context, cfg *config.Config) (*staging, error) {
+	dir, err := os.MkdirTemp("", "keploy-session-")
+	if err != nil {
+		return nil, err
+	}
+	s := &staging{dir: dir, path: cfg.Path, client: cfg.Client, copied: map[string]fileStamp{}}
+	keployDir := filepath.Join(dir, "keploy")
+	if err := s.copyIn(ctx, cfg.Path, ".", keployDir, s.copied); err != nil {
+		s.remove()
+		return nil, fmt.Errorf("failed to read the keploy directory of the client: %w", err)
+	}
+	cfg.Path = keployDir
+	if cfg.Storage.Driver == "bundle" {
+		bundleDir := filepath.Join(dir, "bundle")
+		if err := s.copyIn(ctx, filepath.Dir(cfg.Bundle.File), filepath.Base(cfg.Bundle.File), bundleDir, nil); err != nil {
+			s.remove()
+			return nil, fmt.Errorf("failed to read the bundle of the client: %w", err)
+		}
+		s.bundle = cfg.Bundle.File
+		cfg.Bundle.File = filepath.Join(bundleDir, filepath.Base(cfg.Bundle.File))
+	}
+	return s, nil
+}
+
+// copyIn reads the file or directory name of the directory src of the client into dst, nothing if it doesn't exist.
+func (s *staging) copyIn(ctx context.Context, src string, name string, dst string, copied map[string]fileStamp) error {
+	if err := os.Mkdir(dst, 0o700); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", `cd "$1" 2>/dev/null && [ -e "$2" ] || exit 0; exec tar -cf - -- "$2"`, "sh", src, name)
+	runAsClient(cmd, s.client)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	extractErr := extract(stdout, dst, copied)
+	// the rest of the archive is drained so that tar isn't blocked on a failed extraction
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return extractErr
+}
+
+// extract writes the directories and regular files of the archive into dst, the other entries are skipped. The
+// files are created exclusively without following symlinks, so a crafted archive can't write outside of dst.
+func extract(r io.Reader, dst string, copied map[string]fileStamp) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if name == "." {
+			continue
+		}
+		if !filepath.IsLocal(name) {
+			return fmt.Errorf("invalid path %s in the files of the client", hdr.Name)
+		}
+		target := filepath.Join(dst, name)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o700); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL|noFollow, hdr.FileInfo().Mode().Perm())
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(f, tr)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+			if err := os.Chtimes(target, hdr.ModTime, hdr.ModTime); err != nil {
+				return err
+			}
+			if copied != nil {
+				info, err := os.Lstat(target)
+				if err != nil {
+					return err
+				}
+				copied[filepath.ToSlash(name)] = fileStamp{size: info.Size(), mod: info.ModTime()}
+			}
+		}
+	}
+}
+
+// handBack writes the new and changed files of the keploy directory back to the client and removes the files the
+// session deleted, e.g. the shards of the rewritten mocks. With the bundle driver only the bundle, which the keploy
+// directory was packed into, is written back.
+func (s *staging) handBack(ctx context.Context) error {
+	if s.bundle != "" {
+		return s.writeBack(ctx, filepath.Dir(s.bundle), filepath.Join(s.dir, "bundle"), nil)
+	}
+	keployDir := filepath.Join(s.dir, "keploy")
+	var removed []string
+	for name := range s.copied {
+		if _, err := os.Lstat(filepath.Join(keployDir, filepath.FromSlash(name))); os.IsNotExist(err) {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		cmd := exec.CommandContext(ctx, "sh", append([]string{"-c", `cd "$1" && shift && exec rm -f -- "$@"`, "sh", s.path}, removed...)...)
+		runAsClient(cmd, s.client)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("failed to remove the deleted files: %w: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	return s.writeBack(ctx, s.path, keployDir, s.copied)
+}
+
+// writeBack writes the files of the staged directory, other than the ones copied in unchanged, into the directory
+// dst of the client.
+func (s *staging) writeBack(ctx context.Context, dst string, dir string, copied map[string]fileStamp) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", `mkdir -p -- "$1" && cd "$1" && exec tar -xf -`, "sh", dst)
+	runAsClient(cmd, s.client)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	packErr := s.pack(stdin, dir, copied)
+	_ = stdin.Close()
+	if err := cmd.Wait(); err != nil {
+		return fmt.Errorf("failed to write the files back to %s: %w: %s", dst, err, strings.TrimSpace(stderr.String()))
+	}
+	return packErr
+}
+
+// pack writes the regular files of the directory which weren't copied in unchanged into the archive.
+func (s *staging) pack(w io.Writer, dir string, copied map[string]fileStamp) error {
+	tw := tar.NewWriter(w)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if stamp, ok := copied[rel]; ok && stamp.size == info.Size() && stamp.mod.Equal(info.ModTime()) {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = rel
+		hdr.Uid, hdr.Gid = int(s.client.UID), int(s.client.GID)
+		hdr.Uname, hdr.Gname = "", ""
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+func (s *staging) remove() {
+	_ = os.RemoveAll(s.dir)
+}
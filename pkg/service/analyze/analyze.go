@@ -0,0 +1,294 @@
+package analyze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+type Analyzer struct {
+	logger   *zap.Logger
+	testDB   TestDB
+	mockDB   MockDB
+	reportDB ReportDB
+	config   config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, config config.Config) Service {
+	return &Analyzer{
+		logger:   logger,
+		testDB:   testDB,
+		mockDB:   mockDB,
+		reportDB: reportDB,
+		config:   config,
+	}
+}
+
+// usage is the consumption of a mock across the analyzed test runs.
+type usage struct {
+	mock *models.Mock
+	// consumed is the number of the testcase runs which consumed the mock, failed is the number of them which failed
+	consumed int
+	failed   int
+	runs     map[string]bool
+}
+
+// Analyze reports the consumption of the mocks of the test sets across the last test runs, highlights the mocks
+// which were never used or were only used by failing testcases, and writes the prune plan of the never used mocks
+// if a plan file is configured.
+func (a *Analyzer) Analyze(ctx context.Context) error {
+	testSetIDs := a.config.Analyze.TestSets
+	if len(testSetIDs) == 0 {
+		ids, err := a.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to get the test sets")
+			return err
+		}
+		testSetIDs = ids
+	}
+	testRunIDs, err := a.lastTestRuns(ctx)
+	if err != nil {
+		return err
+	}
+	if len(testRunIDs) == 0 {
+		return errors.New("found no test runs to analyze, run keploy test first")
+	}
+	a.logger.Info("analyzing the consumption of the mocks", zap.Strings("test-runs", testRunIDs))
+
+	plan := Plan{
+		CreatedAt: time.Now().UTC(),
+		TestRuns:  testRunIDs,
+	}
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		usages, runs, err := a.analyzeTestSet(ctx, testSetID, testRunIDs)
+		if err != nil {
+			return err
+		}
+		if runs == 0 {
+			a.logger.Warn("found no test runs of the test set with the consumed mocks, skipping it", zap.String("test-set", testSetID))
+			continue
+		}
+		a.printUsages(testSetID, runs, usages)
+
+		planTestSet := PlanTestSet{TestSet: testSetID}
+		for _, u := range usages {
+			if u.consumed == 0 {
+				planTestSet.Mocks = append(planTestSet.Mocks, PlanMock{
+					Name:   u.mock.Name,
+					Kind:   u.mock.Kind,
+					Reason: fmt.Sprintf("never used in the last %d test runs", runs),
+				})
+			}
+		}
+		if len(planTestSet.Mocks) > 0 {
+			plan.TestSets = append(plan.TestSets, planTestSet)
+		}
+	}
+
+	if a.config.Analyze.Plan == "" {
+		return nil
+	}
+	data, err := yamlLib.Marshal(&plan)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to marshal the prune plan")
+		return err
+	}
+	err = os.WriteFile(a.config.Analyze.Plan, data, 0644)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to write the prune plan", zap.String("file", a.config.Analyze.Plan))
+		return err
+	}
+	a.logger.Info("wrote the prune plan, review it and delete the mocks with keploy analyze --prune", zap.String("file", a.config.Analyze.Plan))
+	return nil
+}
+
+// analyzeTestSet returns the usages of the mocks of the test set, in the order of their names, and the number of
+// the test runs which recorded the consumed mocks of the test set.
+func (a *Analyzer) analyzeTestSet(ctx context.Context, testSetID string, testRunIDs []string) ([]*usage, int, error) {
+	mocks, err := a.mocks(ctx, testSetID)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+		return nil, 0, err
+	}
+	usages := make(map[string]*usage, len(mocks))
+	for _, mock := range mocks {
+		usages[mock.Name] = &usage{mock: mock, runs: map[string]bool{}}
+	}
+
+	runs := 0
+	for _, testRunID := range testRunIDs {
+		report, err := a.reportDB.GetReport(ctx, testRunID, testSetID)
+		if err != nil {
+			// the test set wasn't run in the test run
+			a.logger.Debug("no report of the test set in the test run", zap.String("test-set", testSetID), zap.String("test-run", testRunID), zap.Error(err))
+			continue
+		}
+		tracked := false
+		for _, result := range report.Tests {
+			// the results recorded before the consumed mocks were added have none
+			if result.ConsumedMocks == nil {
+				continue
+			}
+			tracked = true
+			for _, name := range result.ConsumedMocks {
+				u, ok := usages[name]
+				if !ok {
+					continue
+				}
+				u.consumed++
+				if result.Status == models.TestStatusFailed {
+					u.failed++
+				}
+				u.runs[testRunID] = true
+			}
+		}
+		if tracked {
+			runs++
+		}
+	}
+
+	sorted := make([]*usage, 0, len(usages))
+	for _, u := range usages {
+		sorted = append(sorted, u)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return nameIndex(sorted[i].mock.Name) < nameIndex(sorted[j].mock.Name)
+	})
+	return sorted, runs, nil
+}
+
+func (a *Analyzer) printUsages(testSetID string, runs int, usages []*usage) {
+	neverUsed, alwaysFailing := 0, 0
+	if _, err := pp.Printf("\n <=========================================> \n  MOCK USAGE OF %s ACROSS %d TEST RUNS\n\n\tMock\t\tKind\t\tConsumed\tFailed\tRuns\t\n", testSetID, runs); err != nil {
+		utils.LogError(a.logger, err, "failed to print the mock usage")
+		return
+	}
+	for _, u := range usages {
+		note := ""
+		switch {
+		case u.consumed == 0:
+			neverUsed++
+			note = "never used"
+			pp.SetColorScheme(models.FailingColorScheme)
+		case u.failed == u.consumed:
+			alwaysFailing++
+			note = "always failing"
+			pp.SetColorScheme(models.FailingColorScheme)
+		default:
+			pp.SetColorScheme(models.PassingColorScheme)
+		}
+		if _, err := pp.Printf("\n\t%s\t\t%s\t\t%s\t\t%s\t%s\t%s", u.mock.Name, string(u.mock.Kind), u.consumed, u.failed, len(u.runs), note); err != nil {
+			utils.LogError(a.logger, err, "failed to print the mock usage")
+			return
+		}
+	}
+	pp.ResetColorScheme()
+	if _, err := pp.Printf("\n\n\tTotal mocks: %s\n\tNever used: %s\n\tAlways failing: %s\n", len(usages), neverUsed, alwaysFailing); err != nil {
+		utils.LogError(a.logger, err, "failed to print the mock usage summary")
+	}
+}
+
+// Prune deletes the mocks listed in the prune plan file from their test sets.
+func (a *Analyzer) Prune(ctx context.Context, planFile string) error {
+	data, err := os.ReadFile(planFile)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to read the prune plan", zap.String("file", planFile))
+		return err
+	}
+	var plan Plan
+	err = yamlLib.Unmarshal(data, &plan)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to parse the prune plan", zap.String("file", planFile))
+		return err
+	}
+
+	for _, planTestSet := range plan.TestSets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		pruned := make(map[string]bool, len(planTestSet.Mocks))
+		for _, mock := range planTestSet.Mocks {
+			pruned[mock.Name] = true
+		}
+		mocks, err := a.mocks(ctx, planTestSet.TestSet)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to get the mocks", zap.String("test-set", planTestSet.TestSet))
+			return err
+		}
+		kept := make(map[string]bool, len(mocks))
+		for _, mock := range mocks {
+			if !pruned[mock.Name] {
+				kept[mock.Name] = true
+			}
+		}
+		if len(kept) == len(mocks) {
+			continue
+		}
+		err = a.mockDB.UpdateMocks(ctx, planTestSet.TestSet, kept)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to delete the pruned mocks", zap.String("test-set", planTestSet.TestSet))
+			return err
+		}
+		a.logger.Info("pruned the mocks of the test set", zap.String("test-set", planTestSet.TestSet), zap.Int("pruned", len(mocks)-len(kept)), zap.Int("kept", len(kept)))
+	}
+	return nil
+}
+
+// mocks returns all the mocks of the test set.
+func (a *Analyzer) mocks(ctx context.Context, testSetID string) ([]*models.Mock, error) {
+	filtered, err := a.mockDB.GetFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	unfiltered, err := a.mockDB.GetUnFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return append(filtered, unfiltered...), nil
+}
+
+// lastTestRuns returns the ids of the last configured number of test runs, the oldest first.
+func (a *Analyzer) lastTestRuns(ctx context.Context) ([]string, error) {
+	testRunIDs, err := a.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the test runs")
+		return nil, err
+	}
+	var ids []string
+	for _, id := range testRunIDs {
+		if strings.HasPrefix(id, models.TestRunTemplateName) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return nameIndex(ids[i]) < nameIndex(ids[j])
+	})
+	if runs := int(a.config.Analyze.Runs); runs > 0 && len(ids) > runs {
+		ids = ids[len(ids)-runs:]
+	}
+	return ids, nil
+}
+
+// nameIndex returns the number suffixed to the name of a mock or a test run e.g. 3 for mock-3 and test-run-3.
+func nameIndex(name string) int {
+	index, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return -1
+	}
+	return index
+}
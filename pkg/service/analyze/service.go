@@ -0,0 +1,49 @@
+// Package analyze provides the analysis of the consumption of the recorded mocks across the last test runs, and the
+// prune plan of the mocks which are never used.
+package analyze
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	Analyze(ctx context.Context) error
+	// Prune deletes the mocks listed in the reviewed prune plan file.
+	Prune(ctx context.Context, planFile string) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+}
+
+type MockDB interface {
+	GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+	GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+	UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+}
+
+// Plan is the prune plan written to a file, to be reviewed before the mocks are deleted with keploy analyze --prune.
+type Plan struct {
+	CreatedAt time.Time     `yaml:"createdAt"`
+	TestRuns  []string      `yaml:"testRuns"` // test runs the consumption of the mocks was analyzed across
+	TestSets  []PlanTestSet `yaml:"testSets"`
+}
+
+type PlanTestSet struct {
+	TestSet string     `yaml:"testSet"`
+	Mocks   []PlanMock `yaml:"mocks"`
+}
+
+type PlanMock struct {
+	Name   string      `yaml:"name"`
+	Kind   models.Kind `yaml:"kind"`
+	Reason string      `yaml:"reason"`
+}
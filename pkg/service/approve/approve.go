@@ -0,0 +1,215 @@
+package approve
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// changelogFile is the file in the directory of a test set the approvals are appended to.
+const changelogFile = "changelog"
+
+type Approver struct {
+	logger   *zap.Logger
+	testDB   TestDB
+	reportDB ReportDB
+	config   config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, reportDB ReportDB, config config.Config) Service {
+	return &Approver{
+		logger:   logger,
+		testDB:   testDB,
+		reportDB: reportDB,
+		config:   config,
+	}
+}
+
+// Approve rewrites the expected responses of the failed http testcases of the test set with their responses in
+// the report of the test run, and appends an entry listing the changes to the changelog of the test set.
+// The actual response is rebuilt from the result of the testcase, as the report keeps the expected one.
+func (a *Approver) Approve(ctx context.Context, testSetID string, testRunID string, testCases []string, message string) error {
+	if testRunID == "" {
+		var err error
+		testRunID, err = a.latestTestRun(ctx, testSetID)
+		if err != nil {
+			return err
+		}
+	}
+	report, err := a.reportDB.GetReport(ctx, testRunID, testSetID)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the report of the test set", zap.String("test-set", testSetID), zap.String("test-run", testRunID))
+		return err
+	}
+	tcs, err := a.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the testcases", zap.String("test-set", testSetID))
+		return err
+	}
+	byName := make(map[string]*models.TestCase, len(tcs))
+	for _, tc := range tcs {
+		byName[tc.Name] = tc
+	}
+	selected := make(map[string]bool, len(testCases))
+	for _, name := range testCases {
+		selected[name] = true
+	}
+
+	all := len(selected) == 0
+
+	entry := Entry{
+		ApprovedAt: time.Now().UTC(),
+		ApprovedBy: approver(),
+		TestRun:    testRunID,
+		Message:    message,
+	}
+	for _, result := range report.Tests {
+		if result.Status != models.TestStatusFailed || (!all && !selected[result.Name]) {
+			continue
+		}
+		delete(selected, result.Name)
+		tc, ok := byName[result.Name]
+		if !ok {
+			a.logger.Warn("the testcase of the result no longer exists, skipping it", zap.String("testcase", result.Name))
+			continue
+		}
+		if tc.Kind != models.HTTP {
+			a.logger.Warn("only the http testcases can be approved, rerun keploy test with --update for the others", zap.String("testcase", tc.Name), zap.String("kind", string(tc.Kind)))
+			continue
+		}
+		if len(result.Faults) > 0 {
+			a.logger.Warn("the testcase failed with injected faults, skipping it", zap.String("testcase", tc.Name))
+			continue
+		}
+		resp, changes := actualResponse(tc.HTTPResp, result.Result)
+		if len(changes) == 0 {
+			a.logger.Info("the response of the testcase didn't change, skipping it", zap.String("testcase", tc.Name))
+			continue
+		}
+		updated := *tc
+		updated.HTTPResp = resp
+		updated.Updated = time.Now().Unix()
+		err = a.testDB.UpdateTestCase(ctx, &updated, testSetID)
+		if err != nil {
+			utils.LogError(a.logger, err, "failed to update the testcase", zap.String("testcase", tc.Name))
+			return err
+		}
+		entry.TestCases = append(entry.TestCases, Change{Name: tc.Name, Changes: changes})
+	}
+	for name := range selected {
+		a.logger.Warn("the testcase didn't fail in the test run, skipping it", zap.String("testcase", name), zap.String("test-run", testRunID))
+	}
+	if len(entry.TestCases) == 0 {
+		return fmt.Errorf("found no failed testcases to approve in %s of %s", testSetID, testRunID)
+	}
+
+	data, err := yamlLib.Marshal(&entry)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to marshal the changelog entry")
+		return err
+	}
+	err = yaml.WriteFile(ctx, a.logger, filepath.Join(a.config.Path, testSetID), changelogFile, data, true)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to append to the changelog of the test set", zap.String("test-set", testSetID))
+		return err
+	}
+	a.logger.Info("approved the responses of the testcases as their expected responses", zap.String("test-set", testSetID), zap.String("test-run", testRunID), zap.Int("testcases", len(entry.TestCases)))
+	return nil
+}
+
+// latestTestRun returns the last test run with a report of the test set.
+func (a *Approver) latestTestRun(ctx context.Context, testSetID string) (string, error) {
+	testRunIDs, err := a.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(a.logger, err, "failed to get the test runs")
+		return "", err
+	}
+	latest, latestIndex := "", -1
+	for _, id := range testRunIDs {
+		if !strings.HasPrefix(id, models.TestRunTemplateName) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(id, models.TestRunTemplateName))
+		if err != nil || index <= latestIndex {
+			continue
+		}
+		if _, err := a.reportDB.GetReport(ctx, id, testSetID); err != nil {
+			continue
+		}
+		latest, latestIndex = id, index
+	}
+	if latest == "" {
+		return "", errors.New("found no test run of the test set, run keploy test first")
+	}
+	return latest, nil
+}
+
+// actualResponse returns the expected response with the parts which didn't match replaced by the actual ones,
+// and the changes made.
+func actualResponse(expected models.HTTPResp, result models.Result) (models.HTTPResp, []string) {
+	resp := expected
+	var changes []string
+	if !result.StatusCode.Normal && result.StatusCode.Actual != result.StatusCode.Expected {
+		resp.StatusCode = result.StatusCode.Actual
+		resp.StatusMessage = http.StatusText(result.StatusCode.Actual)
+		changes = append(changes, fmt.Sprintf("status code: %d → %d", result.StatusCode.Expected, result.StatusCode.Actual))
+	}
+	for _, body := range result.BodyResult {
+		if !body.Normal && body.Actual != body.Expected {
+			resp.Body = body.Actual
+			changes = append(changes, "body")
+		}
+	}
+	var headers map[string]string
+	for _, header := range result.HeadersResult {
+		if header.Normal {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string, len(expected.Header))
+			for k, v := range expected.Header {
+				headers[k] = v
+			}
+		}
+		key := header.Expected.Key
+		if key == "" {
+			key = header.Actual.Key
+		}
+		if header.Actual.Value == nil {
+			delete(headers, key)
+			changes = append(changes, "header "+key+" removed")
+			continue
+		}
+		headers[key] = strings.Join(header.Actual.Value, ", ")
+		changes = append(changes, "header "+key)
+	}
+	if headers != nil {
+		resp.Header = headers
+	}
+	return resp, changes
+}
+
+// approver returns the git user name of the repository, or the user of the machine.
+func approver() string {
+	if out, err := exec.Command("git", "config", "user.name").Output(); err == nil && strings.TrimSpace(string(out)) != "" {
+		return strings.TrimSpace(string(out))
+	}
+	if user := os.Getenv("USER"); user != "" {
+		return user
+	}
+	return "unknown"
+}
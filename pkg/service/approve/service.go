@@ -0,0 +1,41 @@
+// Package approve provides keploy approve, which promotes the responses of the failed testcases in a test run to
+// their expected responses, recording the approval in the changelog of the test set.
+package approve
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Approve promotes the actual responses of the failed testcases of the test set in the test run, the latest
+	// one if empty, to the expected responses. All the failed testcases are approved if none are given.
+	Approve(ctx context.Context, testSetID string, testRunID string, testCases []string, message string) error
+}
+
+type TestDB interface {
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	UpdateTestCase(ctx context.Context, testCase *models.TestCase, testSetID string) error
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+}
+
+// Entry is a document of the changelog of a test set, appended for each approval.
+type Entry struct {
+	ApprovedAt time.Time `yaml:"approvedAt"`
+	ApprovedBy string    `yaml:"approvedBy"`
+	TestRun    string    `yaml:"testRun"`
+	Message    string    `yaml:"message,omitempty"`
+	TestCases  []Change  `yaml:"testCases"`
+}
+
+// Change is the change of the expected response of an approved testcase.
+type Change struct {
+	Name    string   `yaml:"name"`
+	Changes []string `yaml:"changes"` // the changed parts of the response e.g. "status code: 200 → 201"
+}
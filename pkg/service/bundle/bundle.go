@@ -0,0 +1,150 @@
+package bundle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/platform/bundle"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// reports is the directory of the test reports in the keploy directory.
+const reports = "reports"
+
+type Bundle struct {
+	logger *zap.Logger
+	config config.Config
+}
+
+func New(logger *zap.Logger, config config.Config) Service {
+	return &Bundle{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Pack packs the selected test sets of the keploy directory into the bundle file. With the bundle storage driver the
+// keploy directory only holds the unpacked bundle, so it is removed once packed.
+func (b *Bundle) Pack(ctx context.Context) error {
+	index, err := bundle.Pack(ctx, b.config.Path, b.config.Bundle.File, utils.Version, b.isSelected)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to pack the keploy directory", zap.String("path", b.config.Path))
+		return err
+	}
+	if len(index.Files) == 0 {
+		return fmt.Errorf("found no test sets to pack in %s", b.config.Path)
+	}
+	b.logger.Info("packed the test sets into the bundle", zap.String("bundle", b.config.Bundle.File), zap.Int("files", len(index.Files)))
+
+	if b.config.Storage.Driver == "bundle" {
+		err = os.RemoveAll(b.config.Path)
+		if err != nil {
+			utils.LogError(b.logger, err, "failed to remove the unpacked test sets", zap.String("path", b.config.Path))
+			return err
+		}
+	}
+	return nil
+}
+
+// Unpack extracts the selected test sets of the bundle into the keploy directory. The unpacked test sets replace
+// the local test sets with the same ids, the other local test sets and reports are kept.
+func (b *Bundle) Unpack(ctx context.Context) error {
+	index, err := bundle.ReadIndex(b.config.Bundle.File)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to read the bundle", zap.String("bundle", b.config.Bundle.File))
+		return err
+	}
+
+	var files []string
+	for _, f := range index.Files {
+		if b.isSelected(f.Name) {
+			files = append(files, f.Name)
+		}
+	}
+	count := len(files)
+	if count == 0 {
+		return errors.New("found no test sets to unpack in the bundle " + b.config.Bundle.File)
+	}
+
+	err = os.MkdirAll(b.config.Path, fs.ModePerm)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to create the keploy directory", zap.String("path", b.config.Path))
+		return err
+	}
+	// the bundle is extracted next to the keploy directory first, so that a failed unpack keeps the local test sets
+	tmp, err := os.MkdirTemp(filepath.Dir(filepath.Clean(b.config.Path)), ".keploy-unpack-")
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to create the temporary directory of the unpacked files")
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+
+	_, err = bundle.Unpack(ctx, b.config.Bundle.File, tmp, b.isSelected)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to unpack the bundle", zap.String("bundle", b.config.Bundle.File))
+		return err
+	}
+	err = b.replace(tmp, files)
+	if err != nil {
+		utils.LogError(b.logger, err, "failed to move the unpacked test sets into the keploy directory", zap.String("path", b.config.Path))
+		return err
+	}
+	b.logger.Info("unpacked the test sets from the bundle", zap.String("bundle", b.config.Bundle.File), zap.Int("files", count), zap.String("path", b.config.Path))
+	return nil
+}
+
+// replace moves the unpacked test sets from the temporary directory into the keploy directory in place of the local
+// test sets with the same ids, the stale testcases and mocks of which should not be mixed with the unpacked ones.
+// The unpacked reports are added to the local reports.
+func (b *Bundle) replace(tmp string, files []string) error {
+	moved := map[string]bool{}
+	for _, rel := range files {
+		testSetID := strings.Split(rel, "/")[0]
+		if testSetID == reports {
+			dst := filepath.Join(b.config.Path, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(dst), fs.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Rename(filepath.Join(tmp, filepath.FromSlash(rel)), dst); err != nil {
+				return err
+			}
+			continue
+		}
+		if moved[testSetID] {
+			continue
+		}
+		dst := filepath.Join(b.config.Path, testSetID)
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(tmp, testSetID), dst); err != nil {
+			return err
+		}
+		moved[testSetID] = true
+	}
+	return nil
+}
+
+// isSelected reports whether the file of the keploy directory belongs to the selected test sets. The reports are
+// bundled only if no test set is selected.
+func (b *Bundle) isSelected(name string) bool {
+	if len(b.config.Bundle.TestSets) == 0 {
+		return true
+	}
+	testSetID := strings.Split(name, "/")[0]
+	for _, id := range b.config.Bundle.TestSets {
+		if id == testSetID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+// Package bundle provides the pack and unpack of the test sets, mocks and reports into a single compressed .keploy
+// bundle, which is smaller to commit and faster to checkout than the many yaml files of a large suite.
+package bundle
+
+import (
+	"context"
+)
+
+type Service interface {
+	Pack(ctx context.Context) error
+	Unpack(ctx context.Context) error
+}
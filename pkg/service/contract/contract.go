@@ -0,0 +1,237 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Contractor struct {
+	logger     *zap.Logger
+	testDB     TestDB
+	mockDB     MockDB
+	contractDB ContractDB
+	config     config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, contractDB ContractDB, config config.Config) Service {
+	return &Contractor{
+		logger:     logger,
+		testDB:     testDB,
+		mockDB:     mockDB,
+		contractDB: contractDB,
+		config:     config,
+	}
+}
+
+// Export groups the http mocks of the selected test-sets by the upstream host and stores them as the
+// contracts of the consumer with each provider. The contracts are stored at <contracts>/<provider>/<consumer>.
+func (c *Contractor) Export(ctx context.Context) error {
+	consumer := c.config.Contract.Service
+	if consumer == "" {
+		return errors.New("name of the consumer service is required, pass it using --service")
+	}
+
+	testSetIDs := c.config.Contract.TestSets
+	if len(testSetIDs) == 0 {
+		var err error
+		testSetIDs, err = c.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to get the test-set ids")
+			return err
+		}
+	}
+
+	interactions := map[string][]*models.TestCase{}
+	seen := map[string]bool{}
+	for _, testSetID := range testSetIDs {
+		mocks, err := c.mockDB.GetUnFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+			return err
+		}
+		for _, mock := range mocks {
+			if mock.Kind != models.HTTP || mock.Spec.HTTPReq == nil || mock.Spec.HTTPResp == nil {
+				continue
+			}
+			tc, provider := contractTestCase(mock)
+			if provider == "" {
+				c.logger.Warn("skipping the mock as the host of the provider is not recorded, re-record the mocks to export them as contracts", zap.String("mock", mock.Name), zap.String("test-set", testSetID))
+				continue
+			}
+			// the same interaction can be recorded multiple times across the test-sets
+			key := strings.Join([]string{provider, string(tc.HTTPReq.Method), tc.HTTPReq.URL, tc.HTTPReq.Body}, " ")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			interactions[provider] = append(interactions[provider], tc)
+		}
+	}
+	if len(interactions) == 0 {
+		return errors.New("no http mocks found to export as contracts")
+	}
+
+	for provider, testCases := range interactions {
+		contractID := filepath.Join(provider, consumer)
+		// the previous contract is replaced so that the removed interactions are not verified anymore
+		err := os.RemoveAll(filepath.Join(c.config.Contract.Path, contractID))
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to remove the previous contract", zap.String("provider", provider))
+			return err
+		}
+		for _, tc := range testCases {
+			err := c.contractDB.InsertTestCase(ctx, tc, contractID)
+			if err != nil {
+				utils.LogError(c.logger, err, "failed to insert the contract interaction", zap.String("provider", provider))
+				return err
+			}
+		}
+		c.logger.Info("exported the contract", zap.String("consumer", consumer), zap.String("provider", provider), zap.Int("interactions", len(testCases)))
+	}
+	return nil
+}
+
+// contractTestCase converts the http mock into an interaction of the contract and returns it along with the provider name.
+func contractTestCase(mock *models.Mock) (*models.TestCase, string) {
+	req := *mock.Spec.HTTPReq
+	resp := *mock.Spec.HTTPResp
+	host := mock.Spec.Metadata["host"]
+	if parsedURL, err := url.Parse(req.URL); err == nil {
+		if parsedURL.Host != "" {
+			host = parsedURL.Host
+		} else if host != "" {
+			parsedURL.Scheme = "http"
+			parsedURL.Host = host
+			req.URL = parsedURL.String()
+		}
+	}
+	req.Timestamp = mock.Spec.ReqTimestampMock
+	resp.Timestamp = mock.Spec.ResTimestampMock
+
+	tc := &models.TestCase{
+		Version:  models.GetVersion(),
+		Kind:     models.HTTP,
+		Created:  mock.Spec.Created,
+		HTTPReq:  req,
+		HTTPResp: resp,
+		// the consumer only relies on the status code and the body of the response
+		Noise: map[string][]string{"header": {}},
+	}
+	return tc, strings.ReplaceAll(host, ":", "-")
+}
+
+// Verify replays the interactions of the contracts of the provider(s) at the provider service and
+// compares the responses with the ones the consumers rely on.
+func (c *Contractor) Verify(ctx context.Context) error {
+	providers := []string{c.config.Contract.Provider}
+	if c.config.Contract.Provider == "" {
+		var err error
+		providers, err = listDirs(c.config.Contract.Path)
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to read the contracts", zap.String("path", c.config.Contract.Path))
+			return err
+		}
+	}
+
+	total, failed := 0, 0
+	for _, provider := range providers {
+		consumers, err := listDirs(filepath.Join(c.config.Contract.Path, provider))
+		if err != nil {
+			utils.LogError(c.logger, err, "failed to read the contracts of the provider", zap.String("provider", provider))
+			return err
+		}
+		for _, consumer := range consumers {
+			passed, count, err := c.verifyContract(ctx, provider, consumer)
+			if err != nil {
+				return err
+			}
+			total += count
+			failed += count - passed
+
+			if passed == count {
+				pp.SetColorScheme(models.PassingColorScheme)
+			} else {
+				pp.SetColorScheme(models.FailingColorScheme)
+			}
+			if _, err := pp.Printf("\n <=========================================> \n  CONTRACT VERIFICATION SUMMARY. Consumer: %s Provider: %s\n"+"\tTotal interactions: %s\n"+"\tTotal interactions passed: %s\n"+"\tTotal interactions failed: %s\n <=========================================> \n\n", consumer, provider, count, passed, count-passed); err != nil {
+				utils.LogError(c.logger, err, "failed to print the contract verification summary")
+				return err
+			}
+		}
+	}
+	if total == 0 {
+		return errors.New("no contracts found to verify")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d contract interactions failed", failed, total)
+	}
+	c.logger.Info("all the contracts are verified", zap.Int("interactions", total))
+	return nil
+}
+
+// verifyContract replays the interactions of the consumer at the provider and returns the number of passed and total interactions.
+func (c *Contractor) verifyContract(ctx context.Context, provider, consumer string) (int, int, error) {
+	testCases, err := c.contractDB.GetTestCases(ctx, filepath.Join(provider, consumer))
+	if err != nil {
+		utils.LogError(c.logger, err, "failed to get the contract interactions", zap.String("provider", provider), zap.String("consumer", consumer))
+		return 0, 0, err
+	}
+
+	passed, total := 0, 0
+	for _, tc := range testCases {
+		if tc.Kind != models.HTTP {
+			continue
+		}
+		total++
+		if c.config.Contract.BaseURL != "" {
+			tc.HTTPReq.URL, err = pkg.ReplaceBaseURL(tc.HTTPReq.URL, c.config.Contract.BaseURL)
+			if err != nil {
+				utils.LogError(c.logger, err, "failed to replace the base url of the interaction", zap.String("interaction", tc.Name))
+				return 0, 0, err
+			}
+		}
+		resp, err := pkg.SimulateHTTP(ctx, *tc, consumer, c.logger, c.config.Test.APITimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, 0, ctx.Err()
+			}
+			// the provider not responding to an interaction is a failure of the contract
+			utils.LogError(c.logger, err, "contract check failed, the provider didn't respond to the interaction", zap.String("provider", provider), zap.String("consumer", consumer), zap.String("interaction", tc.Name))
+			continue
+		}
+		if ok, _ := replay.Match(tc, resp, nil, c.config.Test.IgnoreOrdering, c.config.Test.ArrayMatching, c.config.Test.HashAssets, c.logger); ok {
+			passed++
+		}
+	}
+	return passed, total, nil
+}
+
+func listDirs(path string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	sort.Strings(dirs)
+	return dirs, nil
+}
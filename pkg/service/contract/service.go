@@ -0,0 +1,30 @@
+// Package contract provides the consumer driven contract testing built on the recorded mocks.
+package contract
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Export converts the http mocks recorded by the consumer service into contracts of its providers
+	Export(ctx context.Context) error
+	// Verify replays the requests of the contracts at the provider service and compares the responses
+	Verify(ctx context.Context) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+}
+
+type MockDB interface {
+	GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+}
+
+// ContractDB stores the contracts as testcases, a contract is the test-set of a provider and consumer pair.
+type ContractDB interface {
+	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+}
@@ -0,0 +1,92 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/core/app/docker"
+	"go.keploy.io/server/v2/pkg/core/hooks"
+	"go.uber.org/zap"
+)
+
+type Doctor struct {
+	logger *zap.Logger
+	config config.Config
+}
+
+func New(logger *zap.Logger, config config.Config) Service {
+	return &Doctor{
+		logger: logger,
+		config: config,
+	}
+}
+
+// Diagnose runs the preflight of the eBPF hooks on linux, and checks the docker daemon and the ports of keploy.
+func (d *Doctor) Diagnose(ctx context.Context) []hooks.Check {
+	var checks []hooks.Check
+	if runtime.GOOS == "linux" {
+		checks = append(checks, hooks.Preflight(d.config.BTFPath)...)
+	} else {
+		checks = append(checks, hooks.Check{
+			Name:   "redirect",
+			Detail: fmt.Sprintf("the eBPF hooks aren't supported on %s, keploy runs in the proxy mode", runtime.GOOS),
+		})
+	}
+	checks = append(checks, d.checkDocker(ctx))
+	checks = append(checks,
+		checkPort("proxy port", "tcp", d.config.ProxyPort, "--proxyPort"),
+		checkPort("DNS port", "tcp", d.config.DNSPort, "--dnsPort"),
+		checkPort("DNS port", "udp", d.config.DNSPort, "--dnsPort"),
+	)
+	return checks
+}
+
+// checkDocker checks that keploy can reach the docker daemon, which is only needed for the docker apps.
+func (d *Doctor) checkDocker(ctx context.Context) hooks.Check {
+	c := hooks.Check{Name: "docker", Warn: true}
+	client, err := docker.New(d.logger)
+	if err != nil {
+		c.Err = fmt.Errorf("failed to create the docker client: %w", err)
+		c.Hint = "check DOCKER_HOST, docker is only needed to record and test the docker apps"
+		return c
+	}
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	ping, err := client.Ping(ctx)
+	if err != nil {
+		c.Err = fmt.Errorf("failed to reach the docker daemon: %w", err)
+		c.Hint = "start docker and add the user to the docker group or run keploy with sudo, docker is only needed to record and test the docker apps"
+		return c
+	}
+	c.Detail = fmt.Sprintf("%s, api %s", client.DaemonHost(), ping.APIVersion)
+	return c
+}
+
+// checkPort checks that the port of keploy isn't used by another process.
+func checkPort(name, network string, port uint32, flag string) hooks.Check {
+	c := hooks.Check{Name: name, Detail: fmt.Sprintf("%d/%s", port, network)}
+	addr := fmt.Sprintf(":%d", port)
+	var err error
+	if network == "udp" {
+		var conn net.PacketConn
+		conn, err = net.ListenPacket(network, addr)
+		if err == nil {
+			err = conn.Close()
+		}
+	} else {
+		var lis net.Listener
+		lis, err = net.Listen(network, addr)
+		if err == nil {
+			err = lis.Close()
+		}
+	}
+	if err != nil {
+		c.Err = fmt.Errorf("the port %d/%s is in use: %w", port, network, err)
+		c.Hint = fmt.Sprintf("stop the process using it, e.g. found with `sudo lsof -i :%d`, or set another port with %s", port, flag)
+	}
+	return c
+}
@@ -0,0 +1,13 @@
+// Package doctor provides the checks of the machine keploy runs on, so that what would stop keploy record or
+// keploy test is reported with its fix before they are run.
+package doctor
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/core/hooks"
+)
+
+type Service interface {
+	Diagnose(ctx context.Context) []hooks.Check
+}
@@ -0,0 +1,548 @@
+package explain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Explainer struct {
+	logger   *zap.Logger
+	testDB   TestDB
+	mockDB   MockDB
+	reportDB ReportDB
+	config   config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, config config.Config) Service {
+	return &Explainer{
+		logger:   logger,
+		testDB:   testDB,
+		mockDB:   mockDB,
+		reportDB: reportDB,
+		config:   config,
+	}
+}
+
+var (
+	// arrayIndex matches the index of an array element in a field, replaced with [*] in the suggested noise
+	arrayIndex = regexp.MustCompile(`\[\d+\]`)
+	// generated matches the values which look generated per request, like the uuids, the hex ids and the tokens
+	generated = regexp.MustCompile(`^(?i:[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}|[0-9a-f]{16,}|[A-Za-z0-9_\-+/=.]{20,})$`)
+)
+
+// fieldDiff is a field of the response which differed, with its expected and actual values as JSON.
+type fieldDiff struct {
+	field    string
+	expected string
+	actual   string
+}
+
+// explanation is what is known about a failed testcase, from its result, the testcase and the mocks of the test set.
+type explanation struct {
+	result models.TestResult
+	tc     *models.TestCase
+	diffs  []fieldDiff
+	// notConsumed are the mocks recorded for the testcase which weren't consumed, the ones of the outgoing diff of
+	// the result or, for the results which predate it, the ones recorded while the testcase was recorded
+	notConsumed []string
+	kinds       map[string]models.Kind
+	causes      []string
+}
+
+// Explain writes the explanation of the failed testcases of the test set in the test run.
+func (e *Explainer) Explain(ctx context.Context, testSetID string, testRunID string, testCases []string, w io.Writer) error {
+	if testRunID == "" {
+		var err error
+		testRunID, err = e.latestTestRun(ctx, testSetID)
+		if err != nil {
+			return err
+		}
+	}
+	report, err := e.reportDB.GetReport(ctx, testRunID, testSetID)
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to get the report of the test set", zap.String("test-set", testSetID), zap.String("test-run", testRunID))
+		return err
+	}
+	tcs, err := e.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to get the testcases", zap.String("test-set", testSetID))
+		return err
+	}
+	byName := make(map[string]*models.TestCase, len(tcs))
+	for _, tc := range tcs {
+		byName[tc.Name] = tc
+	}
+	// the mocks are read once for all of the testcases, without filtering them by time
+	filtered, err := e.mockDB.GetFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+		return err
+	}
+	unfiltered, err := e.mockDB.GetUnFilteredMocks(ctx, testSetID, time.Time{}, time.Time{})
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+		return err
+	}
+	kinds := make(map[string]models.Kind, len(filtered)+len(unfiltered))
+	for _, mocks := range [][]*models.Mock{filtered, unfiltered} {
+		for _, mock := range mocks {
+			kinds[mock.Name] = mock.Kind
+		}
+	}
+
+	selected := make(map[string]bool, len(testCases))
+	for _, name := range testCases {
+		selected[name] = true
+	}
+	var explanations []*explanation
+	for _, result := range report.Tests {
+		if len(selected) > 0 {
+			if !selected[result.TestCaseID] {
+				continue
+			}
+			delete(selected, result.TestCaseID)
+		} else if result.Status != models.TestStatusFailed {
+			continue
+		}
+		ex := &explanation{result: result, tc: byName[result.TestCaseID], kinds: kinds}
+		switch {
+		case result.OutgoingDiff != nil:
+			ex.notConsumed = result.OutgoingDiff.Missing
+		case ex.tc != nil && result.ConsumedMocks != nil:
+			ex.notConsumed = notConsumed(recordedMocks(ex.tc, filtered, unfiltered), result.ConsumedMocks)
+		}
+		ex.diffs = fieldDiffs(result, e.noise(testSetID, result.Noise))
+		ex.causes = e.likelyCauses(testSetID, ex)
+		explanations = append(explanations, ex)
+	}
+	for name := range selected {
+		e.logger.Warn("the testcase wasn't run in the test run", zap.String("testcase", name), zap.String("test-run", testRunID))
+	}
+	if len(explanations) == 0 {
+		if len(testCases) > 0 {
+			return fmt.Errorf("found none of the testcases in %s of %s", testSetID, testRunID)
+		}
+		fmt.Fprintf(w, "All the testcases of %s passed in %s, nothing to explain.\n", testSetID, testRunID)
+		return nil
+	}
+	for i, ex := range explanations {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		e.write(w, testSetID, testRunID, ex)
+	}
+	return nil
+}
+
+// latestTestRun returns the last test run with a report of the test set.
+func (e *Explainer) latestTestRun(ctx context.Context, testSetID string) (string, error) {
+	testRunIDs, err := e.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(e.logger, err, "failed to get the test runs")
+		return "", err
+	}
+	latest, latestIndex := "", -1
+	for _, id := range testRunIDs {
+		if !strings.HasPrefix(id, models.TestRunTemplateName) {
+			continue
+		}
+		index, err := strconv.Atoi(strings.TrimPrefix(id, models.TestRunTemplateName))
+		if err != nil || index <= latestIndex {
+			continue
+		}
+		if _, err := e.reportDB.GetReport(ctx, id, testSetID); err != nil {
+			continue
+		}
+		latest, latestIndex = id, index
+	}
+	if latest == "" {
+		return "", errors.New("found no test run of the test set, run keploy test first")
+	}
+	return latest, nil
+}
+
+// recordedMocks returns the names of the mocks whose requests were sent while the testcase was recorded, but for
+// the config mocks which are shared by all of the testcases.
+func recordedMocks(tc *models.TestCase, filtered, unfiltered []*models.Mock) []string {
+	start, end := tc.ReqTimestamp(), tc.RespTimestamp()
+	if start.IsZero() || end.IsZero() {
+		return nil
+	}
+	var names []string
+	for _, mocks := range [][]*models.Mock{filtered, unfiltered} {
+		for _, mock := range mocks {
+			at := mock.Spec.ReqTimestampMock
+			if mock.Spec.Metadata["type"] != "config" && !at.Before(start) && !at.After(end) {
+				names = append(names, mock.Name)
+			}
+		}
+	}
+	return names
+}
+
+// noise returns the noisy fields of the testcase and of the test set, e.g. body.data.items.id or header.Date, the
+// indices of the arrays removed.
+func (e *Explainer) noise(testSetID string, tcNoise map[string][]string) map[string]bool {
+	noise := map[string]bool{}
+	for field := range tcNoise {
+		noise[noiseKey(field)] = true
+	}
+	for _, n := range []config.GlobalNoise{e.config.Test.GlobalNoise.Global, e.config.Test.GlobalNoise.Testsets[testSetID]} {
+		for scope, fields := range n {
+			for field := range fields {
+				noise[noiseKey(scope+"."+strings.TrimPrefix(strings.TrimPrefix(field, "$"), "."))] = true
+			}
+		}
+	}
+	return noise
+}
+
+func noiseKey(field string) string {
+	return strings.ToLower(strings.ReplaceAll(arrayIndex.ReplaceAllString(field, ""), "[*]", ""))
+}
+
+// isNoisy reports whether the field or any of the objects it is in is noisy.
+func isNoisy(noise map[string]bool, field string) bool {
+	key := noiseKey(field)
+	for {
+		if noise[key] {
+			return true
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return false
+		}
+		key = key[:i]
+	}
+}
+
+// notConsumed returns the recorded mocks which weren't consumed.
+func notConsumed(recorded, consumed []string) []string {
+	called := make(map[string]bool, len(consumed))
+	for _, name := range consumed {
+		called[name] = true
+	}
+	var names []string
+	for _, name := range recorded {
+		if !called[name] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// fieldDiffs returns the status code, the headers and the fields of the JSON bodies which differed, but for the
+// noisy ones. A body which isn't JSON differs as a whole.
+func fieldDiffs(result models.TestResult, noise map[string]bool) []fieldDiff {
+	var diffs []fieldDiff
+	status := result.Result.StatusCode
+	if !status.Normal && status.Expected != status.Actual {
+		diffs = append(diffs, fieldDiff{field: "status", expected: strconv.Itoa(status.Expected), actual: strconv.Itoa(status.Actual)})
+	}
+	for _, header := range result.Result.HeadersResult {
+		if header.Normal {
+			continue
+		}
+		key := header.Expected.Key
+		if key == "" {
+			key = header.Actual.Key
+		}
+		diffs = append(diffs, fieldDiff{field: "header." + key, expected: strings.Join(header.Expected.Value, ", "), actual: strings.Join(header.Actual.Value, ", ")})
+	}
+	for _, body := range result.Result.BodyResult {
+		if body.Normal {
+			continue
+		}
+		var exp, act interface{}
+		if body.Type != models.BodyTypeJSON || json.Unmarshal([]byte(body.Expected), &exp) != nil || json.Unmarshal([]byte(body.Actual), &act) != nil {
+			diffs = append(diffs, fieldDiff{field: "body", expected: body.Expected, actual: body.Actual})
+			continue
+		}
+		expFields, actFields := map[string]string{}, map[string]string{}
+		flatten("body", exp, expFields)
+		flatten("body", act, actFields)
+		for _, field := range sortedUnion(expFields, actFields) {
+			expValue, inExp := expFields[field]
+			actValue, inAct := actFields[field]
+			if inExp && inAct && expValue == actValue || isNoisy(noise, field) {
+				continue
+			}
+			if !inExp {
+				expValue = "<missing>"
+			}
+			if !inAct {
+				actValue = "<missing>"
+			}
+			diffs = append(diffs, fieldDiff{field: field, expected: expValue, actual: actValue})
+		}
+	}
+	return diffs
+}
+
+// flatten adds the leaves of the JSON value to the fields by their paths, e.g. body.data.items[0].id, with their
+// values as JSON. The empty objects and arrays are leaves too.
+func flatten(path string, v interface{}, fields map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			for key, value := range v {
+				flatten(path+"."+key, value, fields)
+			}
+			return
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			for i, value := range v {
+				flatten(fmt.Sprintf("%s[%d]", path, i), value, fields)
+			}
+			return
+		}
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		out = []byte(fmt.Sprint(v))
+	}
+	fields[path] = string(out)
+}
+
+func sortedUnion(a, b map[string]string) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// likelyCauses guesses why the testcase failed, from the values which differed and the outgoing calls it made.
+func (e *Explainer) likelyCauses(testSetID string, ex *explanation) []string {
+	var causes []string
+	var times, ids []string
+	for _, diff := range ex.diffs {
+		if diff.field == "status" || diff.field == "body" {
+			continue
+		}
+		exp, act := unquote(diff.expected), unquote(diff.actual)
+		switch {
+		case pkg.IsTime(exp) && pkg.IsTime(act):
+			times = append(times, diff.field)
+		case generated.MatchString(exp) && generated.MatchString(act):
+			ids = append(ids, diff.field)
+		}
+	}
+	if len(times) > 0 {
+		cause := fmt.Sprintf("clock skew: %s hold times which moved since the recording.", strings.Join(times, ", "))
+		if !e.config.Test.FreezeTime {
+			cause += " Run keploy test with --freezeTime to replay at the recorded time, or add them to the noise."
+		} else {
+			cause += " The time was frozen, so they may be set by a dependency, add them to the noise."
+		}
+		causes = append(causes, cause)
+	}
+	if len(ids) > 0 {
+		var commands []string
+		for _, field := range ids {
+			commands = append(commands, fmt.Sprintf("keploy noise add %s %s", testSetID, arrayIndex.ReplaceAllString(field, "[*]")))
+		}
+		causes = append(causes, fmt.Sprintf("missing noise: %s look generated per request, add them to the noise with:\n      %s", strings.Join(ids, ", "), strings.Join(commands, "\n      ")))
+	}
+
+	result := ex.result
+	missingMocks := 0
+	for _, outgoingErr := range result.ProxyErrors {
+		if outgoingErr.Type == models.ErrMockMissing {
+			missingMocks++
+		}
+	}
+	var parts []string
+	if missingMocks > 0 {
+		parts = append(parts, fmt.Sprintf("%d outgoing calls matched no mock", missingMocks))
+	}
+	if len(ex.notConsumed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d recorded calls weren't made", len(ex.notConsumed)))
+	}
+	if len(parts) > 0 {
+		causes = append(causes, fmt.Sprintf("changed dependency: %s, the calls of the app to its dependencies changed since the recording. Re-record the test set, or update its mocks.", strings.Join(parts, " and ")))
+	}
+	for _, outgoingErr := range result.ProxyErrors {
+		if outgoingErr.Type != models.ErrMockMissing {
+			causes = append(causes, fmt.Sprintf("infra: the proxy failed to mock an outgoing call (%s), the failure may not be of the app.", outgoingErr.Type))
+			break
+		}
+	}
+	if len(result.Faults) > 0 {
+		causes = append(causes, fmt.Sprintf("injected faults: %d mock responses were replaced with faults by the chaos rules.", len(result.Faults)))
+	}
+	if latency := result.Result.LatencyResult; latency != nil && !latency.Normal {
+		causes = append(causes, fmt.Sprintf("slow response: %.0fms over the max latency of %dms, recorded in %.0fms.", latency.Actual, latency.Max, latency.Recorded))
+	}
+	if len(causes) == 0 && len(ex.diffs) > 0 {
+		causes = append(causes, "changed behaviour: the response changed without a change of the dependencies, approve it with keploy approve if it is expected.")
+	}
+	return causes
+}
+
+func unquote(value string) string {
+	if s, err := strconv.Unquote(value); err == nil {
+		return s
+	}
+	return value
+}
+
+func (e *Explainer) write(w io.Writer, testSetID, testRunID string, ex *explanation) {
+	result := ex.result
+	request := ""
+	switch {
+	case result.Kind == models.GRPC_EXPORT:
+		request = result.GrpcReq.Headers.PseudoHeaders[":path"]
+	case result.Req.URL != "":
+		request = string(result.Req.Method) + " " + result.Req.URL
+	}
+	fmt.Fprintf(w, "%s/%s %s in %s", testSetID, result.TestCaseID, result.Status, testRunID)
+	if request != "" {
+		fmt.Fprintf(w, " (%s)", request)
+	}
+	fmt.Fprintln(w)
+	if ex.tc == nil {
+		fmt.Fprintln(w, "  the testcase no longer exists in the test set")
+	}
+
+	fmt.Fprintln(w, "\n  Differences:")
+	if len(ex.diffs) == 0 {
+		fmt.Fprintln(w, "    none in the response")
+	}
+	for _, diff := range ex.diffs {
+		fmt.Fprintf(w, "    %s: expected %s, got %s\n", diff.field, truncate(diff.expected), truncate(diff.actual))
+	}
+	if latency := result.Result.LatencyResult; latency != nil && !latency.Normal {
+		fmt.Fprintf(w, "    latency: %.0fms over the max of %dms\n", latency.Actual, latency.Max)
+	}
+	for _, dep := range result.Result.DepResult {
+		for _, meta := range dep.Meta {
+			if !meta.Normal {
+				fmt.Fprintf(w, "    %s %s: expected %s, got %s\n", dep.Name, meta.Key, meta.Expected, meta.Actual)
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "\n  Mocks:")
+	if result.ConsumedMocks == nil {
+		fmt.Fprintln(w, "    the report predates the consumed mocks, rerun keploy test to list them")
+	} else {
+		fmt.Fprintf(w, "    consumed: %s\n", ex.mockList(result.ConsumedMocks))
+	}
+	consumed := make(map[string]bool, len(result.ConsumedMocks))
+	for _, name := range result.ConsumedMocks {
+		consumed[name] = true
+	}
+	if len(ex.notConsumed) > 0 {
+		fmt.Fprintf(w, "    recorded for the testcase but not consumed: %s\n", ex.mockList(ex.notConsumed))
+	}
+	if len(result.CorrelatedMocks) > 0 {
+		var missed []string
+		for _, name := range result.CorrelatedMocks {
+			if !consumed[name] {
+				missed = append(missed, name)
+			}
+		}
+		if len(missed) > 0 {
+			fmt.Fprintf(w, "    correlated with the testcase but not consumed: %s\n", ex.mockList(missed))
+		}
+	}
+	var others, unmocked []string
+	if result.OutgoingDiff != nil {
+		for _, name := range result.OutgoingDiff.Extra {
+			if _, ok := ex.kinds[name]; ok {
+				others = append(others, name)
+			}
+		}
+	}
+	for _, outgoingErr := range result.ProxyErrors {
+		if outgoingErr.Type == models.ErrMockMissing {
+			unmocked = append(unmocked, outgoingErr.Message)
+		}
+	}
+	if len(others) > 0 {
+		fmt.Fprintf(w, "    consumed but recorded for other testcases: %s\n", ex.mockList(others))
+	}
+	if len(unmocked) > 0 {
+		fmt.Fprintln(w, "    outgoing calls which matched no mock:")
+		for _, call := range unmocked {
+			fmt.Fprintf(w, "      %s\n", truncate(call))
+		}
+	}
+	for _, outgoingErr := range result.ProxyErrors {
+		if outgoingErr.Type != models.ErrMockMissing {
+			fmt.Fprintf(w, "    proxy error: %s %s\n", outgoingErr.Type, truncate(outgoingErr.Message))
+		}
+	}
+	// the calls to the bypassed destinations go passthrough to the real dependencies, they are neither mocked nor reported
+	for _, rule := range e.config.BypassRules {
+		var dest []string
+		if rule.Host != "" {
+			dest = append(dest, "host "+rule.Host)
+		}
+		if rule.Port != 0 {
+			dest = append(dest, fmt.Sprintf("port %d", rule.Port))
+		}
+		if rule.Path != "" {
+			dest = append(dest, "path "+rule.Path)
+		}
+		if len(dest) > 0 {
+			fmt.Fprintf(w, "    passthrough, not mocked: %s\n", strings.Join(dest, ", "))
+		}
+	}
+
+	fmt.Fprintln(w, "\n  Likely causes:")
+	if len(ex.causes) == 0 {
+		fmt.Fprintln(w, "    none found")
+	}
+	for _, cause := range ex.causes {
+		fmt.Fprintf(w, "    - %s\n", cause)
+	}
+}
+
+// mockList lists the mocks with their kinds, e.g. mock-3 (Postgres).
+func (ex *explanation) mockList(names []string) string {
+	if len(names) == 0 {
+		return "none"
+	}
+	items := make([]string, 0, len(names))
+	for _, name := range names {
+		if kind, ok := ex.kinds[name]; ok {
+			items = append(items, fmt.Sprintf("%s (%s)", name, kind))
+			continue
+		}
+		items = append(items, name)
+	}
+	return strings.Join(items, ", ")
+}
+
+// truncate shortens the long values, like the whole bodies, to keep the explanation readable.
+func truncate(value string) string {
+	const max = 200
+	runes := []rune(strings.Join(strings.Fields(value), " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return string(runes[:max]) + "…"
+}
@@ -0,0 +1,32 @@
+// Package explain provides the explanation of the failed testcases of a test run, aggregated from their results in
+// the report, the testcases and the mocks of the test set: the fields which differed, the mocks which were and
+// weren't consumed, the outgoing calls which weren't mocked, and the likely causes of the failures.
+package explain
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Explain writes the explanation of the failed testcases of the test set in the test run, the latest test run
+	// of the test set if empty. Only the given testcases are explained if any, whether they failed or not.
+	Explain(ctx context.Context, testSetID string, testRunID string, testCases []string, w io.Writer) error
+}
+
+type TestDB interface {
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+}
+
+type MockDB interface {
+	GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+	GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+}
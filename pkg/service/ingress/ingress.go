@@ -0,0 +1,111 @@
+package ingress
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Ingress struct {
+	logger *zap.Logger
+	testDB TestDB
+	config config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, config config.Config) Service {
+	return &Ingress{
+		logger: logger,
+		testDB: testDB,
+		config: config,
+	}
+}
+
+// Start sends the recorded http requests of the test sets, all of them if none is selected, to the URL in their
+// recorded order, and compares the responses with the recorded ones after removing the noise of the test sets.
+// Unlike keploy test, the outgoing calls of the environment aren't mocked, so its responses depend on its data.
+func (i *Ingress) Start(ctx context.Context) error {
+	if i.config.Ingress.URL == "" {
+		return errors.New("missing the url of the environment to send the requests to")
+	}
+	testSetIDs := i.config.Ingress.TestSets
+	if len(testSetIDs) == 0 {
+		var err error
+		testSetIDs, err = i.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(i.logger, err, "failed to get the test-set ids")
+			return err
+		}
+	}
+
+	total, failed := 0, 0
+	for _, testSetID := range testSetIDs {
+		passed, count, err := i.replayTestSet(ctx, testSetID)
+		if err != nil {
+			return err
+		}
+		total += count
+		failed += count - passed
+
+		if passed == count {
+			pp.SetColorScheme(models.PassingColorScheme)
+		} else {
+			pp.SetColorScheme(models.FailingColorScheme)
+		}
+		if _, err := pp.Printf("\n <=========================================> \n  INGRESS REPLAY SUMMARY. Test set: %s URL: %s\n"+"\tTotal requests: %s\n"+"\tTotal requests passed: %s\n"+"\tTotal requests failed: %s\n <=========================================> \n\n", testSetID, i.config.Ingress.URL, count, passed, count-passed); err != nil {
+			utils.LogError(i.logger, err, "failed to print the ingress replay summary")
+			return err
+		}
+	}
+	if total == 0 {
+		return errors.New("no http testcases found to replay")
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d requests didn't get the recorded response", failed, total)
+	}
+	i.logger.Info("all the requests got the recorded responses", zap.Int("requests", total))
+	return nil
+}
+
+// replayTestSet sends the requests of the test set and returns the number of passed and total requests.
+func (i *Ingress) replayTestSet(ctx context.Context, testSetID string) (int, int, error) {
+	testCases, err := i.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		utils.LogError(i.logger, err, "failed to get the testcases", zap.String("test-set", testSetID))
+		return 0, 0, err
+	}
+	noise := replay.LeftJoinNoise(i.config.Test.GlobalNoise.Global, i.config.Test.GlobalNoise.Testsets[testSetID])
+
+	passed, total := 0, 0
+	for _, tc := range testCases {
+		if ctx.Err() != nil {
+			return passed, total, ctx.Err()
+		}
+		if tc.Kind != models.HTTP {
+			continue
+		}
+		total++
+		tc.HTTPReq.URL, err = pkg.ReplaceBaseURL(tc.HTTPReq.URL, i.config.Ingress.URL)
+		if err != nil {
+			utils.LogError(i.logger, err, "failed to replace the base url of the testcase", zap.String("testcase", tc.Name))
+			return 0, 0, err
+		}
+		resp, err := pkg.SimulateHTTP(ctx, *tc, testSetID, i.logger, i.config.Ingress.APITimeout)
+		if err != nil {
+			// the environment not responding to a request is a failure of the request
+			utils.LogError(i.logger, err, "failed to send the request", zap.String("testcase", tc.Name), zap.String("test-set", testSetID))
+			continue
+		}
+		if ok, _ := replay.Match(tc, resp, noise, i.config.Test.IgnoreOrdering, i.config.Test.ArrayMatching, i.config.Test.HashAssets, i.logger); ok {
+			passed++
+		}
+	}
+	return passed, total, nil
+}
@@ -0,0 +1,19 @@
+// Package ingress provides keploy replay-ingress, which sends the recorded incoming requests of the test sets to
+// an environment as a client, e.g. a deployed staging, and compares its responses with the recorded ones.
+package ingress
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Start sends the recorded requests to the environment and returns an error if any response doesn't match
+	Start(ctx context.Context) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+}
@@ -0,0 +1,170 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/service/record"
+	"go.keploy.io/server/v2/pkg/service/remote"
+	"go.keploy.io/server/v2/pkg/service/replay"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+type K8s struct {
+	logger   *zap.Logger
+	services ServiceFactory
+	config   config.Config
+}
+
+func New(logger *zap.Logger, services ServiceFactory, config config.Config) Service {
+	return &K8s{
+		logger:   logger,
+		services: services,
+		config:   config,
+	}
+}
+
+func (k *K8s) Record(ctx context.Context) error {
+	svc, err := k.services.GetService(ctx, "record")
+	if err != nil {
+		return err
+	}
+	recorder, ok := svc.(record.Service)
+	if !ok {
+		return errors.New("service doesn't satisfy record service interface")
+	}
+	err = recorder.Start(ctx)
+	if err != nil {
+		return err
+	}
+	// the recording ends with the termination of the pod, the test sets are still pushed within its grace period
+	return k.sync(context.WithoutCancel(ctx), "push")
+}
+
+func (k *K8s) Test(ctx context.Context) error {
+	err := k.sync(ctx, "pull")
+	if err != nil {
+		return err
+	}
+	failed, err := k.runTests(ctx)
+	// the reports are pushed even if the test run failed, to look into the failures
+	if pushErr := k.sync(context.WithoutCancel(ctx), "push"); pushErr != nil && err == nil {
+		err = pushErr
+	}
+	if err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("the test sets %s failed", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// runTests runs the selected test sets in a new test run and returns the test sets which didn't pass.
+func (k *K8s) runTests(ctx context.Context) ([]string, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
+	var hookCancel context.CancelFunc
+	defer func() {
+		if hookCancel != nil {
+			hookCancel()
+		}
+		if err := g.Wait(); err != nil {
+			utils.LogError(k.logger, err, "failed to stop the test run")
+		}
+	}()
+
+	svc, err := k.services.GetService(ctx, "test")
+	if err != nil {
+		return nil, err
+	}
+	replayer, ok := svc.(replay.Service)
+	if !ok {
+		return nil, errors.New("service doesn't satisfy replay service interface")
+	}
+	testSetIDs, err := replayer.GetAllTestSetIDs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(testSetIDs) == 0 {
+		return nil, errors.New("no test sets found in the keploy directory")
+	}
+
+	testRunID, appID, cancel, err := replayer.BootReplay(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to boot replay: %w", err)
+	}
+	hookCancel = cancel
+
+	var failed []string
+	for _, testSetID := range testSetIDs {
+		if _, ok := k.config.Test.SelectedTests[testSetID]; !ok && len(k.config.Test.SelectedTests) != 0 {
+			continue
+		}
+		testSetStatus, err := replayer.RunTestSet(ctx, testSetID, testRunID, appID, false)
+		if err != nil {
+			return failed, fmt.Errorf("failed to run test set %s: %w", testSetID, err)
+		}
+		k.logger.Info("test set completed", zap.String("testRunID", testRunID), zap.String("testSet", testSetID), zap.String("status", string(testSetStatus)))
+		switch testSetStatus {
+		case models.TestSetStatusPassed:
+		case models.TestSetStatusAppHalted, models.TestSetStatusInternalErr, models.TestSetStatusFaultUserApp:
+			return append(failed, testSetID), fmt.Errorf("test run %s aborted, test set %s: %s", testRunID, testSetID, testSetStatus)
+		case models.TestSetStatusUserAbort:
+			return failed, context.Canceled
+		default:
+			failed = append(failed, testSetID)
+		}
+	}
+	return failed, nil
+}
+
+// sync pushes or pulls the keploy directory if a bucket is set, it is kept on the volume of the pod otherwise.
+func (k *K8s) sync(ctx context.Context, cmd string) error {
+	if k.config.Remote.Bucket == "" {
+		return nil
+	}
+	svc, err := k.services.GetService(ctx, cmd)
+	if err != nil {
+		return err
+	}
+	bucket, ok := svc.(remote.Service)
+	if !ok {
+		return errors.New("service doesn't satisfy remote service interface")
+	}
+	if cmd == "pull" {
+		return bucket.Pull(ctx)
+	}
+	return bucket.Push(ctx)
+}
+
+func (k *K8s) Manifest(_ context.Context, kind string, w io.Writer) error {
+	var tmpl *template.Template
+	switch kind {
+	case "sidecar":
+		tmpl = sidecarTemplate
+	case "job":
+		tmpl = jobTemplate
+	default:
+		return fmt.Errorf("unknown manifest %q, the manifests are sidecar and job", kind)
+	}
+	if kind == "job" && k.config.K8s.AppImage == "" {
+		return errors.New("missing the image of the app of the job, set it with --appImage")
+	}
+	return tmpl.Execute(w, manifest{
+		Image:     k.config.K8s.Image,
+		AppImage:  k.config.K8s.AppImage,
+		PVC:       k.config.K8s.PVC,
+		Bucket:    k.config.Remote.Bucket,
+		ProxyPort: k.config.ProxyPort,
+		Command:   k.config.Command,
+	})
+}
@@ -0,0 +1,112 @@
+package k8s
+
+import "text/template"
+
+type manifest struct {
+	Image     string
+	AppImage  string
+	PVC       string
+	Bucket    string
+	ProxyPort uint32
+	Command   string
+}
+
+// sidecarTemplate is the keploy container and the volumes to add to the pod of the app. keploy is a native sidecar
+// so that it hooks the processes of the pod before the app starts, the startup probe waits for its proxy.
+var sidecarTemplate = template.Must(template.New("sidecar").Parse(`# add to the pod spec of the app
+shareProcessNamespace: true
+initContainers:
+  - name: keploy
+    image: {{.Image}}
+    restartPolicy: Always
+    args: ["k8s", "record", "--path", "/data"{{if .Bucket}}, "--bucket", "{{.Bucket}}"{{end}}]
+    securityContext:
+      privileged: true
+    startupProbe:
+      tcpSocket:
+        port: {{.ProxyPort}}
+      periodSeconds: 1
+      failureThreshold: 60
+{{- if .Bucket}}
+    envFrom:
+      - secretRef:
+          name: keploy-s3
+          optional: true
+{{- end}}
+    volumeMounts:
+      - name: keploy
+        mountPath: /data
+      - name: debugfs
+        mountPath: /sys/kernel/debug
+      - name: bpffs
+        mountPath: /sys/fs/bpf
+volumes:
+  - name: keploy
+{{- if .PVC}}
+    persistentVolumeClaim:
+      claimName: {{.PVC}}
+{{- else}}
+    emptyDir: {}
+{{- end}}
+  - name: debugfs
+    hostPath:
+      path: /sys/kernel/debug
+  - name: bpffs
+    hostPath:
+      path: /sys/fs/bpf
+`))
+
+// jobTemplate is the Job testing the app. The app is a native sidecar, keploy is the main container so that the Job
+// completes with the test run and fails with it. The connections the app opens before keploy hooks it, e.g. the
+// pools of the database clients opened eagerly, aren't mocked.
+var jobTemplate = template.Must(template.New("job").Parse(`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: keploy-test
+spec:
+  backoffLimit: 0
+  template:
+    spec:
+      restartPolicy: Never
+      shareProcessNamespace: true
+      initContainers:
+        - name: app
+          image: {{.AppImage}}
+          restartPolicy: Always
+{{- if .Command}}
+          command: ["sh", "-c", {{printf "%q" .Command}}]
+{{- end}}
+      containers:
+        - name: keploy
+          image: {{.Image}}
+          args: ["k8s", "test", "--path", "/data"{{if .Bucket}}, "--bucket", "{{.Bucket}}"{{end}}]
+          securityContext:
+            privileged: true
+{{- if .Bucket}}
+          envFrom:
+            - secretRef:
+                name: keploy-s3
+                optional: true
+{{- end}}
+          volumeMounts:
+            - name: keploy
+              mountPath: /data
+            - name: debugfs
+              mountPath: /sys/kernel/debug
+            - name: bpffs
+              mountPath: /sys/fs/bpf
+      volumes:
+        - name: keploy
+{{- if .PVC}}
+          persistentVolumeClaim:
+            claimName: {{.PVC}}
+{{- else}}
+          emptyDir: {}
+{{- end}}
+        - name: debugfs
+          hostPath:
+            path: /sys/kernel/debug
+        - name: bpffs
+          hostPath:
+            path: /sys/fs/bpf
+`))
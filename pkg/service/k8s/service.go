@@ -0,0 +1,25 @@
+// Package k8s provides keploy in kubernetes: recording the app of a pod with keploy as its sidecar, attached to the
+// app through the shared process namespace of the pod, and testing the app with a Job. The test sets and the
+// reports are kept on a persistent volume, or synced with an object store.
+package k8s
+
+import (
+	"context"
+	"io"
+)
+
+type Service interface {
+	// Record records the app until the context is cancelled e.g. by the termination of the pod, and pushes the
+	// test sets to the bucket if any.
+	Record(ctx context.Context) error
+	// Test pulls the test sets from the bucket if any, runs them and pushes the reports back. It fails if any test
+	// set fails, so that the Job fails.
+	Test(ctx context.Context) error
+	// Manifest writes the manifest of the sidecar or of the Job.
+	Manifest(ctx context.Context, kind string, w io.Writer) error
+}
+
+// ServiceFactory creates the record, replay and remote services of the commands.
+type ServiceFactory interface {
+	GetService(ctx context.Context, cmd string) (interface{}, error)
+}
@@ -0,0 +1,262 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/pkg/platform/yaml/testdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Linter struct {
+	logger *zap.Logger
+	config config.Config
+	// cipher decrypts the encrypted testcases and mocks to check their specs
+	cipher *yaml.Cipher
+}
+
+func New(logger *zap.Logger, config config.Config, cipher *yaml.Cipher) Service {
+	return &Linter{
+		logger: logger,
+		config: config,
+		cipher: cipher,
+	}
+}
+
+// testSet collects the issues of a test set along with the testcases needed to check its mocks.
+type testSet struct {
+	id     string
+	issues []Issue
+	tcs    []*models.TestCase
+}
+
+func (ts *testSet) add(severity Severity, file, name, format string, args ...interface{}) {
+	ts.issues = append(ts.issues, Issue{
+		Severity: severity,
+		TestSet:  ts.id,
+		File:     file,
+		Name:     name,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Lint validates the testcases and mocks of the test sets, all the test sets by default, and returns their issues.
+func (l *Linter) Lint(ctx context.Context, testSetIDs []string) ([]Issue, error) {
+	if len(testSetIDs) == 0 {
+		ids, err := yaml.ReadSessionIndices(ctx, l.config.Path, l.logger)
+		if err != nil {
+			utils.LogError(l.logger, err, "failed to get the test sets")
+			return nil, err
+		}
+		testSetIDs = ids
+	}
+	if len(testSetIDs) == 0 {
+		return nil, fmt.Errorf("found no test sets to lint in %s", l.config.Path)
+	}
+
+	var issues []Issue
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		ts := &testSet{id: testSetID}
+		err := l.lintTestCases(ctx, ts)
+		if err != nil {
+			return nil, err
+		}
+		err = l.lintMocks(ctx, ts)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, ts.issues...)
+	}
+	return issues, nil
+}
+
+func (l *Linter) lintTestCases(ctx context.Context, ts *testSet) error {
+	dir := filepath.Join(l.config.Path, ts.id, "tests")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			ts.add(SeverityWarning, dir, "", "the test set has no testcases")
+			return nil
+		}
+		utils.LogError(l.logger, err, "failed to read the testcases", zap.String("test-set", ts.id))
+		return err
+	}
+
+	names := map[string]string{}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" || strings.Contains(entry.Name(), "mocks") {
+			continue
+		}
+		file := filepath.Join(dir, entry.Name())
+		docs, err := yaml.ReadShard(ctx, l.logger, file)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			ts.add(SeverityError, file, "", "the file can't be parsed: %v", err)
+			continue
+		}
+		if len(docs) != 1 {
+			ts.add(SeverityError, file, "", "the file should hold exactly one testcase but holds %d", len(docs))
+			continue
+		}
+		doc := docs[0]
+		if !checkDoc(ts, file, doc) {
+			continue
+		}
+		if fileName := strings.TrimSuffix(entry.Name(), ".yaml"); doc.Name != fileName {
+			ts.add(SeverityWarning, file, doc.Name, "the name of the testcase doesn't match the file name %s", fileName)
+		}
+		if other, ok := names[doc.Name]; ok {
+			ts.add(SeverityError, file, doc.Name, "the testcase name is already used by %s", other)
+		}
+		names[doc.Name] = file
+
+		tc, err := testdb.Decode(doc, l.cipher, zap.NewNop())
+		if err != nil {
+			ts.add(SeverityError, file, doc.Name, "the testcase can't be decoded: %v", err)
+			continue
+		}
+		if tc.Kind == models.HTTP {
+			checkBody(ts, file, doc.Name, "request", tc.HTTPReq.Header, tc.HTTPReq.Body)
+			checkBody(ts, file, doc.Name, "response", tc.HTTPResp.Header, tc.HTTPResp.Body)
+		}
+		checkTimestamps(ts, file, doc.Name, tc.ReqTimestamp(), tc.RespTimestamp())
+		ts.tcs = append(ts.tcs, tc)
+	}
+	return nil
+}
+
+func (l *Linter) lintMocks(ctx context.Context, ts *testSet) error {
+	files, err := yaml.ShardFiles(filepath.Join(l.config.Path, ts.id), "mocks")
+	if err != nil {
+		utils.LogError(l.logger, err, "failed to list the mock files", zap.String("test-set", ts.id))
+		return err
+	}
+
+	names := map[string]string{}
+	for _, file := range files {
+		docs, err := yaml.ReadShard(ctx, l.logger, file)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			ts.add(SeverityError, file, "", "the file can't be parsed: %v", err)
+			continue
+		}
+		for _, doc := range docs {
+			if !checkDoc(ts, file, doc) {
+				continue
+			}
+			if other, ok := names[doc.Name]; ok {
+				ts.add(SeverityError, file, doc.Name, "the mock name is already used in %s", other)
+			}
+			names[doc.Name] = file
+
+			mocks, err := mockdb.DecodeMocks([]*yaml.NetworkTrafficDoc{doc}, l.cipher, zap.NewNop())
+			if err != nil {
+				ts.add(SeverityError, file, doc.Name, "the mock can't be decoded: %v", err)
+				continue
+			}
+			if len(mocks) == 0 {
+				// the kinds of the enterprise version are skipped on purpose
+				if !strings.Contains(string(doc.Kind), "-") {
+					ts.add(SeverityError, file, doc.Name, "the mock of unknown kind %s is skipped during replay", doc.Kind)
+				}
+				continue
+			}
+			mock := mocks[0]
+			if mock.Kind == models.HTTP && mock.Spec.HTTPReq != nil && mock.Spec.HTTPResp != nil {
+				checkBody(ts, file, doc.Name, "request", mock.Spec.HTTPReq.Header, mock.Spec.HTTPReq.Body)
+				checkBody(ts, file, doc.Name, "response", mock.Spec.HTTPResp.Header, mock.Spec.HTTPResp.Body)
+			}
+			checkTimestamps(ts, file, doc.Name, mock.Spec.ReqTimestampMock, mock.Spec.ResTimestampMock)
+			if isOrphaned(mock, ts.tcs) {
+				ts.add(SeverityWarning, file, doc.Name, "the mock wasn't recorded during any testcase, so it is never replayed")
+			}
+		}
+	}
+	return nil
+}
+
+// checkDoc checks the fields common to the testcases and mocks, it returns false if the doc can't be decoded.
+func checkDoc(ts *testSet, file string, doc *yaml.NetworkTrafficDoc) bool {
+	ok := true
+	if doc.Version == "" {
+		ts.add(SeverityError, file, doc.Name, "the version is missing")
+		ok = false
+	}
+	if doc.Kind == "" {
+		ts.add(SeverityError, file, doc.Name, "the kind is missing")
+		ok = false
+	}
+	if doc.Name == "" {
+		ts.add(SeverityError, file, doc.Name, "the name is missing")
+		ok = false
+	}
+	if doc.SchemaVersion > yaml.SchemaVersion {
+		ts.add(SeverityError, file, doc.Name, "the schema version %d is newer than the supported version %d, please upgrade keploy", doc.SchemaVersion, yaml.SchemaVersion)
+		ok = false
+	} else if doc.SchemaVersion < yaml.SchemaVersion {
+		ts.add(SeverityWarning, file, doc.Name, "the schema version is older than the current version %d, run keploy migrate to upgrade it", yaml.SchemaVersion)
+	}
+	return ok
+}
+
+// checkBody reports the bodies which can't be read back during replay, i.e. the bodies which aren't valid utf-8
+// and the json bodies which can't be parsed.
+func checkBody(ts *testSet, file, name, part string, header map[string]string, body string) {
+	if body == "" {
+		return
+	}
+	if !utf8.ValidString(body) {
+		ts.add(SeverityError, file, name, "the %s body is not valid utf-8", part)
+		return
+	}
+	for k, v := range header {
+		if strings.EqualFold(k, "Content-Type") && strings.Contains(strings.ToLower(v), "json") && !json.Valid([]byte(body)) {
+			ts.add(SeverityError, file, name, "the %s body of content type %s is not valid json", part, v)
+		}
+	}
+}
+
+func checkTimestamps(ts *testSet, file, name string, reqTime, respTime time.Time) {
+	if reqTime.IsZero() || respTime.IsZero() {
+		ts.add(SeverityWarning, file, name, "the request or response timestamp is missing")
+		return
+	}
+	if respTime.Before(reqTime) {
+		ts.add(SeverityError, file, name, "the response timestamp %s is before the request timestamp %s", respTime.Format(time.RFC3339Nano), reqTime.Format(time.RFC3339Nano))
+	}
+}
+
+// isOrphaned reports whether the mock is filtered by the time of the testcases during replay, but wasn't recorded
+// during any testcase. The config mocks and the mocks of the kinds matched without filtering are never orphaned.
+func isOrphaned(mock *models.Mock, tcs []*models.TestCase) bool {
+	if len(tcs) == 0 || mock.Spec.ReqTimestampMock.IsZero() || mock.Spec.ResTimestampMock.IsZero() {
+		return false
+	}
+	if len(mockdb.FilterTcsMocks([]*models.Mock{mock}, time.Time{}, time.Time{}, zap.NewNop())) == 0 {
+		return false
+	}
+	for _, tc := range tcs {
+		if mock.Spec.ReqTimestampMock.After(tc.ReqTimestamp()) && mock.Spec.ResTimestampMock.Before(tc.RespTimestamp()) {
+			return false
+		}
+	}
+	return true
+}
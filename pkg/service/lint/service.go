@@ -0,0 +1,28 @@
+// Package lint provides the validation of the recorded testcases and mocks, so that the broken artifacts are
+// caught in CI instead of failing or being silently skipped during replay.
+package lint
+
+import (
+	"context"
+)
+
+type Service interface {
+	Lint(ctx context.Context, testSetIDs []string) ([]Issue, error)
+}
+
+type Severity string
+
+const (
+	// SeverityError is an artifact which fails or is skipped during replay.
+	SeverityError Severity = "error"
+	// SeverityWarning is an artifact which is replayed but is likely not what was intended.
+	SeverityWarning Severity = "warning"
+)
+
+type Issue struct {
+	Severity Severity
+	TestSet  string
+	File     string
+	Name     string // name of the testcase or mock, empty for the issues of the whole file
+	Message  string
+}
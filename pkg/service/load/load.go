@@ -0,0 +1,276 @@
+package load
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxRPS bounds the rate of the load run, the ticker can't keep up with shorter intervals
+	maxRPS = 10000
+	// maxWorkers bounds the requests in flight of the load run
+	maxWorkers = 1000
+)
+
+type Loader struct {
+	logger *zap.Logger
+	testDB TestDB
+	config config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, config config.Config) Service {
+	return &Loader{
+		logger: logger,
+		testDB: testDB,
+		config: config,
+	}
+}
+
+// result is the outcome of a single request sent during the load run.
+type result struct {
+	latency    time.Duration
+	statusCode int
+	// matched is true if the status code is the same as the recorded one
+	matched bool
+	err     error
+}
+
+// Start replays the recorded http testcases against the application at the configured rate
+// until the duration elapses and prints the latency percentiles of the run.
+func (l *Loader) Start(ctx context.Context) error {
+	corpus, err := l.getCorpus(ctx)
+	if err != nil {
+		return err
+	}
+	if len(corpus) == 0 {
+		return errors.New("no http testcases found to generate the load from")
+	}
+	if l.config.Load.RPS == 0 || l.config.Load.RPS > maxRPS {
+		return fmt.Errorf("rps should be between 1 and %d", maxRPS)
+	}
+
+	client := &http.Client{
+		Timeout: time.Second * time.Duration(l.config.Load.APITimeout),
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+		Transport: &http.Transport{
+			MaxIdleConns:        int(l.config.Load.RPS),
+			MaxIdleConnsPerHost: int(l.config.Load.RPS),
+		},
+	}
+
+	l.logger.Info("starting the load run", zap.Int("testcases", len(corpus)), zap.Uint64("rps", l.config.Load.RPS), zap.Duration("duration", l.config.Load.Duration))
+
+	ctx, cancel := context.WithTimeout(ctx, l.config.Load.Duration)
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		results []result
+		wg      sync.WaitGroup
+	)
+	requests := make(chan *models.TestCase)
+	for i := 0; i < l.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for tc := range requests {
+				res := l.send(client, tc)
+				mu.Lock()
+				results = append(results, res)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// the requests are sent at a fixed rate irrespective of the response time of the application (open model), the
+	// rate only drops when all the workers are waiting for the responses
+	ticker := time.NewTicker(time.Second / time.Duration(l.config.Load.RPS))
+	defer ticker.Stop()
+
+	start := time.Now()
+loop:
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+			select {
+			case requests <- corpus[i%len(corpus)]:
+			case <-ctx.Done():
+				break loop
+			}
+		}
+	}
+	close(requests)
+	// waiting for the in-flight requests to complete
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	return l.printReport(results, elapsed)
+}
+
+// workers returns the number of workers sending the requests, enough to keep the rate while the responses take up
+// to the request timeout.
+func (l *Loader) workers() int {
+	if l.config.Load.APITimeout == 0 || l.config.Load.APITimeout > maxWorkers/l.config.Load.RPS {
+		return maxWorkers
+	}
+	return int(l.config.Load.RPS * l.config.Load.APITimeout)
+}
+
+// getCorpus returns the http testcases of the selected test-sets, all the test-sets are used if none is selected.
+func (l *Loader) getCorpus(ctx context.Context) ([]*models.TestCase, error) {
+	testSetIDs := l.config.Load.TestSets
+	if len(testSetIDs) == 0 {
+		var err error
+		testSetIDs, err = l.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(l.logger, err, "failed to get the test-set ids")
+			return nil, err
+		}
+	}
+
+	var corpus []*models.TestCase
+	for _, testSetID := range testSetIDs {
+		testCases, err := l.testDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(l.logger, err, "failed to get the testcases", zap.String("test-set", testSetID))
+			return nil, err
+		}
+		for _, tc := range testCases {
+			if tc.Kind != models.HTTP {
+				continue
+			}
+			if l.config.Load.BaseURL != "" {
+				reqURL, err := pkg.ReplaceBaseURL(tc.HTTPReq.URL, l.config.Load.BaseURL)
+				if err != nil {
+					utils.LogError(l.logger, err, "failed to replace the base url of the testcase", zap.String("testcase", tc.Name), zap.String("test-set", testSetID))
+					return nil, err
+				}
+				tc.HTTPReq.URL = reqURL
+			}
+			corpus = append(corpus, tc)
+		}
+	}
+	return corpus, nil
+}
+
+// send sends the request of the testcase. The request is not shared between the goroutines since
+// the body reader is consumed by the client.
+func (l *Loader) send(client *http.Client, tc *models.TestCase) result {
+	// the requests are not bound to the load run's context so that the in-flight requests can complete
+	req, err := http.NewRequest(string(tc.HTTPReq.Method), tc.HTTPReq.URL, bytes.NewBufferString(tc.HTTPReq.Body))
+	if err != nil {
+		return result{err: err}
+	}
+	req.Header = pkg.ToHTTPHeader(tc.HTTPReq.Header)
+	// the content length is set by the client from the body
+	req.Header.Del("Content-Length")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		l.logger.Debug("failed to send the request", zap.String("testcase", tc.Name), zap.Error(err))
+		return result{latency: time.Since(start), err: err}
+	}
+	// the latency includes reading the complete response body
+	_, err = io.Copy(io.Discard, resp.Body)
+	latency := time.Since(start)
+	if cerr := resp.Body.Close(); cerr != nil {
+		l.logger.Debug("failed to close the response body", zap.Error(cerr))
+	}
+	if err != nil {
+		return result{latency: latency, err: err}
+	}
+	return result{
+		latency:    latency,
+		statusCode: resp.StatusCode,
+		matched:    resp.StatusCode == tc.HTTPResp.StatusCode,
+	}
+}
+
+func (l *Loader) printReport(results []result, elapsed time.Duration) error {
+	var latencies []time.Duration
+	statusCodes := map[int]int{}
+	failed, mismatched := 0, 0
+	for _, res := range results {
+		if res.err != nil {
+			failed++
+			continue
+		}
+		latencies = append(latencies, res.latency)
+		statusCodes[res.statusCode]++
+		if !res.matched {
+			mismatched++
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	if failed > 0 || mismatched > 0 {
+		pp.SetColorScheme(models.FailingColorScheme)
+	} else {
+		pp.SetColorScheme(models.PassingColorScheme)
+	}
+	if _, err := pp.Printf("\n <=========================================> \n  LOAD RUN SUMMARY.\n"+"\tTotal requests: %s\n"+"\tFailed requests: %s\n"+"\tStatus code mismatches: %s\n", len(results), failed, mismatched); err != nil {
+		utils.LogError(l.logger, err, "failed to print the load run summary")
+		return err
+	}
+	fmt.Printf("\tThroughput: %.2f req/s\n", float64(len(results))/elapsed.Seconds())
+
+	if len(latencies) > 0 {
+		fmt.Printf("\n\tLatency\n"+"\t  min: %v\n"+"\t  p50: %v\n"+"\t  p90: %v\n"+"\t  p95: %v\n"+"\t  p99: %v\n"+"\t  max: %v\n", round(latencies[0]), round(percentile(latencies, 50)), round(percentile(latencies, 90)), round(percentile(latencies, 95)), round(percentile(latencies, 99)), round(latencies[len(latencies)-1]))
+	}
+
+	codes := make([]int, 0, len(statusCodes))
+	for code := range statusCodes {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	if len(codes) > 0 {
+		if _, err := pp.Printf("\n\tStatus Code\tRequests\n"); err != nil {
+			utils.LogError(l.logger, err, "failed to print the status codes")
+			return err
+		}
+		for _, code := range codes {
+			if _, err := pp.Printf("\t%s\t\t%s\n", code, statusCodes[code]); err != nil {
+				utils.LogError(l.logger, err, "failed to print the status codes")
+				return err
+			}
+		}
+	}
+	if _, err := pp.Printf("<=========================================> \n\n"); err != nil {
+		utils.LogError(l.logger, err, "failed to print the load run summary")
+		return err
+	}
+	return nil
+}
+
+// percentile returns the nearest-rank percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// round rounds the latency to microseconds for the report.
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
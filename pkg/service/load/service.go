@@ -0,0 +1,17 @@
+// Package load provides the load mode which replays the recorded ingress requests at a configured rate.
+package load
+
+import (
+	"context"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	Start(ctx context.Context) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+}
@@ -0,0 +1,98 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Migrator struct {
+	logger *zap.Logger
+	config config.Config
+	cipher *yaml.Cipher
+}
+
+func New(logger *zap.Logger, config config.Config, cipher *yaml.Cipher) Service {
+	return &Migrator{
+		logger: logger,
+		config: config,
+		cipher: cipher,
+	}
+}
+
+// Migrate upgrades the testcases and mocks of the test sets, all the test sets by default, in place. With dryRun
+// the files to be upgraded are only reported.
+func (m *Migrator) Migrate(ctx context.Context, testSetIDs []string, dryRun bool) error {
+	if len(testSetIDs) == 0 {
+		ids, err := yaml.ReadSessionIndices(ctx, m.config.Path, m.logger)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to get the test sets")
+			return err
+		}
+		testSetIDs = ids
+	}
+	if len(testSetIDs) == 0 {
+		return fmt.Errorf("found no test sets to migrate in %s", m.config.Path)
+	}
+
+	total := 0
+	for _, testSetID := range testSetIDs {
+		files, err := m.files(testSetID)
+		if err != nil {
+			utils.LogError(m.logger, err, "failed to list the files of the test set", zap.String("test-set", testSetID))
+			return err
+		}
+		for _, file := range files {
+			migrated, err := yaml.MigrateFile(ctx, m.logger, m.cipher, file, dryRun)
+			if err != nil {
+				utils.LogError(m.logger, err, "failed to migrate the file", zap.String("file", file))
+				return err
+			}
+			if migrated == 0 {
+				continue
+			}
+			total += migrated
+			if dryRun {
+				m.logger.Info("the file is of an older schema version", zap.String("file", file), zap.Int("docs", migrated))
+				continue
+			}
+			m.logger.Info("migrated the file", zap.String("file", file), zap.Int("docs", migrated))
+		}
+	}
+
+	if dryRun {
+		m.logger.Info(fmt.Sprintf("found %d docs to migrate to the schema version %d", total, yaml.SchemaVersion))
+		return nil
+	}
+	m.logger.Info(fmt.Sprintf("migrated %d docs to the schema version %d", total, yaml.SchemaVersion))
+	return nil
+}
+
+// files returns the testcase files and the mock files of the test set.
+func (m *Migrator) files(testSetID string) ([]string, error) {
+	path := filepath.Join(m.config.Path, testSetID)
+	files, err := yaml.ShardFiles(path, "mocks")
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(path, "tests"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+		files = append(files, filepath.Join(path, "tests", entry.Name()))
+	}
+	return files, nil
+}
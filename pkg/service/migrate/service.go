@@ -0,0 +1,11 @@
+// Package migrate provides the upgrade of the recorded testcases and mocks to the current schema version, so that
+// the suites recorded by an older version of keploy keep replaying after a change of the format.
+package migrate
+
+import (
+	"context"
+)
+
+type Service interface {
+	Migrate(ctx context.Context, testSetIDs []string, dryRun bool) error
+}
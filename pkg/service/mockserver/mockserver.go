@@ -0,0 +1,65 @@
+package mockserver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type MockServer struct {
+	logger *zap.Logger
+	testDB TestDB
+	mockDB MockDB
+	stub   Stub
+	config config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, stub Stub, config config.Config) Service {
+	return &MockServer{
+		logger: logger,
+		testDB: testDB,
+		mockDB: mockDB,
+		stub:   stub,
+		config: config,
+	}
+}
+
+// Serve loads the mocks of the test set, or of all the test sets if none is given, and serves them on the port.
+func (s *MockServer) Serve(ctx context.Context) error {
+	testSetIDs := []string{s.config.MockServer.TestSet}
+	if s.config.MockServer.TestSet == "" {
+		var err error
+		testSetIDs, err = s.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to get the test sets")
+			return err
+		}
+	}
+
+	var mocks []*models.Mock
+	for _, testSetID := range testSetIDs {
+		filtered, err := s.mockDB.GetFilteredMocks(ctx, testSetID, models.BaseTime, time.Now())
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+			return err
+		}
+		unfiltered, err := s.mockDB.GetUnFilteredMocks(ctx, testSetID, models.BaseTime, time.Now())
+		if err != nil {
+			utils.LogError(s.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+			return err
+		}
+		mocks = append(append(mocks, filtered...), unfiltered...)
+	}
+	if len(mocks) == 0 {
+		return fmt.Errorf("no mocks found in %v, record them with keploy record", testSetIDs)
+	}
+	s.logger.Info("loaded the mocks", zap.Strings("test-sets", testSetIDs), zap.Int("mocks", len(mocks)))
+
+	s.stub.SetMocks(mocks)
+	return s.stub.Serve(ctx, fmt.Sprintf(":%d", s.config.MockServer.Port))
+}
@@ -0,0 +1,30 @@
+// Package mockserver serves the recorded mocks of a test set as a stub server, for the clients connecting to it
+// directly instead of an app recorded or tested by keploy.
+package mockserver
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Serve serves the mocks until the context is cancelled.
+	Serve(ctx context.Context) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+}
+
+type MockDB interface {
+	GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+	GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+}
+
+// Stub serves the mocks on an address, implemented by the mock server of the proxy.
+type Stub interface {
+	SetMocks(mocks []*models.Mock)
+	Serve(ctx context.Context, addr string) error
+}
@@ -0,0 +1,125 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// annotation names and describes the next testcase captured during the record session.
+type annotation struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Tags        []string `json:"tags"`
+}
+
+// annotator queues the annotations posted by the person driving the traffic of the app, and applies them to the
+// testcases in the order they are captured.
+type annotator struct {
+	logger  *zap.Logger
+	mu      sync.Mutex
+	pending []annotation
+	names   map[string]int // the names given in the session, to suffix the repeated ones
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func newAnnotator(logger *zap.Logger) *annotator {
+	return &annotator{logger: logger, names: map[string]int{}}
+}
+
+// serve serves POST /keploy/annotate, and POST /keploy/pause and /keploy/resume of the recording, on the localhost
+// port until the context is canceled.
+func (a *annotator) serve(ctx context.Context, port uint32, pause *pauser) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /keploy/annotate", a.annotate)
+	mux.HandleFunc("POST /keploy/pause", pause.handle(true))
+	mux.HandleFunc("POST /keploy/resume", pause.handle(false))
+	srv := &http.Server{
+		Addr:              "127.0.0.1:" + strconv.Itoa(int(port)),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			utils.LogError(a.logger, err, "failed to stop the annotation api")
+		}
+	}()
+
+	a.logger.Info(fmt.Sprintf("name the next testcase with: curl -X POST http://localhost:%d/keploy/annotate -d '{\"name\": \"create-order\", \"description\": \"...\"}'", port))
+	err := srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (a *annotator) annotate(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	var ann annotation
+	if err := json.NewDecoder(r.Body).Decode(&ann); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "the annotation should be {name, description, tags}: " + err.Error()})
+		return
+	}
+	ann.Name = strings.Trim(unsafeNameChars.ReplaceAllString(strings.TrimSpace(ann.Name), "-"), "-")
+	ann.Tags = pkg.SplitTags(strings.Join(ann.Tags, ","))
+	if ann.Name == "" && ann.Description == "" && len(ann.Tags) == 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "missing the name, description or tags of the next testcase"})
+		return
+	}
+
+	a.mu.Lock()
+	a.pending = append(a.pending, ann)
+	pending := len(a.pending)
+	a.mu.Unlock()
+
+	a.logger.Info("the next captured testcase will be annotated", zap.String("name", ann.Name), zap.String("description", ann.Description))
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(map[string]int{"pending": pending})
+}
+
+// apply annotates the testcase with the oldest pending annotation, if any. A name given more than once in the
+// session is suffixed with its count, e.g. login-2, so that the testcases don't overwrite each other.
+func (a *annotator) apply(tc *models.TestCase) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.pending) == 0 {
+		return
+	}
+	ann := a.pending[0]
+	a.pending = a.pending[1:]
+
+	if ann.Name != "" {
+		a.names[ann.Name]++
+		tc.Name = ann.Name
+		if n := a.names[ann.Name]; n > 1 {
+			tc.Name = fmt.Sprintf("%s-%d", ann.Name, n)
+		}
+	}
+	if ann.Description != "" {
+		tc.Description = ann.Description
+	}
+	for _, tag := range ann.Tags {
+		if !tc.HasAnyTag([]string{tag}) {
+			tc.Tags = append(tc.Tags, tag)
+		}
+	}
+}
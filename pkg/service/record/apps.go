@@ -0,0 +1,301 @@
+package record
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+// AppDBs returns the storage of the testcases and mocks of the app of the name, for the apps recorded together.
+type AppDBs func(name string) (TestDB, MockDB)
+
+// NewApps returns the record service of the apps of the config recorded together, each into the storage AppDBs
+// returns for it.
+func NewApps(logger *zap.Logger, appDBs AppDBs, telemetry Telemetry, instrumentation Instrumentation, config config.Config) Service {
+	return &Recorder{
+		logger:          logger,
+		appDBs:          appDBs,
+		telemetry:       telemetry,
+		instrumentation: instrumentation,
+		config:          config,
+	}
+}
+
+// recordedApp is one of the apps recorded together.
+type recordedApp struct {
+	name      string
+	testSetID string
+	m         sync.Mutex
+	testCount int
+	mockCount map[string]int
+}
+
+type appError struct {
+	name string
+	models.AppError
+}
+
+// startApps records the apps of the config together, they share the hooks and the proxy, which route the calls to
+// the app whose process made them, and each is recorded into a new test set of its own storage.
+func (r *Recorder) startApps(ctx context.Context) error {
+	sources, err := newSourceFilter(r.logger, r.config.Record)
+	if err != nil {
+		utils.LogError(r.logger, err, "invalid source ips to record")
+		return err
+	}
+	redact, err := newRedactor(r.config.Record)
+	if err != nil {
+		utils.LogError(r.logger, err, "invalid redaction rules")
+		return err
+	}
+	dependencies, err := newDependencyNamer(r.config.Record.Dependencies)
+	if err != nil {
+		utils.LogError(r.logger, err, "invalid dependencies")
+		return err
+	}
+
+	errGrp, _ := errgroup.WithContext(ctx)
+	ctx = context.WithValue(ctx, models.ErrGroupKey, errGrp)
+
+	runAppErrGrp, _ := errgroup.WithContext(ctx)
+	runAppCtx := context.WithoutCancel(ctx)
+	runAppCtx, runAppCtxCancel := context.WithCancel(runAppCtx)
+
+	hookErrGrp, _ := errgroup.WithContext(ctx)
+	hookCtx := context.WithoutCancel(ctx)
+	hookCtx, hookCtxCancel := context.WithCancel(hookCtx)
+	hookCtx = context.WithValue(hookCtx, models.ErrGroupKey, hookErrGrp)
+
+	var stopReason string
+	var apps []*recordedApp
+	var appErrChan = make(chan appError, len(r.config.Record.Apps))
+	var insertErrChan = make(chan error, 10)
+	var mockDBs []MockDB
+
+	defer func() {
+		// the mocks files are closed once the apps and the hooks are stopped and the last mocks are inserted
+		defer func() {
+			for _, mockDB := range mockDBs {
+				r.closeMocks(mockDB)
+			}
+		}()
+		select {
+		case <-ctx.Done():
+			for _, a := range apps {
+				a.m.Lock()
+				r.telemetry.RecordedTestSuite(a.testSetID, a.testCount, a.mockCount)
+				a.m.Unlock()
+			}
+		default:
+			err := utils.StopSession(ctx, r.logger, stopReason)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to stop recording")
+			}
+		}
+		runAppCtxCancel()
+		err := runAppErrGrp.Wait()
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to stop the apps")
+		}
+		hookCtxCancel()
+		err = hookErrGrp.Wait()
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to stop hooks")
+		}
+		err = errGrp.Wait()
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to stop recording")
+		}
+	}()
+
+	ids := make([]uint64, 0, len(r.config.Record.Apps))
+	for _, app := range r.config.Record.Apps {
+		testDB, mockDB := r.appDBs(app.Name)
+		mockDBs = append(mockDBs, mockDB)
+		testSetIDs, err := testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			stopReason = fmt.Sprintf("failed to get the test sets of the app %s", app.Name)
+			utils.LogError(r.logger, err, stopReason)
+			return errors.New(stopReason)
+		}
+		a := &recordedApp{
+			name:      app.Name,
+			testSetID: pkg.NewID(testSetIDs, models.TestSetPattern),
+			mockCount: map[string]int{},
+		}
+
+		appID, err := r.instrumentation.Setup(ctx, app.Command, models.SetupOptions{RandomSeed: r.config.RandomSeed, Client: r.config.Client})
+		if err != nil {
+			stopReason = fmt.Sprintf("failed setting up the environment of the app %s", app.Name)
+			utils.LogError(r.logger, err, stopReason)
+			return errors.New(stopReason)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		// the hooks are loaded and the proxy started for the first app, the next ones are added to them
+		err = r.instrumentation.Hook(hookCtx, appID, models.HookOptions{Mode: models.MODE_RECORD, EnableTesting: r.config.EnableTesting})
+		if err != nil {
+			stopReason = fmt.Sprintf("failed to start the hooks and proxy of the app %s", app.Name)
+			utils.LogError(r.logger, err, stopReason)
+			if err == context.Canceled {
+				return err
+			}
+			return errors.New(stopReason)
+		}
+
+		incomingChan, err := r.instrumentation.GetIncoming(ctx, appID, models.IncomingOptions{})
+		if err != nil {
+			stopReason = fmt.Sprintf("failed to get the incoming frames of the app %s", app.Name)
+			utils.LogError(r.logger, err, stopReason)
+			if err == context.Canceled {
+				return err
+			}
+			return errors.New(stopReason)
+		}
+		// each of the apps is sampled on its own
+		samples := newSampler(r.config.Record)
+		errGrp.Go(func() error {
+			defer func() {
+				for _, tc := range samples.flush(a.testSetID) {
+					redact.testCase(tc)
+					if err := testDB.InsertTestCase(context.WithoutCancel(ctx), tc, a.testSetID); err != nil {
+						utils.LogError(r.logger, err, "failed to insert the sampled testcase", zap.String("app", a.name))
+						continue
+					}
+					a.m.Lock()
+					a.testCount++
+					a.m.Unlock()
+					r.telemetry.RecordedTestAndMocks()
+				}
+			}()
+			for {
+				var testCase *models.TestCase
+				var ok bool
+				select {
+				case <-ctx.Done():
+					return nil
+				case testCase, ok = <-incomingChan:
+					if !ok {
+						return nil
+					}
+				}
+				if !sources.keep(testCase) {
+					continue
+				}
+				for _, tc := range samples.sample(testCase, a.testSetID) {
+					redact.testCase(tc)
+					err := testDB.InsertTestCase(ctx, tc, a.testSetID)
+					if err != nil {
+						if err == context.Canceled {
+							continue
+						}
+						insertErrChan <- err
+						continue
+					}
+					a.m.Lock()
+					a.testCount++
+					a.m.Unlock()
+					r.telemetry.RecordedTestAndMocks()
+				}
+			}
+		})
+
+		outgoingChan, err := r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{ReadTimeout: r.config.ProxyReadTimeout})
+		if err != nil {
+			stopReason = fmt.Sprintf("failed to get the outgoing frames of the app %s", app.Name)
+			utils.LogError(r.logger, err, stopReason)
+			if err == context.Canceled {
+				return err
+			}
+			return errors.New(stopReason)
+		}
+		errGrp.Go(func() error {
+			for mock := range outgoingChan {
+				redact.mock(mock)
+				dependencies.label(mock)
+				err := mockDB.InsertMock(ctx, mock, a.testSetID)
+				if err != nil {
+					if err == context.Canceled {
+						continue
+					}
+					insertErrChan <- err
+					continue
+				}
+				a.m.Lock()
+				a.mockCount[mock.GetKind()]++
+				a.m.Unlock()
+				r.telemetry.RecordedTestCaseMock(mock.GetKind())
+			}
+			return nil
+		})
+
+		apps = append(apps, a)
+		ids = append(ids, appID)
+		r.logger.Info("recording the app", zap.String("app", app.Name), zap.String("testSet", a.testSetID))
+	}
+
+	// running the apps once they are all hooked, so that none of their calls is routed to another app
+	for i, appID := range ids {
+		name := apps[i].name
+		runAppErrGrp.Go(func() error {
+			appErr := r.instrumentation.Run(runAppCtx, appID, models.RunOptions{})
+			if appErr.AppErrorType == models.ErrCtxCanceled {
+				return nil
+			}
+			appErrChan <- appError{name: name, AppError: appErr}
+			return nil
+		})
+	}
+
+	if r.config.Record.RecordTimer != 0 {
+		errGrp.Go(func() error {
+			r.logger.Info("Setting a timer of " + r.config.Record.RecordTimer.String() + " for recording")
+			select {
+			case <-time.After(r.config.Record.RecordTimer):
+				r.logger.Warn("Time up! Stopping keploy")
+				err := utils.StopSession(ctx, r.logger, "Time up! Stopping keploy")
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to stop recording")
+					return errors.New("failed to stop recording")
+				}
+			case <-ctx.Done():
+			}
+			return nil
+		})
+	}
+
+	// keploy stops once any of the apps stops
+	select {
+	case appErr := <-appErrChan:
+		switch appErr.AppErrorType {
+		case models.ErrAppStopped, models.ErrTestBinStopped:
+			stopReason = fmt.Sprintf("the app %s stopped, hence stopping keploy", appErr.name)
+			r.logger.Warn(stopReason, zap.Error(appErr))
+			return nil
+		case models.ErrCommandError:
+			stopReason = fmt.Sprintf("error in running the app %s, hence stopping keploy", appErr.name)
+		default:
+			stopReason = fmt.Sprintf("the app %s terminated unexpectedly hence stopping keploy, please check its logs if this behaviour is not expected", appErr.name)
+		}
+		err = appErr.Err
+	case err = <-insertErrChan:
+		stopReason = "error while inserting a testcase or a mock into db, hence stopping keploy"
+	case <-ctx.Done():
+		return nil
+	}
+	utils.LogError(r.logger, err, stopReason)
+	return errors.New(stopReason)
+}
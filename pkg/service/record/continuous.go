@@ -0,0 +1,169 @@
+package record
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// quotaInterval is how often the continuous recording checks the size of the keploy directory.
+const quotaInterval = time.Minute
+
+// rotator holds the test set the testcases and mocks are recorded into. In the continuous recording it moves to a
+// new test set every rotation and deletes the oldest test sets it recorded once the keploy directory is over the
+// quota, the test sets recorded before it are never deleted.
+type rotator struct {
+	logger   *zap.Logger
+	testDB   TestDB
+	path     string
+	rotation time.Duration
+	maxDisk  int64
+	mu       sync.RWMutex
+	id       string
+	// created are the test sets recorded by the rotator from the oldest, the last one being the current one
+	created []string
+	// rotated receives the test set recorded into before each rotation, to insert the sample of its testcases
+	rotated chan string
+}
+
+func newRotator(logger *zap.Logger, testDB TestDB, path string, rotation time.Duration, maxDiskSize uint64, testSetID string) *rotator {
+	return &rotator{
+		logger:   logger,
+		testDB:   testDB,
+		path:     path,
+		rotation: rotation,
+		maxDisk:  int64(maxDiskSize) * 1024 * 1024,
+		id:       testSetID,
+		created:  []string{testSetID},
+		rotated:  make(chan string, 1),
+	}
+}
+
+// current returns the test set recorded into.
+func (r *rotator) current() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.id
+}
+
+// run rotates the test set and enforces the disk quota until the context is canceled.
+func (r *rotator) run(ctx context.Context) error {
+	rotate := time.NewTicker(r.rotation)
+	defer rotate.Stop()
+	quota := time.NewTicker(quotaInterval)
+	defer quota.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-rotate.C:
+			r.rotate(ctx)
+		case <-quota.C:
+			r.enforceQuota()
+		}
+	}
+}
+
+func (r *rotator) rotate(ctx context.Context) {
+	testSetIDs, err := r.testDB.GetAllTestSetIDs(ctx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to rotate the test set, recording into the same one")
+		return
+	}
+	// the test set is only created with its first testcase or mock, an empty one is kept
+	next := pkg.NewID(testSetIDs, models.TestSetPattern)
+	r.mu.Lock()
+	prev := r.id
+	if next != prev {
+		r.id = next
+		r.created = append(r.created, next)
+	}
+	r.mu.Unlock()
+	if next != prev {
+		r.logger.Info("rotated the recorded test set", zap.String("from", prev), zap.String("to", next))
+		select {
+		case r.rotated <- prev:
+		case <-ctx.Done():
+		}
+	}
+	r.enforceQuota()
+}
+
+// enforceQuota deletes the oldest test sets recorded by the rotator, never the current one, while the keploy
+// directory is over the quota.
+func (r *rotator) enforceQuota() {
+	if r.maxDisk == 0 {
+		return
+	}
+	size, err := dirSize(r.path)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the size of the keploy directory")
+		return
+	}
+	if size <= r.maxDisk {
+		return
+	}
+	// the test sets are only rotated by the same goroutine, the lock only guards the current test set of the recording
+	r.mu.RLock()
+	created := append([]string(nil), r.created...)
+	r.mu.RUnlock()
+	var kept []string
+	for i, id := range created {
+		// the current test set is the last one
+		if size <= r.maxDisk || i == len(created)-1 {
+			kept = append(kept, id)
+			continue
+		}
+		dir := filepath.Join(r.path, id)
+		setSize, err := dirSize(dir)
+		if os.IsNotExist(err) {
+			// the test set is only created with its first testcase or mock
+			continue
+		}
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the size of the test set", zap.String("testSet", id))
+			kept = append(kept, id)
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			utils.LogError(r.logger, err, "failed to delete the test set over the disk quota", zap.String("testSet", id))
+			kept = append(kept, id)
+			continue
+		}
+		size -= setSize
+		r.logger.Info("deleted the oldest recorded test set, the keploy directory is over the disk quota", zap.String("testSet", id), zap.Uint64("maxDiskSizeMB", uint64(r.maxDisk/1024/1024)))
+	}
+	r.mu.Lock()
+	r.created = kept
+	r.mu.Unlock()
+	if size > r.maxDisk {
+		r.logger.Warn("the keploy directory is still over the disk quota with only the current test set of the recording and the test sets recorded before it left, lower the rotation or the quota", zap.String("testSet", created[len(created)-1]))
+	}
+}
+
+func dirSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
@@ -0,0 +1,113 @@
+package record
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// dependencyHost is a host of a named dependency, an empty host matches any host and an empty port any port.
+type dependencyHost struct {
+	host string
+	port string
+}
+
+type dependencyRule struct {
+	name  string
+	hosts []dependencyHost
+}
+
+// dependencyNamer labels the recorded mocks with the name of their dependency, by the rules of the config or else
+// by their host, so that the mocks of a large mocks file can be told apart.
+type dependencyNamer struct {
+	rules []dependencyRule
+}
+
+func newDependencyNamer(deps []config.Dependency) (*dependencyNamer, error) {
+	n := &dependencyNamer{}
+	for _, dep := range deps {
+		if dep.Name == "" {
+			return nil, errors.New("missing the name of the dependency")
+		}
+		if len(dep.Hosts) == 0 {
+			return nil, fmt.Errorf("missing the hosts of the dependency %s", dep.Name)
+		}
+		rule := dependencyRule{name: dep.Name}
+		for _, h := range dep.Hosts {
+			host, port := splitHostPort(strings.ToLower(strings.TrimSpace(h)))
+			if _, err := path.Match(host, ""); err != nil {
+				return nil, fmt.Errorf("invalid host %q of the dependency %s: %w", h, dep.Name, err)
+			}
+			rule.hosts = append(rule.hosts, dependencyHost{host: host, port: port})
+		}
+		n.rules = append(n.rules, rule)
+	}
+	return n, nil
+}
+
+// label sets the dependency of the mock in its metadata, the mocks without a host nor a destination aren't labelled.
+func (n *dependencyNamer) label(mock *models.Mock) {
+	name := n.name(mock)
+	if name == "" {
+		return
+	}
+	if mock.Spec.Metadata == nil {
+		mock.Spec.Metadata = map[string]string{}
+	}
+	mock.Spec.Metadata[models.DependencyKey] = name
+}
+
+// name returns the name of the first rule matching the host or the destination ip of the mock, and their port, or
+// else the host of the mock, or its destination.
+func (n *dependencyNamer) name(mock *models.Mock) string {
+	host, port := mockHostPort(mock)
+	ip, destPort := splitHostPort(mock.Spec.Metadata[models.DestinationAddrKey])
+	if destPort != "" {
+		port = destPort
+	}
+	for _, rule := range n.rules {
+		for _, h := range rule.hosts {
+			if h.port != "" && h.port != port {
+				continue
+			}
+			if h.host == "" || h.host == "*" || matchHost(h.host, host) || matchHost(h.host, ip) {
+				return rule.name
+			}
+		}
+	}
+	if host != "" {
+		return host
+	}
+	return mock.Spec.Metadata[models.DestinationAddrKey]
+}
+
+// mockHostPort returns the server name of the TLS connection of the mock, or else the Host header of its http
+// request, and the port of the Host header.
+func mockHostPort(mock *models.Mock) (string, string) {
+	if sni := mock.Spec.Metadata[models.SNIKey]; sni != "" {
+		return strings.ToLower(sni), ""
+	}
+	return splitHostPort(strings.ToLower(mock.Spec.Metadata["host"]))
+}
+
+func matchHost(pattern, host string) bool {
+	if host == "" {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.ToLower(host))
+	return ok
+}
+
+// splitHostPort splits the address into its host and port, the port is empty if the address has none.
+func splitHostPort(addr string) (string, string) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, ""
+	}
+	return host, port
+}
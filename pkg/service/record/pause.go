@@ -0,0 +1,153 @@
+package record
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// pauser drops the testcases and mocks captured while the recording is paused, e.g. during the bursts of a cron job
+// whose traffic is noise, until it is resumed. The app and the hooks keep running meanwhile. The recording is paused
+// and resumed by pauseSignal and resumeSignal, by keploy record pause and resume through the api of the session, and
+// by the terminal ui.
+type pauser struct {
+	logger *zap.Logger
+	mutex  sync.Mutex
+	paused bool
+	since  time.Time
+	// tests and mocks are the ones dropped since the recording was paused
+	tests int
+	mocks int
+	// onChange is called with the new state once the recording is paused or resumed, if set
+	onChange func(paused bool)
+}
+
+func newPauser(logger *zap.Logger) *pauser {
+	return &pauser{logger: logger}
+}
+
+// set pauses or resumes the recording, by names what did it for the logs. It returns false if it already was.
+func (p *pauser) set(paused bool, by string) bool {
+	p.mutex.Lock()
+	if p.paused == paused {
+		p.mutex.Unlock()
+		return false
+	}
+	p.paused = paused
+	if paused {
+		p.since = time.Now()
+		p.tests, p.mocks = 0, 0
+		p.logger.Info("⏸️ Keploy has paused the recording, the testcases and mocks are dropped until it is resumed", zap.String("by", by))
+	} else {
+		p.logger.Info("▶️ Keploy has resumed the recording", zap.String("by", by), zap.Duration("paused", time.Since(p.since).Round(time.Millisecond)), zap.Int("droppedTestcases", p.tests), zap.Int("droppedMocks", p.mocks))
+	}
+	onChange := p.onChange
+	p.mutex.Unlock()
+	if onChange != nil {
+		onChange(paused)
+	}
+	return true
+}
+
+func (p *pauser) toggle(by string) {
+	p.mutex.Lock()
+	paused := p.paused
+	p.mutex.Unlock()
+	p.set(!paused, by)
+}
+
+// skipTest reports whether the testcase is dropped as the recording is paused.
+func (p *pauser) skipTest() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.paused {
+		p.tests++
+	}
+	return p.paused
+}
+
+// skipMock reports whether the mock is dropped as the recording is paused.
+func (p *pauser) skipMock() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.paused {
+		p.mocks++
+	}
+	return p.paused
+}
+
+// watch pauses and resumes the recording on pauseSignal and resumeSignal until the context is canceled, the signals
+// don't exist on windows.
+func (p *pauser) watch(ctx context.Context) {
+	if pauseSignal == nil {
+		return
+	}
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, pauseSignal, resumeSignal)
+	defer signal.Stop(sigs)
+	p.logger.Info(fmt.Sprintf("pause the recording with: kill -%s %d, and resume it with: kill -%s %d", signalName(pauseSignal), os.Getpid(), signalName(resumeSignal), os.Getpid()))
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-sigs:
+			p.set(sig == pauseSignal, signalName(sig))
+		}
+	}
+}
+
+// handle serves POST /keploy/pause and POST /keploy/resume of the api of the session.
+func (p *pauser) handle(paused bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		changed := p.set(paused, "the api")
+		_ = json.NewEncoder(w).Encode(map[string]bool{"paused": paused, "changed": changed})
+	}
+}
+
+// SetPaused pauses or resumes the recording session serving its api on the localhost port, for keploy record pause
+// and resume.
+func SetPaused(ctx context.Context, logger *zap.Logger, port uint32, paused bool) error {
+	action := "resume"
+	if paused {
+		action = "pause"
+	}
+	url := "http://127.0.0.1:" + strconv.Itoa(int(port)) + "/keploy/" + action
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("no recording session is serving its api on the port %d, is keploy record running with the same --annotatePort? %w", port, err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close the response body")
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to %s the recording, the session responded with %s", action, resp.Status)
+	}
+	var state struct {
+		Changed bool `json:"changed"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&state); err != nil {
+		return fmt.Errorf("failed to decode the response of the session: %w", err)
+	}
+	if !state.Changed {
+		logger.Info(fmt.Sprintf("the recording already was %sd", action))
+		return nil
+	}
+	logger.Info(fmt.Sprintf("the recording is %sd", action))
+	return nil
+}
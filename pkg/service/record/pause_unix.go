@@ -0,0 +1,24 @@
+//go:build !windows
+
+package record
+
+import (
+	"os"
+	"syscall"
+)
+
+// pauseSignal and resumeSignal pause and resume the recording.
+var (
+	pauseSignal  os.Signal = syscall.SIGUSR1
+	resumeSignal os.Signal = syscall.SIGUSR2
+)
+
+func signalName(sig os.Signal) string {
+	switch sig {
+	case syscall.SIGUSR1:
+		return "USR1"
+	case syscall.SIGUSR2:
+		return "USR2"
+	}
+	return sig.String()
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package record
+
+import "os"
+
+// the recording is paused and resumed only through the api of the session and the terminal ui on windows, which has
+// no user signals.
+var (
+	pauseSignal  os.Signal
+	resumeSignal os.Signal
+)
+
+func signalName(sig os.Signal) string {
+	return sig.String()
+}
@@ -5,12 +5,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 
 	"time"
 
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 
 	"go.keploy.io/server/v2/utils"
 	"go.uber.org/zap"
@@ -24,6 +26,8 @@ type Recorder struct {
 	telemetry       Telemetry
 	instrumentation Instrumentation
 	config          config.Config
+	// appDBs is the storage of each of the apps of the config recorded together, instead of testDB and mockDB
+	appDBs AppDBs
 }
 
 func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, telemetry Telemetry, instrumentation Instrumentation, config config.Config) Service {
@@ -38,6 +42,66 @@ func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, telemetry Telemetry,
 }
 
 func (r *Recorder) Start(ctx context.Context) error {
+	ctx, span := tracing.Start(ctx, "record")
+	defer span.End()
+
+	if err := utils.RunLifecycleHook(ctx, r.logger, "preRecord", r.config.Hooks.PreRecord); err != nil {
+		utils.LogError(r.logger, err, "failed to run the preRecord hook")
+		return err
+	}
+	// the postRecord hook runs after the app and the hooks are stopped, with the context canceled by then
+	defer func() {
+		if err := utils.RunLifecycleHook(context.WithoutCancel(ctx), r.logger, "postRecord", r.config.Hooks.PostRecord); err != nil {
+			utils.LogError(r.logger, err, "failed to run the postRecord hook")
+		}
+	}()
+
+	if len(r.config.Record.Apps) > 0 {
+		return r.startApps(ctx)
+	}
+	// the mocks files are closed once the app and the hooks are stopped and the last mocks are inserted
+	defer r.closeMocks(r.mockDB)
+
+	sources, err := newSourceFilter(r.logger, r.config.Record)
+	if err != nil {
+		utils.LogError(r.logger, err, "invalid source ips to record")
+		return err
+	}
+	redact, err := newRedactor(r.config.Record)
+	if err != nil {
+		utils.LogError(r.logger, err, "invalid redaction rules")
+		return err
+	}
+	dependencies, err := newDependencyNamer(r.config.Record.Dependencies)
+	if err != nil {
+		utils.LogError(r.logger, err, "invalid dependencies")
+		return err
+	}
+
+	// pause drops the testcases and mocks captured while the recording is paused
+	pause := newPauser(r.logger)
+
+	// ui draws the captured testcases and mocks over the terminal, nil if it is disabled. It is closed once the
+	// session is stopped, and the mocks of the discarded testcases are removed then as nothing is recorded anymore.
+	var ui *tui
+	if r.config.Record.TUI {
+		ui = newTUI(os.Stdout, os.Stdin)
+		pause.onChange = ui.setPaused
+		ui.start(func() {
+			r.discardLast(ctx, ui)
+		}, func() {
+			pause.toggle("the terminal ui")
+		}, func() {
+			if err := utils.StopSession(ctx, r.logger, "stopped from the terminal ui"); err != nil {
+				utils.LogError(r.logger, err, "failed to stop recording")
+			}
+		})
+		defer func() {
+			ui.close()
+			r.discardMocks(context.WithoutCancel(ctx), ui)
+		}()
+	}
+	go pause.watch(ctx)
 
 	// creating error group to manage proper shutdown of all the go routines and to propagate the error to the caller
 	errGrp, _ := errgroup.WithContext(ctx)
@@ -74,7 +138,7 @@ func (r *Recorder) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			r.telemetry.RecordedTestSuite(newTestSetID, testCount, mockCountMap)
 		default:
-			err := utils.Stop(r.logger, stopReason)
+			err := utils.StopSession(ctx, r.logger, stopReason)
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to stop recording")
 			}
@@ -109,7 +173,9 @@ func (r *Recorder) Start(ctx context.Context) error {
 	newTestSetID = pkg.NewID(testSetIDs, models.TestSetPattern)
 
 	// setting up the environment for recording
-	appID, err = r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
+	setupCtx, setupSpan := tracing.Start(ctx, "setup")
+	appID, err = r.instrumentation.Setup(setupCtx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay, RandomSeed: r.config.RandomSeed, Client: r.config.Client})
+	tracing.End(setupSpan, err)
 	if err != nil {
 		stopReason = "failed setting up the environment"
 		utils.LogError(r.logger, err, stopReason)
@@ -122,7 +188,10 @@ func (r *Recorder) Start(ctx context.Context) error {
 		return nil
 	default:
 		// Starting the hooks and proxy
+		// the hooks and proxy outlive the span, so the spans of the mock matching are the children of the record span
+		_, loadSpan := tracing.Start(hookCtx, "hook load")
 		err = r.instrumentation.Hook(hookCtx, appID, models.HookOptions{Mode: models.MODE_RECORD, EnableTesting: r.config.EnableTesting})
+		tracing.End(loadSpan, err)
 		if err != nil {
 			stopReason = "failed to start the hooks and proxy"
 			utils.LogError(r.logger, err, stopReason)
@@ -144,24 +213,88 @@ func (r *Recorder) Start(ctx context.Context) error {
 		return fmt.Errorf(stopReason)
 	}
 
+	var annotations *annotator
+	if r.config.Record.AnnotatePort != 0 {
+		annotations = newAnnotator(r.logger)
+		errGrp.Go(func() error {
+			// the session isn't stopped if the port is taken, the testcases are named test-1..N instead
+			if err := annotations.serve(ctx, r.config.Record.AnnotatePort, pause); err != nil {
+				r.logger.Warn("the testcases can't be annotated nor the recording paused through the api", zap.Error(err))
+			}
+			return nil
+		})
+	}
+
+	testSets := newRotator(r.logger, r.testDB, r.config.Path, r.config.Record.Rotation, r.config.Record.MaxDiskSize, newTestSetID)
+	if r.config.Record.Continuous {
+		r.logger.Info("recording continuously until stopped", zap.Duration("rotation", r.config.Record.Rotation), zap.Uint64("maxDiskSizeMB", r.config.Record.MaxDiskSize))
+		errGrp.Go(func() error {
+			return testSets.run(ctx)
+		})
+	}
+
+	samples := newSampler(r.config.Record)
+	// insertSample inserts the reservoir of the test set into it, once it is rotated or the session ends
+	insertSample := func(ctx context.Context, testSetID string) {
+		for _, tc := range samples.flush(testSetID) {
+			redact.testCase(tc)
+			if err := r.testDB.InsertTestCase(ctx, tc, testSetID); err != nil {
+				utils.LogError(r.logger, err, "failed to insert the sampled testcase", zap.String("testSet", testSetID))
+				continue
+			}
+			if ui != nil {
+				ui.testCaptured(testSetID, tc)
+			}
+			testCount++
+			r.telemetry.RecordedTestAndMocks()
+		}
+	}
 	errGrp.Go(func() error {
-		for testCase := range incomingChan {
-			err := r.testDB.InsertTestCase(ctx, testCase, newTestSetID)
-			if err != nil {
-				if err == context.Canceled {
-					continue
+		// the reservoirs left are inserted once the session ends, with the context canceled by then
+		defer func() {
+			for _, testSetID := range samples.testSets() {
+				insertSample(context.WithoutCancel(ctx), testSetID)
+			}
+		}()
+		for {
+			var testCase *models.TestCase
+			var ok bool
+			select {
+			case <-ctx.Done():
+				return nil
+			case testSetID := <-testSets.rotated:
+				insertSample(ctx, testSetID)
+				continue
+			case testCase, ok = <-incomingChan:
+				if !ok {
+					return nil
+				}
+			}
+			if pause.skipTest() || !sources.keep(testCase) {
+				continue
+			}
+			annotations.apply(testCase)
+			testSetID := testSets.current()
+			for _, tc := range samples.sample(testCase, testSetID) {
+				redact.testCase(tc)
+				err := r.testDB.InsertTestCase(ctx, tc, testSetID)
+				if err != nil {
+					if err == context.Canceled {
+						continue
+					}
+					insertTestErrChan <- err
+				} else {
+					if ui != nil {
+						ui.testCaptured(testSetID, tc)
+					}
+					testCount++
+					r.telemetry.RecordedTestAndMocks()
 				}
-				insertTestErrChan <- err
-			} else {
-
-				testCount++
-				r.telemetry.RecordedTestAndMocks()
 			}
 		}
-		return nil
 	})
 
-	outgoingChan, err = r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{})
+	outgoingChan, err = r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{ReadTimeout: r.config.ProxyReadTimeout})
 	if err != nil {
 		stopReason = "failed to get outgoing frames"
 		utils.LogError(r.logger, err, stopReason)
@@ -172,13 +305,22 @@ func (r *Recorder) Start(ctx context.Context) error {
 	}
 	errGrp.Go(func() error {
 		for mock := range outgoingChan {
-			err := r.mockDB.InsertMock(ctx, mock, newTestSetID)
+			if pause.skipMock() {
+				continue
+			}
+			redact.mock(mock)
+			dependencies.label(mock)
+			testSetID := testSets.current()
+			err := r.mockDB.InsertMock(ctx, mock, testSetID)
 			if err != nil {
 				if err == context.Canceled {
 					continue
 				}
 				insertMockErrChan <- err
 			} else {
+				if ui != nil {
+					ui.mockRecorded(testSetID, mock)
+				}
 				mockCountMap[mock.GetKind()]++
 				r.telemetry.RecordedTestCaseMock(mock.GetKind())
 			}
@@ -211,7 +353,7 @@ func (r *Recorder) Start(ctx context.Context) error {
 			select {
 			case <-timer:
 				r.logger.Warn("Time up! Stopping keploy")
-				err := utils.Stop(r.logger, "Time up! Stopping keploy")
+				err := utils.StopSession(ctx, r.logger, "Time up! Stopping keploy")
 				if err != nil {
 					utils.LogError(r.logger, err, "failed to stop recording")
 					return errors.New("failed to stop recording")
@@ -261,12 +403,13 @@ func (r *Recorder) StartMock(ctx context.Context) error {
 	g, ctx := errgroup.WithContext(ctx)
 	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
 	var stopReason string
+	defer r.closeMocks(r.mockDB)
 	defer func() {
 		select {
 		case <-ctx.Done():
 			break
 		default:
-			err := utils.Stop(r.logger, stopReason)
+			err := utils.StopSession(ctx, r.logger, stopReason)
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to stop recording")
 			}
@@ -279,7 +422,7 @@ func (r *Recorder) StartMock(ctx context.Context) error {
 	var outgoingChan <-chan *models.Mock
 	var insertMockErrChan = make(chan error)
 
-	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
+	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay, RandomSeed: r.config.RandomSeed, Client: r.config.Client})
 	if err != nil {
 		stopReason = "failed to exeute mock record due to error while setting up the environment"
 		utils.LogError(r.logger, err, stopReason)
@@ -292,7 +435,7 @@ func (r *Recorder) StartMock(ctx context.Context) error {
 		return fmt.Errorf(stopReason)
 	}
 
-	outgoingChan, err = r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{})
+	outgoingChan, err = r.instrumentation.GetOutgoing(ctx, appID, models.OutgoingOptions{ReadTimeout: r.config.ProxyReadTimeout})
 	if err != nil {
 		stopReason = "failed to get outgoing frames"
 		utils.LogError(r.logger, err, stopReason)
@@ -325,6 +468,12 @@ func (r *Recorder) StartMock(ctx context.Context) error {
 	return fmt.Errorf(stopReason)
 }
 
+func (r *Recorder) closeMocks(mockDB MockDB) {
+	if err := mockDB.Close(); err != nil {
+		utils.LogError(r.logger, err, "failed to close the mocks files")
+	}
+}
+
 func (r *Recorder) ReRecord(ctx context.Context) error {
 
 	tcs, err := r.testDB.GetTestCases(ctx, r.config.ReRecord)
@@ -371,13 +520,13 @@ func (r *Recorder) ReRecord(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			err = utils.Stop(r.logger, "Re-recorded testcases successfully")
+			err = utils.StopSession(ctx, r.logger, "Re-recorded testcases successfully")
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to stop recording")
 			}
 		}
 	} else {
-		err = utils.Stop(r.logger, "Failed to re-record some testcases")
+		err = utils.StopSession(ctx, r.logger, "Failed to re-record some testcases")
 		if err != nil {
 			utils.LogError(r.logger, err, "failed to stop recording")
 		}
@@ -385,3 +534,24 @@ func (r *Recorder) ReRecord(ctx context.Context) error {
 
 	return nil
 }
+
+// discardLast deletes the last testcase captured by the session, its mocks are removed once the session is stopped.
+func (r *Recorder) discardLast(ctx context.Context, ui *tui) {
+	tc, ok := ui.discardLast()
+	if !ok {
+		return
+	}
+	if err := r.testDB.DeleteTestCase(ctx, tc.testSet, tc.name); err != nil {
+		utils.LogError(r.logger, err, "failed to discard the testcase", zap.String("testcase", tc.name))
+		ui.setMessage(fmt.Sprintf("failed to discard %s/%s, see the logs", tc.testSet, tc.name))
+	}
+}
+
+// discardMocks removes the mocks recorded within the window of the discarded testcases from their test sets.
+func (r *Recorder) discardMocks(ctx context.Context, ui *tui) {
+	for testSetID, kept := range ui.keptMocks() {
+		if err := r.mockDB.UpdateMocks(ctx, testSetID, kept); err != nil {
+			utils.LogError(r.logger, err, "failed to remove the mocks of the discarded testcases", zap.String("testSet", testSetID))
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package record
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// redacted replaces the sensitive values of the recorded testcases and mocks.
+const redacted = "[REDACTED]"
+
+// sensitiveHeaders are always redacted by the continuous recording.
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token"}
+
+// redactor replaces the values of the sensitive headers, json fields and patterns of the recorded testcases and http
+// mocks before they are written. The mocks of the other protocols are recorded as is. The requests of the redacted
+// testcases carry [REDACTED] when replayed, so the apps checking the redacted credentials need them noised or stubbed.
+type redactor struct {
+	headers  map[string]bool
+	fields   [][]string
+	patterns []*regexp.Regexp
+}
+
+// newRedactor returns the redactor of the rules, nil if there are none so that the recording isn't slowed down.
+func newRedactor(cfg config.Record) (*redactor, error) {
+	headers := cfg.Redact.Headers
+	if cfg.Continuous {
+		headers = append(append([]string{}, sensitiveHeaders...), headers...)
+	}
+	if len(headers) == 0 && len(cfg.Redact.Fields) == 0 && len(cfg.Redact.Patterns) == 0 {
+		return nil, nil
+	}
+	r := &redactor{headers: make(map[string]bool, len(headers))}
+	for _, h := range headers {
+		r.headers[http.CanonicalHeaderKey(h)] = true
+	}
+	for _, f := range cfg.Redact.Fields {
+		r.fields = append(r.fields, strings.Split(strings.TrimPrefix(f, "body."), "."))
+	}
+	for _, p := range cfg.Redact.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// testCase redacts the request and the response of the http testcase, regenerating its curl.
+func (r *redactor) testCase(tc *models.TestCase) {
+	if r == nil || tc.Kind != models.HTTP {
+		return
+	}
+	r.header(tc.HTTPReq.Header)
+	tc.HTTPReq.Body = r.body(tc.HTTPReq.Body)
+	r.header(tc.HTTPResp.Header)
+	tc.HTTPResp.Body = r.body(tc.HTTPResp.Body)
+	if tc.Curl != "" {
+		tc.Curl = pkg.MakeCurlCommand(string(tc.HTTPReq.Method), tc.HTTPReq.URL, tc.HTTPReq.Header, tc.HTTPReq.Body)
+	}
+}
+
+// mock redacts the request and the response of the http mock.
+func (r *redactor) mock(mock *models.Mock) {
+	if r == nil || mock.Kind != models.HTTP {
+		return
+	}
+	if req := mock.Spec.HTTPReq; req != nil {
+		r.header(req.Header)
+		req.Body = r.body(req.Body)
+	}
+	if resp := mock.Spec.HTTPResp; resp != nil {
+		r.header(resp.Header)
+		resp.Body = r.body(resp.Body)
+	}
+}
+
+func (r *redactor) header(header map[string]string) {
+	for name := range header {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			header[name] = redacted
+		}
+	}
+}
+
+// body redacts the fields of the json body and the matches of the patterns. The json body is only re-encoded if
+// one of its fields is redacted, keeping the recorded formatting otherwise.
+func (r *redactor) body(body string) string {
+	if body == "" {
+		return body
+	}
+	if len(r.fields) > 0 {
+		var doc interface{}
+		if json.Unmarshal([]byte(body), &doc) == nil {
+			changed := false
+			for _, path := range r.fields {
+				if redactField(doc, path) {
+					changed = true
+				}
+			}
+			if changed {
+				if b, err := json.Marshal(doc); err == nil {
+					body = string(b)
+				}
+			}
+		}
+	}
+	for _, re := range r.patterns {
+		body = re.ReplaceAllString(body, redacted)
+	}
+	return body
+}
+
+// redactField replaces the value at the path of the json document, * matching any key or index, and reports
+// whether any value was replaced.
+func redactField(doc interface{}, path []string) bool {
+	if len(path) == 0 {
+		return false
+	}
+	key, rest := path[0], path[1:]
+	changed := false
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if key != "*" && key != k {
+				continue
+			}
+			if len(rest) == 0 {
+				v[k] = redacted
+				changed = true
+			} else if redactField(child, rest) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for i, child := range v {
+			if key != "*" && key != strconv.Itoa(i) {
+				continue
+			}
+			if len(rest) == 0 {
+				v[i] = redacted
+				changed = true
+			} else if redactField(child, rest) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
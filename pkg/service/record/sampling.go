@@ -0,0 +1,131 @@
+package record
+
+import (
+	"math/rand/v2"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// idSegment matches the segments of a path which identify a resource, e.g. the numbers, uuids and hashes, so that
+// /users/1 and /users/2 are the same endpoint.
+var idSegment = regexp.MustCompile(`^([0-9]+|[0-9a-fA-F-]{32,36}|[0-9a-fA-F]{24,})$`)
+
+// sampler bounds the testcases recorded from a busy app, by recording a fraction of the requests, at most a number
+// of them per endpoint and a uniform sample of a number of them of each test set. The named testcases, by the
+// Keploy-Test-Name header or the annotation api, are always recorded.
+type sampler struct {
+	rate           float64
+	maxPerEndpoint int
+	size           int
+
+	mu        sync.Mutex
+	endpoints map[string]int
+	// reservoirs are the samples of the test sets, each inserted into its own test set as the mocks of its
+	// testcases are recorded into it
+	reservoirs map[string]*reservoir
+}
+
+type reservoir struct {
+	seen int // the testcases offered to the reservoir
+	tcs  []*models.TestCase
+}
+
+func newSampler(cfg config.Record) *sampler {
+	return &sampler{
+		rate:           cfg.SampleRate,
+		maxPerEndpoint: cfg.MaxPerEndpoint,
+		size:           cfg.Reservoir,
+		endpoints:      map[string]int{},
+		reservoirs:     map[string]*reservoir{},
+	}
+}
+
+// sample returns the testcases to insert now into the test set: the testcase if it is sampled, none if it is
+// dropped or kept in the reservoir of the test set to be inserted by flush.
+func (s *sampler) sample(tc *models.TestCase, testSetID string) []*models.TestCase {
+	if tc.Name != "" {
+		return []*models.TestCase{tc}
+	}
+	if s.rate > 0 && s.rate < 1 && rand.Float64() >= s.rate {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.maxPerEndpoint > 0 {
+		key := endpoint(tc)
+		if s.endpoints[key] >= s.maxPerEndpoint {
+			return nil
+		}
+		s.endpoints[key]++
+	}
+	if s.size <= 0 {
+		return []*models.TestCase{tc}
+	}
+	r, ok := s.reservoirs[testSetID]
+	if !ok {
+		r = &reservoir{}
+		s.reservoirs[testSetID] = r
+	}
+	// algorithm R, each of the testcases seen is in the reservoir with the same probability
+	r.seen++
+	if len(r.tcs) < s.size {
+		r.tcs = append(r.tcs, tc)
+	} else if i := rand.IntN(r.seen); i < s.size {
+		r.tcs[i] = tc
+	}
+	return nil
+}
+
+// testSets returns the test sets with a reservoir to flush.
+func (s *sampler) testSets() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.reservoirs))
+	for id := range s.reservoirs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// flush returns the testcases of the reservoir of the test set in the order of their requests, to be inserted once
+// the test set is rotated or the session ends.
+func (s *sampler) flush(testSetID string) []*models.TestCase {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.reservoirs[testSetID]
+	if !ok {
+		return nil
+	}
+	delete(s.reservoirs, testSetID)
+	tcs := r.tcs
+	sort.SliceStable(tcs, func(i, j int) bool {
+		return tcs[i].ReqTimestamp().Before(tcs[j].ReqTimestamp())
+	})
+	return tcs
+}
+
+// endpoint returns the method and the path of the request of the testcase, with the ids in the path collapsed.
+func endpoint(tc *models.TestCase) string {
+	if tc.Kind == models.GRPC_EXPORT {
+		return "grpc " + tc.GrpcReq.Headers.PseudoHeaders[":path"]
+	}
+	path := tc.HTTPReq.URL
+	if u, err := url.Parse(tc.HTTPReq.URL); err == nil {
+		path = u.Path
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if idSegment.MatchString(segment) {
+			segments[i] = "{id}"
+		}
+	}
+	return string(tc.HTTPReq.Method) + " " + strings.Join(segments, "/")
+}
@@ -27,10 +27,14 @@ type TestDB interface {
 	GetAllTestSetIDs(ctx context.Context) ([]string, error)
 	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
 	GetTestCases(ctx context.Context, testID string) ([]*models.TestCase, error)
+	DeleteTestCase(ctx context.Context, testSetID string, name string) error
 }
 
 type MockDB interface {
 	InsertMock(ctx context.Context, mock *models.Mock, testSetID string) error
+	UpdateMocks(ctx context.Context, testSetID string, mockNames map[string]bool) error
+	// Close closes the files of the mocks kept open between the inserts, once the session ends.
+	Close() error
 }
 
 type Telemetry interface {
@@ -0,0 +1,92 @@
+package record
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// sourceFilter keeps the testcases of the requests sent from the source ips of the config, all of them if none are
+// configured, so that the requests of the other clients of a shared environment aren't recorded.
+type sourceFilter struct {
+	logger    *zap.Logger
+	nets      []*net.IPNet
+	userAgent bool
+	warnOnce  sync.Once
+}
+
+func newSourceFilter(logger *zap.Logger, cfg config.Record) (*sourceFilter, error) {
+	f := &sourceFilter{logger: logger, userAgent: cfg.CaptureUserAgent}
+	for _, source := range cfg.SourceIPs {
+		if !strings.Contains(source, "/") {
+			ip := net.ParseIP(source)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid source ip %q, expected an ip or a cidr e.g. 10.0.0.0/8", source)
+			}
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			source = fmt.Sprintf("%s/%d", source, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source ip %q, expected an ip or a cidr e.g. 10.0.0.0/8", source)
+		}
+		f.nets = append(f.nets, ipNet)
+	}
+	return f, nil
+}
+
+// keep reports whether the testcase is recorded, setting its user agent if it is captured. A request is from a
+// source if its client, or the first client of its X-Forwarded-For header behind a load balancer, is in it.
+func (f *sourceFilter) keep(tc *models.TestCase) bool {
+	header := tc.HTTPReq.Header
+	if tc.Kind == models.GRPC_EXPORT {
+		header = tc.GrpcReq.Headers.OrdinaryHeaders
+	}
+	if f.userAgent {
+		tc.UserAgent = headerValue(header, "User-Agent")
+	}
+	if len(f.nets) == 0 {
+		return true
+	}
+
+	clients := []string{tc.ClientIP}
+	if forwarded := headerValue(header, "X-Forwarded-For"); forwarded != "" {
+		clients = append(clients, strings.TrimSpace(strings.Split(forwarded, ",")[0]))
+	}
+	known := false
+	for _, client := range clients {
+		ip := net.ParseIP(client)
+		if ip == nil {
+			continue
+		}
+		known = true
+		for _, ipNet := range f.nets {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+	}
+	if !known {
+		f.warnOnce.Do(func() {
+			f.logger.Warn("dropping the testcases whose client is unknown, as only the requests from the source ips are recorded", zap.String("testcase", tc.Name))
+		})
+	}
+	return false
+}
+
+func headerValue(header map[string]string, key string) string {
+	for k, v := range header {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,309 @@
+package record
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"golang.org/x/term"
+)
+
+const (
+	// tuiRefresh is how often the terminal ui is redrawn
+	tuiRefresh = 100 * time.Millisecond
+	// tuiTests and tuiMocks are how many of the latest testcases and mocks are shown
+	tuiTests = 12
+	tuiMocks = 10
+)
+
+// capturedTest is a testcase captured in the session, with the window of its request and response in which the
+// mocks of its outgoing calls were recorded.
+type capturedTest struct {
+	testSet string
+	name    string
+	line    string
+	from    time.Time
+	to      time.Time
+}
+
+// capturedMock is a mock recorded in the session.
+type capturedMock struct {
+	testSet string
+	name    string
+	line    string
+	at      time.Time
+}
+
+// tui draws the testcases and the mocks captured by the session over the terminal, on the alternate screen so that
+// the terminal is restored when it is closed, and reads the keys discarding the last testcase and stopping the
+// session. The logs are written to the log file meanwhile.
+type tui struct {
+	mutex     sync.Mutex
+	out       *os.File
+	in        *os.File
+	tests     []capturedTest
+	mocks     []capturedMock
+	kinds     map[string]int
+	discarded []capturedTest
+	paused    bool
+	message   string
+	// restore is the state of the terminal of in before it was made raw, nil if the keys aren't read
+	restore *term.State
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+func newTUI(out *os.File, in *os.File) *tui {
+	return &tui{out: out, in: in, kinds: map[string]int{}, stop: make(chan struct{})}
+}
+
+// start switches to the alternate screen and redraws the terminal ui until it is closed. The keys are read from the
+// input if it is a terminal, d calling discard, p calling pause and q or ctrl-c calling quit.
+func (t *tui) start(discard func(), pause func(), quit func()) {
+	if term.IsTerminal(int(t.in.Fd())) {
+		// the keys are read as they are pressed, and ctrl-c is read as a key rather than interrupting keploy
+		state, err := term.MakeRaw(int(t.in.Fd()))
+		if err == nil {
+			t.restore = state
+			go t.readKeys(discard, pause, quit)
+		}
+	}
+	utils.EnterScreen(t.out)
+	t.stopped.Add(1)
+	go func() {
+		defer t.stopped.Done()
+		ticker := time.NewTicker(tuiRefresh)
+		defer ticker.Stop()
+		for {
+			t.draw()
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// close stops redrawing the terminal ui and restores the screen and the terminal, it may be called more than once.
+func (t *tui) close() {
+	t.once.Do(func() {
+		close(t.stop)
+		t.stopped.Wait()
+		utils.LeaveScreen(t.out)
+		if t.restore != nil {
+			_ = term.Restore(int(t.in.Fd()), t.restore)
+		}
+	})
+}
+
+// readKeys reads the keys until the terminal ui is closed, the read blocked on the last key is left to the exit.
+func (t *tui) readKeys(discard func(), pause func(), quit func()) {
+	buf := make([]byte, 16)
+	for {
+		n, err := t.in.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, key := range buf[:n] {
+			select {
+			case <-t.stop:
+				return
+			default:
+			}
+			switch key {
+			case 'd', 'D':
+				discard()
+			case 'p', 'P':
+				pause()
+			case 'q', 'Q', 0x03:
+				t.setMessage("stopping the recording...")
+				quit()
+			}
+		}
+	}
+}
+
+func (t *tui) setMessage(message string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.message = message
+}
+
+func (t *tui) setPaused(paused bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.paused = paused
+	t.message = ""
+}
+
+func (t *tui) testCaptured(testSetID string, tc *models.TestCase) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.tests = append(t.tests, capturedTest{
+		testSet: testSetID,
+		name:    tc.Name,
+		line:    testLine(tc),
+		from:    tc.ReqTimestamp(),
+		to:      tc.RespTimestamp(),
+	})
+}
+
+func (t *tui) mockRecorded(testSetID string, mock *models.Mock) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.kinds[mock.GetKind()]++
+	t.mocks = append(t.mocks, capturedMock{testSet: testSetID, name: mock.Name, line: mockLine(mock), at: mock.Spec.ReqTimestampMock})
+}
+
+// discardLast removes the last captured testcase from the list, to be deleted by the caller, false if there is none.
+func (t *tui) discardLast() (capturedTest, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if len(t.tests) == 0 {
+		t.message = "no testcase to discard"
+		return capturedTest{}, false
+	}
+	last := t.tests[len(t.tests)-1]
+	t.tests = t.tests[:len(t.tests)-1]
+	t.discarded = append(t.discarded, last)
+	t.message = fmt.Sprintf("discarded %s/%s", last.testSet, last.name)
+	return last, true
+}
+
+// keptMocks returns the names of the mocks to keep of each test set with mocks of discarded testcases: all of its
+// mocks but the ones recorded within the window of a discarded testcase, unless also within the window of a kept one
+// as the concurrent testcases overlap.
+func (t *tui) keptMocks() map[string]map[string]bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	kept := map[string]map[string]bool{}
+	dropped := map[string]bool{}
+	for _, m := range t.mocks {
+		if kept[m.testSet] == nil {
+			kept[m.testSet] = map[string]bool{}
+		}
+		if withinAny(m, t.discarded) && !withinAny(m, t.tests) {
+			dropped[m.testSet] = true
+			continue
+		}
+		kept[m.testSet][m.name] = false
+	}
+	for testSetID := range kept {
+		if !dropped[testSetID] {
+			delete(kept, testSetID)
+		}
+	}
+	return kept
+}
+
+func withinAny(m capturedMock, tests []capturedTest) bool {
+	for _, tc := range tests {
+		if tc.testSet == m.testSet && !m.at.Before(tc.from) && !m.at.After(tc.to) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *tui) draw() {
+	utils.DrawScreen(t.out, t.render())
+}
+
+func (t *tui) render() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	kinds := make([]string, 0, len(t.kinds))
+	for _, kind := range sortedKinds(t.kinds) {
+		kinds = append(kinds, fmt.Sprintf("%s %d", kind, t.kinds[kind]))
+	}
+	state := "\x1b[32mrecording\x1b[0m"
+	if t.paused {
+		state = "\x1b[33mpaused, the testcases and mocks are dropped\x1b[0m"
+	}
+	lines := []string{
+		"\x1b[1mKeploy record\x1b[0m   (logs: keploy-logs.txt)",
+		"",
+		state,
+		fmt.Sprintf("testcases %d captured  %d discarded", len(t.tests), len(t.discarded)),
+		fmt.Sprintf("mocks     %d recorded  %s", len(t.mocks), strings.Join(kinds, "  ")),
+		"",
+		"\x1b[1mlatest testcases\x1b[0m",
+	}
+	if len(t.tests) == 0 {
+		lines = append(lines, "  waiting for the requests to the app")
+	}
+	for i := len(t.tests) - 1; i >= 0 && i >= len(t.tests)-tuiTests; i-- {
+		lines = append(lines, fmt.Sprintf("  %s/%s  %s", t.tests[i].testSet, t.tests[i].name, t.tests[i].line))
+	}
+	lines = append(lines, "", "\x1b[1mlatest mocks\x1b[0m")
+	if len(t.mocks) == 0 {
+		lines = append(lines, "  no outgoing calls yet")
+	}
+	for i := len(t.mocks) - 1; i >= 0 && i >= len(t.mocks)-tuiMocks; i-- {
+		lines = append(lines, fmt.Sprintf("  %s/%s  %s", t.mocks[i].testSet, t.mocks[i].name, t.mocks[i].line))
+	}
+	lines = append(lines, "")
+	if t.restore != nil {
+		lines = append(lines, "\x1b[2md discard the last testcase   p pause or resume   q stop recording\x1b[0m")
+	}
+	if t.message != "" {
+		lines = append(lines, t.message)
+	}
+	return lines
+}
+
+// testLine describes the testcase by its method, path and status.
+func testLine(tc *models.TestCase) string {
+	if tc.Kind == models.GRPC_EXPORT {
+		status := tc.GrpcResp.Trailers.OrdinaryHeaders["grpc-status"]
+		return fmt.Sprintf("gRPC %s %s", tc.GrpcReq.Headers.PseudoHeaders[":path"], statusColor(status, status == "0"))
+	}
+	status := tc.HTTPResp.StatusCode
+	return fmt.Sprintf("%s %s %s", tc.HTTPReq.Method, urlPath(tc.HTTPReq.URL), statusColor(fmt.Sprint(status), status < 400))
+}
+
+// mockLine describes the mock by its kind and dependency, and by its method, path and status for the http mocks.
+func mockLine(mock *models.Mock) string {
+	line := mock.GetKind()
+	if dep := mock.Spec.Metadata[models.DependencyKey]; dep != "" {
+		line += " " + dep
+	}
+	if mock.Spec.HTTPReq != nil && mock.Spec.HTTPResp != nil {
+		status := mock.Spec.HTTPResp.StatusCode
+		line += fmt.Sprintf("  %s %s %s", mock.Spec.HTTPReq.Method, urlPath(mock.Spec.HTTPReq.URL), statusColor(fmt.Sprint(status), status < 400))
+	}
+	return line
+}
+
+func statusColor(status string, ok bool) string {
+	if ok {
+		return "\x1b[32m" + status + "\x1b[0m"
+	}
+	return "\x1b[31m" + status + "\x1b[0m"
+}
+
+// urlPath returns the path and query of the url, the url itself if it can't be parsed.
+func urlPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Path == "" {
+		return rawURL
+	}
+	return u.RequestURI()
+}
+
+func sortedKinds(kinds map[string]int) []string {
+	names := make([]string, 0, len(kinds))
+	for kind := range kinds {
+		names = append(names, kind)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,245 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// latest is the object holding the name of the last pushed version.
+const latest = "latest"
+
+// reports is the directory of the test reports in the keploy directory.
+const reports = "reports"
+
+// Remote keeps every push as a version of the keploy directory in the object store:
+//
+//	<prefix>/<version>/<test-set>/tests/test-1.yaml
+//	<prefix>/<version>/<test-set>/mocks.yaml
+//	<prefix>/<version>/reports/<test-run>/<test-set>-report.yaml
+//	<prefix>/latest
+type Remote struct {
+	logger  *zap.Logger
+	storage Storage
+	config  config.Config
+}
+
+func New(logger *zap.Logger, storage Storage, config config.Config) Service {
+	return &Remote{
+		logger:  logger,
+		storage: storage,
+		config:  config,
+	}
+}
+
+// Push uploads the keploy directory as a new version and marks it as the latest version.
+func (r *Remote) Push(ctx context.Context) error {
+	version := r.config.Remote.Version
+	if version == "" {
+		version = time.Now().UTC().Format("20060102T150405Z")
+	}
+	if err := validVersion(version); err != nil {
+		return err
+	}
+
+	count := 0
+	err := filepath.WalkDir(r.config.Path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(r.config.Path, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !r.isSelected(rel) {
+			return nil
+		}
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		err = r.storage.PutObject(ctx, r.key(version, rel), data)
+		if err != nil {
+			return fmt.Errorf("failed to upload %s: %w", rel, err)
+		}
+		r.logger.Debug("uploaded", zap.String("file", rel), zap.String("version", version))
+		count++
+		return nil
+	})
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to push the keploy directory", zap.String("path", r.config.Path))
+		return err
+	}
+	if count == 0 {
+		return fmt.Errorf("found no test sets to push in %s", r.config.Path)
+	}
+
+	err = r.storage.PutObject(ctx, r.key(latest), []byte(version))
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to mark the pushed version as the latest version")
+		return err
+	}
+	r.logger.Info("pushed the test sets to the bucket", zap.String("version", version), zap.Int("files", count), zap.String("bucket", r.config.Remote.Bucket))
+	return nil
+}
+
+// Pull downloads the given version, or the latest version, of the keploy directory. The pulled test sets replace
+// the local test sets with the same ids, the other local test sets and reports are kept. The files are downloaded
+// into a temporary directory first, so the local test sets are only replaced once the whole version is pulled.
+func (r *Remote) Pull(ctx context.Context) error {
+	version := r.config.Remote.Version
+	if version == "" || version == latest {
+		data, err := r.storage.GetObject(ctx, r.key(latest))
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the latest version, push the test sets first")
+			return err
+		}
+		version = strings.TrimSpace(string(data))
+	}
+	if err := validVersion(version); err != nil {
+		utils.LogError(r.logger, err, "failed to pull the test sets")
+		return err
+	}
+
+	versionPrefix := r.key(version) + "/"
+	keys, err := r.storage.ListObjects(ctx, versionPrefix)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to list the files of the version", zap.String("version", version))
+		return err
+	}
+
+	var files []string
+	for _, key := range keys {
+		rel := path.Clean(strings.TrimPrefix(key, versionPrefix))
+		if rel == "." || rel == ".." || strings.HasPrefix(rel, "../") || path.IsAbs(rel) {
+			r.logger.Warn("skipping the file outside of the keploy directory", zap.String("key", key))
+			continue
+		}
+		if !r.isSelected(rel) {
+			continue
+		}
+		files = append(files, rel)
+	}
+	if len(files) == 0 {
+		return errors.New("found no test sets to pull in the version " + version)
+	}
+
+	err = os.MkdirAll(r.config.Path, fs.ModePerm)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to create the keploy directory", zap.String("path", r.config.Path))
+		return err
+	}
+	// the temporary directory is next to the keploy directory, so that the test sets are renamed into it
+	tmp, err := os.MkdirTemp(filepath.Dir(filepath.Clean(r.config.Path)), ".keploy-pull-")
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to create the temporary directory of the pulled files")
+		return err
+	}
+	defer func() {
+		_ = os.RemoveAll(tmp)
+	}()
+
+	for _, rel := range files {
+		data, err := r.storage.GetObject(ctx, versionPrefix+rel)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to download the file", zap.String("file", rel), zap.String("version", version))
+			return err
+		}
+		file := filepath.Join(tmp, filepath.FromSlash(rel))
+		err = os.MkdirAll(filepath.Dir(file), fs.ModePerm)
+		if err != nil {
+			return err
+		}
+		err = os.WriteFile(file, data, 0644)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to write the pulled file", zap.String("file", file))
+			return err
+		}
+		r.logger.Debug("downloaded", zap.String("file", rel), zap.String("version", version))
+	}
+
+	err = r.replace(tmp, files)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to replace the local test sets with the pulled ones", zap.String("path", r.config.Path))
+		return err
+	}
+	r.logger.Info("pulled the test sets from the bucket", zap.String("version", version), zap.Int("files", len(files)), zap.String("path", r.config.Path))
+	return nil
+}
+
+// replace moves the pulled test sets from the temporary directory into the keploy directory in place of the local
+// test sets with the same ids, the stale testcases and mocks of which should not be mixed with the pulled ones.
+// The pulled reports are added to the local reports.
+func (r *Remote) replace(tmp string, files []string) error {
+	moved := map[string]bool{}
+	for _, rel := range files {
+		testSetID := strings.Split(rel, "/")[0]
+		if testSetID == reports {
+			dst := filepath.Join(r.config.Path, filepath.FromSlash(rel))
+			if err := os.MkdirAll(filepath.Dir(dst), fs.ModePerm); err != nil {
+				return err
+			}
+			if err := os.Rename(filepath.Join(tmp, filepath.FromSlash(rel)), dst); err != nil {
+				return err
+			}
+			continue
+		}
+		if moved[testSetID] {
+			continue
+		}
+		dst := filepath.Join(r.config.Path, testSetID)
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.Rename(filepath.Join(tmp, testSetID), dst); err != nil {
+			return err
+		}
+		moved[testSetID] = true
+	}
+	return nil
+}
+
+// validVersion checks that the version names a single directory of the prefix in the bucket.
+func validVersion(version string) error {
+	if version == "" || version == latest || version == "." || version == ".." || strings.ContainsAny(version, "/\\") {
+		return fmt.Errorf("invalid version %q, it should not be empty, %q, . or .. or contain a slash", version, latest)
+	}
+	return nil
+}
+
+// isSelected reports whether the file of the keploy directory belongs to the selected test sets. The reports are
+// synced only if no test set is selected.
+func (r *Remote) isSelected(rel string) bool {
+	if len(r.config.Remote.TestSets) == 0 {
+		return true
+	}
+	testSetID := strings.Split(rel, "/")[0]
+	for _, id := range r.config.Remote.TestSets {
+		if id == testSetID {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Remote) key(elem ...string) string {
+	return path.Join(append([]string{strings.Trim(r.config.Remote.Prefix, "/")}, elem...)...)
+}
@@ -0,0 +1,18 @@
+// Package remote provides the push and pull of the test sets, mocks and reports to an object store, so that the
+// CI runners can fetch the recorded suites without baking them into their images.
+package remote
+
+import (
+	"context"
+)
+
+type Service interface {
+	Push(ctx context.Context) error
+	Pull(ctx context.Context) error
+}
+
+type Storage interface {
+	PutObject(ctx context.Context, key string, data []byte) error
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+}
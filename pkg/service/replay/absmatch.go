@@ -166,6 +166,16 @@ func CompareHTTPReq(tcs1, tcs2 *models.TestCase, _ models.GlobalNoise, ignoreOrd
 		pass = false
 	}
 
+	// GraphQL operations are compared irrespective of the whitespace of the query and the ordering of the variables
+	if gql1, gql2 := graphQLReq(tcs1.HTTPReq), graphQLReq(tcs2.HTTPReq); gql1 != nil && gql2 != nil {
+		if !pkg.CompareGraphQLReq(gql1, gql2) {
+			logger.Debug("test case graphql operation is not equal", zap.Any("tcs1GraphQL", gql1), zap.Any("tcs2GraphQL", gql2))
+			pass = false
+			reqCompare.BodyResult.Normal = false
+		}
+		return pass, reqCompare
+	}
+
 	reqBodyNoise := map[string][]string{}
 
 	// compare http req body
@@ -199,7 +209,7 @@ func CompareHTTPReq(tcs1, tcs2 *models.TestCase, _ models.GlobalNoise, ignoreOrd
 			return false, reqCompare
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, reqBodyNoise, ignoreOrdering)
+			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, reqBodyNoise, ignoreOrdering, nil)
 			exact := jsonComparisonResult.isExact
 			if err != nil {
 				logger.Error("failed to compare json", zap.Error(err))
@@ -332,7 +342,7 @@ func CompareHTTPResp(tcs1, tcs2 *models.TestCase, noiseConfig models.GlobalNoise
 			return false, respCompare
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering)
+			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering, nil)
 			exact := jsonComparisonResult.isExact
 			if err != nil {
 				logger.Error("failed to compare json", zap.Error(err))
@@ -0,0 +1,184 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// codeQualityReport is the file of the gitlab code quality report, collected with artifacts:reports:codequality.
+const codeQualityReport = "gl-code-quality-report.json"
+
+// the bounds of the diff snippet of an annotation, the full diff is in the test report
+const (
+	snippetLines    = 12
+	snippetLineSize = 200
+)
+
+// codeQualityIssue is an issue of the gitlab code quality report.
+type codeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    codeQualityLocation `json:"location"`
+}
+
+type codeQualityLocation struct {
+	Path  string `json:"path"`
+	Lines struct {
+		Begin int `json:"begin"`
+	} `json:"lines"`
+}
+
+// annotationsCI returns the CI the failed testcases are annotated for, detected from the environment with auto, empty
+// if they aren't annotated.
+func annotationsCI(annotations string) string {
+	if annotations != config.AnnotationsAuto {
+		return annotations
+	}
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return config.AnnotationsGitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		return config.AnnotationsGitLab
+	}
+	return ""
+}
+
+// annotate annotates the failed testcases of the test sets of the test run for the CI, so that they show inline in
+// the pull requests, at the response of their testcase file with a snippet of the diff.
+func (r *Replayer) annotate(ctx context.Context, testRunID string, testSetIDs []string) {
+	ci := annotationsCI(r.config.Test.Annotations)
+	if ci == "" {
+		return
+	}
+	var issues []codeQualityIssue
+	for _, testSetID := range testSetIDs {
+		results, err := r.reportDB.GetTestCaseResults(ctx, testRunID, testSetID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the testcase results to annotate", zap.String("test-set", testSetID))
+			continue
+		}
+		for _, result := range results {
+			if result.Status != models.TestStatusFailed {
+				continue
+			}
+			file := filepath.Join(r.config.Path, testSetID, "tests", result.TestCaseID+".yaml")
+			path, line := annotationPath(file), respLine(file)
+			title := fmt.Sprintf("keploy: %s/%s failed", testSetID, result.TestCaseID)
+			snippet := diffSnippet(Mismatch(result))
+			switch ci {
+			case config.AnnotationsGitHub:
+				writeWorkflowCommand(os.Stdout, path, line, title, snippet)
+			case config.AnnotationsGitLab:
+				issue := codeQualityIssue{
+					Description: title + "\n" + snippet,
+					CheckName:   "keploy",
+					Fingerprint: fingerprint(testSetID, result.TestCaseID),
+					Severity:    "major",
+				}
+				issue.Location.Path = path
+				issue.Location.Lines.Begin = line
+				issues = append(issues, issue)
+			}
+		}
+	}
+	if ci != config.AnnotationsGitLab {
+		return
+	}
+	// the report is written even without failures, so that the issues of an earlier pipeline are resolved
+	if issues == nil {
+		issues = []codeQualityIssue{}
+	}
+	data, err := json.MarshalIndent(issues, "", "  ")
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to marshal the code quality report")
+		return
+	}
+	if err := os.WriteFile(codeQualityReport, data, 0644); err != nil {
+		utils.LogError(r.logger, err, "failed to write the code quality report", zap.String("file", codeQualityReport))
+		return
+	}
+	r.logger.Info("wrote the failed testcases to the code quality report", zap.String("file", codeQualityReport), zap.Int("failed", len(issues)))
+}
+
+// writeWorkflowCommand writes the github error workflow command annotating the file at the line.
+func writeWorkflowCommand(w io.Writer, path string, line int, title, message string) {
+	property := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	data := strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	fmt.Fprintf(w, "::error file=%s,line=%d,title=%s::%s\n", property.Replace(path), line, property.Replace(title), data.Replace(message))
+}
+
+// annotationPath returns the path of the file relative to the root of the repo the CI checked out, or else to the
+// working directory, with forward slashes.
+func annotationPath(file string) string {
+	root := os.Getenv("GITHUB_WORKSPACE")
+	if root == "" {
+		root = os.Getenv("CI_PROJECT_DIR")
+	}
+	if root == "" {
+		var err error
+		if root, err = os.Getwd(); err != nil {
+			return filepath.ToSlash(file)
+		}
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(rel)
+}
+
+// respLine returns the line of the expected response in the testcase file, the first line if it isn't found.
+func respLine(file string) int {
+	f, err := os.Open(file)
+	if err != nil {
+		return 1
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "resp:" || text == "grpcResp:" {
+			return line
+		}
+	}
+	return 1
+}
+
+// diffSnippet bounds the mismatch of the testcase to its first lines, each truncated.
+func diffSnippet(mismatch string) string {
+	lines := strings.Split(mismatch, "\n")
+	more := len(lines) > snippetLines
+	if more {
+		lines = lines[:snippetLines]
+	}
+	for i, line := range lines {
+		if len(line) > snippetLineSize {
+			lines[i] = line[:snippetLineSize] + "..."
+		}
+	}
+	if more {
+		lines = append(lines, "...")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func fingerprint(testSetID, testCaseID string) string {
+	sum := sha1.Sum([]byte(testSetID + "/" + testCaseID))
+	return hex.EncodeToString(sum[:])
+}
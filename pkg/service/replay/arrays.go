@@ -0,0 +1,86 @@
+package replay
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+)
+
+// arrayModes normalises the body fields of the array matching like the body noise, so that they may be given as
+// JSONPath too, e.g. $.data.items[*] or body.data.items for data.items.
+func arrayModes(arrayMatching map[string]string) map[string]string {
+	if len(arrayMatching) == 0 {
+		return nil
+	}
+	modes := make(map[string]string, len(arrayMatching))
+	for field, mode := range arrayMatching {
+		modes[noiseKey("body", strings.TrimPrefix(field, "body."))] = mode
+	}
+	return modes
+}
+
+// arrayMode returns how the elements of the array at the key are matched, in order unless ignoreOrdering by default.
+func arrayMode(key string, arrayMatching map[string]string, ignoreOrdering bool) string {
+	if mode, ok := arrayMatching[key]; ok {
+		return mode
+	}
+	if ignoreOrdering {
+		return config.ArrayUnordered
+	}
+	return config.ArrayOrdered
+}
+
+// matchArrayByKey matches each expected element of the array with the actual element having the same value of the
+// field, e.g. the ids of the elements, whatever their order. The arrays of elements without the field don't match.
+func matchArrayByKey(key string, field string, expSlice, actSlice reflect.Value, noiseMap map[string][]string, ignoreOrdering bool, arrayMatching map[string]string) JSONComparisonResult {
+	var result JSONComparisonResult
+	if expSlice.Len() != actSlice.Len() {
+		return result
+	}
+	idOf := func(elem interface{}) (string, bool) {
+		obj, ok := elem.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		id, ok := obj[field]
+		if !ok {
+			return "", false
+		}
+		return InterfaceToString(id), true
+	}
+
+	// the actual elements by their id, the elements with the same id are matched in order
+	actual := map[string][]int{}
+	for j := 0; j < actSlice.Len(); j++ {
+		id, ok := idOf(actSlice.Index(j).Interface())
+		if !ok {
+			return result
+		}
+		actual[id] = append(actual[id], j)
+	}
+
+	isExact := true
+	for i := 0; i < expSlice.Len(); i++ {
+		id, ok := idOf(expSlice.Index(i).Interface())
+		if !ok || len(actual[id]) == 0 {
+			return result
+		}
+		j := actual[id][0]
+		actual[id] = actual[id][1:]
+		elemResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, ignoreOrdering, arrayMatching)
+		if err != nil || !elemResult.matches {
+			return result
+		}
+		if !elemResult.isExact {
+			isExact = false
+			for _, val := range elemResult.differences {
+				result.differences = append(result.differences, fmt.Sprintf("%s[%s=%s].%s", key, field, id, val))
+			}
+		}
+	}
+	result.matches = true
+	result.isExact = isExact
+	return result
+}
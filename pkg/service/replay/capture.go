@@ -0,0 +1,230 @@
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// captureHeaderPrefix marks the capture expression of a header of the response, e.g. header.X-Request-Id, and
+// captureStatus captures its status code. The other expressions are JSONPath into its body.
+const (
+	captureHeaderPrefix = "header."
+	captureStatus       = "status"
+)
+
+// capturePathStep is a step of a JSONPath, the key of an object, the index of an array, or all of the elements or
+// the values of either with wildcard.
+type capturePathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// captureFields evaluates the capture expressions of the testcase against its actual response. The value of an
+// expression with a wildcard is the list of the values it matched, the value of an expression which matched
+// nothing or which is invalid is nil.
+func captureFields(logger *zap.Logger, tc *models.TestCase, resp *models.HTTPResp) map[string]interface{} {
+	if len(tc.Capture) == 0 || resp == nil {
+		return nil
+	}
+	captured := make(map[string]interface{}, len(tc.Capture))
+	var body interface{}
+	var bodyErr error
+	bodyParsed := false
+	for _, expr := range tc.Capture {
+		captured[expr] = nil
+		switch {
+		case expr == captureStatus:
+			captured[expr] = resp.StatusCode
+			continue
+		case strings.HasPrefix(expr, captureHeaderPrefix):
+			name := strings.TrimPrefix(expr, captureHeaderPrefix)
+			for key, value := range resp.Header {
+				if http.CanonicalHeaderKey(key) == http.CanonicalHeaderKey(name) {
+					captured[expr] = value
+				}
+			}
+			continue
+		}
+
+		steps, err := parseCapturePath(expr)
+		if err != nil {
+			logger.Warn("skipping the invalid capture expression of the testcase", zap.String("testcase", tc.Name), zap.String("expression", expr), zap.Error(err))
+			continue
+		}
+		if !bodyParsed {
+			bodyParsed = true
+			decoder := json.NewDecoder(strings.NewReader(resp.Body))
+			// the numbers are kept as they were sent, the large ids would lose their precision as float64
+			decoder.UseNumber()
+			bodyErr = decoder.Decode(&body)
+			if bodyErr != nil {
+				logger.Debug("the response body of the testcase is not JSON, its fields can't be captured", zap.String("testcase", tc.Name), zap.Error(bodyErr))
+			}
+		}
+		if bodyErr != nil {
+			continue
+		}
+		values := evalCapturePath([]interface{}{body}, steps)
+		if hasWildcard(steps) {
+			list := make([]interface{}, 0, len(values))
+			for _, v := range values {
+				list = append(list, captureValue(v))
+			}
+			captured[expr] = list
+		} else if len(values) > 0 {
+			captured[expr] = captureValue(values[0])
+		}
+	}
+	return captured
+}
+
+// parseCapturePath parses a JSONPath like $.data.items[0].id, $.data.items[*].id or $['data']['total'] into its
+// steps. The leading $ is optional.
+func parseCapturePath(expr string) ([]capturePathStep, error) {
+	path := strings.TrimSpace(expr)
+	path = strings.TrimPrefix(path, "$")
+	var steps []capturePathStep
+	for len(path) > 0 {
+		switch path[0] {
+		case '.':
+			path = path[1:]
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			key := path[:end]
+			if key == "" {
+				return nil, fmt.Errorf("empty key in %q", expr)
+			}
+			path = path[end:]
+			if key == "*" {
+				steps = append(steps, capturePathStep{wildcard: true})
+				continue
+			}
+			steps = append(steps, capturePathStep{key: key})
+		case '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in %q", expr)
+			}
+			inner := strings.TrimSpace(path[1:end])
+			path = path[end+1:]
+			switch {
+			case inner == "*":
+				steps = append(steps, capturePathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, capturePathStep{key: inner[1 : len(inner)-1]})
+			default:
+				index, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index %q in %q", inner, expr)
+				}
+				steps = append(steps, capturePathStep{index: index, isIndex: true})
+			}
+		default:
+			// a path without the leading $. e.g. data.id, as the body noise is given
+			if len(steps) > 0 {
+				return nil, fmt.Errorf("unexpected %q in %q", path[0], expr)
+			}
+			path = "." + path
+		}
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no fields in %q", expr)
+	}
+	return steps, nil
+}
+
+// evalCapturePath returns the values at the steps of the path from each of the nodes, in order. A negative index
+// counts from the end of the array.
+func evalCapturePath(nodes []interface{}, steps []capturePathStep) []interface{} {
+	for _, step := range steps {
+		var next []interface{}
+		for _, node := range nodes {
+			switch n := node.(type) {
+			case map[string]interface{}:
+				switch {
+				case step.wildcard:
+					for _, key := range sortedKeys(n) {
+						next = append(next, n[key])
+					}
+				case !step.isIndex:
+					if v, ok := n[step.key]; ok {
+						next = append(next, v)
+					}
+				}
+			case []interface{}:
+				switch {
+				case step.wildcard:
+					next = append(next, n...)
+				case step.isIndex:
+					index := step.index
+					if index < 0 {
+						index += len(n)
+					}
+					if index >= 0 && index < len(n) {
+						next = append(next, n[index])
+					}
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func hasWildcard(steps []capturePathStep) bool {
+	for _, step := range steps {
+		if step.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// captureValue converts the numbers of the captured value into int64 or float64, so that the report has them as
+// numbers rather than as strings.
+func captureValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		// the integers beyond int64 are kept as strings rather than rounded
+		if f, err := v.Float64(); err == nil && strings.ContainsAny(v.String(), ".eE") {
+			return f
+		}
+		return v.String()
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = captureValue(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = captureValue(value)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,50 @@
+package replay
+
+import (
+	"go.keploy.io/server/v2/pkg/models"
+	"go.uber.org/zap"
+)
+
+// chaosVerdict counts the testcases of a test set which received injected faults.
+type chaosVerdict models.ChaosReport
+
+func (c *chaosVerdict) record(faults []models.InjectedFault, passed bool) {
+	c.Total++
+	c.Faults += len(faults)
+	if passed {
+		c.Passed++
+	} else {
+		c.Failed++
+	}
+}
+
+// report returns the chaos report of the test set, nil if no fault was injected.
+func (c *chaosVerdict) report() *models.ChaosReport {
+	if c.Total == 0 {
+		return nil
+	}
+	report := models.ChaosReport(*c)
+	return &report
+}
+
+// chaosOptions returns the fault injection configured for the mocks of the test set, nil if there is none.
+func (r *Replayer) chaosOptions(testSetID string) *models.ChaosOptions {
+	chaos, ok := r.config.Test.Chaos[testSetID]
+	if !ok || chaos.Percentage <= 0 {
+		return nil
+	}
+	var faults []models.FaultKind
+	for _, fault := range chaos.Faults {
+		switch models.FaultKind(fault) {
+		case models.FaultError, models.FaultReset, models.FaultTruncate, models.FaultTimeout:
+			faults = append(faults, models.FaultKind(fault))
+		default:
+			r.logger.Warn("ignoring the unknown fault of the chaos config", zap.String("fault", fault), zap.String("test-set", testSetID))
+		}
+	}
+	if len(chaos.Faults) > 0 && len(faults) == 0 {
+		return nil
+	}
+	r.logger.Info("injecting faults in place of the mock responses", zap.String("test-set", testSetID), zap.Float64("percentage", chaos.Percentage), zap.Any("faults", faults))
+	return models.NewChaosOptions(chaos.Percentage, faults, chaos.Seed)
+}
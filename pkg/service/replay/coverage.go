@@ -0,0 +1,364 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// coverageFile is the file in the directory of each test set holding the source files its testcases cover.
+const coverageFile = "coverage.yaml"
+
+// testSetCoverage is the coverage map of a test set, the source files of the app covered by its testcases, and the
+// ones covered by each testcase if the app writes its coverage counters after each request.
+type testSetCoverage struct {
+	Files     []string            `yaml:"files"`
+	TestCases map[string][]string `yaml:"testCases,omitempty"`
+}
+
+// coverageCollector collects the coverage maps of the test sets from the go coverage counters written by the app in
+// dir. The go runtime writes them only when the app exits, so the coverage of each testcase is known only if the app
+// writes them after each request with runtime/coverage.WriteCountersDir, and clears them with ClearCounters, e.g. in
+// a middleware.
+type coverageCollector struct {
+	dir string
+	// seen are the counter files already attributed to a test set or a testcase
+	seen map[string]bool
+	// testCases are the files covered by each testcase of the test set being run
+	testCases map[string][]string
+}
+
+func newCoverageCollector(dir string) *coverageCollector {
+	return &coverageCollector{dir: dir}
+}
+
+// start begins the coverage map of a test set, the counters written before it aren't attributed to it.
+func (c *coverageCollector) start() error {
+	c.testCases = map[string][]string{}
+	c.seen = map[string]bool{}
+	counters, err := coverageCounters(c.dir)
+	if err != nil {
+		return err
+	}
+	c.seen = counters
+	return nil
+}
+
+// collect returns the files covered by the counters written since the last collect, nil if none were written.
+func (c *coverageCollector) collect(ctx context.Context) ([]string, error) {
+	counters, err := coverageCounters(c.dir)
+	if err != nil {
+		return nil, err
+	}
+	var written []string
+	for name := range counters {
+		if !c.seen[name] {
+			written = append(written, name)
+			c.seen[name] = true
+		}
+	}
+	if len(written) == 0 {
+		return nil, nil
+	}
+	return coveredFiles(ctx, c.dir, written)
+}
+
+// testCaseDone attributes the counters written while the testcase ran to it.
+func (c *coverageCollector) testCaseDone(ctx context.Context, name string) error {
+	files, err := c.collect(ctx)
+	if err != nil {
+		return err
+	}
+	if files != nil {
+		c.testCases[name] = files
+	}
+	return nil
+}
+
+// testSetDone returns the coverage map of the test set, including the counters written when the app exited.
+func (c *coverageCollector) testSetDone(ctx context.Context) (*testSetCoverage, error) {
+	files, err := c.collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if files == nil && len(c.testCases) == 0 {
+		return nil, fmt.Errorf("no coverage counters were written by the app in %s", c.dir)
+	}
+	covered := ArrayToMap(files)
+	for _, tcFiles := range c.testCases {
+		for _, file := range tcFiles {
+			covered[file] = true
+		}
+	}
+	coverage := &testSetCoverage{Files: make([]string, 0, len(covered))}
+	for file := range covered {
+		coverage.Files = append(coverage.Files, file)
+	}
+	sort.Strings(coverage.Files)
+	if len(c.testCases) > 0 {
+		coverage.TestCases = c.testCases
+	}
+	return coverage, nil
+}
+
+// coverageCounters returns the counter files in the go coverage directory.
+func coverageCounters(dir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	counters := map[string]bool{}
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "covcounters.") {
+			counters[entry.Name()] = true
+		}
+	}
+	return counters, nil
+}
+
+// coveredFiles returns the source files covered by the given counter files of the go coverage directory. They are
+// converted to a profile with go tool covdata, alongside all the meta files.
+func coveredFiles(ctx context.Context, dir string, counters []string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.MkdirTemp("", "keploy-coverage")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	names := counters
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "covmeta.") {
+			names = append(names, entry.Name())
+		}
+	}
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(filepath.Join(tmp, name), data, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	profile := filepath.Join(tmp, "profile.txt")
+	out, err := exec.CommandContext(ctx, "go", "tool", "covdata", "textfmt", "-i="+tmp, "-o="+profile).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the coverage counters: %s: %w", strings.TrimSpace(string(out)), err)
+	}
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		return nil, err
+	}
+	return parseProfile(data), nil
+}
+
+// parseProfile returns the files of the blocks executed at least once in the go coverage profile, whose lines are
+// file:startLine.startCol,endLine.endCol statements count.
+func parseProfile(data []byte) []string {
+	covered := map[string]bool{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		file, block, ok := strings.Cut(line, ":")
+		fields := strings.Fields(block)
+		if !ok || len(fields) != 3 || fields[2] == "0" {
+			continue
+		}
+		covered[file] = true
+	}
+	files := make([]string, 0, len(covered))
+	for file := range covered {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+func readCoverage(path, testSetID string) (*testSetCoverage, error) {
+	data, err := os.ReadFile(filepath.Join(path, testSetID, coverageFile))
+	if err != nil {
+		return nil, err
+	}
+	var coverage testSetCoverage
+	if err := yaml.Unmarshal(data, &coverage); err != nil {
+		return nil, fmt.Errorf("failed to parse the coverage of %s: %w", testSetID, err)
+	}
+	return &coverage, nil
+}
+
+func writeCoverage(path, testSetID string, coverage *testSetCoverage) error {
+	data, err := yaml.Marshal(coverage)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, testSetID, coverageFile), data, 0644)
+}
+
+// changedFiles returns the files changed since the git revision, including the uncommitted changes and the
+// untracked files.
+func changedFiles(ctx context.Context, revision string) ([]string, error) {
+	out, err := git(ctx, "diff", "--name-only", revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against %s: %w", revision, err)
+	}
+	files := strings.Fields(out)
+
+	// the lines of the untracked files are "?? path"
+	out, err = git(ctx, "status", "--porcelain", "--untracked-files=all")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the untracked files: %w", err)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if file, ok := strings.CutPrefix(line, "?? "); ok {
+			files = append(files, strings.Trim(file, `"`))
+		}
+	}
+	return files, nil
+}
+
+func git(ctx context.Context, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, "git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", errors.New(strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(out), nil
+}
+
+// coversChanged reports whether any of the covered files is changed. The covered files are named by the import path
+// of their package while the changed ones are relative to the root of the repository, so they are matched by suffix.
+func coversChanged(covered, changed []string) bool {
+	for _, c := range changed {
+		for _, file := range covered {
+			if file == c || strings.HasSuffix(file, "/"+c) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changedTestCases returns the testcases of the test set covering any of the changed files, from its coverage map,
+// and whether any of them is to run. The testcases are nil if all of them are to run, as the test set has no
+// coverage map or only the one of the whole test set. The testcases without a coverage of their own are always run,
+// as what they cover is unknown.
+func (r *Replayer) changedTestCases(ctx context.Context, testSetID string, changed []string) ([]string, bool) {
+	coverage, err := readCoverage(r.config.Path, testSetID)
+	if err != nil {
+		r.logger.Debug("no coverage map of the test set, running it", zap.String("test-set", testSetID), zap.Error(err))
+		return nil, true
+	}
+	if len(coverage.TestCases) == 0 {
+		return nil, coversChanged(coverage.Files, changed)
+	}
+	testCases, err := r.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the testcases to select by their coverage, running the test set", zap.String("test-set", testSetID))
+		return nil, true
+	}
+	selected := []string{}
+	for _, tc := range testCases {
+		files, ok := coverage.TestCases[tc.Name]
+		if !ok || coversChanged(files, changed) {
+			selected = append(selected, tc.Name)
+		}
+	}
+	return selected, len(selected) > 0
+}
+
+// intersect returns the testcases which are selected too.
+func intersect(testCases, selected []string) []string {
+	isSelected := ArrayToMap(selected)
+	res := []string{}
+	for _, tc := range testCases {
+		if isSelected[tc] {
+			res = append(res, tc)
+		}
+	}
+	return res
+}
+
+// storeCoverage writes the coverage map of the test set collected while it ran.
+func (r *Replayer) storeCoverage(ctx context.Context, testSetID string) {
+	coverage, err := r.coverage.testSetDone(ctx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the coverage of the test set", zap.String("test-set", testSetID))
+		return
+	}
+	err = writeCoverage(r.config.Path, testSetID, coverage)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to store the coverage of the test set", zap.String("test-set", testSetID))
+		return
+	}
+	r.logger.Debug("stored the coverage of the test set", zap.String("test-set", testSetID), zap.Int("files", len(coverage.Files)), zap.Int("testcases", len(coverage.TestCases)))
+}
+
+// profileCoverage returns the percentage of the statements covered in the go coverage profile, each block is counted
+// once if the profile has it more than once.
+func profileCoverage(profile string) (float64, error) {
+	data, err := os.ReadFile(profile)
+	if err != nil {
+		return 0, err
+	}
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	for _, line := range strings.Split(string(data), "\n") {
+		// file.go:12.34,15.2 3 1
+		fields := strings.Fields(line)
+		if len(fields) != 3 || strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		statements, err := strconv.Atoi(fields[1])
+		if err != nil {
+			continue
+		}
+		count, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		b, ok := blocks[fields[0]]
+		if !ok {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	var total, covered int
+	for _, b := range blocks {
+		total += b.statements
+		if b.covered {
+			covered += b.statements
+		}
+	}
+	if total == 0 {
+		return 0, fmt.Errorf("no statements in the coverage profile %s", profile)
+	}
+	return math.Round(float64(covered)/float64(total)*10000) / 100, nil
+}
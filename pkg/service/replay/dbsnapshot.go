@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.uber.org/zap"
+)
+
+// dbSnapshot restores the database of the app, running in a docker container, before each test set to its state
+// before the first one. The database is dumped with the client tools in its container once the app of the first
+// test set is ready, and the dump is restored once the app of each following test set is ready, so that the
+// testcases mutating the database don't change the responses of the next test sets.
+type dbSnapshot struct {
+	logger *zap.Logger
+	cfg    config.DBSnapshot
+	dump   []byte
+}
+
+func newDBSnapshot(logger *zap.Logger, cfg config.DBSnapshot) (*dbSnapshot, error) {
+	switch cfg.Type {
+	case "postgres", "mysql":
+	default:
+		return nil, fmt.Errorf("unsupported database %q for the snapshot, expected postgres or mysql", cfg.Type)
+	}
+	if cfg.Database == "" {
+		return nil, fmt.Errorf("missing the database of %s to snapshot", cfg.Container)
+	}
+	return &dbSnapshot{
+		logger: logger,
+		cfg:    cfg,
+	}, nil
+}
+
+// reset takes the snapshot of the database the first time it is called, and restores it after.
+func (s *dbSnapshot) reset(ctx context.Context) error {
+	if s.dump == nil {
+		dump, err := s.exec(ctx, nil, s.dumpCmd()...)
+		if err != nil {
+			return fmt.Errorf("failed to snapshot the database: %w", err)
+		}
+		s.dump = dump
+		s.logger.Info("took the snapshot of the database", zap.String("container", s.cfg.Container), zap.Int("size", len(dump)))
+		return nil
+	}
+	_, err := s.exec(ctx, s.dump, s.restoreCmd()...)
+	if err != nil {
+		return fmt.Errorf("failed to restore the database: %w", err)
+	}
+	s.logger.Info("restored the database to its snapshot", zap.String("container", s.cfg.Container))
+	return nil
+}
+
+// dumpCmd returns the command writing the dump of the database to stdout. The dump drops the tables before
+// creating them, so that it restores over the existing ones.
+func (s *dbSnapshot) dumpCmd() []string {
+	if s.cfg.Type == "mysql" {
+		return []string{"mysqldump", "-u", s.user(), "--add-drop-table", "--routines", s.cfg.Database}
+	}
+	return []string{"pg_dump", "-U", s.user(), "--clean", "--if-exists", "-d", s.cfg.Database}
+}
+
+// restoreCmd returns the command restoring the dump read from stdin.
+func (s *dbSnapshot) restoreCmd() []string {
+	if s.cfg.Type == "mysql" {
+		return []string{"mysql", "-u", s.user(), s.cfg.Database}
+	}
+	return []string{"psql", "-U", s.user(), "-d", s.cfg.Database, "-q", "-v", "ON_ERROR_STOP=1"}
+}
+
+func (s *dbSnapshot) user() string {
+	if s.cfg.User != "" {
+		return s.cfg.User
+	}
+	if s.cfg.Type == "mysql" {
+		return "root"
+	}
+	return "postgres"
+}
+
+// exec runs the command in the container of the database with the given stdin, returning its stdout. The password
+// is set in the environment of docker, which passes it on by its name only, so that it doesn't show in the arguments
+// of any process.
+func (s *dbSnapshot) exec(ctx context.Context, stdin []byte, command ...string) ([]byte, error) {
+	args := []string{"exec", "-i"}
+	var env []string
+	if s.cfg.Password != "" {
+		name := "PGPASSWORD"
+		if s.cfg.Type == "mysql" {
+			name = "MYSQL_PWD"
+		}
+		args = append(args, "-e", name)
+		env = append(os.Environ(), name+"="+s.cfg.Password)
+	}
+	args = append(append(args, s.cfg.Container), command...)
+
+	cmd := exec.CommandContext(ctx, "docker", args...)
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSpace(stderr.String()), err)
+	}
+	return stdout.Bytes(), nil
+}
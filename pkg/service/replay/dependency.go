@@ -0,0 +1,74 @@
+package replay
+
+import (
+	"sort"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// dependencyStats counts the mocks of each dependency of a test set, by the dependency recorded in their metadata,
+// and the ones consumed by its testcases.
+type dependencyStats struct {
+	// dependencies are the dependencies of the mocks by their name
+	dependencies map[string]string
+	stats        map[string]*models.DependencyReport
+	consumed     map[string]bool
+}
+
+func newDependencyStats(mocks ...[]*models.Mock) *dependencyStats {
+	d := &dependencyStats{
+		dependencies: map[string]string{},
+		stats:        map[string]*models.DependencyReport{},
+		consumed:     map[string]bool{},
+	}
+	for _, list := range mocks {
+		for _, mock := range list {
+			name := mock.Spec.Metadata[models.DependencyKey]
+			if name == "" {
+				continue
+			}
+			d.dependencies[mock.Name] = name
+			if d.stats[name] == nil {
+				d.stats[name] = &models.DependencyReport{Name: name}
+			}
+			d.stats[name].Mocks++
+		}
+	}
+	return d
+}
+
+// record counts the mocks consumed by the testcase, and the testcase as failed for each dependency it consumed the
+// mocks of if it failed.
+func (d *dependencyStats) record(consumed []string, passed bool) {
+	failed := map[string]bool{}
+	for _, mockName := range consumed {
+		name, ok := d.dependencies[mockName]
+		if !ok {
+			continue
+		}
+		if !d.consumed[mockName] {
+			d.consumed[mockName] = true
+			d.stats[name].Consumed++
+		}
+		if !passed && !failed[name] {
+			failed[name] = true
+			d.stats[name].Failed++
+		}
+	}
+}
+
+// report returns the stats of the dependencies sorted by their name, nil if the mocks weren't recorded with their
+// dependency.
+func (d *dependencyStats) report() []models.DependencyReport {
+	if len(d.stats) == 0 {
+		return nil
+	}
+	report := make([]models.DependencyReport, 0, len(d.stats))
+	for _, stats := range d.stats {
+		report = append(report, *stats)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Name < report[j].Name
+	})
+	return report
+}
@@ -0,0 +1,97 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// faketimeLibs are the default install paths of libfaketime, the LD_PRELOAD shim used to freeze the time of the app.
+var faketimeLibs = []string{
+	"/usr/lib/x86_64-linux-gnu/faketime/libfaketime.so.1",
+	"/usr/lib/aarch64-linux-gnu/faketime/libfaketime.so.1",
+	"/usr/local/lib/faketime/libfaketime.so.1",
+	"/usr/lib/faketime/libfaketime.so.1",
+}
+
+// timeFreezer fakes the clock_gettime/gettimeofday of the app with libfaketime. The time of the app is frozen at the
+// recorded time of the testcase being replayed, which is written to a timestamp file re-read by the shim on every call.
+// Statically linked binaries (e.g. go apps built without cgo) read the time without libc and are not affected.
+type timeFreezer struct {
+	logger *zap.Logger
+	lib    string
+	// dir is the temp dir of the timestamp file, removed with it at the end of the run
+	dir  string
+	file string
+}
+
+func newTimeFreezer(logger *zap.Logger, lib string) (*timeFreezer, error) {
+	if lib == "" {
+		for _, path := range faketimeLibs {
+			if _, err := os.Stat(path); err == nil {
+				lib = path
+				break
+			}
+		}
+		if lib == "" {
+			return nil, fmt.Errorf("libfaketime not found, install it or provide its path with freezeTimeLib")
+		}
+	} else if _, err := os.Stat(lib); err != nil {
+		return nil, fmt.Errorf("failed to find libfaketime at %s: %w", lib, err)
+	}
+
+	dir, err := os.MkdirTemp("", "keploy-faketime-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the dir of the faketime timestamp file: %w", err)
+	}
+	// the app runs as the user who invoked sudo, so the file is kept readable by everyone and writable by keploy only
+	err = os.Chmod(dir, 0755)
+	if err == nil {
+		var f *os.File
+		f, err = os.OpenFile(filepath.Join(dir, "timestamp"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			err = f.Close()
+		}
+	}
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to create the faketime timestamp file: %w", err)
+	}
+	return &timeFreezer{
+		logger: logger,
+		lib:    lib,
+		dir:    dir,
+		file:   filepath.Join(dir, "timestamp"),
+	}, nil
+}
+
+// env returns the environment variables which preload the shim in the app.
+func (f *timeFreezer) env() []string {
+	return []string{
+		"LD_PRELOAD=" + f.lib,
+		"FAKETIME_TIMESTAMP_FILE=" + f.file,
+		// re-read the timestamp file on every call instead of caching it
+		"FAKETIME_NO_CACHE=1",
+		// timers and timeouts of the app keep working with the real monotonic clock
+		"FAKETIME_DONT_FAKE_MONOTONIC=1",
+	}
+}
+
+// freeze sets the wall clock of the app to the given time, an empty file falls back to the real time.
+func (f *timeFreezer) freeze(t time.Time) error {
+	var timestamp []byte
+	if !t.IsZero() {
+		timestamp = []byte(t.Local().Format("2006-01-02 15:04:05") + "\n")
+	}
+	return os.WriteFile(f.file, timestamp, 0644)
+}
+
+func (f *timeFreezer) cleanup() {
+	err := os.RemoveAll(f.dir)
+	if err != nil {
+		f.logger.Debug("failed to remove the faketime timestamp file", zap.Error(err))
+	}
+}
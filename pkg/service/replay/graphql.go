@@ -0,0 +1,50 @@
+package replay
+
+import (
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// graphQLReq returns the GraphQL operation of the http request. The body is parsed for the testcases
+// recorded before the operation was stored separately.
+func graphQLReq(req models.HTTPReq) *models.GraphQLReq {
+	if req.GraphQL != nil {
+		return req.GraphQL
+	}
+	return pkg.ParseGraphQLRequest(string(req.Method), req.Header, req.Body)
+}
+
+// withGraphQLNoise returns the noise config with the noisy fields of the GraphQL operation of the testcase
+// added to the body noise. The given noise config is left untouched.
+func withGraphQLNoise(tc *models.TestCase, noiseConfig config.GlobalNoise, graphQLNoise config.GraphQLNoise) config.GlobalNoise {
+	gql := graphQLReq(tc.HTTPReq)
+	if gql == nil || len(graphQLNoise) == 0 {
+		return noiseConfig
+	}
+
+	noise := config.GlobalNoise{}
+	for k, fields := range noiseConfig {
+		noise[k] = map[string][]string{}
+		for field, regexArr := range fields {
+			noise[k][field] = regexArr
+		}
+	}
+	if noise["body"] == nil {
+		noise["body"] = map[string][]string{}
+	}
+	// the operation names are looked up regardless of their case, viper lowercases the keys of the config
+	for _, operation := range []string{"*", gql.OperationName} {
+		for name, fields := range graphQLNoise {
+			if !strings.EqualFold(name, operation) {
+				continue
+			}
+			for field, regexArr := range fields {
+				noise["body"][field] = regexArr
+			}
+		}
+	}
+	return noise
+}
@@ -0,0 +1,113 @@
+package replay
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/k0kubun/pp/v3"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// matchGrpc compares the actual grpc response with the expected response of the testcase after removing the noise.
+// The grpc-status trailer is asserted as the status code and the decoded message as the body of the response.
+func matchGrpc(tc *models.TestCase, actualResponse *models.GrpcResp, noiseConfig map[string]map[string][]string, logger *zap.Logger) (bool, *models.Result) {
+	pass := true
+	hRes := &[]models.HeaderResult{}
+
+	expStatus, _ := strconv.Atoi(tc.GrpcResp.Trailers.OrdinaryHeaders["grpc-status"])
+	actStatus, _ := strconv.Atoi(actualResponse.Trailers.OrdinaryHeaders["grpc-status"])
+
+	res := &models.Result{
+		StatusCode: models.IntResult{
+			Normal:   expStatus == actStatus,
+			Expected: expStatus,
+			Actual:   actStatus,
+		},
+		BodyResult: []models.BodyResult{{
+			Normal:   true,
+			Type:     models.BodyTypePlain,
+			Expected: tc.GrpcResp.Body.DecodedData,
+			Actual:   actualResponse.Body.DecodedData,
+		}},
+	}
+
+	headerNoise := copyNoise(noiseConfig["header"])
+	for field, regexArr := range tc.Noise {
+		a := strings.Split(field, ".")
+		if a[0] == "header" {
+			headerNoise[noiseKey("header", a[len(a)-1])] = regexArr
+		}
+	}
+
+	_, isBodyNoisy := tc.Noise["body"]
+	if !isBodyNoisy && tc.GrpcResp.Body.DecodedData != actualResponse.Body.DecodedData {
+		res.BodyResult[0].Normal = false
+		pass = false
+	}
+
+	if !CompareHeaders(grpcHeadersToHTTP(tc.GrpcResp), grpcHeadersToHTTP(*actualResponse), hRes, headerNoise) {
+		pass = false
+	}
+	res.HeadersResult = *hRes
+
+	if !res.StatusCode.Normal {
+		pass = false
+	}
+
+	newLogger := pp.New()
+	newLogger.WithLineInfo = false
+	if !pass {
+		logDiffs := NewDiffsPrinter(tc.Name)
+		newLogger.SetColorScheme(models.FailingColorScheme)
+		logs := newLogger.Sprintf("Testrun failed for testcase with id: %s\n\n--------------------------------------------------------------------\n\n", tc.Name)
+
+		if !res.StatusCode.Normal {
+			logDiffs.PushStatusDiff(fmt.Sprint(res.StatusCode.Expected), fmt.Sprint(res.StatusCode.Actual))
+		}
+		for _, j := range res.HeadersResult {
+			if !j.Normal {
+				logDiffs.PushHeaderDiff(fmt.Sprint(j.Expected.Value), fmt.Sprint(j.Actual.Value), j.Expected.Key, headerNoise)
+			}
+		}
+		if !res.BodyResult[0].Normal {
+			logDiffs.PushBodyDiff(tc.GrpcResp.Body.DecodedData, actualResponse.Body.DecodedData, map[string][]string{})
+		}
+
+		_, err := newLogger.Printf(logs)
+		if err != nil {
+			utils.LogError(logger, err, "failed to print the logs")
+		}
+		err = logDiffs.Render()
+		if err != nil {
+			utils.LogError(logger, err, "failed to render the diffs")
+		}
+	} else {
+		newLogger.SetColorScheme(models.PassingColorScheme)
+		log := newLogger.Sprintf("Testrun passed for testcase with id: %s\n\n--------------------------------------------------------------------\n\n", tc.Name)
+		_, err := newLogger.Printf(log)
+		if err != nil {
+			utils.LogError(logger, err, "failed to print the logs")
+		}
+	}
+	return pass, res
+}
+
+// grpcHeadersToHTTP merges the ordinary headers and trailers of the grpc response, except the grpc-status
+// which is asserted as the status code, into a http header.
+func grpcHeadersToHTTP(resp models.GrpcResp) http.Header {
+	header := http.Header{}
+	for k, v := range resp.Headers.OrdinaryHeaders {
+		header[k] = []string{v}
+	}
+	for k, v := range resp.Trailers.OrdinaryHeaders {
+		if k == "grpc-status" {
+			continue
+		}
+		header[k] = []string{v}
+	}
+	return header
+}
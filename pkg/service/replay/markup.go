@@ -0,0 +1,266 @@
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"golang.org/x/net/html"
+)
+
+// markupNode is an element of an XML or HTML body, or a text node if its name is empty. The attributes are kept in
+// a map, as their order is insignificant, and the comments, the doctype and the processing instructions are dropped.
+type markupNode struct {
+	name     string
+	attrs    map[string]string
+	children []*markupNode
+	text     string
+}
+
+// markupDiff is a value of the bodies which differs, at the path of its element, e.g. html.body.div.span, or of
+// its attribute, e.g. html.body.form.input.@value. The paths are the keys of the body noise of the markup bodies.
+type markupDiff struct {
+	path     string
+	expected string
+	actual   string
+}
+
+// markupBodyType returns whether the body is XML or HTML by the content type of the response, or by the start of
+// the body if it has none.
+func markupBodyType(header map[string]string, body string) models.BodyType {
+	contentType := ""
+	for key, value := range header {
+		if strings.EqualFold(key, "Content-Type") {
+			contentType = strings.ToLower(value)
+		}
+	}
+	switch {
+	case strings.Contains(contentType, "html"):
+		return models.BodyTypeHTML
+	case strings.Contains(contentType, "xml"):
+		return models.BodyTypeXML
+	case contentType != "":
+		return models.BodyTypePlain
+	}
+	start := strings.ToLower(strings.TrimSpace(body))
+	switch {
+	case strings.HasPrefix(start, "<!doctype html"), strings.HasPrefix(start, "<html"):
+		return models.BodyTypeHTML
+	case strings.HasPrefix(start, "<?xml"):
+		return models.BodyTypeXML
+	}
+	return models.BodyTypePlain
+}
+
+// parseMarkup parses the body into the tree of its elements. The contents of the script and style elements of an
+// HTML body are replaced with their sha256 if hashAssets is set, so that a changed bundle is one differing value.
+func parseMarkup(bodyType models.BodyType, body string, hashAssets bool) (*markupNode, error) {
+	if bodyType == models.BodyTypeHTML {
+		doc, err := html.Parse(strings.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		root := &markupNode{}
+		appendHTML(root, doc, false, hashAssets)
+		return root, nil
+	}
+
+	root := &markupNode{}
+	stack := []*markupNode{root}
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent := stack[len(stack)-1]
+		switch t := token.(type) {
+		case xml.StartElement:
+			node := &markupNode{name: t.Name.Local, attrs: map[string]string{}}
+			for _, attr := range t.Attr {
+				name := attr.Name.Local
+				if attr.Name.Space != "" {
+					name = attr.Name.Space + ":" + name
+				}
+				node.attrs[name] = attr.Value
+			}
+			parent.children = append(parent.children, node)
+			stack = append(stack, node)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			// the whitespace around the text is insignificant, as is the indentation between the elements
+			appendText(parent, strings.TrimSpace(string(t)))
+		}
+	}
+	return root, nil
+}
+
+func appendHTML(parent *markupNode, n *html.Node, pre bool, hashAssets bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		switch c.Type {
+		case html.ElementNode:
+			node := &markupNode{name: c.Data, attrs: map[string]string{}}
+			for _, attr := range c.Attr {
+				name := attr.Key
+				if attr.Namespace != "" {
+					name = attr.Namespace + ":" + name
+				}
+				node.attrs[name] = attr.Val
+			}
+			parent.children = append(parent.children, node)
+			if hashAssets && (c.Data == "script" || c.Data == "style") {
+				var content strings.Builder
+				for t := c.FirstChild; t != nil; t = t.NextSibling {
+					content.WriteString(t.Data)
+				}
+				if text := strings.TrimSpace(content.String()); text != "" {
+					sum := sha256.Sum256([]byte(text))
+					appendText(node, "sha256:"+hex.EncodeToString(sum[:]))
+				}
+				continue
+			}
+			appendHTML(node, c, pre || c.Data == "pre" || c.Data == "textarea", hashAssets)
+		case html.TextNode:
+			if pre {
+				appendText(parent, c.Data)
+				continue
+			}
+			// the runs of whitespace render as a single space outside of the preformatted elements
+			appendText(parent, strings.Join(strings.Fields(c.Data), " "))
+		}
+	}
+}
+
+// appendText adds the text to the node, merging it with the text before it whose comment was dropped.
+func appendText(parent *markupNode, text string) {
+	if text == "" {
+		return
+	}
+	if n := len(parent.children); n > 0 && parent.children[n-1].name == "" {
+		parent.children[n-1].text += " " + text
+		return
+	}
+	parent.children = append(parent.children, &markupNode{text: text})
+}
+
+// compareMarkup compares the children and the attributes of the elements, in the order of the children. The
+// subtree of a noisy path without regexes is skipped, and the text or the attribute of a noisy path with regexes
+// is skipped if its expected value matches any of them.
+func compareMarkup(path string, expected, actual *markupNode, noise map[string][]string, diffs *[]markupDiff) {
+	isNoisy := func(path string, value string) bool {
+		regexArr, ok := CheckStringExist(path, noise)
+		if !ok || len(regexArr) == 0 {
+			return ok
+		}
+		ok, _ = MatchesAnyRegex(value, regexArr)
+		return ok
+	}
+	if path != "" {
+		if regexArr, ok := CheckStringExist(path, noise); ok && len(regexArr) == 0 {
+			return
+		}
+	}
+
+	names := map[string]bool{}
+	for name := range expected.attrs {
+		names[name] = true
+	}
+	for name := range actual.attrs {
+		names[name] = true
+	}
+	for name := range names {
+		exp, act := expected.attrs[name], actual.attrs[name]
+		attrPath := path + ".@" + name
+		if exp != act && !isNoisy(attrPath, exp) {
+			*diffs = append(*diffs, markupDiff{path: attrPath, expected: exp, actual: act})
+		}
+	}
+
+	for i := 0; i < len(expected.children) || i < len(actual.children); i++ {
+		var exp, act *markupNode
+		if i < len(expected.children) {
+			exp = expected.children[i]
+		}
+		if i < len(actual.children) {
+			act = actual.children[i]
+		}
+		switch {
+		case exp != nil && act != nil && exp.name == "" && act.name == "":
+			if exp.text != act.text && !isNoisy(path, exp.text) {
+				*diffs = append(*diffs, markupDiff{path: path, expected: exp.text, actual: act.text})
+			}
+		case exp != nil && act != nil && exp.name == act.name:
+			compareMarkup(strings.TrimPrefix(path+"."+exp.name, "."), exp, act, noise, diffs)
+		default:
+			diff := markupDiff{path: path}
+			if exp != nil {
+				diff.expected = renderMarkup(exp)
+				diff.path = childPath(path, exp)
+			}
+			if act != nil {
+				diff.actual = renderMarkup(act)
+				if exp == nil {
+					diff.path = childPath(path, act)
+				}
+			}
+			if !isNoisy(diff.path, diff.expected) {
+				*diffs = append(*diffs, diff)
+			}
+		}
+	}
+}
+
+func childPath(path string, n *markupNode) string {
+	if n.name == "" {
+		return path
+	}
+	return strings.TrimPrefix(path+"."+n.name, ".")
+}
+
+// renderMarkup renders the node canonically, with its attributes sorted, for the diffs of the missing elements.
+func renderMarkup(n *markupNode) string {
+	if n.name == "" {
+		return n.text
+	}
+	var b strings.Builder
+	b.WriteString("<" + n.name)
+	names := make([]string, 0, len(n.attrs))
+	for name := range n.attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteString(" " + name + "=\"" + n.attrs[name] + "\"")
+	}
+	b.WriteString(">")
+	for _, c := range n.children {
+		b.WriteString(renderMarkup(c))
+	}
+	b.WriteString("</" + n.name + ">")
+	return b.String()
+}
+
+// matchMarkup compares the XML or HTML bodies canonically, the bodies which fail to parse are compared as they are.
+func matchMarkup(bodyType models.BodyType, expected, actual string, noise map[string][]string, hashAssets bool) (bool, []markupDiff) {
+	exp, err := parseMarkup(bodyType, expected, hashAssets)
+	if err != nil {
+		return expected == actual, nil
+	}
+	act, err := parseMarkup(bodyType, actual, hashAssets)
+	if err != nil {
+		return expected == actual, nil
+	}
+	var diffs []markupDiff
+	compareMarkup("", exp, act, noise, &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].path < diffs[j].path })
+	return len(diffs) == 0, diffs
+}
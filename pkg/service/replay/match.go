@@ -22,6 +22,7 @@ import (
 	"github.com/wI2L/jsondiff"
 	"github.com/yudai/gojsondiff"
 	"github.com/yudai/gojsondiff/formatter"
+	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
 	"go.keploy.io/server/v2/utils"
@@ -39,10 +40,20 @@ type JSONComparisonResult struct {
 	differences []string // Lists the keys or indices of values that are not the same
 }
 
-func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, ignoreOrdering bool, logger *zap.Logger) (bool, *models.Result) {
+// Match compares the actual response with the expected response of the testcase after removing the noise.
+// It allows the responses to be asserted outside of a test run, e.g. while verifying the contracts.
+// The arrays of the JSON bodies are matched as set in arrayMatching by their field, and in order unless ignoreOrdering.
+// The XML and HTML bodies are compared canonically, the inline scripts and styles by their sha256 if hashAssets is set.
+func Match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, ignoreOrdering bool, arrayMatching map[string]string, hashAssets bool, logger *zap.Logger) (bool, *models.Result) {
+	return match(tc, actualResponse, noiseConfig, ignoreOrdering, arrayMatching, hashAssets, logger)
+}
+
+func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map[string]map[string][]string, ignoreOrdering bool, arrayMatching map[string]string, hashAssets bool, logger *zap.Logger) (bool, *models.Result) {
 	bodyType := models.BodyTypePlain
 	if json.Valid([]byte(actualResponse.Body)) {
 		bodyType = models.BodyTypeJSON
+	} else {
+		bodyType = markupBodyType(tc.HTTPResp.Header, tc.HTTPResp.Body)
 	}
 	pass := true
 	hRes := &[]models.HeaderResult{}
@@ -62,31 +73,26 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 	}
 	noise := tc.Noise
 
+	// the noise of the testcase is added to a copy of the noise config, which is shared by the testcases
 	var (
-		bodyNoise   = noiseConfig["body"]
-		headerNoise = noiseConfig["header"]
+		bodyNoise   = copyNoise(noiseConfig["body"])
+		headerNoise = copyNoise(noiseConfig["header"])
 	)
 
-	if bodyNoise == nil {
-		bodyNoise = map[string][]string{}
-	}
-	if headerNoise == nil {
-		headerNoise = map[string][]string{}
-	}
-
 	for field, regexArr := range noise {
 		a := strings.Split(field, ".")
 		if len(a) > 1 && a[0] == "body" {
 			x := strings.Join(a[1:], ".")
-			bodyNoise[x] = regexArr
+			bodyNoise[noiseKey("body", x)] = regexArr
 		} else if a[0] == "header" {
-			headerNoise[a[len(a)-1]] = regexArr
+			headerNoise[noiseKey("header", a[len(a)-1])] = regexArr
 		}
 	}
 
 	// stores the json body after removing the noise
 	cleanExp, cleanAct := tc.HTTPResp.Body, actualResponse.Body
 	var jsonComparisonResult JSONComparisonResult
+	var markupDiffs []markupDiff
 	if !Contains(MapToArray(noise), "body") && bodyType == models.BodyTypeJSON {
 		//validate the stored json
 		validatedJSON, err := ValidateAndMarshalJSON(logger, &cleanExp, &cleanAct)
@@ -94,7 +100,7 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 			return false, res
 		}
 		if validatedJSON.isIdentical {
-			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering)
+			jsonComparisonResult, err = JSONDiffWithNoiseControl(validatedJSON, bodyNoise, ignoreOrdering, arrayModes(arrayMatching))
 			pass = jsonComparisonResult.isExact
 			if err != nil {
 				return false, res
@@ -106,6 +112,8 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 		// debug log for cleanExp and cleanAct
 		logger.Debug("cleanExp", zap.Any("", cleanExp))
 		logger.Debug("cleanAct", zap.Any("", cleanAct))
+	} else if !Contains(MapToArray(noise), "body") && (bodyType == models.BodyTypeXML || bodyType == models.BodyTypeHTML) {
+		pass, markupDiffs = matchMarkup(bodyType, tc.HTTPResp.Body, actualResponse.Body, bodyNoise, hashAssets)
 	} else {
 		if !Contains(MapToArray(noise), "body") && tc.HTTPResp.Body != actualResponse.Body {
 			pass = false
@@ -176,6 +184,14 @@ func match(tc *models.TestCase, actualResponse *models.HTTPResp, noiseConfig map
 					logDiffs.PushBodyDiff(fmt.Sprint(op.OldValue), fmt.Sprint(op.Value), bodyNoise)
 
 				}
+			} else if len(markupDiffs) > 0 {
+				// only the differing values of the elements are shown, the bodies differ in their formatting too
+				var exp, act strings.Builder
+				for _, diff := range markupDiffs {
+					fmt.Fprintf(&exp, "%s: %s\n", diff.path, diff.expected)
+					fmt.Fprintf(&act, "%s: %s\n", diff.path, diff.actual)
+				}
+				logDiffs.PushBodyDiff(exp.String(), act.String(), bodyNoise)
 			} else {
 				logDiffs.PushBodyDiff(fmt.Sprint(tc.HTTPResp.Body), fmt.Sprint(actualResponse.Body), bodyNoise)
 			}
@@ -248,9 +264,11 @@ func InterfaceToString(val interface{}) string {
 	}
 }
 
-func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string, ignoreOrdering bool) (JSONComparisonResult, error) {
+// JSONDiffWithNoiseControl compares the JSON bodies without their noise. The elements of the arrays are matched as
+// set in arrayMatching by the field of the array, the arrays missing from it are matched in order unless ignoreOrdering.
+func JSONDiffWithNoiseControl(validatedJSON ValidatedJSON, noise map[string][]string, ignoreOrdering bool, arrayMatching map[string]string) (JSONComparisonResult, error) {
 	var matchJSONComparisonResult JSONComparisonResult
-	matchJSONComparisonResult, err := matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, ignoreOrdering)
+	matchJSONComparisonResult, err := matchJSONWithNoiseHandling("", validatedJSON.expected, validatedJSON.actual, noise, ignoreOrdering, arrayMatching)
 	if err != nil {
 		return matchJSONComparisonResult, err
 	}
@@ -289,7 +307,7 @@ func ValidateAndMarshalJSON(log *zap.Logger, exp, act *string) (ValidatedJSON, e
 }
 
 // matchJSONWithNoiseHandling returns strcut if expected and actual JSON objects matches(are equal) and in exact order(isExact).
-func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseMap map[string][]string, ignoreOrdering bool) (JSONComparisonResult, error) {
+func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseMap map[string][]string, ignoreOrdering bool, arrayMatching map[string]string) (JSONComparisonResult, error) {
 	var matchJSONComparisonResult JSONComparisonResult
 	if reflect.TypeOf(expected) != reflect.TypeOf(actual) {
 		return matchJSONComparisonResult, errors.New("type not matched")
@@ -307,8 +325,13 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 	switch x.Kind() {
 	case reflect.Float64, reflect.String, reflect.Bool:
 		regexArr, isNoisy := CheckStringExist(key, noiseMap)
-		if isNoisy && len(regexArr) != 0 {
-			isNoisy, _ = MatchesAnyRegex(InterfaceToString(expected), regexArr)
+		tolerances, regexArr := splitTolerances(regexArr)
+		if isNoisy && (len(regexArr) != 0 || len(tolerances) != 0) {
+			// the numbers within a tolerance are equal, the field is not ignored
+			isNoisy = withinTolerance(tolerances, expected, actual)
+			if !isNoisy && len(regexArr) != 0 {
+				isNoisy, _ = MatchesAnyRegex(InterfaceToString(expected), regexArr)
+			}
 		}
 		if expected != actual && !isNoisy {
 			return matchJSONComparisonResult, nil
@@ -336,7 +359,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 			if !ok {
 				return matchJSONComparisonResult, nil
 			}
-			if valueMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(prefix+k, v, val, noiseMap, ignoreOrdering); !valueMatchJSONComparisonResult.matches || er != nil {
+			if valueMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(prefix+k, v, val, noiseMap, ignoreOrdering, arrayMatching); !valueMatchJSONComparisonResult.matches || er != nil {
 				return valueMatchJSONComparisonResult, nil
 			} else if !valueMatchJSONComparisonResult.isExact {
 				isExact = false
@@ -362,12 +385,25 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 		matchJSONComparisonResult.differences = append(matchJSONComparisonResult.differences, differences...)
 		return matchJSONComparisonResult, nil
 	case reflect.Slice:
+		// the elements of an array with tolerances are compared with them
 		if regexArr, isNoisy := CheckStringExist(key, noiseMap); isNoisy && len(regexArr) != 0 {
-			break
+			if _, regexes := splitTolerances(regexArr); len(regexes) != 0 {
+				break
+			}
 		}
 		expSlice := reflect.ValueOf(expected)
 		actSlice := reflect.ValueOf(actual)
-		if expSlice.Len() != actSlice.Len() {
+		mode := arrayMode(key, arrayMatching, ignoreOrdering)
+		switch {
+		case mode == config.ArrayLength:
+			matchJSONComparisonResult.matches = expSlice.Len() == actSlice.Len()
+			matchJSONComparisonResult.isExact = matchJSONComparisonResult.matches
+			return matchJSONComparisonResult, nil
+		case strings.HasPrefix(mode, config.ArrayKeyPrefix):
+			return matchArrayByKey(key, strings.TrimPrefix(mode, config.ArrayKeyPrefix), expSlice, actSlice, noiseMap, ignoreOrdering, arrayMatching), nil
+		}
+		// the expected elements of a subset are found among any number of actual ones
+		if expSlice.Len() != actSlice.Len() && mode != config.ArraySubset {
 			return matchJSONComparisonResult, nil
 		}
 		isMatched := true
@@ -375,7 +411,7 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 		for i := 0; i < expSlice.Len(); i++ {
 			matched := false
 			for j := 0; j < actSlice.Len(); j++ {
-				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, ignoreOrdering); err == nil && valMatchJSONComparisonResult.matches {
+				if valMatchJSONComparisonResult, err := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(j).Interface(), noiseMap, ignoreOrdering, arrayMatching); err == nil && valMatchJSONComparisonResult.matches {
 					if !valMatchJSONComparisonResult.isExact {
 						for _, val := range valMatchJSONComparisonResult.differences {
 							prefixedVal := key + "[" + fmt.Sprint(j) + "]." + val // Prefix the value
@@ -398,9 +434,9 @@ func matchJSONWithNoiseHandling(key string, expected, actual interface{}, noiseM
 			matchJSONComparisonResult.isExact = isExact
 			return matchJSONComparisonResult, nil
 		}
-		if !ignoreOrdering {
+		if mode == config.ArrayOrdered {
 			for i := 0; i < expSlice.Len(); i++ {
-				if valMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(i).Interface(), noiseMap, ignoreOrdering); er != nil || !valMatchJSONComparisonResult.isExact {
+				if valMatchJSONComparisonResult, er := matchJSONWithNoiseHandling(key, expSlice.Index(i).Interface(), actSlice.Index(i).Interface(), noiseMap, ignoreOrdering, arrayMatching); er != nil || !valMatchJSONComparisonResult.isExact {
 					isExact = false
 					break
 				}
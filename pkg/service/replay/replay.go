@@ -16,7 +16,9 @@ import (
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
 	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/tracing"
 	"go.keploy.io/server/v2/utils"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
@@ -42,6 +44,17 @@ type Replayer struct {
 	telemetry       Telemetry
 	instrumentation Instrumentation
 	config          config.Config
+	// timeFreezer freezes the time of the app at the recorded time of the testcases, nil if it is disabled.
+	timeFreezer *timeFreezer
+	// dbSnapshot restores the database of the app before each test set, nil if it is disabled.
+	dbSnapshot *dbSnapshot
+	// tui draws the live progress of the test run over the terminal, nil if it is disabled.
+	tui *tui
+	// coverage collects the coverage map of each test set and testcase, nil if go coverage is disabled.
+	coverage *coverageCollector
+	// changedTests are the testcases of each test set covering the files changed since Test.Changed, for the test
+	// sets with a coverage map per testcase.
+	changedTests map[string][]string
 }
 
 func NewReplayer(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, telemetry Telemetry, instrumentation Instrumentation, config config.Config) Service {
@@ -67,6 +80,9 @@ func (r *Replayer) Start(ctx context.Context) error {
 	g, ctx := errgroup.WithContext(ctx)
 	ctx = context.WithValue(ctx, models.ErrGroupKey, g)
 
+	ctx, span := tracing.Start(ctx, "test")
+	defer span.End()
+
 	var stopReason = "replay completed successfully"
 	var hookCancel context.CancelFunc
 
@@ -76,7 +92,7 @@ func (r *Replayer) Start(ctx context.Context) error {
 		case <-ctx.Done():
 			break
 		default:
-			err := utils.Stop(r.logger, stopReason)
+			err := utils.StopSession(ctx, r.logger, stopReason)
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to stop recording")
 			}
@@ -84,6 +100,9 @@ func (r *Replayer) Start(ctx context.Context) error {
 		if hookCancel != nil {
 			hookCancel()
 		}
+		if r.tui != nil {
+			r.tui.close()
+		}
 		err := g.Wait()
 		if err != nil {
 			utils.LogError(r.logger, err, "failed to stop recording")
@@ -118,9 +137,31 @@ func (r *Replayer) Start(ctx context.Context) error {
 		return fmt.Errorf(stopReason)
 	}
 
+	// the test sets are selected by the files changed since the revision, if given, from their coverage maps
+	var changed []string
+	if r.config.Test.Changed != "" {
+		changed, err = changedFiles(ctx, r.config.Test.Changed)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the changed files, running all the test sets")
+		} else if changed == nil {
+			changed = []string{}
+		}
+	}
+	// the coverage map of each test set is collected from the go coverage counters written by the app
+	if coverDir := os.Getenv("GOCOVERDIR"); utils.CmdType(r.config.CommandType) == utils.Native && r.config.Test.GoCoverage && coverDir != "" {
+		r.coverage = newCoverageCollector(coverDir)
+	}
+
+	if r.config.Test.TUI {
+		r.tui = newTUI(os.Stdout)
+		r.tui.start()
+	}
+
 	testSetResult := false
 	testRunResult := true
 	abortTestRun := false
+	// the test sets run, whose failed testcases are annotated for the CI
+	var ranTestSets []string
 
 	for _, testSetID := range testSetIDs {
 
@@ -128,7 +169,38 @@ func (r *Replayer) Start(ctx context.Context) error {
 			continue
 		}
 
+		if changed != nil {
+			testCases, run := r.changedTestCases(ctx, testSetID, changed)
+			if selected := r.config.Test.SelectedTests[testSetID]; testCases != nil && len(selected) != 0 {
+				testCases = intersect(testCases, selected)
+				run = len(testCases) > 0
+			}
+			if !run {
+				r.logger.Info("skipping the test set, it covers none of the changed files", zap.String("test-set", testSetID))
+				continue
+			}
+			if testCases != nil {
+				if r.changedTests == nil {
+					r.changedTests = map[string][]string{}
+				}
+				r.changedTests[testSetID] = testCases
+				r.logger.Info("running only the testcases covering the changed files", zap.String("test-set", testSetID), zap.Strings("testcases", testCases))
+			}
+		}
+
+		coverageStarted := false
+		if r.coverage != nil {
+			err = r.coverage.start()
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to read the go coverage directory", zap.String("dir", r.coverage.dir))
+			}
+			coverageStarted = err == nil
+		}
+
 		testSetStatus, err := r.RunTestSet(ctx, testSetID, testRunID, appID, false)
+		if coverageStarted {
+			r.storeCoverage(ctx, testSetID)
+		}
 		if err != nil {
 			stopReason = fmt.Sprintf("failed to run test set: %v", err)
 			utils.LogError(r.logger, err, stopReason)
@@ -137,6 +209,7 @@ func (r *Replayer) Start(ctx context.Context) error {
 			}
 			return fmt.Errorf(stopReason)
 		}
+		ranTestSets = append(ranTestSets, testSetID)
 		switch testSetStatus {
 		case models.TestSetStatusAppHalted:
 			testSetResult = false
@@ -160,14 +233,20 @@ func (r *Replayer) Start(ctx context.Context) error {
 		}
 	}
 
+	r.annotate(ctx, testRunID, ranTestSets)
+
 	testRunStatus := "fail"
 	if testRunResult {
 		testRunStatus = "pass"
 	}
 	r.telemetry.TestRun(totalTestPassed, totalTestFailed, len(testSetIDs), testRunStatus)
 
+	// the summary is printed on the terminal restored from the terminal ui
+	if r.tui != nil {
+		r.tui.close()
+	}
 	if !abortTestRun {
-		r.printSummary(ctx, testRunResult)
+		r.printSummary(ctx, testRunID, testRunResult)
 	}
 	return nil
 }
@@ -175,6 +254,7 @@ func (r *Replayer) Start(ctx context.Context) error {
 func (r *Replayer) BootReplay(ctx context.Context) (string, uint64, context.CancelFunc, error) {
 
 	var cancel context.CancelFunc
+	booted := false
 
 	testRunIDs, err := r.reportDB.GetAllTestRunIDs(ctx)
 	if err != nil {
@@ -186,7 +266,38 @@ func (r *Replayer) BootReplay(ctx context.Context) (string, uint64, context.Canc
 
 	newTestRunID := pkg.NewID(testRunIDs, models.TestRunTemplateName)
 
-	appID, err := r.instrumentation.Setup(ctx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay})
+	var appEnv []string
+	if r.config.Test.FreezeTime {
+		cmdType := utils.FindDockerCmd(r.config.Command)
+		if cmdType == utils.Docker || cmdType == utils.DockerCompose {
+			r.logger.Warn("freezing the time is not supported for docker apps, preload libfaketime in the image to freeze its time")
+		} else {
+			freezer, err := newTimeFreezer(r.logger, r.config.Test.FreezeTimeLib)
+			if err != nil {
+				return "", 0, nil, fmt.Errorf("failed to setup the time freezing: %w", err)
+			}
+			r.timeFreezer = freezer
+			appEnv = freezer.env()
+			// the timestamp file is removed with the hooks when the run ends, or now if the boot fails
+			defer func() {
+				if !booted {
+					freezer.cleanup()
+					r.timeFreezer = nil
+				}
+			}()
+		}
+	}
+
+	if r.config.Test.DBSnapshot.Container != "" {
+		r.dbSnapshot, err = newDBSnapshot(r.logger, r.config.Test.DBSnapshot)
+		if err != nil {
+			return "", 0, nil, fmt.Errorf("failed to setup the database snapshot: %w", err)
+		}
+	}
+
+	setupCtx, setupSpan := tracing.Start(ctx, "setup")
+	appID, err := r.instrumentation.Setup(setupCtx, r.config.Command, models.SetupOptions{Container: r.config.ContainerName, DockerNetwork: r.config.NetworkName, DockerDelay: r.config.BuildDelay, Env: appEnv, RandomSeed: r.config.RandomSeed, Client: r.config.Client})
+	tracing.End(setupSpan, err)
 	if err != nil {
 		if errors.Is(err, context.Canceled) {
 			return "", 0, nil, err
@@ -201,7 +312,10 @@ func (r *Replayer) BootReplay(ctx context.Context) (string, uint64, context.Canc
 	default:
 		hookCtx := context.WithoutCancel(ctx)
 		hookCtx, cancel = context.WithCancel(hookCtx)
+		// the hooks and proxy outlive the span, so the spans of the mock matching are the children of the test span
+		_, loadSpan := tracing.Start(hookCtx, "hook load")
 		err = r.instrumentation.Hook(hookCtx, appID, models.HookOptions{Mode: models.MODE_TEST, EnableTesting: r.config.EnableTesting})
+		tracing.End(loadSpan, err)
 		if err != nil {
 			cancel()
 			if errors.Is(err, context.Canceled) {
@@ -211,6 +325,14 @@ func (r *Replayer) BootReplay(ctx context.Context) (string, uint64, context.Canc
 		}
 	}
 
+	if freezer := r.timeFreezer; freezer != nil {
+		stop := cancel
+		cancel = func() {
+			stop()
+			freezer.cleanup()
+		}
+	}
+	booted = true
 	return newTestRunID, appID, cancel, nil
 }
 
@@ -226,6 +348,22 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 
 	runTestSetCtx, runTestSetCtxCancel := context.WithCancel(runTestSetCtx)
 
+	runTestSetCtx, span := tracing.Start(runTestSetCtx, "test set", attribute.String("test-set", testSetID))
+	defer span.End()
+
+	testSetEnv := "KEPLOY_TEST_SET=" + testSetID
+	if err := utils.RunLifecycleHook(runTestSetCtx, r.logger, "preTestSet", r.config.Hooks.PreTestSet, testSetEnv); err != nil {
+		utils.LogError(r.logger, err, "failed to run the preTestSet hook", zap.Any("testSet", testSetID))
+		runTestSetCtxCancel()
+		return models.TestSetStatusFailed, nil
+	}
+	// the postTestSet hook runs after the app is stopped, with the context of the test set canceled by then
+	defer func() {
+		if err := utils.RunLifecycleHook(context.WithoutCancel(ctx), r.logger, "postTestSet", r.config.Hooks.PostTestSet, testSetEnv); err != nil {
+			utils.LogError(r.logger, err, "failed to run the postTestSet hook", zap.Any("testSet", testSetID))
+		}
+	}()
+
 	exitLoopChan := make(chan bool, 2)
 	defer func() {
 		runTestSetCtxCancel()
@@ -241,6 +379,11 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	var success int
 	var failure int
 	var totalConsumedMocks = map[string]bool{}
+	var chaosReport chaosVerdict
+	// proxyErrors counts the errors of the proxy while mocking the outgoing calls of the test set, by type
+	var proxyErrors map[models.ProxyErrorType]int
+	// latencies of the testcases having a recorded latency, used for the timing report of the test set
+	var recordedLatencies, actualLatencies []time.Duration
 
 	testSetStatus := models.TestSetStatusPassed
 	testSetStatusByErrChan := models.TestSetStatusRunning
@@ -252,26 +395,58 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		return models.TestSetStatusFailed, fmt.Errorf("failed to get test cases: %w", err)
 	}
 
+	if len(r.config.Test.Tags) > 0 {
+		tagged := testCases[:0]
+		for _, tc := range testCases {
+			if tc.HasAnyTag(r.config.Test.Tags) {
+				tagged = append(tagged, tc)
+			}
+		}
+		testCases = tagged
+	}
+
 	if len(testCases) == 0 {
 		return models.TestSetStatusPassed, nil
 	}
 
+	_, loadMocksSpan := tracing.Start(runTestSetCtx, "load mocks")
 	filteredMocks, err := r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, models.BaseTime, time.Now())
 	if err != nil {
+		tracing.End(loadMocksSpan, err)
 		utils.LogError(r.logger, err, "failed to get filtered mocks")
 		return models.TestSetStatusFailed, err
 	}
 	unfilteredMocks, err := r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, models.BaseTime, time.Now())
+	tracing.End(loadMocksSpan, err)
 	if err != nil {
 		utils.LogError(r.logger, err, "failed to get unfiltered mocks")
 		return models.TestSetStatusFailed, err
 	}
+	// the mocks recorded while serving a testcase, by the correlation id of the testcase
+	correlatedMocks := correlateMocks(filteredMocks, unfilteredMocks)
+	dependencies := newDependencyStats(filteredMocks, unfilteredMocks)
+
+	sharedMocks, err := r.sharedMocks(runTestSetCtx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the shared mocks")
+		return models.TestSetStatusFailed, err
+	}
 
 	err = r.instrumentation.MockOutgoing(runTestSetCtx, appID, models.OutgoingOptions{
-		Rules:          r.config.BypassRules,
-		MongoPassword:  r.config.Test.MongoPassword,
-		SQLDelay:       time.Duration(r.config.Test.Delay),
-		FallBackOnMiss: r.config.Test.FallBackOnMiss,
+		Rules:             r.config.BypassRules,
+		MongoPassword:     r.config.Test.MongoPassword,
+		SQLDelay:          time.Duration(r.config.Test.Delay),
+		FallBackOnMiss:    r.config.Test.FallBackOnMiss && !r.config.Test.StrictMocking,
+		StrictMocking:     r.config.Test.StrictMocking,
+		LatencyMultiplier: r.config.Test.SimulateLatency,
+		Chaos:             r.chaosOptions(testSetID),
+		ReadTimeout:       r.config.ProxyReadTimeout,
+		StreamChunkSize:   r.config.Test.StreamChunkSize,
+		SharedMocks:       sharedMocks,
+		Revalidate:        r.config.Test.Conditional == config.ConditionalRevalidate,
+		// the recorded tokens are still valid at the frozen time of the app
+		OAuthTokens: r.config.Test.OAuthTokens && !r.config.Test.FreezeTime,
+		OnMockEvent: r.onMockEvent(),
 	})
 	if err != nil {
 		utils.LogError(r.logger, err, "failed to mock outgoing")
@@ -324,14 +499,36 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		return nil
 	})
 
-	// Delay for user application to run
-	select {
-	case <-time.After(time.Duration(r.config.Test.Delay) * time.Second):
-	case <-runTestSetCtx.Done():
-		return models.TestSetStatusUserAbort, context.Canceled
+	// Wait for the user application to be ready, or for the delay if no readiness probe is configured
+	if r.config.Test.ReadinessURL != "" || r.config.Test.ReadinessPort != 0 {
+		err = waitForApp(runTestSetCtx, r.logger, r.config.Test.ReadinessURL, r.config.Test.ReadinessPort, r.config.Test.ReadinessTimeout)
+		if err != nil {
+			if runTestSetCtx.Err() != nil {
+				return models.TestSetStatusUserAbort, context.Canceled
+			}
+			utils.LogError(r.logger, err, "the application isn't ready", zap.Any("testSet", testSetID))
+			return models.TestSetStatusFaultUserApp, nil
+		}
+	} else {
+		select {
+		case <-time.After(time.Duration(r.config.Test.Delay) * time.Second):
+		case <-runTestSetCtx.Done():
+			return models.TestSetStatusUserAbort, context.Canceled
+		}
+	}
+
+	if r.dbSnapshot != nil {
+		err = r.dbSnapshot.reset(runTestSetCtx)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to reset the database of the app", zap.Any("testSet", testSetID))
+			return models.TestSetStatusFailed, nil
+		}
 	}
 
 	selectedTests := ArrayToMap(r.config.Test.SelectedTests[testSetID])
+	if changedTests, ok := r.changedTests[testSetID]; ok {
+		selectedTests = ArrayToMap(changedTests)
+	}
 
 	testCasesCount := len(testCases)
 
@@ -339,6 +536,10 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		testCasesCount = len(selectedTests)
 	}
 
+	if r.tui != nil {
+		r.tui.testSetStarted(testSetID, testCasesCount)
+	}
+
 	// Inserting the initial report for the test set
 	testReport := &models.TestReport{
 		Version: models.GetVersion(),
@@ -379,16 +580,24 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		var testResult *models.Result
 		var testPass bool
 
-		filteredMocks, loopErr := r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, testCase.HTTPReq.Timestamp, testCase.HTTPResp.Timestamp)
+		// the window of the testcase is widened by the slack for the clocks of the mocks and the testcase
+		afterTime, beforeTime := testCase.ReqTimestamp(), testCase.RespTimestamp()
+		if r.config.Test.StrictMockWindow && !afterTime.IsZero() && !beforeTime.IsZero() {
+			afterTime, beforeTime = afterTime.Add(-r.config.Test.MockWindowSlack), beforeTime.Add(r.config.Test.MockWindowSlack)
+		}
+		filteredMocks, loopErr := r.mockDB.GetFilteredMocks(runTestSetCtx, testSetID, afterTime, beforeTime)
 		if loopErr != nil {
 			utils.LogError(r.logger, err, "failed to get filtered mocks")
 			break
 		}
-		unfilteredMocks, loopErr := r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, testCase.HTTPReq.Timestamp, testCase.HTTPResp.Timestamp)
+		unfilteredMocks, loopErr := r.mockDB.GetUnFilteredMocks(runTestSetCtx, testSetID, afterTime, beforeTime)
 		if loopErr != nil {
 			utils.LogError(r.logger, err, "failed to get unfiltered mocks")
 			break
 		}
+		if r.config.Test.StrictMockWindow {
+			unfilteredMocks = scopeMocks(unfilteredMocks, afterTime, beforeTime)
+		}
 
 		loopErr = r.instrumentation.SetMocks(runTestSetCtx, appID, filteredMocks, unfilteredMocks)
 		if loopErr != nil {
@@ -400,6 +609,10 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 
 		cmdType := utils.FindDockerCmd(r.config.Command)
 
+		// keeping the recorded url and authority to restore them before updating the testcase
+		recordedURL := testCase.HTTPReq.URL
+		recordedAuthority := testCase.GrpcReq.Headers.PseudoHeaders[":authority"]
+
 		if cmdType == utils.Docker || cmdType == utils.DockerCompose {
 
 			userIP, err := r.instrumentation.GetAppIP(ctx, appID)
@@ -408,19 +621,67 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 				break
 			}
 
-			testCase.HTTPReq.URL, err = replaceHostToIP(testCase.HTTPReq.URL, userIP)
+			if testCase.Kind == models.GRPC_EXPORT {
+				testCase.GrpcReq.Headers.PseudoHeaders[":authority"], err = replaceAuthorityToIP(recordedAuthority, userIP)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to replace authority to docker container's IP")
+					break
+				}
+				r.logger.Debug("", zap.Any("replaced authority in case of docker env", testCase.GrpcReq.Headers.PseudoHeaders[":authority"]))
+			} else {
+				testCase.HTTPReq.URL, err = replaceHostToIP(testCase.HTTPReq.URL, userIP)
+				if err != nil {
+					utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
+					break
+				}
+				r.logger.Debug("", zap.Any("replaced URL in case of docker env", testCase.HTTPReq.URL))
+			}
+		}
+
+		if r.timeFreezer != nil {
+			err = r.timeFreezer.freeze(testCase.ReqTimestamp())
 			if err != nil {
-				utils.LogError(r.logger, err, "failed to replace host to docker container's IP")
-				break
+				utils.LogError(r.logger, err, "failed to freeze the time of the app at the recorded time of the testcase", zap.Any("testcase", testCase.Name))
 			}
-			r.logger.Debug("", zap.Any("replaced URL in case of docker env", testCase.HTTPReq.URL))
 		}
 
-		resp, loopErr := emulator.SimulateRequest(runTestSetCtx, appID, testCase, testSetID)
+		if r.config.Test.CaptureAppLogs {
+			err = r.instrumentation.CaptureAppLogs(runTestSetCtx, appID)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to capture the app logs", zap.Any("testcase", testCase.Name))
+			}
+		}
+
+		err = utils.RunLifecycleHook(runTestSetCtx, r.logger, "preTestCase", r.config.Hooks.PreTestCase, testSetEnv, "KEPLOY_TEST_CASE="+testCase.Name)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to run the preTestCase hook", zap.Any("testcase", testCase.Name))
+		}
+
+		if r.tui != nil {
+			r.tui.testCaseStarted(testCase.Name)
+		}
+		var resp *models.HTTPResp
+		var grpcResp *models.GrpcResp
+		simulated := time.Now()
+		simulateCtx, testCaseSpan := tracing.Start(runTestSetCtx, "testcase", attribute.String("testcase", testCase.Name), attribute.String("test-set", testSetID))
+		if testCase.Kind == models.GRPC_EXPORT {
+			// grpc testcases are sent by the embedded grpc client, the request emulator only knows about http
+			grpcResp, loopErr = pkg.SimulateGRPC(simulateCtx, *testCase, testSetID, r.logger, r.config.Test.APITimeout)
+		} else {
+			resp, loopErr = emulator.SimulateRequest(simulateCtx, appID, testCase, testSetID)
+		}
+		tracing.End(testCaseSpan, loopErr)
 		if loopErr != nil {
 			utils.LogError(r.logger, err, "failed to simulate request")
 			break
 		}
+		latency := time.Since(simulated)
+		if r.coverage != nil {
+			err = r.coverage.testCaseDone(runTestSetCtx, testCase.Name)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to get the coverage of the testcase", zap.Any("testcase", testCase.Name))
+			}
+		}
 
 		consumedMocks, err := r.instrumentation.GetConsumedMocks(runTestSetCtx, appID)
 		if err != nil {
@@ -431,26 +692,110 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 				totalConsumedMocks[mockName] = true
 			}
 		}
+		// the testcases which received faults in place of their mock responses are reported separately
+		faults, err := r.instrumentation.GetInjectedFaults(runTestSetCtx, appID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get injected faults")
+		}
+		// the errors of the proxy tell a failure of the infra, like a missing mock, apart from a failed assertion
+		outgoingErrors, err := r.instrumentation.GetProxyErrors(runTestSetCtx, appID)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get proxy errors")
+		}
+		for _, outgoingErr := range outgoingErrors {
+			if proxyErrors == nil {
+				proxyErrors = map[models.ProxyErrorType]int{}
+			}
+			proxyErrors[outgoingErr.Type]++
+		}
+		// the calls the app stopped making, or started making, since the testcase was recorded
+		outgoingDiff := diffOutgoing(filteredMocks, unfilteredMocks, consumedMocks, outgoingErrors)
+		if outgoingDiff != nil {
+			r.logger.Warn("the outgoing calls of the testcase differ from the recorded mocks", zap.Any("testcase id", testCase.Name), zap.Any("testset id", testSetID), zap.Any("missing", outgoingDiff.Missing), zap.Any("extra", outgoingDiff.Extra))
+		}
 
-		testPass, testResult = r.compareResp(testCase, resp, testSetID)
-		if !testPass {
+		if testCase.Kind == models.GRPC_EXPORT {
+			testPass, testResult = r.compareGrpcResp(testCase, grpcResp, testSetID)
+		} else {
+			testPass, testResult = r.compareResp(testCase, resp, testSetID)
+		}
+		var appLogs string
+		if r.config.Test.CaptureAppLogs {
+			appLogs, err = r.instrumentation.GetAppLogs(runTestSetCtx, appID)
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to get the app logs", zap.Any("testcase", testCase.Name))
+			}
+		}
+		// the response is updated only if it did not match, a slow response can't be fixed by updating the testcase
+		respPass := testPass
+
+		if recordedLatency := testCase.RecordedLatency(); recordedLatency > 0 && len(faults) == 0 {
+			recordedLatencies = append(recordedLatencies, recordedLatency)
+			actualLatencies = append(actualLatencies, latency)
+		}
+		if testResult != nil {
+			testResult.LatencyResult = checkLatency(testCase, latency)
+			if !testResult.LatencyResult.Normal {
+				testPass = false
+				pp.SetColorScheme(models.FailingColorScheme)
+				if _, err := pp.Printf("Testrun failed for testcase with id: %s, the latency of %sms exceeded the max latency of %sms\n\n", testCase.Name, latency.Milliseconds(), testCase.MaxLatencyMs); err != nil {
+					utils.LogError(r.logger, err, "failed to print the latency assertion")
+				}
+			}
+			// with strict mocking an outgoing call without a mock fails the testcase, even if its response matched
+			if missing := missingMocks(outgoingErrors); r.config.Test.StrictMocking && len(missing) > 0 {
+				testResult.DepResult = append(testResult.DepResult, missing...)
+				testPass = false
+				pp.SetColorScheme(models.FailingColorScheme)
+				if _, err := pp.Printf("Testrun failed for testcase with id: %s, %s outgoing calls didn't match any mock\n\n", testCase.Name, len(missing)); err != nil {
+					utils.LogError(r.logger, err, "failed to print the missing mocks")
+				}
+			}
+		}
+
+		if !respPass && r.config.Test.Update && len(faults) == 0 {
+			if testCase.Kind == models.GRPC_EXPORT {
+				testCase.GrpcReq.Headers.PseudoHeaders[":authority"] = recordedAuthority
+				err = r.updateGrpcTestCase(runTestSetCtx, testCase, grpcResp, testSetID)
+			} else {
+				testCase.HTTPReq.URL = recordedURL
+				err = r.updateTestCase(runTestSetCtx, testCase, resp, testSetID)
+			}
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to update the testcase", zap.Any("testcase id", testCase.Name), zap.Any("testset id", testSetID))
+			}
+		}
+		if len(faults) > 0 {
+			r.logger.Info("result with injected faults", zap.Any("testcase id", testCase.Name), zap.Any("testset id", testSetID), zap.Any("passed", testPass), zap.Any("faults", faults))
+		} else if !testPass {
 			// log the consumed mocks during the test run of the test case for test set
-			r.logger.Info("result", zap.Any("testcase id", models.HighlightFailingString(testCase.Name)), zap.Any("testset id", models.HighlightFailingString(testSetID)), zap.Any("passed", models.HighlightFailingString(testPass)), zap.Any("consumed mocks", consumedMocks))
+			r.logger.Info("result", zap.Any("testcase id", models.HighlightFailingString(testCase.Name)), zap.Any("testset id", models.HighlightFailingString(testSetID)), zap.Any("passed", models.HighlightFailingString(testPass)), zap.Any("consumed mocks", consumedMocks), zap.Any("correlated mocks", correlatedMocks[testCase.CorrelationID]), zap.Any("proxy errors", outgoingErrors))
 		} else {
 			r.logger.Info("result", zap.Any("testcase id", models.HighlightPassingString(testCase.Name)), zap.Any("testset id", models.HighlightPassingString(testSetID)), zap.Any("passed", models.HighlightPassingString(testPass)))
 		}
-		if testPass {
+		switch {
+		case len(faults) > 0:
+			testStatus = models.TestStatusFailed
+			if testPass {
+				testStatus = models.TestStatusPassed
+			}
+			chaosReport.record(faults, testPass)
+		case testPass:
 			testStatus = models.TestStatusPassed
 			success++
-		} else {
+		default:
 			testStatus = models.TestStatusFailed
 			failure++
 			testSetStatus = models.TestSetStatusFailed
 		}
+		dependencies.record(consumedMocks, testStatus == models.TestStatusPassed)
+		if r.tui != nil {
+			r.tui.testCaseDone(testCase.Name, testStatus)
+		}
 
 		if testResult != nil {
 			testCaseResult := &models.TestResult{
-				Kind:       models.HTTP,
+				Kind:       testCase.Kind,
 				Name:       testSetID,
 				Status:     testStatus,
 				Started:    started.Unix(),
@@ -478,10 +823,23 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 					Binary:        testCase.HTTPResp.Binary,
 					Timestamp:     testCase.HTTPResp.Timestamp,
 				},
-				TestCasePath: filepath.Join(r.config.Path, testSetID),
-				MockPath:     filepath.Join(r.config.Path, testSetID, "mocks.yaml"),
-				Noise:        testCase.Noise,
-				Result:       *testResult,
+				GrpcReq:       testCase.GrpcReq,
+				GrpcRes:       testCase.GrpcResp,
+				TestCasePath:  filepath.Join(r.config.Path, testSetID),
+				MockPath:      filepath.Join(r.config.Path, testSetID, "mocks.yaml"),
+				Noise:         testCase.Noise,
+				Result:        *testResult,
+				Faults:        faults,
+				ProxyErrors:   outgoingErrors,
+				OutgoingDiff:  outgoingDiff,
+				ConsumedMocks: consumedMocks,
+				AppLogs:       appLogs,
+				// the fields of the actual response are captured even on pass, to audit what the app returned
+				Captured: captureFields(r.logger, testCase, resp),
+			}
+			if testCase.CorrelationID != "" {
+				testCaseResult.CorrelationID = testCase.CorrelationID
+				testCaseResult.CorrelatedMocks = correlatedMocks[testCase.CorrelationID]
 			}
 			loopErr = r.reportDB.InsertTestCaseResult(runTestSetCtx, testRunID, testSetID, testCaseResult)
 			if loopErr != nil {
@@ -522,15 +880,21 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 	}
 
 	testReport = &models.TestReport{
-		Version: models.GetVersion(),
-		TestSet: testSetID,
-		Status:  string(testSetStatus),
-		Total:   testCasesCount,
-		Success: success,
-		Failure: failure,
-		Tests:   testCaseResults,
+		Version:      models.GetVersion(),
+		TestSet:      testSetID,
+		Status:       string(testSetStatus),
+		Total:        testCasesCount - chaosReport.Total,
+		Success:      success,
+		Failure:      failure,
+		Tests:        testCaseResults,
+		Timing:       timingReport(recordedLatencies, actualLatencies),
+		Chaos:        chaosReport.report(),
+		ProxyErrors:  proxyErrors,
+		Dependencies: dependencies.report(),
 	}
 
+	span.SetAttributes(attribute.String("status", string(testSetStatus)), attribute.Int("passed", success), attribute.Int("failed", failure))
+
 	// final report should have reason for sudden stop of the test run so this should get canceled
 	reportCtx := context.WithoutCancel(runTestSetCtx)
 	err = r.reportDB.InsertReport(reportCtx, testRunID, testSetID, testReport)
@@ -571,9 +935,33 @@ func (r *Replayer) RunTestSet(ctx context.Context, testSetID string, testRunID s
 		if _, err := pp.Printf("\n <=========================================> \n  TESTRUN SUMMARY. For test-set: %s\n"+"\tTotal tests: %s\n"+"\tTotal test passed: %s\n"+"\tTotal test failed: %s\n <=========================================> \n\n", testReport.TestSet, testReport.Total, testReport.Success, testReport.Failure); err != nil {
 			utils.LogError(r.logger, err, "failed to print testrun summary")
 		}
+		if testReport.Chaos != nil {
+			fmt.Printf("\tTests with injected faults: %d (passed: %d, failed: %d, faults: %d)\n\n", testReport.Chaos.Total, testReport.Chaos.Passed, testReport.Chaos.Failed, testReport.Chaos.Faults)
+		}
+		if len(testReport.ProxyErrors) > 0 {
+			fmt.Printf("\tProxy errors of the outgoing calls: %v\n\n", testReport.ProxyErrors)
+		}
+		for _, dep := range testReport.Dependencies {
+			fmt.Printf("\tDependency %s: %d mocks, %d consumed, %d failed testcases\n", dep.Name, dep.Mocks, dep.Consumed, dep.Failed)
+		}
+		if len(testReport.Dependencies) > 0 {
+			fmt.Println()
+		}
+		if testReport.Timing != nil {
+			fmt.Printf("\tLatency p50: recorded %.2fms, actual %.2fms (%+.2fms)\n\tLatency p95: recorded %.2fms, actual %.2fms (%+.2fms)\n\n",
+				testReport.Timing.RecordedP50, testReport.Timing.ActualP50, testReport.Timing.P50Delta,
+				testReport.Timing.RecordedP95, testReport.Timing.ActualP95, testReport.Timing.P95Delta)
+		}
 	}
 
 	r.telemetry.TestSetRun(testReport.Success, testReport.Failure, testSetID, string(testSetStatus))
+	if len(proxyErrors) > 0 {
+		counts := make(map[string]int, len(proxyErrors))
+		for errType, count := range proxyErrors {
+			counts[string(errType)] = count
+		}
+		r.telemetry.ProxyErrors(testSetID, counts)
+	}
 	return testSetStatus, nil
 }
 
@@ -589,16 +977,64 @@ func (r *Replayer) GetTestSetStatus(ctx context.Context, testRunID string, testS
 	return status, nil
 }
 
-func (r *Replayer) compareResp(tc *models.TestCase, actualResponse *models.HTTPResp, testSetID string) (bool, *models.Result) {
+func (r *Replayer) GetTestCaseResults(ctx context.Context, testRunID string, testSetID string) ([]models.TestResult, error) {
+	results, err := r.reportDB.GetTestCaseResults(ctx, testRunID, testSetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the testcase results: %w", err)
+	}
+	return results, nil
+}
 
-	noiseConfig := r.config.Test.GlobalNoise.Global
-	if tsNoise, ok := r.config.Test.GlobalNoise.Testsets[testSetID]; ok {
-		noiseConfig = LeftJoinNoise(r.config.Test.GlobalNoise.Global, tsNoise)
+// updateTestCase rewrites the expected response of the testcase with the actual response.
+// The recorded timestamp is retained so that the mocks are still filtered for the same window.
+func (r *Replayer) updateTestCase(ctx context.Context, tc *models.TestCase, actualResponse *models.HTTPResp, testSetID string) error {
+	if actualResponse == nil {
+		return errors.New("actual response is nil")
 	}
-	return match(tc, actualResponse, noiseConfig, r.config.Test.IgnoreOrdering, r.logger)
+	updatedTc := *tc
+	updatedTc.HTTPResp = *actualResponse
+	updatedTc.HTTPResp.Timestamp = tc.HTTPResp.Timestamp
+	updatedTc.Updated = time.Now().Unix()
+	err := r.testDB.UpdateTestCase(ctx, &updatedTc, testSetID)
+	if err != nil {
+		return err
+	}
+	return nil
 }
 
-func (r *Replayer) printSummary(ctx context.Context, testRunResult bool) {
+// updateGrpcTestCase rewrites the expected grpc response of the testcase with the actual response.
+func (r *Replayer) updateGrpcTestCase(ctx context.Context, tc *models.TestCase, actualResponse *models.GrpcResp, testSetID string) error {
+	if actualResponse == nil {
+		return errors.New("actual response is nil")
+	}
+	updatedTc := *tc
+	updatedTc.GrpcResp = *actualResponse
+	updatedTc.GrpcResp.Timestamp = tc.GrpcResp.Timestamp
+	updatedTc.Updated = time.Now().Unix()
+	return r.testDB.UpdateTestCase(ctx, &updatedTc, testSetID)
+}
+
+func (r *Replayer) compareGrpcResp(tc *models.TestCase, actualResponse *models.GrpcResp, testSetID string) (bool, *models.Result) {
+	noiseConfig := LeftJoinNoise(r.config.Test.GlobalNoise.Global, r.config.Test.GlobalNoise.Testsets[testSetID])
+	return matchGrpc(tc, actualResponse, noiseConfig, r.logger)
+}
+
+// onMockEvent returns the handler of the outgoing calls mocked by the proxy, nil if nothing shows them.
+func (r *Replayer) onMockEvent() func(models.MockEvent) {
+	if r.tui == nil {
+		return nil
+	}
+	return r.tui.mockEvent
+}
+
+func (r *Replayer) compareResp(tc *models.TestCase, actualResponse *models.HTTPResp, testSetID string) (bool, *models.Result) {
+
+	noiseConfig := LeftJoinNoise(r.config.Test.GlobalNoise.Global, r.config.Test.GlobalNoise.Testsets[testSetID])
+	noiseConfig = withGraphQLNoise(tc, noiseConfig, r.config.Test.GraphQLNoise)
+	return match(tc, actualResponse, noiseConfig, r.config.Test.IgnoreOrdering, r.config.Test.ArrayMatching, r.config.Test.HashAssets, r.logger)
+}
+
+func (r *Replayer) printSummary(ctx context.Context, testRunID string, testRunResult bool) {
 	if totalTests > 0 {
 		testSuiteNames := make([]string, 0, len(completeTestReport))
 		for testSuiteName := range completeTestReport {
@@ -659,6 +1095,13 @@ func (r *Replayer) printSummary(ctx context.Context, testRunResult bool) {
 			if len(output) > 0 {
 				r.logger.Sugar().Infoln("\n", models.HighlightFailingString(string(output)))
 			}
+			// the total coverage of the test run is stored for the coverage delta of keploy report comment
+			percent, err := profileCoverage(os.Getenv("GOCOVERDIR") + "/total-coverage.txt")
+			if err != nil {
+				utils.LogError(r.logger, err, "failed to compute the total coverage of the go binary")
+			} else if err := r.reportDB.InsertCoverage(ctx, testRunID, &models.CoverageReport{Percent: percent}); err != nil {
+				utils.LogError(r.logger, err, "failed to store the coverage of the test run")
+			}
 		}
 	}
 }
@@ -675,7 +1118,7 @@ func (r *Replayer) ProvideMocks(ctx context.Context) error {
 		case <-ctx.Done():
 			return
 		default:
-			err := utils.Stop(r.logger, stopReason)
+			err := utils.StopSession(ctx, r.logger, stopReason)
 			if err != nil {
 				utils.LogError(r.logger, err, "failed to stop mock replay")
 			}
@@ -727,3 +1170,17 @@ func (r *Replayer) ProvideMocks(ctx context.Context) error {
 	<-ctx.Done()
 	return nil
 }
+
+// sharedMocks returns the mocks of the shared directory of the keploy directory, shared by all the test sets. There
+// are none if the directory doesn't exist.
+func (r *Replayer) sharedMocks(ctx context.Context) ([]*models.Mock, error) {
+	filtered, err := r.mockDB.GetFilteredMocks(ctx, models.SharedMocks, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	unfiltered, err := r.mockDB.GetUnFilteredMocks(ctx, models.SharedMocks, time.Time{}, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	return append(filtered, unfiltered...), nil
+}
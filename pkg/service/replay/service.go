@@ -17,10 +17,18 @@ type Instrumentation interface {
 	SetMocks(ctx context.Context, id uint64, filtered []*models.Mock, unFiltered []*models.Mock) error
 	// GetConsumedMocks to log the names of the mocks that were consumed during the test run of failed test cases
 	GetConsumedMocks(ctx context.Context, id uint64) ([]string, error)
+	// GetInjectedFaults to know the faults injected in place of the mock responses during the test run of a test case
+	GetInjectedFaults(ctx context.Context, id uint64) ([]models.InjectedFault, error)
+	// GetProxyErrors to know the errors of the proxy while mocking the outgoing calls during the test run of a test case
+	GetProxyErrors(ctx context.Context, id uint64) ([]models.OutgoingError, error)
 	// Run is blocking call and will execute until error
 	Run(ctx context.Context, id uint64, opts models.RunOptions) models.AppError
 
 	GetAppIP(ctx context.Context, id uint64) (string, error)
+	// CaptureAppLogs starts capturing the stdout and stderr of the app during the test run of a test case
+	CaptureAppLogs(ctx context.Context, id uint64) error
+	// GetAppLogs stops capturing and returns the stdout and stderr of the app captured since CaptureAppLogs
+	GetAppLogs(ctx context.Context, id uint64) (string, error)
 }
 
 type Service interface {
@@ -29,6 +37,7 @@ type Service interface {
 	GetAllTestSetIDs(ctx context.Context) ([]string, error)
 	RunTestSet(ctx context.Context, testSetID string, testRunID string, appID uint64, serveTest bool) (models.TestSetStatus, error)
 	GetTestSetStatus(ctx context.Context, testRunID string, testSetID string) (models.TestSetStatus, error)
+	GetTestCaseResults(ctx context.Context, testRunID string, testSetID string) ([]models.TestResult, error)
 	RunApplication(ctx context.Context, appID uint64, opts models.RunOptions) models.AppError
 	ProvideMocks(ctx context.Context) error
 }
@@ -36,6 +45,7 @@ type Service interface {
 type TestDB interface {
 	GetAllTestSetIDs(ctx context.Context) ([]string, error)
 	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	UpdateTestCase(ctx context.Context, testCase *models.TestCase, testSetID string) error
 }
 
 type MockDB interface {
@@ -50,12 +60,14 @@ type ReportDB interface {
 	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
 	InsertTestCaseResult(ctx context.Context, testRunID string, testSetID string, result *models.TestResult) error
 	InsertReport(ctx context.Context, testRunID string, testSetID string, testReport *models.TestReport) error
+	InsertCoverage(ctx context.Context, testRunID string, coverage *models.CoverageReport) error
 }
 
 type Telemetry interface {
 	TestSetRun(success int, failure int, testSet string, runStatus string)
 	TestRun(success int, failure int, testSets int, runStatus string)
 	MockTestRun(utilizedMocks int)
+	ProxyErrors(testSet string, proxyErrors map[string]int)
 }
 
 // RequestEmulator is used to simulate the API requests to the user API. The requests are read from
@@ -0,0 +1,89 @@
+package replay
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// epsilonPrefix marks the noise of a numeric field which is compared with a tolerance instead of being ignored,
+// e.g. epsilon:0.001 for an absolute tolerance or epsilon:0.1% for one relative to the expected value. The
+// tolerances are given among the regexes of the noisy field, e.g. body.total: ["epsilon:0.005"].
+const epsilonPrefix = "epsilon:"
+
+type tolerance struct {
+	value    float64
+	relative bool
+}
+
+// splitTolerances splits the tolerances off the regexes of the noisy field, the malformed ones are kept as regexes.
+func splitTolerances(regexArr []string) ([]tolerance, []string) {
+	var tolerances []tolerance
+	var regexes []string
+	for _, entry := range regexArr {
+		t, ok := parseTolerance(entry)
+		if !ok {
+			regexes = append(regexes, entry)
+			continue
+		}
+		tolerances = append(tolerances, t)
+	}
+	return tolerances, regexes
+}
+
+func parseTolerance(entry string) (tolerance, bool) {
+	value, ok := strings.CutPrefix(strings.TrimSpace(entry), epsilonPrefix)
+	if !ok {
+		return tolerance{}, false
+	}
+	value = strings.TrimSpace(value)
+	t := tolerance{}
+	if v, ok := strings.CutSuffix(value, "%"); ok {
+		value = v
+		t.relative = true
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 || math.IsNaN(f) {
+		return tolerance{}, false
+	}
+	t.value = f
+	if t.relative {
+		t.value /= 100
+	}
+	return t, true
+}
+
+// withinTolerance reports whether the actual number is within any of the tolerances of the expected one. The
+// numbers may be JSON numbers or numeric strings, e.g. the monetary amounts sent as "12.50".
+func withinTolerance(tolerances []tolerance, expected, actual interface{}) bool {
+	exp, ok := toFloat(expected)
+	if !ok {
+		return false
+	}
+	act, ok := toFloat(actual)
+	if !ok {
+		return false
+	}
+	diff := math.Abs(exp - act)
+	for _, t := range tolerances {
+		limit := t.value
+		if t.relative {
+			limit *= math.Abs(exp)
+		}
+		if diff <= limit {
+			return true
+		}
+	}
+	return false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
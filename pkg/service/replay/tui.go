@@ -0,0 +1,173 @@
+package replay
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+)
+
+const (
+	// tuiRefresh is how often the terminal ui is redrawn
+	tuiRefresh = 100 * time.Millisecond
+	// tuiCalls and tuiResults are how many of the latest outgoing calls and testcases are shown
+	tuiCalls   = 12
+	tuiResults = 8
+)
+
+// tui draws the live progress of the test run over the terminal, on the alternate screen so that the terminal is
+// restored when it is closed: the testcase running with its outgoing calls and the mocks they matched, the latest
+// results and the pass and fail tally. The logs are written to the log file meanwhile.
+type tui struct {
+	mutex   sync.Mutex
+	out     *os.File
+	testSet string
+	total   int
+	done    int
+	// current is the running testcase and since when, calls are its outgoing calls
+	current string
+	since   time.Time
+	calls   []models.MockEvent
+	hits    int
+	misses  int
+	passed  int
+	failed  int
+	results []string
+	stop    chan struct{}
+	stopped sync.WaitGroup
+	once    sync.Once
+}
+
+func newTUI(out *os.File) *tui {
+	return &tui{out: out, stop: make(chan struct{})}
+}
+
+// start switches to the alternate screen and redraws the terminal ui until it is closed.
+func (t *tui) start() {
+	utils.EnterScreen(t.out)
+	t.stopped.Add(1)
+	go func() {
+		defer t.stopped.Done()
+		ticker := time.NewTicker(tuiRefresh)
+		defer ticker.Stop()
+		for {
+			t.draw()
+			select {
+			case <-t.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// close stops redrawing the terminal ui and restores the screen, it may be called more than once.
+func (t *tui) close() {
+	t.once.Do(func() {
+		close(t.stop)
+		t.stopped.Wait()
+		utils.LeaveScreen(t.out)
+	})
+}
+
+func (t *tui) testSetStarted(testSetID string, total int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.testSet = testSetID
+	t.total = total
+	t.done = 0
+}
+
+func (t *tui) testCaseStarted(name string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.current = name
+	t.since = time.Now()
+	t.calls = nil
+}
+
+func (t *tui) testCaseDone(name string, status models.TestStatus) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.done++
+	mark := "\x1b[32m✔\x1b[0m"
+	if status == models.TestStatusPassed {
+		t.passed++
+	} else {
+		t.failed++
+		mark = "\x1b[31m✘\x1b[0m"
+	}
+	t.results = append(t.results, fmt.Sprintf("%s %s/%s (%dms)", mark, t.testSet, name, time.Since(t.since).Milliseconds()))
+	if len(t.results) > tuiResults {
+		t.results = t.results[len(t.results)-tuiResults:]
+	}
+	t.current = ""
+}
+
+// mockEvent adds the outgoing call to the ones of the running testcase, it is called by the proxy.
+func (t *tui) mockEvent(event models.MockEvent) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if event.Hit {
+		t.hits++
+	} else {
+		t.misses++
+	}
+	t.calls = append(t.calls, event)
+	if len(t.calls) > tuiCalls {
+		t.calls = t.calls[len(t.calls)-tuiCalls:]
+	}
+}
+
+func (t *tui) draw() {
+	utils.DrawScreen(t.out, t.render())
+}
+
+func (t *tui) render() []string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	lines := []string{
+		"\x1b[1mKeploy test run\x1b[0m   (logs: keploy-logs.txt)",
+		"",
+		fmt.Sprintf("test set  %s  %s %d/%d", t.testSet, progressBar(t.done, t.total, 30), t.done, t.total),
+		fmt.Sprintf("tests     \x1b[32m%d passed\x1b[0m  \x1b[31m%d failed\x1b[0m", t.passed, t.failed),
+		fmt.Sprintf("mocks     \x1b[32m%d hits\x1b[0m  \x1b[31m%d misses\x1b[0m", t.hits, t.misses),
+		"",
+	}
+	if t.current != "" {
+		lines = append(lines, fmt.Sprintf("\x1b[1mrunning %s\x1b[0m (%.1fs)", t.current, time.Since(t.since).Seconds()))
+	} else {
+		lines = append(lines, "\x1b[1mwaiting for the next testcase\x1b[0m")
+	}
+	if len(t.calls) == 0 {
+		lines = append(lines, "  no outgoing calls yet")
+	}
+	for _, call := range t.calls {
+		at := call.Time.Format("15:04:05.000")
+		if call.Hit {
+			lines = append(lines, fmt.Sprintf("  %s \x1b[32mhit \x1b[0m %s (%s)", at, call.Mock, call.Kind))
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("  %s \x1b[31mmiss\x1b[0m %s", at, strings.Join(strings.Fields(call.Error), " ")))
+	}
+	lines = append(lines, "", "\x1b[1mlatest testcases\x1b[0m")
+	for i := len(t.results) - 1; i >= 0; i-- {
+		lines = append(lines, "  "+t.results[i])
+	}
+	return lines
+}
+
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	if filled > width {
+		filled = width
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
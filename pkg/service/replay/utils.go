@@ -3,8 +3,15 @@ package replay
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
+	"net/http"
 	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"go.keploy.io/server/v2/config"
 	"go.keploy.io/server/v2/pkg"
@@ -19,13 +26,51 @@ type TestReportVerdict struct {
 	status bool
 }
 
+// LeftJoinNoise returns the global noise with the noise of the test set added to it, the test set taking precedence
+// for the fields in both. The fields are normalised by their scope with noiseKey. The given noise is left untouched.
 func LeftJoinNoise(globalNoise config.GlobalNoise, tsNoise config.GlobalNoise) config.GlobalNoise {
-	noise := globalNoise
-	for field, regexArr := range tsNoise["body"] {
-		noise["body"][field] = regexArr
+	noise := config.GlobalNoise{"body": {}, "header": {}}
+	for _, n := range []config.GlobalNoise{globalNoise, tsNoise} {
+		for scope, fields := range n {
+			if noise[scope] == nil {
+				noise[scope] = map[string][]string{}
+			}
+			for field, regexArr := range fields {
+				noise[scope][noiseKey(scope, field)] = regexArr
+			}
+		}
 	}
-	for field, regexArr := range tsNoise["header"] {
-		noise["header"][field] = regexArr
+	return noise
+}
+
+var (
+	// arrayIndex matches the index of an array element in a JSONPath, e.g. [0] or [*]
+	arrayIndex = regexp.MustCompile(`\[(\d+|\*)\]`)
+	// headerName matches a plain header name, as opposed to a regex of header names
+	headerName = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+)
+
+// noiseKey normalises the noisy field of the scope. The header names are canonicalised like the recorded headers.
+// The body fields may be given as JSONPath, e.g. $.data.items[*].id, which is matched as data.items.id since the
+// elements of an array are compared under the key of the array.
+func noiseKey(scope, field string) string {
+	switch scope {
+	case "header":
+		if headerName.MatchString(field) {
+			return http.CanonicalHeaderKey(field)
+		}
+	case "body":
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "$"), ".")
+		return arrayIndex.ReplaceAllString(field, "")
+	}
+	return field
+}
+
+// copyNoise returns a copy of the noisy fields of a scope, so that the noise of a testcase can be added to it.
+func copyNoise(fields map[string][]string) map[string][]string {
+	noise := make(map[string][]string, len(fields))
+	for field, regexArr := range fields {
+		noise[field] = regexArr
 	}
 	return noise
 }
@@ -49,6 +94,15 @@ func replaceHostToIP(currentURL string, ipAddress string) (string, error) {
 	return parsedURL.String(), nil
 }
 
+// replaceAuthorityToIP replaces the host of the :authority of a grpc request with the ip address, keeping the port.
+func replaceAuthorityToIP(authority string, ipAddress string) (string, error) {
+	replacedURL, err := replaceHostToIP("http://"+authority, ipAddress)
+	if err != nil {
+		return authority, err
+	}
+	return strings.TrimPrefix(replacedURL, "http://"), nil
+}
+
 type testUtils struct {
 	logger     *zap.Logger
 	apiTimeout uint64
@@ -73,3 +127,237 @@ func (t *testUtils) SimulateRequest(ctx context.Context, _ uint64, tc *models.Te
 	}
 	return nil, nil
 }
+
+// readinessInterval is the interval between the polls of the readiness probe of the application.
+const readinessInterval = 500 * time.Millisecond
+
+// waitForApp polls the application until its health endpoint responds with 2xx, or its port accepts connections if
+// no endpoint is given. It returns an error if the application isn't ready within the timeout.
+func waitForApp(ctx context.Context, logger *zap.Logger, healthURL string, port uint32, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	client := &http.Client{Timeout: readinessInterval}
+	addr := net.JoinHostPort("localhost", strconv.FormatUint(uint64(port), 10))
+	start := time.Now()
+	ticker := time.NewTicker(readinessInterval)
+	defer ticker.Stop()
+	for {
+		var err error
+		if healthURL != "" {
+			err = probeHTTP(ctx, client, healthURL)
+		} else {
+			var conn net.Conn
+			conn, err = (&net.Dialer{Timeout: readinessInterval}).DialContext(ctx, "tcp", addr)
+			if err == nil {
+				conn.Close()
+			}
+		}
+		if err == nil {
+			logger.Info("the application is ready", zap.Duration("after", time.Since(start).Round(time.Millisecond)))
+			return nil
+		}
+		logger.Debug("the application isn't ready yet", zap.Error(err))
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("timed out after %v waiting for the application: %w", timeout, err)
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// probeHTTP returns an error unless the health endpoint responds with 2xx.
+func probeHTTP(ctx context.Context, client *http.Client, healthURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("the health endpoint responded with %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Mismatch describes the fields of the response of the testcase which don't match the recorded ones.
+func Mismatch(result models.TestResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the response doesn't match the recorded one (%s)", result.TestCasePath)
+	if !result.Result.StatusCode.Normal {
+		fmt.Fprintf(&b, "\nstatus code: expected %d, got %d", result.Result.StatusCode.Expected, result.Result.StatusCode.Actual)
+	}
+	for _, header := range result.Result.HeadersResult {
+		if !header.Normal {
+			fmt.Fprintf(&b, "\nheader %s: expected %v, got %v", header.Expected.Key, header.Expected.Value, header.Actual.Value)
+		}
+	}
+	for _, body := range result.Result.BodyResult {
+		if !body.Normal {
+			fmt.Fprintf(&b, "\nbody:\n\texpected: %s\n\tgot:      %s", body.Expected, body.Actual)
+		}
+	}
+	for _, dep := range result.Result.DepResult {
+		for _, meta := range dep.Meta {
+			if !meta.Normal {
+				fmt.Fprintf(&b, "\n%s %s: expected %s, got %s", dep.Name, meta.Key, meta.Expected, meta.Actual)
+			}
+		}
+	}
+	if result.Result.LatencyResult != nil && !result.Result.LatencyResult.Normal {
+		fmt.Fprintf(&b, "\nlatency: %vms over the max of %vms", result.Result.LatencyResult.Actual, result.Result.LatencyResult.Max)
+	}
+	return b.String()
+}
+
+// checkLatency asserts the latency of the response of the testcase against its max latency, if any.
+func checkLatency(tc *models.TestCase, latency time.Duration) *models.LatencyResult {
+	res := &models.LatencyResult{
+		Normal:   true,
+		Max:      tc.MaxLatencyMs,
+		Recorded: toMilliseconds(tc.RecordedLatency()),
+		Actual:   toMilliseconds(latency),
+	}
+	if tc.MaxLatencyMs > 0 && latency > time.Duration(tc.MaxLatencyMs)*time.Millisecond {
+		res.Normal = false
+	}
+	return res
+}
+
+// missingMocks returns the dependency results of the outgoing calls which didn't match any mock.
+func missingMocks(errs []models.OutgoingError) []models.DepResult {
+	var res []models.DepResult
+	for _, err := range errs {
+		if err.Type != models.ErrMockMissing {
+			continue
+		}
+		res = append(res, models.DepResult{
+			Name: "missing mock",
+			Type: string(err.Type),
+			Meta: []models.DepMetaResult{{
+				Normal:   false,
+				Key:      "outgoing call",
+				Expected: "a recorded mock",
+				Actual:   err.Message,
+			}},
+		})
+	}
+	return res
+}
+
+// diffOutgoing compares the mocks consumed during the test run of a testcase with the mocks recorded in its time
+// window. The config mocks are shared by the testcases, so they are never extra. The outgoing calls which didn't
+// match any mock are extra too. It returns nil if the calls are the same as the recorded ones.
+func diffOutgoing(filtered, unfiltered []*models.Mock, consumed []string, errs []models.OutgoingError) *models.OutgoingDiff {
+	recorded := map[string]bool{}
+	for _, mock := range filtered {
+		recorded[mock.Name] = true
+	}
+	config := map[string]bool{}
+	for _, mock := range unfiltered {
+		if mock.Spec.Metadata["type"] == "config" {
+			config[mock.Name] = true
+		}
+	}
+
+	diff := &models.OutgoingDiff{}
+	called := map[string]bool{}
+	for _, name := range consumed {
+		called[name] = true
+		if !recorded[name] && !config[name] {
+			diff.Extra = append(diff.Extra, name)
+		}
+	}
+	for _, err := range errs {
+		if err.Type == models.ErrMockMissing {
+			diff.Extra = append(diff.Extra, err.Message)
+		}
+	}
+	for _, mock := range filtered {
+		if !called[mock.Name] {
+			diff.Missing = append(diff.Missing, mock.Name)
+		}
+	}
+	if len(diff.Missing) == 0 && len(diff.Extra) == 0 {
+		return nil
+	}
+	return diff
+}
+
+// timingReport returns the p50 and p95 latencies of the test run against the recorded ones.
+// It returns nil if none of the testcases has a recorded latency.
+func timingReport(recorded, actual []time.Duration) *models.TimingReport {
+	if len(recorded) == 0 {
+		return nil
+	}
+	report := &models.TimingReport{
+		RecordedP50: toMilliseconds(percentile(recorded, 50)),
+		RecordedP95: toMilliseconds(percentile(recorded, 95)),
+		ActualP50:   toMilliseconds(percentile(actual, 50)),
+		ActualP95:   toMilliseconds(percentile(actual, 95)),
+	}
+	report.P50Delta = math.Round((report.ActualP50-report.RecordedP50)*1000) / 1000
+	report.P95Delta = math.Round((report.ActualP95-report.RecordedP95)*1000) / 1000
+	return report
+}
+
+// percentile returns the nearest-rank percentile of the latencies.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// toMilliseconds converts the duration into milliseconds, truncated to microseconds.
+func toMilliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// correlateMocks groups the names of the mocks by their correlation id, the mocks without one are left out.
+func correlateMocks(mocks ...[]*models.Mock) map[string][]string {
+	correlated := map[string][]string{}
+	seen := map[string]bool{}
+	for _, ms := range mocks {
+		for _, mock := range ms {
+			id := mock.Spec.Metadata[models.CorrelationIDKey]
+			if id == "" || seen[mock.Name] {
+				continue
+			}
+			seen[mock.Name] = true
+			correlated[id] = append(correlated[id], mock.Name)
+		}
+	}
+	return correlated
+}
+
+// scopeMocks returns the mocks recorded within the window of the testcase, so that the adjacent testcases can't
+// consume them. The config mocks shared by the session and the mocks without their timestamps are kept, and so are
+// all the mocks of a testcase recorded without its timestamps.
+func scopeMocks(mocks []*models.Mock, after, before time.Time) []*models.Mock {
+	if after.IsZero() || before.IsZero() {
+		return mocks
+	}
+	scoped := make([]*models.Mock, 0, len(mocks))
+	for _, mock := range mocks {
+		req, res := mock.Spec.ReqTimestampMock, mock.Spec.ResTimestampMock
+		if mock.Spec.Metadata["type"] == "config" || req.IsZero() || res.IsZero() || (req.After(after) && res.Before(before)) {
+			scoped = append(scoped, mock)
+		}
+	}
+	return scoped
+}
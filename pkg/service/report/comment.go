@@ -0,0 +1,233 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// FormatMarkdown is the format of the summaries posted as pull request comments.
+const FormatMarkdown = "markdown"
+
+// testSetSummary is the result of a test set in the summarized test run.
+type testSetSummary struct {
+	testSetID string
+	passed    int
+	failed    int
+}
+
+// runSummary is the summary of a test run, compared with the test run base and the latest test runs.
+type runSummary struct {
+	head, base  string
+	testSets    []testSetSummary
+	failures    []string // the testcases failing in head, which didn't fail in base
+	flaky       []string // the testcases which both passed and failed in the latest test runs
+	flakyRuns   int
+	coverage    *models.CoverageReport
+	baseCovered *models.CoverageReport
+}
+
+// Comment writes the summary of the test run head, the latest one if empty, for a pull request comment: the pass
+// and fail counts of its test sets, the testcases newly failing since the test run base, the one before head if
+// empty, the flaky testcases of the latest test runs and the delta of the coverage of the app.
+func (r *Reporter) Comment(ctx context.Context, head, base, format string, w io.Writer) error {
+	if format != FormatMarkdown {
+		return fmt.Errorf("unsupported format %s of the summary, expected %s", format, FormatMarkdown)
+	}
+	runs, err := r.testRuns(ctx)
+	if err != nil {
+		return err
+	}
+	if head == "" {
+		if len(runs) == 0 {
+			return errors.New("found no test run, run keploy test first")
+		}
+		head = runs[len(runs)-1]
+	}
+	headIndex := indexOf(runs, head)
+	if headIndex < 0 {
+		return fmt.Errorf("found no test run %s", head)
+	}
+	if base == "" && headIndex > 0 {
+		base = runs[headIndex-1]
+	}
+
+	testSetIDs := r.config.Report.TestSets
+	if len(testSetIDs) == 0 {
+		testSetIDs, err = r.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the test sets")
+			return err
+		}
+	}
+
+	summary := &runSummary{head: head, base: base, flakyRuns: r.config.Report.FlakyRuns}
+	// the latest test runs up to head, in which the flaky testcases are looked for
+	var recent []string
+	if summary.flakyRuns > 1 {
+		recent = runs[max(0, headIndex+1-summary.flakyRuns) : headIndex+1]
+	}
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		report, err := r.reportDB.GetReport(ctx, head, testSetID)
+		if err != nil {
+			r.logger.Debug("the test set wasn't run in the test run", zap.String("test-set", testSetID), zap.String("test-run", head))
+			continue
+		}
+		summary.testSets = append(summary.testSets, testSetSummary{testSetID: testSetID, passed: report.Success, failed: report.Failure})
+
+		baseFailed := map[string]bool{}
+		if base != "" {
+			if baseReport, err := r.reportDB.GetReport(ctx, base, testSetID); err == nil {
+				for _, result := range baseReport.Tests {
+					if result.Status == models.TestStatusFailed {
+						baseFailed[result.TestCaseID] = true
+					}
+				}
+			}
+		}
+		for _, result := range report.Tests {
+			if result.Status == models.TestStatusFailed && !baseFailed[result.TestCaseID] {
+				summary.failures = append(summary.failures, testSetID+"/"+result.TestCaseID)
+			}
+		}
+		summary.flaky = append(summary.flaky, r.flakyTestCases(ctx, testSetID, recent)...)
+	}
+	if len(summary.testSets) == 0 {
+		return fmt.Errorf("found no reports of the test run %s", head)
+	}
+
+	// the coverage is only collected by the test runs with goCoverage
+	if coverage, err := r.reportDB.GetCoverage(ctx, head); err == nil {
+		summary.coverage = coverage
+		if base != "" {
+			summary.baseCovered, _ = r.reportDB.GetCoverage(ctx, base)
+		}
+	}
+
+	if _, err := io.WriteString(w, summary.markdown()); err != nil {
+		utils.LogError(r.logger, err, "failed to write the summary of the test run")
+		return errors.New("failed to write the summary of the test run")
+	}
+	return nil
+}
+
+// flakyTestCases returns the testcases of the test set which both passed and failed in the test runs.
+func (r *Reporter) flakyTestCases(ctx context.Context, testSetID string, testRunIDs []string) []string {
+	passed, failed := map[string]bool{}, map[string]bool{}
+	for _, id := range testRunIDs {
+		report, err := r.reportDB.GetReport(ctx, id, testSetID)
+		if err != nil {
+			continue
+		}
+		for _, result := range report.Tests {
+			switch result.Status {
+			case models.TestStatusPassed:
+				passed[result.TestCaseID] = true
+			case models.TestStatusFailed:
+				failed[result.TestCaseID] = true
+			}
+		}
+	}
+	var flaky []string
+	for name := range failed {
+		if passed[name] {
+			flaky = append(flaky, testSetID+"/"+name)
+		}
+	}
+	sort.Strings(flaky)
+	return flaky
+}
+
+// testRuns returns the test runs in the order they were run.
+func (r *Reporter) testRuns(ctx context.Context) ([]string, error) {
+	ids, err := r.reportDB.GetAllTestRunIDs(ctx)
+	if err != nil {
+		utils.LogError(r.logger, err, "failed to get the test runs")
+		return nil, err
+	}
+	var runs []string
+	for _, id := range ids {
+		if _, err := strconv.Atoi(strings.TrimPrefix(id, models.TestRunTemplateName)); err == nil && strings.HasPrefix(id, models.TestRunTemplateName) {
+			runs = append(runs, id)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		a, _ := strconv.Atoi(strings.TrimPrefix(runs[i], models.TestRunTemplateName))
+		b, _ := strconv.Atoi(strings.TrimPrefix(runs[j], models.TestRunTemplateName))
+		return a < b
+	})
+	return runs, nil
+}
+
+func (s *runSummary) markdown() string {
+	var passed, failed int
+	for _, ts := range s.testSets {
+		passed += ts.passed
+		failed += ts.failed
+	}
+	status := "passed"
+	if failed > 0 {
+		status = "failed"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Keploy test run `%s` %s\n\n", s.head, status)
+	fmt.Fprintf(&b, "**%d passed, %d failed** in %d test sets", passed, failed, len(s.testSets))
+	if s.base != "" {
+		fmt.Fprintf(&b, ", compared with `%s`", s.base)
+	}
+	b.WriteString("\n\n")
+
+	if s.coverage != nil {
+		fmt.Fprintf(&b, "**Coverage:** %.2f%%", s.coverage.Percent)
+		if s.baseCovered != nil {
+			fmt.Fprintf(&b, " (%+.2f%%)", s.coverage.Percent-s.baseCovered.Percent)
+		}
+		b.WriteString("\n\n")
+	}
+
+	b.WriteString("| Test set | Passed | Failed |\n|---|---|---|\n")
+	for _, ts := range s.testSets {
+		fmt.Fprintf(&b, "| %s | %d | %d |\n", ts.testSetID, ts.passed, ts.failed)
+	}
+
+	title := "New failures"
+	if s.base == "" {
+		title = "Failures"
+	}
+	writeDetails(&b, title, s.failures)
+	writeDetails(&b, fmt.Sprintf("Flaky in the last %d test runs", s.flakyRuns), s.flaky)
+	return b.String()
+}
+
+// writeDetails writes the items as a collapsed list, which keeps the comment compact.
+func writeDetails(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n<details><summary>%s (%d)</summary>\n\n", title, len(items))
+	for _, item := range items {
+		fmt.Fprintf(b, "- `%s`\n", item)
+	}
+	b.WriteString("\n</details>\n")
+}
+
+func indexOf(items []string, item string) int {
+	for i, it := range items {
+		if it == item {
+			return i
+		}
+	}
+	return -1
+}
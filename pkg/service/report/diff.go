@@ -0,0 +1,197 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+type Reporter struct {
+	logger   *zap.Logger
+	testDB   TestDB
+	reportDB ReportDB
+	config   config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, reportDB ReportDB, config config.Config) Service {
+	return &Reporter{
+		logger:   logger,
+		testDB:   testDB,
+		reportDB: reportDB,
+		config:   config,
+	}
+}
+
+// testSetDiff is the change of the results of a test set, the testcases are matched by their names.
+type testSetDiff struct {
+	testSetID    string
+	missing      string // the run the test set wasn't run in, if any
+	newlyFailing []string
+	newlyPassing []string
+	slower       []string // testcases whose latency regressed beyond the threshold
+	mocks        []string // testcases whose consumed mocks changed
+}
+
+func (d *testSetDiff) changed() bool {
+	return d.missing != "" || len(d.newlyFailing)+len(d.newlyPassing)+len(d.slower)+len(d.mocks) > 0
+}
+
+// Diff compares the reports of the test sets in the test runs base and head, and writes the testcases which
+// newly failed or passed, whose latency regressed and whose consumed mocks changed as markdown.
+func (r *Reporter) Diff(ctx context.Context, base, head string, w io.Writer) error {
+	testSetIDs := r.config.Report.TestSets
+	if len(testSetIDs) == 0 {
+		ids, err := r.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(r.logger, err, "failed to get the test sets")
+			return err
+		}
+		testSetIDs = ids
+	}
+
+	var diffs []*testSetDiff
+	for _, testSetID := range testSetIDs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		baseReport, baseErr := r.reportDB.GetReport(ctx, base, testSetID)
+		headReport, headErr := r.reportDB.GetReport(ctx, head, testSetID)
+		switch {
+		case baseErr != nil && headErr != nil:
+			r.logger.Debug("the test set wasn't run in the test runs", zap.String("test-set", testSetID))
+		case baseErr != nil:
+			diffs = append(diffs, &testSetDiff{testSetID: testSetID, missing: base})
+		case headErr != nil:
+			diffs = append(diffs, &testSetDiff{testSetID: testSetID, missing: head})
+		default:
+			diffs = append(diffs, r.diffTestSet(testSetID, baseReport, headReport))
+		}
+	}
+	if len(diffs) == 0 {
+		return fmt.Errorf("found no reports of the test runs %s and %s", base, head)
+	}
+	return r.write(w, base, head, diffs)
+}
+
+func (r *Reporter) diffTestSet(testSetID string, base, head *models.TestReport) *testSetDiff {
+	diff := &testSetDiff{testSetID: testSetID}
+	baseResults := make(map[string]models.TestResult, len(base.Tests))
+	for _, result := range base.Tests {
+		baseResults[result.Name] = result
+	}
+	threshold := 1 + r.config.Report.LatencyThreshold/100
+
+	for _, result := range head.Tests {
+		baseResult, ok := baseResults[result.Name]
+		// a new testcase which fails is newly failing too
+		if result.Status == models.TestStatusFailed && (!ok || baseResult.Status != models.TestStatusFailed) {
+			diff.newlyFailing = append(diff.newlyFailing, result.Name)
+		}
+		if !ok {
+			continue
+		}
+		if result.Status == models.TestStatusPassed && baseResult.Status == models.TestStatusFailed {
+			diff.newlyPassing = append(diff.newlyPassing, result.Name)
+		}
+
+		baseLatency, latency := baseResult.Result.LatencyResult, result.Result.LatencyResult
+		if baseLatency != nil && latency != nil && baseLatency.Actual > 0 && latency.Actual > baseLatency.Actual*threshold {
+			diff.slower = append(diff.slower, fmt.Sprintf("%s: %.1fms → %.1fms (+%.0f%%)", result.Name, baseLatency.Actual, latency.Actual, (latency.Actual/baseLatency.Actual-1)*100))
+		}
+
+		// the results recorded before the consumed mocks were added have none
+		if baseResult.ConsumedMocks == nil || result.ConsumedMocks == nil {
+			continue
+		}
+		if added, removed := setDiff(baseResult.ConsumedMocks, result.ConsumedMocks); len(added)+len(removed) > 0 {
+			var changes []string
+			for _, name := range added {
+				changes = append(changes, "+"+name)
+			}
+			for _, name := range removed {
+				changes = append(changes, "-"+name)
+			}
+			diff.mocks = append(diff.mocks, fmt.Sprintf("%s: %s", result.Name, strings.Join(changes, ", ")))
+		}
+	}
+	return diff
+}
+
+func (r *Reporter) write(w io.Writer, base, head string, diffs []*testSetDiff) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Keploy test run diff: `%s` → `%s`\n\n", base, head)
+	fmt.Fprintf(&b, "| Test set | Newly failing | Newly passing | Latency regressions | Changed mock consumption |\n|---|---|---|---|---|\n")
+	for _, d := range diffs {
+		if d.missing != "" {
+			fmt.Fprintf(&b, "| %s | not run in `%s` | | | |\n", d.testSetID, d.missing)
+			continue
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %d | %d |\n", d.testSetID, len(d.newlyFailing), len(d.newlyPassing), len(d.slower), len(d.mocks))
+	}
+
+	changed := false
+	for _, d := range diffs {
+		if d.missing != "" || !d.changed() {
+			continue
+		}
+		changed = true
+		fmt.Fprintf(&b, "\n### %s\n", d.testSetID)
+		writeList(&b, "Newly failing", d.newlyFailing)
+		writeList(&b, "Newly passing", d.newlyPassing)
+		writeList(&b, fmt.Sprintf("Latency regressions (over %g%%)", r.config.Report.LatencyThreshold), d.slower)
+		writeList(&b, "Changed mock consumption", d.mocks)
+	}
+	if !changed {
+		b.WriteString("\nNo testcase changed between the test runs.\n")
+	}
+
+	if _, err := io.WriteString(w, b.String()); err != nil {
+		utils.LogError(r.logger, err, "failed to write the diff of the test runs")
+		return errors.New("failed to write the diff of the test runs")
+	}
+	return nil
+}
+
+func writeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n**%s**\n\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "- %s\n", item)
+	}
+}
+
+// setDiff returns the sorted names in b but not in a, and in a but not in b.
+func setDiff(a, b []string) ([]string, []string) {
+	inA := make(map[string]bool, len(a))
+	for _, name := range a {
+		inA[name] = true
+	}
+	inB := make(map[string]bool, len(b))
+	for _, name := range b {
+		inB[name] = true
+	}
+	var added, removed []string
+	for name := range inB {
+		if !inA[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range inA {
+		if !inB[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
@@ -0,0 +1,28 @@
+// Package report provides the comparison of the reports of two test runs and the summary of a test run, to be posted
+// as a comment on a pull request.
+package report
+
+import (
+	"context"
+	"io"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Diff writes the markdown summary of the changes of the results of the test run head from the test run base.
+	Diff(ctx context.Context, base, head string, w io.Writer) error
+	// Comment writes the summary of the test run head in the format, compared with the test run base, for a pull
+	// request comment.
+	Comment(ctx context.Context, head, base, format string, w io.Writer) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+	GetCoverage(ctx context.Context, testRunID string) (*models.CoverageReport, error)
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// Export formats supported by the export command
+const (
+	ExportFormatOpenAPI = "openapi"
+	ExportFormatPostman = "postman"
+)
+
+// Export converts the testcases of the given test-sets into the given format and writes it to the output path.
+// All the test-sets are exported if no test-set is selected.
+func (t *Tools) Export(ctx context.Context, format string, testSetIDs []string, outputPath string) error {
+	testCases, err := t.getTestCases(ctx, testSetIDs)
+	if err != nil {
+		return err
+	}
+	if len(testCases) == 0 {
+		return fmt.Errorf("no testcases found to export")
+	}
+
+	var doc interface{}
+	// postman collections can only be imported as json
+	isJSON := strings.ToLower(filepath.Ext(outputPath)) == ".json"
+	switch format {
+	case ExportFormatOpenAPI:
+		doc = openAPIFromTestCases(testCases)
+		if outputPath == "" {
+			outputPath = "openapi.yaml"
+		}
+	case ExportFormatPostman:
+		doc = postmanFromTestCases(testCases)
+		if outputPath == "" {
+			outputPath = "postman_collection.json"
+		}
+		isJSON = true
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	var data []byte
+	if isJSON {
+		data, err = json.MarshalIndent(doc, "", "  ")
+	} else {
+		data, err = yaml.Marshal(doc)
+	}
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to marshal the exported document", zap.String("format", format))
+		return err
+	}
+
+	err = os.WriteFile(outputPath, data, 0644)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to write the exported document", zap.String("path", outputPath))
+		return err
+	}
+	t.logger.Info("exported the testcases", zap.String("format", format), zap.String("path", outputPath))
+	return nil
+}
+
+// getTestCases returns the testcases of the given test-sets keyed by the test-set id.
+func (t *Tools) getTestCases(ctx context.Context, testSetIDs []string) (map[string][]*models.TestCase, error) {
+	if len(testSetIDs) == 0 {
+		var err error
+		testSetIDs, err = t.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to get the test-set ids")
+			return nil, err
+		}
+	}
+	testCases := map[string][]*models.TestCase{}
+	for _, testSetID := range testSetIDs {
+		tcs, err := t.testDB.GetTestCases(ctx, testSetID)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to get the testcases", zap.String("test-set", testSetID))
+			return nil, err
+		}
+		if len(tcs) > 0 {
+			testCases[testSetID] = tcs
+		}
+	}
+	return testCases, nil
+}
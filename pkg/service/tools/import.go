@@ -0,0 +1,472 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+var httpFileVarRegex = regexp.MustCompile(`^@([A-Za-z0-9_\-.]+)\s*=\s*(.*)$`)
+
+// ImportCurl converts the given curl commands into testcases. The commands are read from the file
+// if a file path is given. The testcases are added to the given test-set or to a new one.
+func (t *Tools) ImportCurl(ctx context.Context, curlCmds string, testSetID string) error {
+	if data, err := os.ReadFile(curlCmds); err == nil {
+		curlCmds = string(data)
+	}
+	cmds := splitCurlCommands(curlCmds)
+	if len(cmds) == 0 {
+		return errors.New("no curl command found")
+	}
+
+	var testCases []*models.TestCase
+	timestamp := time.Now().UTC()
+	for _, cmd := range cmds {
+		method, reqURL, header, body, err := parseCurl(cmd)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to parse the curl command", zap.String("curl", cmd))
+			return err
+		}
+		testCases = append(testCases, newHTTPTestCase(method, reqURL, header, body, 0, map[string]string{}, "", nil, timestamp))
+		timestamp = timestamp.Add(time.Millisecond)
+	}
+	return t.insertImportedTestCases(ctx, testSetID, testCases)
+}
+
+// ImportHTTPFile converts the requests of a VS Code/IntelliJ .http file into testcases.
+// The testcases are added to the given test-set or to a new one.
+func (t *Tools) ImportHTTPFile(ctx context.Context, path string, testSetID string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to read the http file", zap.String("path", path))
+		return err
+	}
+	reqs, err := parseHTTPFile(string(data))
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to parse the http file", zap.String("path", path))
+		return err
+	}
+	if len(reqs) == 0 {
+		return errors.New("no request found in the http file")
+	}
+
+	var testCases []*models.TestCase
+	timestamp := time.Now().UTC()
+	for _, req := range reqs {
+		testCases = append(testCases, newHTTPTestCase(req.method, req.url, req.header, req.body, 0, map[string]string{}, "", nil, timestamp))
+		timestamp = timestamp.Add(time.Millisecond)
+	}
+	return t.insertImportedTestCases(ctx, testSetID, testCases)
+}
+
+// insertImportedTestCases stores the testcases imported without a response. The expected
+// response is left empty to be captured from the application by `keploy test --update`.
+func (t *Tools) insertImportedTestCases(ctx context.Context, testSetID string, testCases []*models.TestCase) error {
+	testSetID, err := t.insertTestSet(ctx, testSetID, testCases)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("imported the requests", zap.Int("testcases", len(testCases)), zap.String("test-set", testSetID))
+	t.logger.Info("the expected responses are not captured yet, run `keploy test --update` to capture them from the application", zap.String("test-set", testSetID))
+	return nil
+}
+
+// insertTestSet stores the given testcases in the test-set and returns its id.
+// A new test-set is created if no test-set id is given.
+func (t *Tools) insertTestSet(ctx context.Context, testSetID string, testCases []*models.TestCase) (string, error) {
+	if testSetID == "" {
+		testSetIDs, err := t.testDB.GetAllTestSetIDs(ctx)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to get the test-set ids")
+			return "", err
+		}
+		testSetID = pkg.NewID(testSetIDs, models.TestSetPattern)
+	}
+
+	for _, tc := range testCases {
+		err := t.testDB.InsertTestCase(ctx, tc, testSetID)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to insert the testcase", zap.String("test-set", testSetID))
+			return "", err
+		}
+	}
+	return testSetID, nil
+}
+
+// newHTTPTestCase builds a http testcase from the request and the expected response of an imported source.
+func newHTTPTestCase(method, reqURL string, reqHeader map[string]string, reqBody string, statusCode int, respHeader map[string]string, respBody string, noise map[string][]string, timestamp time.Time) *models.TestCase {
+	urlParams := map[string]string{}
+	if parsedURL, err := url.Parse(reqURL); err == nil {
+		for key, values := range parsedURL.Query() {
+			urlParams[key] = strings.Join(values, ", ")
+		}
+	}
+	if noise == nil {
+		noise = map[string][]string{}
+	}
+	return &models.TestCase{
+		Version: models.GetVersion(),
+		Kind:    models.HTTP,
+		Created: timestamp.Unix(),
+		HTTPReq: models.HTTPReq{
+			Method:     models.Method(method),
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			URL:        reqURL,
+			URLParams:  urlParams,
+			Header:     reqHeader,
+			Body:       reqBody,
+			Timestamp:  timestamp,
+		},
+		HTTPResp: models.HTTPResp{
+			StatusCode:    statusCode,
+			Header:        respHeader,
+			Body:          respBody,
+			StatusMessage: http.StatusText(statusCode),
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Timestamp:     timestamp,
+		},
+		Noise: noise,
+	}
+}
+
+// splitCurlCommands splits the text into separate curl commands, joining the escaped line breaks.
+func splitCurlCommands(text string) []string {
+	text = strings.ReplaceAll(text, "\\\r\n", " ")
+	text = strings.ReplaceAll(text, "\\\n", " ")
+	var cmds []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "curl ") || len(cmds) == 0 {
+			cmds = append(cmds, line)
+			continue
+		}
+		cmds[len(cmds)-1] += " " + line
+	}
+	return cmds
+}
+
+// splitShellWords splits the command line into words, honouring the shell quoting rules.
+func splitShellWords(cmd string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, r := range cmd {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else if r == '\\' {
+				escaped = true
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote in the command")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// parseCurl extracts the request from a curl command line.
+func parseCurl(cmd string) (string, string, map[string]string, string, error) {
+	words, err := splitShellWords(cmd)
+	if err != nil {
+		return "", "", nil, "", err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return "", "", nil, "", errors.New("not a curl command")
+	}
+
+	var method, reqURL string
+	var data []string
+	header := map[string]string{}
+	isGet := false
+
+	// flags with a value that do not affect the request
+	ignoredWithValue := map[string]bool{"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true, "-w": true, "--write-out": true, "--retry": true}
+
+	for i := 1; i < len(words); i++ {
+		word := words[i]
+		value := func() (string, error) {
+			if i+1 >= len(words) {
+				return "", fmt.Errorf("missing value for %s", word)
+			}
+			i++
+			return words[i], nil
+		}
+		switch word {
+		case "-X", "--request":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			key, val, ok := strings.Cut(v, ":")
+			if ok {
+				header[strings.TrimSpace(key)] = strings.TrimSpace(val)
+			}
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			data = append(data, v)
+		case "--data-urlencode":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			if key, val, ok := strings.Cut(v, "="); ok {
+				v = key + "=" + url.QueryEscape(val)
+			} else {
+				v = url.QueryEscape(v)
+			}
+			data = append(data, v)
+		case "--json":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			data = append(data, v)
+			header["Content-Type"] = "application/json"
+			header["Accept"] = "application/json"
+		case "-u", "--user":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			header["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(v))
+		case "-A", "--user-agent":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			header["User-Agent"] = v
+		case "-e", "--referer":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			header["Referer"] = v
+		case "-b", "--cookie":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			header["Cookie"] = v
+		case "--url":
+			v, err := value()
+			if err != nil {
+				return "", "", nil, "", err
+			}
+			reqURL = v
+		case "-G", "--get":
+			isGet = true
+		case "-I", "--head":
+			method = http.MethodHead
+		default:
+			if ignoredWithValue[word] {
+				i++
+				continue
+			}
+			if !strings.HasPrefix(word, "-") && reqURL == "" {
+				reqURL = word
+			}
+		}
+	}
+
+	if reqURL == "" {
+		return "", "", nil, "", errors.New("no url found in the curl command")
+	}
+	if !strings.Contains(reqURL, "://") {
+		reqURL = "http://" + reqURL
+	}
+
+	body := strings.Join(data, "&")
+	if isGet && body != "" {
+		sep := "?"
+		if strings.Contains(reqURL, "?") {
+			sep = "&"
+		}
+		reqURL += sep + body
+		body = ""
+	}
+	if method == "" {
+		method = http.MethodGet
+		if body != "" {
+			method = http.MethodPost
+		}
+	}
+	if body != "" && headerValue(header, "Content-Type") == "" {
+		header["Content-Type"] = "application/x-www-form-urlencoded"
+	}
+	return method, reqURL, header, body, nil
+}
+
+type httpFileRequest struct {
+	method string
+	url    string
+	header map[string]string
+	body   string
+}
+
+// parseHTTPFile parses the requests of a VS Code REST client/IntelliJ http client file.
+// The requests are separated by ### and the file level variables are defined as @name = value.
+func parseHTTPFile(content string) ([]httpFileRequest, error) {
+	vars := map[string]string{}
+	var blocks [][]string
+	current := []string{}
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	scanner.Buffer(make([]byte, 1024*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "###") {
+			blocks = append(blocks, current)
+			current = []string{}
+			continue
+		}
+		if m := httpFileVarRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			vars[m[1]] = strings.TrimSpace(m[2])
+			continue
+		}
+		current = append(current, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	blocks = append(blocks, current)
+
+	resolve := func(s string) string {
+		return postmanVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := postmanVarRegex.FindStringSubmatch(match)[1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			return match
+		})
+	}
+
+	var reqs []httpFileRequest
+	for _, block := range blocks {
+		req, ok := parseHTTPFileBlock(block, resolve)
+		if ok {
+			reqs = append(reqs, req)
+		}
+	}
+	return reqs, nil
+}
+
+func parseHTTPFileBlock(lines []string, resolve func(string) string) (httpFileRequest, bool) {
+	req := httpFileRequest{header: map[string]string{}}
+	i := 0
+	// skipping the comments and the blank lines before the request line
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		break
+	}
+	if i == len(lines) {
+		return req, false
+	}
+
+	parts := strings.Fields(resolve(lines[i]))
+	switch {
+	case len(parts) == 1:
+		req.method, req.url = http.MethodGet, parts[0]
+	case len(parts) >= 2:
+		req.method, req.url = strings.ToUpper(parts[0]), parts[1]
+	default:
+		return req, false
+	}
+	i++
+
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed == "" {
+			i++
+			break
+		}
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+		if key, val, ok := strings.Cut(resolve(trimmed), ":"); ok {
+			req.header[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		}
+	}
+
+	var body []string
+	inHandler := false
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		// response handler scripts and response references of the IntelliJ http client are not part of the body
+		if strings.HasPrefix(trimmed, "> {%") {
+			inHandler = !strings.HasSuffix(trimmed, "%}")
+			continue
+		}
+		if inHandler {
+			inHandler = !strings.HasSuffix(trimmed, "%}")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "<> ") || strings.HasPrefix(trimmed, ">> ") {
+			continue
+		}
+		body = append(body, lines[i])
+	}
+	req.body = strings.TrimSpace(resolve(strings.Join(body, "\n")))
+	return req, true
+}
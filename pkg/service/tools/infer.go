@@ -0,0 +1,298 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg"
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+var (
+	uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hexRegex  = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+// openAPIFromTestCases infers an OpenAPI 3 document from the recorded http testcases.
+func openAPIFromTestCases(testCases map[string][]*models.TestCase) *models.OpenAPI {
+	spec := &models.OpenAPI{
+		OpenAPI: "3.0.3",
+		Info: models.OpenAPIInfo{
+			Title:       "Keploy exported API",
+			Description: "Inferred from the testcases recorded by keploy",
+			Version:     "1.0.0",
+		},
+		Paths: map[string]*models.OpenAPIPathItem{},
+	}
+
+	testSetIDs := make([]string, 0, len(testCases))
+	for testSetID := range testCases {
+		testSetIDs = append(testSetIDs, testSetID)
+	}
+	sort.Strings(testSetIDs)
+
+	servers := map[string]bool{}
+	for _, testSetID := range testSetIDs {
+		for _, tc := range testCases[testSetID] {
+			if tc.Kind != models.HTTP {
+				continue
+			}
+			parsedURL, err := url.Parse(tc.HTTPReq.URL)
+			if err != nil {
+				continue
+			}
+			servers[parsedURL.Scheme+"://"+parsedURL.Host] = true
+
+			path, pathParams := templatePath(parsedURL.Path)
+			item, ok := spec.Paths[path]
+			if !ok {
+				item = &models.OpenAPIPathItem{}
+				spec.Paths[path] = item
+			}
+			method := strings.ToUpper(string(tc.HTTPReq.Method))
+			_, ops := item.Operations()
+			op, ok := ops[method]
+			if !ok {
+				op = &models.OpenAPIOperation{
+					Responses: map[string]*models.OpenAPIResponse{},
+				}
+				item.SetOperation(method, op)
+			}
+			mergeOperation(op, tc, pathParams, parsedURL.Query())
+		}
+	}
+
+	serverURLs := make([]string, 0, len(servers))
+	for server := range servers {
+		serverURLs = append(serverURLs, server)
+	}
+	sort.Strings(serverURLs)
+	for _, server := range serverURLs {
+		spec.Servers = append(spec.Servers, models.OpenAPIServer{URL: server})
+	}
+	return spec
+}
+
+// templatePath replaces the identifier like segments of the path with path parameters.
+// It returns the templated path along with the parameters and the recorded values.
+func templatePath(path string) (string, []*models.OpenAPIParameter) {
+	segments := strings.Split(path, "/")
+	var params []*models.OpenAPIParameter
+	names := map[string]bool{}
+	for i, segment := range segments {
+		if !isPathParam(segment) {
+			continue
+		}
+		name := "id"
+		if i > 0 && segments[i-1] != "" && !isPathParam(segments[i-1]) {
+			name = strings.TrimSuffix(segments[i-1], "s") + "Id"
+		}
+		base := name
+		for j := 2; names[name]; j++ {
+			name = fmt.Sprintf("%s%d", base, j)
+		}
+		names[name] = true
+		params = append(params, &models.OpenAPIParameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   inferSchema(scalarValue(segment)),
+			Example:  scalarValue(segment),
+		})
+		segments[i] = "{" + name + "}"
+	}
+	if path == "" {
+		return "/", params
+	}
+	return strings.Join(segments, "/"), params
+}
+
+func isPathParam(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	if _, err := strconv.ParseInt(segment, 10, 64); err == nil {
+		return true
+	}
+	return uuidRegex.MatchString(segment) || hexRegex.MatchString(segment)
+}
+
+func mergeOperation(op *models.OpenAPIOperation, tc *models.TestCase, pathParams []*models.OpenAPIParameter, query url.Values) {
+	existing := map[string]bool{}
+	for _, param := range op.Parameters {
+		existing[param.In+":"+param.Name] = true
+	}
+	for _, param := range pathParams {
+		if !existing["path:"+param.Name] {
+			op.Parameters = append(op.Parameters, param)
+			existing["path:"+param.Name] = true
+		}
+	}
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if existing["query:"+key] {
+			continue
+		}
+		value := scalarValue(query.Get(key))
+		op.Parameters = append(op.Parameters, &models.OpenAPIParameter{
+			Name:    key,
+			In:      "query",
+			Schema:  inferSchema(value),
+			Example: value,
+		})
+		existing["query:"+key] = true
+	}
+
+	if tc.HTTPReq.Body != "" {
+		contentType := headerValue(tc.HTTPReq.Header, "Content-Type")
+		if op.RequestBody == nil {
+			op.RequestBody = &models.OpenAPIRequestBody{Content: map[string]*models.OpenAPIMediaType{}}
+		}
+		mergeMediaType(op.RequestBody.Content, contentType, tc.HTTPReq.Body)
+	}
+
+	code := strconv.Itoa(tc.HTTPResp.StatusCode)
+	resp, ok := op.Responses[code]
+	if !ok {
+		resp = &models.OpenAPIResponse{Description: http.StatusText(tc.HTTPResp.StatusCode)}
+		op.Responses[code] = resp
+	}
+	if tc.HTTPResp.Body != "" {
+		if resp.Content == nil {
+			resp.Content = map[string]*models.OpenAPIMediaType{}
+		}
+		mergeMediaType(resp.Content, headerValue(tc.HTTPResp.Header, "Content-Type"), tc.HTTPResp.Body)
+	}
+}
+
+func mergeMediaType(content map[string]*models.OpenAPIMediaType, contentType string, body string) {
+	if contentType == "" {
+		contentType = "text/plain"
+		if json.Valid([]byte(body)) {
+			contentType = "application/json"
+		}
+	}
+	// parameters like charset are not part of the media type key
+	contentType = strings.TrimSpace(strings.Split(contentType, ";")[0])
+
+	var example interface{} = body
+	if json.Valid([]byte(body)) {
+		var v interface{}
+		if err := json.Unmarshal([]byte(body), &v); err == nil {
+			example = v
+		}
+	}
+
+	media, ok := content[contentType]
+	if !ok {
+		content[contentType] = &models.OpenAPIMediaType{
+			Schema:  inferSchema(example),
+			Example: example,
+		}
+		return
+	}
+	media.Schema = mergeSchema(media.Schema, inferSchema(example))
+}
+
+func headerValue(header map[string]string, key string) string {
+	for k, v := range header {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return ""
+}
+
+// scalarValue converts the string value of a path or query parameter into its typed form.
+func scalarValue(s string) interface{} {
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return f
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return b
+	}
+	return s
+}
+
+// inferSchema infers the schema of a value decoded from json.
+func inferSchema(v interface{}) *models.OpenAPISchema {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		schema := &models.OpenAPISchema{Type: "object", Properties: map[string]*models.OpenAPISchema{}}
+		for key, prop := range val {
+			schema.Properties[key] = inferSchema(prop)
+		}
+		return schema
+	case []interface{}:
+		schema := &models.OpenAPISchema{Type: "array"}
+		for _, item := range val {
+			schema.Items = mergeSchema(schema.Items, inferSchema(item))
+		}
+		if schema.Items == nil {
+			schema.Items = &models.OpenAPISchema{}
+		}
+		return schema
+	case float64:
+		if val == math.Trunc(val) {
+			return &models.OpenAPISchema{Type: "integer"}
+		}
+		return &models.OpenAPISchema{Type: "number"}
+	case int64, int:
+		return &models.OpenAPISchema{Type: "integer"}
+	case bool:
+		return &models.OpenAPISchema{Type: "boolean"}
+	case string:
+		schema := &models.OpenAPISchema{Type: "string"}
+		switch {
+		case uuidRegex.MatchString(val):
+			schema.Format = "uuid"
+		case pkg.IsTime(val):
+			schema.Format = "date-time"
+		}
+		return schema
+	}
+	return &models.OpenAPISchema{}
+}
+
+// mergeSchema merges the schemas inferred from different samples of the same field.
+func mergeSchema(a, b *models.OpenAPISchema) *models.OpenAPISchema {
+	if a == nil || a.Type == "" {
+		return b
+	}
+	if b == nil || b.Type == "" {
+		return a
+	}
+	if a.Type != b.Type {
+		if (a.Type == "integer" && b.Type == "number") || (a.Type == "number" && b.Type == "integer") {
+			return &models.OpenAPISchema{Type: "number"}
+		}
+		return a
+	}
+	switch a.Type {
+	case "object":
+		for key, prop := range b.Properties {
+			a.Properties[key] = mergeSchema(a.Properties[key], prop)
+		}
+	case "array":
+		a.Items = mergeSchema(a.Items, b.Items)
+	case "string":
+		if a.Format != b.Format {
+			a.Format = ""
+		}
+	}
+	return a
+}
@@ -0,0 +1,152 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// Init runs the wizard writing a starter keploy.yml in the directory of the app. It detects whether the app is run
+// with docker compose, its language and its port, and asks to confirm them.
+func (t *Tools) Init(ctx context.Context, dir string) error {
+	setup := utils.DetectApp(dir)
+	switch {
+	case setup.Compose != "" && setup.Language != "":
+		fmt.Printf("Detected a %s app run with docker compose from %s\n\n", setup.Language, setup.Compose)
+	case setup.Compose != "":
+		fmt.Printf("Detected an app run with docker compose from %s\n\n", setup.Compose)
+	case setup.Language != "":
+		fmt.Printf("Detected a %s app\n\n", setup.Language)
+	default:
+		fmt.Printf("Detected no app in %s, fill in how it is run\n\n", dir)
+	}
+
+	p := &prompter{in: bufio.NewReader(os.Stdin), out: os.Stdout}
+	docker, err := p.confirm("Is the app run with docker?", setup.Compose != "")
+	if err != nil {
+		return err
+	}
+	values := map[string]interface{}{}
+	command := setup.Native
+	if docker {
+		command = "docker compose up"
+		if setup.Compose != "" {
+			command = "docker compose -f " + setup.Compose + " up"
+		}
+	}
+	command, err = p.ask("Command to run the app", command)
+	if err != nil {
+		return err
+	}
+	values["command"] = command
+	if docker {
+		container, err := p.ask("Name of the container of the app", setup.Container)
+		if err != nil {
+			return err
+		}
+		values["containerName"] = container
+	}
+	for {
+		answer, err := p.ask("Port the app listens on", strconv.Itoa(int(setup.Port)))
+		if err != nil {
+			return err
+		}
+		port, err := strconv.ParseUint(answer, 10, 16)
+		if err == nil {
+			values["port"] = port
+			break
+		}
+		fmt.Fprintf(p.out, "invalid port %q\n", answer)
+	}
+
+	filePath := filepath.Join(dir, "keploy.yml")
+	if utils.CheckFileExists(filePath) {
+		override, err := p.confirm("Config file already exists. Do you want to override it?", false)
+		if err != nil {
+			return err
+		}
+		if !override {
+			return nil
+		}
+	}
+	overlay, err := yaml.Marshal(values)
+	if err != nil {
+		return err
+	}
+	defaults, err := config.Merge(config.InternalConfig, config.GetDefaultConfig())
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to create default config string")
+		return err
+	}
+	configData, err := config.Merge(string(overlay), defaults)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to merge the answers into the default config")
+		return err
+	}
+	err = t.CreateConfig(ctx, filePath, configData)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nWrote %s, record the testcases of the app with:\n\n  sudo -E keploy record\n\n", filePath)
+	return nil
+}
+
+// prompter asks the questions of the wizard, the input is read with a single reader so that the answers can be piped.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// ask asks the question, the empty answer and the end of the input are the default.
+func (p *prompter) ask(question string, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	answer, err := p.in.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// confirm asks the yes or no question, the empty answer and the end of the input are the default.
+func (p *prompter) confirm(question string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, hint)
+		answer, err := p.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		if errors.Is(err, io.EOF) {
+			return def, nil
+		}
+	}
+}
@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/pkg/platform/yaml"
+	"go.keploy.io/server/v2/pkg/platform/yaml/mockdb"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+// EditMock opens the mock of the test set in the editor of $VISUAL or $EDITOR. The edited mock is validated when the
+// editor exits, an invalid mock can be edited again, and saved with its derived fields recomputed.
+func (t *Tools) EditMock(ctx context.Context, testSetID string, mockName string) error {
+	mocks, err := t.mockDB.GetMocks(ctx, testSetID)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to get the mocks", zap.String("test-set", testSetID))
+		return err
+	}
+	var mock *models.Mock
+	for _, m := range mocks {
+		if m.Name == mockName {
+			mock = m
+			break
+		}
+	}
+	if mock == nil {
+		return fmt.Errorf("mock %s not found in %s", mockName, testSetID)
+	}
+
+	doc, err := mockdb.EncodeMock(mock, t.logger)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to encode the mock", zap.String("mock", mockName))
+		return err
+	}
+	original, err := yamlLib.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	// the mock is edited decrypted, the temp file is readable by the user only and removed once the mock is saved
+	file, err := os.CreateTemp("", "keploy-"+testSetID+"-"+mockName+"-*.yaml")
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to create the temp file of the mock")
+		return err
+	}
+	defer func() {
+		if err := os.Remove(file.Name()); err != nil {
+			utils.LogError(t.logger, err, "failed to remove the temp file of the mock", zap.String("file", file.Name()))
+		}
+	}()
+	_, err = file.Write(original)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to write the temp file of the mock", zap.String("file", file.Name()))
+		return err
+	}
+
+	var edited *models.Mock
+	for {
+		err = openEditor(ctx, file.Name())
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to run the editor")
+			return err
+		}
+		data, err := os.ReadFile(file.Name())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(data, original) {
+			t.logger.Info("the mock is unchanged, nothing to save", zap.String("mock", mockName))
+			return nil
+		}
+		edited, err = parseMock(data, mock)
+		if err == nil {
+			break
+		}
+		t.logger.Error("the edited mock is invalid", zap.String("mock", mockName), zap.Error(err))
+		again, cerr := utils.AskForConfirmation("Edit the mock again? The changes are discarded otherwise")
+		if cerr != nil {
+			return cerr
+		}
+		if !again {
+			return err
+		}
+	}
+
+	recomputeMock(edited)
+	err = t.mockDB.UpdateMock(ctx, testSetID, edited)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to save the edited mock", zap.String("mock", mockName))
+		return err
+	}
+	t.logger.Info("saved the edited mock", zap.String("test-set", testSetID), zap.String("mock", mockName))
+	return nil
+}
+
+// openEditor runs the editor of $VISUAL or $EDITOR, which may have arguments e.g. "code --wait", on the file.
+func openEditor(ctx context.Context, path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	args := strings.Fields(editor)
+	cmd := exec.CommandContext(ctx, args[0], append(args[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// parseMock decodes the edited mock doc, its fields are checked against the schema of its kind. The name and the kind
+// of the mock can't be edited.
+func parseMock(data []byte, original *models.Mock) (*models.Mock, error) {
+	var doc yaml.NetworkTrafficDoc
+	dec := yamlLib.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid yaml: %w", err)
+	}
+	if doc.Name != original.Name {
+		return nil, fmt.Errorf("the name of the mock can't be changed from %s", original.Name)
+	}
+	if doc.Kind != original.Kind {
+		return nil, fmt.Errorf("the kind of the mock can't be changed from %s", original.Kind)
+	}
+	if err := checkSpecFields(&doc); err != nil {
+		return nil, err
+	}
+	mocks, err := mockdb.DecodeMocks([]*yaml.NetworkTrafficDoc{&doc}, nil, zap.NewNop())
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s spec: %w", doc.Kind, err)
+	}
+	if len(mocks) != 1 {
+		return nil, fmt.Errorf("unsupported mock kind %s", doc.Kind)
+	}
+	mock := mocks[0]
+	if mock.Kind == models.HTTP {
+		if err := validateHTTPMock(mock); err != nil {
+			return nil, err
+		}
+	}
+	return mock, nil
+}
+
+// checkSpecFields rejects the fields of the spec which aren't in the schema of the http mocks, which are the ones
+// edited by hand the most, a misspelt field would be dropped silently otherwise.
+func checkSpecFields(doc *yaml.NetworkTrafficDoc) error {
+	if doc.Kind != models.HTTP {
+		return nil
+	}
+	spec, err := yamlLib.Marshal(&doc.Spec)
+	if err != nil {
+		return err
+	}
+	dec := yamlLib.NewDecoder(bytes.NewReader(spec))
+	dec.KnownFields(true)
+	var schema models.HTTPSchema
+	if err := dec.Decode(&schema); err != nil {
+		return fmt.Errorf("invalid %s spec: %w", doc.Kind, err)
+	}
+	return nil
+}
+
+func validateHTTPMock(mock *models.Mock) error {
+	req, resp := mock.Spec.HTTPReq, mock.Spec.HTTPResp
+	if req == nil || resp == nil {
+		return errors.New("the http mock needs both a req and a resp")
+	}
+	if req.Method == "" || strings.ContainsAny(string(req.Method), " \t\r\n") {
+		return fmt.Errorf("invalid http method %q", req.Method)
+	}
+	if _, err := url.ParseRequestURI(req.URL); err != nil {
+		return fmt.Errorf("invalid url %q: %w", req.URL, err)
+	}
+	if resp.StatusCode < 100 || resp.StatusCode > 599 {
+		return fmt.Errorf("invalid http status code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// recomputeMock recomputes the fields of the mock derived from the edited ones: the Content-Length of the http
+// bodies, the query params of the url and the operation, and the response time, which can't precede the request.
+func recomputeMock(mock *models.Mock) {
+	if req := mock.Spec.HTTPReq; req != nil {
+		setContentLength(req.Header, req.Body, true)
+		if u, err := url.Parse(req.URL); err == nil {
+			params := map[string]string{}
+			for key, values := range u.Query() {
+				params[key] = strings.Join(values, ", ")
+			}
+			req.URLParams = params
+		}
+		if mock.Spec.Metadata != nil && mock.Spec.Metadata["operation"] != "" {
+			mock.Spec.Metadata["operation"] = string(req.Method)
+		}
+		if resp := mock.Spec.HTTPResp; resp != nil {
+			withBody := req.Method != http.MethodHead && resp.StatusCode >= 200 && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotModified
+			setContentLength(resp.Header, resp.Body, withBody)
+		}
+	}
+	if mock.Spec.ResTimestampMock.Before(mock.Spec.ReqTimestampMock) {
+		mock.Spec.ResTimestampMock = mock.Spec.ReqTimestampMock
+	}
+}
+
+// setContentLength sets the Content-Length header to the length of the body if the header is recorded, the one of
+// a message without a body is kept as is.
+func setContentLength(header map[string]string, body string, withBody bool) {
+	if !withBody {
+		return
+	}
+	for key := range header {
+		if strings.EqualFold(key, "Content-Length") {
+			header[key] = strconv.Itoa(len(body))
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// AddNoise adds the field to the noise of the test set, or to the global noise if the test set is empty, in the
+// config file at filePath. The field is scoped by its prefix, header.<name> for a header and body.<path> or a
+// JSONPath for a field of the body. The rest of the config file, with its comments, is kept as is.
+func (t *Tools) AddNoise(_ context.Context, filePath, testSetID, field string, regexes []string) error {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read the config file, generate it with keploy config --generate: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse the config file: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return errors.New("the config file is empty, generate it with keploy config --generate")
+	}
+
+	scope, key := noiseScope(field)
+	if key == "" {
+		return fmt.Errorf("missing the %s field in the noise %q", scope, field)
+	}
+	path := []string{"test", "globalNoise", "global", scope}
+	if testSetID != "" {
+		path = []string{"test", "globalNoise", "test-sets", testSetID, scope}
+	}
+	node := doc.Content[0]
+	for _, name := range path {
+		node, err = mappingValue(node, name)
+		if err != nil {
+			return err
+		}
+	}
+	values := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Style: yaml.FlowStyle}
+	for _, regex := range regexes {
+		values.Content = append(values.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: regex})
+	}
+	setMappingValue(node, key, values)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to marshal the config file: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to marshal the config file: %w", err)
+	}
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filePath, buf.Bytes(), info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write the config file: %w", err)
+	}
+
+	scopeOf := "global noise"
+	if testSetID != "" {
+		scopeOf = "noise of " + testSetID
+	}
+	t.logger.Info("added the field to the "+scopeOf, zap.String(scope, key), zap.Strings("regexes", regexes))
+	return nil
+}
+
+// noiseScope returns the scope and the key of a noisy field, header for header.<name> and body otherwise.
+func noiseScope(field string) (string, string) {
+	if name, ok := strings.CutPrefix(field, "header."); ok {
+		return "header", name
+	}
+	if path, ok := strings.CutPrefix(field, "body."); ok {
+		return "body", path
+	}
+	return "body", field
+}
+
+// mappingValue returns the mapping under the key of the mapping node, adding it if missing. The flow style of an
+// empty mapping, e.g. {}, is dropped so that the added keys are written as a block.
+func mappingValue(node *yaml.Node, key string) (*yaml.Node, error) {
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping in the config file before %s", key)
+	}
+	node.Style = 0
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			// an empty value, e.g. `global:`, is a null which is replaced with a mapping
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				*value = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			}
+			value.Style = 0
+			return value, nil
+		}
+	}
+	value := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	setMappingValue(node, key, value)
+	return value, nil
+}
+
+// setMappingValue sets the value of the key of the mapping node, adding the key if missing.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
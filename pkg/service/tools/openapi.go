@@ -0,0 +1,347 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// maxSchemaDepth limits the recursion while building example payloads from self-referencing schemas.
+const maxSchemaDepth = 8
+
+// GenerateFromOpenAPI synthesizes testcases from the operations of an OpenAPI document and
+// stores them in a new test-set.
+func (t *Tools) GenerateFromOpenAPI(ctx context.Context, specPath string, baseURL string) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to read the openapi spec", zap.String("path", specPath))
+		return err
+	}
+
+	var spec models.OpenAPI
+	// yaml is a superset of json, hence both the formats of the spec can be decoded here
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		utils.LogError(t.logger, err, "failed to parse the openapi spec", zap.String("path", specPath))
+		return err
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return fmt.Errorf("unsupported openapi version %q, only openapi 3.x documents are supported", spec.OpenAPI)
+	}
+
+	if baseURL == "" {
+		baseURL = "http://localhost:8080"
+		if len(spec.Servers) > 0 && strings.HasPrefix(spec.Servers[0].URL, "http") {
+			baseURL = spec.Servers[0].URL
+		}
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	testCases, err := testCasesFromOpenAPI(&spec, baseURL)
+	if err != nil {
+		return err
+	}
+	if len(testCases) == 0 {
+		return errors.New("no operations found in the openapi spec")
+	}
+
+	testSetID, err := t.insertTestSet(ctx, "", testCases)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("generated testcases from the openapi spec", zap.Int("testcases", len(testCases)), zap.String("test-set", testSetID))
+	return nil
+}
+
+func testCasesFromOpenAPI(spec *models.OpenAPI, baseURL string) ([]*models.TestCase, error) {
+	paths := make([]string, 0, len(spec.Paths))
+	for path := range spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var testCases []*models.TestCase
+	// timestamps are incremented per testcase to retain the order of the operations in the spec
+	timestamp := time.Now().UTC()
+	for _, path := range paths {
+		item := spec.Paths[path]
+		if item == nil {
+			continue
+		}
+		methods, ops := item.Operations()
+		for _, method := range methods {
+			op := ops[method]
+			params := append(resolveParameters(spec, item.Parameters), resolveParameters(spec, op.Parameters)...)
+			tc, err := openAPITestCase(spec, baseURL, path, method, op, params, timestamp)
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate testcase for %s %s: %w", method, path, err)
+			}
+			testCases = append(testCases, tc)
+			timestamp = timestamp.Add(time.Millisecond)
+		}
+	}
+	return testCases, nil
+}
+
+func openAPITestCase(spec *models.OpenAPI, baseURL, path, method string, op *models.OpenAPIOperation, params []*models.OpenAPIParameter, timestamp time.Time) (*models.TestCase, error) {
+	reqHeader := map[string]string{}
+	query := url.Values{}
+	for _, param := range params {
+		if param == nil {
+			continue
+		}
+		value := exampleString(parameterExample(spec, param))
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(value))
+		case "query":
+			if param.Required || param.Example != nil {
+				query.Set(param.Name, value)
+			}
+		case "header":
+			if param.Required || param.Example != nil {
+				reqHeader[param.Name] = value
+			}
+		}
+	}
+
+	reqURL := baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	var reqBody string
+	if op.RequestBody != nil {
+		contentType, media := pickMediaType(op.RequestBody.Content)
+		if media != nil {
+			body, _, err := mediaExample(spec, media)
+			if err != nil {
+				return nil, err
+			}
+			reqBody = body
+			reqHeader["Content-Type"] = contentType
+		}
+	}
+
+	statusCode, resp := pickResponse(op.Responses)
+	noise := map[string][]string{
+		// response headers are not part of the spec, hence they are not asserted
+		"header": {},
+	}
+	var respBody string
+	if resp != nil {
+		_, media := pickMediaType(resp.Content)
+		if media != nil {
+			body, isExample, err := mediaExample(spec, media)
+			if err != nil {
+				return nil, err
+			}
+			respBody = body
+			if !isExample {
+				// the body is built from the schema with placeholder values, so only the status code is asserted
+				noise["body"] = []string{}
+			}
+		}
+	}
+
+	return newHTTPTestCase(method, reqURL, reqHeader, reqBody, statusCode, map[string]string{}, respBody, noise, timestamp), nil
+}
+
+// pickResponse returns the lowest 2xx response of the operation, falling back to the default response.
+func pickResponse(responses map[string]*models.OpenAPIResponse) (int, *models.OpenAPIResponse) {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			statusCode, err := strconv.Atoi(code)
+			if err != nil {
+				// ranges like 2XX
+				statusCode = http.StatusOK
+			}
+			return statusCode, responses[code]
+		}
+	}
+	if resp, ok := responses["default"]; ok {
+		return http.StatusOK, resp
+	}
+	return http.StatusOK, nil
+}
+
+// pickMediaType prefers the json content of a request or response.
+func pickMediaType(content map[string]*models.OpenAPIMediaType) (string, *models.OpenAPIMediaType) {
+	if media, ok := content["application/json"]; ok {
+		return "application/json", media
+	}
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	for _, contentType := range types {
+		if strings.Contains(contentType, "json") {
+			return contentType, content[contentType]
+		}
+	}
+	if len(types) > 0 {
+		return types[0], content[types[0]]
+	}
+	return "", nil
+}
+
+// mediaExample returns the serialized example of a media type and whether it was explicitly provided in the spec.
+func mediaExample(spec *models.OpenAPI, media *models.OpenAPIMediaType) (string, bool, error) {
+	example := media.Example
+	isExample := example != nil
+	if example == nil && len(media.Examples) > 0 {
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		example = media.Examples[names[0]].Value
+		isExample = example != nil
+	}
+	if example == nil && media.Schema != nil {
+		example = schemaExample(spec, media.Schema, 0)
+	}
+	if example == nil {
+		return "", isExample, nil
+	}
+	if s, ok := example.(string); ok {
+		return s, isExample, nil
+	}
+	body, err := json.Marshal(example)
+	if err != nil {
+		return "", isExample, err
+	}
+	return string(body), isExample, nil
+}
+
+func parameterExample(spec *models.OpenAPI, param *models.OpenAPIParameter) interface{} {
+	if param.Example != nil {
+		return param.Example
+	}
+	if param.Schema != nil {
+		return schemaExample(spec, param.Schema, 0)
+	}
+	return "string"
+}
+
+// schemaExample builds an example value for the schema, resolving the local references.
+func schemaExample(spec *models.OpenAPI, schema *models.OpenAPISchema, depth int) interface{} {
+	if schema == nil || depth > maxSchemaDepth {
+		return nil
+	}
+	if schema.Ref != "" {
+		return schemaExample(spec, resolveSchema(spec, schema.Ref), depth+1)
+	}
+	if schema.Example != nil {
+		return schema.Example
+	}
+	if schema.Default != nil {
+		return schema.Default
+	}
+	if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+	switch schema.Type {
+	case "object", "":
+		if len(schema.Properties) == 0 {
+			if schema.Type == "" {
+				return nil
+			}
+			return map[string]interface{}{}
+		}
+		obj := map[string]interface{}{}
+		for name, prop := range schema.Properties {
+			obj[name] = schemaExample(spec, prop, depth+1)
+		}
+		return obj
+	case "array":
+		item := schemaExample(spec, schema.Items, depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+		return []interface{}{item}
+	case "integer":
+		return 0
+	case "number":
+		return 0.0
+	case "boolean":
+		return true
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return models.BaseTime.Format(time.RFC3339)
+		case "date":
+			return models.BaseTime.Format("2006-01-02")
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		}
+		return "string"
+	}
+	return nil
+}
+
+func resolveSchema(spec *models.OpenAPI, ref string) *models.OpenAPISchema {
+	const prefix = "#/components/schemas/"
+	if spec.Components == nil || !strings.HasPrefix(ref, prefix) {
+		return nil
+	}
+	return spec.Components.Schemas[strings.TrimPrefix(ref, prefix)]
+}
+
+func resolveParameters(spec *models.OpenAPI, params []*models.OpenAPIParameter) []*models.OpenAPIParameter {
+	const prefix = "#/components/parameters/"
+	resolved := make([]*models.OpenAPIParameter, 0, len(params))
+	for _, param := range params {
+		if param == nil {
+			continue
+		}
+		if param.Ref != "" {
+			if spec.Components == nil || !strings.HasPrefix(param.Ref, prefix) {
+				continue
+			}
+			param = spec.Components.Parameters[strings.TrimPrefix(param.Ref, prefix)]
+			if param == nil {
+				continue
+			}
+		}
+		resolved = append(resolved, param)
+	}
+	return resolved
+}
+
+func exampleString(example interface{}) string {
+	switch v := example.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int, int64, float64, bool:
+		return fmt.Sprint(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
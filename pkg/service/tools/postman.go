@@ -0,0 +1,274 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+var postmanVarRegex = regexp.MustCompile(`{{\s*([^{}\s]+)\s*}}`)
+
+// ImportPostman converts the requests of a postman collection into testcases of a new test-set.
+// The variables used in the collection are resolved from the collection and the environment file.
+func (t *Tools) ImportPostman(ctx context.Context, collectionPath string, envPath string) error {
+	data, err := os.ReadFile(collectionPath)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to read the postman collection", zap.String("path", collectionPath))
+		return err
+	}
+	var collection models.PostmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		utils.LogError(t.logger, err, "failed to parse the postman collection", zap.String("path", collectionPath))
+		return err
+	}
+
+	vars := map[string]string{}
+	for _, v := range collection.Variable {
+		if v.Enabled == nil || *v.Enabled {
+			vars[v.Key] = v.Value
+		}
+	}
+	if envPath != "" {
+		data, err := os.ReadFile(envPath)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to read the postman environment", zap.String("path", envPath))
+			return err
+		}
+		var env models.PostmanEnvironment
+		if err := json.Unmarshal(data, &env); err != nil {
+			utils.LogError(t.logger, err, "failed to parse the postman environment", zap.String("path", envPath))
+			return err
+		}
+		// environment variables take precedence over the collection variables
+		for _, v := range env.Values {
+			if v.Enabled == nil || *v.Enabled {
+				vars[v.Key] = v.Value
+			}
+		}
+	}
+
+	var testCases []*models.TestCase
+	timestamp := time.Now().UTC()
+	unresolved := map[string]bool{}
+	var walk func(items []models.PostmanItem)
+	walk = func(items []models.PostmanItem) {
+		for _, item := range items {
+			if item.Request == nil {
+				walk(item.Item)
+				continue
+			}
+			tc := postmanTestCase(item, vars, unresolved, timestamp)
+			testCases = append(testCases, tc)
+			timestamp = timestamp.Add(time.Millisecond)
+		}
+	}
+	walk(collection.Item)
+
+	if len(unresolved) > 0 {
+		names := make([]string, 0, len(unresolved))
+		for name := range unresolved {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		t.logger.Warn("some variables of the postman collection are not defined, pass an environment file using --env", zap.Strings("variables", names))
+	}
+	if len(testCases) == 0 {
+		return errors.New("no requests found in the postman collection")
+	}
+
+	testSetID, err := t.insertTestSet(ctx, "", testCases)
+	if err != nil {
+		return err
+	}
+	t.logger.Info("imported the postman collection", zap.String("collection", collection.Info.Name), zap.Int("testcases", len(testCases)), zap.String("test-set", testSetID))
+	return nil
+}
+
+func postmanTestCase(item models.PostmanItem, vars map[string]string, unresolved map[string]bool, timestamp time.Time) *models.TestCase {
+	resolve := func(s string) string {
+		return postmanVarRegex.ReplaceAllStringFunc(s, func(match string) string {
+			name := postmanVarRegex.FindStringSubmatch(match)[1]
+			if v, ok := vars[name]; ok {
+				return v
+			}
+			unresolved[name] = true
+			return match
+		})
+	}
+
+	req := item.Request
+	reqHeader := postmanHeaders(req.Header, resolve)
+	reqBody := ""
+	if req.Body != nil {
+		switch req.Body.Mode {
+		case "raw":
+			reqBody = resolve(req.Body.Raw)
+			if req.Body.Options != nil && req.Body.Options.Raw.Language == "json" && headerValue(reqHeader, "Content-Type") == "" {
+				reqHeader["Content-Type"] = "application/json"
+			}
+		case "urlencoded":
+			form := url.Values{}
+			for _, field := range req.Body.URLEncoded {
+				if !field.Disabled {
+					form.Add(resolve(field.Key), resolve(field.Value))
+				}
+			}
+			reqBody = form.Encode()
+			if headerValue(reqHeader, "Content-Type") == "" {
+				reqHeader["Content-Type"] = "application/x-www-form-urlencoded"
+			}
+		}
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	noise := map[string][]string{}
+	statusCode := http.StatusOK
+	respHeader := map[string]string{}
+	respBody := ""
+	if len(item.Response) > 0 {
+		resp := item.Response[0]
+		if resp.Code != 0 {
+			statusCode = resp.Code
+		}
+		respHeader = postmanHeaders(resp.Header, resolve)
+		respBody = resp.Body
+	} else {
+		// without a saved example response only the status code can be asserted
+		noise["header"] = []string{}
+		noise["body"] = []string{}
+	}
+
+	return newHTTPTestCase(method, resolve(postmanRawURL(req.URL)), reqHeader, reqBody, statusCode, respHeader, respBody, noise, timestamp)
+}
+
+func postmanHeaders(headers []models.PostmanHeader, resolve func(string) string) map[string]string {
+	header := map[string]string{}
+	for _, h := range headers {
+		if !h.Disabled {
+			header[resolve(h.Key)] = resolve(h.Value)
+		}
+	}
+	return header
+}
+
+func postmanRawURL(u models.PostmanURL) string {
+	if u.Raw != "" {
+		return u.Raw
+	}
+	raw := strings.Join(u.Host, ".")
+	if len(u.Path) > 0 {
+		raw += "/" + strings.Join(u.Path, "/")
+	}
+	var query []string
+	for _, q := range u.Query {
+		if !q.Disabled {
+			query = append(query, q.Key+"="+q.Value)
+		}
+	}
+	if len(query) > 0 {
+		raw += "?" + strings.Join(query, "&")
+	}
+	return raw
+}
+
+// postmanFromTestCases converts the http testcases into a postman collection with a folder per test-set.
+// The hosts of the requests are mapped to collection variables so that they can be switched using environments.
+func postmanFromTestCases(testCases map[string][]*models.TestCase) *models.PostmanCollection {
+	collection := &models.PostmanCollection{
+		Info: models.PostmanInfo{
+			Name:   "Keploy exported collection",
+			Schema: models.PostmanSchemaV21,
+		},
+	}
+
+	testSetIDs := make([]string, 0, len(testCases))
+	for testSetID := range testCases {
+		testSetIDs = append(testSetIDs, testSetID)
+	}
+	sort.Strings(testSetIDs)
+
+	hostVars := map[string]string{}
+	for _, testSetID := range testSetIDs {
+		folder := models.PostmanItem{Name: testSetID}
+		for _, tc := range testCases[testSetID] {
+			if tc.Kind != models.HTTP {
+				continue
+			}
+			rawURL := tc.HTTPReq.URL
+			if parsedURL, err := url.Parse(rawURL); err == nil && parsedURL.Host != "" {
+				base := parsedURL.Scheme + "://" + parsedURL.Host
+				name, ok := hostVars[base]
+				if !ok {
+					name = "baseUrl"
+					if len(hostVars) > 0 {
+						name = fmt.Sprintf("baseUrl%d", len(hostVars)+1)
+					}
+					hostVars[base] = name
+					collection.Variable = append(collection.Variable, models.PostmanVariable{Key: name, Value: base})
+				}
+				rawURL = "{{" + name + "}}" + strings.TrimPrefix(rawURL, base)
+			}
+
+			req := &models.PostmanRequest{
+				Method: string(tc.HTTPReq.Method),
+				Header: toPostmanHeaders(tc.HTTPReq.Header),
+				URL:    models.PostmanURL{Raw: rawURL},
+			}
+			if tc.HTTPReq.Body != "" {
+				req.Body = &models.PostmanBody{Mode: "raw", Raw: tc.HTTPReq.Body}
+				if json.Valid([]byte(tc.HTTPReq.Body)) {
+					req.Body.Options = &models.PostmanOptions{Raw: models.PostmanRawOptions{Language: "json"}}
+				}
+			}
+			folder.Item = append(folder.Item, models.PostmanItem{
+				Name:    tc.Name,
+				Request: req,
+				Response: []models.PostmanResponse{{
+					Name:            tc.Name,
+					OriginalRequest: req,
+					Status:          http.StatusText(tc.HTTPResp.StatusCode),
+					Code:            tc.HTTPResp.StatusCode,
+					Header:          toPostmanHeaders(tc.HTTPResp.Header),
+					Body:            tc.HTTPResp.Body,
+				}},
+			})
+		}
+		if len(folder.Item) > 0 {
+			collection.Item = append(collection.Item, folder)
+		}
+	}
+	return collection
+}
+
+func toPostmanHeaders(header map[string]string) []models.PostmanHeader {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	headers := []models.PostmanHeader{}
+	for _, key := range keys {
+		// content length is computed by postman while sending the request
+		if strings.EqualFold(key, "Content-Length") {
+			continue
+		}
+		headers = append(headers, models.PostmanHeader{Key: key, Value: header[key]})
+	}
+	return headers
+}
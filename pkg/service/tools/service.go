@@ -1,11 +1,37 @@
 // Package tools provides utility functions for the service package.
 package tools
 
-import "context"
+import (
+	"context"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+)
 
 type Service interface {
-	Update(ctx context.Context) error
+	Update(ctx context.Context, opts config.Update) error
 	CreateConfig(ctx context.Context, filePath string, config string) error
+	GenerateFromOpenAPI(ctx context.Context, specPath string, baseURL string) error
+	Export(ctx context.Context, format string, testSetIDs []string, outputPath string) error
+	ImportPostman(ctx context.Context, collectionPath string, envPath string) error
+	ImportCurl(ctx context.Context, curlCmds string, testSetID string) error
+	ImportHTTPFile(ctx context.Context, path string, testSetID string) error
+	AddNoise(ctx context.Context, filePath string, testSetID string, field string, regexes []string) error
+	Tag(ctx context.Context, testSetID string, testCases []string, add []string, remove []string) error
+	EditMock(ctx context.Context, testSetID string, mockName string) error
+	Init(ctx context.Context, dir string) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	InsertTestCase(ctx context.Context, tc *models.TestCase, testSetID string) error
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	UpdateTestCase(ctx context.Context, testCase *models.TestCase, testSetID string) error
+}
+
+type MockDB interface {
+	GetMocks(ctx context.Context, testSetID string) ([]*models.Mock, error)
+	UpdateMock(ctx context.Context, testSetID string, mock *models.Mock) error
 }
 
 type teleDB interface {
@@ -0,0 +1,82 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+)
+
+// Tag adds the tags to and removes the tags from the testcases of the test set, all of them if none are given.
+// The testcases whose tags are unchanged aren't rewritten.
+func (t *Tools) Tag(ctx context.Context, testSetID string, testCases []string, add []string, remove []string) error {
+	tcs, err := t.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		utils.LogError(t.logger, err, "failed to get the testcases", zap.String("test-set", testSetID))
+		return err
+	}
+	selected := make(map[string]bool, len(testCases))
+	for _, name := range testCases {
+		selected[name] = true
+	}
+
+	tagged := 0
+	all := len(selected) == 0
+	for _, tc := range tcs {
+		if !all && !selected[tc.Name] {
+			continue
+		}
+		delete(selected, tc.Name)
+		tags, changed := editTags(tc.Tags, add, remove)
+		if !changed {
+			continue
+		}
+		tc.Tags = tags
+		err = t.testDB.UpdateTestCase(ctx, tc, testSetID)
+		if err != nil {
+			utils.LogError(t.logger, err, "failed to update the tags of the testcase", zap.String("testcase", tc.Name))
+			return err
+		}
+		tagged++
+	}
+	if len(selected) > 0 {
+		missing := make([]string, 0, len(selected))
+		for name := range selected {
+			missing = append(missing, name)
+		}
+		return fmt.Errorf("testcases %s not found in %s", strings.Join(missing, ", "), testSetID)
+	}
+	t.logger.Info("updated the tags of the testcases", zap.String("test-set", testSetID), zap.Int("testcases", tagged))
+	return nil
+}
+
+// editTags returns the tags with the added tags appended and the removed tags left out, and whether they changed.
+func editTags(tags []string, add []string, remove []string) ([]string, bool) {
+	edited := make([]string, 0, len(tags)+len(add))
+	changed := false
+	for _, tag := range tags {
+		if containsTag(remove, tag) {
+			changed = true
+			continue
+		}
+		edited = append(edited, tag)
+	}
+	for _, tag := range add {
+		if !containsTag(edited, tag) {
+			edited = append(edited, tag)
+			changed = true
+		}
+	}
+	return edited, changed
+}
+
+func containsTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
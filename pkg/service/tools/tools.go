@@ -2,6 +2,7 @@ package tools
 
 import (
 	"archive/tar"
+	"bytes"
 	"compress/gzip"
 	"context"
 	"errors"
@@ -12,7 +13,6 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
-	"runtime"
 	"strings"
 
 	"github.com/charmbracelet/glamour"
@@ -22,22 +22,27 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-func NewTools(logger *zap.Logger, telemetry teleDB) Service {
+func NewTools(logger *zap.Logger, testDB TestDB, mockDB MockDB, telemetry teleDB) Service {
 	return &Tools{
 		logger:    logger,
+		testDB:    testDB,
+		mockDB:    mockDB,
 		telemetry: telemetry,
 	}
 }
 
 type Tools struct {
 	logger    *zap.Logger
+	testDB    TestDB
+	mockDB    MockDB
 	telemetry teleDB
 }
 
 var ErrGitHubAPIUnresponsive = errors.New("GitHub API is unresponsive")
 
-// Update initiates the tools process for the Keploy binary file.
-func (t *Tools) Update(ctx context.Context) error {
+// Update initiates the tools process for the Keploy binary file. The binary of the release of the channel, or of
+// the offline release, is verified against the checksums of the release before it replaces the current one.
+func (t *Tools) Update(ctx context.Context, opts config.Update) error {
 	currentVersion := "v" + utils.Version
 	isKeployInDocker := len(os.Getenv("KEPLOY_INDOCKER")) > 0
 	if isKeployInDocker {
@@ -48,8 +53,37 @@ func (t *Tools) Update(ctx context.Context) error {
 		fmt.Println("you are using a development version of Keploy. Skipping update")
 		return nil
 	}
+	if err := checkPlatform(); err != nil {
+		return err
+	}
+	publicKey := opts.PublicKey
+	if publicKey == "" {
+		publicKey = releasePublicKey
+	}
+	if opts.SkipSignature {
+		t.logger.Warn("the signature of the release checksums isn't verified as --skipSignature is set, the binary is only verified against the checksums")
+		publicKey = ""
+	}
 
-	releaseInfo, err := utils.GetLatestGitHubRelease(ctx, t.logger)
+	if opts.Offline != "" {
+		archive, checksums, signature, err := offlineRelease(opts.Offline)
+		if err != nil {
+			return err
+		}
+		t.logger.Info("Updating from the offline release", zap.String("archive", archive))
+		err = verifyRelease(archive, filepath.Base(archive), checksums, signature, publicKey)
+		if err != nil {
+			return err
+		}
+		err = t.install(archive)
+		if err != nil {
+			return err
+		}
+		t.logger.Info("Update Successful!")
+		return nil
+	}
+
+	releaseInfo, err := utils.GetGitHubRelease(ctx, t.logger, opts.Channel)
 	if err != nil {
 		if errors.Is(err, ErrGitHubAPIUnresponsive) {
 			return errors.New("gitHub API is unresponsive. Update process cannot continue")
@@ -60,20 +94,15 @@ func (t *Tools) Update(ctx context.Context) error {
 	latestVersion := releaseInfo.TagName
 	changelog := releaseInfo.Body
 
+	// the nightly release keeps its tag, it is updated to whenever asked for
 	if currentVersion == latestVersion {
 		fmt.Println("✅You are already on the latest version of Keploy: " + latestVersion)
 		return nil
 	}
 
-	t.logger.Info("Updating to Version: " + latestVersion)
+	t.logger.Info("Updating to Version: "+latestVersion, zap.String("channel", opts.Channel))
 
-	downloadURL := ""
-	if runtime.GOARCH == "amd64" {
-		downloadURL = "https://github.com/keploy/keploy/releases/latest/download/keploy_linux_amd64.tar.gz"
-	} else {
-		downloadURL = "https://github.com/keploy/keploy/releases/latest/download/keploy_linux_arm64.tar.gz"
-	}
-	err = t.downloadAndUpdate(ctx, t.logger, downloadURL)
+	err = t.downloadAndUpdate(ctx, t.logger, releaseInfo, publicKey)
 	if err != nil {
 		return err
 	}
@@ -100,9 +129,67 @@ func (t *Tools) Update(ctx context.Context) error {
 	return nil
 }
 
-func (t *Tools) downloadAndUpdate(ctx context.Context, logger *zap.Logger, downloadURL string) error {
+// downloadAndUpdate downloads the archive of the binary of the release with its checksums and their signature, and
+// installs the binary once it's verified.
+func (t *Tools) downloadAndUpdate(ctx context.Context, logger *zap.Logger, release utils.GitHubRelease, publicKey string) error {
+	name := archiveName()
+	archiveURL := fmt.Sprintf("https://github.com/keploy/keploy/releases/download/%s/%s", release.TagName, name)
+	var checksumsURL, signatureURL string
+	for _, asset := range release.Assets {
+		switch {
+		case asset.Name == name:
+			archiveURL = asset.URL
+		case strings.HasSuffix(asset.Name, checksumsSuffix):
+			checksumsURL = asset.URL
+		case strings.HasSuffix(asset.Name, checksumsSuffix+signatureSuffix):
+			signatureURL = asset.URL
+		}
+	}
+	if checksumsURL == "" {
+		return fmt.Errorf("the release %s has no checksums to verify the binary with", release.TagName)
+	}
+
+	// Create a temporary file to store the downloaded tar.gz
+	tmpFile, err := os.CreateTemp("", "keploy-download-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer func() {
+		if err := tmpFile.Close(); err != nil {
+			utils.LogError(logger, err, "failed to close temporary file")
+		}
+		if err := os.Remove(tmpFile.Name()); err != nil {
+			utils.LogError(logger, err, "failed to remove temporary file")
+		}
+	}()
+
+	err = download(ctx, logger, archiveURL, tmpFile)
+	if err != nil {
+		return err
+	}
+	var checksums, signature bytes.Buffer
+	err = download(ctx, logger, checksumsURL, &checksums)
+	if err != nil {
+		return err
+	}
+	if signatureURL != "" {
+		err = download(ctx, logger, signatureURL, &signature)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = verifyRelease(tmpFile.Name(), name, checksums.Bytes(), signature.Bytes(), publicKey)
+	if err != nil {
+		return err
+	}
+	return t.install(tmpFile.Name())
+}
+
+// download writes the file at the url to w.
+func download(ctx context.Context, logger *zap.Logger, url string, w io.Writer) error {
 	// Create a new request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %v", err)
 	}
@@ -118,29 +205,21 @@ func (t *Tools) downloadAndUpdate(ctx context.Context, logger *zap.Logger, downl
 			utils.LogError(logger, cerr, "failed to close response body")
 		}
 	}()
-
-	// Create a temporary file to store the downloaded tar.gz
-	tmpFile, err := os.CreateTemp("", "keploy-download-*.tar.gz")
-	if err != nil {
-		return fmt.Errorf("failed to create temporary file: %v", err)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", url, resp.Status)
 	}
-	defer func() {
-		if err := tmpFile.Close(); err != nil {
-			utils.LogError(logger, err, "failed to close temporary file")
-		}
-		if err := os.Remove(tmpFile.Name()); err != nil {
-			utils.LogError(logger, err, "failed to remove temporary file")
-		}
-	}()
 
-	// Write the downloaded content to the temporary file
-	_, err = io.Copy(tmpFile, resp.Body)
+	_, err = io.Copy(w, resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to write to temporary file: %v", err)
+		return fmt.Errorf("failed to write the downloaded file: %v", err)
 	}
+	return nil
+}
 
+// install replaces the keploy binary with the one of the verified archive.
+func (t *Tools) install(archive string) error {
 	// Extract the tar.gz file
-	if err := extractTarGz(tmpFile.Name(), "/tmp"); err != nil {
+	if err := extractTarGz(archive, "/tmp"); err != nil {
 		return fmt.Errorf("failed to extract tar.gz file: %v", err)
 	}
 
@@ -0,0 +1,134 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// the checksums of a release are in its asset ending with checksumsSuffix, e.g. checksums.txt, in the sha256sum
+// format, and their ed25519 signature in the one ending with checksumsSuffix+signatureSuffix
+const (
+	checksumsSuffix = "checksums.txt"
+	signatureSuffix = ".sig"
+)
+
+// releasePublicKey is the base64 ed25519 key the checksums of the releases are signed with by the release workflow,
+// the signature is verified with it unless another key is set with --publicKey.
+//
+//go:embed release.pub
+var releasePublicKey string
+
+// archiveName returns the name of the release archive of the binary for the os and arch keploy runs on, by the
+// name_template of the archives in goreleaser.yaml.
+func archiveName() string {
+	return fmt.Sprintf("keploy_%s_%s.tar.gz", runtime.GOOS, runtime.GOARCH)
+}
+
+// checkPlatform returns an error if keploy can't update itself on the os and arch it runs on. The releases are built
+// for amd64 and arm64, and the binary of a running keploy can't be replaced on windows.
+func checkPlatform() error {
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		return fmt.Errorf("keploy can't update itself on %s, download the %s archive of the release instead", runtime.GOOS, archiveName())
+	}
+	if runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64" {
+		return fmt.Errorf("keploy isn't released for %s, build it from the source to update it", runtime.GOARCH)
+	}
+	return nil
+}
+
+// offlineRelease returns the archive of the offline release and the content of its checksums and their signature,
+// which are next to the archive. The path is either the archive, or its directory with the archive for the arch.
+func offlineRelease(path string) (string, []byte, []byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to find the offline release: %w", err)
+	}
+	archive, dir := path, filepath.Dir(path)
+	if info.IsDir() {
+		archive, dir = filepath.Join(path, archiveName()), path
+		if _, err := os.Stat(archive); err != nil {
+			return "", nil, nil, fmt.Errorf("failed to find the archive %s of the offline release: %w", archiveName(), err)
+		}
+	}
+
+	matches, err := filepath.Glob(filepath.Join(dir, "*"+checksumsSuffix))
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if len(matches) == 0 {
+		return "", nil, nil, fmt.Errorf("found no %s next to the offline release to verify the binary with", checksumsSuffix)
+	}
+	checksums, err := os.ReadFile(matches[0])
+	if err != nil {
+		return "", nil, nil, fmt.Errorf("failed to read the checksums of the offline release: %w", err)
+	}
+	signature, err := os.ReadFile(matches[0] + signatureSuffix)
+	if err != nil && !os.IsNotExist(err) {
+		return "", nil, nil, fmt.Errorf("failed to read the signature of the checksums of the offline release: %w", err)
+	}
+	return archive, checksums, signature, nil
+}
+
+// verifyRelease verifies the archive against its sha256 in the checksums, by its name. The signature of the checksums
+// is verified first with the base64 ed25519 public key, unless it is empty as the user opted out of it.
+func verifyRelease(archive string, name string, checksums []byte, signature []byte, publicKey string) error {
+	if publicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(publicKey))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return errors.New("invalid public key of the releases, expected a base64 ed25519 key")
+		}
+		if len(signature) == 0 {
+			return errors.New("the release has no signature of its checksums to verify with the public key")
+		}
+		// the signature is either raw or base64
+		sig := signature
+		if len(sig) != ed25519.SignatureSize {
+			sig, err = base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+			if err != nil {
+				return fmt.Errorf("invalid signature of the release checksums: %w", err)
+			}
+		}
+		if !ed25519.Verify(key, checksums, sig) {
+			return errors.New("the signature of the release checksums doesn't match the public key, the release may have been tampered with")
+		}
+	}
+
+	want := ""
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			want = strings.ToLower(fields[0])
+			break
+		}
+	}
+	if want == "" {
+		return fmt.Errorf("found no checksum of %s in the release checksums", name)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to compute the checksum of %s: %w", name, err)
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return fmt.Errorf("the checksum %s of %s doesn't match the release checksum %s, the download may be corrupt", got, name, want)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package ui provides the web dashboard of keploy, to browse the recorded test sets and the reports of the test
+// runs, edit the noise of the testcases and rerun them.
+package ui
+
+import (
+	"context"
+	"time"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+type Service interface {
+	// Serve serves the dashboard on the localhost until the context is cancelled.
+	Serve(ctx context.Context) error
+}
+
+type TestDB interface {
+	GetAllTestSetIDs(ctx context.Context) ([]string, error)
+	GetTestCases(ctx context.Context, testSetID string) ([]*models.TestCase, error)
+	UpdateTestCase(ctx context.Context, testCase *models.TestCase, testSetID string) error
+}
+
+type MockDB interface {
+	GetFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+	GetUnFilteredMocks(ctx context.Context, testSetID string, afterTime time.Time, beforeTime time.Time) ([]*models.Mock, error)
+}
+
+type ReportDB interface {
+	GetAllTestRunIDs(ctx context.Context) ([]string, error)
+	GetReport(ctx context.Context, testRunID string, testSetID string) (*models.TestReport, error)
+}
+
+// TestCase is the summary of a testcase listed in a test set.
+type TestCase struct {
+	Name          string              `json:"name"`
+	Kind          models.Kind         `json:"kind"`
+	Method        string              `json:"method"`
+	URL           string              `json:"url"`
+	Noise         map[string][]string `json:"noise"`
+	CorrelationID string              `json:"correlationId,omitempty"`
+}
+
+// Mock is the summary of a mock listed in a test set.
+type Mock struct {
+	Name string      `json:"name"`
+	Kind models.Kind `json:"kind"`
+	Type string      `json:"type"`
+}
+
+// TestRun is the reports of the test sets run in a test run.
+type TestRun struct {
+	ID      string               `json:"id"`
+	Reports []*models.TestReport `json:"reports"`
+}
+
+// RerunResult is the output of the keploy test run of a single testcase.
+type RerunResult struct {
+	Passed bool   `json:"passed"`
+	Output string `json:"output"`
+}
@@ -0,0 +1,328 @@
+package ui
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.keploy.io/server/v2/config"
+	"go.keploy.io/server/v2/pkg/models"
+	"go.keploy.io/server/v2/utils"
+	"go.uber.org/zap"
+	yamlLib "gopkg.in/yaml.v3"
+)
+
+//go:embed static
+var static embed.FS
+
+type UI struct {
+	logger   *zap.Logger
+	testDB   TestDB
+	mockDB   MockDB
+	reportDB ReportDB
+	config   config.Config
+}
+
+func New(logger *zap.Logger, testDB TestDB, mockDB MockDB, reportDB ReportDB, config config.Config) Service {
+	return &UI{
+		logger:   logger,
+		testDB:   testDB,
+		mockDB:   mockDB,
+		reportDB: reportDB,
+		config:   config,
+	}
+}
+
+// Serve serves the dashboard and its api on the localhost only, as the testcases can be edited and rerun from it.
+// The api is only served with the token of the session, printed in the url of the dashboard, and to the pages of
+// the dashboard itself, so that neither the other websites open in the browser nor the other users can call it.
+func (u *UI) Serve(ctx context.Context) error {
+	assets, err := fs.Sub(static, "static")
+	if err != nil {
+		return err
+	}
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		utils.LogError(u.logger, err, "failed to generate the token of the dashboard")
+		return err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServer(http.FS(assets)))
+	mux.HandleFunc("GET /api/testsets", u.handle(u.testSets))
+	mux.HandleFunc("GET /api/testsets/{testSet}/testcases", u.handle(u.testCases))
+	mux.HandleFunc("GET /api/testsets/{testSet}/mocks", u.handle(u.mocks))
+	mux.HandleFunc("PUT /api/testsets/{testSet}/testcases/{testCase}/noise", u.handle(u.updateNoise))
+	mux.HandleFunc("POST /api/testsets/{testSet}/testcases/{testCase}/rerun", u.handle(u.rerun))
+	mux.HandleFunc("GET /api/testruns", u.handle(u.testRuns))
+	mux.HandleFunc("GET /api/testruns/{testRun}", u.handle(u.testRun))
+
+	srv := &http.Server{
+		Addr:              "127.0.0.1:" + strconv.Itoa(int(u.config.UI.Port)),
+		Handler:           guard(u.config.UI.Port, token, mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		if err := srv.Shutdown(context.Background()); err != nil {
+			utils.LogError(u.logger, err, "failed to stop the dashboard")
+		}
+	}()
+
+	u.logger.Info(fmt.Sprintf("the dashboard is served on http://localhost:%d/#token=%s", u.config.UI.Port, token))
+	err = srv.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		utils.LogError(u.logger, err, "failed to serve the dashboard")
+		return err
+	}
+	return nil
+}
+
+// guard rejects the requests for another host than the localhost, e.g. of a DNS rebinding, the api calls from
+// another origin and the api calls without the token.
+func guard(port uint32, token string, next http.Handler) http.Handler {
+	hosts := []string{fmt.Sprintf("localhost:%d", port), fmt.Sprintf("127.0.0.1:%d", port)}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(hosts, r.Host) {
+			http.Error(w, "the dashboard is only served on the localhost", http.StatusForbidden)
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/") {
+			if origin := r.Header.Get("Origin"); origin != "" && !slices.Contains(hosts, strings.TrimPrefix(origin, "http://")) {
+				http.Error(w, "the api of the dashboard is only called by the dashboard", http.StatusForbidden)
+				return
+			}
+			bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(token)) != 1 {
+				http.Error(w, "missing or invalid token, open the dashboard from the url printed by keploy ui", http.StatusUnauthorized)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// handle writes the result of the api call as json, or its error with the status code of the error.
+func (u *UI) handle(api func(r *http.Request) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		res, err := api(r)
+		w.Header().Set("Content-Type", "application/json")
+		if err != nil {
+			status := http.StatusInternalServerError
+			var apiErr *apiError
+			if errors.As(err, &apiErr) {
+				status = apiErr.status
+			} else {
+				utils.LogError(u.logger, err, "failed to serve the api call of the dashboard", zap.String("path", r.URL.Path))
+			}
+			w.WriteHeader(status)
+			res = map[string]string{"error": err.Error()}
+		}
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			u.logger.Debug("failed to write the response of the api call of the dashboard", zap.Error(err))
+		}
+	}
+}
+
+type apiError struct {
+	status int
+	msg    string
+}
+
+func (e *apiError) Error() string {
+	return e.msg
+}
+
+func (u *UI) testSets(r *http.Request) (interface{}, error) {
+	return u.testDB.GetAllTestSetIDs(r.Context())
+}
+
+func (u *UI) testCases(r *http.Request) (interface{}, error) {
+	tcs, err := u.testDB.GetTestCases(r.Context(), r.PathValue("testSet"))
+	if err != nil {
+		return nil, err
+	}
+	res := make([]TestCase, 0, len(tcs))
+	for _, tc := range tcs {
+		summary := TestCase{
+			Name:          tc.Name,
+			Kind:          tc.Kind,
+			Method:        string(tc.HTTPReq.Method),
+			URL:           tc.HTTPReq.URL,
+			Noise:         tc.Noise,
+			CorrelationID: tc.CorrelationID,
+		}
+		if tc.Kind == models.GRPC_EXPORT {
+			summary.Method = "gRPC"
+			summary.URL = tc.GrpcReq.Headers.PseudoHeaders[":path"]
+		}
+		res = append(res, summary)
+	}
+	return res, nil
+}
+
+func (u *UI) mocks(r *http.Request) (interface{}, error) {
+	testSetID := r.PathValue("testSet")
+	filtered, err := u.mockDB.GetFilteredMocks(r.Context(), testSetID, models.BaseTime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	unfiltered, err := u.mockDB.GetUnFilteredMocks(r.Context(), testSetID, models.BaseTime, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	res := []Mock{}
+	seen := map[string]bool{}
+	for _, mock := range append(filtered, unfiltered...) {
+		if seen[mock.Name] {
+			continue
+		}
+		seen[mock.Name] = true
+		res = append(res, Mock{Name: mock.Name, Kind: mock.Kind, Type: mock.Spec.Metadata["type"]})
+	}
+	return res, nil
+}
+
+// updateNoise replaces the noise of a testcase with the noise in the request body.
+func (u *UI) updateNoise(r *http.Request) (interface{}, error) {
+	var noise map[string][]string
+	if err := json.NewDecoder(r.Body).Decode(&noise); err != nil {
+		return nil, &apiError{status: http.StatusBadRequest, msg: "the noise should be a map of the fields to their regexes: " + err.Error()}
+	}
+	testSetID := r.PathValue("testSet")
+	tc, err := u.testCase(r.Context(), testSetID, r.PathValue("testCase"))
+	if err != nil {
+		return nil, err
+	}
+	if noise == nil {
+		noise = map[string][]string{}
+	}
+	tc.Noise = noise
+	err = u.testDB.UpdateTestCase(r.Context(), tc, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	u.logger.Info("updated the noise of the testcase", zap.String("testcase", tc.Name), zap.String("test-set", testSetID))
+	return tc.Noise, nil
+}
+
+func (u *UI) testCase(ctx context.Context, testSetID, name string) (*models.TestCase, error) {
+	tcs, err := u.testDB.GetTestCases(ctx, testSetID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tc := range tcs {
+		if tc.Name == name {
+			return tc, nil
+		}
+	}
+	return nil, &apiError{status: http.StatusNotFound, msg: fmt.Sprintf("testcase %s not found in %s", name, testSetID)}
+}
+
+// rerun runs keploy test for the testcase alone, with the config of the dashboard, and returns its output.
+func (u *UI) rerun(r *http.Request) (interface{}, error) {
+	testSetID := r.PathValue("testSet")
+	tc, err := u.testCase(r.Context(), testSetID, r.PathValue("testCase"))
+	if err != nil {
+		return nil, err
+	}
+	if u.config.Command == "" {
+		return nil, &apiError{status: http.StatusBadRequest, msg: "missing the command to start the app, set it with -c or in the config file"}
+	}
+
+	dir, err := os.MkdirTemp("", "keploy-ui")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := os.RemoveAll(dir); err != nil {
+			utils.LogError(u.logger, err, "failed to remove the config of the rerun")
+		}
+	}()
+	cfg := u.config
+	// the path of the config is the directory the keploy directory is in
+	cfg.Path = filepath.Dir(u.config.Path)
+	cfg.Test.SelectedTests = map[string][]string{testSetID: {tc.Name}}
+	data, err := yamlLib.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	err = os.WriteFile(filepath.Join(dir, "keploy.yml"), data, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	// the test run of the rerun is the one which was not there before it
+	prevRuns, err := u.reportDB.GetAllTestRunIDs(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	u.logger.Info("rerunning the testcase", zap.String("testcase", tc.Name), zap.String("test-set", testSetID))
+	out, err := exec.CommandContext(r.Context(), exe, "test", "--configPath", dir, "--disableANSI").CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		return nil, err
+	}
+	res := RerunResult{Output: string(out)}
+	runs, err := u.reportDB.GetAllTestRunIDs(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	for _, run := range runs {
+		if slices.Contains(prevRuns, run) {
+			continue
+		}
+		report, err := u.reportDB.GetReport(r.Context(), run, testSetID)
+		if err != nil {
+			return nil, err
+		}
+		for _, result := range report.Tests {
+			if result.TestCaseID == tc.Name {
+				res.Passed = result.Status == models.TestStatusPassed
+			}
+		}
+	}
+	return res, nil
+}
+
+func (u *UI) testRuns(r *http.Request) (interface{}, error) {
+	return u.reportDB.GetAllTestRunIDs(r.Context())
+}
+
+// testRun returns the reports of the test sets of the test run, with the diffs of the failed testcases.
+func (u *UI) testRun(r *http.Request) (interface{}, error) {
+	testRunID := r.PathValue("testRun")
+	testSetIDs, err := u.testDB.GetAllTestSetIDs(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	run := TestRun{ID: testRunID, Reports: []*models.TestReport{}}
+	for _, testSetID := range testSetIDs {
+		report, err := u.reportDB.GetReport(r.Context(), testRunID, testSetID)
+		if err != nil {
+			// the test set was not run in the test run
+			u.logger.Debug("no report of the test set in the test run", zap.String("test-set", testSetID), zap.String("test-run", testRunID), zap.Error(err))
+			continue
+		}
+		run.Reports = append(run.Reports, report)
+	}
+	return run, nil
+}
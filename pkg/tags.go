@@ -0,0 +1,32 @@
+package pkg
+
+import (
+	"strings"
+
+	"go.keploy.io/server/v2/pkg/models"
+)
+
+// Tags returns the tags carried by the X-Keploy-Tag header of a request, nil if it carries none.
+func Tags(header map[string]string) []string {
+	for k, v := range header {
+		if strings.EqualFold(k, models.TagHeader) {
+			return SplitTags(v)
+		}
+	}
+	return nil
+}
+
+// SplitTags returns the unique non-empty tags of a comma separated list e.g. "smoke, critical".
+func SplitTags(list string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, tag := range strings.Split(list, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -193,6 +194,25 @@ func MakeCurlCommand(method string, url string, header map[string]string, body s
 	return curl
 }
 
+// ReplaceBaseURL replaces the scheme and host of the recorded url with the ones of the base url.
+// The path of the base url, if any, is prefixed to the recorded path.
+func ReplaceBaseURL(recordedURL string, baseURL string) (string, error) {
+	parsedURL, err := url.Parse(recordedURL)
+	if err != nil {
+		return "", err
+	}
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	parsedURL.Scheme = base.Scheme
+	parsedURL.Host = base.Host
+	if basePath := strings.TrimSuffix(base.Path, "/"); basePath != "" {
+		parsedURL.Path = basePath + parsedURL.Path
+	}
+	return parsedURL.String(), nil
+}
+
 func ReadSessionIndices(path string, Logger *zap.Logger) ([]string, error) {
 	indices := []string{}
 	dir, err := os.OpenFile(path, os.O_RDONLY, fs.FileMode(os.O_RDONLY))
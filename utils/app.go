@@ -0,0 +1,175 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// the compose files docker compose looks for, in its order
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+var (
+	exposeRe  = regexp.MustCompile(`(?im)^\s*EXPOSE\s+(\d+)`)
+	envPortRe = regexp.MustCompile(`(?m)^\s*(?:export\s+)?(?:[A-Z_]*_)?PORT\s*=\s*"?(\d+)"?`)
+)
+
+// AppSetup is how the app in a directory is run, as detected from its files.
+type AppSetup struct {
+	Language   string // the language of the app, as passed to keploy test --language, empty if unknown
+	Compose    string // the compose file the app is run with, empty if it isn't
+	Container  string // the container of the app in the compose file
+	Dockerfile bool   // whether the app has a Dockerfile
+	Port       uint32
+	Native     string // the command running the app natively
+}
+
+// DetectApp detects the language of the app in the directory from its build files, the compose file it is run
+// with, and its port from the compose file, the Dockerfile or the .env file, 8080 if none of them has one.
+func DetectApp(dir string) AppSetup {
+	var setup AppSetup
+	exists := func(name string) bool { return CheckFileExists(filepath.Join(dir, name)) }
+	switch {
+	case exists("go.mod"):
+		setup.Language, setup.Native = "golang", "go run ."
+	case exists("package.json"):
+		setup.Language, setup.Native = "javascript", "npm start"
+	case exists("pom.xml"):
+		setup.Language, setup.Native = "java", "mvn spring-boot:run"
+	case exists("build.gradle"), exists("build.gradle.kts"):
+		setup.Language, setup.Native = "java", "./gradlew bootRun"
+	case exists("manage.py"):
+		setup.Language, setup.Native = "python", "python3 manage.py runserver --noreload"
+	case exists("requirements.txt"), exists("pyproject.toml"), exists("Pipfile"):
+		setup.Language, setup.Native = "python", "python3 app.py"
+		if exists("main.py") {
+			setup.Native = "python3 main.py"
+		}
+	}
+
+	for _, name := range composeFiles {
+		if exists(name) {
+			setup.Compose = name
+			setup.Container, setup.Port = composeApp(dir, name)
+			break
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "Dockerfile")); err == nil {
+		setup.Dockerfile = true
+		if setup.Port == 0 {
+			setup.Port = matchPort(exposeRe, data)
+		}
+	}
+	if setup.Port == 0 {
+		if data, err := os.ReadFile(filepath.Join(dir, ".env")); err == nil {
+			setup.Port = matchPort(envPortRe, data)
+		}
+	}
+	if setup.Port == 0 {
+		setup.Port = 8080
+	}
+	return setup
+}
+
+// composeApp returns the container and the published port of the app in the compose file, the first service built
+// from the directory publishing a port, or else the first one publishing a port.
+func composeApp(dir string, file string) (string, uint32) {
+	data, err := os.ReadFile(filepath.Join(dir, file))
+	if err != nil {
+		return "", 0
+	}
+	var compose struct {
+		Name     string `yaml:"name"`
+		Services map[string]struct {
+			ContainerName string        `yaml:"container_name"`
+			Build         interface{}   `yaml:"build"`
+			Ports         []interface{} `yaml:"ports"`
+		} `yaml:"services"`
+	}
+	if err := yaml.Unmarshal(data, &compose); err != nil {
+		return "", 0
+	}
+	names := make([]string, 0, len(compose.Services))
+	for name := range compose.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	app, port := "", uint32(0)
+	for _, built := range []bool{true, false} {
+		for _, name := range names {
+			service := compose.Services[name]
+			if (service.Build != nil) != built || len(service.Ports) == 0 {
+				continue
+			}
+			if p := publishedPort(service.Ports[0]); p != 0 {
+				app, port = name, p
+				break
+			}
+		}
+		if app != "" {
+			break
+		}
+	}
+	if app == "" {
+		return "", 0
+	}
+	if container := compose.Services[app].ContainerName; container != "" {
+		return container, port
+	}
+	// the default name of the containers of docker compose
+	project := compose.Name
+	if project == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return "", port
+		}
+		project = strings.ToLower(filepath.Base(abs))
+	}
+	return project + "-" + app + "-1", port
+}
+
+// publishedPort returns the port published on the host by the port of the compose file, either in the short syntax
+// e.g. "127.0.0.1:8080:80/tcp" or in the long one.
+func publishedPort(port interface{}) uint32 {
+	var published string
+	switch p := port.(type) {
+	case string:
+		parts := strings.Split(strings.SplitN(p, "/", 2)[0], ":")
+		published = parts[0]
+		if len(parts) > 1 {
+			published = parts[len(parts)-2]
+		}
+	case int:
+		published = strconv.Itoa(p)
+	case map[string]interface{}:
+		published = fmt.Sprint(p["published"])
+		if p["published"] == nil {
+			published = fmt.Sprint(p["target"])
+		}
+	}
+	// a range of ports is published from its first port
+	n, err := strconv.ParseUint(strings.SplitN(published, "-", 2)[0], 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint32(n)
+}
+
+func matchPort(re *regexp.Regexp, data []byte) uint32 {
+	m := re.FindSubmatch(data)
+	if m == nil {
+		return 0
+	}
+	n, err := strconv.ParseUint(string(m[1]), 10, 16)
+	if err != nil {
+		return 0
+	}
+	return uint32(n)
+}
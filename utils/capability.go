@@ -0,0 +1,41 @@
+package utils
+
+// Capability is a linux capability keploy needs to intercept the traffic of the app.
+type Capability struct {
+	Name string
+	Bit  uint
+}
+
+// the bits of the linux capabilities, they are declared here as keploy also builds for macOS and Windows
+var (
+	CapNetAdmin = Capability{Name: "CAP_NET_ADMIN", Bit: 12}
+	CapNetRaw   = Capability{Name: "CAP_NET_RAW", Bit: 13}
+	CapSysAdmin = Capability{Name: "CAP_SYS_ADMIN", Bit: 21}
+	CapPerfmon  = Capability{Name: "CAP_PERFMON", Bit: 38}
+	CapBPF      = Capability{Name: "CAP_BPF", Bit: 39}
+)
+
+// EBPFCapabilities are needed to load the eBPF hooks, CAP_SYS_ADMIN grants CAP_BPF and CAP_PERFMON on its own.
+var EBPFCapabilities = []Capability{CapBPF, CapPerfmon, CapNetAdmin}
+
+// IptablesCapabilities are needed to redirect the traffic of the app with iptables.
+var IptablesCapabilities = []Capability{CapNetAdmin, CapNetRaw}
+
+// MissingCapabilities returns the names of the capabilities missing from the effective set of keploy.
+func MissingCapabilities(caps ...Capability) ([]string, error) {
+	effective, err := effectiveCapabilities()
+	if err != nil {
+		return nil, err
+	}
+	has := func(c Capability) bool {
+		return effective&(1<<c.Bit) != 0
+	}
+	var missing []string
+	for _, c := range caps {
+		if has(c) || ((c == CapBPF || c == CapPerfmon) && has(CapSysAdmin)) {
+			continue
+		}
+		missing = append(missing, c.Name)
+	}
+	return missing, nil
+}
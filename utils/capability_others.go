@@ -0,0 +1,8 @@
+//go:build !linux
+
+package utils
+
+// effectiveCapabilities is empty outside linux, keploy only intercepts the app through its proxy there.
+func effectiveCapabilities() (uint64, error) {
+	return 0, nil
+}
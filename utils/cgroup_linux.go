@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// appCgroup is the cgroup v2 the native apps are started in while the UDP datagrams of the apps are intercepted.
+var appCgroup struct {
+	mu   sync.Mutex
+	path string // relative to the root of the cgroup v2 hierarchy, empty until it is created
+	dir  string
+	fd   int
+}
+
+// CreateAppCgroup creates the cgroup v2 the native apps started by keploy are put in, and returns its path relative
+// to the root of the hierarchy, so that the iptables rules intercepting the calls of the apps match their processes
+// only. It is created at the root of the hierarchy, or in the cgroup of keploy if keploy can't write the root e.g.
+// when it runs without root in a cgroup delegated to its user. It is created once per keploy process and removed by
+// RemoveAppCgroup.
+func CreateAppCgroup() (string, error) {
+	appCgroup.mu.Lock()
+	defer appCgroup.mu.Unlock()
+	if appCgroup.path != "" {
+		return appCgroup.path, nil
+	}
+	root, err := cgroup2Mount()
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("keploy-apps-%d", os.Getpid())
+	path, dir, err := mkAppCgroup(root, "", name)
+	if errors.Is(err, os.ErrPermission) {
+		own, ownErr := ownCgroup()
+		if ownErr != nil {
+			return "", fmt.Errorf("%w, and the cgroup of keploy is unknown: %v", err, ownErr)
+		}
+		path, dir, err = mkAppCgroup(root, own, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	fd, err := syscall.Open(dir, syscall.O_RDONLY|syscall.O_DIRECTORY|syscall.O_CLOEXEC, 0)
+	if err != nil {
+		_ = os.Remove(dir)
+		return "", err
+	}
+	appCgroup.path, appCgroup.dir, appCgroup.fd = path, dir, fd
+	return path, nil
+}
+
+// mkAppCgroup creates the cgroup name in the cgroup parent, relative to the root of the hierarchy, and returns its
+// path relative to the root and its directory.
+func mkAppCgroup(root, parent, name string) (string, string, error) {
+	// the cgroups left behind by a keploy which didn't exit cleanly are removed once their processes exited
+	stale, _ := filepath.Glob(filepath.Join(root, parent, "keploy-apps-*"))
+	for _, dir := range stale {
+		_ = os.Remove(dir)
+	}
+	path := strings.TrimPrefix(filepath.Join(parent, name), "/")
+	dir := filepath.Join(root, path)
+	if err := os.Mkdir(dir, 0o755); err != nil && !os.IsExist(err) {
+		return "", "", err
+	}
+	return path, dir, nil
+}
+
+// ownCgroup returns the cgroup v2 of keploy relative to the root of the hierarchy.
+func ownCgroup() (string, error) {
+	data, err := os.ReadFile("/proc/self/cgroup")
+	if err != nil {
+		return "", err
+	}
+	// the line of the cgroup v2 hierarchy is 0::/path
+	for _, line := range strings.Split(string(data), "\n") {
+		if path, ok := strings.CutPrefix(line, "0::"); ok {
+			return path, nil
+		}
+	}
+	return "", errors.New("keploy isn't in a cgroup v2")
+}
+
+// RemoveAppCgroup removes the cgroup of the apps, which only succeeds once their processes exited.
+func RemoveAppCgroup() error {
+	appCgroup.mu.Lock()
+	defer appCgroup.mu.Unlock()
+	if appCgroup.path == "" {
+		return nil
+	}
+	_ = syscall.Close(appCgroup.fd)
+	err := os.Remove(appCgroup.dir)
+	appCgroup.path, appCgroup.dir, appCgroup.fd = "", "", 0
+	return err
+}
+
+// PlaceInAppCgroup starts the command in the cgroup of the apps, if it was created.
+func PlaceInAppCgroup(cmd *exec.Cmd) {
+	appCgroup.mu.Lock()
+	defer appCgroup.mu.Unlock()
+	if appCgroup.path == "" {
+		return
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = appCgroup.fd
+}
+
+// cgroup2Mount returns the mount point of the cgroup v2 hierarchy.
+func cgroup2Mount() (string, error) {
+	f, err := os.Open("/proc/mounts")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		// e.g. cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 3 && fields[2] == "cgroup2" {
+			return fields[1], nil
+		}
+	}
+	return "", errors.New("cgroup2 not mounted")
+}
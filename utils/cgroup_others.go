@@ -0,0 +1,19 @@
+//go:build !linux
+
+package utils
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// CreateAppCgroup isn't supported outside linux, the UDP datagrams of the apps are only intercepted there.
+func CreateAppCgroup() (string, error) {
+	return "", errors.New("the cgroup of the apps is only supported on linux")
+}
+
+func RemoveAppCgroup() error {
+	return nil
+}
+
+func PlaceInAppCgroup(_ *exec.Cmd) {}
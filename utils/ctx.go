@@ -60,6 +60,24 @@ func Stop(logger *zap.Logger, reason string) error {
 	return nil
 }
 
+type sessionKey struct{}
+
+// WithSessionCancel makes StopSession end the session of the context with the cancel function instead of stopping
+// keploy, for the sessions keploy agent runs which must not stop the agent.
+func WithSessionCancel(ctx context.Context, cancel context.CancelFunc) context.Context {
+	return context.WithValue(ctx, sessionKey{}, cancel)
+}
+
+// StopSession stops the session of the context if it is one of keploy agent, and keploy otherwise.
+func StopSession(ctx context.Context, logger *zap.Logger, reason string) error {
+	if cancel, ok := ctx.Value(sessionKey{}).(context.CancelFunc); ok {
+		logger.Info("stopping the session", zap.String("reason", reason))
+		cancel()
+		return nil
+	}
+	return Stop(logger, reason)
+}
+
 func SetCancel(c context.CancelFunc) {
 	cancel = c
 }
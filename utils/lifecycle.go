@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// RunLifecycleHook runs the shell command of the lifecycle hook, e.g. preTestSet, with the environment of keploy
+// and the given variables, logging its output. It does nothing if the command is empty.
+func RunLifecycleHook(ctx context.Context, logger *zap.Logger, hook, command string, env ...string) error {
+	if strings.TrimSpace(command) == "" {
+		return nil
+	}
+	logger.Info("running the "+hook+" hook", zap.String("command", command))
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Env = append(os.Environ(), env...)
+	out, err := cmd.CombinedOutput()
+	if len(out) > 0 {
+		logger.Info("output of the "+hook+" hook", zap.String("output", strings.TrimRight(string(out), "\n")))
+	}
+	if err != nil {
+		return fmt.Errorf("the %s hook failed: %w", hook, err)
+	}
+	return nil
+}
@@ -102,6 +102,16 @@ func AddMode(mode string) (*zap.Logger, error) {
 	return newLogger, nil
 }
 
+// ToFileOnly writes the logs to the log file only, e.g. while the terminal is drawn over by the terminal ui.
+func ToFileOnly() (*zap.Logger, error) {
+	logCfg.OutputPaths = []string{"./keploy-logs.txt"}
+	logger, err := logCfg.Build()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config for logger: %v", err)
+	}
+	return logger, nil
+}
+
 func ChangeColorEncoding() (*zap.Logger, error) {
 	logCfg.Encoding = "nonColorConsole"
 	logger, err := logCfg.Build()
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// EnterScreen switches the terminal to the alternate screen and hides the cursor, LeaveScreen restores them, so that
+// the terminal uis of keploy leave the terminal as it was.
+func EnterScreen(out *os.File) {
+	fmt.Fprint(out, "\x1b[?1049h\x1b[?25l")
+}
+
+func LeaveScreen(out *os.File) {
+	fmt.Fprint(out, "\x1b[?25h\x1b[?1049l")
+}
+
+// DrawScreen draws the lines over the screen, cut to the width and the height of the terminal.
+func DrawScreen(out *os.File, lines []string) {
+	width, height, err := term.GetSize(int(out.Fd()))
+	if err != nil || width <= 0 {
+		width, height = 80, 24
+	}
+	// the last line of the screen is left empty, the newline after it would scroll the screen
+	if len(lines) > height-1 {
+		lines = lines[:max(height-1, 0)]
+	}
+	var b strings.Builder
+	// the lines are overwritten in place rather than clearing the screen, which flickers
+	b.WriteString("\x1b[H")
+	for _, line := range lines {
+		b.WriteString(fitLine(line, width))
+		b.WriteString("\x1b[K\r\n")
+	}
+	b.WriteString("\x1b[J")
+	fmt.Fprint(out, b.String())
+}
+
+// fitLine cuts the line to the width of the terminal, the escape sequences of the colors taking no width.
+func fitLine(line string, width int) string {
+	var b strings.Builder
+	visible := 0
+	escape := false
+	for _, r := range line {
+		switch {
+		case escape:
+			if r >= '@' && r <= '~' && r != '[' {
+				escape = false
+			}
+		case r == '\x1b':
+			escape = true
+		default:
+			if visible >= width {
+				continue
+			}
+			visible++
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
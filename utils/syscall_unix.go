@@ -0,0 +1,14 @@
+//go:build !windows
+
+package utils
+
+import "syscall"
+
+func umask(mask int) int {
+	return syscall.Umask(mask)
+}
+
+// killGroup signals the process group of the process.
+func killGroup(pid int, sig syscall.Signal) error {
+	return syscall.Kill(-pid, sig)
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"os"
+	"syscall"
+)
+
+// umask is a no-op, the permissions of the files on Windows aren't masked.
+func umask(_ int) int {
+	return 0
+}
+
+// killGroup kills the process, Windows has neither the signals nor the process groups of unix.
+func killGroup(pid int, _ syscall.Signal) error {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	return p.Kill()
+}
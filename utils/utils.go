@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
+	"go.keploy.io/server/v2/config"
 	"go.uber.org/zap"
 	"golang.org/x/term"
 )
@@ -127,8 +128,16 @@ func DeleteLogs(logger *zap.Logger) {
 }
 
 type GitHubRelease struct {
-	TagName string `json:"tag_name"`
-	Body    string `json:"body"`
+	TagName    string        `json:"tag_name"`
+	Body       string        `json:"body"`
+	Draft      bool          `json:"draft"`
+	Prerelease bool          `json:"prerelease"`
+	Assets     []GitHubAsset `json:"assets"`
+}
+
+type GitHubAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
 }
 
 var ErrGitHubAPIUnresponsive = errors.New("GitHub API is unresponsive")
@@ -180,6 +189,34 @@ var ConfigGuide = `
 #          # we can also pass the exact value to ignore for a field
 #          "User-Agent": ["PostmanRuntime/7.34.0"]
 #        }
+#Example on using graphqlNoise
+#graphqlNoise:
+#   # ignore the fields of the response of a GraphQL operation by its operation name,
+#   # the fields are relative to the response body
+#   GetUser: {
+#     "data.user.lastLogin": []
+#   }
+#   # "*" applies to the responses of every GraphQL operation
+#   "*": {
+#     "extensions.tracing": []
+#   }
+#Example on using chaos
+#chaos:
+#   # replace 10% of the mock responses of test-set-1 with faults, the testcases which
+#   # receive a fault are reported separately from the other testcases of the test-set
+#   test-set-1:
+#     percentage: 10
+#     # error (500 for http, UNAVAILABLE for grpc), reset and truncate (http only)
+#     faults: ["error", "reset", "truncate"]
+#     seed: 42
+#Example on naming the dependencies of the recorded mocks
+#record:
+#  dependencies:
+#   - name: payments-api
+#     hosts: ["payments.internal", "*.stripe.com"]
+#   - name: users-db
+#     # any host on the port
+#     hosts: [":5432"]
 `
 
 // AskForConfirmation asks the user for confirmation. A user must type in "yes" or "no" and
@@ -314,11 +351,25 @@ jobs:
 
 // GetLatestGitHubRelease fetches the latest version and release body from GitHub releases with a timeout.
 func GetLatestGitHubRelease(ctx context.Context, logger *zap.Logger) (GitHubRelease, error) {
+	return GetGitHubRelease(ctx, logger, config.UpdateStable)
+}
+
+// GetGitHubRelease fetches the latest release of the channel from GitHub releases with a timeout: the latest release
+// for stable, the latest one including the pre-releases for beta, and the release tagged nightly for nightly.
+func GetGitHubRelease(ctx context.Context, logger *zap.Logger, channel string) (GitHubRelease, error) {
 	// GitHub repository details
 	repoOwner := "keploy"
 	repoName := "keploy"
 
-	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases/latest", repoOwner, repoName)
+	apiURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/releases", repoOwner, repoName)
+	switch channel {
+	case config.UpdateBeta:
+		apiURL += "?per_page=30"
+	case config.UpdateNightly:
+		apiURL += "/tags/nightly"
+	default:
+		apiURL += "/latest"
+	}
 
 	client := http.Client{
 		Timeout: 4 * time.Second,
@@ -342,12 +393,28 @@ func GetLatestGitHubRelease(ctx context.Context, logger *zap.Logger) (GitHubRele
 			LogError(logger, err, "failed to close response body")
 		}
 	}()
+	if resp.StatusCode != http.StatusOK {
+		return GitHubRelease{}, fmt.Errorf("unexpected status %s of the %s release", resp.Status, channel)
+	}
 
-	var release GitHubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	if channel != config.UpdateBeta {
+		var release GitHubRelease
+		if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+			return GitHubRelease{}, err
+		}
+		return release, nil
+	}
+	// the releases are listed newest first, the nightly build isn't a beta
+	var releases []GitHubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
 		return GitHubRelease{}, err
 	}
-	return release, nil
+	for _, release := range releases {
+		if !release.Draft && release.TagName != config.UpdateNightly {
+			return release, nil
+		}
+	}
+	return GitHubRelease{}, errors.New("found no beta release")
 }
 
 // FindDockerCmd checks if the cli is related to docker or not, it also returns if it is a docker compose file
@@ -515,8 +582,8 @@ func GetAbsPath(path string) (string, error) {
 
 // makeDirectory creates a directory if not exists with all user access
 func makeDirectory(path string) error {
-	oldUmask := syscall.Umask(0)
-	defer syscall.Umask(oldUmask)
+	oldUmask := umask(0)
+	defer umask(oldUmask)
 	err := os.MkdirAll(path, 0777)
 	if err != nil {
 		return err
@@ -585,7 +652,7 @@ func InterruptProcessTree(logger *zap.Logger, ppid int, sig syscall.Signal) erro
 	}
 
 	for _, pid := range uniqueProcess {
-		err := syscall.Kill(-pid, sig)
+		err := killGroup(pid, sig)
 		// ignore the ESRCH error as it means the process is already dead
 		if errno, ok := err.(syscall.Errno); ok && err != nil && errno != syscall.ESRCH {
 			logger.Error("failed to send signal to process", zap.Int("pid", pid), zap.Error(err))